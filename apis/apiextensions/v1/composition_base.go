@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errFmtBaseTypeMismatch         = "composition is compatible with %s %s, but its base is compatible with %s %s"
+	errInheritAnonymous            = "resources must be named in order to be inherited from, or added to, a base composition"
+	errFmtInheritDuplicateResource = "resource %q is defined more than once"
+	errFmtInheritDuplicatePatchSet = "patch set %q is defined more than once"
+)
+
+// Inherit returns a copy of this composition spec that inherits from the
+// supplied base. Patch sets and resources of the base are replaced by any of
+// this spec's patch sets and resources of the same name, and followed by any
+// others. Any other fields of the base are used only if this spec doesn't set
+// them. The returned spec does not reference a base.
+func (cs *CompositionSpec) Inherit(base *CompositionSpec) (*CompositionSpec, error) { // nolint:gocyclo
+	if cs.CompositeTypeRef != base.CompositeTypeRef {
+		return nil, errors.Errorf(errFmtBaseTypeMismatch,
+			cs.CompositeTypeRef.APIVersion, cs.CompositeTypeRef.Kind,
+			base.CompositeTypeRef.APIVersion, base.CompositeTypeRef.Kind)
+	}
+
+	out := base.DeepCopy()
+	in := cs.DeepCopy()
+	out.BaseCompositionRef = nil
+
+	if in.CompositeTypeVersions != nil {
+		out.CompositeTypeVersions = in.CompositeTypeVersions
+	}
+	if in.WriteConnectionSecretsToNamespace != nil {
+		out.WriteConnectionSecretsToNamespace = in.WriteConnectionSecretsToNamespace
+	}
+	if in.WriteConnectionSecretsToNamespaceFrom != nil {
+		out.WriteConnectionSecretsToNamespaceFrom = in.WriteConnectionSecretsToNamespaceFrom
+	}
+	if in.PublishConnectionDetailsWithStoreConfigRef != nil {
+		out.PublishConnectionDetailsWithStoreConfigRef = in.PublishConnectionDetailsWithStoreConfigRef
+	}
+	if in.PropagateMetadata != nil {
+		out.PropagateMetadata = in.PropagateMetadata
+	}
+
+	psi := make(map[string]int, len(out.PatchSets))
+	for i, ps := range out.PatchSets {
+		psi[ps.Name] = i
+	}
+	seen := map[string]bool{}
+	for _, ps := range in.PatchSets {
+		if seen[ps.Name] {
+			return nil, errors.Errorf(errFmtInheritDuplicatePatchSet, ps.Name)
+		}
+		seen[ps.Name] = true
+		if i, ok := psi[ps.Name]; ok {
+			out.PatchSets[i] = ps
+			continue
+		}
+		out.PatchSets = append(out.PatchSets, ps)
+	}
+
+	// A resource of either composition that isn't named can't be matched to
+	// a resource of the other, so we'd have no way to tell whether it was an
+	// override or an addition.
+	if len(in.Resources) == 0 {
+		return out, nil
+	}
+	ri := make(map[string]int, len(out.Resources))
+	for i, r := range out.Resources {
+		if r.Name == nil {
+			return nil, errors.New(errInheritAnonymous)
+		}
+		ri[*r.Name] = i
+	}
+	seen = map[string]bool{}
+	for _, r := range in.Resources {
+		if r.Name == nil {
+			return nil, errors.New(errInheritAnonymous)
+		}
+		if seen[*r.Name] {
+			return nil, errors.Errorf(errFmtInheritDuplicateResource, *r.Name)
+		}
+		seen[*r.Name] = true
+		if i, ok := ri[*r.Name]; ok {
+			out.Resources[i] = r
+			continue
+		}
+		out.Resources = append(out.Resources, r)
+	}
+
+	return out, nil
+}
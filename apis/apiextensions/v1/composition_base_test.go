@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/pointer"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestInherit(t *testing.T) {
+	ref := TypeReference{APIVersion: "example.org/v1", Kind: "XR"}
+	ns := "crossplane-system"
+	other := "other-system"
+
+	named := func(name, patch string) ComposedTemplate {
+		return ComposedTemplate{
+			Name:    pointer.StringPtr(name),
+			Patches: []Patch{{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr(patch)}},
+		}
+	}
+
+	type args struct {
+		cs   *CompositionSpec
+		base *CompositionSpec
+	}
+	type want struct {
+		cs  *CompositionSpec
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"TypeMismatch": {
+			reason: "We should return an error if a composition and its base are compatible with different types.",
+			args: args{
+				cs:   &CompositionSpec{CompositeTypeRef: ref},
+				base: &CompositionSpec{CompositeTypeRef: TypeReference{APIVersion: "example.org/v1", Kind: "Other"}},
+			},
+			want: want{
+				err: errors.Errorf(errFmtBaseTypeMismatch, "example.org/v1", "XR", "example.org/v1", "Other"),
+			},
+		},
+		"AnonymousResource": {
+			reason: "We should return an error if a composition adds an anonymous resource to its base.",
+			args: args{
+				cs:   &CompositionSpec{CompositeTypeRef: ref, Resources: []ComposedTemplate{{}}},
+				base: &CompositionSpec{CompositeTypeRef: ref, Resources: []ComposedTemplate{named("a", "a")}},
+			},
+			want: want{
+				err: errors.New(errInheritAnonymous),
+			},
+		},
+		"DuplicateResource": {
+			reason: "We should return an error if a composition defines the same resource twice.",
+			args: args{
+				cs:   &CompositionSpec{CompositeTypeRef: ref, Resources: []ComposedTemplate{named("a", "a"), named("a", "b")}},
+				base: &CompositionSpec{CompositeTypeRef: ref},
+			},
+			want: want{
+				err: errors.Errorf(errFmtInheritDuplicateResource, "a"),
+			},
+		},
+		"InheritEverything": {
+			reason: "A composition that sets nothing but its type should be identical to its base.",
+			args: args{
+				cs: &CompositionSpec{CompositeTypeRef: ref, BaseCompositionRef: &xpv1.Reference{Name: "base"}},
+				base: &CompositionSpec{
+					CompositeTypeRef:                  ref,
+					WriteConnectionSecretsToNamespace: &ns,
+					PatchSets:                         []PatchSet{{Name: "p"}},
+					Resources:                         []ComposedTemplate{named("a", "a")},
+				},
+			},
+			want: want{
+				cs: &CompositionSpec{
+					CompositeTypeRef:                  ref,
+					WriteConnectionSecretsToNamespace: &ns,
+					PatchSets:                         []PatchSet{{Name: "p"}},
+					Resources:                         []ComposedTemplate{named("a", "a")},
+				},
+			},
+		},
+		"OverrideAndAdd": {
+			reason: "A composition's fields, patch sets, and resources should override and add to its base's.",
+			args: args{
+				cs: &CompositionSpec{
+					CompositeTypeRef:                  ref,
+					WriteConnectionSecretsToNamespace: &other,
+					PatchSets: []PatchSet{
+						{Name: "p", Patches: []Patch{{Type: PatchTypeFromCompositeFieldPath}}},
+						{Name: "q"},
+					},
+					Resources: []ComposedTemplate{named("b", "override"), named("c", "c")},
+				},
+				base: &CompositionSpec{
+					CompositeTypeRef:                  ref,
+					WriteConnectionSecretsToNamespace: &ns,
+					PatchSets:                         []PatchSet{{Name: "p"}},
+					Resources:                         []ComposedTemplate{named("a", "a"), named("b", "b")},
+				},
+			},
+			want: want{
+				cs: &CompositionSpec{
+					CompositeTypeRef:                  ref,
+					WriteConnectionSecretsToNamespace: &other,
+					PatchSets: []PatchSet{
+						{Name: "p", Patches: []Patch{{Type: PatchTypeFromCompositeFieldPath}}},
+						{Name: "q"},
+					},
+					Resources: []ComposedTemplate{named("a", "a"), named("b", "override"), named("c", "c")},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.args.cs.Inherit(tc.args.base)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ncs.Inherit(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cs, got); diff != "" {
+				t.Errorf("\n%s\ncs.Inherit(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -21,7 +21,9 @@ import (
 	"reflect"
 
 	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
@@ -30,6 +32,7 @@ import (
 const (
 	errPatchSetType             = "a patch in a PatchSet cannot be of type PatchSet"
 	errCombineRequiresVariables = "combine patch types require at least one variable"
+	errTransformFailureDefault  = "cannot unmarshal transform failure default value"
 
 	errFmtRequiredField               = "%s is required by type %s"
 	errFmtUndefinedPatchSet           = "cannot find PatchSet by name %s"
@@ -60,6 +63,25 @@ const (
 	FromFieldPathPolicyRequired FromFieldPathPolicy = "Required"
 )
 
+// A TransformFailurePolicy determines what happens when a patch's transforms
+// fail.
+type TransformFailurePolicy string
+
+// Transform failure policies.
+const (
+	// TransformFailureError fails to render the composed resource, and
+	// returns the transform's error.
+	TransformFailureError TransformFailurePolicy = "Error"
+
+	// TransformFailureSkip doesn't apply the patch, as if the field it
+	// patches from did not exist.
+	TransformFailureSkip TransformFailurePolicy = "Skip"
+
+	// TransformFailureUseDefault patches the patch's transform failure
+	// default value to its toFieldPath.
+	TransformFailureUseDefault TransformFailurePolicy = "UseDefault"
+)
+
 // A PatchPolicy configures the specifics of patching behaviour.
 type PatchPolicy struct {
 	// FromFieldPath specifies how to patch from a field path. The default is
@@ -70,6 +92,28 @@ type PatchPolicy struct {
 	// +optional
 	FromFieldPath *FromFieldPathPolicy `json:"fromFieldPath,omitempty"`
 	MergeOptions  *xpv1.MergeOptions   `json:"mergeOptions,omitempty"`
+
+	// TransformFailure specifies what happens when one of the patch's
+	// transforms fails. The default is 'Error', which means the composed
+	// resource will not be rendered. Use 'Skip' to not apply the patch, or
+	// 'UseDefault' to patch the transformFailureDefault value instead.
+	// +kubebuilder:validation:Enum=Error;Skip;UseDefault
+	// +optional
+	TransformFailure *TransformFailurePolicy `json:"transformFailure,omitempty"`
+
+	// TransformFailureDefault is the value that is patched to the
+	// toFieldPath when the transformFailure policy is 'UseDefault'.
+	// +optional
+	TransformFailureDefault *extv1.JSON `json:"transformFailureDefault,omitempty"`
+}
+
+// GetTransformFailurePolicy returns the transform failure policy of this patch
+// policy. Policies that don't specify one return Error.
+func (p *PatchPolicy) GetTransformFailurePolicy() TransformFailurePolicy {
+	if p == nil || p.TransformFailure == nil {
+		return TransformFailureError
+	}
+	return *p.TransformFailure
 }
 
 // Patch objects are applied between composite and composed resources. Their
@@ -213,7 +257,7 @@ func (c *Patch) applyFromFieldPathPatch(from, to runtime.Object) error {
 	// Apply transform pipeline
 	out, err := c.applyTransforms(in)
 	if err != nil {
-		return err
+		return c.transformFailed(err, to, mo)
 	}
 
 	return patchFieldValueToObject(*c.ToFieldPath, out, to, mo)
@@ -278,12 +322,32 @@ func (c *Patch) applyCombineFromVariablesPatch(from, to runtime.Object) error {
 	// Apply transform pipeline
 	out, err := c.applyTransforms(cb)
 	if err != nil {
-		return err
+		return c.transformFailed(err, to, nil)
 	}
 
 	return patchFieldValueToObject(*c.ToFieldPath, out, to, nil)
 }
 
+// transformFailed handles the supplied error, returned by the patch's
+// transforms, according to the patch's transform failure policy.
+func (c *Patch) transformFailed(err error, to runtime.Object, mo *xpv1.MergeOptions) error {
+	switch c.Policy.GetTransformFailurePolicy() {
+	case TransformFailureSkip:
+		return nil
+	case TransformFailureUseDefault:
+		if c.Policy.TransformFailureDefault == nil {
+			return errors.Errorf(errFmtRequiredField, "TransformFailureDefault", TransformFailureUseDefault)
+		}
+		var v interface{}
+		if err := utiljson.Unmarshal(c.Policy.TransformFailureDefault.Raw, &v); err != nil {
+			return errors.Wrap(err, errTransformFailureDefault)
+		}
+		return patchFieldValueToObject(*c.ToFieldPath, v, to, mo)
+	case TransformFailureError:
+	}
+	return err
+}
+
 // IsOptionalFieldPathNotFound returns true if the supplied error indicates a
 // field path was not found, and the supplied policy indicates a patch from that
 // field path was optional.
@@ -374,8 +438,26 @@ func (c *Combine) Combine(vars []interface{}) (interface{}, error) {
 }
 
 // ComposedTemplates returns a revision's composed resource templates with any
-// patchsets dereferenced.
+// patchsets dereferenced, and any propagated metadata expressed as patches.
 func (rs *CompositionSpec) ComposedTemplates() ([]ComposedTemplate, error) {
+	return rs.ComposedTemplatesForVersion(rs.CompositeTypeRef.APIVersion)
+}
+
+// ComposedTemplatesForVersion returns the composed resource templates used to
+// compose a composite resource of the supplied API version. The patches of any
+// templates that are overridden for that version are replaced before
+// patchsets are dereferenced.
+func (rs *CompositionSpec) ComposedTemplatesForVersion(apiVersion string) ([]ComposedTemplate, error) { // nolint:gocyclo
+	overrides := make(map[string][]Patch)
+	for _, v := range rs.CompositeTypeVersions {
+		if v.APIVersion != apiVersion {
+			continue
+		}
+		for _, o := range v.PatchOverrides {
+			overrides[o.ResourceName] = o.Patches
+		}
+	}
+
 	pn := make(map[string][]Patch)
 	for _, s := range rs.PatchSets {
 		for _, p := range s.Patches {
@@ -388,8 +470,16 @@ func (rs *CompositionSpec) ComposedTemplates() ([]ComposedTemplate, error) {
 
 	ct := make([]ComposedTemplate, len(rs.Resources))
 	for i, r := range rs.Resources {
-		po := []Patch{}
-		for _, p := range r.Patches {
+		// Propagated metadata is patched first, so that patches in the
+		// template may override it.
+		po := rs.PropagateMetadata.Patches()
+		patches := r.Patches
+		if r.Name != nil {
+			if o, ok := overrides[*r.Name]; ok {
+				patches = o
+			}
+		}
+		for _, p := range patches {
 			if p.Type != PatchTypePatchSet {
 				po = append(po, p)
 				continue
@@ -408,3 +498,27 @@ func (rs *CompositionSpec) ComposedTemplates() ([]ComposedTemplate, error) {
 	}
 	return ct, nil
 }
+
+// Patches returns patches that propagate the metadata specified by this policy
+// from a composite resource to a composed resource.
+func (m *MetadataPropagation) Patches() []Patch {
+	po := []Patch{}
+	if m == nil {
+		return po
+	}
+	for _, k := range m.Labels {
+		po = append(po, fromCompositeFieldPath("metadata.labels["+k+"]"))
+	}
+	for _, k := range m.Annotations {
+		po = append(po, fromCompositeFieldPath("metadata.annotations["+k+"]"))
+	}
+	return po
+}
+
+func fromCompositeFieldPath(path string) Patch {
+	return Patch{
+		Type:          PatchTypeFromCompositeFieldPath,
+		FromFieldPath: &path,
+		ToFieldPath:   &path,
+	}
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 
@@ -43,6 +44,17 @@ func TestPatchApply(t *testing.T) {
 		return err
 	}
 
+	// A math transform fails when its input is not a number.
+	failing := []Transform{{Type: TransformTypeMath, Math: &MathTransform{Multiply: pointer.Int64(2)}}}
+	_, errTransform := (&Patch{Transforms: failing}).applyTransforms("blah")
+	policy := func(p TransformFailurePolicy, def string) *PatchPolicy {
+		pp := &PatchPolicy{TransformFailure: &p}
+		if def != "" {
+			pp.TransformFailureDefault = &extv1.JSON{Raw: []byte(def)}
+		}
+		return pp
+	}
+
 	type args struct {
 		patch Patch
 		cp    *fake.Composite
@@ -123,6 +135,116 @@ func TestPatchApply(t *testing.T) {
 				err: nil,
 			},
 		},
+		"TransformFailureError": {
+			reason: "A patch should return an error when one of its transforms fails, by default",
+			args: args{
+				patch: Patch{
+					Type:          PatchTypeFromCompositeFieldPath,
+					FromFieldPath: pointer.StringPtr("objectMeta.labels.Test"),
+					ToFieldPath:   pointer.StringPtr("objectMeta.labels.Test"),
+					Transforms:    failing,
+				},
+				cp: &fake.Composite{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "cp",
+						Labels: map[string]string{"Test": "blah"},
+					},
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+				cd: &fake.Composed{
+					ObjectMeta: metav1.ObjectMeta{Name: "cd"},
+				},
+			},
+			want: want{
+				err: errTransform,
+			},
+		},
+		"TransformFailureSkip": {
+			reason: "A patch should be a no-op when one of its transforms fails and its policy is to skip",
+			args: args{
+				patch: Patch{
+					Type:          PatchTypeFromCompositeFieldPath,
+					FromFieldPath: pointer.StringPtr("objectMeta.labels.Test"),
+					ToFieldPath:   pointer.StringPtr("objectMeta.labels.Test"),
+					Transforms:    failing,
+					Policy:        policy(TransformFailureSkip, ""),
+				},
+				cp: &fake.Composite{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "cp",
+						Labels: map[string]string{"Test": "blah"},
+					},
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+				cd: &fake.Composed{
+					ObjectMeta: metav1.ObjectMeta{Name: "cd"},
+				},
+			},
+			want: want{
+				cd: &fake.Composed{
+					ObjectMeta: metav1.ObjectMeta{Name: "cd"},
+				},
+			},
+		},
+		"TransformFailureUseDefault": {
+			reason: "A patch should patch its default value when one of its transforms fails and its policy is to use the default",
+			args: args{
+				patch: Patch{
+					Type:          PatchTypeFromCompositeFieldPath,
+					FromFieldPath: pointer.StringPtr("objectMeta.labels.Test"),
+					ToFieldPath:   pointer.StringPtr("objectMeta.labels.Test"),
+					Transforms:    failing,
+					Policy:        policy(TransformFailureUseDefault, `"default"`),
+				},
+				cp: &fake.Composite{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "cp",
+						Labels: map[string]string{"Test": "blah"},
+					},
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+				cd: &fake.Composed{
+					ObjectMeta: metav1.ObjectMeta{Name: "cd"},
+				},
+			},
+			want: want{
+				cd: &fake.Composed{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "cd",
+						Labels: map[string]string{"Test": "default"},
+					},
+				},
+			},
+		},
+		"TransformFailureMissingDefault": {
+			reason: "A patch should return an error when one of its transforms fails and its policy is to use a default it doesn't specify",
+			args: args{
+				patch: Patch{
+					Type:        PatchTypeCombineFromComposite,
+					ToFieldPath: pointer.StringPtr("objectMeta.labels.Test"),
+					Transforms:  failing,
+					Policy:      policy(TransformFailureUseDefault, ""),
+					Combine: &Combine{
+						Variables: []CombineVariable{{FromFieldPath: "objectMeta.labels.Test"}},
+						Strategy:  CombineStrategyString,
+						String:    &StringCombine{Format: "%s"},
+					},
+				},
+				cp: &fake.Composite{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "cp",
+						Labels: map[string]string{"Test": "blah"},
+					},
+					ConnectionDetailsLastPublishedTimer: lpt,
+				},
+				cd: &fake.Composed{
+					ObjectMeta: metav1.ObjectMeta{Name: "cd"},
+				},
+			},
+			want: want{
+				err: errors.Errorf(errFmtRequiredField, "TransformFailureDefault", TransformFailureUseDefault),
+			},
+		},
 		"MissingOptionalFieldPath": {
 			reason: "A FromFieldPath patch should be a no-op when an optional fromFieldPath doesn't exist",
 			args: args{
@@ -992,6 +1114,49 @@ func TestComposedTemplates(t *testing.T) {
 				},
 			},
 		},
+		"PropagateMetadata": {
+			reason: "Propagated labels and annotations should be patched from the composite resource before any other patches.",
+			args: args{
+				cs: CompositionSpec{
+					PropagateMetadata: &MetadataPropagation{
+						Labels:      []string{"example.org/cost-center"},
+						Annotations: []string{"example.org/owner"},
+					},
+					Resources: []ComposedTemplate{
+						{
+							Patches: []Patch{
+								{
+									Type:          PatchTypeFromCompositeFieldPath,
+									FromFieldPath: pointer.StringPtr("metadata.name"),
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				ct: []ComposedTemplate{
+					{
+						Patches: []Patch{
+							{
+								Type:          PatchTypeFromCompositeFieldPath,
+								FromFieldPath: pointer.StringPtr("metadata.labels[example.org/cost-center]"),
+								ToFieldPath:   pointer.StringPtr("metadata.labels[example.org/cost-center]"),
+							},
+							{
+								Type:          PatchTypeFromCompositeFieldPath,
+								FromFieldPath: pointer.StringPtr("metadata.annotations[example.org/owner]"),
+								ToFieldPath:   pointer.StringPtr("metadata.annotations[example.org/owner]"),
+							},
+							{
+								Type:          PatchTypeFromCompositeFieldPath,
+								FromFieldPath: pointer.StringPtr("metadata.name"),
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -1007,3 +1172,66 @@ func TestComposedTemplates(t *testing.T) {
 		})
 	}
 }
+
+func TestComposedTemplatesForVersion(t *testing.T) {
+	name := pointer.StringPtr("a")
+	base := []Patch{{
+		Type:          PatchTypeFromCompositeFieldPath,
+		FromFieldPath: pointer.StringPtr("spec.size"),
+	}}
+	override := []Patch{{
+		Type:          PatchTypeFromCompositeFieldPath,
+		FromFieldPath: pointer.StringPtr("spec.parameters.size"),
+	}}
+
+	cs := CompositionSpec{
+		CompositeTypeRef: TypeReference{APIVersion: "example.org/v1beta1", Kind: "XR"},
+		CompositeTypeVersions: []CompositeTypeVersion{{
+			APIVersion: "example.org/v1",
+			PatchOverrides: []PatchOverride{{
+				ResourceName: "a",
+				Patches:      override,
+			}},
+		}},
+		Resources: []ComposedTemplate{{Name: name, Patches: base}},
+	}
+
+	type args struct {
+		apiVersion string
+	}
+
+	type want struct {
+		ct  []ComposedTemplate
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args
+		want
+	}{
+		"CompositeTypeRefVersion": {
+			reason: "A resource template's own patches should be used for the CompositeTypeRef's version.",
+			args:   args{apiVersion: "example.org/v1beta1"},
+			want:   want{ct: []ComposedTemplate{{Name: name, Patches: base}}},
+		},
+		"OverriddenVersion": {
+			reason: "A resource template's patches should be replaced for a version that overrides them.",
+			args:   args{apiVersion: "example.org/v1"},
+			want:   want{ct: []ComposedTemplate{{Name: name, Patches: override}}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := cs.ComposedTemplatesForVersion(tc.args.apiVersion)
+
+			if diff := cmp.Diff(tc.want.ct, got); diff != "" {
+				t.Errorf("\n%s\nrs.ComposedTemplatesForVersion(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nrs.ComposedTemplatesForVersion(...)): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
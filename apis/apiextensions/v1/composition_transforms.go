@@ -17,20 +17,37 @@ limitations under the License.
 package v1
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+	"sigs.k8s.io/yaml"
 )
 
 const (
 	errMathNoMultiplier   = "no input is given"
 	errMathInputNonNumber = "input is required to be a number for math transformer"
+	errMathDivideByZero   = "math transform cannot divide by zero"
+
+	errFmtMathTransformTypeFailed     = "type %s is not supported for math transform type"
+	errFmtMathTransformOperandMissing = "math transform of type %s requires a value to be set"
+	errFmtMathOverflow                = "math transform of type %s overflows"
+
+	errConvertNoToType = "convert transform requires either toType or format to be set"
 
 	errFmtConvertInputTypeNotSupported = "input type %s is not supported"
+	errFmtConvertFormatNotSupported    = "format %s is not supported"
+	errFmtConvertFormatInputNotString  = "input is required to be a string for format %s"
+	errFmtConvertFormatFailed          = "cannot convert input using format %s"
 	errFmtConversionPairNotSupported   = "conversion from %s to %s is not supported"
 	errFmtTransformAtIndex             = "transform at index %d returned error"
 	errFmtTypeNotSupported             = "transform type %s is not supported"
@@ -39,6 +56,16 @@ const (
 	errFmtMapTypeNotSupported          = "type %s is not supported for map transform"
 	errFmtMapNotFound                  = "key %s is not found in map"
 
+	errMatchInvalidRegexp         = "cannot compile regexp"
+	errFmtMatchTypeNotSupported   = "type %s is not supported for match transform"
+	errFmtMatchNotFound           = "no pattern matches %s and no fallback value is set"
+	errFmtMatchPatternAtIndex     = "pattern at index %d returned error"
+	errFmtMatchPatternTypeFailed  = "type %s is not supported for match pattern type"
+	errFmtMatchPatternTypeLiteral = "match pattern of type %s literal is not set"
+	errFmtMatchPatternTypeRegexp  = "match pattern of type %s regexp is not set"
+	errFmtMatchResult             = "cannot unmarshal result of pattern at index %d"
+	errMatchFallbackValue         = "cannot unmarshal match fallback value"
+
 	errStringTransformTypeFailed  = "type %s is not supported for string transform type"
 	errStringTransformTypeFormat  = "string transform of type %s fmt is not set"
 	errStringTransformTypeConvert = "string transform of type %s convert is not set"
@@ -52,6 +79,7 @@ type TransformType string
 // Accepted TransformTypes.
 const (
 	TransformTypeMap     TransformType = "map"
+	TransformTypeMatch   TransformType = "match"
 	TransformTypeMath    TransformType = "math"
 	TransformTypeString  TransformType = "string"
 	TransformTypeConvert TransformType = "convert"
@@ -62,11 +90,11 @@ const (
 type Transform struct {
 
 	// Type of the transform to be run.
-	// +kubebuilder:validation:Enum=map;math;string;convert
+	// +kubebuilder:validation:Enum=map;match;math;string;convert
 	Type TransformType `json:"type"`
 
 	// Math is used to transform the input via mathematical operations such as
-	// multiplication.
+	// multiplication, addition, or clamping.
 	// +optional
 	Math *MathTransform `json:"math,omitempty"`
 
@@ -74,6 +102,14 @@ type Transform struct {
 	// +optional
 	Map *MapTransform `json:"map,omitempty"`
 
+	// Match is a more flexible version of Map. It matches the input against
+	// an ordered list of literal or regular expression patterns, and returns
+	// a fallback value if no pattern matches. Match is a separate transform
+	// because Map is an arbitrary map of input to output values, which leaves
+	// no room for patterns or a fallback value.
+	// +optional
+	Match *MatchTransform `json:"match,omitempty"`
+
 	// String is used to transform the input into a string or a different kind
 	// of string. Note that the input does not necessarily need to be a string.
 	// +optional
@@ -94,6 +130,8 @@ func (t *Transform) Transform(input interface{}) (interface{}, error) {
 		transformer = t.Math
 	case TransformTypeMap:
 		transformer = t.Map
+	case TransformTypeMatch:
+		transformer = t.Match
 	case TransformTypeString:
 		transformer = t.String
 	case TransformTypeConvert:
@@ -111,24 +149,213 @@ func (t *Transform) Transform(input interface{}) (interface{}, error) {
 	return out, errors.Wrapf(err, errFmtTransformTypeFailed, string(t.Type))
 }
 
+// MathTransformType is type of the math transform function to be executed.
+type MathTransformType string
+
+// Accepted MathTransformTypes.
+const (
+	MathTransformTypeMultiply MathTransformType = "Multiply" // Default
+	MathTransformTypeAdd      MathTransformType = "Add"
+	MathTransformTypeSubtract MathTransformType = "Subtract"
+	MathTransformTypeDivide   MathTransformType = "Divide"
+	MathTransformTypeModulo   MathTransformType = "Modulo"
+	MathTransformTypeClampMin MathTransformType = "ClampMin"
+	MathTransformTypeClampMax MathTransformType = "ClampMax"
+	MathTransformTypeRound    MathTransformType = "Round"
+)
+
 // MathTransform conducts mathematical operations on the input with the given
 // configuration in its properties.
 type MathTransform struct {
+	// Type of the math transform to be run. Integer inputs produce integer
+	// outputs, while float inputs produce float outputs. Round always produces
+	// an integer.
+	// +optional
+	// +kubebuilder:validation:Enum=Multiply;Add;Subtract;Divide;Modulo;ClampMin;ClampMax;Round
+	// +kubebuilder:default=Multiply
+	Type MathTransformType `json:"type,omitempty"`
+
 	// Multiply the value.
 	// +optional
 	Multiply *int64 `json:"multiply,omitempty"`
+
+	// Add to the value.
+	// +optional
+	Add *int64 `json:"add,omitempty"`
+
+	// Subtract from the value.
+	// +optional
+	Subtract *int64 `json:"subtract,omitempty"`
+
+	// Divide the value. Integer division truncates toward zero.
+	// +optional
+	Divide *int64 `json:"divide,omitempty"`
+
+	// Modulo returns the remainder of dividing the value.
+	// +optional
+	Modulo *int64 `json:"modulo,omitempty"`
+
+	// ClampMin sets the minimum value of the output.
+	// +optional
+	ClampMin *int64 `json:"clampMin,omitempty"`
+
+	// ClampMax sets the maximum value of the output.
+	// +optional
+	ClampMax *int64 `json:"clampMax,omitempty"`
 }
 
 // Resolve runs the Math transform.
 func (m *MathTransform) Resolve(input interface{}) (interface{}, error) {
-	if m.Multiply == nil {
-		return nil, errors.New(errMathNoMultiplier)
+	t := m.Type
+	if t == "" {
+		t = MathTransformTypeMultiply
+	}
+
+	if t == MathTransformTypeRound {
+		return mathRound(input)
+	}
+
+	operand, err := m.operand(t)
+	if err != nil {
+		return nil, err
+	}
+
+	switch i := input.(type) {
+	case int64:
+		return mathInt64(t, i, operand)
+	case int:
+		return mathInt64(t, int64(i), operand)
+	case float64:
+		return mathFloat64(t, i, float64(operand))
+	default:
+		return nil, errors.New(errMathInputNonNumber)
+	}
+}
+
+func (m *MathTransform) operand(t MathTransformType) (int64, error) {
+	var o *int64
+	switch t {
+	case MathTransformTypeMultiply:
+		if m.Multiply == nil {
+			return 0, errors.New(errMathNoMultiplier)
+		}
+		o = m.Multiply
+	case MathTransformTypeAdd:
+		o = m.Add
+	case MathTransformTypeSubtract:
+		o = m.Subtract
+	case MathTransformTypeDivide:
+		o = m.Divide
+	case MathTransformTypeModulo:
+		o = m.Modulo
+	case MathTransformTypeClampMin:
+		o = m.ClampMin
+	case MathTransformTypeClampMax:
+		o = m.ClampMax
+	default:
+		return 0, errors.Errorf(errFmtMathTransformTypeFailed, string(t))
+	}
+	if o == nil {
+		return 0, errors.Errorf(errFmtMathTransformOperandMissing, string(t))
+	}
+	return *o, nil
+}
+
+func mathInt64(t MathTransformType, i, o int64) (interface{}, error) { // nolint:gocyclo
+	switch t {
+	case MathTransformTypeMultiply:
+		r := i * o
+		if i != 0 && (r/i != o || (i == -1 && o == math.MinInt64)) {
+			return nil, errors.Errorf(errFmtMathOverflow, string(t))
+		}
+		return r, nil
+	case MathTransformTypeAdd:
+		r := i + o
+		if (o > 0 && r < i) || (o < 0 && r > i) {
+			return nil, errors.Errorf(errFmtMathOverflow, string(t))
+		}
+		return r, nil
+	case MathTransformTypeSubtract:
+		r := i - o
+		if (o > 0 && r > i) || (o < 0 && r < i) {
+			return nil, errors.Errorf(errFmtMathOverflow, string(t))
+		}
+		return r, nil
+	case MathTransformTypeDivide:
+		if o == 0 {
+			return nil, errors.New(errMathDivideByZero)
+		}
+		if i == math.MinInt64 && o == -1 {
+			return nil, errors.Errorf(errFmtMathOverflow, string(t))
+		}
+		return i / o, nil
+	case MathTransformTypeModulo:
+		if o == 0 {
+			return nil, errors.New(errMathDivideByZero)
+		}
+		if o == -1 {
+			return int64(0), nil
+		}
+		return i % o, nil
+	case MathTransformTypeClampMin:
+		if i < o {
+			return o, nil
+		}
+		return i, nil
+	case MathTransformTypeClampMax:
+		if i > o {
+			return o, nil
+		}
+		return i, nil
+	}
+	return nil, errors.Errorf(errFmtMathTransformTypeFailed, string(t))
+}
+
+func mathFloat64(t MathTransformType, i, o float64) (interface{}, error) {
+	var r float64
+	switch t {
+	case MathTransformTypeMultiply:
+		r = i * o
+	case MathTransformTypeAdd:
+		r = i + o
+	case MathTransformTypeSubtract:
+		r = i - o
+	case MathTransformTypeDivide:
+		if o == 0 {
+			return nil, errors.New(errMathDivideByZero)
+		}
+		r = i / o
+	case MathTransformTypeModulo:
+		if o == 0 {
+			return nil, errors.New(errMathDivideByZero)
+		}
+		r = math.Mod(i, o)
+	case MathTransformTypeClampMin:
+		r = math.Max(i, o)
+	case MathTransformTypeClampMax:
+		r = math.Min(i, o)
+	default:
+		return nil, errors.Errorf(errFmtMathTransformTypeFailed, string(t))
 	}
+	if math.IsInf(r, 0) || math.IsNaN(r) {
+		return nil, errors.Errorf(errFmtMathOverflow, string(t))
+	}
+	return r, nil
+}
+
+func mathRound(input interface{}) (interface{}, error) {
 	switch i := input.(type) {
 	case int64:
-		return *m.Multiply * i, nil
+		return i, nil
 	case int:
-		return *m.Multiply * int64(i), nil
+		return int64(i), nil
+	case float64:
+		r := math.Round(i)
+		// float64(math.MaxInt64) rounds up to 2^63, which does not fit.
+		if math.IsNaN(r) || r < math.MinInt64 || r >= math.MaxInt64 {
+			return nil, errors.Errorf(errFmtMathOverflow, string(MathTransformTypeRound))
+		}
+		return int64(r), nil
 	default:
 		return nil, errors.New(errMathInputNonNumber)
 	}
@@ -174,6 +401,144 @@ func (m *MapTransform) Resolve(input interface{}) (interface{}, error) {
 	}
 }
 
+// MatchTransformPatternType is the type of a MatchTransformPattern.
+type MatchTransformPatternType string
+
+// Accepted MatchTransformPatternTypes.
+const (
+	MatchTransformPatternTypeLiteral MatchTransformPatternType = "Literal" // Default
+	MatchTransformPatternTypeRegexp  MatchTransformPatternType = "Regexp"
+)
+
+// A MatchTransformPattern is a pattern that can be matched against the input
+// of a MatchTransform.
+type MatchTransformPattern struct {
+	// Type specifies how the pattern matches the input.
+	// +optional
+	// +kubebuilder:validation:Enum=Literal;Regexp
+	// +kubebuilder:default=Literal
+	Type MatchTransformPatternType `json:"type,omitempty"`
+
+	// Literal exactly matches the input. Required if type is Literal.
+	// +optional
+	Literal *string `json:"literal,omitempty"`
+
+	// Regexp is a regular expression that is matched against the input.
+	// Required if type is Regexp. See https://github.com/google/re2/wiki/Syntax
+	// for the supported syntax.
+	// +optional
+	Regexp *string `json:"regexp,omitempty"`
+
+	// Result is returned if the pattern matches the input. It may be any
+	// JSON value, for example a string, number, or object.
+	Result extv1.JSON `json:"result"`
+}
+
+// Matches returns true if the pattern matches the supplied input.
+func (p *MatchTransformPattern) Matches(input string) (bool, error) {
+	switch p.Type {
+	case MatchTransformPatternTypeLiteral, "":
+		if p.Literal == nil {
+			return false, errors.Errorf(errFmtMatchPatternTypeLiteral, string(MatchTransformPatternTypeLiteral))
+		}
+		return *p.Literal == input, nil
+	case MatchTransformPatternTypeRegexp:
+		if p.Regexp == nil {
+			return false, errors.Errorf(errFmtMatchPatternTypeRegexp, string(MatchTransformPatternTypeRegexp))
+		}
+		re, err := regexps.Compile(*p.Regexp)
+		if err != nil {
+			return false, errors.Wrap(err, errMatchInvalidRegexp)
+		}
+		return re.MatchString(input), nil
+	default:
+		return false, errors.Errorf(errFmtMatchPatternTypeFailed, string(p.Type))
+	}
+}
+
+// MaxCachedRegexps is the maximum number of compiled match transform regular
+// expressions that are cached.
+const MaxCachedRegexps = 1024
+
+// Match transforms are resolved for each composed resource each time a
+// composite resource is reconciled, from a Composition that is read afresh, so
+// we cache compiled regular expressions rather than compiling them every time.
+var regexps = &regexpCache{max: MaxCachedRegexps, cache: map[string]*regexp.Regexp{}}
+
+// A regexpCache caches compiled regular expressions. It is emptied when it
+// reaches its maximum size, so that expressions that are no longer used are
+// eventually evicted.
+// +kubebuilder:object:generate=false
+type regexpCache struct {
+	mu    sync.RWMutex
+	max   int
+	cache map[string]*regexp.Regexp
+}
+
+// Compile the supplied regular expression, or return it from the cache if it
+// was already compiled.
+func (c *regexpCache) Compile(expr string) (*regexp.Regexp, error) {
+	c.mu.RLock()
+	re, ok := c.cache[expr]
+	c.mu.RUnlock()
+	if ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.cache) >= c.max {
+		c.cache = map[string]*regexp.Regexp{}
+	}
+	c.cache[expr] = re
+	return re, nil
+}
+
+// MatchTransform returns the result of the first pattern that matches the
+// input, or the fallback value if no pattern matches.
+type MatchTransform struct {
+	// Patterns are matched against the input in order. The result of the
+	// first pattern that matches is returned.
+	// +optional
+	Patterns []MatchTransformPattern `json:"patterns,omitempty"`
+
+	// FallbackValue is returned if no pattern matches the input. Like a
+	// pattern's result it may be any JSON value. The transform returns an
+	// error if no pattern matches and no fallback value is set.
+	// +optional
+	FallbackValue *extv1.JSON `json:"fallbackValue,omitempty"`
+}
+
+// Resolve runs the Match transform.
+func (m *MatchTransform) Resolve(input interface{}) (interface{}, error) {
+	i, ok := input.(string)
+	if !ok {
+		return nil, errors.Errorf(errFmtMatchTypeNotSupported, reflect.TypeOf(input).String())
+	}
+	for idx := range m.Patterns {
+		ok, err := m.Patterns[idx].Matches(i)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtMatchPatternAtIndex, idx)
+		}
+		if ok {
+			var out interface{}
+			err := utiljson.Unmarshal(m.Patterns[idx].Result.Raw, &out)
+			return out, errors.Wrapf(err, errFmtMatchResult, idx)
+		}
+	}
+	if m.FallbackValue == nil {
+		return nil, errors.Errorf(errFmtMatchNotFound, i)
+	}
+	var out interface{}
+	err := utiljson.Unmarshal(m.FallbackValue.Raw, &out)
+	return out, errors.Wrap(err, errMatchFallbackValue)
+}
+
 // StringTransformType is type of the string transform function to be executed fmt/convert.
 type StringTransformType string
 
@@ -280,11 +645,35 @@ type conversionPair struct {
 	To   string
 }
 
+// ConvertTransformFormat is the format a ConvertTransform serializes the
+// input to, or deserializes the input from.
+type ConvertTransformFormat string
+
+// Accepted ConvertTransformFormats.
+const (
+	ConvertTransformFormatToJSON     ConvertTransformFormat = "ToJSON"
+	ConvertTransformFormatFromJSON   ConvertTransformFormat = "FromJSON"
+	ConvertTransformFormatToYAML     ConvertTransformFormat = "ToYAML"
+	ConvertTransformFormatFromYAML   ConvertTransformFormat = "FromYAML"
+	ConvertTransformFormatToBase64   ConvertTransformFormat = "ToBase64"
+	ConvertTransformFormatFromBase64 ConvertTransformFormat = "FromBase64"
+)
+
 // A ConvertTransform converts the input into a new object whose type is supplied.
 type ConvertTransform struct {
-	// ToType is the type of the output of this transform.
+	// ToType is the type of the output of this transform. Required unless
+	// format is set.
+	// +optional
 	// +kubebuilder:validation:Enum=string;int;int64;bool;float64
-	ToType string `json:"toType"`
+	ToType string `json:"toType,omitempty"`
+
+	// Format the input is serialized to or deserialized from. ToJSON and
+	// ToYAML serialize any input to a string, while FromJSON and FromYAML
+	// deserialize a string input into an object. ToBase64 and FromBase64
+	// encode and decode a string input. ToType is ignored if format is set.
+	// +optional
+	// +kubebuilder:validation:Enum=ToJSON;FromJSON;ToYAML;FromYAML;ToBase64;FromBase64
+	Format *ConvertTransformFormat `json:"format,omitempty"`
 }
 
 var conversions = map[conversionPair]func(interface{}) (interface{}, error){
@@ -335,8 +724,14 @@ var conversions = map[conversionPair]func(interface{}) (interface{}, error){
 	},
 }
 
-// Resolve runs the String transform.
+// Resolve runs the Convert transform.
 func (s *ConvertTransform) Resolve(input interface{}) (interface{}, error) {
+	if s.Format != nil {
+		return convertFormat(input, *s.Format)
+	}
+	if s.ToType == "" {
+		return nil, errors.New(errConvertNoToType)
+	}
 	from := reflect.TypeOf(input).Kind().String()
 	if from == ConvertTransformTypeInt {
 		from = ConvertTransformTypeInt64
@@ -359,3 +754,56 @@ func (s *ConvertTransform) Resolve(input interface{}) (interface{}, error) {
 	}
 	return f(input)
 }
+
+func convertFormat(input interface{}, f ConvertTransformFormat) (interface{}, error) {
+	switch f {
+	case ConvertTransformFormatToJSON:
+		b, err := json.Marshal(input)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtConvertFormatFailed, string(f))
+		}
+		return string(b), nil
+	case ConvertTransformFormatToYAML:
+		b, err := yaml.Marshal(input)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtConvertFormatFailed, string(f))
+		}
+		return string(b), nil
+	}
+
+	str, ok := input.(string)
+	if !ok {
+		return nil, errors.Errorf(errFmtConvertFormatInputNotString, string(f))
+	}
+
+	switch f {
+	case ConvertTransformFormatFromJSON:
+		var out interface{}
+		// We use the apimachinery JSON package so that numbers are decoded as
+		// int64 or float64, the same as they are in unstructured objects.
+		if err := utiljson.Unmarshal([]byte(str), &out); err != nil {
+			return nil, errors.Wrapf(err, errFmtConvertFormatFailed, string(f))
+		}
+		return out, nil
+	case ConvertTransformFormatFromYAML:
+		j, err := yaml.YAMLToJSON([]byte(str))
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtConvertFormatFailed, string(f))
+		}
+		var out interface{}
+		if err := utiljson.Unmarshal(j, &out); err != nil {
+			return nil, errors.Wrapf(err, errFmtConvertFormatFailed, string(f))
+		}
+		return out, nil
+	case ConvertTransformFormatToBase64:
+		return base64.StdEncoding.EncodeToString([]byte(str)), nil
+	case ConvertTransformFormatFromBase64:
+		b, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtConvertFormatFailed, string(f))
+		}
+		return string(b), nil
+	default:
+		return nil, errors.Errorf(errFmtConvertFormatNotSupported, string(f))
+	}
+}
@@ -17,11 +17,15 @@ limitations under the License.
 package v1
 
 import (
+	"encoding/base64"
+	"math"
 	"reflect"
+	"regexp"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 )
@@ -80,12 +84,197 @@ func TestMapResolve(t *testing.T) {
 	}
 }
 
+func TestMatchResolve(t *testing.T) {
+	usEast := "us-east-1"
+	euPrefix := "^eu-"
+	invalid := "("
+	str := func(s string) extv1.JSON { return extv1.JSON{Raw: []byte(`"` + s + `"`)} }
+	fallback := str("ami-default")
+
+	type args struct {
+		patterns []MatchTransformPattern
+		fallback *extv1.JSON
+		i        interface{}
+	}
+	type want struct {
+		o   interface{}
+		err error
+	}
+
+	cases := map[string]struct {
+		args
+		want
+	}{
+		"NonStringInput": {
+			args: args{
+				i: 5,
+			},
+			want: want{
+				err: errors.Errorf(errFmtMatchTypeNotSupported, "int"),
+			},
+		},
+		"NoMatchNoFallback": {
+			args: args{
+				patterns: []MatchTransformPattern{{Literal: &usEast, Result: str("ami-east")}},
+				i:        "us-west-1",
+			},
+			want: want{
+				err: errors.Errorf(errFmtMatchNotFound, "us-west-1"),
+			},
+		},
+		"NoMatchFallback": {
+			args: args{
+				patterns: []MatchTransformPattern{{Literal: &usEast, Result: str("ami-east")}},
+				fallback: &fallback,
+				i:        "us-west-1",
+			},
+			want: want{
+				o: "ami-default",
+			},
+		},
+		"LiteralMatch": {
+			args: args{
+				patterns: []MatchTransformPattern{
+					{Type: MatchTransformPatternTypeRegexp, Regexp: &euPrefix, Result: str("ami-eu")},
+					{Type: MatchTransformPatternTypeLiteral, Literal: &usEast, Result: str("ami-east")},
+				},
+				fallback: &fallback,
+				i:        usEast,
+			},
+			want: want{
+				o: "ami-east",
+			},
+		},
+		"RegexpMatch": {
+			args: args{
+				patterns: []MatchTransformPattern{
+					{Type: MatchTransformPatternTypeLiteral, Literal: &usEast, Result: str("ami-east")},
+					{Type: MatchTransformPatternTypeRegexp, Regexp: &euPrefix, Result: str("ami-eu")},
+				},
+				i: "eu-central-1",
+			},
+			want: want{
+				o: "ami-eu",
+			},
+		},
+		"FirstMatchWins": {
+			args: args{
+				patterns: []MatchTransformPattern{
+					{Type: MatchTransformPatternTypeRegexp, Regexp: &euPrefix, Result: str("ami-eu")},
+					{Type: MatchTransformPatternTypeRegexp, Regexp: &euPrefix, Result: str("ami-other")},
+				},
+				i: "eu-central-1",
+			},
+			want: want{
+				o: "ami-eu",
+			},
+		},
+		"NonStringResult": {
+			args: args{
+				patterns: []MatchTransformPattern{{Literal: &usEast, Result: extv1.JSON{Raw: []byte(`{"size":3}`)}}},
+				fallback: &extv1.JSON{Raw: []byte(`1`)},
+				i:        usEast,
+			},
+			want: want{
+				o: map[string]interface{}{"size": int64(3)},
+			},
+		},
+		"NonStringFallback": {
+			args: args{
+				patterns: []MatchTransformPattern{{Literal: &usEast, Result: str("ami-east")}},
+				fallback: &extv1.JSON{Raw: []byte(`1`)},
+				i:        "us-west-1",
+			},
+			want: want{
+				o: int64(1),
+			},
+		},
+		"LiteralNotSet": {
+			args: args{
+				patterns: []MatchTransformPattern{{Result: str("ami-east")}},
+				i:        usEast,
+			},
+			want: want{
+				err: errors.Wrapf(errors.Errorf(errFmtMatchPatternTypeLiteral, string(MatchTransformPatternTypeLiteral)), errFmtMatchPatternAtIndex, 0),
+			},
+		},
+		"RegexpNotSet": {
+			args: args{
+				patterns: []MatchTransformPattern{{Type: MatchTransformPatternTypeRegexp, Result: str("ami-east")}},
+				i:        usEast,
+			},
+			want: want{
+				err: errors.Wrapf(errors.Errorf(errFmtMatchPatternTypeRegexp, string(MatchTransformPatternTypeRegexp)), errFmtMatchPatternAtIndex, 0),
+			},
+		},
+		"InvalidRegexp": {
+			args: args{
+				patterns: []MatchTransformPattern{{Type: MatchTransformPatternTypeRegexp, Regexp: &invalid, Result: str("ami-east")}},
+				i:        usEast,
+			},
+			want: want{
+				err: errors.Wrapf(errors.Wrap(func() error { _, err := regexp.Compile(invalid); return err }(), errMatchInvalidRegexp), errFmtMatchPatternAtIndex, 0),
+			},
+		},
+		"PatternTypeNotSupported": {
+			args: args{
+				patterns: []MatchTransformPattern{{Type: "Glob", Result: str("ami-east")}},
+				i:        usEast,
+			},
+			want: want{
+				err: errors.Wrapf(errors.Errorf(errFmtMatchPatternTypeFailed, "Glob"), errFmtMatchPatternAtIndex, 0),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := (&MatchTransform{Patterns: tc.patterns, FallbackValue: tc.fallback}).Resolve(tc.i)
+
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("Resolve(b): -want, +got:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("Resolve(b): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRegexpCacheCompile(t *testing.T) {
+	c := &regexpCache{max: 2, cache: map[string]*regexp.Regexp{}}
+
+	a, err := c.Compile("^a")
+	if err != nil {
+		t.Fatalf("Compile(...): %s", err)
+	}
+	if got, _ := c.Compile("^a"); got != a {
+		t.Errorf("Compile(...): want cached regexp, got a newly compiled regexp")
+	}
+	if _, err := c.Compile("("); err == nil {
+		t.Errorf("Compile(...): want error compiling invalid regexp, got nil")
+	}
+
+	// Caching a third regexp should empty the cache before adding it.
+	if _, err := c.Compile("^b"); err != nil {
+		t.Fatalf("Compile(...): %s", err)
+	}
+	if _, err := c.Compile("^c"); err != nil {
+		t.Fatalf("Compile(...): %s", err)
+	}
+	if diff := cmp.Diff(1, len(c.cache)); diff != "" {
+		t.Errorf("len(cache): -want, +got:\n%s", diff)
+	}
+}
+
 func TestMathResolve(t *testing.T) {
 	m := int64(2)
+	zero := int64(0)
+	negOne := int64(-1)
+	maxInt := int64(math.MaxInt64)
 
 	type args struct {
-		multiplier *int64
-		i          interface{}
+		mt MathTransform
+		i  interface{}
 	}
 	type want struct {
 		o   interface{}
@@ -106,8 +295,8 @@ func TestMathResolve(t *testing.T) {
 		},
 		"NonNumberInput": {
 			args: args{
-				multiplier: &m,
-				i:          "ola",
+				mt: MathTransform{Multiply: &m},
+				i:  "ola",
 			},
 			want: want{
 				err: errors.New(errMathInputNonNumber),
@@ -115,8 +304,8 @@ func TestMathResolve(t *testing.T) {
 		},
 		"Success": {
 			args: args{
-				multiplier: &m,
-				i:          3,
+				mt: MathTransform{Multiply: &m},
+				i:  3,
 			},
 			want: want{
 				o: 3 * m,
@@ -124,17 +313,179 @@ func TestMathResolve(t *testing.T) {
 		},
 		"SuccessInt64": {
 			args: args{
-				multiplier: &m,
-				i:          int64(3),
+				mt: MathTransform{Multiply: &m},
+				i:  int64(3),
 			},
 			want: want{
 				o: 3 * m,
 			},
 		},
+		"SuccessFloat64": {
+			args: args{
+				mt: MathTransform{Multiply: &m},
+				i:  1.5,
+			},
+			want: want{
+				o: 3.0,
+			},
+		},
+		"UnsupportedType": {
+			args: args{
+				mt: MathTransform{Type: "Pow"},
+				i:  3,
+			},
+			want: want{
+				err: errors.Errorf(errFmtMathTransformTypeFailed, "Pow"),
+			},
+		},
+		"OperandMissing": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeAdd, Multiply: &m},
+				i:  3,
+			},
+			want: want{
+				err: errors.Errorf(errFmtMathTransformOperandMissing, string(MathTransformTypeAdd)),
+			},
+		},
+		"MultiplyOverflow": {
+			args: args{
+				mt: MathTransform{Multiply: &m},
+				i:  maxInt,
+			},
+			want: want{
+				err: errors.Errorf(errFmtMathOverflow, string(MathTransformTypeMultiply)),
+			},
+		},
+		"Add": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeAdd, Add: &m},
+				i:  3,
+			},
+			want: want{
+				o: int64(5),
+			},
+		},
+		"AddOverflow": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeAdd, Add: &m},
+				i:  maxInt,
+			},
+			want: want{
+				err: errors.Errorf(errFmtMathOverflow, string(MathTransformTypeAdd)),
+			},
+		},
+		"Subtract": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeSubtract, Subtract: &m},
+				i:  int64(3),
+			},
+			want: want{
+				o: int64(1),
+			},
+		},
+		"SubtractOverflow": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeSubtract, Subtract: &m},
+				i:  int64(math.MinInt64),
+			},
+			want: want{
+				err: errors.Errorf(errFmtMathOverflow, string(MathTransformTypeSubtract)),
+			},
+		},
+		"Divide": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeDivide, Divide: &m},
+				i:  7,
+			},
+			want: want{
+				o: int64(3),
+			},
+		},
+		"DivideFloat64": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeDivide, Divide: &m},
+				i:  7.0,
+			},
+			want: want{
+				o: 3.5,
+			},
+		},
+		"DivideByZero": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeDivide, Divide: &zero},
+				i:  7,
+			},
+			want: want{
+				err: errors.New(errMathDivideByZero),
+			},
+		},
+		"DivideOverflow": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeDivide, Divide: &negOne},
+				i:  int64(math.MinInt64),
+			},
+			want: want{
+				err: errors.Errorf(errFmtMathOverflow, string(MathTransformTypeDivide)),
+			},
+		},
+		"Modulo": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeModulo, Modulo: &m},
+				i:  7,
+			},
+			want: want{
+				o: int64(1),
+			},
+		},
+		"ModuloByZero": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeModulo, Modulo: &zero},
+				i:  7.0,
+			},
+			want: want{
+				err: errors.New(errMathDivideByZero),
+			},
+		},
+		"ClampMin": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeClampMin, ClampMin: &m},
+				i:  1,
+			},
+			want: want{
+				o: m,
+			},
+		},
+		"ClampMax": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeClampMax, ClampMax: &m},
+				i:  2.5,
+			},
+			want: want{
+				o: 2.0,
+			},
+		},
+		"Round": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeRound},
+				i:  2.5,
+			},
+			want: want{
+				o: int64(3),
+			},
+		},
+		"RoundOverflow": {
+			args: args{
+				mt: MathTransform{Type: MathTransformTypeRound},
+				i:  math.MaxFloat64,
+			},
+			want: want{
+				err: errors.Errorf(errFmtMathOverflow, string(MathTransformTypeRound)),
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			got, err := (&MathTransform{Multiply: tc.multiplier}).Resolve(tc.i)
+			got, err := tc.mt.Resolve(tc.i)
 
 			if diff := cmp.Diff(tc.want.o, got); diff != "" {
 				t.Errorf("Resolve(b): -want, +got:\n%s", diff)
@@ -310,6 +661,7 @@ func TestStringResolve(t *testing.T) {
 func TestConvertResolve(t *testing.T) {
 	type args struct {
 		ot string
+		f  ConvertTransformFormat
 		i  interface{}
 	}
 	type want struct {
@@ -357,6 +709,95 @@ func TestConvertResolve(t *testing.T) {
 				err: errors.Errorf(errFmtConvertInputTypeNotSupported, reflect.TypeOf([]int{}).Kind().String()),
 			},
 		},
+		"NoToType": {
+			args: args{
+				i: "true",
+			},
+			want: want{
+				err: errors.New(errConvertNoToType),
+			},
+		},
+		"ToJSON": {
+			args: args{
+				i: map[string]interface{}{"a": []interface{}{"b", int64(1)}},
+				f: ConvertTransformFormatToJSON,
+			},
+			want: want{
+				o: `{"a":["b",1]}`,
+			},
+		},
+		"FromJSON": {
+			args: args{
+				i: `{"a":["b",1,1.5]}`,
+				f: ConvertTransformFormatFromJSON,
+			},
+			want: want{
+				o: map[string]interface{}{"a": []interface{}{"b", int64(1), 1.5}},
+			},
+		},
+		"FromJSONInputNotString": {
+			args: args{
+				i: 1,
+				f: ConvertTransformFormatFromJSON,
+			},
+			want: want{
+				err: errors.Errorf(errFmtConvertFormatInputNotString, string(ConvertTransformFormatFromJSON)),
+			},
+		},
+		"ToYAML": {
+			args: args{
+				i: map[string]interface{}{"a": "b"},
+				f: ConvertTransformFormatToYAML,
+			},
+			want: want{
+				o: "a: b\n",
+			},
+		},
+		"FromYAML": {
+			args: args{
+				i: "a:\n- b\n- 1\n",
+				f: ConvertTransformFormatFromYAML,
+			},
+			want: want{
+				o: map[string]interface{}{"a": []interface{}{"b", int64(1)}},
+			},
+		},
+		"ToBase64": {
+			args: args{
+				i: "crossplane",
+				f: ConvertTransformFormatToBase64,
+			},
+			want: want{
+				o: "Y3Jvc3NwbGFuZQ==",
+			},
+		},
+		"FromBase64": {
+			args: args{
+				i: "Y3Jvc3NwbGFuZQ==",
+				f: ConvertTransformFormatFromBase64,
+			},
+			want: want{
+				o: "crossplane",
+			},
+		},
+		"FromBase64Invalid": {
+			args: args{
+				i: "crossplane!",
+				f: ConvertTransformFormatFromBase64,
+			},
+			want: want{
+				err: errors.Wrapf(base64.CorruptInputError(10), errFmtConvertFormatFailed, string(ConvertTransformFormatFromBase64)),
+			},
+		},
+		"FormatNotSupported": {
+			args: args{
+				i: "crossplane",
+				f: "ToXML",
+			},
+			want: want{
+				err: errors.Errorf(errFmtConvertFormatNotSupported, "ToXML"),
+			},
+		},
 		"ConversionPairNotSupported": {
 			args: args{
 				i:  "[64]",
@@ -369,7 +810,11 @@ func TestConvertResolve(t *testing.T) {
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			got, err := (&ConvertTransform{ToType: tc.args.ot}).Resolve(tc.i)
+			ct := &ConvertTransform{ToType: tc.args.ot}
+			if tc.args.f != "" {
+				ct.Format = &tc.args.f
+			}
+			got, err := ct.Resolve(tc.i)
 
 			if diff := cmp.Diff(tc.want.o, got); diff != "" {
 				t.Errorf("Resolve(b): -want, +got:\n%s", diff)
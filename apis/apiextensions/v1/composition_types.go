@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -31,14 +32,33 @@ type CompositionSpec struct {
 	// +immutable
 	CompositeTypeRef TypeReference `json:"compositeTypeRef"`
 
+	// CompositeTypeVersions lists additional versions of the kind of
+	// composite resource referenced by CompositeTypeRef that this
+	// composition is compatible with. Each version may override the patches
+	// of named resource templates, for example to account for fields that
+	// were renamed between versions.
+	// +optional
+	CompositeTypeVersions []CompositeTypeVersion `json:"compositeTypeVersions,omitempty"`
+
 	// PatchSets define a named set of patches that may be included by
 	// any resource in this Composition.
 	// PatchSets cannot themselves refer to other PatchSets.
 	// +optional
 	PatchSets []PatchSet `json:"patchSets,omitempty"`
 
+	// BaseCompositionRef references a Composition that this composition
+	// inherits from. The base's patch sets and resources are included in
+	// this composition, and may be overridden by a patch set or resource of
+	// the same name. Any other fields of the base are used only if this
+	// composition does not set them. The base must be compatible with the
+	// same type of composite resource.
+	// +optional
+	BaseCompositionRef *xpv1.Reference `json:"baseCompositionRef,omitempty"`
+
 	// Resources is the list of resource templates that will be used when a
-	// composite resource referring to this composition is created.
+	// composite resource referring to this composition is created. Resources
+	// may be omitted only if they are inherited from a base composition.
+	// +optional
 	Resources []ComposedTemplate `json:"resources"`
 
 	// WriteConnectionSecretsToNamespace specifies the namespace in which the
@@ -52,12 +72,67 @@ type CompositionSpec struct {
 	// +optional
 	WriteConnectionSecretsToNamespace *string `json:"writeConnectionSecretsToNamespace,omitempty"`
 
+	// WriteConnectionSecretsToNamespaceFrom derives the namespace in which the
+	// connection secrets of composite resources dynamically provisioned using
+	// this composition will be created by combining fields of the composite
+	// resource, for example its region or tenant. It takes precedence over
+	// WriteConnectionSecretsToNamespace, which is used instead if any of the
+	// combined fields are not found.
+	// +optional
+	WriteConnectionSecretsToNamespaceFrom *Combine `json:"writeConnectionSecretsToNamespaceFrom,omitempty"`
+
 	// PublishConnectionDetailsWithStoreConfig specifies the secret store config
 	// with which the connection secrets of composite resource dynamically
 	// provisioned using this composition will be published.
 	// +optional
 	// +kubebuilder:default={"name": "default"}
 	PublishConnectionDetailsWithStoreConfigRef *xpv1.Reference `json:"publishConnectionDetailsWithStoreConfigRef,omitempty"`
+
+	// PropagateMetadata specifies labels and annotations of the composite
+	// resource that are propagated to, and kept in sync on, every composed
+	// resource. Claim labels and annotations are propagated to the composite
+	// resource, so this may be used to propagate metadata like cost centers
+	// or owners from a claim to all of its composed resources.
+	// +optional
+	PropagateMetadata *MetadataPropagation `json:"propagateMetadata,omitempty"`
+}
+
+// A CompositeTypeVersion is an additional version of a composite resource
+// kind that a composition is compatible with.
+type CompositeTypeVersion struct {
+	// APIVersion of the composite resource, for example
+	// example.org/v1alpha1. It must be in the same API group as the
+	// CompositeTypeRef.
+	APIVersion string `json:"apiVersion"`
+
+	// PatchOverrides replace the patches of named resource templates when
+	// composing a composite resource of this version.
+	// +optional
+	PatchOverrides []PatchOverride `json:"patchOverrides,omitempty"`
+}
+
+// A PatchOverride replaces the patches of a named resource template.
+type PatchOverride struct {
+	// ResourceName is the name of the resource template whose patches are
+	// replaced.
+	ResourceName string `json:"resourceName"`
+
+	// Patches replace the resource template's patches.
+	Patches []Patch `json:"patches"`
+}
+
+// MetadataPropagation specifies which labels and annotations of a composite
+// resource are propagated to its composed resources.
+type MetadataPropagation struct {
+	// Labels is a list of the keys of composite resource labels that will be
+	// propagated to all composed resources.
+	// +optional
+	Labels []string `json:"labels,omitempty"`
+
+	// Annotations is a list of the keys of composite resource annotations
+	// that will be propagated to all composed resources.
+	// +optional
+	Annotations []string `json:"annotations,omitempty"`
 }
 
 // A PatchSet is a set of patches that can be reused from all resources within
@@ -84,6 +159,23 @@ func TypeReferenceTo(gvk schema.GroupVersionKind) TypeReference {
 	return TypeReference{APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind}
 }
 
+// CompatibleWith returns true if this composition is compatible with composite
+// resources of the supplied API version and kind.
+func (cs *CompositionSpec) CompatibleWith(apiVersion, kind string) bool {
+	if cs.CompositeTypeRef.Kind != kind {
+		return false
+	}
+	if cs.CompositeTypeRef.APIVersion == apiVersion {
+		return true
+	}
+	for _, v := range cs.CompositeTypeVersions {
+		if v.APIVersion == apiVersion {
+			return true
+		}
+	}
+	return false
+}
+
 // ComposedTemplate is used to provide information about how the composed resource
 // should be processed.
 type ComposedTemplate struct {
@@ -103,6 +195,15 @@ type ComposedTemplate struct {
 	// +kubebuilder:validation:EmbeddedResource
 	Base runtime.RawExtension `json:"base"`
 
+	// ObserveOnly causes this entry to observe an existing resource of the
+	// base's apiVersion and kind rather than composing a new one. Only the
+	// base's apiVersion and kind are used. An observed resource is never
+	// created, updated, or deleted, but its fields may be patched into the
+	// composite resource using ToCompositeFieldPath and CombineToComposite
+	// patches, and it may supply connection details and readiness checks.
+	// +optional
+	ObserveOnly *ObserveOnly `json:"observeOnly,omitempty"`
+
 	// Patches will be applied as overlay to the base resource.
 	// +optional
 	Patches []Patch `json:"patches,omitempty"`
@@ -117,6 +218,117 @@ type ComposedTemplate struct {
 	// default readiness check is to have the "Ready" condition to be "True".
 	// +optional
 	ReadinessChecks []ReadinessCheck `json:"readinessChecks,omitempty"`
+
+	// ReadinessTimeout determines what happens if this resource doesn't
+	// become ready in time. By default Crossplane waits indefinitely for every
+	// composed resource to become ready.
+	// +optional
+	ReadinessTimeout *ReadinessTimeout `json:"readinessTimeout,omitempty"`
+
+	// Gates that must be open before this resource is created. Each gate
+	// waits for another resource of the same composite resource to report a
+	// condition or field value. Gates are evaluated each time the composite
+	// resource is reconciled, until this resource has been created; they
+	// have no effect once it exists.
+	// +optional
+	Gates []Gate `json:"gates,omitempty"`
+}
+
+// ObserveOnly identifies the existing resource observed by an observe-only
+// resource template, either by its name or by its labels.
+type ObserveOnly struct {
+	// Name of the existing resource.
+	// +optional
+	Name *string `json:"name,omitempty"`
+
+	// Namespace of the existing resource. Required if the resource is
+	// namespaced and is identified by name. Resources identified by their
+	// labels are selected from all namespaces if no namespace is specified.
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+
+	// MatchLabels selects the existing resource by its labels. Exactly one
+	// resource must match.
+	// +optional
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+// A ReadinessFailurePolicy determines what happens when a composed resource
+// doesn't become ready before its readiness timeout.
+type ReadinessFailurePolicy string
+
+// Readiness failure policies.
+const (
+	// ReadinessFailureBlock keeps the composite resource unready until the
+	// composed resource becomes ready, and explains why in the composite
+	// resource's Ready condition.
+	ReadinessFailureBlock ReadinessFailurePolicy = "Block"
+
+	// ReadinessFailureContinue ignores the composed resource when determining
+	// whether the composite resource is ready.
+	ReadinessFailureContinue ReadinessFailurePolicy = "Continue"
+
+	// ReadinessFailureMarkDegraded marks the composite resource unavailable,
+	// rather than still being created, until the composed resource becomes
+	// ready.
+	ReadinessFailureMarkDegraded ReadinessFailurePolicy = "MarkDegraded"
+)
+
+// A ReadinessTimeout determines what happens when a composed resource doesn't
+// become ready in time.
+type ReadinessTimeout struct {
+	// Duration for which the composed resource may be unready before its
+	// failure policy applies. It is measured from when the resource's Ready
+	// condition last changed, or from when the resource was created if it
+	// has no Ready condition.
+	Duration metav1.Duration `json:"duration"`
+
+	// FailurePolicy determines what happens when the composed resource is
+	// unready for longer than the duration.
+	// +optional
+	// +kubebuilder:validation:Enum=Block;Continue;MarkDegraded
+	// +kubebuilder:default=Block
+	FailurePolicy *ReadinessFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// GetFailurePolicy returns the failure policy of this readiness timeout.
+// Timeouts that don't specify a policy block.
+func (t *ReadinessTimeout) GetFailurePolicy() ReadinessFailurePolicy {
+	if t.FailurePolicy == nil {
+		return ReadinessFailureBlock
+	}
+	return *t.FailurePolicy
+}
+
+// A Gate delays the creation of a composed resource until another composed
+// resource reports a condition or field value.
+type Gate struct {
+	// ResourceName is the name of the resource template whose composed
+	// resource this gate waits for.
+	ResourceName string `json:"resourceName"`
+
+	// Condition the resource must report for this gate to open.
+	// +optional
+	Condition *GateCondition `json:"condition,omitempty"`
+
+	// Checks the resource must pass for this gate to open. They behave like
+	// readiness checks. A gate that specifies neither a condition nor any
+	// checks opens when the resource's Ready condition is True.
+	// +optional
+	Checks []ReadinessCheck `json:"checks,omitempty"`
+}
+
+// A GateCondition is a condition a composed resource must report for a gate to
+// open.
+type GateCondition struct {
+	// Type of the condition, for example Synced.
+	Type xpv1.ConditionType `json:"type"`
+
+	// Status the condition must have.
+	// +optional
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	// +kubebuilder:default=True
+	Status corev1.ConditionStatus `json:"status,omitempty"`
 }
 
 // ReadinessCheckType is used for readiness check types.
@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errParseCompositeTypeRef          = "cannot parse spec.compositeTypeRef.apiVersion"
+	errFmtParseCompositeTypeVersion   = "cannot parse spec.compositeTypeVersions[%d].apiVersion"
+	errFmtCompositeTypeVersionGroup   = "spec.compositeTypeVersions[%d].apiVersion must be in API group %q"
+	errFmtCompositeTypeVersionDup     = "spec.compositeTypeVersions[%d].apiVersion %q is already declared"
+	errFmtPatchOverrideUnknown        = "spec.compositeTypeVersions[%d].patchOverrides[%d] overrides unknown resource %q"
+	errFmtPatchOverrideDup            = "spec.compositeTypeVersions[%d].patchOverrides[%d] overrides resource %q more than once"
+	errFmtInvalidCompositeTypeVersion = "invalid patches for spec.compositeTypeVersions[%d]"
+	errFmtObserveOnlySelector         = "spec.resources[%d].observeOnly must specify exactly one of name and matchLabels"
+	errFmtObserveOnlyPatch            = "spec.resources[%d].patches[%d] is a %s patch, but observe-only resources are never written; only ToCompositeFieldPath, CombineToComposite, and PatchSet patches may be used"
+	errFmtObserveOnlyGates            = "spec.resources[%d].gates must not be specified, because observe-only resources are never created"
+	errFmtGateResourceUnknown         = "spec.resources[%d].gates[%d] waits for unknown resource %q"
+	errFmtGateResourceSelf            = "spec.resources[%d].gates[%d] must not wait for the resource it gates"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-apiextensions-crossplane-io-v1-composition,mutating=false,failurePolicy=fail,groups=apiextensions.crossplane.io,resources=compositions,versions=v1,name=compositions.apiextensions.crossplane.io,sideEffects=None,admissionReviewVersions=v1
+
+// ValidateCreate is run for creation actions.
+func (in *Composition) ValidateCreate() error {
+	if err := in.validateObserveOnly(); err != nil {
+		return err
+	}
+	if err := in.validateGates(); err != nil {
+		return err
+	}
+	return in.validateCompositeTypeVersions()
+}
+
+// ValidateUpdate is run for update actions.
+func (in *Composition) ValidateUpdate(_ runtime.Object) error {
+	if err := in.validateObserveOnly(); err != nil {
+		return err
+	}
+	if err := in.validateGates(); err != nil {
+		return err
+	}
+	return in.validateCompositeTypeVersions()
+}
+
+// ValidateDelete is run for delete actions.
+func (in *Composition) ValidateDelete() error {
+	return nil
+}
+
+func (in *Composition) validateObserveOnly() error {
+	for i, r := range in.Spec.Resources {
+		if r.ObserveOnly == nil {
+			continue
+		}
+		if (r.ObserveOnly.Name == nil) == (len(r.ObserveOnly.MatchLabels) == 0) {
+			return errors.Errorf(errFmtObserveOnlySelector, i)
+		}
+		for j, p := range r.Patches {
+			switch p.Type {
+			case PatchTypeToCompositeFieldPath, PatchTypeCombineToComposite, PatchTypePatchSet:
+			default:
+				return errors.Errorf(errFmtObserveOnlyPatch, i, j, p.Type)
+			}
+		}
+	}
+	return nil
+}
+
+func (in *Composition) validateGates() error {
+	names := make(map[string]bool, len(in.Spec.Resources))
+	for _, r := range in.Spec.Resources {
+		if r.Name != nil {
+			names[*r.Name] = true
+		}
+	}
+	for i, r := range in.Spec.Resources {
+		if len(r.Gates) > 0 && r.ObserveOnly != nil {
+			return errors.Errorf(errFmtObserveOnlyGates, i)
+		}
+		for j, g := range r.Gates {
+			if !names[g.ResourceName] {
+				return errors.Errorf(errFmtGateResourceUnknown, i, j, g.ResourceName)
+			}
+			if r.Name != nil && *r.Name == g.ResourceName {
+				return errors.Errorf(errFmtGateResourceSelf, i, j)
+			}
+		}
+	}
+	return nil
+}
+
+func (in *Composition) validateCompositeTypeVersions() error { // nolint:gocyclo
+	if len(in.Spec.CompositeTypeVersions) == 0 {
+		return nil
+	}
+	gv, err := schema.ParseGroupVersion(in.Spec.CompositeTypeRef.APIVersion)
+	if err != nil {
+		return errors.Wrap(err, errParseCompositeTypeRef)
+	}
+
+	named := map[string]bool{}
+	for _, r := range in.Spec.Resources {
+		if r.Name != nil {
+			named[*r.Name] = true
+		}
+	}
+
+	seen := map[string]bool{in.Spec.CompositeTypeRef.APIVersion: true}
+	for i, v := range in.Spec.CompositeTypeVersions {
+		vgv, err := schema.ParseGroupVersion(v.APIVersion)
+		if err != nil {
+			return errors.Wrapf(err, errFmtParseCompositeTypeVersion, i)
+		}
+		if vgv.Group != gv.Group {
+			return errors.Errorf(errFmtCompositeTypeVersionGroup, i, gv.Group)
+		}
+		if seen[v.APIVersion] {
+			return errors.Errorf(errFmtCompositeTypeVersionDup, i, v.APIVersion)
+		}
+		seen[v.APIVersion] = true
+
+		overridden := map[string]bool{}
+		for j, o := range v.PatchOverrides {
+			if !named[o.ResourceName] {
+				return errors.Errorf(errFmtPatchOverrideUnknown, i, j, o.ResourceName)
+			}
+			if overridden[o.ResourceName] {
+				return errors.Errorf(errFmtPatchOverrideDup, i, j, o.ResourceName)
+			}
+			overridden[o.ResourceName] = true
+		}
+
+		// Make sure any patchsets the overrides reference exist.
+		if _, err := in.Spec.ComposedTemplatesForVersion(v.APIVersion); err != nil {
+			return errors.Wrapf(err, errFmtInvalidCompositeTypeVersion, i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestCompositionValidateCreate(t *testing.T) {
+	ref := TypeReference{APIVersion: "example.org/v1beta1", Kind: "XR"}
+	resources := []ComposedTemplate{{Name: pointer.StringPtr("a")}}
+
+	cases := map[string]struct {
+		comp *Composition
+		err  error
+	}{
+		"NoCompositeTypeVersions": {
+			comp: &Composition{Spec: CompositionSpec{CompositeTypeRef: ref}},
+		},
+		"DifferentGroup": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef:      ref,
+				CompositeTypeVersions: []CompositeTypeVersion{{APIVersion: "example.net/v1"}},
+			}},
+			err: errors.Errorf(errFmtCompositeTypeVersionGroup, 0, "example.org"),
+		},
+		"DuplicatesCompositeTypeRef": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef:      ref,
+				CompositeTypeVersions: []CompositeTypeVersion{{APIVersion: "example.org/v1beta1"}},
+			}},
+			err: errors.Errorf(errFmtCompositeTypeVersionDup, 0, "example.org/v1beta1"),
+		},
+		"DuplicateVersion": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef: ref,
+				CompositeTypeVersions: []CompositeTypeVersion{
+					{APIVersion: "example.org/v1"},
+					{APIVersion: "example.org/v1"},
+				},
+			}},
+			err: errors.Errorf(errFmtCompositeTypeVersionDup, 1, "example.org/v1"),
+		},
+		"OverridesUnknownResource": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef: ref,
+				CompositeTypeVersions: []CompositeTypeVersion{{
+					APIVersion:     "example.org/v1",
+					PatchOverrides: []PatchOverride{{ResourceName: "b"}},
+				}},
+				Resources: resources,
+			}},
+			err: errors.Errorf(errFmtPatchOverrideUnknown, 0, 0, "b"),
+		},
+		"OverridesResourceTwice": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef: ref,
+				CompositeTypeVersions: []CompositeTypeVersion{{
+					APIVersion:     "example.org/v1",
+					PatchOverrides: []PatchOverride{{ResourceName: "a"}, {ResourceName: "a"}},
+				}},
+				Resources: resources,
+			}},
+			err: errors.Errorf(errFmtPatchOverrideDup, 0, 1, "a"),
+		},
+		"OverrideReferencesUndefinedPatchSet": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef: ref,
+				CompositeTypeVersions: []CompositeTypeVersion{{
+					APIVersion: "example.org/v1",
+					PatchOverrides: []PatchOverride{{
+						ResourceName: "a",
+						Patches:      []Patch{{Type: PatchTypePatchSet, PatchSetName: pointer.StringPtr("nope")}},
+					}},
+				}},
+				Resources: resources,
+			}},
+			err: errors.Wrapf(errors.Errorf(errFmtUndefinedPatchSet, "nope"), errFmtInvalidCompositeTypeVersion, 0),
+		},
+		"ObserveOnlyWithoutSelector": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef: ref,
+				Resources:        []ComposedTemplate{{Name: pointer.StringPtr("a"), ObserveOnly: &ObserveOnly{}}},
+			}},
+			err: errors.Errorf(errFmtObserveOnlySelector, 0),
+		},
+		"ObserveOnlyWithNameAndLabels": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef: ref,
+				Resources: []ComposedTemplate{{Name: pointer.StringPtr("a"), ObserveOnly: &ObserveOnly{
+					Name:        pointer.StringPtr("shared"),
+					MatchLabels: map[string]string{"shared": "true"},
+				}}},
+			}},
+			err: errors.Errorf(errFmtObserveOnlySelector, 0),
+		},
+		"ObserveOnlyPatchedFromComposite": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef: ref,
+				Resources: []ComposedTemplate{{
+					Name:        pointer.StringPtr("a"),
+					ObserveOnly: &ObserveOnly{Name: pointer.StringPtr("shared")},
+					Patches: []Patch{
+						{Type: PatchTypeToCompositeFieldPath, FromFieldPath: pointer.StringPtr("status.atProvider.id")},
+						{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr("spec.size")},
+					},
+				}},
+			}},
+			err: errors.Errorf(errFmtObserveOnlyPatch, 0, 1, PatchTypeFromCompositeFieldPath),
+		},
+		"ValidObserveOnly": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef: ref,
+				Resources: []ComposedTemplate{{
+					Name:        pointer.StringPtr("a"),
+					ObserveOnly: &ObserveOnly{MatchLabels: map[string]string{"shared": "true"}},
+					Patches:     []Patch{{Type: PatchTypeToCompositeFieldPath, FromFieldPath: pointer.StringPtr("status.atProvider.id")}},
+				}},
+			}},
+		},
+		"ObserveOnlyGated": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef: ref,
+				Resources: []ComposedTemplate{
+					{Name: pointer.StringPtr("a")},
+					{
+						Name:        pointer.StringPtr("b"),
+						ObserveOnly: &ObserveOnly{Name: pointer.StringPtr("shared")},
+						Gates:       []Gate{{ResourceName: "a"}},
+					},
+				},
+			}},
+			err: errors.Errorf(errFmtObserveOnlyGates, 1),
+		},
+		"GateWaitsForUnknownResource": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef: ref,
+				Resources:        []ComposedTemplate{{Name: pointer.StringPtr("a"), Gates: []Gate{{ResourceName: "nope"}}}},
+			}},
+			err: errors.Errorf(errFmtGateResourceUnknown, 0, 0, "nope"),
+		},
+		"GateWaitsForItself": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef: ref,
+				Resources:        []ComposedTemplate{{Name: pointer.StringPtr("a"), Gates: []Gate{{ResourceName: "a"}}}},
+			}},
+			err: errors.Errorf(errFmtGateResourceSelf, 0, 0),
+		},
+		"Valid": {
+			comp: &Composition{Spec: CompositionSpec{
+				CompositeTypeRef: ref,
+				CompositeTypeVersions: []CompositeTypeVersion{{
+					APIVersion: "example.org/v1",
+					PatchOverrides: []PatchOverride{{
+						ResourceName: "a",
+						Patches:      []Patch{{Type: PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.StringPtr("spec.size")}},
+					}},
+				}},
+				Resources: resources,
+			}},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.comp.ValidateCreate()
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ValidateCreate(): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
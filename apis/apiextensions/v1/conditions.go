@@ -41,6 +41,9 @@ const (
 
 	ReasonTerminatingComposite xpv1.ConditionReason = "TerminatingCompositeResource"
 	ReasonTerminatingClaim     xpv1.ConditionReason = "TerminatingCompositeResourceClaim"
+
+	ReasonPausedComposite xpv1.ConditionReason = "PausedCompositeResource"
+	ReasonPausedClaim     xpv1.ConditionReason = "PausedCompositeResourceClaim"
 )
 
 // WatchingComposite indicates that Crossplane has defined and is watching for a
@@ -65,6 +68,17 @@ func TerminatingComposite() xpv1.Condition {
 	}
 }
 
+// PausedComposite indicates that Crossplane has defined a new kind of composite
+// resource, but its controller has been administratively paused.
+func PausedComposite() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeEstablished,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonPausedComposite,
+	}
+}
+
 // WatchingClaim indicates that Crossplane has defined and is watching for a
 // new kind of composite resource claim.
 func WatchingClaim() xpv1.Condition {
@@ -86,3 +100,14 @@ func TerminatingClaim() xpv1.Condition {
 		Reason:             ReasonTerminatingClaim,
 	}
 }
+
+// PausedClaim indicates that Crossplane has defined a new kind of composite
+// resource claim, but its controller has been administratively paused.
+func PausedClaim() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeOffered,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonPausedClaim,
+	}
+}
@@ -46,17 +46,41 @@ type CompositeResourceDefinitionSpec struct {
 	// the composite resource; creating, updating, or deleting the claim will
 	// create, update, or delete a corresponding composite resource. You may add
 	// claim names to an existing CompositeResourceDefinition, but they cannot
-	// be changed or removed once they have been set.
-	// +immutable
+	// be removed once they have been set. The kind and plural may only be
+	// changed together, in which case the old claim CRD is deprecated.
 	// +optional
 	ClaimNames *extv1.CustomResourceDefinitionNames `json:"claimNames,omitempty"`
 
+	// ClaimNamespaceSelector restricts the namespaces in which composite
+	// resource claims may be created to those whose labels match the
+	// selector. Claims may be created in any namespace when it is omitted.
+	// +optional
+	ClaimNamespaceSelector *metav1.LabelSelector `json:"claimNamespaceSelector,omitempty"`
+
+	// ClaimNaming configures how Crossplane names the composite resources it
+	// creates for claims, and how it propagates their external names.
+	// +optional
+	ClaimNaming *ClaimNaming `json:"claimNaming,omitempty"`
+
 	// ConnectionSecretKeys is the list of keys that will be exposed to the end
 	// user of the defined kind.
 	// If the list is empty, all keys will be published.
 	// +optional
 	ConnectionSecretKeys []string `json:"connectionSecretKeys,omitempty"`
 
+	// ClaimStatusFields is the list of status fields of the defined composite
+	// resource that will be propagated to its claim, for example
+	// status.address. Paths must be within status. If the list is empty, all
+	// status fields will be propagated.
+	// +optional
+	ClaimStatusFields []string `json:"claimStatusFields,omitempty"`
+
+	// DefaultConnectionSecretNamespace is the namespace to which composite
+	// resources that don't specify a writeConnectionSecretToRef write their
+	// connection secret, if their Composition doesn't specify one either.
+	// +optional
+	DefaultConnectionSecretNamespace *string `json:"defaultConnectionSecretNamespace,omitempty"`
+
 	// DefaultCompositionRef refers to the Composition resource that will be used
 	// in case no composition selector is given.
 	// +optional
@@ -68,6 +92,25 @@ type CompositeResourceDefinitionSpec struct {
 	// +immutable
 	EnforcedCompositionRef *xpv1.Reference `json:"enforcedCompositionRef,omitempty"`
 
+	// CompositionSelectionRules select the Composition used by composite
+	// resources that neither reference nor select one, based on their
+	// fields. The Composition of the first rule that a composite resource
+	// matches is used. Composite resources that match no rule use the
+	// DefaultCompositionRef, if any.
+	// +optional
+	CompositionSelectionRules []CompositionSelectionRule `json:"compositionSelectionRules,omitempty"`
+
+	// DeletionPolicy specifies what happens to the composite resources and
+	// claims of this definition when it is deleted. Block, the default,
+	// prevents the definition from being deleted while any of its composite
+	// resources exist. Orphan leaves its composite resources and claims, and
+	// their CRDs, in place. Cascade deletes its composite resources and claims
+	// before the definition is deleted.
+	// +optional
+	// +kubebuilder:validation:Enum=Block;Orphan;Cascade
+	// +kubebuilder:default=Block
+	DeletionPolicy *DeletionPolicy `json:"deletionPolicy,omitempty"`
+
 	// Versions is the list of all API versions of the defined composite
 	// resource. Version names are used to compute the order in which served
 	// versions are listed in API discovery. If the version string is
@@ -84,6 +127,115 @@ type CompositeResourceDefinitionSpec struct {
 	Versions []CompositeResourceDefinitionVersion `json:"versions"`
 }
 
+// A DeprecatedField is a deprecated field of a composite resource schema.
+type DeprecatedField struct {
+	// Path of the deprecated field, for example spec.parameters.size. Paths
+	// must be within spec, and may use [*] to refer to any array element.
+	Path string `json:"path"`
+
+	// Message explains what to use instead of the deprecated field.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// A CompositionSelectionRule selects a Composition for the composite resources
+// whose fields meet all of its requirements.
+type CompositionSelectionRule struct {
+	// Requirements that the fields of a composite resource must all meet.
+	// +kubebuilder:validation:MinItems=1
+	Requirements []FieldRequirement `json:"requirements"`
+
+	// CompositionRef refers to the Composition that is selected for matching
+	// composite resources.
+	CompositionRef xpv1.Reference `json:"compositionRef"`
+}
+
+// A FieldSelectorOperator determines how a FieldRequirement is met.
+type FieldSelectorOperator string
+
+// Field selector operators.
+const (
+	// FieldSelectorOpIn requires the field to equal one of the values.
+	FieldSelectorOpIn FieldSelectorOperator = "In"
+
+	// FieldSelectorOpNotIn requires the field to equal none of the values.
+	// A field that does not exist meets the requirement.
+	FieldSelectorOpNotIn FieldSelectorOperator = "NotIn"
+
+	// FieldSelectorOpExists requires the field to exist.
+	FieldSelectorOpExists FieldSelectorOperator = "Exists"
+
+	// FieldSelectorOpDoesNotExist requires the field not to exist.
+	FieldSelectorOpDoesNotExist FieldSelectorOperator = "DoesNotExist"
+
+	// FieldSelectorOpMatches requires the field to match the regular
+	// expression that is the only value.
+	FieldSelectorOpMatches FieldSelectorOperator = "Matches"
+)
+
+// A FieldRequirement is a requirement that a field of a composite resource
+// must meet.
+type FieldRequirement struct {
+	// FieldPath of the field, for example spec.parameters.region.
+	FieldPath string `json:"fieldPath"`
+
+	// Operator determines how the field's value is compared to the values.
+	// In, NotIn, and Matches compare the field's value as a string. Fields
+	// that are objects or arrays never equal or match a value.
+	// +kubebuilder:validation:Enum=In;NotIn;Exists;DoesNotExist;Matches
+	Operator FieldSelectorOperator `json:"operator"`
+
+	// Values the field's value is compared to. In and NotIn require at least
+	// one value, and Matches requires exactly one. Exists and DoesNotExist
+	// require none.
+	// +optional
+	Values []string `json:"values,omitempty"`
+}
+
+// A DeletionPolicy determines what happens to the composite resources and
+// claims of a CompositeResourceDefinition when it is deleted.
+type DeletionPolicy string
+
+// Deletion policies.
+const (
+	// DeletionBlock blocks deletion of a CompositeResourceDefinition while
+	// any of its composite resources exist.
+	DeletionBlock DeletionPolicy = "Block"
+
+	// DeletionOrphan leaves the composite resources and claims of a deleted
+	// CompositeResourceDefinition, and their CRDs, in place. Nothing
+	// reconciles them once the definition is deleted.
+	DeletionOrphan DeletionPolicy = "Orphan"
+
+	// DeletionCascade deletes the composite resources and claims of a
+	// CompositeResourceDefinition before the definition is deleted.
+	DeletionCascade DeletionPolicy = "Cascade"
+)
+
+// ClaimNaming configures how Crossplane names the composite resources it
+// creates for claims, and how it propagates their external names.
+type ClaimNaming struct {
+	// Deterministic composite resource names are derived from the namespace
+	// and name of their claim, rather than generated randomly. Claims whose
+	// derived names collide cannot be bound to a composite resource.
+	// +optional
+	Deterministic bool `json:"deterministic,omitempty"`
+
+	// HashSuffix determines whether deterministic composite resource names
+	// are the name of their claim suffixed with a hash of the claim's
+	// namespace and name, rather than the claim's namespace and name joined
+	// with a hyphen. Hashed names are less likely to collide.
+	// +optional
+	HashSuffix bool `json:"hashSuffix,omitempty"`
+
+	// PropagateExternalName propagates the crossplane.io/external-name
+	// annotation of a composite resource to any composed resource that does
+	// not specify its own. Crossplane refuses to change the external name of a
+	// claim's composite resource, or of a composed resource, once set.
+	// +optional
+	PropagateExternalName bool `json:"propagateExternalName,omitempty"`
+}
+
 // CompositeResourceDefinitionVersion describes a version of an XR.
 type CompositeResourceDefinitionVersion struct {
 	// Name of this version, e.g. “v1”, “v2beta1”, etc. Composite resources are
@@ -111,6 +263,13 @@ type CompositeResourceDefinitionVersion struct {
 	// +optional
 	DeprecationWarning *string `json:"deprecationWarning,omitempty"`
 
+	// DeprecatedFields are fields of this version's schema that are
+	// deprecated. Their descriptions in the generated CRDs are marked as
+	// deprecated, and Crossplane warns when a claim that sets any of them is
+	// created or updated.
+	// +optional
+	DeprecatedFields []DeprecatedField `json:"deprecatedFields,omitempty"`
+
 	// Schema describes the schema used for validation, pruning, and defaulting
 	// of this version of the defined composite resource. Fields required by all
 	// composite resources will be injected into this schema automatically, and
@@ -144,6 +303,31 @@ type CompositeResourceDefinitionStatus struct {
 	// Controllers represents the status of the controllers that power this
 	// composite resource definition.
 	Controllers CompositeResourceDefinitionControllerStatus `json:"controllers,omitempty"`
+
+	// Usage is the number of composite resources and claims of this
+	// definition that existed when they were last counted. They're counted
+	// periodically, so the counts may be slightly out of date.
+	// +optional
+	Usage *CompositeResourceDefinitionUsage `json:"usage,omitempty"`
+}
+
+// CompositeResourceDefinitionUsage shows how many composite resources and
+// claims of the definition exist.
+type CompositeResourceDefinitionUsage struct {
+	// Composites is the number of composite resources of this definition.
+	Composites int64 `json:"composites"`
+
+	// Claims is the number of composite resource claims of this definition.
+	// It is omitted if the definition doesn't offer a claim.
+	// +optional
+	Claims *int64 `json:"claims,omitempty"`
+
+	// LastSampleTime is the time at which the composite resources and claims
+	// were last counted.
+	LastSampleTime metav1.Time `json:"lastSampleTime"`
+
+	// LastTransitionTime is the time at which either count last changed.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
 }
 
 // CompositeResourceDefinitionControllerStatus shows the observed state of the
@@ -237,3 +421,39 @@ func (in CompositeResourceDefinition) GetClaimGroupVersionKind() schema.GroupVer
 func (in *CompositeResourceDefinition) GetConnectionSecretKeys() []string {
 	return in.Spec.ConnectionSecretKeys
 }
+
+// GetClaimStatusFields returns the status fields of composite resources that
+// should be propagated to their claims. All fields should be propagated if it
+// is empty.
+func (in *CompositeResourceDefinition) GetClaimStatusFields() []string {
+	return in.Spec.ClaimStatusFields
+}
+
+// GetClaimNaming returns how the composite resources created for claims of
+// this CompositeResourceDefinition should be named.
+func (in *CompositeResourceDefinition) GetClaimNaming() ClaimNaming {
+	if in.Spec.ClaimNaming == nil {
+		return ClaimNaming{}
+	}
+	return *in.Spec.ClaimNaming
+}
+
+// GetDeletionPolicy returns what should happen to the composite resources and
+// claims of this CompositeResourceDefinition when it is deleted.
+func (in *CompositeResourceDefinition) GetDeletionPolicy() DeletionPolicy {
+	if in.Spec.DeletionPolicy == nil {
+		return DeletionBlock
+	}
+	return *in.Spec.DeletionPolicy
+}
+
+// AnnotationKeyPaused may be set to "true" on a CompositeResourceDefinition to
+// stop the controllers that reconcile its composite resources and claims
+// without deleting it. Removing the annotation restarts them.
+const AnnotationKeyPaused = "apiextensions.crossplane.io/paused"
+
+// IsPaused returns true if the controllers for this CompositeResourceDefinition
+// have been administratively paused.
+func (in *CompositeResourceDefinition) IsPaused() bool {
+	return in.GetAnnotations()[AnnotationKeyPaused] == "true"
+}
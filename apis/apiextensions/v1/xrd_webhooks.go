@@ -17,10 +17,14 @@ limitations under the License.
 package v1
 
 import (
+	"regexp"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 )
 
 const (
@@ -29,15 +33,43 @@ const (
 	errGroupImmutable       = "spec.group is immutable"
 	errPluralImmutable      = "spec.names.plural is immutable"
 	errKindImmutable        = "spec.names.kind is immutable"
-	errClaimPluralImmutable = "spec.claimNames.plural is immutable"
-	errClaimKindImmutable   = "spec.claimNames.kind is immutable"
+	errClaimPluralImmutable = "spec.claimNames.plural cannot be changed without also changing spec.claimNames.kind"
+	errClaimKindImmutable   = "spec.claimNames.kind cannot be changed without also changing spec.claimNames.plural"
+	errClaimNamesRemoved    = "spec.claimNames cannot be removed once set"
+
+	errClaimKindRequired   = "spec.claimNames.kind is required"
+	errClaimPluralRequired = "spec.claimNames.plural is required"
+	errClaimKindConflict   = "spec.claimNames.kind must differ from spec.names.kind"
+	errClaimPluralConflict = "spec.claimNames.plural must differ from spec.names.plural"
+
+	errClaimNamespaceSelectorWithoutClaim = "spec.claimNamespaceSelector requires spec.claimNames"
+	errClaimNamespaceSelectorInvalid      = "spec.claimNamespaceSelector is invalid"
+
+	errFmtDeprecatedFieldInvalid   = "spec.versions[%d].deprecatedFields[%d].path is invalid"
+	errFmtDeprecatedFieldNotInSpec = "spec.versions[%d].deprecatedFields[%d].path must be within spec"
+
+	errFmtClaimStatusFieldInvalid     = "spec.claimStatusFields[%d] is invalid"
+	errFmtClaimStatusFieldNotInStatus = "spec.claimStatusFields[%d] must be within status"
+
+	errFmtSelectionRequirementPath    = "spec.compositionSelectionRules[%d].requirements[%d].fieldPath is invalid"
+	errFmtSelectionRequirementValues  = "spec.compositionSelectionRules[%d].requirements[%d] operator %s requires %s"
+	errFmtSelectionRequirementMatches = "spec.compositionSelectionRules[%d].requirements[%d].values[0] is not a valid regular expression"
 )
 
-// +kubebuilder:webhook:verbs=update,path=/validate-apiextensions-crossplane-io-v1-compositeresourcedefinition,mutating=false,failurePolicy=fail,groups=apiextensions.crossplane.io,resources=compositeresourcedefinitions,versions=v1,name=compositeresourcedefinitions.apiextensions.crossplane.io,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:verbs=create;update,path=/validate-apiextensions-crossplane-io-v1-compositeresourcedefinition,mutating=false,failurePolicy=fail,groups=apiextensions.crossplane.io,resources=compositeresourcedefinitions,versions=v1,name=compositeresourcedefinitions.apiextensions.crossplane.io,sideEffects=None,admissionReviewVersions=v1
 
 // ValidateCreate is run for creation actions.
 func (in *CompositeResourceDefinition) ValidateCreate() error {
-	return nil
+	if err := in.validateDeprecatedFields(); err != nil {
+		return err
+	}
+	if err := in.validateClaimStatusFields(); err != nil {
+		return err
+	}
+	if err := in.validateCompositionSelectionRules(); err != nil {
+		return err
+	}
+	return in.validateClaimNames()
 }
 
 // ValidateUpdate is run for update actions.
@@ -54,12 +86,112 @@ func (in *CompositeResourceDefinition) ValidateUpdate(old runtime.Object) error
 	case in.Spec.Names.Kind != oldObj.Spec.Names.Kind:
 		return errors.New(errKindImmutable)
 	}
-	if in.Spec.ClaimNames != nil && oldObj.Spec.ClaimNames != nil {
-		switch {
-		case in.Spec.ClaimNames.Plural != oldObj.Spec.ClaimNames.Plural:
-			return errors.New(errClaimPluralImmutable)
-		case in.Spec.ClaimNames.Kind != oldObj.Spec.ClaimNames.Kind:
-			return errors.New(errClaimKindImmutable)
+	if err := in.validateDeprecatedFields(); err != nil {
+		return err
+	}
+	if err := in.validateClaimStatusFields(); err != nil {
+		return err
+	}
+	if err := in.validateCompositionSelectionRules(); err != nil {
+		return err
+	}
+	if oldObj.Spec.ClaimNames == nil {
+		return in.validateClaimNames()
+	}
+	if in.Spec.ClaimNames == nil {
+		// Removing the claim names would orphan the claim CRD, and any
+		// claims of that type.
+		return errors.New(errClaimNamesRemoved)
+	}
+
+	// Claims may be renamed, but only by changing both their kind and their
+	// plural. Doing so results in a new claim CRD being created, while the
+	// old one is deprecated. Changing only one of the two would either
+	// change the kind of existing claims, or result in two CRDs in the same
+	// group with the same kind.
+	pluralChanged := in.Spec.ClaimNames.Plural != oldObj.Spec.ClaimNames.Plural
+	kindChanged := in.Spec.ClaimNames.Kind != oldObj.Spec.ClaimNames.Kind
+	switch {
+	case pluralChanged && !kindChanged:
+		return errors.New(errClaimPluralImmutable)
+	case kindChanged && !pluralChanged:
+		return errors.New(errClaimKindImmutable)
+	}
+	return in.validateClaimNames()
+}
+
+func (in *CompositeResourceDefinition) validateClaimNames() error {
+	if in.Spec.ClaimNames == nil {
+		if in.Spec.ClaimNamespaceSelector != nil {
+			return errors.New(errClaimNamespaceSelectorWithoutClaim)
+		}
+		return nil
+	}
+	switch {
+	case in.Spec.ClaimNames.Kind == "":
+		return errors.New(errClaimKindRequired)
+	case in.Spec.ClaimNames.Plural == "":
+		return errors.New(errClaimPluralRequired)
+	case in.Spec.ClaimNames.Kind == in.Spec.Names.Kind:
+		return errors.New(errClaimKindConflict)
+	case in.Spec.ClaimNames.Plural == in.Spec.Names.Plural:
+		return errors.New(errClaimPluralConflict)
+	}
+	_, err := metav1.LabelSelectorAsSelector(in.Spec.ClaimNamespaceSelector)
+	return errors.Wrap(err, errClaimNamespaceSelectorInvalid)
+}
+
+func (in *CompositeResourceDefinition) validateDeprecatedFields() error {
+	for i, vr := range in.Spec.Versions {
+		for j, f := range vr.DeprecatedFields {
+			s, err := fieldpath.Parse(f.Path)
+			if err != nil {
+				return errors.Wrapf(err, errFmtDeprecatedFieldInvalid, i, j)
+			}
+			if len(s) < 2 || s[0].Field != "spec" {
+				return errors.Errorf(errFmtDeprecatedFieldNotInSpec, i, j)
+			}
+		}
+	}
+	return nil
+}
+
+func (in *CompositeResourceDefinition) validateClaimStatusFields() error {
+	for i, p := range in.Spec.ClaimStatusFields {
+		s, err := fieldpath.Parse(p)
+		if err != nil {
+			return errors.Wrapf(err, errFmtClaimStatusFieldInvalid, i)
+		}
+		if len(s) < 2 || s[0].Field != "status" {
+			return errors.Errorf(errFmtClaimStatusFieldNotInStatus, i)
+		}
+	}
+	return nil
+}
+
+func (in *CompositeResourceDefinition) validateCompositionSelectionRules() error {
+	for i, rule := range in.Spec.CompositionSelectionRules {
+		for j, r := range rule.Requirements {
+			if _, err := fieldpath.Parse(r.FieldPath); err != nil {
+				return errors.Wrapf(err, errFmtSelectionRequirementPath, i, j)
+			}
+			switch r.Operator {
+			case FieldSelectorOpIn, FieldSelectorOpNotIn:
+				if len(r.Values) == 0 {
+					return errors.Errorf(errFmtSelectionRequirementValues, i, j, r.Operator, "at least one value")
+				}
+			case FieldSelectorOpExists, FieldSelectorOpDoesNotExist:
+				if len(r.Values) != 0 {
+					return errors.Errorf(errFmtSelectionRequirementValues, i, j, r.Operator, "no values")
+				}
+			case FieldSelectorOpMatches:
+				if len(r.Values) != 1 {
+					return errors.Errorf(errFmtSelectionRequirementValues, i, j, r.Operator, "exactly one value")
+				}
+				if _, err := regexp.Compile(r.Values[0]); err != nil {
+					return errors.Wrapf(err, errFmtSelectionRequirementMatches, i, j)
+				}
+			}
 		}
 	}
 	return nil
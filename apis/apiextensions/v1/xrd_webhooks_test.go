@@ -17,13 +17,16 @@ limitations under the License.
 package v1
 
 import (
+	"regexp"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 )
 
@@ -134,6 +137,73 @@ func TestValidateUpdate(t *testing.T) {
 			},
 			err: errors.New(errClaimKindImmutable),
 		},
+		"ClaimNamesRemoved": {
+			args: args{
+				old: &CompositeResourceDefinition{
+					Spec: CompositeResourceDefinitionSpec{
+						ClaimNames: &extv1.CustomResourceDefinitionNames{
+							Kind:   "a",
+							Plural: "as",
+						},
+					},
+				},
+				new: &CompositeResourceDefinition{},
+			},
+			err: errors.New(errClaimNamesRemoved),
+		},
+		"ClaimNamesRenamed": {
+			args: args{
+				old: &CompositeResourceDefinition{
+					Spec: CompositeResourceDefinitionSpec{
+						Names: extv1.CustomResourceDefinitionNames{
+							Kind:   "XA",
+							Plural: "xas",
+						},
+						ClaimNames: &extv1.CustomResourceDefinitionNames{
+							Kind:   "A",
+							Plural: "as",
+						},
+					},
+				},
+				new: &CompositeResourceDefinition{
+					Spec: CompositeResourceDefinitionSpec{
+						Names: extv1.CustomResourceDefinitionNames{
+							Kind:   "XA",
+							Plural: "xas",
+						},
+						ClaimNames: &extv1.CustomResourceDefinitionNames{
+							Kind:   "B",
+							Plural: "bs",
+						},
+					},
+				},
+			},
+		},
+		"ClaimNamesAddedConflict": {
+			args: args{
+				old: &CompositeResourceDefinition{
+					Spec: CompositeResourceDefinitionSpec{
+						Names: extv1.CustomResourceDefinitionNames{
+							Kind:   "XA",
+							Plural: "xas",
+						},
+					},
+				},
+				new: &CompositeResourceDefinition{
+					Spec: CompositeResourceDefinitionSpec{
+						Names: extv1.CustomResourceDefinitionNames{
+							Kind:   "XA",
+							Plural: "xas",
+						},
+						ClaimNames: &extv1.CustomResourceDefinitionNames{
+							Kind:   "XA",
+							Plural: "as",
+						},
+					},
+				},
+			},
+			err: errors.New(errClaimKindConflict),
+		},
 		"Success": {
 			args: args{
 				old: &CompositeResourceDefinition{
@@ -163,3 +233,174 @@ func TestValidateUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateCreate(t *testing.T) {
+	invalid := &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tenant", Operator: "Bogus"}},
+	}
+	_, errInvalid := metav1.LabelSelectorAsSelector(invalid)
+	_, errInvalidPath := fieldpath.Parse("spec.parameters[size")
+	_, errInvalidStatusPath := fieldpath.Parse("status.address[0")
+	_, errInvalidRegexp := regexp.Compile("(")
+
+	cases := map[string]struct {
+		xrd *CompositeResourceDefinition
+		err error
+	}{
+		"NoClaimNames": {
+			xrd: &CompositeResourceDefinition{},
+		},
+		"ClaimKindMissing": {
+			xrd: &CompositeResourceDefinition{
+				Spec: CompositeResourceDefinitionSpec{
+					ClaimNames: &extv1.CustomResourceDefinitionNames{
+						Plural: "as",
+					},
+				},
+			},
+			err: errors.New(errClaimKindRequired),
+		},
+		"ClaimPluralMissing": {
+			xrd: &CompositeResourceDefinition{
+				Spec: CompositeResourceDefinitionSpec{
+					ClaimNames: &extv1.CustomResourceDefinitionNames{
+						Kind: "A",
+					},
+				},
+			},
+			err: errors.New(errClaimPluralRequired),
+		},
+		"ClaimPluralConflict": {
+			xrd: &CompositeResourceDefinition{
+				Spec: CompositeResourceDefinitionSpec{
+					Names: extv1.CustomResourceDefinitionNames{
+						Kind:   "XA",
+						Plural: "as",
+					},
+					ClaimNames: &extv1.CustomResourceDefinitionNames{
+						Kind:   "A",
+						Plural: "as",
+					},
+				},
+			},
+			err: errors.New(errClaimPluralConflict),
+		},
+		"ClaimNamespaceSelectorWithoutClaim": {
+			xrd: &CompositeResourceDefinition{
+				Spec: CompositeResourceDefinitionSpec{
+					ClaimNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+				},
+			},
+			err: errors.New(errClaimNamespaceSelectorWithoutClaim),
+		},
+		"ClaimNamespaceSelectorInvalid": {
+			xrd: &CompositeResourceDefinition{
+				Spec: CompositeResourceDefinitionSpec{
+					Names: extv1.CustomResourceDefinitionNames{
+						Kind:   "XA",
+						Plural: "xas",
+					},
+					ClaimNames: &extv1.CustomResourceDefinitionNames{
+						Kind:   "A",
+						Plural: "as",
+					},
+					ClaimNamespaceSelector: invalid,
+				},
+			},
+			err: errors.Wrap(errInvalid, errClaimNamespaceSelectorInvalid),
+		},
+		"DeprecatedFieldInvalid": {
+			xrd: &CompositeResourceDefinition{
+				Spec: CompositeResourceDefinitionSpec{
+					Versions: []CompositeResourceDefinitionVersion{{
+						DeprecatedFields: []DeprecatedField{{Path: "spec.parameters[size"}},
+					}},
+				},
+			},
+			err: errors.Wrapf(errInvalidPath, errFmtDeprecatedFieldInvalid, 0, 0),
+		},
+		"DeprecatedFieldNotInSpec": {
+			xrd: &CompositeResourceDefinition{
+				Spec: CompositeResourceDefinitionSpec{
+					Versions: []CompositeResourceDefinitionVersion{{
+						DeprecatedFields: []DeprecatedField{{Path: "spec.parameters.size"}, {Path: "status.size"}},
+					}},
+				},
+			},
+			err: errors.Errorf(errFmtDeprecatedFieldNotInSpec, 0, 1),
+		},
+		"ClaimStatusFieldInvalid": {
+			xrd: &CompositeResourceDefinition{
+				Spec: CompositeResourceDefinitionSpec{
+					ClaimStatusFields: []string{"status.address[0"},
+				},
+			},
+			err: errors.Wrapf(errInvalidStatusPath, errFmtClaimStatusFieldInvalid, 0),
+		},
+		"ClaimStatusFieldNotInStatus": {
+			xrd: &CompositeResourceDefinition{
+				Spec: CompositeResourceDefinitionSpec{
+					ClaimStatusFields: []string{"status.address", "status"},
+				},
+			},
+			err: errors.Errorf(errFmtClaimStatusFieldNotInStatus, 1),
+		},
+		"CompositionSelectionRulePathInvalid": {
+			xrd: &CompositeResourceDefinition{
+				Spec: CompositeResourceDefinitionSpec{
+					CompositionSelectionRules: []CompositionSelectionRule{{
+						Requirements: []FieldRequirement{{FieldPath: "spec.parameters[size", Operator: FieldSelectorOpExists}},
+					}},
+				},
+			},
+			err: errors.Wrapf(errInvalidPath, errFmtSelectionRequirementPath, 0, 0),
+		},
+		"CompositionSelectionRuleValuesMissing": {
+			xrd: &CompositeResourceDefinition{
+				Spec: CompositeResourceDefinitionSpec{
+					CompositionSelectionRules: []CompositionSelectionRule{{
+						Requirements: []FieldRequirement{{FieldPath: "spec.region", Operator: FieldSelectorOpIn}},
+					}},
+				},
+			},
+			err: errors.Errorf(errFmtSelectionRequirementValues, 0, 0, FieldSelectorOpIn, "at least one value"),
+		},
+		"CompositionSelectionRuleRegexpInvalid": {
+			xrd: &CompositeResourceDefinition{
+				Spec: CompositeResourceDefinitionSpec{
+					CompositionSelectionRules: []CompositionSelectionRule{{
+						Requirements: []FieldRequirement{
+							{FieldPath: "spec.region", Operator: FieldSelectorOpExists},
+							{FieldPath: "spec.region", Operator: FieldSelectorOpMatches, Values: []string{"("}},
+						},
+					}},
+				},
+			},
+			err: errors.Wrapf(errInvalidRegexp, errFmtSelectionRequirementMatches, 0, 1),
+		},
+		"Success": {
+			xrd: &CompositeResourceDefinition{
+				Spec: CompositeResourceDefinitionSpec{
+					Names: extv1.CustomResourceDefinitionNames{
+						Kind:   "XA",
+						Plural: "xas",
+					},
+					ClaimNames: &extv1.CustomResourceDefinitionNames{
+						Kind:   "A",
+						Plural: "as",
+					},
+					ClaimNamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "a"}},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.xrd.ValidateCreate()
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("ValidateCreate(): -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
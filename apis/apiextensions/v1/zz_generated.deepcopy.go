@@ -24,9 +24,25 @@ package v1
 import (
 	commonv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClaimNaming) DeepCopyInto(out *ClaimNaming) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClaimNaming.
+func (in *ClaimNaming) DeepCopy() *ClaimNaming {
+	if in == nil {
+		return nil
+	}
+	out := new(ClaimNaming)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Combine) DeepCopyInto(out *Combine) {
 	*out = *in
@@ -76,6 +92,11 @@ func (in *ComposedTemplate) DeepCopyInto(out *ComposedTemplate) {
 		**out = **in
 	}
 	in.Base.DeepCopyInto(&out.Base)
+	if in.ObserveOnly != nil {
+		in, out := &in.ObserveOnly, &out.ObserveOnly
+		*out = new(ObserveOnly)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Patches != nil {
 		in, out := &in.Patches, &out.Patches
 		*out = make([]Patch, len(*in))
@@ -95,6 +116,18 @@ func (in *ComposedTemplate) DeepCopyInto(out *ComposedTemplate) {
 		*out = make([]ReadinessCheck, len(*in))
 		copy(*out, *in)
 	}
+	if in.ReadinessTimeout != nil {
+		in, out := &in.ReadinessTimeout, &out.ReadinessTimeout
+		*out = new(ReadinessTimeout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Gates != nil {
+		in, out := &in.Gates, &out.Gates
+		*out = make([]Gate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComposedTemplate.
@@ -192,11 +225,31 @@ func (in *CompositeResourceDefinitionSpec) DeepCopyInto(out *CompositeResourceDe
 		*out = new(apiextensionsv1.CustomResourceDefinitionNames)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ClaimNamespaceSelector != nil {
+		in, out := &in.ClaimNamespaceSelector, &out.ClaimNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClaimNaming != nil {
+		in, out := &in.ClaimNaming, &out.ClaimNaming
+		*out = new(ClaimNaming)
+		**out = **in
+	}
 	if in.ConnectionSecretKeys != nil {
 		in, out := &in.ConnectionSecretKeys, &out.ConnectionSecretKeys
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ClaimStatusFields != nil {
+		in, out := &in.ClaimStatusFields, &out.ClaimStatusFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DefaultConnectionSecretNamespace != nil {
+		in, out := &in.DefaultConnectionSecretNamespace, &out.DefaultConnectionSecretNamespace
+		*out = new(string)
+		**out = **in
+	}
 	if in.DefaultCompositionRef != nil {
 		in, out := &in.DefaultCompositionRef, &out.DefaultCompositionRef
 		*out = new(commonv1.Reference)
@@ -207,6 +260,18 @@ func (in *CompositeResourceDefinitionSpec) DeepCopyInto(out *CompositeResourceDe
 		*out = new(commonv1.Reference)
 		**out = **in
 	}
+	if in.CompositionSelectionRules != nil {
+		in, out := &in.CompositionSelectionRules, &out.CompositionSelectionRules
+		*out = make([]CompositionSelectionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DeletionPolicy != nil {
+		in, out := &in.DeletionPolicy, &out.DeletionPolicy
+		*out = new(DeletionPolicy)
+		**out = **in
+	}
 	if in.Versions != nil {
 		in, out := &in.Versions, &out.Versions
 		*out = make([]CompositeResourceDefinitionVersion, len(*in))
@@ -231,6 +296,11 @@ func (in *CompositeResourceDefinitionStatus) DeepCopyInto(out *CompositeResource
 	*out = *in
 	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
 	out.Controllers = in.Controllers
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(CompositeResourceDefinitionUsage)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeResourceDefinitionStatus.
@@ -243,6 +313,28 @@ func (in *CompositeResourceDefinitionStatus) DeepCopy() *CompositeResourceDefini
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositeResourceDefinitionUsage) DeepCopyInto(out *CompositeResourceDefinitionUsage) {
+	*out = *in
+	if in.Claims != nil {
+		in, out := &in.Claims, &out.Claims
+		*out = new(int64)
+		**out = **in
+	}
+	in.LastSampleTime.DeepCopyInto(&out.LastSampleTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeResourceDefinitionUsage.
+func (in *CompositeResourceDefinitionUsage) DeepCopy() *CompositeResourceDefinitionUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositeResourceDefinitionUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CompositeResourceDefinitionVersion) DeepCopyInto(out *CompositeResourceDefinitionVersion) {
 	*out = *in
@@ -256,6 +348,11 @@ func (in *CompositeResourceDefinitionVersion) DeepCopyInto(out *CompositeResourc
 		*out = new(string)
 		**out = **in
 	}
+	if in.DeprecatedFields != nil {
+		in, out := &in.DeprecatedFields, &out.DeprecatedFields
+		*out = make([]DeprecatedField, len(*in))
+		copy(*out, *in)
+	}
 	if in.Schema != nil {
 		in, out := &in.Schema, &out.Schema
 		*out = new(CompositeResourceValidation)
@@ -294,6 +391,28 @@ func (in *CompositeResourceValidation) DeepCopy() *CompositeResourceValidation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositeTypeVersion) DeepCopyInto(out *CompositeTypeVersion) {
+	*out = *in
+	if in.PatchOverrides != nil {
+		in, out := &in.PatchOverrides, &out.PatchOverrides
+		*out = make([]PatchOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeTypeVersion.
+func (in *CompositeTypeVersion) DeepCopy() *CompositeTypeVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositeTypeVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Composition) DeepCopyInto(out *Composition) {
 	*out = *in
@@ -352,10 +471,40 @@ func (in *CompositionList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositionSelectionRule) DeepCopyInto(out *CompositionSelectionRule) {
+	*out = *in
+	if in.Requirements != nil {
+		in, out := &in.Requirements, &out.Requirements
+		*out = make([]FieldRequirement, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.CompositionRef = in.CompositionRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositionSelectionRule.
+func (in *CompositionSelectionRule) DeepCopy() *CompositionSelectionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositionSelectionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CompositionSpec) DeepCopyInto(out *CompositionSpec) {
 	*out = *in
 	out.CompositeTypeRef = in.CompositeTypeRef
+	if in.CompositeTypeVersions != nil {
+		in, out := &in.CompositeTypeVersions, &out.CompositeTypeVersions
+		*out = make([]CompositeTypeVersion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.PatchSets != nil {
 		in, out := &in.PatchSets, &out.PatchSets
 		*out = make([]PatchSet, len(*in))
@@ -363,6 +512,11 @@ func (in *CompositionSpec) DeepCopyInto(out *CompositionSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.BaseCompositionRef != nil {
+		in, out := &in.BaseCompositionRef, &out.BaseCompositionRef
+		*out = new(commonv1.Reference)
+		**out = **in
+	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
 		*out = make([]ComposedTemplate, len(*in))
@@ -375,11 +529,21 @@ func (in *CompositionSpec) DeepCopyInto(out *CompositionSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.WriteConnectionSecretsToNamespaceFrom != nil {
+		in, out := &in.WriteConnectionSecretsToNamespaceFrom, &out.WriteConnectionSecretsToNamespaceFrom
+		*out = new(Combine)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PublishConnectionDetailsWithStoreConfigRef != nil {
 		in, out := &in.PublishConnectionDetailsWithStoreConfigRef, &out.PublishConnectionDetailsWithStoreConfigRef
 		*out = new(commonv1.Reference)
 		**out = **in
 	}
+	if in.PropagateMetadata != nil {
+		in, out := &in.PropagateMetadata, &out.PropagateMetadata
+		*out = new(MetadataPropagation)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositionSpec.
@@ -435,6 +599,11 @@ func (in *ConnectionDetail) DeepCopy() *ConnectionDetail {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConvertTransform) DeepCopyInto(out *ConvertTransform) {
 	*out = *in
+	if in.Format != nil {
+		in, out := &in.Format, &out.Format
+		*out = new(ConvertTransformFormat)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConvertTransform.
@@ -447,6 +616,81 @@ func (in *ConvertTransform) DeepCopy() *ConvertTransform {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeprecatedField) DeepCopyInto(out *DeprecatedField) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeprecatedField.
+func (in *DeprecatedField) DeepCopy() *DeprecatedField {
+	if in == nil {
+		return nil
+	}
+	out := new(DeprecatedField)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldRequirement) DeepCopyInto(out *FieldRequirement) {
+	*out = *in
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldRequirement.
+func (in *FieldRequirement) DeepCopy() *FieldRequirement {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldRequirement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Gate) DeepCopyInto(out *Gate) {
+	*out = *in
+	if in.Condition != nil {
+		in, out := &in.Condition, &out.Condition
+		*out = new(GateCondition)
+		**out = **in
+	}
+	if in.Checks != nil {
+		in, out := &in.Checks, &out.Checks
+		*out = make([]ReadinessCheck, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Gate.
+func (in *Gate) DeepCopy() *Gate {
+	if in == nil {
+		return nil
+	}
+	out := new(Gate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GateCondition) DeepCopyInto(out *GateCondition) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GateCondition.
+func (in *GateCondition) DeepCopy() *GateCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(GateCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MapTransform) DeepCopyInto(out *MapTransform) {
 	*out = *in
@@ -469,6 +713,59 @@ func (in *MapTransform) DeepCopy() *MapTransform {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatchTransform) DeepCopyInto(out *MatchTransform) {
+	*out = *in
+	if in.Patterns != nil {
+		in, out := &in.Patterns, &out.Patterns
+		*out = make([]MatchTransformPattern, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FallbackValue != nil {
+		in, out := &in.FallbackValue, &out.FallbackValue
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatchTransform.
+func (in *MatchTransform) DeepCopy() *MatchTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(MatchTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MatchTransformPattern) DeepCopyInto(out *MatchTransformPattern) {
+	*out = *in
+	if in.Literal != nil {
+		in, out := &in.Literal, &out.Literal
+		*out = new(string)
+		**out = **in
+	}
+	if in.Regexp != nil {
+		in, out := &in.Regexp, &out.Regexp
+		*out = new(string)
+		**out = **in
+	}
+	in.Result.DeepCopyInto(&out.Result)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MatchTransformPattern.
+func (in *MatchTransformPattern) DeepCopy() *MatchTransformPattern {
+	if in == nil {
+		return nil
+	}
+	out := new(MatchTransformPattern)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MathTransform) DeepCopyInto(out *MathTransform) {
 	*out = *in
@@ -477,6 +774,36 @@ func (in *MathTransform) DeepCopyInto(out *MathTransform) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.Add != nil {
+		in, out := &in.Add, &out.Add
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Subtract != nil {
+		in, out := &in.Subtract, &out.Subtract
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Divide != nil {
+		in, out := &in.Divide, &out.Divide
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Modulo != nil {
+		in, out := &in.Modulo, &out.Modulo
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ClampMin != nil {
+		in, out := &in.ClampMin, &out.ClampMin
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ClampMax != nil {
+		in, out := &in.ClampMax, &out.ClampMax
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MathTransform.
@@ -489,6 +816,63 @@ func (in *MathTransform) DeepCopy() *MathTransform {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetadataPropagation) DeepCopyInto(out *MetadataPropagation) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetadataPropagation.
+func (in *MetadataPropagation) DeepCopy() *MetadataPropagation {
+	if in == nil {
+		return nil
+	}
+	out := new(MetadataPropagation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObserveOnly) DeepCopyInto(out *ObserveOnly) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+	if in.MatchLabels != nil {
+		in, out := &in.MatchLabels, &out.MatchLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObserveOnly.
+func (in *ObserveOnly) DeepCopy() *ObserveOnly {
+	if in == nil {
+		return nil
+	}
+	out := new(ObserveOnly)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Patch) DeepCopyInto(out *Patch) {
 	*out = *in
@@ -536,6 +920,28 @@ func (in *Patch) DeepCopy() *Patch {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatchOverride) DeepCopyInto(out *PatchOverride) {
+	*out = *in
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]Patch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatchOverride.
+func (in *PatchOverride) DeepCopy() *PatchOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(PatchOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PatchPolicy) DeepCopyInto(out *PatchPolicy) {
 	*out = *in
@@ -549,6 +955,16 @@ func (in *PatchPolicy) DeepCopyInto(out *PatchPolicy) {
 		*out = new(commonv1.MergeOptions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TransformFailure != nil {
+		in, out := &in.TransformFailure, &out.TransformFailure
+		*out = new(TransformFailurePolicy)
+		**out = **in
+	}
+	if in.TransformFailureDefault != nil {
+		in, out := &in.TransformFailureDefault, &out.TransformFailureDefault
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatchPolicy.
@@ -598,6 +1014,27 @@ func (in *ReadinessCheck) DeepCopy() *ReadinessCheck {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessTimeout) DeepCopyInto(out *ReadinessTimeout) {
+	*out = *in
+	out.Duration = in.Duration
+	if in.FailurePolicy != nil {
+		in, out := &in.FailurePolicy, &out.FailurePolicy
+		*out = new(ReadinessFailurePolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessTimeout.
+func (in *ReadinessTimeout) DeepCopy() *ReadinessTimeout {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessTimeout)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StringCombine) DeepCopyInto(out *StringCombine) {
 	*out = *in
@@ -656,6 +1093,11 @@ func (in *Transform) DeepCopyInto(out *Transform) {
 		*out = new(MapTransform)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = new(MatchTransform)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.String != nil {
 		in, out := &in.String, &out.String
 		*out = new(StringTransform)
@@ -664,7 +1106,7 @@ func (in *Transform) DeepCopyInto(out *Transform) {
 	if in.Convert != nil {
 		in, out := &in.Convert, &out.Convert
 		*out = new(ConvertTransform)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CompositeResourceQuotaSpec specifies the desired state of the quota.
+type CompositeResourceQuotaSpec struct {
+	// Limits on the number of composite resources of each type that may be
+	// created by claims in the quota's namespace.
+	Limits []CompositeResourceQuotaLimit `json:"limits"`
+}
+
+// A CompositeResourceQuotaLimit limits the number of composite resources of a
+// particular type that may be created by claims.
+type CompositeResourceQuotaLimit struct {
+	// CompositeTypeRef specifies the type of composite resource to limit. The
+	// version of the type is ignored.
+	CompositeTypeRef TypeReference `json:"compositeTypeRef"`
+
+	// Max is the maximum number of composite resources of this type that
+	// claims in the quota's namespace may create.
+	// +kubebuilder:validation:Minimum=0
+	Max int64 `json:"max"`
+}
+
+// Limits returns true if this limit applies to composite resources of the
+// supplied group and kind.
+func (l CompositeResourceQuotaLimit) Limits(gk schema.GroupKind) bool {
+	gv, err := schema.ParseGroupVersion(l.CompositeTypeRef.APIVersion)
+	if err != nil {
+		return false
+	}
+	return gv.Group == gk.Group && l.CompositeTypeRef.Kind == gk.Kind
+}
+
+// +kubebuilder:object:root=true
+// +genclient
+
+// A CompositeResourceQuota limits the number of composite resources that may be
+// created by claims in its namespace. Claims that would exceed the quota are
+// rejected when an admission webhook is enabled.
+// +kubebuilder:resource:scope=Namespaced,categories=crossplane
+type CompositeResourceQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec CompositeResourceQuotaSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// CompositeResourceQuotaList contains a list of CompositeResourceQuotas.
+type CompositeResourceQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CompositeResourceQuota `json:"items"`
+}
@@ -48,6 +48,15 @@ var (
 	CompositionRevisionGroupVersionKind = SchemeGroupVersion.WithKind(CompositionRevisionKind)
 )
 
+// CompositeResourceQuota type metadata.
+var (
+	CompositeResourceQuotaKind             = reflect.TypeOf(CompositeResourceQuota{}).Name()
+	CompositeResourceQuotaGroupKind        = schema.GroupKind{Group: Group, Kind: CompositeResourceQuotaKind}.String()
+	CompositeResourceQuotaKindAPIVersion   = CompositeResourceQuotaKind + "." + SchemeGroupVersion.String()
+	CompositeResourceQuotaGroupVersionKind = SchemeGroupVersion.WithKind(CompositeResourceQuotaKind)
+)
+
 func init() {
 	SchemeBuilder.Register(&CompositionRevision{}, &CompositionRevisionList{})
+	SchemeBuilder.Register(&CompositeResourceQuota{}, &CompositeResourceQuotaList{})
 }
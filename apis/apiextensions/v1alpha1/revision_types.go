@@ -21,6 +21,8 @@ import (
 	"reflect"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -52,14 +54,35 @@ type CompositionRevisionSpec struct {
 	// +immutable
 	CompositeTypeRef TypeReference `json:"compositeTypeRef"`
 
+	// CompositeTypeVersions lists additional versions of the kind of
+	// composite resource referenced by CompositeTypeRef that this
+	// composition is compatible with. Each version may override the patches
+	// of named resource templates, for example to account for fields that
+	// were renamed between versions.
+	// +optional
+	// +immutable
+	CompositeTypeVersions []CompositeTypeVersion `json:"compositeTypeVersions,omitempty"`
+
 	// PatchSets define a named set of patches that may be included by
 	// any resource in this Composition.
 	// PatchSets cannot themselves refer to other PatchSets.
 	// +optional
 	PatchSets []PatchSet `json:"patchSets,omitempty"`
 
+	// BaseCompositionRef references a Composition that this composition
+	// inherits from. The base's patch sets and resources are included in
+	// this composition, and may be overridden by a patch set or resource of
+	// the same name. Any other fields of the base are used only if this
+	// composition does not set them. The base must be compatible with the
+	// same type of composite resource.
+	// +optional
+	// +immutable
+	BaseCompositionRef *xpv1.Reference `json:"baseCompositionRef,omitempty"`
+
 	// Resources is the list of resource templates that will be used when a
-	// composite resource referring to this composition is created.
+	// composite resource referring to this composition is created. Resources
+	// may be omitted only if they are inherited from a base composition.
+	// +optional
 	Resources []ComposedTemplate `json:"resources"`
 
 	// WriteConnectionSecretsToNamespace specifies the namespace in which the
@@ -73,6 +96,15 @@ type CompositionRevisionSpec struct {
 	// +optional
 	WriteConnectionSecretsToNamespace *string `json:"writeConnectionSecretsToNamespace,omitempty"`
 
+	// WriteConnectionSecretsToNamespaceFrom derives the namespace in which the
+	// connection secrets of composite resources dynamically provisioned using
+	// this composition will be created by combining fields of the composite
+	// resource, for example its region or tenant. It takes precedence over
+	// WriteConnectionSecretsToNamespace, which is used instead if any of the
+	// combined fields are not found.
+	// +optional
+	WriteConnectionSecretsToNamespaceFrom *Combine `json:"writeConnectionSecretsToNamespaceFrom,omitempty"`
+
 	// PublishConnectionDetailsWithStoreConfig specifies the secret store config
 	// with which the connection secrets of composite resource dynamically
 	// provisioned using this composition will be published.
@@ -80,11 +112,60 @@ type CompositionRevisionSpec struct {
 	// +kubebuilder:default={"name": "default"}
 	PublishConnectionDetailsWithStoreConfigRef *xpv1.Reference `json:"publishConnectionDetailsWithStoreConfigRef,omitempty"`
 
+	// PropagateMetadata specifies labels and annotations of the composite
+	// resource that are propagated to, and kept in sync on, every composed
+	// resource. Claim labels and annotations are propagated to the composite
+	// resource, so this may be used to propagate metadata like cost centers
+	// or owners from a claim to all of its composed resources.
+	// +optional
+	// +immutable
+	PropagateMetadata *MetadataPropagation `json:"propagateMetadata,omitempty"`
+
 	// Revision number. Newer revisions have larger numbers.
 	// +immutable
 	Revision int64 `json:"revision"`
 }
 
+// A CompositeTypeVersion is an additional version of a composite resource
+// kind that a composition is compatible with.
+type CompositeTypeVersion struct {
+	// APIVersion of the composite resource, for example
+	// example.org/v1alpha1. It must be in the same API group as the
+	// CompositeTypeRef.
+	APIVersion string `json:"apiVersion"`
+
+	// PatchOverrides replace the patches of named resource templates when
+	// composing a composite resource of this version.
+	// +optional
+	PatchOverrides []PatchOverride `json:"patchOverrides,omitempty"`
+}
+
+// A PatchOverride replaces the patches of a named resource template.
+type PatchOverride struct {
+	// ResourceName is the name of the resource template whose patches are
+	// replaced.
+	ResourceName string `json:"resourceName"`
+
+	// Patches replace the resource template's patches.
+	Patches []Patch `json:"patches"`
+}
+
+// MetadataPropagation specifies which labels and annotations of a composite
+// resource are propagated to its composed resources.
+type MetadataPropagation struct {
+	// Labels is a list of the keys of composite resource labels that will be
+	// propagated to all composed resources.
+	// +optional
+	// +immutable
+	Labels []string `json:"labels,omitempty"`
+
+	// Annotations is a list of the keys of composite resource annotations
+	// that will be propagated to all composed resources.
+	// +optional
+	// +immutable
+	Annotations []string `json:"annotations,omitempty"`
+}
+
 // A PatchSet is a set of patches that can be reused from all resources within
 // a Composition.
 type PatchSet struct {
@@ -130,6 +211,16 @@ type ComposedTemplate struct {
 	// +immutable
 	Base runtime.RawExtension `json:"base"`
 
+	// ObserveOnly causes this entry to observe an existing resource of the
+	// base's apiVersion and kind rather than composing a new one. Only the
+	// base's apiVersion and kind are used. An observed resource is never
+	// created, updated, or deleted, but its fields may be patched into the
+	// composite resource using ToCompositeFieldPath and CombineToComposite
+	// patches, and it may supply connection details and readiness checks.
+	// +optional
+	// +immutable
+	ObserveOnly *ObserveOnly `json:"observeOnly,omitempty"`
+
 	// Patches will be applied as overlay to the base resource.
 	// +optional
 	// +immutable
@@ -147,6 +238,120 @@ type ComposedTemplate struct {
 	// +optional
 	// +immutable
 	ReadinessChecks []ReadinessCheck `json:"readinessChecks,omitempty"`
+
+	// ReadinessTimeout determines what happens if this resource doesn't
+	// become ready in time. By default Crossplane waits indefinitely for every
+	// composed resource to become ready.
+	// +optional
+	// +immutable
+	ReadinessTimeout *ReadinessTimeout `json:"readinessTimeout,omitempty"`
+
+	// Gates that must be open before this resource is created. Each gate
+	// waits for another resource of the same composite resource to report a
+	// condition or field value. Gates are evaluated each time the composite
+	// resource is reconciled, until this resource has been created; they
+	// have no effect once it exists.
+	// +optional
+	// +immutable
+	Gates []Gate `json:"gates,omitempty"`
+}
+
+// ObserveOnly identifies the existing resource observed by an observe-only
+// resource template, either by its name or by its labels.
+type ObserveOnly struct {
+	// Name of the existing resource.
+	// +optional
+	// +immutable
+	Name *string `json:"name,omitempty"`
+
+	// Namespace of the existing resource. Required if the resource is
+	// namespaced and is identified by name. Resources identified by their
+	// labels are selected from all namespaces if no namespace is specified.
+	// +optional
+	// +immutable
+	Namespace *string `json:"namespace,omitempty"`
+
+	// MatchLabels selects the existing resource by its labels. Exactly one
+	// resource must match.
+	// +optional
+	// +immutable
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+// A ReadinessFailurePolicy determines what happens when a composed resource
+// doesn't become ready before its readiness timeout.
+type ReadinessFailurePolicy string
+
+// Readiness failure policies.
+const (
+	// ReadinessFailureBlock keeps the composite resource unready until the
+	// composed resource becomes ready, and explains why in the composite
+	// resource's Ready condition.
+	ReadinessFailureBlock ReadinessFailurePolicy = "Block"
+
+	// ReadinessFailureContinue ignores the composed resource when determining
+	// whether the composite resource is ready.
+	ReadinessFailureContinue ReadinessFailurePolicy = "Continue"
+
+	// ReadinessFailureMarkDegraded marks the composite resource unavailable,
+	// rather than still being created, until the composed resource becomes
+	// ready.
+	ReadinessFailureMarkDegraded ReadinessFailurePolicy = "MarkDegraded"
+)
+
+// A ReadinessTimeout determines what happens when a composed resource doesn't
+// become ready in time.
+type ReadinessTimeout struct {
+	// Duration for which the composed resource may be unready before its
+	// failure policy applies. It is measured from when the resource's Ready
+	// condition last changed, or from when the resource was created if it
+	// has no Ready condition.
+	// +immutable
+	Duration metav1.Duration `json:"duration"`
+
+	// FailurePolicy determines what happens when the composed resource is
+	// unready for longer than the duration.
+	// +optional
+	// +immutable
+	// +kubebuilder:validation:Enum=Block;Continue;MarkDegraded
+	// +kubebuilder:default=Block
+	FailurePolicy *ReadinessFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// A Gate delays the creation of a composed resource until another composed
+// resource reports a condition or field value.
+type Gate struct {
+	// ResourceName is the name of the resource template whose composed
+	// resource this gate waits for.
+	// +immutable
+	ResourceName string `json:"resourceName"`
+
+	// Condition the resource must report for this gate to open.
+	// +optional
+	// +immutable
+	Condition *GateCondition `json:"condition,omitempty"`
+
+	// Checks the resource must pass for this gate to open. They behave like
+	// readiness checks. A gate that specifies neither a condition nor any
+	// checks opens when the resource's Ready condition is True.
+	// +optional
+	// +immutable
+	Checks []ReadinessCheck `json:"checks,omitempty"`
+}
+
+// A GateCondition is a condition a composed resource must report for a gate to
+// open.
+type GateCondition struct {
+	// Type of the condition, for example Synced.
+	// +immutable
+	Type xpv1.ConditionType `json:"type"`
+
+	// Status the condition must have.
+	// +optional
+	// +immutable
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	// +kubebuilder:default=True
+	Status corev1.ConditionStatus `json:"status,omitempty"`
 }
 
 // ReadinessCheckType is used for readiness check types.
@@ -254,6 +459,17 @@ const (
 	FromFieldPathPolicyRequired FromFieldPathPolicy = "Required"
 )
 
+// A TransformFailurePolicy determines what happens when a patch's transforms
+// fail.
+type TransformFailurePolicy string
+
+// Transform failure policies.
+const (
+	TransformFailureError      TransformFailurePolicy = "Error"
+	TransformFailureSkip       TransformFailurePolicy = "Skip"
+	TransformFailureUseDefault TransformFailurePolicy = "UseDefault"
+)
+
 // A PatchPolicy configures the specifics of patching behaviour.
 type PatchPolicy struct {
 	// FromFieldPath specifies how to patch from a field path. The default is
@@ -264,6 +480,21 @@ type PatchPolicy struct {
 	// +optional
 	// +immutable
 	FromFieldPath *FromFieldPathPolicy `json:"fromFieldPath,omitempty"`
+
+	// TransformFailure specifies what happens when one of the patch's
+	// transforms fails. The default is 'Error', which means the composed
+	// resource will not be rendered. Use 'Skip' to not apply the patch, or
+	// 'UseDefault' to patch the transformFailureDefault value instead.
+	// +kubebuilder:validation:Enum=Error;Skip;UseDefault
+	// +optional
+	// +immutable
+	TransformFailure *TransformFailurePolicy `json:"transformFailure,omitempty"`
+
+	// TransformFailureDefault is the value that is patched to the
+	// toFieldPath when the transformFailure policy is 'UseDefault'.
+	// +optional
+	// +immutable
+	TransformFailureDefault *extv1.JSON `json:"transformFailureDefault,omitempty"`
 }
 
 // A Combine configures a patch that combines more than
@@ -321,6 +552,7 @@ type TransformType string
 // Accepted TransformTypes.
 const (
 	TransformTypeMap     TransformType = "map"
+	TransformTypeMatch   TransformType = "match"
 	TransformTypeMath    TransformType = "math"
 	TransformTypeString  TransformType = "string"
 	TransformTypeConvert TransformType = "convert"
@@ -331,12 +563,12 @@ const (
 type Transform struct {
 
 	// Type of the transform to be run.
-	// +kubebuilder:validation:Enum=map;math;string;convert
+	// +kubebuilder:validation:Enum=map;match;math;string;convert
 	// +immutable
 	Type TransformType `json:"type"`
 
 	// Math is used to transform the input via mathematical operations such as
-	// multiplication.
+	// multiplication, addition, or clamping.
 	// +optional
 	// +immutable
 	Math *MathTransform `json:"math,omitempty"`
@@ -346,6 +578,15 @@ type Transform struct {
 	// +immutable
 	Map *MapTransform `json:"map,omitempty"`
 
+	// Match is a more flexible version of Map. It matches the input against
+	// an ordered list of literal or regular expression patterns, and returns
+	// a fallback value if no pattern matches. Match is a separate transform
+	// because Map is an arbitrary map of input to output values, which leaves
+	// no room for patterns or a fallback value.
+	// +optional
+	// +immutable
+	Match *MatchTransform `json:"match,omitempty"`
+
 	// String is used to transform the input into a string or a different kind
 	// of string. Note that the input does not necessarily need to be a string.
 	// +optional
@@ -358,13 +599,55 @@ type Transform struct {
 	Convert *ConvertTransform `json:"convert,omitempty"`
 }
 
+// MathTransformType is type of the math transform function to be executed.
+type MathTransformType string
+
 // MathTransform conducts mathematical operations on the input with the given
 // configuration in its properties.
 type MathTransform struct {
+	// Type of the math transform to be run. Integer inputs produce integer
+	// outputs, while float inputs produce float outputs. Round always produces
+	// an integer.
+	// +optional
+	// +immutable
+	// +kubebuilder:validation:Enum=Multiply;Add;Subtract;Divide;Modulo;ClampMin;ClampMax;Round
+	// +kubebuilder:default=Multiply
+	Type MathTransformType `json:"type,omitempty"`
+
 	// Multiply the value.
 	// +optional
 	// +immutable
 	Multiply *int64 `json:"multiply,omitempty"`
+
+	// Add to the value.
+	// +optional
+	// +immutable
+	Add *int64 `json:"add,omitempty"`
+
+	// Subtract from the value.
+	// +optional
+	// +immutable
+	Subtract *int64 `json:"subtract,omitempty"`
+
+	// Divide the value. Integer division truncates toward zero.
+	// +optional
+	// +immutable
+	Divide *int64 `json:"divide,omitempty"`
+
+	// Modulo returns the remainder of dividing the value.
+	// +optional
+	// +immutable
+	Modulo *int64 `json:"modulo,omitempty"`
+
+	// ClampMin sets the minimum value of the output.
+	// +optional
+	// +immutable
+	ClampMin *int64 `json:"clampMin,omitempty"`
+
+	// ClampMax sets the maximum value of the output.
+	// +optional
+	// +immutable
+	ClampMax *int64 `json:"clampMax,omitempty"`
 }
 
 // MapTransform returns a value for the input from the given map.
@@ -408,6 +691,54 @@ func (m *MapTransform) Resolve(input interface{}) (interface{}, error) {
 	}
 }
 
+// MatchTransformPatternType is the type of a MatchTransformPattern.
+type MatchTransformPatternType string
+
+// A MatchTransformPattern is a pattern that can be matched against the input
+// of a MatchTransform.
+type MatchTransformPattern struct {
+	// Type specifies how the pattern matches the input.
+	// +optional
+	// +immutable
+	// +kubebuilder:validation:Enum=Literal;Regexp
+	// +kubebuilder:default=Literal
+	Type MatchTransformPatternType `json:"type,omitempty"`
+
+	// Literal exactly matches the input. Required if type is Literal.
+	// +optional
+	// +immutable
+	Literal *string `json:"literal,omitempty"`
+
+	// Regexp is a regular expression that is matched against the input.
+	// Required if type is Regexp. See https://github.com/google/re2/wiki/Syntax
+	// for the supported syntax.
+	// +optional
+	// +immutable
+	Regexp *string `json:"regexp,omitempty"`
+
+	// Result is returned if the pattern matches the input. It may be any
+	// JSON value, for example a string, number, or object.
+	// +immutable
+	Result extv1.JSON `json:"result"`
+}
+
+// MatchTransform returns the result of the first pattern that matches the
+// input, or the fallback value if no pattern matches.
+type MatchTransform struct {
+	// Patterns are matched against the input in order. The result of the
+	// first pattern that matches is returned.
+	// +optional
+	// +immutable
+	Patterns []MatchTransformPattern `json:"patterns,omitempty"`
+
+	// FallbackValue is returned if no pattern matches the input. Like a
+	// pattern's result it may be any JSON value. The transform returns an
+	// error if no pattern matches and no fallback value is set.
+	// +optional
+	// +immutable
+	FallbackValue *extv1.JSON `json:"fallbackValue,omitempty"`
+}
+
 // A StringTransform returns a string given the supplied input.
 type StringTransform struct {
 	// Format the input using a Go format string. See
@@ -416,12 +747,27 @@ type StringTransform struct {
 	Format string `json:"fmt"`
 }
 
+// ConvertTransformFormat is the format a ConvertTransform serializes the
+// input to, or deserializes the input from.
+type ConvertTransformFormat string
+
 // A ConvertTransform converts the input into a new object whose type is supplied.
 type ConvertTransform struct {
-	// ToType is the type of the output of this transform.
+	// ToType is the type of the output of this transform. Required unless
+	// format is set.
+	// +optional
 	// +immutable
 	// +kubebuilder:validation:Enum=string;int;int64;bool;float64
-	ToType string `json:"toType"`
+	ToType string `json:"toType,omitempty"`
+
+	// Format the input is serialized to or deserialized from. ToJSON and
+	// ToYAML serialize any input to a string, while FromJSON and FromYAML
+	// deserialize a string input into an object. ToBase64 and FromBase64
+	// encode and decode a string input. ToType is ignored if format is set.
+	// +optional
+	// +immutable
+	// +kubebuilder:validation:Enum=ToJSON;FromJSON;ToYAML;FromYAML;ToBase64;FromBase64
+	Format *ConvertTransformFormat `json:"format,omitempty"`
 }
 
 // A ConnectionDetailType is a type of connection detail.
@@ -17,12 +17,52 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 )
 
 // ConfigurationSpec specifies the configuration of a Configuration.
 type ConfigurationSpec struct {
 	MetaSpec `json:",inline"`
+
+	// Tests of this Configuration. Tests are only run when package tests are
+	// enabled, once a revision of the Configuration is active.
+	// +optional
+	Tests []Test `json:"tests,omitempty"`
+}
+
+// A Test of a Configuration. A test creates a resource, for example a claim
+// for an XR the Configuration defines, and passes if the resource reaches the
+// expected conditions before the test times out.
+type Test struct {
+	// Name of the test.
+	Name string `json:"name"`
+
+	// Manifest of the resource the test creates. Namespaced resources are
+	// created in a sandbox namespace that is deleted once all tests finish.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:EmbeddedResource
+	Manifest runtime.RawExtension `json:"manifest"`
+
+	// Conditions the resource must have for the test to pass.
+	Conditions []TestCondition `json:"conditions"`
+
+	// Timeout after which the test fails if the resource doesn't have the
+	// expected conditions. Defaults to 10 minutes.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// A TestCondition is a condition a resource created by a test must have.
+type TestCondition struct {
+	// Type of the condition, for example Ready.
+	Type xpv1.ConditionType `json:"type"`
+
+	// Status of the condition, for example True.
+	Status corev1.ConditionStatus `json:"status"`
 }
 
 // +kubebuilder:object:root=true
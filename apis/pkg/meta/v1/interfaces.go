@@ -24,6 +24,7 @@ var _ Pkg = &Provider{}
 type Pkg interface {
 	GetCrossplaneConstraints() *CrossplaneConstraints
 	GetDependencies() []Dependency
+	GetChannel() *string
 }
 
 // GetCrossplaneConstraints gets the Configuration package's Crossplane version
@@ -47,3 +48,13 @@ func (c *Provider) GetCrossplaneConstraints() *CrossplaneConstraints {
 func (c *Provider) GetDependencies() []Dependency {
 	return c.Spec.MetaSpec.DependsOn
 }
+
+// GetChannel gets the Configuration package's release channel.
+func (c *Configuration) GetChannel() *string {
+	return c.Spec.MetaSpec.Channel
+}
+
+// GetChannel gets the Provider package's release channel.
+func (c *Provider) GetChannel() *string {
+	return c.Spec.MetaSpec.Channel
+}
@@ -16,6 +16,36 @@ limitations under the License.
 
 package v1
 
+// Annotations of package metadata that describe a package to humans.
+const (
+	// AnnotationDescription describes the package. Descriptions in other
+	// languages may be supplied by suffixing the annotation with a language
+	// tag, for example 'meta.crossplane.io/description.fr'.
+	AnnotationDescription = "meta.crossplane.io/description"
+
+	// AnnotationMaintainer is a comma separated list of the package's
+	// maintainers.
+	AnnotationMaintainer = "meta.crossplane.io/maintainer"
+
+	// AnnotationSource is the URL of the package's source code.
+	AnnotationSource = "meta.crossplane.io/source"
+
+	// AnnotationIconURI is the URI of the package's icon.
+	AnnotationIconURI = "meta.crossplane.io/iconURI"
+
+	// AnnotationIconDigest is the digest of the package's icon.
+	AnnotationIconDigest = "meta.crossplane.io/iconDigest"
+)
+
+// Annotations of package metadata that configure how a package is linted.
+const (
+	// AnnotationLintDisable is a comma separated list of the IDs of the lint
+	// rules that shouldn't be applied to the package, for example
+	// 'crd-kind-case,no-duplicate-objects'. Rules that the package manager
+	// depends on can't be disabled.
+	AnnotationLintDisable = "meta.crossplane.io/lint-disable"
+)
+
 // MetaSpec are fields that every meta package type must implement.
 type MetaSpec struct {
 	// Semantic version constraints of Crossplane that package is compatible with.
@@ -23,6 +53,12 @@ type MetaSpec struct {
 
 	// Dependencies on other packages.
 	DependsOn []Dependency `json:"dependsOn,omitempty"`
+
+	// Channel is the release channel of the package, i.e. stable, beta, or
+	// edge. A package that declares a channel may be installed only by a
+	// package that is pinned to that channel or a less stable one.
+	// +optional
+	Channel *string `json:"channel,omitempty"`
 }
 
 // CrossplaneConstraints specifies a packages compatibility with Crossplane versions.
@@ -31,14 +67,31 @@ type CrossplaneConstraints struct {
 	Version string `json:"version"`
 }
 
-// Dependency is a dependency on another package. One of Provider or Configuration may be supplied.
+// Dependency is a dependency on another package. One of Provider,
+// Configuration, or APIGroup may be supplied.
 type Dependency struct {
-	// Provider is the name of a Provider package image.
+	// Provider is the name of a Provider package image. It may contain '*'
+	// wildcards to depend on any one or more of a family of providers.
 	Provider *string `json:"provider,omitempty"`
 
-	// Configuration is the name of a Configuration package image.
+	// Configuration is the name of a Configuration package image. It may
+	// contain '*' wildcards to depend on any one or more of a family of
+	// configurations.
 	Configuration *string `json:"configuration,omitempty"`
 
+	// APIGroup is an API group, for example 'ec2.aws.crossplane.io'. The
+	// dependency is satisfied by whichever installed Provider or
+	// Configuration defines a CRD or XRD in the API group, regardless of its
+	// source. It is never installed automatically.
+	// +optional
+	APIGroup *string `json:"apiGroup,omitempty"`
+
 	// Version is the semantic version constraints of the dependency image.
 	Version string `json:"version"`
+
+	// Optional dependencies are not installed automatically, and do not
+	// prevent a package from becoming healthy if they are missing. They must
+	// have a valid version if they are installed.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
 }
@@ -40,6 +40,21 @@ type ControllerSpec struct {
 	// permissions.
 	// +optional
 	PermissionRequests []rbacv1.PolicyRule `json:"permissionRequests,omitempty"`
+
+	// Webhooks configures the webhook server of the packaged Provider
+	// controller. Crossplane generates a TLS serving certificate for each
+	// revision of a Provider that declares webhooks, and configures the
+	// webhook configurations and CRD conversion webhooks in the package to
+	// call the controller.
+	// +optional
+	Webhooks *WebhookSpec `json:"webhooks,omitempty"`
+}
+
+// WebhookSpec configures the webhook server of a packaged Provider controller.
+type WebhookSpec struct {
+	// Port on which the controller serves webhooks. Defaults to 9443.
+	// +optional
+	Port *int32 `json:"port,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -57,6 +57,8 @@ func (c *Configuration) ConvertTo(hub conversion.Hub) error {
 		out.Spec.Crossplane = &v1.CrossplaneConstraints{Version: c.Spec.Crossplane.Version}
 	}
 
+	out.Spec.Channel = c.Spec.Channel
+
 	if len(c.Spec.DependsOn) == 0 {
 		return nil
 	}
@@ -66,7 +68,9 @@ func (c *Configuration) ConvertTo(hub conversion.Hub) error {
 		out.Spec.DependsOn[i] = v1.Dependency{
 			Provider:      c.Spec.DependsOn[i].Provider,
 			Configuration: c.Spec.DependsOn[i].Configuration,
+			APIGroup:      c.Spec.DependsOn[i].APIGroup,
 			Version:       c.Spec.DependsOn[i].Version,
+			Optional:      c.Spec.DependsOn[i].Optional,
 		}
 	}
 
@@ -86,6 +90,8 @@ func (c *Configuration) ConvertFrom(hub conversion.Hub) error {
 		c.Spec.Crossplane = &CrossplaneConstraints{Version: in.Spec.Crossplane.Version}
 	}
 
+	c.Spec.Channel = in.Spec.Channel
+
 	if len(in.Spec.DependsOn) == 0 {
 		return nil
 	}
@@ -95,7 +101,9 @@ func (c *Configuration) ConvertFrom(hub conversion.Hub) error {
 		c.Spec.DependsOn[i] = Dependency{
 			Provider:      in.Spec.DependsOn[i].Provider,
 			Configuration: in.Spec.DependsOn[i].Configuration,
+			APIGroup:      in.Spec.DependsOn[i].APIGroup,
 			Version:       in.Spec.DependsOn[i].Version,
+			Optional:      in.Spec.DependsOn[i].Optional,
 		}
 	}
 
@@ -20,9 +20,11 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	fuzz "github.com/google/gofuzz"
 	"github.com/pkg/errors"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 
 	"github.com/crossplane/crossplane-runtime/pkg/test"
@@ -34,6 +36,7 @@ func TestConvertTo(t *testing.T) {
 	version := "0.42.0"
 	provider := "crossplane/provider-cool:v0.42.0"
 	config := "crossplane/getting-started-with-being-cool:v0.42.0"
+	channel := "beta"
 	ctrl := "crossplane/provider-cool-controller:v0.42.0"
 	url := "/cool"
 	verb := "activate"
@@ -72,6 +75,7 @@ func TestConvertTo(t *testing.T) {
 				Spec: ConfigurationSpec{
 					MetaSpec: MetaSpec{
 						Crossplane: &CrossplaneConstraints{Version: version},
+						Channel:    &channel,
 						DependsOn: []Dependency{
 							{
 								Provider: &provider,
@@ -92,6 +96,7 @@ func TestConvertTo(t *testing.T) {
 					Spec: v1.ConfigurationSpec{
 						MetaSpec: v1.MetaSpec{
 							Crossplane: &v1.CrossplaneConstraints{Version: version},
+							Channel:    &channel,
 							DependsOn: []v1.Dependency{
 								{
 									Provider: &provider,
@@ -146,6 +151,7 @@ func TestConvertTo(t *testing.T) {
 					},
 					MetaSpec: MetaSpec{
 						Crossplane: &CrossplaneConstraints{Version: version},
+						Channel:    &channel,
 						DependsOn: []Dependency{
 							{
 								Provider: &provider,
@@ -175,6 +181,7 @@ func TestConvertTo(t *testing.T) {
 						},
 						MetaSpec: v1.MetaSpec{
 							Crossplane: &v1.CrossplaneConstraints{Version: version},
+							Channel:    &channel,
 							DependsOn: []v1.Dependency{
 								{
 									Provider: &provider,
@@ -210,6 +217,7 @@ func TestConvertFrom(t *testing.T) {
 	version := "0.42.0"
 	provider := "crossplane/provider-cool:v0.42.0"
 	config := "crossplane/getting-started-with-being-cool:v0.42.0"
+	channel := "beta"
 	ctrl := "crossplane/provider-cool-controller:v0.42.0"
 	url := "/cool"
 	verb := "activate"
@@ -249,6 +257,7 @@ func TestConvertFrom(t *testing.T) {
 				Spec: v1.ConfigurationSpec{
 					MetaSpec: v1.MetaSpec{
 						Crossplane: &v1.CrossplaneConstraints{Version: version},
+						Channel:    &channel,
 						DependsOn: []v1.Dependency{
 							{
 								Provider: &provider,
@@ -268,6 +277,7 @@ func TestConvertFrom(t *testing.T) {
 					Spec: ConfigurationSpec{
 						MetaSpec: MetaSpec{
 							Crossplane: &CrossplaneConstraints{Version: version},
+							Channel:    &channel,
 							DependsOn: []Dependency{
 								{
 									Provider: &provider,
@@ -327,6 +337,7 @@ func TestConvertFrom(t *testing.T) {
 					},
 					MetaSpec: v1.MetaSpec{
 						Crossplane: &v1.CrossplaneConstraints{Version: version},
+						Channel:    &channel,
 						DependsOn: []v1.Dependency{
 							{
 								Provider: &provider,
@@ -355,6 +366,7 @@ func TestConvertFrom(t *testing.T) {
 						},
 						MetaSpec: MetaSpec{
 							Crossplane: &CrossplaneConstraints{Version: version},
+							Channel:    &channel,
 							DependsOn: []Dependency{
 								{
 									Provider: &provider,
@@ -384,3 +396,82 @@ func TestConvertFrom(t *testing.T) {
 		})
 	}
 }
+
+func TestRoundTrip(t *testing.T) {
+	// Conversion doesn't preserve type metadata; the caller is expected to
+	// set it. Raw extensions only exist in the hub version, and can't be
+	// fuzzed.
+	f := fuzz.New().NilChance(0.5).Funcs(
+		func(_ *metav1.TypeMeta, _ fuzz.Continue) {},
+		func(_ *runtime.RawExtension, _ fuzz.Continue) {},
+	)
+
+	type convertible interface {
+		conversion.Convertible
+		DeepCopyObject() runtime.Object
+	}
+
+	cases := map[string]struct {
+		reason string
+		c      func() convertible
+		hub    func() conversion.Hub
+
+		// dropUnconvertible removes fields that exist only in the hub
+		// version, and thus can't survive a round trip through this one.
+		dropUnconvertible func(hub conversion.Hub)
+	}{
+		"Provider": {
+			reason: "A v1alpha1 Provider should survive a round trip through its hub version, and vice versa.",
+			c:      func() convertible { return &Provider{} },
+			hub:    func() conversion.Hub { return &v1.Provider{} },
+			dropUnconvertible: func(hub conversion.Hub) {
+				hub.(*v1.Provider).Spec.Controller.Webhooks = nil
+			},
+		},
+		"Configuration": {
+			reason: "A v1alpha1 Configuration should survive a round trip through its hub version, and vice versa.",
+			c:      func() convertible { return &Configuration{} },
+			hub:    func() conversion.Hub { return &v1.Configuration{} },
+			dropUnconvertible: func(hub conversion.Hub) {
+				hub.(*v1.Configuration).Spec.Tests = nil
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				want := tc.c()
+				f.Fuzz(want)
+				hub := tc.hub()
+				if err := want.ConvertTo(hub); err != nil {
+					t.Fatalf("\n%s\nc.ConvertTo(...): %s", tc.reason, err)
+				}
+				got := tc.c()
+				if err := got.ConvertFrom(hub); err != nil {
+					t.Fatalf("\n%s\nc.ConvertFrom(...): %s", tc.reason, err)
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("\n%s\nConvertTo then ConvertFrom: -want, +got:\n%s", tc.reason, diff)
+				}
+			}
+
+			for i := 0; i < 100; i++ {
+				want := tc.hub()
+				f.Fuzz(want)
+				tc.dropUnconvertible(want)
+				c := tc.c()
+				if err := c.ConvertFrom(want); err != nil {
+					t.Fatalf("\n%s\nc.ConvertFrom(...): %s", tc.reason, err)
+				}
+				got := tc.hub()
+				if err := c.ConvertTo(got); err != nil {
+					t.Fatalf("\n%s\nc.ConvertTo(...): %s", tc.reason, err)
+				}
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("\n%s\nConvertFrom then ConvertTo: -want, +got:\n%s", tc.reason, diff)
+				}
+			}
+		})
+	}
+}
@@ -23,6 +23,12 @@ type MetaSpec struct {
 
 	// Dependencies on other packages.
 	DependsOn []Dependency `json:"dependsOn,omitempty"`
+
+	// Channel is the release channel of the package, i.e. stable, beta, or
+	// edge. A package that declares a channel may be installed only by a
+	// package that is pinned to that channel or a less stable one.
+	// +optional
+	Channel *string `json:"channel,omitempty"`
 }
 
 // CrossplaneConstraints specifies a packages compatibility with Crossplane versions.
@@ -31,14 +37,31 @@ type CrossplaneConstraints struct {
 	Version string `json:"version"`
 }
 
-// Dependency is a dependency on another package. One of Provider or Configuration may be supplied.
+// Dependency is a dependency on another package. One of Provider,
+// Configuration, or APIGroup may be supplied.
 type Dependency struct {
-	// Provider is the name of a Provider package image.
+	// Provider is the name of a Provider package image. It may contain '*'
+	// wildcards to depend on any one or more of a family of providers.
 	Provider *string `json:"provider,omitempty"`
 
-	// Configuration is the name of a Configuration package image.
+	// Configuration is the name of a Configuration package image. It may
+	// contain '*' wildcards to depend on any one or more of a family of
+	// configurations.
 	Configuration *string `json:"configuration,omitempty"`
 
+	// APIGroup is an API group, for example 'ec2.aws.crossplane.io'. The
+	// dependency is satisfied by whichever installed Provider or
+	// Configuration defines a CRD or XRD in the API group, regardless of its
+	// source. It is never installed automatically.
+	// +optional
+	APIGroup *string `json:"apiGroup,omitempty"`
+
 	// Version is the semantic version constraints of the dependency image.
 	Version string `json:"version"`
+
+	// Optional dependencies are not installed automatically, and do not
+	// prevent a package from becoming healthy if they are missing. They must
+	// have a valid version if they are installed.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
 }
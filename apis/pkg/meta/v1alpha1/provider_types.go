@@ -81,6 +81,8 @@ func (p *Provider) ConvertTo(hub conversion.Hub) error {
 		out.Spec.Crossplane = &v1.CrossplaneConstraints{Version: p.Spec.Crossplane.Version}
 	}
 
+	out.Spec.Channel = p.Spec.Channel
+
 	if len(p.Spec.DependsOn) == 0 {
 		return nil
 	}
@@ -90,7 +92,9 @@ func (p *Provider) ConvertTo(hub conversion.Hub) error {
 		out.Spec.DependsOn[i] = v1.Dependency{
 			Provider:      p.Spec.DependsOn[i].Provider,
 			Configuration: p.Spec.DependsOn[i].Configuration,
+			APIGroup:      p.Spec.DependsOn[i].APIGroup,
 			Version:       p.Spec.DependsOn[i].Version,
+			Optional:      p.Spec.DependsOn[i].Optional,
 		}
 	}
 
@@ -117,6 +121,8 @@ func (p *Provider) ConvertFrom(hub conversion.Hub) error {
 		p.Spec.Crossplane = &CrossplaneConstraints{Version: in.Spec.Crossplane.Version}
 	}
 
+	p.Spec.Channel = in.Spec.Channel
+
 	if len(in.Spec.DependsOn) == 0 {
 		return nil
 	}
@@ -126,7 +132,9 @@ func (p *Provider) ConvertFrom(hub conversion.Hub) error {
 		p.Spec.DependsOn[i] = Dependency{
 			Provider:      in.Spec.DependsOn[i].Provider,
 			Configuration: in.Spec.DependsOn[i].Configuration,
+			APIGroup:      in.Spec.DependsOn[i].APIGroup,
 			Version:       in.Spec.DependsOn[i].Version,
+			Optional:      in.Spec.DependsOn[i].Optional,
 		}
 	}
 
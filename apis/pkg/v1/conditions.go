@@ -30,16 +30,32 @@ const (
 
 	// A TypeHealthy indicates whether a package is healthy.
 	TypeHealthy xpv1.ConditionType = "Healthy"
+
+	// A TypeDependenciesResolved indicates whether all of a package's
+	// dependencies are installed at valid versions.
+	TypeDependenciesResolved xpv1.ConditionType = "DependenciesResolved"
+
+	// A TypeTested indicates whether a package's tests have passed.
+	TypeTested xpv1.ConditionType = "Tested"
+
+	// A TypeDeprecatedAPIs indicates whether a package contains objects of
+	// deprecated API versions that were converted before being installed.
+	TypeDeprecatedAPIs xpv1.ConditionType = "DeprecatedAPIs"
 )
 
 // Reasons a package is or is not installed.
 const (
-	ReasonUnpacking     xpv1.ConditionReason = "UnpackingPackage"
-	ReasonInactive      xpv1.ConditionReason = "InactivePackageRevision"
-	ReasonActive        xpv1.ConditionReason = "ActivePackageRevision"
-	ReasonUnhealthy     xpv1.ConditionReason = "UnhealthyPackageRevision"
-	ReasonHealthy       xpv1.ConditionReason = "HealthyPackageRevision"
-	ReasonUnknownHealth xpv1.ConditionReason = "UnknownPackageRevisionHealth"
+	ReasonUnpacking        xpv1.ConditionReason = "UnpackingPackage"
+	ReasonInactive         xpv1.ConditionReason = "InactivePackageRevision"
+	ReasonActive           xpv1.ConditionReason = "ActivePackageRevision"
+	ReasonUnhealthy        xpv1.ConditionReason = "UnhealthyPackageRevision"
+	ReasonHealthy          xpv1.ConditionReason = "HealthyPackageRevision"
+	ReasonUnknownHealth    xpv1.ConditionReason = "UnknownPackageRevisionHealth"
+	ReasonFieldConflict    xpv1.ConditionReason = "FieldConflict"
+	ReasonResourceConflict xpv1.ConditionReason = "ResourceConflict"
+	ReasonWaitingOnLock    xpv1.ConditionReason = "WaitingOnLock"
+	ReasonLockConflict     xpv1.ConditionReason = "LockConflict"
+	ReasonPolicyDenied     xpv1.ConditionReason = "PolicyDenied"
 )
 
 // Unpacking indicates that the package manager is waiting for a package
@@ -85,6 +101,30 @@ func Unhealthy() xpv1.Condition {
 	}
 }
 
+// FieldConflict indicates that the current revision is unhealthy because
+// applying one of its objects would change fields that were set by another
+// field manager.
+func FieldConflict() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeHealthy,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonFieldConflict,
+	}
+}
+
+// ResourceConflict indicates that the current revision is unhealthy because one
+// of its objects, for example a CRD, is already controlled by a revision of an
+// unrelated package.
+func ResourceConflict() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeHealthy,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonResourceConflict,
+	}
+}
+
 // Healthy indicates that the current revision is healthy.
 func Healthy() xpv1.Condition {
 	return xpv1.Condition{
@@ -104,3 +144,185 @@ func UnknownHealth() xpv1.Condition {
 		Reason:             ReasonUnknownHealth,
 	}
 }
+
+// WaitingOnLock indicates that the health of the current revision is unknown
+// because its dependencies can't be resolved until the package Lock exists.
+func WaitingOnLock() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeHealthy,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonWaitingOnLock,
+	}
+}
+
+// LockConflict indicates that the health of the current revision is unknown
+// because another revision updated the package Lock while its dependencies
+// were being resolved.
+func LockConflict() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeHealthy,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonLockConflict,
+	}
+}
+
+// PolicyDenied indicates that the current revision is unhealthy because policy,
+// for example an activation webhook, denied its activation.
+func PolicyDenied() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeHealthy,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonPolicyDenied,
+	}
+}
+
+// Reasons a package's dependencies are or are not resolved.
+const (
+	ReasonResolvedDependencies        xpv1.ConditionReason = "ResolvedDependencies"
+	ReasonMissingDependencies         xpv1.ConditionReason = "MissingDependencies"
+	ReasonInvalidDependencies         xpv1.ConditionReason = "InvalidDependencies"
+	ReasonDependencyTypeMismatch      xpv1.ConditionReason = "DependencyTypeMismatch"
+	ReasonDegradedDependencies        xpv1.ConditionReason = "DegradedDependencies"
+	ReasonUnknownDependencies         xpv1.ConditionReason = "UnknownDependencies"
+	ReasonSkippedDependencyResolution xpv1.ConditionReason = "SkippedDependencyResolution"
+)
+
+// ResolvedDependencies indicates that all of the dependencies of the current
+// revision are installed at valid versions.
+func ResolvedDependencies() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDependenciesResolved,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonResolvedDependencies,
+	}
+}
+
+// MissingDependencies indicates that one or more of the dependencies of the
+// current revision are not installed.
+func MissingDependencies() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDependenciesResolved,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonMissingDependencies,
+	}
+}
+
+// InvalidDependencies indicates that one or more of the dependencies of the
+// current revision are installed at a version that doesn't satisfy its
+// constraints.
+func InvalidDependencies() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDependenciesResolved,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonInvalidDependencies,
+	}
+}
+
+// DependencyTypeMismatch indicates that one or more of the dependencies of the
+// current revision are installed as a different type of package than the one
+// the current revision declared, for example a Configuration rather than a
+// Provider.
+func DependencyTypeMismatch() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDependenciesResolved,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDependencyTypeMismatch,
+	}
+}
+
+// DegradedDependencies indicates that one or more of the dependencies of the
+// current revision are installed, but their active revisions are not healthy.
+func DegradedDependencies() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDependenciesResolved,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDegradedDependencies,
+	}
+}
+
+// UnknownDependencies indicates that the dependencies of the current revision
+// could not be resolved.
+func UnknownDependencies() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDependenciesResolved,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonUnknownDependencies,
+	}
+}
+
+// SkippedDependencyResolution indicates that the current revision does not
+// resolve its dependencies.
+func SkippedDependencyResolution() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDependenciesResolved,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSkippedDependencyResolution,
+	}
+}
+
+// Reasons a package's tests have or have not passed.
+const (
+	ReasonTestsRunning xpv1.ConditionReason = "TestsRunning"
+	ReasonTestsPassed  xpv1.ConditionReason = "TestsPassed"
+	ReasonTestsFailed  xpv1.ConditionReason = "TestsFailed"
+)
+
+// TestsRunning indicates that some of a package's tests have not yet passed or
+// failed.
+func TestsRunning() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeTested,
+		Status:             corev1.ConditionUnknown,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonTestsRunning,
+	}
+}
+
+// TestsPassed indicates that all of a package's tests passed.
+func TestsPassed() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeTested,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonTestsPassed,
+	}
+}
+
+// TestsFailed indicates that at least one of a package's tests failed.
+func TestsFailed() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeTested,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonTestsFailed,
+	}
+}
+
+// Reasons a package does or does not contain objects of deprecated API
+// versions.
+const (
+	ReasonConvertedDeprecatedAPIs xpv1.ConditionReason = "ConvertedDeprecatedAPIs"
+)
+
+// ConvertedDeprecatedAPIs indicates that a package contains objects of
+// deprecated API versions, which were converted before being installed. The
+// supplied message should describe the converted objects.
+func ConvertedDeprecatedAPIs(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDeprecatedAPIs,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonConvertedDeprecatedAPIs,
+		Message:            msg,
+	}
+}
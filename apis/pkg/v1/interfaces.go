@@ -18,6 +18,7 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -28,6 +29,29 @@ const (
 	// LabelParentPackage is used as key for the owner package label we add to the
 	// revisions. Its corresponding value should be the name of the owner package.
 	LabelParentPackage = "pkg.crossplane.io/package"
+
+	// LabelResolvedDependency is added to packages that the package manager
+	// installed to satisfy the dependencies of another package, and to their
+	// revisions. Its value should be "true".
+	LabelResolvedDependency = "pkg.crossplane.io/resolved-dependency"
+
+	// AnnotationRetry may be added to a package revision to ask the package
+	// manager to fetch its package again, and to resume retrying it if it
+	// stopped after repeated failures. The package manager removes the
+	// annotation once it has retried. Its value is ignored.
+	AnnotationRetry = "pkg.crossplane.io/retry"
+
+	// AnnotationSecretChecksum is added to the pods of a provider's
+	// Deployment when its ControllerConfig asks for the controller to be
+	// restarted when a Secret it references changes. Its value is a checksum
+	// of the referenced Secrets, so changing them rolls out the Deployment.
+	AnnotationSecretChecksum = "pkg.crossplane.io/secret-checksum"
+
+	// AnnotationEstablishedHash is added to the objects of a package when a
+	// revision establishes control of them. Its value is a hash of the object
+	// the revision applied, so that a revision that is interrupted while it is
+	// being installed can skip objects it already established when it resumes.
+	AnnotationEstablishedHash = "pkg.crossplane.io/established-hash"
 )
 
 // RevisionActivationPolicy indicates how a package should activate its
@@ -88,8 +112,23 @@ type Package interface {
 	GetCurrentIdentifier() string
 	SetCurrentIdentifier(r string)
 
+	GetInstalledVersion() string
+	SetInstalledVersion(v string)
+
+	GetDependencyStatus() string
+	SetDependencyStatus(s string)
+
+	GetLastFetchTime() *metav1.Time
+	SetLastFetchTime(t *metav1.Time)
+
+	GetPackageMetadata() *PackageMetadata
+	SetPackageMetadata(m *PackageMetadata)
+
 	GetSkipDependencyResolution() *bool
 	SetSkipDependencyResolution(*bool)
+
+	GetChannel() PackageChannel
+	SetChannel(c PackageChannel)
 }
 
 // GetCondition of this Provider.
@@ -192,6 +231,16 @@ func (p *Provider) SetSkipDependencyResolution(b *bool) {
 	p.Spec.SkipDependencyResolution = b
 }
 
+// GetChannel of this Provider.
+func (p *Provider) GetChannel() PackageChannel {
+	return p.Spec.Channel
+}
+
+// SetChannel of this Provider.
+func (p *Provider) SetChannel(c PackageChannel) {
+	p.Spec.Channel = c
+}
+
 // GetCurrentIdentifier of this Provider.
 func (p *Provider) GetCurrentIdentifier() string {
 	return p.Status.CurrentIdentifier
@@ -202,6 +251,46 @@ func (p *Provider) SetCurrentIdentifier(s string) {
 	p.Status.CurrentIdentifier = s
 }
 
+// GetInstalledVersion of this Provider.
+func (p *Provider) GetInstalledVersion() string {
+	return p.Status.InstalledVersion
+}
+
+// SetInstalledVersion of this Provider.
+func (p *Provider) SetInstalledVersion(v string) {
+	p.Status.InstalledVersion = v
+}
+
+// GetDependencyStatus of this Provider.
+func (p *Provider) GetDependencyStatus() string {
+	return p.Status.DependencyStatus
+}
+
+// SetDependencyStatus of this Provider.
+func (p *Provider) SetDependencyStatus(s string) {
+	p.Status.DependencyStatus = s
+}
+
+// GetLastFetchTime of this Provider.
+func (p *Provider) GetLastFetchTime() *metav1.Time {
+	return p.Status.LastFetchTime
+}
+
+// SetLastFetchTime of this Provider.
+func (p *Provider) SetLastFetchTime(t *metav1.Time) {
+	p.Status.LastFetchTime = t
+}
+
+// GetPackageMetadata of this Provider.
+func (p *Provider) GetPackageMetadata() *PackageMetadata {
+	return p.Status.PackageMetadata
+}
+
+// SetPackageMetadata of this Provider.
+func (p *Provider) SetPackageMetadata(m *PackageMetadata) {
+	p.Status.PackageMetadata = m
+}
+
 // GetCondition of this Configuration.
 func (p *Configuration) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
 	return p.Status.GetCondition(ct)
@@ -300,6 +389,16 @@ func (p *Configuration) SetSkipDependencyResolution(b *bool) {
 	p.Spec.SkipDependencyResolution = b
 }
 
+// GetChannel of this Configuration.
+func (p *Configuration) GetChannel() PackageChannel {
+	return p.Spec.Channel
+}
+
+// SetChannel of this Configuration.
+func (p *Configuration) SetChannel(c PackageChannel) {
+	p.Spec.Channel = c
+}
+
 // GetCurrentIdentifier of this Configuration.
 func (p *Configuration) GetCurrentIdentifier() string {
 	return p.Status.CurrentIdentifier
@@ -310,6 +409,46 @@ func (p *Configuration) SetCurrentIdentifier(s string) {
 	p.Status.CurrentIdentifier = s
 }
 
+// GetInstalledVersion of this Configuration.
+func (p *Configuration) GetInstalledVersion() string {
+	return p.Status.InstalledVersion
+}
+
+// SetInstalledVersion of this Configuration.
+func (p *Configuration) SetInstalledVersion(v string) {
+	p.Status.InstalledVersion = v
+}
+
+// GetDependencyStatus of this Configuration.
+func (p *Configuration) GetDependencyStatus() string {
+	return p.Status.DependencyStatus
+}
+
+// SetDependencyStatus of this Configuration.
+func (p *Configuration) SetDependencyStatus(s string) {
+	p.Status.DependencyStatus = s
+}
+
+// GetLastFetchTime of this Configuration.
+func (p *Configuration) GetLastFetchTime() *metav1.Time {
+	return p.Status.LastFetchTime
+}
+
+// SetLastFetchTime of this Configuration.
+func (p *Configuration) SetLastFetchTime(t *metav1.Time) {
+	p.Status.LastFetchTime = t
+}
+
+// GetPackageMetadata of this Configuration.
+func (p *Configuration) GetPackageMetadata() *PackageMetadata {
+	return p.Status.PackageMetadata
+}
+
+// SetPackageMetadata of this Configuration.
+func (p *Configuration) SetPackageMetadata(m *PackageMetadata) {
+	p.Status.PackageMetadata = m
+}
+
 var _ PackageRevision = &ProviderRevision{}
 var _ PackageRevision = &ConfigurationRevision{}
 
@@ -349,9 +488,36 @@ type PackageRevision interface {
 	GetSkipDependencyResolution() *bool
 	SetSkipDependencyResolution(*bool)
 
+	GetChannel() PackageChannel
+	SetChannel(c PackageChannel)
+
 	GetDependencyStatus() (found, installed, invalid int64)
 	SetDependencyStatus(found, installed, invalid int64)
 
+	GetOptionalDependencyStatus() (missing int64)
+	SetOptionalDependencyStatus(missing int64)
+
+	GetControllerImage() string
+	SetControllerImage(image string)
+
+	GetRuntimeManifests() []RuntimeManifest
+	SetRuntimeManifests(m []RuntimeManifest)
+
+	GetTestResults() []PackageTestResult
+	SetTestResults(r []PackageTestResult)
+
+	GetBuildMetadata() *PackageBuildMetadata
+	SetBuildMetadata(m *PackageBuildMetadata)
+
+	GetProgress() *PackageRevisionProgress
+	SetProgress(p *PackageRevisionProgress)
+
+	GetParseError() *PackageParseError
+	SetParseError(e *PackageParseError)
+
+	GetAdmittedPackage() string
+	SetAdmittedPackage(id string)
+
 	GetWebhookTLSSecretName() *string
 	SetWebhookTLSSecretName(n *string)
 }
@@ -448,6 +614,86 @@ func (p *ProviderRevision) SetDependencyStatus(found, installed, invalid int64)
 	p.Status.InvalidDependencies = invalid
 }
 
+// GetOptionalDependencyStatus of this ProviderRevision.
+func (p *ProviderRevision) GetOptionalDependencyStatus() (missing int64) {
+	return p.Status.MissingOptionalDependencies
+}
+
+// SetOptionalDependencyStatus of this ProviderRevision.
+func (p *ProviderRevision) SetOptionalDependencyStatus(missing int64) {
+	p.Status.MissingOptionalDependencies = missing
+}
+
+// GetBuildMetadata of this ProviderRevision.
+func (p *ProviderRevision) GetBuildMetadata() *PackageBuildMetadata {
+	return p.Status.BuildMetadata
+}
+
+// SetBuildMetadata of this ProviderRevision.
+func (p *ProviderRevision) SetBuildMetadata(m *PackageBuildMetadata) {
+	p.Status.BuildMetadata = m
+}
+
+// GetProgress of this ProviderRevision.
+func (p *ProviderRevision) GetProgress() *PackageRevisionProgress {
+	return p.Status.Progress
+}
+
+// SetProgress of this ProviderRevision.
+func (p *ProviderRevision) SetProgress(prog *PackageRevisionProgress) {
+	p.Status.Progress = prog
+}
+
+// GetParseError of this ProviderRevision.
+func (p *ProviderRevision) GetParseError() *PackageParseError {
+	return p.Status.ParseError
+}
+
+// SetParseError of this ProviderRevision.
+func (p *ProviderRevision) SetParseError(e *PackageParseError) {
+	p.Status.ParseError = e
+}
+
+// GetAdmittedPackage of this ProviderRevision.
+func (p *ProviderRevision) GetAdmittedPackage() string {
+	return p.Status.AdmittedPackage
+}
+
+// SetAdmittedPackage of this ProviderRevision.
+func (p *ProviderRevision) SetAdmittedPackage(id string) {
+	p.Status.AdmittedPackage = id
+}
+
+// GetControllerImage of this ProviderRevision.
+func (p *ProviderRevision) GetControllerImage() string {
+	return p.Status.ControllerImage
+}
+
+// SetControllerImage of this ProviderRevision.
+func (p *ProviderRevision) SetControllerImage(image string) {
+	p.Status.ControllerImage = image
+}
+
+// GetRuntimeManifests of this ProviderRevision.
+func (p *ProviderRevision) GetRuntimeManifests() []RuntimeManifest {
+	return p.Status.RuntimeManifests
+}
+
+// SetRuntimeManifests of this ProviderRevision.
+func (p *ProviderRevision) SetRuntimeManifests(m []RuntimeManifest) {
+	p.Status.RuntimeManifests = m
+}
+
+// GetTestResults of this ProviderRevision.
+func (p *ProviderRevision) GetTestResults() []PackageTestResult {
+	return p.Status.TestResults
+}
+
+// SetTestResults of this ProviderRevision.
+func (p *ProviderRevision) SetTestResults(r []PackageTestResult) {
+	p.Status.TestResults = r
+}
+
 // GetIgnoreCrossplaneConstraints of this ProviderRevision.
 func (p *ProviderRevision) GetIgnoreCrossplaneConstraints() *bool {
 	return p.Spec.IgnoreCrossplaneConstraints
@@ -478,6 +724,16 @@ func (p *ProviderRevision) SetSkipDependencyResolution(b *bool) {
 	p.Spec.SkipDependencyResolution = b
 }
 
+// GetChannel of this ProviderRevision.
+func (p *ProviderRevision) GetChannel() PackageChannel {
+	return p.Spec.Channel
+}
+
+// SetChannel of this ProviderRevision.
+func (p *ProviderRevision) SetChannel(c PackageChannel) {
+	p.Spec.Channel = c
+}
+
 // GetWebhookTLSSecretName of this ProviderRevision.
 func (p *ProviderRevision) GetWebhookTLSSecretName() *string {
 	return p.Spec.WebhookTLSSecretName
@@ -580,6 +836,86 @@ func (p *ConfigurationRevision) SetDependencyStatus(found, installed, invalid in
 	p.Status.InvalidDependencies = invalid
 }
 
+// GetOptionalDependencyStatus of this ConfigurationRevision.
+func (p *ConfigurationRevision) GetOptionalDependencyStatus() (missing int64) {
+	return p.Status.MissingOptionalDependencies
+}
+
+// SetOptionalDependencyStatus of this ConfigurationRevision.
+func (p *ConfigurationRevision) SetOptionalDependencyStatus(missing int64) {
+	p.Status.MissingOptionalDependencies = missing
+}
+
+// GetBuildMetadata of this ConfigurationRevision.
+func (p *ConfigurationRevision) GetBuildMetadata() *PackageBuildMetadata {
+	return p.Status.BuildMetadata
+}
+
+// SetBuildMetadata of this ConfigurationRevision.
+func (p *ConfigurationRevision) SetBuildMetadata(m *PackageBuildMetadata) {
+	p.Status.BuildMetadata = m
+}
+
+// GetProgress of this ConfigurationRevision.
+func (p *ConfigurationRevision) GetProgress() *PackageRevisionProgress {
+	return p.Status.Progress
+}
+
+// SetProgress of this ConfigurationRevision.
+func (p *ConfigurationRevision) SetProgress(prog *PackageRevisionProgress) {
+	p.Status.Progress = prog
+}
+
+// GetParseError of this ConfigurationRevision.
+func (p *ConfigurationRevision) GetParseError() *PackageParseError {
+	return p.Status.ParseError
+}
+
+// SetParseError of this ConfigurationRevision.
+func (p *ConfigurationRevision) SetParseError(e *PackageParseError) {
+	p.Status.ParseError = e
+}
+
+// GetAdmittedPackage of this ConfigurationRevision.
+func (p *ConfigurationRevision) GetAdmittedPackage() string {
+	return p.Status.AdmittedPackage
+}
+
+// SetAdmittedPackage of this ConfigurationRevision.
+func (p *ConfigurationRevision) SetAdmittedPackage(id string) {
+	p.Status.AdmittedPackage = id
+}
+
+// GetControllerImage of this ConfigurationRevision.
+func (p *ConfigurationRevision) GetControllerImage() string {
+	return p.Status.ControllerImage
+}
+
+// SetControllerImage of this ConfigurationRevision.
+func (p *ConfigurationRevision) SetControllerImage(image string) {
+	p.Status.ControllerImage = image
+}
+
+// GetRuntimeManifests of this ConfigurationRevision.
+func (p *ConfigurationRevision) GetRuntimeManifests() []RuntimeManifest {
+	return p.Status.RuntimeManifests
+}
+
+// SetRuntimeManifests of this ConfigurationRevision.
+func (p *ConfigurationRevision) SetRuntimeManifests(m []RuntimeManifest) {
+	p.Status.RuntimeManifests = m
+}
+
+// GetTestResults of this ConfigurationRevision.
+func (p *ConfigurationRevision) GetTestResults() []PackageTestResult {
+	return p.Status.TestResults
+}
+
+// SetTestResults of this ConfigurationRevision.
+func (p *ConfigurationRevision) SetTestResults(r []PackageTestResult) {
+	p.Status.TestResults = r
+}
+
 // GetIgnoreCrossplaneConstraints of this ConfigurationRevision.
 func (p *ConfigurationRevision) GetIgnoreCrossplaneConstraints() *bool {
 	return p.Spec.IgnoreCrossplaneConstraints
@@ -610,6 +946,16 @@ func (p *ConfigurationRevision) SetSkipDependencyResolution(b *bool) {
 	p.Spec.SkipDependencyResolution = b
 }
 
+// GetChannel of this ConfigurationRevision.
+func (p *ConfigurationRevision) GetChannel() PackageChannel {
+	return p.Spec.Channel
+}
+
+// SetChannel of this ConfigurationRevision.
+func (p *ConfigurationRevision) SetChannel(c PackageChannel) {
+	p.Spec.Channel = c
+}
+
 // GetWebhookTLSSecretName of this ConfigurationRevision.
 func (p *ConfigurationRevision) GetWebhookTLSSecretName() *string {
 	return p.Spec.WebhookTLSSecretName
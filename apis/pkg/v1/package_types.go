@@ -16,7 +16,10 @@ limitations under the License.
 
 package v1
 
-import corev1 "k8s.io/api/core/v1"
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // PackageSpec specifies the desired state of a Package.
 type PackageSpec struct {
@@ -62,8 +65,32 @@ type PackageSpec struct {
 	// +optional
 	// +kubebuilder:default=false
 	SkipDependencyResolution *bool `json:"skipDependencyResolution,omitempty"`
+
+	// Channel pins the package to a release channel. A package that declares a
+	// channel outside the pinned channel will not become healthy, and its
+	// dependencies are resolved only to versions in the pinned channel.
+	// +optional
+	// +kubebuilder:validation:Enum=stable;beta;edge
+	Channel PackageChannel `json:"channel,omitempty"`
 }
 
+// A PackageChannel is a release channel. Each channel includes the versions of
+// the channels that are more stable than it.
+type PackageChannel string
+
+// Release channels.
+const (
+	// PackageChannelStable includes only release versions, e.g. v1.2.0.
+	PackageChannelStable PackageChannel = "stable"
+
+	// PackageChannelBeta includes release versions, and beta and release
+	// candidate pre-release versions, e.g. v1.2.0-beta.1 or v1.2.0-rc.1.
+	PackageChannelBeta PackageChannel = "beta"
+
+	// PackageChannelEdge includes all versions, e.g. v1.2.0-alpha.1.
+	PackageChannelEdge PackageChannel = "edge"
+)
+
 // PackageStatus represents the observed state of a Package.
 type PackageStatus struct {
 	// CurrentRevision is the name of the current package revision. It will
@@ -78,4 +105,57 @@ type PackageStatus struct {
 	// will cause the package manager to check that the current revision is
 	// correct for the given package source.
 	CurrentIdentifier string `json:"currentIdentifier,omitempty"`
+
+	// InstalledVersion is the version of the current package revision, i.e.
+	// the tag or digest of the package source it was produced from.
+	// +optional
+	InstalledVersion string `json:"installedVersion,omitempty"`
+
+	// DependencyStatus summarizes the dependencies of the current package
+	// revision as the number installed out of the number found, for example
+	// 2/3. It is empty if the revision has no dependencies.
+	// +optional
+	DependencyStatus string `json:"dependencyStatus,omitempty"`
+
+	// LastFetchTime is when the package manager last successfully fetched
+	// the package's digest from its registry in order to determine its
+	// current revision. Fetches that don't change the current revision are
+	// recorded at most once per poll interval.
+	// +optional
+	LastFetchTime *metav1.Time `json:"lastFetchTime,omitempty"`
+
+	// PackageMetadata is human-facing metadata read from the current package
+	// revision, for example to render the package in a dashboard.
+	// +optional
+	PackageMetadata *PackageMetadata `json:"packageMetadata,omitempty"`
+}
+
+// PackageMetadata is human-facing metadata about a package. It is read from the
+// annotations of the package's metadata.
+type PackageMetadata struct {
+	// Description of the package.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// LocalizedDescriptions of the package, keyed by language tag, for
+	// example 'fr' or 'pt-BR'.
+	// +optional
+	LocalizedDescriptions map[string]string `json:"localizedDescriptions,omitempty"`
+
+	// Maintainers of the package.
+	// +optional
+	Maintainers []string `json:"maintainers,omitempty"`
+
+	// Source is the URL of the package's source code.
+	// +optional
+	Source string `json:"source,omitempty"`
+
+	// IconURI is the URI of the package's icon.
+	// +optional
+	IconURI string `json:"iconURI,omitempty"`
+
+	// IconDigest is the digest of the package's icon, which may be used to
+	// verify or cache it.
+	// +optional
+	IconDigest string `json:"iconDigest,omitempty"`
 }
@@ -32,6 +32,10 @@ import (
 // +kubebuilder:printcolumn:name="INSTALLED",type="string",JSONPath=".status.conditions[?(@.type=='Installed')].status"
 // +kubebuilder:printcolumn:name="HEALTHY",type="string",JSONPath=".status.conditions[?(@.type=='Healthy')].status"
 // +kubebuilder:printcolumn:name="PACKAGE",type="string",JSONPath=".spec.package"
+// +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".status.installedVersion"
+// +kubebuilder:printcolumn:name="DEPS",type="string",JSONPath=".status.dependencyStatus"
+// +kubebuilder:printcolumn:name="REVISION",type="string",JSONPath=".status.currentRevision",priority=1
+// +kubebuilder:printcolumn:name="LAST-FETCH",type="date",JSONPath=".status.lastFetchTime",priority=1
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,pkg}
 type Provider struct {
@@ -81,6 +85,7 @@ type ProviderList struct {
 // +kubebuilder:printcolumn:name="STATE",type="string",JSONPath=".spec.desiredState"
 // +kubebuilder:printcolumn:name="DEP-FOUND",type="string",JSONPath=".status.foundDependencies"
 // +kubebuilder:printcolumn:name="DEP-INSTALLED",type="string",JSONPath=".status.installedDependencies"
+// +kubebuilder:printcolumn:name="PHASE",type="string",JSONPath=".status.progress.phase",priority=1
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,pkgrev}
 type ProviderRevision struct {
@@ -19,6 +19,7 @@ package v1
 import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 )
@@ -80,6 +81,13 @@ type PackageRevisionSpec struct {
 	// +kubebuilder:default=false
 	SkipDependencyResolution *bool `json:"skipDependencyResolution,omitempty"`
 
+	// Channel pins the package to a release channel. A package that declares a
+	// channel outside the pinned channel will not become healthy, and its
+	// dependencies are resolved only to versions in the pinned channel.
+	// +optional
+	// +kubebuilder:validation:Enum=stable;beta;edge
+	Channel PackageChannel `json:"channel,omitempty"`
+
 	// WebhookTLSSecretName is the name of the TLS Secret that will be used
 	// by the provider to serve a TLS-enabled webhook server. The certificate
 	// will be injected to webhook configurations as well as CRD conversion
@@ -99,13 +107,204 @@ type PackageRevisionStatus struct {
 	// References to objects owned by PackageRevision.
 	ObjectRefs []xpv1.TypedReference `json:"objectRefs,omitempty"`
 
-	// Dependency information.
-	FoundDependencies     int64 `json:"foundDependencies,omitempty"`
+	// FoundDependencies is the number of required dependencies, and transitive
+	// required dependencies, of this package. It is updated each time the
+	// package revision is reconciled.
+	FoundDependencies int64 `json:"foundDependencies,omitempty"`
+
+	// InstalledDependencies is the number of found dependencies that are
+	// installed.
 	InstalledDependencies int64 `json:"installedDependencies,omitempty"`
-	InvalidDependencies   int64 `json:"invalidDependencies,omitempty"`
+
+	// InvalidDependencies is the number of direct dependencies that are
+	// installed at a version that doesn't satisfy their constraints.
+	InvalidDependencies int64 `json:"invalidDependencies,omitempty"`
+
+	// MissingOptionalDependencies is the number of optional dependencies, or
+	// transitive optional dependencies, that are not installed.
+	MissingOptionalDependencies int64 `json:"missingOptionalDependencies,omitempty"`
 
 	// PermissionRequests made by this package. The package declares that its
 	// controller needs these permissions to run. The RBAC manager is
 	// responsible for granting them.
 	PermissionRequests []rbacv1.PolicyRule `json:"permissionRequests,omitempty"`
+
+	// ControllerImage is the image run by this package's controller. It is
+	// pinned by digest if a PackageSourcePolicy requires it to be.
+	ControllerImage string `json:"controllerImage,omitempty"`
+
+	// RuntimeManifests summarizes the runtime resources that were rendered
+	// for this package's controller, e.g. its Deployment. Each hash changes
+	// when something, e.g. a ControllerConfig, changes what's deployed.
+	RuntimeManifests []RuntimeManifest `json:"runtimeManifests,omitempty"`
+
+	// TestResults are the results of this package's tests, if any. Tests are
+	// only run when package tests are enabled.
+	TestResults []PackageTestResult `json:"testResults,omitempty"`
+
+	// BuildMetadata describes how this package's image was built. It is read
+	// from the image's OCI annotations and labels when the package is
+	// fetched.
+	// +optional
+	BuildMetadata *PackageBuildMetadata `json:"buildMetadata,omitempty"`
+
+	// Progress of the installation of this package revision. It is updated as
+	// the revision moves through each phase of its installation, and records
+	// the furthest phase reached; it doesn't move back when a failed
+	// installation is retried.
+	// +optional
+	Progress *PackageRevisionProgress `json:"progress,omitempty"`
+
+	// ParseError describes the object of this package's contents that could
+	// not be parsed, if any. It is cleared once the contents are parsed.
+	// +optional
+	ParseError *PackageParseError `json:"parseError,omitempty"`
+
+	// AdmittedPackage identifies the package an activation webhook allowed
+	// this revision to be activated with - its image digest, or its source if
+	// it has no digest. The webhook isn't asked again while it identifies
+	// this revision's package.
+	// +optional
+	AdmittedPackage string `json:"admittedPackage,omitempty"`
+}
+
+// A PackageRevisionPhase is a phase of the installation of a package revision.
+type PackageRevisionPhase string
+
+// Package revision installation phases.
+const (
+	// PackageRevisionFetching indicates that the package's contents are being
+	// fetched and parsed.
+	PackageRevisionFetching PackageRevisionPhase = "Fetching"
+
+	// PackageRevisionVerifying indicates that the package is being linted and
+	// checked against its constraints.
+	PackageRevisionVerifying PackageRevisionPhase = "Verifying"
+
+	// PackageRevisionResolving indicates that the package's dependencies are
+	// being resolved.
+	PackageRevisionResolving PackageRevisionPhase = "Resolving"
+
+	// PackageRevisionEstablishing indicates that control or ownership of the
+	// package's objects is being established.
+	PackageRevisionEstablishing PackageRevisionPhase = "Establishing"
+
+	// PackageRevisionInstalled indicates that all of the package's objects
+	// were established.
+	PackageRevisionInstalled PackageRevisionPhase = "Installed"
+)
+
+// PackageRevisionProgress reports the progress of the installation of a
+// package revision.
+type PackageRevisionProgress struct {
+	// Phase the installation is in.
+	// +kubebuilder:validation:Enum=Fetching;Verifying;Resolving;Establishing;Installed
+	Phase PackageRevisionPhase `json:"phase"`
+
+	// ObjectsApplied is the number of the package's objects that have been
+	// established.
+	// +optional
+	ObjectsApplied int64 `json:"objectsApplied,omitempty"`
+
+	// ObjectsTotal is the number of objects the package contains. It is known
+	// once the package's objects are being established.
+	// +optional
+	ObjectsTotal int64 `json:"objectsTotal,omitempty"`
+}
+
+// A PackageParseError describes an object of a package's contents that could
+// not be parsed.
+type PackageParseError struct {
+	// Index of the object in the package's contents, starting from zero.
+	Index int64 `json:"index"`
+
+	// Line of the package's contents on which the object starts.
+	Line int64 `json:"line"`
+
+	// APIVersion of the object, if it could be determined.
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind of the object, if it could be determined.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the object, if it could be determined.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Layer is the digest of the package image layer the object was read
+	// from, if known.
+	// +optional
+	Layer string `json:"layer,omitempty"`
+
+	// Message describing why the object could not be parsed.
+	Message string `json:"message"`
+}
+
+// PackageBuildMetadata describes how a package's image was built. Each field is
+// read from the OCI annotation of the same name, for example
+// 'org.opencontainers.image.revision'.
+type PackageBuildMetadata struct {
+	// Revision of the source code the package was built from, for example a
+	// git commit.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// Source is the URL of the source code the package was built from.
+	// +optional
+	Source string `json:"source,omitempty"`
+
+	// Version of the packaged software.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Created is when the package was built, as an RFC 3339 date-time.
+	// +optional
+	Created string `json:"created,omitempty"`
+}
+
+// A RuntimeManifest summarizes a runtime resource that was rendered for a
+// package's controller.
+type RuntimeManifest struct {
+	// Kind of the rendered resource.
+	Kind string `json:"kind"`
+
+	// Name of the rendered resource.
+	Name string `json:"name"`
+
+	// Hash of the rendered resource's manifest.
+	Hash string `json:"hash"`
+}
+
+// A PackageTestResultType is the result of a package's test.
+type PackageTestResultType string
+
+// Package test results.
+const (
+	// PackageTestRunning indicates that a test has not yet passed or failed.
+	PackageTestRunning PackageTestResultType = "Running"
+
+	// PackageTestPassed indicates that a test's resource reached its expected
+	// conditions.
+	PackageTestPassed PackageTestResultType = "Passed"
+
+	// PackageTestFailed indicates that a test's resource didn't reach its
+	// expected conditions before the test timed out.
+	PackageTestFailed PackageTestResultType = "Failed"
+)
+
+// A PackageTestResult is the result of one of a package's tests.
+type PackageTestResult struct {
+	// Name of the test.
+	Name string `json:"name"`
+
+	// Result of the test.
+	Result PackageTestResultType `json:"result"`
+
+	// Message explaining the result, if any.
+	Message string `json:"message,omitempty"`
+
+	// StartTime is when the test started running.
+	StartTime metav1.Time `json:"startTime"`
 }
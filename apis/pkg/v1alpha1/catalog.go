@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// PackageCatalogSpec specifies the repositories a PackageCatalog lists.
+type PackageCatalogSpec struct {
+	// Repositories whose packages are listed by this catalog, for example
+	// 'xpkg.upbound.io/crossplane-contrib/provider-aws'. Repositories that
+	// don't specify a registry are read from the default registry.
+	Repositories []string `json:"repositories"`
+
+	// PackagePullSecrets are named secrets in the same namespace that can be
+	// used to read the repositories.
+	// +optional
+	PackagePullSecrets []corev1.LocalObjectReference `json:"packagePullSecrets,omitempty"`
+
+	// RefreshInterval is how often the catalog is refreshed.
+	// +optional
+	// +kubebuilder:default="1h"
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// A CatalogPackage is a package listed by a PackageCatalog.
+type CatalogPackage struct {
+	// Repository of the package, as specified by the catalog.
+	Repository string `json:"repository"`
+
+	// Kind of the package, for example Provider or Configuration.
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the package, as specified by its metadata.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Description of the package, as specified by its metadata.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Versions of the package, newest first. Tags that aren't semantic
+	// versions are not listed.
+	// +optional
+	Versions []string `json:"versions,omitempty"`
+
+	// Message explaining why the package could not be fully listed, if any.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PackageCatalogStatus represents the observed state of a PackageCatalog.
+type PackageCatalogStatus struct {
+	xpv1.ConditionedStatus `json:",inline"`
+
+	// Packages available in the catalog's repositories. The kind, name, and
+	// description of each package are read from the metadata of its newest
+	// version.
+	// +optional
+	Packages []CatalogPackage `json:"packages,omitempty"`
+
+	// LastRefreshTime is when the catalog was last refreshed.
+	// +optional
+	LastRefreshTime *metav1.Time `json:"lastRefreshTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +genclient
+// +genclient:nonNamespaced
+
+// A PackageCatalog lists the packages that are available in a set of
+// repositories, so that they can be discovered without access to the registry.
+// The catalog is refreshed periodically.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="REFRESHED",type="date",JSONPath=".status.lastRefreshTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories=crossplane
+type PackageCatalog struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PackageCatalogSpec   `json:"spec"`
+	Status PackageCatalogStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PackageCatalogList contains a list of PackageCatalog.
+type PackageCatalogList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PackageCatalog `json:"items"`
+}
+
+// GetCondition of this PackageCatalog.
+func (c *PackageCatalog) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return c.Status.GetCondition(ct)
+}
+
+// SetConditions of this PackageCatalog.
+func (c *PackageCatalog) SetConditions(cs ...xpv1.Condition) {
+	c.Status.SetConditions(cs...)
+}
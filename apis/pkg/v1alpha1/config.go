@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -36,6 +37,14 @@ type ControllerConfigSpec struct {
 	// used to enable leader election process.
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
+	// Strategy used to replace the provider's pods when its controller is
+	// upgraded or reconfigured. Providers that use leader election may
+	// misbehave when more than one pod runs at once; use the Recreate
+	// strategy, or a RollingUpdate strategy with a maxSurge of 0, for them.
+	// Defaults to a RollingUpdate strategy with a maxSurge and maxUnavailable
+	// of 25%.
+	// +optional
+	Strategy *appsv1.DeploymentStrategy `json:"strategy,omitempty"`
 	// Docker image name.
 	// More info: https://kubernetes.io/docs/concepts/containers/images
 	// This field is optional to allow higher level config management to default or override
@@ -131,6 +140,12 @@ type ControllerConfigSpec struct {
 	// List of container ports to expose on the container
 	// +optional
 	Ports []corev1.ContainerPort `json:"ports,omitempty"`
+
+	// RestartOnSecretChange restarts the provider's controller when a Secret
+	// referenced by its env or envFrom changes, for example because a
+	// credential was rotated.
+	// +optional
+	RestartOnSecretChange *bool `json:"restartOnSecretChange,omitempty"`
 }
 
 // PodObjectMeta is metadata that is added to the Pods in a provider's
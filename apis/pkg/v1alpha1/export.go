@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ControlPlaneExportSpec specifies where and how often the control plane is
+// exported.
+type ControlPlaneExportSpec struct {
+	// ConfigMapName is the name of the ConfigMap to which the control plane
+	// is exported. The ConfigMap is created in the namespace Crossplane runs
+	// in if it doesn't exist.
+	ConfigMapName string `json:"configMapName"`
+
+	// Interval at which the control plane is exported again. The control
+	// plane is exported only once, and whenever this spec changes, if no
+	// interval is specified.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// ControlPlaneExportStatus represents the observed state of a
+// ControlPlaneExport.
+type ControlPlaneExportStatus struct {
+	xpv1.ConditionedStatus `json:",inline"`
+
+	// ExportedObjects is the number of objects that were last exported.
+	// +optional
+	ExportedObjects int `json:"exportedObjects,omitempty"`
+
+	// LastExportTime is when the control plane was last exported.
+	// +optional
+	LastExportTime *metav1.Time `json:"lastExportTime,omitempty"`
+
+	// ObservedGeneration is the generation of the ControlPlaneExport that was
+	// last exported.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +genclient
+// +genclient:nonNamespaced
+
+// A ControlPlaneExport exports the configuration of Crossplane, but not the
+// managed resources, composite resources, or claims it manages, to a
+// ConfigMap. The export includes packages, the Lock, ControllerConfigs,
+// CompositeResourceDefinitions, and Compositions that weren't installed by a
+// package. It can be restored to a fresh cluster using the Crossplane CLI.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="OBJECTS",type="integer",JSONPath=".status.exportedObjects"
+// +kubebuilder:printcolumn:name="EXPORTED",type="date",JSONPath=".status.lastExportTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories=crossplane
+type ControlPlaneExport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ControlPlaneExportSpec   `json:"spec"`
+	Status ControlPlaneExportStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ControlPlaneExportList contains a list of ControlPlaneExport.
+type ControlPlaneExportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ControlPlaneExport `json:"items"`
+}
+
+// GetCondition of this ControlPlaneExport.
+func (e *ControlPlaneExport) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return e.Status.GetCondition(ct)
+}
+
+// SetConditions of this ControlPlaneExport.
+func (e *ControlPlaneExport) SetConditions(cs ...xpv1.Condition) {
+	e.Status.SetConditions(cs...)
+}
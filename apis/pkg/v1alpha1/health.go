@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ClusterHealthName is the name of the ClusterHealth that summarizes the
+// health of Crossplane. Crossplane creates it when it starts.
+const ClusterHealthName = "crossplane"
+
+// An UnhealthyObject is an object that is affecting the health of Crossplane.
+type UnhealthyObject struct {
+	// APIVersion of the object.
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the object.
+	Kind string `json:"kind"`
+
+	// Name of the object.
+	Name string `json:"name"`
+
+	// Reason the object is unhealthy, as reported by its conditions.
+	// +optional
+	Reason xpv1.ConditionReason `json:"reason,omitempty"`
+
+	// Message explaining why the object is unhealthy, as reported by its
+	// conditions.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// WebhookCertificateStatus represents the observed state of the TLS
+// certificate served by Crossplane's webhooks.
+type WebhookCertificateStatus struct {
+	// SecretName is the name of the Secret the certificate is read from.
+	SecretName string `json:"secretName"`
+
+	// NotAfter is when the certificate expires.
+	// +optional
+	NotAfter *metav1.Time `json:"notAfter,omitempty"`
+
+	// Message explaining why the certificate is unhealthy, if it is.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ClusterHealthStatus represents the observed health of Crossplane.
+type ClusterHealthStatus struct {
+	xpv1.ConditionedStatus `json:",inline"`
+
+	// UnhealthyPackageRevisions are the active package revisions that are
+	// not healthy.
+	// +optional
+	UnhealthyPackageRevisions []UnhealthyObject `json:"unhealthyPackageRevisions,omitempty"`
+
+	// UnresolvedDependencies are the active package revisions whose
+	// dependencies are not resolved.
+	// +optional
+	UnresolvedDependencies []UnhealthyObject `json:"unresolvedDependencies,omitempty"`
+
+	// FailingDefinitions are the CompositeResourceDefinitions that are not
+	// established, or that fail to offer their claim.
+	// +optional
+	FailingDefinitions []UnhealthyObject `json:"failingDefinitions,omitempty"`
+
+	// WebhookCertificate is the TLS certificate served by Crossplane's
+	// webhooks. It is omitted if webhooks are not enabled.
+	// +optional
+	WebhookCertificate *WebhookCertificateStatus `json:"webhookCertificate,omitempty"`
+
+	// LastCheckTime is when the health of Crossplane was last checked.
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +genclient
+// +genclient:nonNamespaced
+
+// A ClusterHealth summarizes the health of Crossplane, so that probes and
+// dashboards can check it in one place. Crossplane maintains a single
+// ClusterHealth named 'crossplane', which is Ready when no package revision,
+// dependency, CompositeResourceDefinition, or webhook certificate is unhealthy.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="CHECKED",type="date",JSONPath=".status.lastCheckTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories=crossplane
+type ClusterHealth struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status ClusterHealthStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterHealthList contains a list of ClusterHealth.
+type ClusterHealthList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterHealth `json:"items"`
+}
+
+// GetCondition of this ClusterHealth.
+func (h *ClusterHealth) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return h.Status.GetCondition(ct)
+}
+
+// SetConditions of this ClusterHealth.
+func (h *ClusterHealth) SetConditions(cs ...xpv1.Condition) {
+	h.Status.SetConditions(cs...)
+}
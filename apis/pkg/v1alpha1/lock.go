@@ -125,6 +125,7 @@ func (d *Dependency) AddNeighbors(...dag.Node) error {
 // +kubebuilder:subresource:status
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:deprecatedversion:warning="pkg.crossplane.io/v1alpha1 Lock is deprecated; use v1beta1"
 type Lock struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
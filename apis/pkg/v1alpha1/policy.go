@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"path"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-pkg-crossplane-io-v1-packages,mutating=false,failurePolicy=fail,groups=pkg.crossplane.io,resources=providers;configurations,versions=v1,name=packages.pkg.crossplane.io,sideEffects=None,admissionReviewVersions=v1
+
+// PackageSourcePolicySpec specifies the sources from which packages may be
+// installed.
+type PackageSourcePolicySpec struct {
+	// Allow is a list of sources from which packages may be installed. A
+	// source is a registry, or a registry followed by a repository path
+	// prefix, for example 'xpkg.upbound.io/crossplane-contrib'. Sources may
+	// contain '*' wildcards, which do not match '/'. Packages may be installed
+	// from any source if this list is empty.
+	// +optional
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny is a list of sources from which packages may not be installed,
+	// even if they are allowed. Deny uses the same format as Allow.
+	// +optional
+	Deny []string `json:"deny,omitempty"`
+
+	// ControllerImages specifies how the controller images of provider
+	// packages must be verified before they are run.
+	// +optional
+	ControllerImages *ControllerImagePolicy `json:"controllerImages,omitempty"`
+}
+
+// A DigestPolicy determines whether a controller image must be pinned by
+// digest.
+type DigestPolicy string
+
+// Digest policies.
+const (
+	// DigestPolicyNone allows controller images to be referenced by tag.
+	DigestPolicyNone DigestPolicy = "None"
+
+	// DigestPolicyRequired requires controller images to be referenced by
+	// digest, either by the package or by its ControllerConfig.
+	DigestPolicyRequired DigestPolicy = "Required"
+
+	// DigestPolicyResolve resolves controller images that are referenced by
+	// tag to a digest, and pins the provider's deployment to that digest.
+	DigestPolicyResolve DigestPolicy = "Resolve"
+)
+
+// A ControllerImagePolicy specifies how the controller images of provider
+// packages must be verified before they are run.
+type ControllerImagePolicy struct {
+	// Digest determines whether controller images must be pinned by digest.
+	// +optional
+	// +kubebuilder:validation:Enum=None;Required;Resolve
+	// +kubebuilder:default=None
+	Digest DigestPolicy `json:"digest,omitempty"`
+
+	// PublicKeys is a list of PEM encoded ECDSA public keys. When keys are
+	// supplied a controller image must have a cosign signature that can be
+	// verified by at least one of them. Verified images are always pinned by
+	// digest.
+	// +optional
+	PublicKeys []string `json:"publicKeys,omitempty"`
+}
+
+// Allows returns true if the policy allows packages to be installed from the
+// supplied fully qualified repository, e.g. 'index.docker.io/crossplane/nop'.
+func (s PackageSourcePolicySpec) Allows(repository string) bool {
+	for _, src := range s.Deny {
+		if sourceMatches(src, repository) {
+			return false
+		}
+	}
+	if len(s.Allow) == 0 {
+		return true
+	}
+	for _, src := range s.Allow {
+		if sourceMatches(src, repository) {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceMatches returns true if the supplied source matches the supplied
+// repository, or any of its parent paths.
+func sourceMatches(source, repository string) bool {
+	parts := strings.Split(repository, "/")
+	for i := range parts {
+		if ok, _ := path.Match(source, strings.Join(parts[:i+1], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// +kubebuilder:object:root=true
+// +genclient
+// +genclient:nonNamespaced
+
+// A PackageSourcePolicy restricts the sources from which packages may be
+// installed, including packages installed to satisfy a dependency. Packages
+// must be allowed by every PackageSourcePolicy. Packages that are not allowed
+// are rejected when an admission webhook is enabled, and are never installed
+// as a dependency.
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories=crossplane
+type PackageSourcePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PackageSourcePolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// PackageSourcePolicyList contains a list of PackageSourcePolicy.
+type PackageSourcePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PackageSourcePolicy `json:"items"`
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// LabelPropagation is added to the packages a PackagePropagation installs in
+// its spoke clusters. Its value is the name of the PackagePropagation.
+const LabelPropagation = "pkg.crossplane.io/propagation"
+
+// A SpokeCluster is a cluster to which packages are propagated.
+type SpokeCluster struct {
+	// Name of the cluster. It identifies the cluster in the status of the
+	// PackagePropagation.
+	Name string `json:"name"`
+
+	// KubeconfigSecretRef references a Secret key containing a kubeconfig
+	// that can be used to connect to the cluster.
+	KubeconfigSecretRef xpv1.SecretKeySelector `json:"kubeconfigSecretRef"`
+}
+
+// PackagePropagationSpec specifies the packages a PackagePropagation
+// propagates, and the clusters it propagates them to.
+type PackagePropagationSpec struct {
+	// PackageSelector selects the Providers and Configurations of this
+	// cluster that are propagated.
+	PackageSelector metav1.LabelSelector `json:"packageSelector"`
+
+	// Clusters to which packages are propagated.
+	Clusters []SpokeCluster `json:"clusters"`
+
+	// RefreshInterval is how often the health of the propagated packages is
+	// read from the spoke clusters.
+	// +optional
+	// +kubebuilder:default="1m"
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// A PropagatedPackage is a package that was propagated to a spoke cluster.
+type PropagatedPackage struct {
+	// Kind of the package, for example Provider or Configuration.
+	Kind string `json:"kind"`
+
+	// Name of the package.
+	Name string `json:"name"`
+
+	// Package source, including its version, that was propagated.
+	Package string `json:"package"`
+
+	// Healthy is true if the package is healthy in the spoke cluster.
+	Healthy bool `json:"healthy"`
+
+	// Message explaining why the package is not healthy, if it isn't.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// SpokeClusterStatus represents the observed state of the packages propagated
+// to a spoke cluster.
+type SpokeClusterStatus struct {
+	// Name of the cluster.
+	Name string `json:"name"`
+
+	// Packages propagated to the cluster.
+	// +optional
+	Packages []PropagatedPackage `json:"packages,omitempty"`
+
+	// Message explaining why packages could not be propagated to the
+	// cluster, if any.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PackagePropagationStatus represents the observed state of a
+// PackagePropagation.
+type PackagePropagationStatus struct {
+	xpv1.ConditionedStatus `json:",inline"`
+
+	// Clusters to which packages are propagated.
+	// +optional
+	Clusters []SpokeClusterStatus `json:"clusters,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +genclient
+// +genclient:nonNamespaced
+
+// A PackagePropagation propagates the selected Providers and Configurations,
+// at the versions installed in this cluster, to a set of spoke clusters, and
+// aggregates their health in the spoke clusters. It is Ready when every
+// propagated package is healthy in every spoke cluster.
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories=crossplane
+type PackagePropagation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PackagePropagationSpec   `json:"spec"`
+	Status PackagePropagationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PackagePropagationList contains a list of PackagePropagation.
+type PackagePropagationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PackagePropagation `json:"items"`
+}
+
+// GetCondition of this PackagePropagation.
+func (p *PackagePropagation) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return p.Status.GetCondition(ct)
+}
+
+// SetConditions of this PackagePropagation.
+func (p *PackagePropagation) SetConditions(cs ...xpv1.Condition) {
+	p.Status.SetConditions(cs...)
+}
@@ -56,7 +56,52 @@ var (
 	LockGroupVersionKind = SchemeGroupVersion.WithKind(LockKind)
 )
 
+// PackageSourcePolicy type metadata.
+var (
+	PackageSourcePolicyKind             = reflect.TypeOf(PackageSourcePolicy{}).Name()
+	PackageSourcePolicyGroupKind        = schema.GroupKind{Group: Group, Kind: PackageSourcePolicyKind}.String()
+	PackageSourcePolicyKindAPIVersion   = PackageSourcePolicyKind + "." + SchemeGroupVersion.String()
+	PackageSourcePolicyGroupVersionKind = SchemeGroupVersion.WithKind(PackageSourcePolicyKind)
+)
+
+// PackageCatalog type metadata.
+var (
+	PackageCatalogKind             = reflect.TypeOf(PackageCatalog{}).Name()
+	PackageCatalogGroupKind        = schema.GroupKind{Group: Group, Kind: PackageCatalogKind}.String()
+	PackageCatalogKindAPIVersion   = PackageCatalogKind + "." + SchemeGroupVersion.String()
+	PackageCatalogGroupVersionKind = SchemeGroupVersion.WithKind(PackageCatalogKind)
+)
+
+// ClusterHealth type metadata.
+var (
+	ClusterHealthKind             = reflect.TypeOf(ClusterHealth{}).Name()
+	ClusterHealthGroupKind        = schema.GroupKind{Group: Group, Kind: ClusterHealthKind}.String()
+	ClusterHealthKindAPIVersion   = ClusterHealthKind + "." + SchemeGroupVersion.String()
+	ClusterHealthGroupVersionKind = SchemeGroupVersion.WithKind(ClusterHealthKind)
+)
+
+// PackagePropagation type metadata.
+var (
+	PackagePropagationKind             = reflect.TypeOf(PackagePropagation{}).Name()
+	PackagePropagationGroupKind        = schema.GroupKind{Group: Group, Kind: PackagePropagationKind}.String()
+	PackagePropagationKindAPIVersion   = PackagePropagationKind + "." + SchemeGroupVersion.String()
+	PackagePropagationGroupVersionKind = SchemeGroupVersion.WithKind(PackagePropagationKind)
+)
+
+// ControlPlaneExport type metadata.
+var (
+	ControlPlaneExportKind             = reflect.TypeOf(ControlPlaneExport{}).Name()
+	ControlPlaneExportGroupKind        = schema.GroupKind{Group: Group, Kind: ControlPlaneExportKind}.String()
+	ControlPlaneExportKindAPIVersion   = ControlPlaneExportKind + "." + SchemeGroupVersion.String()
+	ControlPlaneExportGroupVersionKind = SchemeGroupVersion.WithKind(ControlPlaneExportKind)
+)
+
 func init() {
 	SchemeBuilder.Register(&ControllerConfig{}, &ControllerConfigList{})
 	SchemeBuilder.Register(&Lock{}, &LockList{})
+	SchemeBuilder.Register(&PackageSourcePolicy{}, &PackageSourcePolicyList{})
+	SchemeBuilder.Register(&PackageCatalog{}, &PackageCatalogList{})
+	SchemeBuilder.Register(&ClusterHealth{}, &ClusterHealthList{})
+	SchemeBuilder.Register(&PackagePropagation{}, &PackagePropagationList{})
+	SchemeBuilder.Register(&ControlPlaneExport{}, &ControlPlaneExportList{})
 }
@@ -17,6 +17,9 @@ limitations under the License.
 package v1beta1
 
 import (
+	"path"
+	"strings"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/crossplane/crossplane/internal/dag"
@@ -51,6 +54,13 @@ type LockPackage struct {
 	// Dependencies are the list of dependencies of this package. The order of
 	// the dependencies will dictate the order in which they are resolved.
 	Dependencies []Dependency `json:"dependencies"`
+
+	// Resolved is true if this package was installed automatically by the
+	// package manager to satisfy the dependencies of another package, rather
+	// than being explicitly installed. Resolved packages may be safely updated
+	// to any version that satisfies their dependents' constraints.
+	// +optional
+	Resolved bool `json:"resolved,omitempty"`
 }
 
 // ToNodes converts LockPackages to DAG nodes.
@@ -78,6 +88,44 @@ func (l *LockPackage) Neighbors() []dag.Node {
 	return nodes
 }
 
+// Expand returns a copy of a LockPackage in which each wildcard dependency is
+// replaced by a dependency on every other supplied package of the same type
+// whose source it matches. Wildcard dependencies that match no package are
+// left as is, and will thus be reported as missing.
+func (l *LockPackage) Expand(pkgs ...LockPackage) LockPackage {
+	out := *l
+	out.Dependencies = make([]Dependency, 0, len(l.Dependencies))
+	for _, d := range l.Dependencies {
+		if !d.IsWildcard() {
+			out.Dependencies = append(out.Dependencies, d)
+			continue
+		}
+		matched := false
+		for _, p := range pkgs {
+			if p.Source == l.Source || p.Type != d.Type || !d.Matches(p.Source) {
+				continue
+			}
+			out.Dependencies = append(out.Dependencies, Dependency{Package: p.Source, Type: d.Type, Constraints: d.Constraints, Optional: d.Optional, Channel: d.Channel})
+			matched = true
+		}
+		if !matched {
+			out.Dependencies = append(out.Dependencies, d)
+		}
+	}
+	return out
+}
+
+// ExpandWildcards returns a copy of the supplied LockPackages in which the
+// wildcard dependencies of each package are expanded to the other supplied
+// packages they match.
+func ExpandWildcards(pkgs ...LockPackage) []LockPackage {
+	out := make([]LockPackage, len(pkgs))
+	for i := range pkgs {
+		out[i] = pkgs[i].Expand(pkgs...)
+	}
+	return out
+}
+
 // AddNeighbors adds dependencies to a LockPackage. A LockPackage should always
 // have all dependencies declared before being added to the Lock, so we no-op
 // when adding a neighbor.
@@ -87,7 +135,9 @@ func (l *LockPackage) AddNeighbors(nodes ...dag.Node) error {
 
 // A Dependency is a dependency of a package in the lock.
 type Dependency struct {
-	// Package is the OCI image name without a tag or digest.
+	// Package is the OCI image name without a tag or digest. It may contain
+	// '*' wildcards, in which case any package whose source matches satisfies
+	// the dependency.
 	Package string `json:"package"`
 
 	// Type is the type of package. Can be either Configuration or Provider.
@@ -96,6 +146,34 @@ type Dependency struct {
 	// Constraints is a valid semver range, which will be used to select a valid
 	// dependency version.
 	Constraints string `json:"constraints"`
+
+	// Optional dependencies are never installed by the package manager, and
+	// are not required to be present.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+
+	// Channel is the release channel the dependent package is pinned to. Only
+	// versions in this channel will be used to satisfy the dependency.
+	// +optional
+	Channel string `json:"channel,omitempty"`
+}
+
+// IsWildcard returns true if a dependency's package is a pattern that may
+// match the sources of several packages, for example the members of a family
+// of providers. At least one package must match a wildcard dependency.
+func (d *Dependency) IsWildcard() bool {
+	return strings.Contains(d.Package, "*")
+}
+
+// Matches returns true if the supplied package source satisfies a dependency.
+// Wildcard dependencies are matched using path.Match, so '*' does not match
+// the '/' separating the parts of a source.
+func (d *Dependency) Matches(source string) bool {
+	if !d.IsWildcard() {
+		return d.Package == source
+	}
+	ok, _ := path.Match(d.Package, source)
+	return ok
 }
 
 // Identifier returns a dependency's source.
@@ -0,0 +1,72 @@
+#!/usr/bin/env bash
+set -e
+
+# setting up colors
+BLU='\033[0;34m'
+GRN='\033[0;32m'
+NOC='\033[0m' # No Color
+echo_step() {
+    printf "\n${BLU}>>>>>>> %s${NOC}\n" "$1"
+}
+
+echo_success() {
+    printf "\n${GRN}%s${NOC}\n" "$1"
+}
+
+# ------------------------------
+projectdir="$(cd "$(dirname "${BASH_SOURCE[0]}")"/../.. && pwd)"
+
+# get the build environment variables from the special build.vars target in the main makefile
+eval $(make --no-print-directory -C ${projectdir} build.vars)
+
+SAFEHOSTARCH="${SAFEHOSTARCH:-amd64}"
+BUILD_IMAGE="${BUILD_REGISTRY}/${PROJECT_NAME}-${SAFEHOSTARCH}"
+
+helm_tag="$(cat ${projectdir}/_output/version)"
+CROSSPLANE_IMAGE="${PROJECT_NAME}/${PROJECT_NAME}:${helm_tag}"
+K8S_CLUSTER="${K8S_CLUSTER:-${BUILD_REGISTRY}-upgradetests}"
+
+CROSSPLANE_NAMESPACE="crossplane-system"
+
+# The released version of Crossplane to upgrade from. The latest stable release
+# is used if this is unset.
+CROSSPLANE_FROM_VERSION="${CROSSPLANE_FROM_VERSION:-}"
+CROSSPLANE_STABLE_REPO="https://charts.crossplane.io/stable"
+
+# cleanup on exit
+if [ "$skipcleanup" != true ]; then
+    function cleanup() {
+        echo_step "Cleaning up..."
+        export KUBECONFIG=
+        "${KIND}" delete cluster --name="${K8S_CLUSTER}"
+    }
+
+    trap cleanup EXIT
+fi
+
+echo_step "creating k8s cluster using kind"
+"${KIND}" create cluster --name="${K8S_CLUSTER}"
+
+# tag crossplane image and load it to kind cluster
+docker tag "${BUILD_IMAGE}" "${CROSSPLANE_IMAGE}"
+"${KIND}" load docker-image "${CROSSPLANE_IMAGE}" --name="${K8S_CLUSTER}"
+
+echo_step "installing released helm package ${CROSSPLANE_FROM_VERSION:-latest} into \"${CROSSPLANE_NAMESPACE}\" namespace"
+"${KUBECTL}" create ns "${CROSSPLANE_NAMESPACE}"
+version_flag=""
+if [ -n "${CROSSPLANE_FROM_VERSION}" ]; then
+    version_flag="--version=${CROSSPLANE_FROM_VERSION}"
+fi
+"${HELM3}" install "${PROJECT_NAME}" --namespace "${CROSSPLANE_NAMESPACE}" --repo "${CROSSPLANE_STABLE_REPO}" "${PROJECT_NAME}" ${version_flag} --set replicas=2,rbacManager.replicas=2 --wait
+
+echo_step "waiting for deployment ${PROJECT_NAME} rollout to finish"
+"${KUBECTL}" -n "${CROSSPLANE_NAMESPACE}" rollout status "deploy/${PROJECT_NAME}" --timeout=2m
+
+# ----------- upgrade tests
+echo_step "------------------------------ UPGRADE TESTS"
+E2E_HELM="${HELM3}" \
+E2E_UPGRADE_CHART="${projectdir}/cluster/charts/${PROJECT_NAME}" \
+E2E_UPGRADE_SET="replicas=2,rbacManager.replicas=2,image.pullPolicy=Never,imagePullSecrets=''" \
+    go test -v -count=1 -timeout=20m -tags=e2e "${projectdir}/test/e2e/upgrade"
+
+echo_success "Upgrade tests succeeded!"
@@ -18,6 +18,8 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
@@ -68,8 +70,9 @@ func (c *buildCmd) Run(child *buildChild, logger logging.Logger) error {
 	logger.Debug("Successfully built Object scheme for package parser")
 	img, err := xpkg.Build(context.Background(),
 		parser.NewFsBackend(child.fs, parser.FsDir(root), parser.FsFilters(buildFilters(root, c.Ignore)...)),
-		parser.New(metaScheme, objScheme),
-		child.linter)
+		xpkg.NewStreamParser(metaScheme, objScheme),
+		child.linter,
+		xpkg.WithLintWarningFn(func(err error) { fmt.Fprintf(os.Stderr, "warning: %s\n", err) }))
 	if err != nil {
 		logger.Debug(errBuildPackage, "error", err)
 		return errors.Wrap(err, errBuildPackage)
@@ -112,7 +115,7 @@ func (c *buildCmd) Run(child *buildChild, logger logging.Logger) error {
 func buildFilters(root string, skips []string) []parser.FilterFn {
 	defaultFns := []parser.FilterFn{
 		parser.SkipDirs(),
-		parser.SkipNotYAML(),
+		skipNotYAMLOrJSON(),
 		parser.SkipEmpty(),
 	}
 	opts := make([]parser.FilterFn, len(skips)+len(defaultFns))
@@ -123,12 +126,30 @@ func buildFilters(root string, skips []string) []parser.FilterFn {
 	return opts
 }
 
+// skipNotYAMLOrJSON skips files that don't have a YAML or JSON extension.
+func skipNotYAMLOrJSON() parser.FilterFn {
+	return func(path string, info os.FileInfo) (bool, error) {
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+			return false, nil
+		}
+		return true, nil
+	}
+}
+
 type buildChild struct {
 	name   string
 	linter parser.Linter
 	fs     afero.Fs
 }
 
+// buildRules are stricter than the rules Crossplane lints installed packages
+// against. A package that already broke them may still be installed, but
+// shouldn't be built.
+var buildRules = []xpkg.Rule{
+	{ID: xpkg.RuleNoDuplicateObjects, Severity: xpkg.SeverityError, Check: xpkg.NoDuplicateObjects},
+}
+
 // buildConfigCmd builds a Configuration.
 type buildConfigCmd struct {
 	Name string `optional:"" help:"Name of the package to be built. Uses name in crossplane.yaml if not specified. Does not correspond to package tag."`
@@ -137,7 +158,7 @@ type buildConfigCmd struct {
 // AfterApply sets the name and linter for the parent build command.
 func (c buildConfigCmd) AfterApply(b *buildChild) error { // nolint:unparam
 	b.name = c.Name
-	b.linter = xpkg.NewConfigurationLinter()
+	b.linter = xpkg.NewConfigurationLinter(buildRules...)
 	return nil
 }
 
@@ -149,6 +170,6 @@ type buildProviderCmd struct {
 // AfterApply sets the name and linter for the parent build command.
 func (c buildProviderCmd) AfterApply(b *buildChild) error { // nolint:unparam
 	b.name = c.Name
-	b.linter = xpkg.NewProviderLinter()
+	b.linter = xpkg.NewProviderLinter(buildRules...)
 	return nil
 }
@@ -0,0 +1,269 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/alecthomas/kong"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	pkgv1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/internal/version"
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+const (
+	errReadMeta          = "cannot read crossplane.yaml"
+	errParseMeta         = "cannot parse crossplane.yaml"
+	errWriteMeta         = "cannot write crossplane.yaml"
+	errFmtDepConstraints = "invalid version constraints %q for dependency %q"
+	errFmtDepReference   = "invalid package reference for dependency %q"
+	errFmtDepTags        = "cannot fetch tags of dependency %q"
+	errFmtDepNoVersion   = "no version of dependency %q satisfies constraints %q"
+)
+
+// depCmd manages the dependencies of a package.
+type depCmd struct {
+	Update depUpdateCmd `cmd:"" help:"Update the version constraints of a package's dependencies to require their newest satisfying versions."`
+}
+
+// depUpdateCmd updates the dependencies of a package.
+type depUpdateCmd struct {
+	PackageRoot string `short:"f" help:"Path to package directory." default:"."`
+}
+
+// A tagLister lists the tags of a package's repository.
+type tagLister func(ctx context.Context, ref name.Reference) ([]string, error)
+
+// remoteTags lists the tags of a package's repository using the credentials of
+// the local container tooling, e.g. Docker.
+func remoteTags(ctx context.Context, ref name.Reference) ([]string, error) {
+	return remote.List(ref.Context(), remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithContext(ctx))
+}
+
+// A depUpdate is an update to the version constraints of a dependency.
+type depUpdate struct {
+	Package string
+	From    string
+	To      string
+}
+
+// Run runs the dep update cmd.
+func (c *depUpdateCmd) Run(k *kong.Context, logger logging.Logger) error {
+	path := filepath.Join(c.PackageRoot, xpkg.MetaFile)
+	fs := afero.NewOsFs()
+
+	meta, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return errors.Wrap(err, errReadMeta)
+	}
+	updated, updates, err := updateDependencies(context.Background(), meta, remoteTags)
+	if err != nil {
+		return err
+	}
+	logger.Debug("Resolved dependencies", "updates", len(updates))
+	if len(updates) == 0 {
+		_, err := fmt.Fprintln(k.Stdout, "All dependencies are up to date")
+		return err
+	}
+	if err := afero.WriteFile(fs, path, updated, 0644); err != nil {
+		return errors.Wrap(err, errWriteMeta)
+	}
+	return printUpdates(k.Stdout, updates)
+}
+
+func printUpdates(w io.Writer, updates []depUpdate) error {
+	for _, u := range updates {
+		if _, err := fmt.Fprintf(w, "%s: %s -> %s\n", u.Package, u.From, u.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateDependencies raises the lower bound of the version constraints of each
+// dependency in the supplied package metadata to the newest version that
+// satisfies them, picking versions the same way Crossplane does when it
+// resolves dependencies. Only the constraints are rewritten; the formatting and
+// comments of the package metadata are preserved.
+func updateDependencies(ctx context.Context, meta []byte, tags tagLister) ([]byte, []depUpdate, error) { //nolint:gocyclo
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(meta, doc); err != nil {
+		return nil, nil, errors.Wrap(err, errParseMeta)
+	}
+
+	// Only consider versions in the package's own channel.
+	ch := pkgv1.PackageChannel("")
+	if n := channel(doc); n != nil {
+		ch = pkgv1.PackageChannel(n.Value)
+	}
+
+	lines := strings.Split(string(meta), "\n")
+	updates := make([]depUpdate, 0)
+	for _, dep := range dependencies(doc) {
+		pkg, constraints := "", (*yaml.Node)(nil)
+		for i := 0; i+1 < len(dep.Content); i += 2 {
+			switch dep.Content[i].Value {
+			case "provider", "configuration":
+				pkg = dep.Content[i+1].Value
+			case "version":
+				constraints = dep.Content[i+1]
+			}
+		}
+
+		// We can't know which packages a wildcard dependency refers to.
+		if pkg == "" || constraints == nil || strings.Contains(pkg, "*") {
+			continue
+		}
+
+		c, err := version.ParseConstraints(constraints.Value)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, errFmtDepConstraints, constraints.Value, pkg)
+		}
+		ref, err := name.ParseReference(pkg)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, errFmtDepReference, pkg)
+		}
+		t, err := tags(ctx, ref)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, errFmtDepTags, pkg)
+		}
+		v := xpkg.NewestSatisfyingVersion(c, ch, t)
+		if v == nil {
+			return nil, nil, errors.Errorf(errFmtDepNoVersion, pkg, constraints.Value)
+		}
+
+		raised := raiseConstraints(constraints.Value, ch, v)
+		if raised == constraints.Value {
+			continue
+		}
+		lines[constraints.Line-1] = replaceScalar(lines[constraints.Line-1], constraints.Column-1, raised)
+		updates = append(updates, depUpdate{Package: pkg, From: constraints.Value, To: raised})
+	}
+
+	return []byte(strings.Join(lines, "\n")), updates, nil
+}
+
+// channel returns the spec.channel of the supplied document.
+func channel(doc *yaml.Node) *yaml.Node {
+	return lookup(doc, "spec", "channel")
+}
+
+// dependencies returns the spec.dependsOn entries of the supplied document.
+func dependencies(doc *yaml.Node) []*yaml.Node {
+	n := lookup(doc, "spec", "dependsOn")
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+	deps := make([]*yaml.Node, 0, len(n.Content))
+	for _, d := range n.Content {
+		if d.Kind == yaml.MappingNode {
+			deps = append(deps, d)
+		}
+	}
+	return deps
+}
+
+// lookup returns the node at the supplied path of mapping keys in the supplied
+// document, if any.
+func lookup(doc *yaml.Node, keys ...string) *yaml.Node {
+	n := doc
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	for _, key := range keys {
+		n = mappingValue(n, key)
+		if n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// lowerBound matches a constraint that sets a lower bound, i.e. >=, ~, or ^.
+var lowerBound = regexp.MustCompile(`(>=|=>|~>|~|\^)(\s*)(v?[0-9][^\s,|]*)`)
+
+// raiseConstraints raises each lower bound (i.e. >=, ~, or ^) of any of the
+// supplied constraints that the supplied version satisfies in the supplied
+// channel to that version. Other constraints, including exact versions, are
+// unchanged.
+func raiseConstraints(constraints string, ch pkgv1.PackageChannel, v *semver.Version) string {
+	alternatives := strings.Split(constraints, "||")
+	for i, alt := range alternatives {
+		c, err := version.ParseConstraints(alt)
+		if err != nil || !xpkg.Satisfies(c, ch, v) {
+			continue
+		}
+		alternatives[i] = lowerBound.ReplaceAllStringFunc(alt, func(term string) string {
+			m := lowerBound.FindStringSubmatch(term)
+			bound, err := semver.NewVersion(m[3])
+			if err != nil || !v.GreaterThan(bound) {
+				return term
+			}
+			return m[1] + m[2] + v.Original()
+		})
+	}
+	return strings.Join(alternatives, "||")
+}
+
+// replaceScalar replaces the YAML scalar that starts at the supplied column of
+// the supplied line, preserving its quoting style.
+func replaceScalar(line string, col int, value string) string {
+	rest := line[col:]
+	end := len(rest)
+	quote := ""
+	switch {
+	case strings.HasPrefix(rest, `"`), strings.HasPrefix(rest, `'`):
+		quote = rest[:1]
+		if i := strings.Index(rest[1:], quote); i >= 0 {
+			end = i + 2
+		}
+	default:
+		if i := strings.Index(rest, " #"); i >= 0 {
+			end = i
+		}
+		// Constraints often contain characters YAML treats specially.
+		quote = `"`
+	}
+	return line[:col] + quote + value + quote + rest[end:]
+}
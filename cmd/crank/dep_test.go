@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestUpdateDependencies(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	tags := map[string][]string{
+		"index.docker.io/crossplane/provider-gcp":          {"v0.14.0", "v0.20.0", "v1.0.0", "master"},
+		"index.docker.io/crossplane/provider-aws":          {"v0.24.0", "v0.24.3", "v0.25.0", "v0.26.0-rc.1"},
+		"registry.example.org/platform/configuration-base": {"v1.0.0", "v1.2.0"},
+	}
+	lister := func(_ context.Context, ref name.Reference) ([]string, error) {
+		return tags[ref.Context().Name()], nil
+	}
+
+	type args struct {
+		meta string
+		tags tagLister
+	}
+	type want struct {
+		meta    string
+		updates []depUpdate
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RaiseLowerBounds": {
+			reason: "We should raise the lower bound of each dependency's constraints to the newest satisfying version, leaving everything else untouched.",
+			args: args{
+				meta: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: my-platform
+spec:
+  crossplane:
+    version: ">=v1.6.0"
+  dependsOn:
+  # GCP is our primary cloud.
+  - provider: crossplane/provider-gcp
+    version: ">=v0.14.0, <v1.0.0" # Not ready for v1.
+  - provider: crossplane/provider-aws
+    version: '~v0.24.0'
+  - configuration: registry.example.org/platform/configuration-base
+    version: "v1.0.0"
+  - provider: crossplane/provider-azure-*
+    version: ">=v0.1.0"
+`,
+				tags: lister,
+			},
+			want: want{
+				meta: `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: my-platform
+spec:
+  crossplane:
+    version: ">=v1.6.0"
+  dependsOn:
+  # GCP is our primary cloud.
+  - provider: crossplane/provider-gcp
+    version: ">=v0.20.0, <v1.0.0" # Not ready for v1.
+  - provider: crossplane/provider-aws
+    version: '~v0.24.3'
+  - configuration: registry.example.org/platform/configuration-base
+    version: "v1.0.0"
+  - provider: crossplane/provider-azure-*
+    version: ">=v0.1.0"
+`,
+				updates: []depUpdate{
+					{Package: "crossplane/provider-gcp", From: ">=v0.14.0, <v1.0.0", To: ">=v0.20.0, <v1.0.0"},
+					{Package: "crossplane/provider-aws", From: "~v0.24.0", To: "~v0.24.3"},
+				},
+			},
+		},
+		"RaiseNpmStyleBounds": {
+			reason: "We should raise lower bounds separated by whitespace, and treat a caret on a v0 version as allowing only patch releases.",
+			args: args{
+				meta: "spec:\n  dependsOn:\n  - provider: crossplane/provider-gcp\n    version: \">= v0.14.0 <v1.0.0 || >=v2.0.0\"\n  - provider: crossplane/provider-aws\n    version: \"^v0.24.0\"\n",
+				tags: lister,
+			},
+			want: want{
+				meta: "spec:\n  dependsOn:\n  - provider: crossplane/provider-gcp\n    version: \">= v0.20.0 <v1.0.0 || >=v2.0.0\"\n  - provider: crossplane/provider-aws\n    version: \"^v0.24.3\"\n",
+				updates: []depUpdate{
+					{Package: "crossplane/provider-gcp", From: ">= v0.14.0 <v1.0.0 || >=v2.0.0", To: ">= v0.20.0 <v1.0.0 || >=v2.0.0"},
+					{Package: "crossplane/provider-aws", From: "^v0.24.0", To: "^v0.24.3"},
+				},
+			},
+		},
+		"RaiseWithinChannel": {
+			reason: "We should consider pre-release versions in the package's own channel.",
+			args: args{
+				meta: "spec:\n  channel: beta\n  dependsOn:\n  - provider: crossplane/provider-aws\n    version: \">=v0.24.0\"\n",
+				tags: lister,
+			},
+			want: want{
+				meta: "spec:\n  channel: beta\n  dependsOn:\n  - provider: crossplane/provider-aws\n    version: \">=v0.26.0-rc.1\"\n",
+				updates: []depUpdate{
+					{Package: "crossplane/provider-aws", From: ">=v0.24.0", To: ">=v0.26.0-rc.1"},
+				},
+			},
+		},
+		"ErrNoVersion": {
+			reason: "We should return an error if no version of a dependency satisfies its constraints.",
+			args: args{
+				meta: "spec:\n  dependsOn:\n  - provider: crossplane/provider-gcp\n    version: \">=v2.0.0\"\n",
+				tags: lister,
+			},
+			want: want{
+				err: errors.Errorf(errFmtDepNoVersion, "crossplane/provider-gcp", ">=v2.0.0"),
+			},
+		},
+		"ErrTags": {
+			reason: "We should return an error if we can't list the tags of a dependency.",
+			args: args{
+				meta: "spec:\n  dependsOn:\n  - provider: crossplane/provider-gcp\n    version: \">=v0.1.0\"\n",
+				tags: func(_ context.Context, _ name.Reference) ([]string, error) { return nil, errBoom },
+			},
+			want: want{
+				err: errors.Wrapf(errBoom, errFmtDepTags, "crossplane/provider-gcp"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			meta, updates, err := updateDependencies(context.Background(), []byte(tc.args.meta), tc.args.tags)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nupdateDependencies(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.meta, string(meta)); diff != "" {
+				t.Errorf("\n%s\nupdateDependencies(...): -want meta, +got meta:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.updates, updates); diff != "" {
+				t.Errorf("\n%s\nupdateDependencies(...): -want updates, +got updates:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
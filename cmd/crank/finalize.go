@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane/crossplane/internal/controller/apiextensions/composite"
+)
+
+const (
+	errFmtResolveType   = "cannot resolve type %q"
+	errFmtGetResource   = "cannot get %s %q"
+	errFmtNotDeleting   = "%s %q is not being deleted; delete it before force-finalizing it"
+	errFmtFinalize      = "cannot remove finalizers from %s %q"
+	errReadConfirmation = "cannot read confirmation"
+)
+
+// forceFinalizeCmd removes the finalizers from a composite resource or claim
+// that is stuck deleting.
+type forceFinalizeCmd struct {
+	Type string `arg:"" help:"Type of the composite resource or claim, e.g. xpostgresqlinstances.database.example.org."`
+	Name string `arg:"" help:"Name of the composite resource or claim."`
+
+	Namespace string `short:"n" help:"Namespace of the claim."`
+	Yes       bool   `short:"y" help:"Remove finalizers without asking for confirmation."`
+}
+
+// Run runs the force-finalize cmd.
+func (c *forceFinalizeCmd) Run(k *kong.Context, logger logging.Logger) error { //nolint:gocyclo
+	kubeConfig, err := ctrl.GetConfig()
+	if err != nil {
+		logger.Debug(errKubeConfig, "error", err)
+		return errors.Wrap(err, errKubeConfig)
+	}
+	logger.Debug("Found kubeconfig")
+	kube, err := client.New(kubeConfig, client.Options{})
+	if err != nil {
+		logger.Debug(errKubeClient, "error", err)
+		return errors.Wrap(err, errKubeClient)
+	}
+	logger.Debug("Created kubernetes client")
+
+	gvk, err := kube.RESTMapper().KindFor(schema.ParseGroupResource(c.Type).WithVersion(""))
+	if err != nil {
+		return errors.Wrapf(err, errFmtResolveType, c.Type)
+	}
+
+	ctx := context.Background()
+	u := &kunstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: c.Namespace, Name: c.Name}, u); err != nil {
+		return errors.Wrapf(err, errFmtGetResource, gvk.Kind, c.Name)
+	}
+
+	// Removing finalizers from a resource that is not being deleted would
+	// only cause them to be added again.
+	if u.GetDeletionTimestamp() == nil {
+		return errors.Errorf(errFmtNotDeleting, gvk.Kind, c.Name)
+	}
+	if len(u.GetFinalizers()) == 0 {
+		_, err := fmt.Fprintf(k.Stdout, "%s %q has no finalizers\n", gvk.Kind, c.Name)
+		return err
+	}
+
+	if err := describeStuck(k.Stdout, u); err != nil {
+		return err
+	}
+
+	if !c.Yes {
+		ok, err := confirm(os.Stdin, k.Stdout, "Remove all finalizers? [y/N]: ")
+		if err != nil {
+			return errors.Wrap(err, errReadConfirmation)
+		}
+		if !ok {
+			_, err := fmt.Fprintln(k.Stdout, "Aborted")
+			return err
+		}
+	}
+
+	// We test the resource version to ensure we don't remove finalizers that
+	// were added after we described the resource.
+	p, _ := json.Marshal([]map[string]interface{}{
+		{"op": "test", "path": "/metadata/resourceVersion", "value": u.GetResourceVersion()},
+		{"op": "remove", "path": "/metadata/finalizers"},
+	})
+	if err := kube.Patch(ctx, u, client.RawPatch(types.JSONPatchType, p)); err != nil {
+		return errors.Wrapf(err, errFmtFinalize, gvk.Kind, c.Name)
+	}
+
+	_, err = fmt.Fprintf(k.Stdout, "%s %q finalized\n", gvk.Kind, c.Name)
+	return err
+}
+
+// describeStuck explains why the supplied resource is stuck deleting, and what
+// will be orphaned if its finalizers are removed.
+func describeStuck(w io.Writer, u *kunstructured.Unstructured) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "%s %q is being deleted but is blocked by finalizers: %s\n", u.GetKind(), u.GetName(), strings.Join(u.GetFinalizers(), ", "))
+
+	rr := []composite.RemainingResource{}
+	_ = fieldpath.Pave(u.Object).GetValueInto(composite.FieldPathRemainingResources, &rr)
+	if len(rr) > 0 {
+		fmt.Fprintln(b, "The following composed resources still exist and will be orphaned:")
+		for _, r := range rr {
+			fmt.Fprintf(b, "  - %s %q: %s\n", r.Kind, r.Name, r.Reason)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// confirm prompts for and reads a yes or no answer. Anything other than yes is
+// treated as no.
+func confirm(r io.Reader, w io.Writer, prompt string) (bool, error) {
+	if _, err := io.WriteString(w, prompt); err != nil {
+		return false, err
+	}
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestConfirm(t *testing.T) {
+	type want struct {
+		ok  bool
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		input  string
+		want   want
+	}{
+		"Yes": {
+			reason: "We should treat 'yes' as confirmation.",
+			input:  "yes\n",
+			want:   want{ok: true},
+		},
+		"ShortYesWithoutNewline": {
+			reason: "We should treat 'Y' as confirmation, even if input ends without a newline.",
+			input:  "Y",
+			want:   want{ok: true},
+		},
+		"No": {
+			reason: "We should treat 'n' as a refusal.",
+			input:  "n\n",
+			want:   want{ok: false},
+		},
+		"Empty": {
+			reason: "We should treat no answer as a refusal.",
+			input:  "",
+			want:   want{ok: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ok, err := confirm(strings.NewReader(tc.input), &bytes.Buffer{}, "? ")
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nconfirm(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ok, ok); diff != "" {
+				t.Errorf("\n%s\nconfirm(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDescribeStuck(t *testing.T) {
+	u := &kunstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "XPostgreSQLInstance",
+		"metadata": map[string]interface{}{
+			"name":       "cool-db",
+			"finalizers": []interface{}{"foregroundDeletion"},
+		},
+		"status": map[string]interface{}{
+			"remainingResources": []interface{}{
+				map[string]interface{}{"apiVersion": "example.org/v1", "kind": "CloudSQLInstance", "name": "cool-db-x7s2k", "reason": "Waiting"},
+			},
+		},
+	}}
+
+	want := `XPostgreSQLInstance "cool-db" is being deleted but is blocked by finalizers: foregroundDeletion
+The following composed resources still exist and will be orphaned:
+  - CloudSQLInstance "cool-db-x7s2k": Waiting
+`
+
+	b := &bytes.Buffer{}
+	if err := describeStuck(b, u); err != nil {
+		t.Fatalf("describeStuck(...): %s", err)
+	}
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("describeStuck(...): -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/alecthomas/kong"
+	"github.com/spf13/afero"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+const (
+	errFmtInvalidName   = "invalid package name %q: %s"
+	errFmtInvalidGroup  = "invalid API group %q: %s"
+	errFmtFileExists    = "cannot initialize package: %q already exists"
+	errFmtWriteFile     = "cannot write %q"
+	errFmtRenderFile    = "cannot render %q"
+	errCreateProjectDir = "cannot create package directory"
+)
+
+// initCmd initializes a package.
+type initCmd struct {
+	Configuration initConfigCmd `cmd:"" help:"Initialize a Configuration package."`
+}
+
+// initConfigCmd initializes a Configuration package.
+type initConfigCmd struct {
+	Name string `arg:"" help:"Name of the package to be initialized."`
+
+	Directory         string `short:"d" help:"Directory in which to initialize the package. Defaults to a new directory named after the package."`
+	Group             string `help:"API group of the example composite resource." default:"example.org"`
+	Kind              string `help:"Kind of the example claim. The kind of the example composite resource is prefixed with X." default:"Example"`
+	CrossplaneVersion string `help:"Semantic version constraint of Crossplane versions the package is compatible with." default:">=v1.6.0"`
+}
+
+// A configProject is the data used to render the files of a new Configuration
+// package.
+type configProject struct {
+	Name              string
+	Group             string
+	Kind              string
+	Plural            string
+	CrossplaneVersion string
+}
+
+// The files of a new Configuration package, relative to the package root.
+var configFiles = map[string]*template.Template{
+	xpkg.MetaFile:           template.Must(template.New(xpkg.MetaFile).Parse(tmplConfigMeta)),
+	"apis/definition.yaml":  template.Must(template.New("definition.yaml").Parse(tmplDefinition)),
+	"apis/composition.yaml": template.Must(template.New("composition.yaml").Parse(tmplComposition)),
+	"examples/claim.yaml":   template.Must(template.New("claim.yaml").Parse(tmplClaim)),
+	"Makefile":              template.Must(template.New("Makefile").Parse(tmplMakefile)),
+	".gitignore":            template.Must(template.New(".gitignore").Parse(tmplGitIgnore)),
+}
+
+// Run runs the init configuration cmd.
+func (c *initConfigCmd) Run(k *kong.Context, logger logging.Logger) error {
+	dir := c.Directory
+	if dir == "" {
+		dir = c.Name
+	}
+	if err := c.scaffold(afero.NewOsFs(), dir); err != nil {
+		return err
+	}
+	logger.Debug("Initialized package", "directory", dir)
+	_, err := fmt.Fprintf(k.Stdout, "Initialized Configuration package %q in %s\n", c.Name, dir)
+	return err
+}
+
+// scaffold writes the files of a new Configuration package to the supplied
+// directory. It refuses to overwrite any existing files.
+func (c *initConfigCmd) scaffold(fs afero.Fs, dir string) error {
+	if errs := validation.IsDNS1123Subdomain(c.Name); len(errs) > 0 {
+		return errors.Errorf(errFmtInvalidName, c.Name, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsDNS1123Subdomain(c.Group); len(errs) > 0 {
+		return errors.Errorf(errFmtInvalidGroup, c.Group, strings.Join(errs, ", "))
+	}
+
+	p := configProject{
+		Name:              c.Name,
+		Group:             c.Group,
+		Kind:              c.Kind,
+		Plural:            strings.ToLower(c.Kind) + "s",
+		CrossplaneVersion: c.CrossplaneVersion,
+	}
+
+	for f := range configFiles {
+		path := filepath.Join(dir, f)
+		exists, err := afero.Exists(fs, path)
+		if err != nil {
+			return errors.Wrapf(err, errFmtWriteFile, path)
+		}
+		if exists {
+			return errors.Errorf(errFmtFileExists, path)
+		}
+	}
+
+	if err := fs.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrap(err, errCreateProjectDir)
+	}
+	for f, t := range configFiles {
+		path := filepath.Join(dir, f)
+		b := &strings.Builder{}
+		if err := t.Execute(b, p); err != nil {
+			return errors.Wrapf(err, errFmtRenderFile, path)
+		}
+		if err := fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return errors.Wrapf(err, errFmtWriteFile, path)
+		}
+		if err := afero.WriteFile(fs, path, []byte(b.String()), 0644); err != nil {
+			return errors.Wrapf(err, errFmtWriteFile, path)
+		}
+	}
+	return nil
+}
+
+const tmplConfigMeta = `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: {{ .Name }}
+spec:
+  crossplane:
+    version: "{{ .CrossplaneVersion }}"
+  # Add the providers your Compositions compose resources from, for example:
+  #
+  # dependsOn:
+  # - provider: crossplane/provider-gcp
+  #   version: ">=v0.20.0"
+`
+
+const tmplDefinition = `apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: x{{ .Plural }}.{{ .Group }}
+spec:
+  group: {{ .Group }}
+  names:
+    kind: X{{ .Kind }}
+    plural: x{{ .Plural }}
+  claimNames:
+    kind: {{ .Kind }}
+    plural: {{ .Plural }}
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              parameters:
+                type: object
+                properties:
+                  size:
+                    type: string
+                required:
+                - size
+            required:
+            - parameters
+`
+
+const tmplComposition = `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: x{{ .Plural }}.{{ .Group }}
+spec:
+  compositeTypeRef:
+    apiVersion: {{ .Group }}/v1alpha1
+    kind: X{{ .Kind }}
+  # Add the resources an X{{ .Kind }} composes.
+  resources: []
+`
+
+const tmplClaim = `apiVersion: {{ .Group }}/v1alpha1
+kind: {{ .Kind }}
+metadata:
+  name: example
+  namespace: default
+spec:
+  parameters:
+    size: small
+`
+
+const tmplMakefile = `# The OCI repository and tag to push the package to.
+PACKAGE ?= example/{{ .Name }}
+VERSION ?= v0.1.0
+
+.PHONY: build push validate clean
+
+# Examples aren't part of the package.
+build: clean
+	kubectl crossplane build configuration --ignore "examples/*"
+
+push: build
+	kubectl crossplane push configuration $(PACKAGE):$(VERSION)
+
+validate:
+	kubectl crossplane beta validate apis examples
+
+clean:
+	rm -f *.xpkg
+`
+
+const tmplGitIgnore = `*.xpkg
+`
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+func TestInitConfigScaffold(t *testing.T) {
+	type args struct {
+		cmd      initConfigCmd
+		existing []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"Successful": {
+			reason: "We should write a package that builds and validates.",
+			args: args{
+				cmd: initConfigCmd{Name: "my-platform", Group: "platform.example.org", Kind: "Cluster", CrossplaneVersion: ">=v1.6.0"},
+			},
+		},
+		"ErrInvalidName": {
+			reason: "We should refuse to initialize a package with an invalid name.",
+			args: args{
+				cmd: initConfigCmd{Name: "My Platform", Group: "platform.example.org", Kind: "Cluster"},
+			},
+			want: errors.Errorf(errFmtInvalidName, "My Platform", "a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')"),
+		},
+		"ErrFileExists": {
+			reason: "We should refuse to overwrite an existing package.",
+			args: args{
+				cmd:      initConfigCmd{Name: "my-platform", Group: "platform.example.org", Kind: "Cluster"},
+				existing: []string{"/pkg/" + xpkg.MetaFile},
+			},
+			want: errors.Errorf(errFmtFileExists, "/pkg/"+xpkg.MetaFile),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			for _, f := range tc.args.existing {
+				_ = afero.WriteFile(fs, f, []byte{}, 0600)
+			}
+
+			err := tc.args.cmd.scaffold(fs, "/pkg")
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nscaffold(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+
+			b := buildCmd{PackageRoot: "/pkg", Ignore: []string{"examples/*"}}
+			if err := b.Run(&buildChild{linter: xpkg.NewConfigurationLinter(), fs: fs}, logging.NewNopLogger()); err != nil {
+				t.Errorf("\n%s\nbuildCmd.Run(...): %s", tc.reason, err)
+			}
+
+			objs, err := loadObjects(fs, []string{"/pkg/apis", "/pkg/examples"})
+			if err != nil {
+				t.Fatalf("loadObjects(...): %s", err)
+			}
+			if p := validateObjects(objs); len(p) > 0 {
+				t.Errorf("\n%s\nvalidateObjects(...): %s", tc.reason, strings.Join(p, "\n"))
+			}
+		})
+	}
+}
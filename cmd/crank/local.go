@@ -0,0 +1,211 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/kong"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/spf13/afero"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	typedclient "github.com/crossplane/crossplane/internal/client/clientset/versioned/typed/pkg/v1"
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+const (
+	errLoadPackage       = "cannot load package file"
+	errDigestPackage     = "cannot compute package digest"
+	errReadPackageStream = "cannot read package contents from package file"
+	errListCrossplane    = "cannot list Crossplane pods"
+	errNoCrossplane      = "no running Crossplane pods found"
+	errFmtSeedCache      = "cannot load package into the package cache of pod %q"
+	errGetProvider       = "cannot get provider"
+	errCreateProvider    = "cannot create provider"
+	errUpdateProvider    = "cannot update provider"
+
+	labelCrossplane     = "app=crossplane"
+	containerCrossplane = "crossplane"
+
+	// localTagPrefix prefixes the tag of a local package source. The rest of
+	// the tag is derived from the package digest, so that reloading a changed
+	// package results in a new revision.
+	localTagPrefix = "dev-"
+	localDigestLen = 12
+)
+
+// installLocalCmd installs a provider from a local package file.
+type installLocalCmd struct {
+	File      string `arg:"" type:"existingfile" help:"Package file (.xpkg) to install."`
+	Name      string `optional:"" help:"Name of the Provider. Derived from the package file name if omitted."`
+	Namespace string `short:"n" help:"Namespace in which Crossplane runs." default:"crossplane-system"`
+}
+
+// Run runs the install-local cmd.
+func (c *installLocalCmd) Run(k *kong.Context, logger logging.Logger) error { //nolint:gocyclo
+	img, err := tarball.ImageFromPath(c.File, nil)
+	if err != nil {
+		return errors.Wrap(err, errLoadPackage)
+	}
+	d, err := img.Digest()
+	if err != nil {
+		return errors.Wrap(err, errDigestPackage)
+	}
+	rc, err := packageStream(afero.NewOsFs(), c.File)
+	if err != nil {
+		return err
+	}
+	stream, err := io.ReadAll(rc)
+	_ = rc.Close()
+	if err != nil {
+		return errors.Wrap(err, errReadPackageStream)
+	}
+
+	pkgName := c.Name
+	if pkgName == "" {
+		pkgName = localPackageName(c.File)
+	}
+	src := localSource(pkgName, d.Hex)
+	logger.Debug("Loaded package", "source", src)
+
+	kubeConfig, err := ctrl.GetConfig()
+	if err != nil {
+		logger.Debug(errKubeConfig, "error", err)
+		return errors.Wrap(err, errKubeConfig)
+	}
+	logger.Debug("Found kubeconfig")
+	core, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		logger.Debug(errKubeClient, "error", err)
+		return errors.Wrap(err, errKubeClient)
+	}
+	pkg, err := typedclient.NewForConfig(kubeConfig)
+	if err != nil {
+		logger.Debug(errKubeClient, "error", err)
+		return errors.Wrap(err, errKubeClient)
+	}
+	logger.Debug("Created kubernetes clients")
+
+	ctx := context.Background()
+
+	pods, err := core.CoreV1().Pods(c.Namespace).List(ctx, metav1.ListOptions{LabelSelector: labelCrossplane})
+	if err != nil {
+		return errors.Wrap(err, errListCrossplane)
+	}
+
+	// Every Crossplane pod may reconcile the provider's revisions, so each of
+	// their package caches must contain the package.
+	seeded := 0
+	for _, p := range pods.Items {
+		if p.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		if err := seedCache(kubeConfig, core, p, xpkg.LocalCacheID(src), stream); err != nil {
+			return errors.Wrapf(err, errFmtSeedCache, p.GetName())
+		}
+		logger.Debug("Loaded package into package cache", "pod", p.GetName())
+		seeded++
+	}
+	if seeded == 0 {
+		return errors.New(errNoCrossplane)
+	}
+
+	verb := "configured"
+	cr, err := pkg.Providers().Get(ctx, pkgName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		verb = "created"
+		cr = &v1.Provider{ObjectMeta: metav1.ObjectMeta{Name: pkgName}}
+		cr.SetSource(src)
+		if cr, err = pkg.Providers().Create(ctx, cr, metav1.CreateOptions{}); err != nil {
+			return errors.Wrap(warnIfNotFound(err), errCreateProvider)
+		}
+	case err != nil:
+		return errors.Wrap(warnIfNotFound(err), errGetProvider)
+	default:
+		cr.SetSource(src)
+		if cr, err = pkg.Providers().Update(ctx, cr, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrap(err, errUpdateProvider)
+		}
+	}
+
+	_, err = fmt.Fprintf(k.Stdout, "%s/%s %s\n", strings.ToLower(v1.ProviderGroupKind), cr.GetName(), verb)
+	return err
+}
+
+// localPackageName derives a package name from the supplied package file path,
+// e.g. provider-foo from /tmp/provider-foo.xpkg.
+func localPackageName(path string) string {
+	return xpkg.ToDNSLabel(strings.ToLower(strings.TrimSuffix(filepath.Base(path), xpkg.XpkgExtension)))
+}
+
+// localSource returns the source of a local package with the supplied name
+// and digest.
+func localSource(name, digest string) string {
+	if len(digest) > localDigestLen {
+		digest = digest[:localDigestLen]
+	}
+	return xpkg.LocalSourcePrefix + name + ":" + localTagPrefix + digest
+}
+
+// seedCache stores the supplied package stream in the package cache of the
+// supplied Crossplane pod. The Crossplane image has no shell, so we run a
+// Crossplane command that reads the package stream from stdin.
+func seedCache(cfg *rest.Config, core kubernetes.Interface, p corev1.Pod, id string, stream []byte) error {
+	req := core.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(p.GetNamespace()).
+		Name(p.GetName()).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: containerCrossplane,
+			Command:   []string{"crossplane", "core", "seed-cache", "--id", id},
+			Stdin:     true,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(cfg, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+	stderr := &bytes.Buffer{}
+	if err := exec.Stream(remotecommand.StreamOptions{Stdin: bytes.NewReader(stream), Stdout: io.Discard, Stderr: stderr}); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return errors.Wrap(err, msg)
+		}
+		return err
+	}
+	return nil
+}
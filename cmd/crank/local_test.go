@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+func TestLocalSource(t *testing.T) {
+	type args struct {
+		file   string
+		digest string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   string
+	}{
+		"NameFromFile": {
+			reason: "The package name should be derived from the package file name, and the tag from the start of its digest.",
+			args: args{
+				file:   "/tmp/build/provider-foo.xpkg",
+				digest: "0123456789abcdef0123456789abcdef",
+			},
+			want: "file://provider-foo:dev-0123456789ab",
+		},
+		"InvalidNameFromFile": {
+			reason: "A package name derived from the package file name should be a valid DNS label.",
+			args: args{
+				file:   "Provider-Foo.xpkg",
+				digest: "0123456789abcdef0123456789abcdef",
+			},
+			want: "file://provider-foo:dev-0123456789ab",
+		},
+		"ShortDigest": {
+			reason: "A digest shorter than the tag length should be used whole.",
+			args: args{
+				file:   "provider-foo.xpkg",
+				digest: "0123",
+			},
+			want: "file://provider-foo:dev-0123",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := localSource(localPackageName(tc.args.file), tc.args.digest)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nlocalSource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if _, err := xpkg.ParseSource(got, ""); err != nil {
+				t.Errorf("\n%s\nxpkg.ParseSource(...): unexpected error: %s", tc.reason, err)
+			}
+		})
+	}
+}
@@ -56,10 +56,16 @@ var cli struct {
 	Version versionFlag `short:"v" name:"version" help:"Print version and quit."`
 	Verbose verboseFlag `name:"verbose" help:"Print verbose logging statements."`
 
+	Init    initCmd    `cmd:"" help:"Initialize Crossplane packages."`
 	Build   buildCmd   `cmd:"" help:"Build Crossplane packages."`
 	Install installCmd `cmd:"" help:"Install Crossplane packages."`
 	Update  updateCmd  `cmd:"" help:"Update Crossplane packages."`
 	Push    pushCmd    `cmd:"" help:"Push Crossplane packages."`
+	Dep     depCmd     `cmd:"" help:"Manage the dependencies of Crossplane packages."`
+
+	ForceFinalize forceFinalizeCmd `cmd:"" help:"Remove the finalizers from a composite resource or claim that is stuck deleting."`
+
+	Beta betaCmd `cmd:"" help:"Commands that are not yet stable."`
 }
 
 func main() {
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/alecthomas/kong"
+	"github.com/spf13/afero"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane/crossplane/internal/controlplane"
+)
+
+const (
+	errFmtReadExport = "cannot read exported control plane %q"
+	errRestore       = "cannot restore control plane"
+)
+
+// restoreCmd restores a control plane exported by a ControlPlaneExport.
+type restoreCmd struct {
+	File string `arg:"" type:"existingfile" help:"File containing the exported control plane, i.e. the controlplane.yaml key of the ConfigMap a ControlPlaneExport exported to."`
+
+	Timeout time.Duration `default:"15m" help:"How long to wait for the restored control plane to become ready."`
+}
+
+// Run runs the restore cmd.
+func (c *restoreCmd) Run(k *kong.Context, logger logging.Logger) error {
+	data, err := afero.ReadFile(afero.NewOsFs(), c.File)
+	if err != nil {
+		return errors.Wrapf(err, errFmtReadExport, c.File)
+	}
+	objs, err := controlplane.Unmarshal(data)
+	if err != nil {
+		return errors.Wrapf(err, errFmtReadExport, c.File)
+	}
+	logger.Debug("Read exported control plane", "objects", len(objs))
+
+	kubeConfig, err := ctrl.GetConfig()
+	if err != nil {
+		logger.Debug(errKubeConfig, "error", err)
+		return errors.Wrap(err, errKubeConfig)
+	}
+	logger.Debug("Found kubeconfig")
+	kube, err := client.New(kubeConfig, client.Options{})
+	if err != nil {
+		logger.Debug(errKubeClient, "error", err)
+		return errors.Wrap(err, errKubeClient)
+	}
+	logger.Debug("Created kubernetes client")
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.Timeout)
+	defer cancel()
+	if err := controlplane.NewRestorer(kube, controlplane.WithLogger(logger)).Restore(ctx, objs); err != nil {
+		return errors.Wrap(err, errRestore)
+	}
+	_, err = fmt.Fprintf(k.Stdout, "Restored control plane from %d exported object(s)\n", len(objs))
+	return err
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/alecthomas/kong"
+	corev1 "k8s.io/api/core/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+)
+
+const (
+	errListProviders   = "cannot list providers"
+	errListPods        = "cannot list provider pods"
+	errListPodMetrics  = "cannot list pod metrics; is the metrics API (e.g. metrics-server) available?"
+	errFmtGetRevision  = "cannot get provider revision %q"
+	errFmtCountManaged = "cannot count managed resources of provider %q"
+	errFmtPodUsage     = "cannot determine resource usage of pod %q"
+	errBuildScheme     = "cannot build scheme"
+
+	labelProvider = "pkg.crossplane.io/provider"
+
+	categoryManaged = "managed"
+)
+
+// betaCmd contains commands that are not yet stable.
+type betaCmd struct {
+	InstallLocal installLocalCmd `cmd:"" help:"Install a Provider from a local package file, for development."`
+	Top          topCmd          `cmd:"" help:"Show the CPU and memory used by provider pods, and the managed resources of each provider."`
+	Validate     validateCmd     `cmd:"" help:"Validate Compositions, composite resources, and claims against the schemas of XRDs and provider CRDs without connecting to a cluster."`
+	Restore      restoreCmd      `cmd:"" help:"Restore a control plane exported by a ControlPlaneExport to this cluster."`
+}
+
+// topCmd shows the resources used by each provider.
+type topCmd struct {
+	Namespace string `short:"n" help:"Namespace in which Crossplane runs providers." default:"crossplane-system"`
+}
+
+// A providerUsage describes the resources used by a provider.
+type providerUsage struct {
+	Provider         string
+	Package          string
+	Revision         string
+	Pods             int
+	CPU              kresource.Quantity
+	Memory           kresource.Quantity
+	ManagedResources int
+}
+
+// Run runs the top cmd.
+func (c *topCmd) Run(k *kong.Context, logger logging.Logger) error { //nolint:gocyclo
+	kubeConfig, err := ctrl.GetConfig()
+	if err != nil {
+		logger.Debug(errKubeConfig, "error", err)
+		return errors.Wrap(err, errKubeConfig)
+	}
+	logger.Debug("Found kubeconfig")
+
+	s := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{corev1.AddToScheme, extv1.AddToScheme, v1.AddToScheme} {
+		if err := add(s); err != nil {
+			return errors.Wrap(err, errBuildScheme)
+		}
+	}
+	kube, err := client.New(kubeConfig, client.Options{Scheme: s})
+	if err != nil {
+		logger.Debug(errKubeClient, "error", err)
+		return errors.Wrap(err, errKubeClient)
+	}
+	logger.Debug("Created kubernetes client")
+
+	ctx := context.Background()
+
+	pl := &v1.ProviderList{}
+	if err := kube.List(ctx, pl); err != nil {
+		return errors.Wrap(err, errListProviders)
+	}
+
+	pods := &corev1.PodList{}
+	if err := kube.List(ctx, pods, client.InNamespace(c.Namespace), client.HasLabels{labelProvider}); err != nil {
+		return errors.Wrap(err, errListPods)
+	}
+
+	pml := &kunstructured.UnstructuredList{}
+	pml.SetAPIVersion("metrics.k8s.io/v1beta1")
+	pml.SetKind("PodMetricsList")
+	if err := kube.List(ctx, pml, client.InNamespace(c.Namespace)); err != nil {
+		return errors.Wrap(err, errListPodMetrics)
+	}
+
+	usage := make(map[string]*providerUsage, len(pl.Items))
+	for _, p := range pl.Items {
+		u := &providerUsage{Provider: p.GetName(), Package: p.GetSource(), Revision: p.GetCurrentRevision()}
+		usage[p.GetName()] = u
+
+		if u.Revision == "" {
+			continue
+		}
+		pr := &v1.ProviderRevision{}
+		if err := kube.Get(ctx, types.NamespacedName{Name: u.Revision}, pr); err != nil {
+			return errors.Wrapf(err, errFmtGetRevision, u.Revision)
+		}
+		n, err := countManaged(ctx, kube, pr)
+		if err != nil {
+			return errors.Wrapf(err, errFmtCountManaged, p.GetName())
+		}
+		u.ManagedResources = n
+	}
+
+	if err := addPodUsage(usage, pods.Items, pml.Items); err != nil {
+		return err
+	}
+
+	return printUsage(k.Stdout, usage)
+}
+
+// countManaged counts the managed resources of the supplied revision, i.e. the
+// custom resources of any of its CRDs that are in the 'managed' category.
+func countManaged(ctx context.Context, kube client.Client, pr *v1.ProviderRevision) (int, error) {
+	n := 0
+	for _, ref := range pr.Status.ObjectRefs {
+		if ref.Kind != "CustomResourceDefinition" {
+			continue
+		}
+		crd := &extv1.CustomResourceDefinition{}
+		if err := kube.Get(ctx, types.NamespacedName{Name: ref.Name}, crd); err != nil {
+			return 0, err
+		}
+		if !isManaged(crd) {
+			continue
+		}
+
+		// We only need to count the resources, so we don't fetch their
+		// specs and statuses.
+		l := &metav1.PartialObjectMetadataList{}
+		l.SetGroupVersionKind(schema.GroupVersionKind{Group: crd.Spec.Group, Version: storageVersion(crd), Kind: crd.Spec.Names.ListKind})
+		if err := kube.List(ctx, l); err != nil {
+			return 0, err
+		}
+		n += len(l.Items)
+	}
+	return n, nil
+}
+
+func isManaged(crd *extv1.CustomResourceDefinition) bool {
+	for _, c := range crd.Spec.Names.Categories {
+		if c == categoryManaged {
+			return true
+		}
+	}
+	return false
+}
+
+func storageVersion(crd *extv1.CustomResourceDefinition) string {
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	return ""
+}
+
+// addPodUsage adds the usage of the supplied provider pods to the usage of the
+// provider that runs them. Pods for which no metrics are available are counted,
+// but don't contribute any usage.
+func addPodUsage(usage map[string]*providerUsage, pods []corev1.Pod, metrics []kunstructured.Unstructured) error {
+	byName := make(map[string]kunstructured.Unstructured, len(metrics))
+	for _, m := range metrics {
+		byName[m.GetName()] = m
+	}
+
+	for _, pod := range pods {
+		name := pod.GetLabels()[labelProvider]
+		u, ok := usage[name]
+		if !ok {
+			u = &providerUsage{Provider: name}
+			usage[name] = u
+		}
+		u.Pods++
+
+		m, ok := byName[pod.GetName()]
+		if !ok {
+			continue
+		}
+		cpu, mem, err := podUsage(m)
+		if err != nil {
+			return errors.Wrapf(err, errFmtPodUsage, pod.GetName())
+		}
+		u.CPU.Add(cpu)
+		u.Memory.Add(mem)
+	}
+	return nil
+}
+
+// podUsage returns the CPU and memory used by all containers of the supplied
+// PodMetrics.
+func podUsage(m kunstructured.Unstructured) (kresource.Quantity, kresource.Quantity, error) {
+	containers := []struct {
+		Usage corev1.ResourceList `json:"usage"`
+	}{}
+	if err := fieldpath.Pave(m.Object).GetValueInto("containers", &containers); err != nil && !fieldpath.IsNotFound(err) {
+		return kresource.Quantity{}, kresource.Quantity{}, err
+	}
+
+	cpu, mem := kresource.Quantity{}, kresource.Quantity{}
+	for _, c := range containers {
+		cpu.Add(c.Usage[corev1.ResourceCPU])
+		mem.Add(c.Usage[corev1.ResourceMemory])
+	}
+	return cpu, mem, nil
+}
+
+// printUsage prints a table of provider usage, with the providers using the
+// most CPU first.
+func printUsage(w io.Writer, usage map[string]*providerUsage) error {
+	rows := make([]*providerUsage, 0, len(usage))
+	for _, u := range usage {
+		rows = append(rows, u)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if c := rows[i].CPU.Cmp(rows[j].CPU); c != 0 {
+			return c > 0
+		}
+		return rows[i].Provider < rows[j].Provider
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tPACKAGE\tREVISION\tPODS\tCPU(cores)\tMEMORY(bytes)\tMANAGED RESOURCES")
+	for _, u := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%dm\t%dMi\t%d\n", u.Provider, u.Package, u.Revision, u.Pods, u.CPU.MilliValue(), u.Memory.Value()/(1024*1024), u.ManagedResources)
+	}
+	return tw.Flush()
+}
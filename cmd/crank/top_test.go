@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func podMetrics(name string, usage ...map[string]interface{}) kunstructured.Unstructured {
+	containers := make([]interface{}, len(usage))
+	for i, u := range usage {
+		containers[i] = map[string]interface{}{"name": "c", "usage": u}
+	}
+	u := kunstructured.Unstructured{Object: map[string]interface{}{"containers": containers}}
+	u.SetName(name)
+	return u
+}
+
+func providerPod(name, provider string) corev1.Pod {
+	return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{labelProvider: provider}}}
+}
+
+func TestAddPodUsage(t *testing.T) {
+	type args struct {
+		usage   map[string]*providerUsage
+		pods    []corev1.Pod
+		metrics []kunstructured.Unstructured
+	}
+	type want struct {
+		usage map[string]*providerUsage
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"SumContainersAndPods": {
+			reason: "We should sum the usage of every container of every pod of a provider.",
+			args: args{
+				usage: map[string]*providerUsage{"provider-aws": {Provider: "provider-aws"}},
+				pods:  []corev1.Pod{providerPod("a", "provider-aws"), providerPod("b", "provider-aws")},
+				metrics: []kunstructured.Unstructured{
+					podMetrics("a",
+						map[string]interface{}{"cpu": "100m", "memory": "64Mi"},
+						map[string]interface{}{"cpu": "50m", "memory": "16Mi"},
+					),
+					podMetrics("b", map[string]interface{}{"cpu": "1", "memory": "128Mi"}),
+				},
+			},
+			want: want{
+				usage: map[string]*providerUsage{"provider-aws": {
+					Provider: "provider-aws",
+					Pods:     2,
+					CPU:      kresource.MustParse("1150m"),
+					Memory:   kresource.MustParse("208Mi"),
+				}},
+			},
+		},
+		"MissingMetrics": {
+			reason: "We should count pods for which there are no metrics, and pods of providers we don't know about.",
+			args: args{
+				usage: map[string]*providerUsage{},
+				pods:  []corev1.Pod{providerPod("a", "provider-gcp")},
+			},
+			want: want{
+				usage: map[string]*providerUsage{"provider-gcp": {Provider: "provider-gcp", Pods: 1}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := addPodUsage(tc.args.usage, tc.args.pods, tc.args.metrics)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\naddPodUsage(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.usage, tc.args.usage, cmp.Comparer(func(a, b kresource.Quantity) bool { return a.Cmp(b) == 0 })); diff != "" {
+				t.Errorf("\n%s\naddPodUsage(...): -want usage, +got usage:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPrintUsage(t *testing.T) {
+	usage := map[string]*providerUsage{
+		"provider-gcp": {
+			Provider:         "provider-gcp",
+			Package:          "crossplane/provider-gcp:v0.20.0",
+			Revision:         "provider-gcp-1a2b3c",
+			Pods:             1,
+			CPU:              kresource.MustParse("20m"),
+			Memory:           kresource.MustParse("64Mi"),
+			ManagedResources: 3,
+		},
+		"provider-aws": {
+			Provider:         "provider-aws",
+			Package:          "crossplane/provider-aws:v0.24.0",
+			Revision:         "provider-aws-4d5e6f",
+			Pods:             1,
+			CPU:              kresource.MustParse("1500m"),
+			Memory:           kresource.MustParse("512Mi"),
+			ManagedResources: 42,
+		},
+	}
+
+	want := `PROVIDER       PACKAGE                           REVISION              PODS   CPU(cores)   MEMORY(bytes)   MANAGED RESOURCES
+provider-aws   crossplane/provider-aws:v0.24.0   provider-aws-4d5e6f   1      1500m        512Mi           42
+provider-gcp   crossplane/provider-gcp:v0.20.0   provider-gcp-1a2b3c   1      20m          64Mi            3
+`
+
+	b := &bytes.Buffer{}
+	if err := printUsage(b, usage); err != nil {
+		t.Fatalf("printUsage(...): %s", err)
+	}
+	if diff := cmp.Diff(want, b.String()); diff != "" {
+		t.Errorf("printUsage(...): Providers using the most CPU should be printed first: -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,470 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/alecthomas/kong"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/spf13/afero"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/pruning"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/internal/controller/apiextensions/composite"
+	"github.com/crossplane/crossplane/internal/xcrd"
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+const (
+	errFmtReadPath     = "cannot read %q"
+	errFmtDecode       = "cannot decode %q"
+	errFmtReadPackage  = "cannot read package %q"
+	errFmtProblemCount = "found %d problem(s)"
+	errFmtSchema       = "cannot build schema for %s"
+
+	groupPkgMeta = "meta.pkg.crossplane.io"
+)
+
+// validateCmd validates resources against the schemas of the XRDs and CRDs
+// they're instances of, without connecting to a cluster.
+type validateCmd struct {
+	Paths []string `arg:"" type:"path" help:"Files or directories containing XRDs, CRDs, packages (.xpkg), and the Compositions, composite resources, claims, and other resources to validate."`
+}
+
+// Run runs the validate cmd.
+func (c *validateCmd) Run(k *kong.Context, logger logging.Logger) error {
+	objs, err := loadObjects(afero.NewOsFs(), c.Paths)
+	if err != nil {
+		return err
+	}
+	logger.Debug("Loaded objects", "count", len(objs))
+
+	problems := validateObjects(objs)
+	for _, p := range problems {
+		fmt.Fprintln(k.Stdout, p)
+	}
+	if len(problems) > 0 {
+		return errors.Errorf(errFmtProblemCount, len(problems))
+	}
+	_, err = fmt.Fprintf(k.Stdout, "No problems found in %d object(s)\n", len(objs))
+	return err
+}
+
+// An object loaded from a file.
+type object struct {
+	source string
+	*kunstructured.Unstructured
+}
+
+// loadObjects loads all objects from the supplied files, or from the YAML,
+// JSON, and package files in the supplied directories.
+func loadObjects(fs afero.Fs, paths []string) ([]object, error) {
+	objs := make([]object, 0)
+	for _, root := range paths {
+		err := afero.Walk(fs, root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			ext := filepath.Ext(path)
+			if path != root && ext != ".yaml" && ext != ".yml" && ext != ".json" && ext != xpkg.XpkgExtension {
+				return nil
+			}
+
+			var r io.ReadCloser
+			if ext == xpkg.XpkgExtension {
+				r, err = packageStream(fs, path)
+			} else {
+				r, err = fs.Open(path)
+			}
+			if err != nil {
+				return err
+			}
+			defer r.Close() //nolint:errcheck
+
+			o, err := decodeObjects(path, r)
+			objs = append(objs, o...)
+			return err
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtReadPath, root)
+		}
+	}
+	return objs, nil
+}
+
+// packageStream returns the package.yaml stream of the supplied package file.
+func packageStream(fs afero.Fs, path string) (io.ReadCloser, error) {
+	img, err := tarball.Image(func() (io.ReadCloser, error) { return fs.Open(path) }, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, errFmtReadPackage, path)
+	}
+	rc := mutate.Extract(img)
+	t := tar.NewReader(rc)
+	for {
+		h, err := t.Next()
+		if err != nil {
+			_ = rc.Close()
+			return nil, errors.Wrapf(err, errFmtReadPackage, path)
+		}
+		if h.Name == xpkg.StreamFile {
+			return xpkg.JoinedReadCloser(t, rc), nil
+		}
+	}
+}
+
+// decodeObjects decodes a stream of YAML or JSON objects.
+func decodeObjects(source string, r io.Reader) ([]object, error) {
+	objs := make([]object, 0)
+	d := yaml.NewYAMLOrJSONDecoder(r, 4096)
+	for {
+		u := &kunstructured.Unstructured{}
+		err := d.Decode(&u.Object)
+		if errors.Is(err, io.EOF) {
+			return objs, nil
+		}
+		if err != nil {
+			return objs, errors.Wrapf(err, errFmtDecode, source)
+		}
+		if len(u.Object) == 0 {
+			continue
+		}
+		objs = append(objs, object{source: source, Unstructured: u})
+	}
+}
+
+// validateObjects validates the supplied objects against the schemas of any
+// supplied CRDs and XRDs, and returns a description of each problem found.
+func validateObjects(objs []object) []string {
+	problems := make([]string, 0)
+	report := func(o object, format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf("%s: %s %q: %s", o.source, o.GetKind(), o.GetName(), fmt.Sprintf(format, args...)))
+	}
+
+	s := newSchemaSet()
+	for _, o := range objs {
+		switch o.GroupVersionKind().GroupKind() {
+		case extv1.Kind("CustomResourceDefinition"):
+			crd := &extv1.CustomResourceDefinition{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.Object, crd); err != nil {
+				report(o, "%s", err)
+				continue
+			}
+			if err := s.addCRD(crd); err != nil {
+				report(o, "%s", err)
+			}
+		case v1.CompositeResourceDefinitionGroupVersionKind.GroupKind():
+			xrd := &v1.CompositeResourceDefinition{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.Object, xrd); err != nil {
+				report(o, "%s", err)
+				continue
+			}
+			for _, err := range s.addXRD(xrd) {
+				report(o, "%s", err)
+			}
+		}
+	}
+
+	for _, o := range objs {
+		gvk := o.GroupVersionKind()
+		switch {
+		case gvk.GroupKind() == extv1.Kind("CustomResourceDefinition"), gvk.GroupKind() == v1.CompositeResourceDefinitionGroupVersionKind.GroupKind(), gvk.Group == groupPkgMeta:
+			continue
+		case gvk.GroupKind() == v1.CompositionGroupVersionKind.GroupKind():
+			comp := &v1.Composition{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(o.Object, comp); err != nil {
+				report(o, "%s", err)
+				continue
+			}
+			for _, p := range s.validateComposition(comp) {
+				report(o, "%s", p)
+			}
+		default:
+			p, known := s.validate(o.Unstructured, false)
+			if !known {
+				report(o, "no schema for %s", gvk)
+			}
+			for _, p := range p {
+				report(o, "%s", p)
+			}
+		}
+	}
+
+	return problems
+}
+
+// A schemaSet contains the schemas of known kinds of resource.
+type schemaSet struct {
+	validators map[schema.GroupVersionKind]*validate.SchemaValidator
+	structural map[schema.GroupVersionKind]*structuralschema.Structural
+}
+
+func newSchemaSet() *schemaSet {
+	return &schemaSet{
+		validators: make(map[schema.GroupVersionKind]*validate.SchemaValidator),
+		structural: make(map[schema.GroupVersionKind]*structuralschema.Structural),
+	}
+}
+
+// addXRD adds the schemas of the composite resource and claim the supplied XRD
+// defines.
+func (s *schemaSet) addXRD(xrd *v1.CompositeResourceDefinition) []error {
+	errs := make([]error, 0)
+	crd, err := xcrd.ForCompositeResource(xrd)
+	if err != nil {
+		errs = append(errs, err)
+	} else if err := s.addCRD(crd); err != nil {
+		errs = append(errs, err)
+	}
+	if xrd.Spec.ClaimNames == nil {
+		return errs
+	}
+	crd, err = xcrd.ForCompositeResourceClaim(xrd)
+	if err != nil {
+		errs = append(errs, err)
+	} else if err := s.addCRD(crd); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// addCRD adds the schema of each version of the supplied CRD.
+func (s *schemaSet) addCRD(crd *extv1.CustomResourceDefinition) error {
+	for _, v := range crd.Spec.Versions {
+		if v.Schema == nil {
+			continue
+		}
+		gvk := schema.GroupVersionKind{Group: crd.Spec.Group, Version: v.Name, Kind: crd.Spec.Names.Kind}
+
+		in := &apiextensions.CustomResourceValidation{}
+		if err := extv1.Convert_v1_CustomResourceValidation_To_apiextensions_CustomResourceValidation(v.Schema, in, nil); err != nil {
+			return errors.Wrapf(err, errFmtSchema, gvk)
+		}
+		sv, _, err := validation.NewSchemaValidator(in)
+		if err != nil {
+			return errors.Wrapf(err, errFmtSchema, gvk)
+		}
+		ss, err := structuralschema.NewStructural(in.OpenAPIV3Schema)
+		if err != nil {
+			return errors.Wrapf(err, errFmtSchema, gvk)
+		}
+		s.validators[gvk] = sv
+		s.structural[gvk] = ss
+	}
+	return nil
+}
+
+// validate the supplied object against the schema of its kind. Returns false if
+// the schema of its kind is unknown. Fields that are missing but required are
+// not reported if ignoreRequired is true.
+func (s *schemaSet) validate(u *kunstructured.Unstructured, ignoreRequired bool) ([]string, bool) {
+	gvk := u.GroupVersionKind()
+	sv, ok := s.validators[gvk]
+	if !ok {
+		return nil, false
+	}
+
+	problems := make([]string, 0)
+	for _, err := range validation.ValidateCustomResource(nil, u.Object, sv) {
+		if ignoreRequired && err.Type == field.ErrorTypeRequired {
+			continue
+		}
+		problems = append(problems, err.Error())
+	}
+
+	// Pruning mutates the object, so we prune a copy.
+	for _, path := range pruning.PruneWithOptions(u.DeepCopy().Object, s.structural[gvk], true, pruning.PruneOptions{ReturnPruned: true}) {
+		problems = append(problems, fmt.Sprintf("%s: unknown field", path))
+	}
+	return problems, true
+}
+
+// hasField returns true if the supplied kind of resource has a field at the
+// supplied path. It returns true if the kind of resource is unknown.
+func (s *schemaSet) hasField(gvk schema.GroupVersionKind, path string) bool {
+	ss, ok := s.structural[gvk]
+	if !ok {
+		return true
+	}
+	segments, err := fieldpath.Parse(path)
+	if err != nil {
+		return false
+	}
+	return hasField(ss, segments)
+}
+
+func hasField(s *structuralschema.Structural, segments fieldpath.Segments) bool { //nolint:gocyclo
+	for i, seg := range segments {
+		if s.XPreserveUnknownFields {
+			return true
+		}
+
+		// The schemas of embedded resources, including the resource
+		// itself, don't describe their type and object metadata.
+		if (i == 0 || s.XEmbeddedResource) && seg.Type == fieldpath.SegmentField {
+			switch seg.Field {
+			case "apiVersion", "kind", "metadata":
+				return true
+			}
+		}
+
+		switch seg.Type {
+		case fieldpath.SegmentField:
+			if p, ok := s.Properties[seg.Field]; ok {
+				s = &p
+				continue
+			}
+			if s.AdditionalProperties == nil {
+				return false
+			}
+			if s.AdditionalProperties.Structural == nil {
+				return s.AdditionalProperties.Bool
+			}
+			s = s.AdditionalProperties.Structural
+		case fieldpath.SegmentIndex:
+			if s.Items == nil {
+				return false
+			}
+			s = s.Items
+		}
+	}
+	return true
+}
+
+// validateComposition validates the supplied Composition, including the bases
+// of its resources and the field paths of its patches.
+func (s *schemaSet) validateComposition(comp *v1.Composition) []string { //nolint:gocyclo
+	problems := make([]string, 0)
+	for _, fn := range []func() error{
+		comp.ValidateCreate,
+		func() error { return composite.RejectMixedTemplates(comp) },
+		func() error { return composite.RejectDuplicateNames(comp) },
+	} {
+		if err := fn(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	xr := schema.FromAPIVersionAndKind(comp.Spec.CompositeTypeRef.APIVersion, comp.Spec.CompositeTypeRef.Kind)
+	if _, ok := s.structural[xr]; !ok {
+		problems = append(problems, fmt.Sprintf("no schema for composite resource type %s", xr))
+	}
+
+	ct, err := comp.Spec.ComposedTemplates()
+	if err != nil {
+		return append(problems, err.Error())
+	}
+
+	for i, t := range ct {
+		name := fmt.Sprintf("spec.resources[%d]", i)
+		if t.Name != nil {
+			name = fmt.Sprintf("resource %q", *t.Name)
+		}
+
+		base := &kunstructured.Unstructured{}
+		if err := base.UnmarshalJSON(t.Base.Raw); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: cannot decode base: %s", name, err))
+			continue
+		}
+		cd := base.GroupVersionKind()
+
+		// Patches may set required fields of the base, so we don't require
+		// them to be set.
+		bp, known := s.validate(base, true)
+		if !known {
+			problems = append(problems, fmt.Sprintf("%s: no schema for %s", name, cd))
+		}
+		for _, p := range bp {
+			problems = append(problems, fmt.Sprintf("%s: base: %s", name, p))
+		}
+
+		for j, p := range t.Patches {
+			for _, path := range unknownPatchFields(s, p, xr, cd) {
+				problems = append(problems, fmt.Sprintf("%s: patches[%d]: %s", name, j, path))
+			}
+		}
+
+		for j, d := range t.ConnectionDetails {
+			if d.FromFieldPath != nil && !s.hasField(cd, *d.FromFieldPath) {
+				problems = append(problems, fmt.Sprintf("%s: connectionDetails[%d]: %s has no field %q", name, j, cd.Kind, *d.FromFieldPath))
+			}
+		}
+	}
+	return problems
+}
+
+// unknownPatchFields returns a description of each field that the supplied
+// patch reads from or writes to that doesn't exist.
+func unknownPatchFields(s *schemaSet, p v1.Patch, xr, cd schema.GroupVersionKind) []string {
+	from, to := xr, cd
+	sources := make([]string, 0)
+	switch p.Type {
+	// The API server defaults the type of a patch, but we don't.
+	case "", v1.PatchTypeFromCompositeFieldPath, v1.PatchTypeToCompositeFieldPath:
+		if p.FromFieldPath != nil {
+			sources = append(sources, *p.FromFieldPath)
+		}
+	case v1.PatchTypeCombineFromComposite, v1.PatchTypeCombineToComposite:
+		if p.Combine != nil {
+			for _, v := range p.Combine.Variables {
+				sources = append(sources, v.FromFieldPath)
+			}
+		}
+	case v1.PatchTypePatchSet:
+		return nil
+	}
+	if p.Type == v1.PatchTypeToCompositeFieldPath || p.Type == v1.PatchTypeCombineToComposite {
+		from, to = cd, xr
+	}
+
+	out := make([]string, 0)
+	for _, path := range sources {
+		if !s.hasField(from, path) {
+			out = append(out, fmt.Sprintf("%s has no field %q", from.Kind, path))
+		}
+	}
+	dst := p.ToFieldPath
+	if dst == nil {
+		dst = p.FromFieldPath
+	}
+	if dst != nil && !s.hasField(to, *dst) {
+		out = append(out, fmt.Sprintf("%s has no field %q", to.Kind, *dst))
+	}
+	return out
+}
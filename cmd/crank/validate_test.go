@@ -0,0 +1,198 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+)
+
+const validateXRD = `
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xdatabases.example.org
+spec:
+  group: example.org
+  names:
+    kind: XDatabase
+    plural: xdatabases
+  claimNames:
+    kind: Database
+    plural: databases
+  versions:
+  - name: v1alpha1
+    served: true
+    referenceable: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              storageGB:
+                type: integer
+            required:
+            - storageGB
+`
+
+const validateCRD = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: instances.db.example.org
+spec:
+  group: db.example.org
+  names:
+    kind: Instance
+    plural: instances
+  scope: Cluster
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              forProvider:
+                type: object
+                properties:
+                  size:
+                    type: integer
+                  engine:
+                    type: string
+                required:
+                - engine
+            required:
+            - forProvider
+          status:
+            type: object
+            properties:
+              atProvider:
+                type: object
+                x-kubernetes-preserve-unknown-fields: true
+`
+
+const validateComposition = `
+apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: xdatabases
+spec:
+  compositeTypeRef:
+    apiVersion: example.org/v1alpha1
+    kind: XDatabase
+  resources:
+  - name: instance
+    base:
+      apiVersion: db.example.org/v1beta1
+      kind: Instance
+      spec:
+        forProvider:
+          engine: postgres
+    patches:
+    - fromFieldPath: spec.storageGB
+      toFieldPath: spec.forProvider.size
+    - fromFieldPath: spec.storageGBs
+      toFieldPath: spec.forProvider.sizes
+    - type: ToCompositeFieldPath
+      fromFieldPath: status.atProvider.endpoint
+      toFieldPath: metadata.annotations[example.org/endpoint]
+    connectionDetails:
+    - fromFieldPath: status.atProvider.endpoint
+`
+
+const validateClaims = `
+apiVersion: example.org/v1alpha1
+kind: Database
+metadata:
+  name: good
+  namespace: default
+spec:
+  storageGB: 20
+---
+apiVersion: example.org/v1alpha1
+kind: Database
+metadata:
+  name: bad
+  namespace: default
+spec:
+  storageGB: twenty
+  size: 20
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unknown
+`
+
+func TestValidateObjects(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		files  map[string]string
+		want   []string
+	}{
+		"Valid": {
+			reason: "We should not report problems with objects that match their schemas.",
+			files: map[string]string{
+				"xrd.yaml":   validateXRD,
+				"claim.yaml": "apiVersion: example.org/v1alpha1\nkind: Database\nmetadata:\n  name: good\nspec:\n  storageGB: 20\n",
+			},
+			want: []string{},
+		},
+		"Invalid": {
+			reason: "We should report objects that don't match their schemas, objects we have no schema for, and patches to fields that don't exist.",
+			files: map[string]string{
+				"xrd.yaml":         validateXRD,
+				"crd.yaml":         validateCRD,
+				"composition.yaml": validateComposition,
+				"claims.yaml":      validateClaims,
+			},
+			want: []string{
+				`/claims.yaml: Database "bad": spec.storageGB: Invalid value: "string": spec.storageGB in body must be of type integer: "string"`,
+				`/claims.yaml: Database "bad": spec.size: unknown field`,
+				`/claims.yaml: ConfigMap "unknown": no schema for /v1, Kind=ConfigMap`,
+				`/composition.yaml: Composition "xdatabases": resource "instance": patches[1]: XDatabase has no field "spec.storageGBs"`,
+				`/composition.yaml: Composition "xdatabases": resource "instance": patches[1]: Instance has no field "spec.forProvider.sizes"`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			for f, content := range tc.files {
+				_ = afero.WriteFile(fs, "/"+f, []byte(content), 0600)
+			}
+			objs, err := loadObjects(fs, []string{"/"})
+			if err != nil {
+				t.Fatalf("loadObjects(...): %s", err)
+			}
+			got := validateObjects(objs)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nvalidateObjects(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
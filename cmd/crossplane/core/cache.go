@@ -0,0 +1,42 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+// seedCacheCommand stores package contents read from stdin in the package
+// cache. The Crossplane image has no shell, so the CLI runs this command in a
+// Crossplane pod to load local packages into its cache.
+type seedCacheCommand struct {
+	CacheDir string `short:"c" help:"Directory used for caching package images." default:"/cache" env:"CACHE_DIR"`
+	ID       string `name:"id" required:"" help:"Identifier under which the package contents are cached."`
+}
+
+// Run stores the package contents.
+func (c *seedCacheCommand) Run() error {
+	pc := xpkg.NewFsPackageCache(c.CacheDir, afero.NewOsFs())
+	return errors.Wrap(pc.Store(c.ID, io.NopCloser(os.Stdin)), "cannot store package contents in cache")
+}
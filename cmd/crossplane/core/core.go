@@ -17,14 +17,19 @@ limitations under the License.
 package core
 
 import (
+	"strings"
 	"time"
 
 	"github.com/alecthomas/kong"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/spf13/afero"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -33,17 +38,38 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 
 	apiextensionsv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	pkgv1 "github.com/crossplane/crossplane/apis/pkg/v1"
 	"github.com/crossplane/crossplane/internal/controller/apiextensions"
+	"github.com/crossplane/crossplane/internal/controller/apiextensions/claim"
+	"github.com/crossplane/crossplane/internal/controller/apiextensions/composite"
+	"github.com/crossplane/crossplane/internal/controller/apiextensions/composition"
+	apiextensionscontroller "github.com/crossplane/crossplane/internal/controller/apiextensions/controller"
 	"github.com/crossplane/crossplane/internal/controller/pkg"
 	pkgcontroller "github.com/crossplane/crossplane/internal/controller/pkg/controller"
+	pkgmanager "github.com/crossplane/crossplane/internal/controller/pkg/manager"
 	"github.com/crossplane/crossplane/internal/features"
+	"github.com/crossplane/crossplane/internal/loglevel"
+	xpmetrics "github.com/crossplane/crossplane/internal/metrics"
+	"github.com/crossplane/crossplane/internal/probe"
+	"github.com/crossplane/crossplane/internal/profile"
+	xpwebhook "github.com/crossplane/crossplane/internal/webhook"
 	"github.com/crossplane/crossplane/internal/xpkg"
 )
 
+const (
+	// logLevelsInterval is how often the log levels ConfigMap is reread.
+	logLevelsInterval = 10 * time.Second
+
+	// profileInterval is how often the profile Secret is reread.
+	profileInterval = 10 * time.Second
+)
+
 // Command runs the core crossplane controllers
 type Command struct {
 	Start startCommand `cmd:"" help:"Start Crossplane controllers."`
 	Init  initCommand  `cmd:"" help:"Make cluster ready for Crossplane controllers."`
+
+	SeedCache seedCacheCommand `cmd:"" hidden:"" help:"Store package contents read from stdin in the package cache."`
 }
 
 // KongVars represent the kong variables associated with the CLI parser
@@ -72,12 +98,49 @@ type startCommand struct {
 	PollInterval     time.Duration `help:"How often individual resources will be checked for drift from the desired state." default:"1m"`
 	MaxReconcileRate int           `help:"The global maximum rate per second at which resources may checked for drift from the desired state." default:"10"`
 
+	PackageActivationWebhookURL string `help:"URL of a webhook, for example an image vulnerability scanner, that is asked whether each package revision may be activated. Revisions the webhook denies are not activated. An empty URL disables the webhook." env:"PACKAGE_ACTIVATION_WEBHOOK_URL"`
+
+	LogLevelsConfigMap string `help:"Name of a ConfigMap in Crossplane's namespace that lists controllers that should emit debug logs. It is reread periodically, so debug logs can be enabled without restarting Crossplane." default:"crossplane-log-levels" env:"LOG_LEVELS_CONFIG_MAP"`
+
+	EnableProfiling bool   `help:"Serve pprof profiling endpoints under /debug/pprof/ on the metrics port." env:"ENABLE_PROFILING"`
+	ProfileSecret   string `help:"Name of a Secret in Crossplane's namespace that may be annotated to request that profiles be captured. Profiles are written to the Secret unless a profile directory is specified." default:"crossplane-profile" env:"PROFILE_SECRET"`
+	ProfileDir      string `help:"Directory, for example a mounted PersistentVolumeClaim, to which requested profiles are written." env:"PROFILE_DIR"`
+
+	HealthProbeBindAddress  string        `help:"The address on which the /healthz liveness and /readyz readiness endpoints are served. An empty address disables them." default:":8081" env:"HEALTH_PROBE_BIND_ADDRESS"`
+	WorkqueueStallThreshold time.Duration `help:"How long a controller may process the same item before Crossplane is considered unhealthy and should be restarted." default:"10m" env:"WORKQUEUE_STALL_THRESHOLD"`
+
+	MaxComposedResources int `help:"The maximum number of resources a composite resource may compose. Zero means no limit." default:"0" env:"MAX_COMPOSED_RESOURCES"`
+	MaxRenderedBytes     int `help:"The maximum size in bytes of a rendered composed resource, serialized as JSON. Zero means no limit." default:"1572864" env:"MAX_RENDERED_BYTES"`
+
+	CompositionSecretPatchPolicy string `help:"What to do when a Composition patches the data of a composed Secret into fields that are not secret. Allow, Warn, or Deny." default:"Warn" enum:"Allow,Warn,Deny" env:"COMPOSITION_SECRET_PATCH_POLICY"`
+
+	ComposedEventPropagation string `help:"Which objects are told when a composed resource fails to sync, for example because its provider can't create it. None, Composite, or Claim, which tells both the composite resource and its claim." default:"Composite" enum:"None,Composite,Claim" env:"COMPOSED_EVENT_PROPAGATION"`
+
+	PackageApplyConflictPolicy string `help:"What to do when applying an object of a package, or a provider's runtime resources, would change fields that were set by someone else. Force overwrites them, Fail reports the conflict on the package revision." default:"Force" enum:"Force,Fail" env:"PACKAGE_APPLY_CONFLICT_POLICY"`
+
+	PackageUnhealthyDependencyPolicy string `help:"What to do when a dependency of a package is installed but its active revision isn't healthy. Ignore considers it resolved, Report reports it in the DependenciesResolved condition of the package revision, Block also waits for it to become healthy before installing the package." default:"Ignore" enum:"Ignore,Report,Block" env:"PACKAGE_UNHEALTHY_DEPENDENCY_POLICY"`
+
+	PackageScopedCache           bool  `help:"Cache the contents of each package's revisions in a directory of the package's own, so that the size of each package's cached contents can be limited and is reported as a metric." env:"PACKAGE_SCOPED_CACHE"`
+	PackageCacheQuota            int64 `help:"The maximum size in bytes of the compressed contents cached for each package when the package cache is scoped per package. Revisions whose contents would exceed it are installed but not cached. Zero means no limit." default:"0" env:"PACKAGE_CACHE_QUOTA"`
+	PackageCacheDetectCorruption bool  `help:"Detect package contents that were corrupted while cached, by checking that they have the digest they were cached with. Corrupt contents are fetched again. Tampering is not detected." env:"PACKAGE_CACHE_DETECT_CORRUPTION"`
+
+	MaxConcurrentPackageEstablishers int `help:"The maximum number of objects, such as CRDs, a package revision may create or take ownership of at the same time." default:"10" env:"MAX_CONCURRENT_PACKAGE_ESTABLISHERS"`
+
+	EventSuppressionWindow time.Duration `help:"How long identical events about the same object are suppressed for once one has been recorded. The number of suppressed events is included in the next identical event. Zero disables suppression." default:"5m" env:"EVENT_SUPPRESSION_WINDOW"`
+
+	DisableRuntimeRepair bool `help:"Don't immediately repair provider Deployments, ServiceAccounts, and Services that are changed or deleted out-of-band. They are repaired when their provider is next reconciled." env:"DISABLE_RUNTIME_REPAIR"`
+
+	DefaultLabels map[string]string `help:"Labels, for example cluster=prod;env=dev, added to every resource Crossplane composes and every provider Deployment it creates. Labels set by a Composition or ControllerConfig take precedence." env:"DEFAULT_LABELS"`
+
 	EnableCompositionRevisions bool `group:"Alpha Features:" help:"Enable support for CompositionRevisions."`
 	EnableExternalSecretStores bool `group:"Alpha Features:" help:"Enable support for ExternalSecretStores."`
+	EnablePackageTests         bool `group:"Alpha Features:" help:"Enable running the tests declared by Configuration packages once they're installed."`
+	EnableObjectTransformers   bool `group:"Alpha Features:" help:"Enable transforming the objects of packages before they're installed, using the transformers built into this Crossplane binary."`
+	EnablePackagePropagation   bool `group:"Alpha Features:" help:"Enable propagating packages to spoke clusters using PackagePropagations."`
 }
 
 // Run core Crossplane controllers.
-func (c *startCommand) Run(s *runtime.Scheme, log logging.Logger) error { //nolint:gocyclo
+func (c *startCommand) Run(s *runtime.Scheme, log logging.Logger, levels *loglevel.Levels) error { //nolint:gocyclo
 	cfg, err := ctrl.GetConfig()
 	if err != nil {
 		return errors.Wrap(err, "Cannot get config")
@@ -99,11 +162,64 @@ func (c *startCommand) Run(s *runtime.Scheme, log logging.Logger) error { //noli
 		LeaderElectionResourceLock: resourcelock.LeasesResourceLock,
 		LeaseDuration:              func() *time.Duration { d := 60 * time.Second; return &d }(),
 		RenewDeadline:              func() *time.Duration { d := 50 * time.Second; return &d }(),
+
+		HealthProbeBindAddress: c.HealthProbeBindAddress,
 	})
 	if err != nil {
 		return errors.Wrap(err, "Cannot create manager")
 	}
 
+	if c.HealthProbeBindAddress != "" {
+		if err := mgr.AddHealthzCheck("workqueues", probe.StalledWorkqueues(metrics.Registry, c.WorkqueueStallThreshold)); err != nil {
+			return errors.Wrap(err, "Cannot add workqueue liveness check to manager")
+		}
+		// These controllers install packages and serve XRDs. If they're not
+		// running nothing else Crossplane does will work.
+		critical := []string{
+			"defined/" + strings.ToLower(apiextensionsv1.CompositeResourceDefinitionGroupKind),
+			"offered/" + strings.ToLower(apiextensionsv1.CompositeResourceDefinitionGroupKind),
+			"packages/" + strings.ToLower(pkgv1.ProviderGroupKind),
+			"packages/" + strings.ToLower(pkgv1.ConfigurationGroupKind),
+		}
+		leader := probe.NewLeader()
+		if err := mgr.Add(leader); err != nil {
+			return errors.Wrap(err, "Cannot add leader tracker to manager")
+		}
+		if err := mgr.AddReadyzCheck("controllers", probe.ControllersRunning(leader, metrics.Registry, critical...)); err != nil {
+			return errors.Wrap(err, "Cannot add controller readiness check to manager")
+		}
+	}
+
+	if c.LogLevelsConfigMap != "" {
+		nn := types.NamespacedName{Namespace: c.Namespace, Name: c.LogLevelsConfigMap}
+		if err := mgr.Add(loglevel.NewConfigMapWatcher(mgr.GetAPIReader(), nn, levels, log, logLevelsInterval)); err != nil {
+			return errors.Wrap(err, "Cannot add log levels watcher to manager")
+		}
+	}
+
+	if c.EnableProfiling {
+		if err := profile.AddHandlers(mgr.AddMetricsExtraHandler); err != nil {
+			return errors.Wrap(err, "Cannot add profiling endpoints to manager")
+		}
+		log.Info("Profiling endpoints enabled", "path", profile.PathPrefix)
+	}
+
+	if c.ProfileSecret != "" {
+		// We use an uncached client in order to avoid caching all Secrets.
+		cl, err := client.New(cfg, client.Options{Scheme: s})
+		if err != nil {
+			return errors.Wrap(err, "Cannot create profile client")
+		}
+		o := []profile.SecretWatcherOption{profile.WithLogger(log)}
+		if c.ProfileDir != "" {
+			o = append(o, profile.WithStore(profile.NewFsStore(c.ProfileDir, afero.NewOsFs())))
+		}
+		nn := types.NamespacedName{Namespace: c.Namespace, Name: c.ProfileSecret}
+		if err := mgr.Add(profile.NewSecretWatcher(cl, nn, profileInterval, o...)); err != nil {
+			return errors.Wrap(err, "Cannot add profile watcher to manager")
+		}
+	}
+
 	feats := &feature.Flags{}
 	if c.EnableCompositionRevisions {
 		feats.Enable(features.EnableAlphaCompositionRevisions)
@@ -113,6 +229,18 @@ func (c *startCommand) Run(s *runtime.Scheme, log logging.Logger) error { //noli
 		feats.Enable(features.EnableAlphaExternalSecretStores)
 		log.Info("Alpha feature enabled", "flag", features.EnableAlphaExternalSecretStores)
 	}
+	if c.EnablePackageTests {
+		feats.Enable(features.EnableAlphaPackageTests)
+		log.Info("Alpha feature enabled", "flag", features.EnableAlphaPackageTests)
+	}
+	if c.EnableObjectTransformers {
+		feats.Enable(features.EnableAlphaObjectTransformers)
+		log.Info("Alpha feature enabled", "flag", features.EnableAlphaObjectTransformers)
+	}
+	if c.EnablePackagePropagation {
+		feats.Enable(features.EnableAlphaPackagePropagation)
+		log.Info("Alpha feature enabled", "flag", features.EnableAlphaPackagePropagation)
+	}
 
 	o := controller.Options{
 		Logger:                  log,
@@ -122,17 +250,45 @@ func (c *startCommand) Run(s *runtime.Scheme, log logging.Logger) error { //noli
 		Features:                feats,
 	}
 
-	if err := apiextensions.Setup(mgr, o); err != nil {
+	ao := apiextensionscontroller.Options{
+		Options: o,
+		CompositeLimits: composite.Limits{
+			MaxComposedResources: c.MaxComposedResources,
+			MaxRenderedBytes:     c.MaxRenderedBytes,
+		},
+		CompositeSecretPatchPolicy: composite.SecretPatchPolicy(c.CompositionSecretPatchPolicy),
+		ComposedEventPolicy:        composite.ComposedEventPolicy(c.ComposedEventPropagation),
+		DefaultComposedLabels:      c.DefaultLabels,
+		EventSuppressionWindow:     c.EventSuppressionWindow,
+	}
+
+	if err := apiextensions.Setup(mgr, ao); err != nil {
 		return errors.Wrap(err, "Cannot setup API extension controllers")
 	}
 
+	copts := []xpkg.FsPackageCacheOption{xpkg.WithCacheMetricsRecorder(xpmetrics.NewPrometheusCacheRecorder()), xpkg.WithCacheLogger(log)}
+	if c.PackageScopedCache {
+		copts = append(copts, xpkg.WithPackageQuota(c.PackageCacheQuota))
+	}
+	if c.PackageCacheDetectCorruption {
+		copts = append(copts, xpkg.WithCorruptionDetection())
+	}
+
 	po := pkgcontroller.Options{
-		Options:              o,
-		Cache:                xpkg.NewFsPackageCache(c.CacheDir, afero.NewOsFs()),
-		Namespace:            c.Namespace,
-		DefaultRegistry:      c.Registry,
-		Features:             feats,
-		WebhookTLSSecretName: c.WebhookTLSSecretName,
+		Options:                   o,
+		Cache:                     xpkg.NewFsPackageCache(c.CacheDir, afero.NewOsFs(), copts...),
+		ScopedCache:               c.PackageScopedCache,
+		Namespace:                 c.Namespace,
+		DefaultRegistry:           c.Registry,
+		Features:                  feats,
+		WebhookTLSSecretName:      c.WebhookTLSSecretName,
+		DisableRuntimeRepair:      c.DisableRuntimeRepair,
+		ApplyConflictPolicy:       pkgcontroller.ApplyConflictPolicy(c.PackageApplyConflictPolicy),
+		UnhealthyDependencyPolicy: pkgcontroller.UnhealthyDependencyPolicy(c.PackageUnhealthyDependencyPolicy),
+		MaxConcurrentEstablishers: c.MaxConcurrentPackageEstablishers,
+		EventSuppressionWindow:    c.EventSuppressionWindow,
+		DefaultLabels:             c.DefaultLabels,
+		ActivationWebhookURL:      c.PackageActivationWebhookURL,
 	}
 
 	if c.CABundlePath != "" {
@@ -158,6 +314,16 @@ func (c *startCommand) Run(s *runtime.Scheme, log logging.Logger) error { //noli
 		if err := (&apiextensionsv1.CompositeResourceDefinition{}).SetupWebhookWithManager(mgr); err != nil {
 			return errors.Wrap(err, "cannot setup webhook for compositeresourcedefinitions")
 		}
+		ws.Register(composition.WebhookPath, &webhook.Admission{Handler: composition.NewValidator(mgr.GetClient(), mgr.GetRESTMapper())})
+		ws.Register(claim.WebhookPath, &webhook.Admission{Handler: xpwebhook.MultiValidatingHandler(
+			claim.NewNamespaceValidator(mgr.GetClient()),
+			claim.NewQuotaValidator(mgr.GetClient()),
+			claim.NewDeprecationWarner(mgr.GetClient()),
+			claim.NewCompositionWarner(mgr.GetClient()),
+		)})
+		if err := pkgmanager.SetupWebhook(mgr, po); err != nil {
+			return errors.Wrap(err, "cannot setup webhook for packages")
+		}
 	}
 
 	return errors.Wrap(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
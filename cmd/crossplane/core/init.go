@@ -37,6 +37,8 @@ type initCommand struct {
 	Configurations []string `name:"configuration" help:"Pre-install a Configuration by giving its image URI. This argument can be repeated."`
 	Namespace      string   `short:"n" help:"Namespace used to set as default scope in default secret store config." default:"crossplane-system" env:"POD_NAMESPACE"`
 
+	BootstrapConfigMap string `help:"The name of a ConfigMap in the Crossplane namespace whose providers and configurations keys list packages to pre-install, one image URI per line." env:"BOOTSTRAP_CONFIG_MAP"`
+
 	WebhookTLSSecretName    string `help:"The name of the Secret that the initializer will fill with webhook TLS certificate bundle." env:"WEBHOOK_TLS_SECRET_NAME"`
 	WebhookServiceName      string `help:"The name of the Service object that the webhook service will be run." env:"WEBHOOK_SERVICE_NAME"`
 	WebhookServiceNamespace string `help:"The namespace of the Service object that the webhook service will be run." env:"WEBHOOK_SERVICE_NAMESPACE"`
@@ -74,8 +76,14 @@ func (c *initCommand) Run(s *runtime.Scheme, log logging.Logger) error {
 		steps = append(steps, initializer.NewCoreCRDs("/crds", s))
 	}
 
+	var pkgOpts []initializer.PackageInstallerOption
+	if c.BootstrapConfigMap != "" {
+		pkgOpts = append(pkgOpts, initializer.WithBootstrapConfigMap(types.NamespacedName{Namespace: c.Namespace, Name: c.BootstrapConfigMap}))
+	}
+
 	steps = append(steps, initializer.NewLockObject(),
-		initializer.NewPackageInstaller(c.Providers, c.Configurations),
+		initializer.NewClusterHealthObject(),
+		initializer.NewPackageInstaller(c.Providers, c.Configurations, pkgOpts...),
 		initializer.NewStoreConfigObject(c.Namespace))
 	if err := initializer.New(cl, log, steps...).Init(context.TODO()); err != nil {
 		return errors.Wrap(err, "cannot initialize core")
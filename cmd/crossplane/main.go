@@ -18,6 +18,7 @@ package main
 
 import (
 	"github.com/alecthomas/kong"
+	"go.uber.org/zap/zapcore"
 	admv1 "k8s.io/api/admissionregistration/v1"
 	appsv1 "k8s.io/api/apps/v1"
 	coordinationv1 "k8s.io/api/coordination/v1"
@@ -33,6 +34,7 @@ import (
 	"github.com/crossplane/crossplane/apis"
 	"github.com/crossplane/crossplane/cmd/crossplane/core"
 	"github.com/crossplane/crossplane/cmd/crossplane/rbac"
+	"github.com/crossplane/crossplane/internal/loglevel"
 )
 
 type debugFlag bool
@@ -63,7 +65,10 @@ func (d debugFlag) BeforeApply(ctx *kong.Context) error { // nolint:unparam
 }
 
 func main() {
-	zl := zap.New().WithName("crossplane")
+	// Our logger is capable of emitting debug logs, but only does so for the
+	// controllers that our log levels enable debug logs for.
+	zl := zap.New(zap.Level(zapcore.DebugLevel)).WithName("crossplane")
+	levels := loglevel.NewLevels()
 
 	// Note that the controller managers scheme must be a superset of the
 	// package manager's object scheme; it must contain all object types that
@@ -75,7 +80,8 @@ func main() {
 	ctx := kong.Parse(&cli,
 		kong.Name("crossplane"),
 		kong.Description("An open source multicloud control plane."),
-		kong.BindTo(logging.NewLogrLogger(zl), (*logging.Logger)(nil)),
+		kong.BindTo(loglevel.NewLogger(logging.NewLogrLogger(zl), levels), (*logging.Logger)(nil)),
+		kong.Bind(levels),
 		kong.UsageOnError(),
 		rbac.KongVars,
 		core.KongVars,
@@ -69,6 +69,11 @@ type startCommand struct {
 	ProviderClusterRole string `name:"provider-clusterrole" help:"A ClusterRole enumerating the permissions provider packages may request."`
 	LeaderElection      bool   `name:"leader-election" short:"l" help:"Use leader election for the conroller manager." env:"LEADER_ELECTION"`
 	ManagementPolicy    string `name:"manage" short:"m" help:"RBAC management policy." default:"${rbac_manage_default_var}" enum:"${rbac_manage_enum_var}"`
+	DryRun              bool   `name:"dry-run" help:"Log the roles and bindings that would be created or updated, without creating or updating them." env:"DRY_RUN"`
+
+	AggregateToAdmin map[string]string `name:"aggregate-to-admin" help:"Labels, for example key=value, to add to ClusterRoles that aggregate to the Crossplane admin ClusterRole, so that they also aggregate to another ClusterRole."`
+	AggregateToEdit  map[string]string `name:"aggregate-to-edit" help:"Labels, for example key=value, to add to ClusterRoles that aggregate to the Crossplane edit ClusterRole, so that they also aggregate to another ClusterRole."`
+	AggregateToView  map[string]string `name:"aggregate-to-view" help:"Labels, for example key=value, to add to ClusterRoles that aggregate to the Crossplane view ClusterRole, so that they also aggregate to another ClusterRole."`
 
 	SyncInterval     time.Duration `short:"s" help:"How often all resources will be double-checked for drift from the desired state." default:"1h"`
 	PollInterval     time.Duration `help:"How often individual resources will be checked for drift from the desired state." default:"1m"`
@@ -77,7 +82,7 @@ type startCommand struct {
 
 // Run the RBAC manager.
 func (c *startCommand) Run(s *runtime.Scheme, log logging.Logger) error {
-	log.Debug("Starting", "policy", c.ManagementPolicy)
+	log.Debug("Starting", "policy", c.ManagementPolicy, "dry-run", c.DryRun)
 
 	cfg, err := ctrl.GetConfig()
 	if err != nil {
@@ -104,6 +109,12 @@ func (c *startCommand) Run(s *runtime.Scheme, log logging.Logger) error {
 		},
 		AllowClusterRole: c.ProviderClusterRole,
 		ManagementPolicy: rbaccontroller.ManagementPolicy(c.ManagementPolicy),
+		AggregationLabels: rbaccontroller.AggregationLabels{
+			Admin: c.AggregateToAdmin,
+			Edit:  c.AggregateToEdit,
+			View:  c.AggregateToView,
+		},
+		DryRun: c.DryRun,
 	}
 
 	if err := rbac.Setup(mgr, o); err != nil {
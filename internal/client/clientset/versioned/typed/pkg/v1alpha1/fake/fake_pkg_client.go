@@ -28,6 +28,14 @@ type FakePkgV1alpha1 struct {
 	*testing.Fake
 }
 
+func (c *FakePkgV1alpha1) ClusterHealths() v1alpha1.ClusterHealthInterface {
+	return &FakeClusterHealths{c}
+}
+
+func (c *FakePkgV1alpha1) ControlPlaneExports() v1alpha1.ControlPlaneExportInterface {
+	return &FakeControlPlaneExports{c}
+}
+
 func (c *FakePkgV1alpha1) ControllerConfigs() v1alpha1.ControllerConfigInterface {
 	return &FakeControllerConfigs{c}
 }
@@ -36,6 +44,18 @@ func (c *FakePkgV1alpha1) Locks() v1alpha1.LockInterface {
 	return &FakeLocks{c}
 }
 
+func (c *FakePkgV1alpha1) PackageCatalogs() v1alpha1.PackageCatalogInterface {
+	return &FakePackageCatalogs{c}
+}
+
+func (c *FakePkgV1alpha1) PackagePropagations() v1alpha1.PackagePropagationInterface {
+	return &FakePackagePropagations{c}
+}
+
+func (c *FakePkgV1alpha1) PackageSourcePolicies() v1alpha1.PackageSourcePolicyInterface {
+	return &FakePackageSourcePolicies{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakePkgV1alpha1) RESTClient() rest.Interface {
@@ -18,6 +18,16 @@ limitations under the License.
 
 package v1alpha1
 
+type ClusterHealthExpansion interface{}
+
+type ControlPlaneExportExpansion interface{}
+
 type ControllerConfigExpansion interface{}
 
 type LockExpansion interface{}
+
+type PackageCatalogExpansion interface{}
+
+type PackagePropagationExpansion interface{}
+
+type PackageSourcePolicyExpansion interface{}
@@ -28,8 +28,13 @@ import (
 
 type PkgV1alpha1Interface interface {
 	RESTClient() rest.Interface
+	ClusterHealthsGetter
+	ControlPlaneExportsGetter
 	ControllerConfigsGetter
 	LocksGetter
+	PackageCatalogsGetter
+	PackagePropagationsGetter
+	PackageSourcePoliciesGetter
 }
 
 // PkgV1alpha1Client is used to interact with features provided by the pkg.crossplane.io group.
@@ -37,6 +42,14 @@ type PkgV1alpha1Client struct {
 	restClient rest.Interface
 }
 
+func (c *PkgV1alpha1Client) ClusterHealths() ClusterHealthInterface {
+	return newClusterHealths(c)
+}
+
+func (c *PkgV1alpha1Client) ControlPlaneExports() ControlPlaneExportInterface {
+	return newControlPlaneExports(c)
+}
+
 func (c *PkgV1alpha1Client) ControllerConfigs() ControllerConfigInterface {
 	return newControllerConfigs(c)
 }
@@ -45,6 +58,18 @@ func (c *PkgV1alpha1Client) Locks() LockInterface {
 	return newLocks(c)
 }
 
+func (c *PkgV1alpha1Client) PackageCatalogs() PackageCatalogInterface {
+	return newPackageCatalogs(c)
+}
+
+func (c *PkgV1alpha1Client) PackagePropagations() PackagePropagationInterface {
+	return newPackagePropagations(c)
+}
+
+func (c *PkgV1alpha1Client) PackageSourcePolicies() PackageSourcePolicyInterface {
+	return newPackageSourcePolicies(c)
+}
+
 // NewForConfig creates a new PkgV1alpha1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
@@ -19,16 +19,16 @@ package apiextensions
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
-	"github.com/crossplane/crossplane-runtime/pkg/controller"
-
 	"github.com/crossplane/crossplane/internal/controller/apiextensions/composition"
+	apiextensionscontroller "github.com/crossplane/crossplane/internal/controller/apiextensions/controller"
 	"github.com/crossplane/crossplane/internal/controller/apiextensions/definition"
 	"github.com/crossplane/crossplane/internal/controller/apiextensions/offered"
+	"github.com/crossplane/crossplane/internal/engine"
 	"github.com/crossplane/crossplane/internal/features"
 )
 
 // Setup API extensions controllers.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
+func Setup(mgr ctrl.Manager, o apiextensionscontroller.Options) error {
 
 	// The Composition controller only deals in the management of
 	// CompositionRevisions, so we don't need it at all unless the
@@ -39,6 +39,13 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 		}
 	}
 
+	// The composite resource and claim controllers share an engine, so that
+	// a claim controller and the composite resource controller it depends on
+	// share a cache of the composite resource kind.
+	if o.ControllerEngine == nil {
+		o.ControllerEngine = engine.New(mgr, engine.WithLogger(o.Logger))
+	}
+
 	if err := definition.Setup(mgr, o); err != nil {
 		return err
 	}
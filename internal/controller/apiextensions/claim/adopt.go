@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claim
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// AnnotationKeyAdoptComposite must be set to "true" on a claim to adopt the
+// existing, unclaimed composite resource its spec.resourceRef references. This
+// eases migrating from composite resources that were created directly to
+// claims, while preventing a claim from binding an unclaimed composite resource
+// by accident. The claim refuses to bind, rather than creating a new composite
+// resource, if the referenced composite resource can't be adopted.
+const AnnotationKeyAdoptComposite = "claim.apiextensions.crossplane.io/adopt-composite"
+
+// Error strings.
+const (
+	errAdoptNoRef      = "claim must specify a resourceRef to adopt a composite resource"
+	errAdoptUnclaimed  = "referenced composite resource is unclaimed; annotate the claim with " + AnnotationKeyAdoptComposite + `: "true" to adopt it`
+	errAdoptNotFound   = "referenced composite resource does not exist"
+	errFmtAdoptKind    = "claim may only adopt a composite resource of kind %s, not %s"
+	errFmtAdoptClaimed = "referenced composite resource is already claimed by %s %s/%s"
+)
+
+// adopting returns true if the supplied claim asks to adopt an existing
+// composite resource.
+func adopting(cm resource.CompositeClaim) bool {
+	return cm.GetAnnotations()[AnnotationKeyAdoptComposite] == "true"
+}
+
+// adoptable returns an error if the supplied claim may not bind the supplied
+// composite resource, which was read using the claim's resourceRef. A claim
+// must ask to adopt an existing, unclaimed composite resource. A claim that
+// asks may adopt a composite resource of the kind it claims that exists and is
+// either unclaimed or already bound to the claim.
+func adoptable(cm resource.CompositeClaim, cp resource.Composite) error {
+	if !adopting(cm) {
+		// A composite resource that a claim creates references the claim
+		// before it is created, so one that exists but is unclaimed was
+		// created some other way.
+		if meta.WasCreated(cp) && cp.GetClaimReference() == nil {
+			return errors.New(errAdoptUnclaimed)
+		}
+		return nil
+	}
+
+	ref := cm.GetResourceReference()
+	if ref == nil {
+		return errors.New(errAdoptNoRef)
+	}
+
+	if want, got := cp.GetObjectKind().GroupVersionKind(), ref.GroupVersionKind(); want != got {
+		return errors.Errorf(errFmtAdoptKind, want, got)
+	}
+
+	if !meta.WasCreated(cp) {
+		return errors.New(errAdoptNotFound)
+	}
+
+	existing := cp.GetClaimReference()
+	proposed := meta.ReferenceTo(cm, cm.GetObjectKind().GroupVersionKind())
+	if existing != nil && !cmp.Equal(existing, proposed, cmpopts.IgnoreFields(corev1.ObjectReference{}, "UID")) {
+		return errors.Errorf(errFmtAdoptClaimed, existing.Kind, existing.Namespace, existing.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claim
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestAdoptable(t *testing.T) {
+	xrGVK := schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "XDatabase"}
+	cmGVK := schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Database"}
+
+	cm := func(adopt bool, ref *corev1.ObjectReference) resource.CompositeClaim {
+		cm := claim.New(claim.WithGroupVersionKind(cmGVK))
+		cm.SetNamespace("default")
+		cm.SetName("cool-claim")
+		if adopt {
+			cm.SetAnnotations(map[string]string{AnnotationKeyAdoptComposite: "true"})
+		}
+		if ref != nil {
+			cm.SetResourceReference(ref)
+		}
+		return cm
+	}
+	xr := func(created bool, claimRef *corev1.ObjectReference) resource.Composite {
+		cp := composite.New(composite.WithGroupVersionKind(xrGVK))
+		cp.SetName("cool-xr")
+		if created {
+			cp.SetCreationTimestamp(metav1.Now())
+		}
+		if claimRef != nil {
+			cp.SetClaimReference(claimRef)
+		}
+		return cp
+	}
+	ref := &corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "XDatabase", Name: "cool-xr"}
+
+	cases := map[string]struct {
+		reason string
+		cm     resource.CompositeClaim
+		cp     resource.Composite
+		want   error
+	}{
+		"NotAdopting": {
+			reason: "A claim that doesn't ask to adopt a composite resource may bind one that doesn't exist yet.",
+			cm:     cm(false, nil),
+			cp:     xr(false, nil),
+		},
+		"NotAdoptingBound": {
+			reason: "A claim that doesn't ask to adopt a composite resource may bind one that is already bound to it.",
+			cm:     cm(false, ref),
+			cp:     xr(true, &corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "Database", Namespace: "default", Name: "cool-claim"}),
+		},
+		"NotAdoptingUnclaimed": {
+			reason: "A claim that doesn't ask to adopt a composite resource may not bind an existing, unclaimed one.",
+			cm:     cm(false, ref),
+			cp:     xr(true, nil),
+			want:   errors.New(errAdoptUnclaimed),
+		},
+		"Adoptable": {
+			reason: "A claim may adopt an existing, unclaimed composite resource of the kind it claims.",
+			cm:     cm(true, ref),
+			cp:     xr(true, nil),
+		},
+		"AlreadyBound": {
+			reason: "A claim may continue to 'adopt' a composite resource that is already bound to it.",
+			cm:     cm(true, ref),
+			cp:     xr(true, &corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "Database", Namespace: "default", Name: "cool-claim"}),
+		},
+		"NoRef": {
+			reason: "A claim must reference the composite resource it adopts.",
+			cm:     cm(true, nil),
+			cp:     xr(false, nil),
+			want:   errors.New(errAdoptNoRef),
+		},
+		"WrongKind": {
+			reason: "A claim may only adopt a composite resource of the kind it claims.",
+			cm:     cm(true, &corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "XBucket", Name: "cool-xr"}),
+			cp:     xr(true, nil),
+			want:   errors.Errorf(errFmtAdoptKind, xrGVK, schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "XBucket"}),
+		},
+		"NotFound": {
+			reason: "A claim may not adopt a composite resource that doesn't exist.",
+			cm:     cm(true, ref),
+			cp:     xr(false, nil),
+			want:   errors.New(errAdoptNotFound),
+		},
+		"Claimed": {
+			reason: "A claim may not adopt a composite resource that is claimed by another claim.",
+			cm:     cm(true, ref),
+			cp:     xr(true, &corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "Database", Namespace: "default", Name: "other-claim"}),
+			want:   errors.Errorf(errFmtAdoptClaimed, "Database", "default", "other-claim"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := adoptable(tc.cm, tc.cp)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nadoptable(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -18,20 +18,24 @@ package claim
 
 import (
 	"context"
+	"crypto/sha256"
 	"fmt"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/imdario/mergo"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
 
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	"github.com/crossplane/crossplane/internal/xcrd"
 )
 
@@ -42,9 +46,15 @@ const (
 
 	errName                  = "cannot use dry-run create to name composite resource"
 	errBindCompositeConflict = "cannot bind composite resource that references a different claim"
+	errGetNamedComposite     = "cannot get composite resource with deterministic name"
+
+	errFmtNameConflict         = "composite resource %q already exists, and references a different claim"
+	errFmtExternalNameConflict = "claim requests external name %q, but its composite resource already has external name %q"
 
 	errMergeClaimSpec   = "unable to merge claim spec"
 	errMergeClaimStatus = "unable to merge claim status"
+
+	errFmtPropagateStatusField = "cannot propagate composite resource status field %q to claim"
 )
 
 // An APIDryRunCompositeConfigurator configures composite resources. It may
@@ -52,13 +62,30 @@ const (
 // the configured resource.
 type APIDryRunCompositeConfigurator struct {
 	client client.Client
+	naming v1.ClaimNaming
+}
+
+// A CompositeConfiguratorOption configures an APIDryRunCompositeConfigurator.
+type CompositeConfiguratorOption func(c *APIDryRunCompositeConfigurator)
+
+// WithClaimNaming specifies how an APIDryRunCompositeConfigurator should name
+// composite resources and propagate their external names. Names are generated
+// by the API server by default.
+func WithClaimNaming(n v1.ClaimNaming) CompositeConfiguratorOption {
+	return func(c *APIDryRunCompositeConfigurator) {
+		c.naming = n
+	}
 }
 
 // NewAPIDryRunCompositeConfigurator returns a Configurator of composite
 // resources that may perform a dry-run create against an API server in order to
 // name and validate the configured resource.
-func NewAPIDryRunCompositeConfigurator(c client.Client) *APIDryRunCompositeConfigurator {
-	return &APIDryRunCompositeConfigurator{client: c}
+func NewAPIDryRunCompositeConfigurator(c client.Client, o ...CompositeConfiguratorOption) *APIDryRunCompositeConfigurator {
+	cc := &APIDryRunCompositeConfigurator{client: c}
+	for _, fn := range o {
+		fn(cc)
+	}
+	return cc
 }
 
 // Configure the supplied composite resource by propagating configuration from
@@ -93,6 +120,10 @@ func (c *APIDryRunCompositeConfigurator) Configure(ctx context.Context, cm resou
 	// external name.
 	en := meta.GetExternalName(ucp)
 
+	if ren := meta.GetExternalName(ucm); c.naming.PropagateExternalName && meta.WasCreated(ucp) && en != "" && ren != "" && ren != en {
+		return errors.Errorf(errFmtExternalNameConflict, ren, en)
+	}
+
 	meta.AddAnnotations(ucp, ucm.GetAnnotations())
 	meta.AddLabels(ucp, cm.GetLabels())
 	meta.AddLabels(ucp, map[string]string{
@@ -125,6 +156,10 @@ func (c *APIDryRunCompositeConfigurator) Configure(ctx context.Context, cm resou
 	// earlier so we can return early if it would not be allowed.
 	ucp.SetClaimReference(proposed)
 
+	if !meta.WasCreated(cp) && c.naming.Deterministic {
+		return c.name(ctx, cp, proposed, DeterministicName(cm, c.naming.HashSuffix))
+	}
+
 	if !meta.WasCreated(cp) {
 		// The API server returns an available name derived from
 		// generateName when we perform a dry-run create. This name is
@@ -138,6 +173,39 @@ func (c *APIDryRunCompositeConfigurator) Configure(ctx context.Context, cm resou
 	return nil
 }
 
+// name the supplied composite resource, which has not yet been created. The
+// composite resource may already exist if we named it previously but failed to
+// bind it to the claim, in which case it must reference the proposed claim.
+func (c *APIDryRunCompositeConfigurator) name(ctx context.Context, cp resource.Composite, proposed *corev1.ObjectReference, name string) error {
+	existing := composite.New(composite.WithGroupVersionKind(cp.GetObjectKind().GroupVersionKind()))
+	err := c.client.Get(ctx, types.NamespacedName{Name: name}, existing)
+	if resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errGetNamedComposite)
+	}
+
+	cp.SetName(name)
+	if err == nil {
+		ref := existing.GetClaimReference()
+		if ref == nil || !cmp.Equal(ref, proposed, cmpopts.IgnoreFields(corev1.ObjectReference{}, "UID")) {
+			return errors.Errorf(errFmtNameConflict, name)
+		}
+		return nil
+	}
+
+	return errors.Wrap(c.client.Create(ctx, cp, client.DryRunAll), errName)
+}
+
+// DeterministicName returns the name of the composite resource for the supplied
+// claim. The name is either the claim's namespace and name joined by a hyphen,
+// or the claim's name suffixed with a hash of its namespace and name.
+func DeterministicName(cm resource.CompositeClaim, hashSuffix bool) string {
+	if !hashSuffix {
+		return fmt.Sprintf("%s-%s", cm.GetNamespace(), cm.GetName())
+	}
+	h := sha256.Sum256([]byte(cm.GetNamespace() + "/" + cm.GetName()))
+	return fmt.Sprintf("%s-%x", cm.GetName(), h[:4])
+}
+
 func filter(in map[string]interface{}, keys ...string) map[string]interface{} {
 	filter := map[string]bool{}
 	for _, k := range keys {
@@ -159,12 +227,29 @@ func filter(in map[string]interface{}, keys ...string) map[string]interface{} {
 // from the composite. This includes late-initializing spec values
 // and updating status fields in claim.
 type APIClaimConfigurator struct {
-	client client.Client
+	client       client.Client
+	statusFields []string
+}
+
+// A ClaimConfiguratorOption configures an APIClaimConfigurator.
+type ClaimConfiguratorOption func(c *APIClaimConfigurator)
+
+// WithClaimStatusFields specifies which status fields of a composite resource
+// an APIClaimConfigurator should propagate to its claim. All status fields are
+// propagated by default.
+func WithClaimStatusFields(paths ...string) ClaimConfiguratorOption {
+	return func(c *APIClaimConfigurator) {
+		c.statusFields = paths
+	}
 }
 
 // NewAPIClaimConfigurator returns a APIClaimConfigurator.
-func NewAPIClaimConfigurator(client client.Client) *APIClaimConfigurator {
-	return &APIClaimConfigurator{client: client}
+func NewAPIClaimConfigurator(client client.Client, o ...ClaimConfiguratorOption) *APIClaimConfigurator {
+	c := &APIClaimConfigurator{client: client}
+	for _, fn := range o {
+		fn(c)
+	}
+	return c
 }
 
 // Configure the supplied claims with fields from the composite.
@@ -179,11 +264,8 @@ func (c *APIClaimConfigurator) Configure(ctx context.Context, cm resource.Compos
 		return nil
 	}
 
-	if err := merge(ucm.Object["status"], ucp.Object["status"],
-		// Status fields from composite overwrite non-empty fields in claim
-		withMergeOptions(mergo.WithOverride),
-		withSrcFilter(xcrd.GetPropFields(xcrd.CompositeResourceStatusProps())...)); err != nil {
-		return errors.Wrap(err, errMergeClaimStatus)
+	if err := c.propagateStatus(ucm, ucp); err != nil {
+		return err
 	}
 
 	if err := c.client.Status().Update(ctx, cm); err != nil {
@@ -219,6 +301,36 @@ func (c *APIClaimConfigurator) Configure(ctx context.Context, cm resource.Compos
 	return errors.Wrap(c.client.Update(ctx, cm), errUpdateClaim)
 }
 
+// propagateStatus propagates the status of the supplied composite resource to
+// the supplied claim. Only the configured status fields are propagated, if any
+// are configured.
+func (c *APIClaimConfigurator) propagateStatus(cm *claim.Unstructured, cp *composite.Unstructured) error {
+	if len(c.statusFields) == 0 {
+		return errors.Wrap(merge(cm.Object["status"], cp.Object["status"],
+			// Status fields from composite overwrite non-empty fields in claim
+			withMergeOptions(mergo.WithOverride),
+			withSrcFilter(xcrd.GetPropFields(xcrd.CompositeResourceStatusProps())...)), errMergeClaimStatus)
+	}
+
+	from := fieldpath.Pave(cp.UnstructuredContent())
+	to := fieldpath.Pave(cm.UnstructuredContent())
+	for _, p := range c.statusFields {
+		// A field the composite resource hasn't reported yet is left as-is
+		// on the claim.
+		v, err := from.GetValue(p)
+		if fieldpath.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, errFmtPropagateStatusField, p)
+		}
+		if err := to.SetValue(p, v); err != nil {
+			return errors.Wrapf(err, errFmtPropagateStatusField, p)
+		}
+	}
+	return nil
+}
+
 type mergeConfig struct {
 	mergeOptions []func(*mergo.Config)
 	srcfilter    []string
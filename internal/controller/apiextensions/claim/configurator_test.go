@@ -22,8 +22,11 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -33,6 +36,8 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	"github.com/crossplane/crossplane/internal/xcrd"
 )
 
@@ -55,9 +60,56 @@ func TestCompositeConfigure(t *testing.T) {
 		err error
 	}
 
+	// newClaim returns a claim with the supplied annotations.
+	newClaim := func(annotations map[string]string) *claim.Unstructured {
+		cm := &claim.Unstructured{
+			Unstructured: unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": apiVersion,
+					"kind":       kind,
+					"metadata": map[string]interface{}{
+						"namespace": ns,
+						"name":      name,
+					},
+					"spec": map[string]interface{}{
+						"coolness": 23,
+					},
+				},
+			},
+		}
+		cm.SetAnnotations(annotations)
+		return cm
+	}
+
+	// configured returns the composite resource we expect to be configured
+	// for a claim returned by newClaim, with the supplied metadata.
+	configured := func(md map[string]interface{}) *composite.Unstructured {
+		md["labels"] = map[string]interface{}{
+			xcrd.LabelKeyClaimNamespace: ns,
+			xcrd.LabelKeyClaimName:      name,
+		}
+		return &composite.Unstructured{
+			Unstructured: unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": md,
+					"spec": map[string]interface{}{
+						"coolness": 23,
+						"claimRef": map[string]interface{}{
+							"apiVersion": apiVersion,
+							"kind":       kind,
+							"namespace":  ns,
+							"name":       name,
+						},
+					},
+				},
+			},
+		}
+	}
+
 	cases := map[string]struct {
 		reason string
 		c      client.Client
+		naming v1.ClaimNaming
 		args   args
 		want   want
 	}{
@@ -280,6 +332,107 @@ func TestCompositeConfigure(t *testing.T) {
 				},
 			},
 		},
+		"ErrGetDeterministicallyNamedXR": {
+			reason: "We should return any error we encounter getting a composite resource with a deterministic name.",
+			c: &test.MockClient{
+				MockGet: test.NewMockGetFn(errBoom),
+			},
+			naming: v1.ClaimNaming{Deterministic: true},
+			args: args{
+				cm: newClaim(nil),
+				cp: &composite.Unstructured{},
+			},
+			want: want{
+				cp:  configured(map[string]interface{}{}),
+				err: errors.Wrap(errBoom, errGetNamedComposite),
+			},
+		},
+		"DeterministicNameConflict": {
+			reason: "We should return an error if a composite resource with our deterministic name references a different claim.",
+			c: &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					obj.(*composite.Unstructured).SetClaimReference(&corev1.ObjectReference{Namespace: "other", Name: name})
+					return nil
+				}),
+			},
+			naming: v1.ClaimNaming{Deterministic: true},
+			args: args{
+				cm: newClaim(nil),
+				cp: &composite.Unstructured{},
+			},
+			want: want{
+				cp:  configured(map[string]interface{}{"name": ns + "-" + name}),
+				err: errors.Errorf(errFmtNameConflict, ns+"-"+name),
+			},
+		},
+		"ConfiguredNewXRDeterministicName": {
+			reason: "A dynamically provisioned composite resource should be named after its claim's namespace and name.",
+			c: &test.MockClient{
+				MockGet:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, ns+"-"+name)),
+				MockCreate: test.NewMockCreateFn(nil),
+			},
+			naming: v1.ClaimNaming{Deterministic: true},
+			args: args{
+				cm: newClaim(nil),
+				cp: &composite.Unstructured{},
+			},
+			want: want{
+				cp: configured(map[string]interface{}{"name": ns + "-" + name}),
+			},
+		},
+		"ConfiguredXRDeterministicNameAlreadyExists": {
+			reason: "A composite resource with our deterministic name that references our claim should be used.",
+			c: &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					obj.(*composite.Unstructured).SetClaimReference(&corev1.ObjectReference{APIVersion: apiVersion, Kind: kind, Namespace: ns, Name: name})
+					return nil
+				}),
+			},
+			naming: v1.ClaimNaming{Deterministic: true, HashSuffix: true},
+			args: args{
+				cm: newClaim(nil),
+				cp: &composite.Unstructured{},
+			},
+			want: want{
+				cp: configured(map[string]interface{}{"name": DeterministicName(newClaim(nil), true)}),
+			},
+		},
+		"ExternalNameConflict": {
+			reason: "We should return an error if a claim requests a different external name from the one its composite resource already has.",
+			naming: v1.ClaimNaming{PropagateExternalName: true},
+			args: args{
+				cm: newClaim(map[string]string{meta.AnnotationKeyExternalName: "wat"}),
+				cp: &composite.Unstructured{
+					Unstructured: unstructured.Unstructured{
+						Object: map[string]interface{}{
+							"metadata": map[string]interface{}{
+								"name":              name,
+								"creationTimestamp": "2022-01-01T00:00:00Z",
+								"annotations": map[string]interface{}{
+									meta.AnnotationKeyExternalName: name,
+								},
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				cp: &composite.Unstructured{
+					Unstructured: unstructured.Unstructured{
+						Object: map[string]interface{}{
+							"metadata": map[string]interface{}{
+								"name":              name,
+								"creationTimestamp": "2022-01-01T00:00:00Z",
+								"annotations": map[string]interface{}{
+									meta.AnnotationKeyExternalName: name,
+								},
+							},
+						},
+					},
+				},
+				err: errors.Errorf(errFmtExternalNameConflict, "wat", name),
+			},
+		},
 		"ConfiguredExistingXR": {
 			reason: "A statically provisioned composite resource should be configured according to the claim",
 			args: args{
@@ -375,7 +528,7 @@ func TestCompositeConfigure(t *testing.T) {
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			c := NewAPIDryRunCompositeConfigurator(tc.c)
+			c := NewAPIDryRunCompositeConfigurator(tc.c, WithClaimNaming(tc.naming))
 			got := c.Configure(tc.args.ctx, tc.args.cm, tc.args.cp)
 			if diff := cmp.Diff(tc.want.err, got, test.EquateErrors()); diff != "" {
 				t.Errorf("Configure(...): %s\n-want error, +got error:\n%s\n", tc.reason, diff)
@@ -388,6 +541,48 @@ func TestCompositeConfigure(t *testing.T) {
 
 }
 
+func TestDeterministicName(t *testing.T) {
+	cm := &claim.Unstructured{}
+	cm.SetNamespace("spacename")
+	cm.SetName("cool")
+
+	other := &claim.Unstructured{}
+	other.SetNamespace("othername")
+	other.SetName("cool")
+
+	cases := map[string]struct {
+		reason     string
+		cm         resource.CompositeClaim
+		hashSuffix bool
+		want       string
+	}{
+		"NamespaceAndName": {
+			reason: "Without a hash suffix the name should be the claim's namespace and name.",
+			cm:     cm,
+			want:   "spacename-cool",
+		},
+		"HashSuffix": {
+			reason:     "With a hash suffix the name should be the claim's name and a hash of its namespace and name.",
+			cm:         cm,
+			hashSuffix: true,
+			want:       "cool-39c40b50",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := DeterministicName(tc.cm, tc.hashSuffix)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDeterministicName(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+
+	if DeterministicName(cm, true) == DeterministicName(other, true) {
+		t.Errorf("DeterministicName(...): claims with the same name in different namespaces should not share a name")
+	}
+}
+
 func TestClaimConfigure(t *testing.T) {
 	errBoom := errors.New("boom")
 	ns := "spacename"
@@ -397,6 +592,7 @@ func TestClaimConfigure(t *testing.T) {
 		cm     resource.CompositeClaim
 		cp     resource.Composite
 		client client.Client
+		opts   []ClaimConfiguratorOption
 	}
 
 	type want struct {
@@ -726,11 +922,58 @@ func TestClaimConfigure(t *testing.T) {
 				},
 			},
 		},
+		"ConfigureStatusFields": {
+			reason: "Only the configured status fields of the composite should be propagated to the claim",
+			args: args{
+				client: test.NewMockClient(),
+				opts:   []ClaimConfiguratorOption{WithClaimStatusFields("status.address", "status.endpoint.port", "status.notYetReported")},
+				cm: &claim.Unstructured{
+					Unstructured: unstructured.Unstructured{
+						Object: map[string]interface{}{
+							"spec": map[string]interface{}{},
+							"status": map[string]interface{}{
+								"address": "old.example.org",
+							},
+						},
+					},
+				},
+				cp: &composite.Unstructured{
+					Unstructured: unstructured.Unstructured{
+						Object: map[string]interface{}{
+							"spec": map[string]interface{}{},
+							"status": map[string]interface{}{
+								"address": "new.example.org",
+								"endpoint": map[string]interface{}{
+									"port":     "5432",
+									"protocol": "TCP",
+								},
+								"internalID": "secret-sauce",
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				cm: &claim.Unstructured{
+					Unstructured: unstructured.Unstructured{
+						Object: map[string]interface{}{
+							"spec": map[string]interface{}{},
+							"status": map[string]interface{}{
+								"address": "new.example.org",
+								"endpoint": map[string]interface{}{
+									"port": "5432",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			c := NewAPIClaimConfigurator(tc.args.client)
+			c := NewAPIClaimConfigurator(tc.args.client, tc.args.opts...)
 			got := c.Configure(context.Background(), tc.args.cm, tc.args.cp)
 			if diff := cmp.Diff(tc.want.err, got, test.EquateErrors()); diff != "" {
 				t.Errorf("c.Configure(...): %s\n-want error, +got error:\n%s\n", tc.reason, diff)
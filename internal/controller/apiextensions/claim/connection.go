@@ -2,13 +2,23 @@ package claim
 
 import (
 	"context"
+	"path"
+	"strings"
+	"text/template"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 )
 
+const (
+	errParseSecretName   = "cannot parse connection secret name template"
+	errRenderSecretName  = "cannot render connection secret name template"
+	errSecretNameInScope = "connection secret name must be a relative path within the claim's namespace"
+)
+
 // NopConnectionUnpublisher is a ConnectionUnpublisher that does nothing.
 type NopConnectionUnpublisher struct{}
 
@@ -46,7 +56,101 @@ func NewSecretStoreConnectionUnpublisher(p managed.ConnectionPublisher) *SecretS
 
 // UnpublishConnection details for the supplied Managed resource.
 func (u *SecretStoreConnectionUnpublisher) UnpublishConnection(ctx context.Context, so resource.LocalConnectionSecretOwner, c managed.ConnectionDetails) error {
-	return u.publisher.UnpublishConnection(ctx, newClaimAsSecretOwner(so), c)
+	ts, err := newTenantScopedClaim(so)
+	if err != nil {
+		return err
+	}
+	return u.publisher.UnpublishConnection(ctx, newClaimAsSecretOwner(ts), c)
+}
+
+// SecretStoreConnectionPropagator propagates connection details from a
+// composite resource to the secret store configured by a claim. The name of
+// the claim's connection secret may be a Go template, which is rendered before
+// the connection details are propagated. The rendered name must be a relative
+// path, ensuring a claim can only write to its own namespace's scope of the
+// secret store.
+type SecretStoreConnectionPropagator struct {
+	propagator ConnectionPropagator
+}
+
+// NewSecretStoreConnectionPropagator returns a new
+// SecretStoreConnectionPropagator.
+func NewSecretStoreConnectionPropagator(p ConnectionPropagator) *SecretStoreConnectionPropagator {
+	return &SecretStoreConnectionPropagator{propagator: p}
+}
+
+// PropagateConnection details from the supplied composite resource to the
+// supplied claim's secret store.
+func (p *SecretStoreConnectionPropagator) PropagateConnection(ctx context.Context, to resource.LocalConnectionSecretOwner, from resource.ConnectionSecretOwner) (bool, error) {
+	// The claim does not want its connection details published to a
+	// secret store.
+	if to.GetPublishConnectionDetailsTo() == nil {
+		return false, nil
+	}
+	ts, err := newTenantScopedClaim(to)
+	if err != nil {
+		return false, err
+	}
+	return p.propagator.PropagateConnection(ctx, ts, from)
+}
+
+// SecretNameTemplateValues are the values that may be used when templating
+// the name of a claim's connection secret.
+type SecretNameTemplateValues struct {
+	// Name of the claim.
+	Name string
+
+	// Namespace of the claim.
+	Namespace string
+
+	// UID of the claim.
+	UID string
+}
+
+// RenderSecretName renders the supplied connection secret name template
+// using the supplied claim. It returns an error if the rendered name would
+// escape the scope of the claim's namespace.
+func RenderSecretName(tmpl string, cm resource.Object) (string, error) {
+	t, err := template.New("name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", errors.Wrap(err, errParseSecretName)
+	}
+	b := &strings.Builder{}
+	v := SecretNameTemplateValues{Name: cm.GetName(), Namespace: cm.GetNamespace(), UID: string(cm.GetUID())}
+	if err := t.Execute(b, v); err != nil {
+		return "", errors.Wrap(err, errRenderSecretName)
+	}
+
+	name := path.Clean(b.String())
+	if path.IsAbs(name) || name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return "", errors.New(errSecretNameInScope)
+	}
+	return name, nil
+}
+
+// tenantScopedClaim is a claim whose connection secret name has been
+// rendered, and is known to be within the scope of the claim's namespace.
+type tenantScopedClaim struct {
+	resource.LocalConnectionSecretOwner
+	to *xpv1.PublishConnectionDetailsTo
+}
+
+func newTenantScopedClaim(lo resource.LocalConnectionSecretOwner) (*tenantScopedClaim, error) {
+	p := lo.GetPublishConnectionDetailsTo()
+	if p == nil {
+		return &tenantScopedClaim{LocalConnectionSecretOwner: lo}, nil
+	}
+	name, err := RenderSecretName(p.Name, lo)
+	if err != nil {
+		return nil, err
+	}
+	to := p.DeepCopy()
+	to.Name = name
+	return &tenantScopedClaim{LocalConnectionSecretOwner: lo, to: to}, nil
+}
+
+func (c *tenantScopedClaim) GetPublishConnectionDetailsTo() *xpv1.PublishConnectionDetailsTo {
+	return c.to
 }
 
 // soClaim is a type that enables using claim type with Secret Store
@@ -0,0 +1,188 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+var (
+	_ ConnectionPropagator  = &SecretStoreConnectionPropagator{}
+	_ ConnectionUnpublisher = &SecretStoreConnectionUnpublisher{}
+)
+
+func TestRenderSecretName(t *testing.T) {
+	cm := &fake.CompositeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "db", UID: "some-uid"}}
+
+	type want struct {
+		name string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		tmpl   string
+		want   want
+	}{
+		"Plain": {
+			reason: "A name without template actions should be returned as is",
+			tmpl:   "db-conn",
+			want:   want{name: "db-conn"},
+		},
+		"Templated": {
+			reason: "Template actions should be rendered using the claim",
+			tmpl:   "apps/{{ .Name }}-{{ .UID }}",
+			want:   want{name: "apps/db-some-uid"},
+		},
+		"ParseError": {
+			reason: "An invalid template should return an error",
+			tmpl:   "{{ .Name ",
+			want: want{err: errors.Wrap(func() error {
+				_, err := RenderSecretName("{{ .Name ", cm)
+				return errors.Cause(err)
+			}(), errParseSecretName)},
+		},
+		"UnknownValue": {
+			reason: "Referencing an unknown value should return an error",
+			tmpl:   "{{ .Cluster }}",
+			want: want{err: errors.Wrap(func() error {
+				_, err := RenderSecretName("{{ .Cluster }}", cm)
+				return errors.Cause(err)
+			}(), errRenderSecretName)},
+		},
+		"Absolute": {
+			reason: "An absolute path should be rejected",
+			tmpl:   "/team-b/db",
+			want:   want{err: errors.New(errSecretNameInScope)},
+		},
+		"Traversal": {
+			reason: "A path that escapes the claim's namespace should be rejected",
+			tmpl:   "apps/../../team-b/db",
+			want:   want{err: errors.New(errSecretNameInScope)},
+		},
+		"Empty": {
+			reason: "A path that refers to the claim's namespace itself should be rejected",
+			tmpl:   "apps/..",
+			want:   want{err: errors.New(errSecretNameInScope)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := RenderSecretName(tc.tmpl, cm)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRenderSecretName(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.name, got); diff != "" {
+				t.Errorf("\n%s\nRenderSecretName(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStorePropagateConnection(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type args struct {
+		p  ConnectionPropagator
+		to resource.LocalConnectionSecretOwner
+	}
+	type want struct {
+		propagated bool
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ClaimDoesNotWantConnectionSecret": {
+			reason: "Nothing should be propagated if the claim does not want to publish to a secret store",
+			args: args{
+				to: &fake.CompositeClaim{},
+			},
+			want: want{propagated: false},
+		},
+		"NameNotInScope": {
+			reason: "An error should be returned if the claim's secret name escapes its namespace",
+			args: args{
+				to: &fake.CompositeClaim{
+					ConnectionDetailsPublisherTo: fake.ConnectionDetailsPublisherTo{
+						To: &xpv1.PublishConnectionDetailsTo{Name: "../team-b/db"},
+					},
+				},
+			},
+			want: want{err: errors.New(errSecretNameInScope)},
+		},
+		"PropagateError": {
+			reason: "Errors propagating connection details should be returned",
+			args: args{
+				p: ConnectionPropagatorFn(func(_ context.Context, _ resource.LocalConnectionSecretOwner, _ resource.ConnectionSecretOwner) (bool, error) {
+					return false, errBoom
+				}),
+				to: &fake.CompositeClaim{
+					ConnectionDetailsPublisherTo: fake.ConnectionDetailsPublisherTo{
+						To: &xpv1.PublishConnectionDetailsTo{Name: "db"},
+					},
+				},
+			},
+			want: want{err: errBoom},
+		},
+		"Success": {
+			reason: "Connection details should be propagated using the rendered secret name",
+			args: args{
+				p: ConnectionPropagatorFn(func(_ context.Context, to resource.LocalConnectionSecretOwner, _ resource.ConnectionSecretOwner) (bool, error) {
+					if diff := cmp.Diff("apps/db", to.GetPublishConnectionDetailsTo().Name); diff != "" {
+						t.Errorf("PropagateConnection(...): -want name, +got name:\n%s", diff)
+					}
+					return true, nil
+				}),
+				to: &fake.CompositeClaim{
+					ObjectMeta: metav1.ObjectMeta{Name: "db"},
+					ConnectionDetailsPublisherTo: fake.ConnectionDetailsPublisherTo{
+						To: &xpv1.PublishConnectionDetailsTo{Name: "apps/{{ .Name }}"},
+					},
+				},
+			},
+			want: want{propagated: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := NewSecretStoreConnectionPropagator(tc.args.p)
+			got, err := p.PropagateConnection(context.Background(), tc.args.to, &fake.Composite{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPropagateConnection(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.propagated, got); diff != "" {
+				t.Errorf("\n%s\nPropagateConnection(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package claim
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -32,6 +33,7 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
@@ -39,6 +41,9 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+
+	"github.com/crossplane/crossplane/internal/loglevel"
+	"github.com/crossplane/crossplane/internal/metrics"
 )
 
 const (
@@ -48,7 +53,8 @@ const (
 
 // Reasons a composite resource claim is or is not ready.
 const (
-	ReasonWaiting = "Composite resource claim is waiting for composite resource to become Ready"
+	ReasonWaiting     = "Composite resource claim is waiting for composite resource to become Ready"
+	ReasonUnavailable = "Composite resource claim was Ready, but its composite resource is no longer Ready"
 )
 
 // Error strings.
@@ -59,15 +65,24 @@ const (
 	errDeleteCDs          = "cannot delete connection details"
 	errRemoveFinalizer    = "cannot remove composite resource claim finalizer"
 	errAddFinalizer       = "cannot add composite resource claim finalizer"
+	errAdoptComposite     = "cannot adopt referenced composite resource"
 	errConfigureComposite = "cannot configure composite resource"
 	errBindComposite      = "cannot bind composite resource"
 	errApplyComposite     = "cannot apply composite resource"
 	errConfigureClaim     = "cannot configure composite resource claim"
 	errPropagateCDs       = "cannot propagate connection details from composite"
 
-	errUpdateClaimStatus = "cannot update composite resource claim status"
+	errUpdateClaimStatus  = "cannot update composite resource claim status"
+	errRemainingResources = "cannot determine which composed resources remain"
+
+	msgFmtWaitingForComposite = "Waiting for composite resource %q to be deleted; see status.remainingResources"
 )
 
+// fieldPathRemainingResources is the field path at which the composed
+// resources that still exist while a composite resource is being deleted are
+// recorded, on both the composite resource and its claim.
+const fieldPathRemainingResources = "status.remainingResources"
+
 // Event reasons.
 const (
 	reasonBind               event.Reason = "BindCompositeResource"
@@ -180,8 +195,9 @@ type Reconciler struct {
 	composite crComposite
 	claim     crClaim
 
-	log    logging.Logger
-	record event.Recorder
+	log     logging.Logger
+	record  event.Recorder
+	metrics metrics.Recorder
 }
 
 type crComposite struct {
@@ -285,6 +301,13 @@ func WithRecorder(er event.Recorder) ReconcilerOption {
 	}
 }
 
+// WithMetricsRecorder specifies how the Reconciler should record metrics.
+func WithMetricsRecorder(m metrics.Recorder) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.metrics = m
+	}
+}
+
 // NewReconciler returns a Reconciler that reconciles composite resource claims of
 // the supplied CompositeClaimKind with resources of the supplied CompositeKind.
 // The returned Reconciler will apply only the ObjectMetaConfigurator by
@@ -307,6 +330,7 @@ func NewReconciler(m manager.Manager, of resource.CompositeClaimKind, with resou
 		claim:     defaultCRClaim(c),
 		log:       logging.NewNopLogger(),
 		record:    event.NewNopRecorder(),
+		metrics:   metrics.NewNopRecorder(),
 	}
 
 	for _, ro := range o {
@@ -335,6 +359,8 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetClaim)
 	}
 
+	log = loglevel.ForObject(log, cm)
+
 	record := r.record.WithAnnotations("external-name", meta.GetExternalName(cm))
 	log = log.WithValues(
 		"uid", cm.GetUID(),
@@ -374,13 +400,27 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 				return reconcile.Result{Requeue: false}, nil
 			}
 
-			if err := r.client.Delete(ctx, cp); resource.IgnoreNotFound(err) != nil {
+			if err := r.client.Delete(ctx, cp, deleteOptions(cm)...); resource.IgnoreNotFound(err) != nil {
 				log.Debug(errDeleteComposite, "error", err)
 				err = errors.Wrap(err, errDeleteComposite)
 				record.Event(cm, event.Warning(reasonDelete, err))
 				return reconcile.Result{}, err
 			}
 
+			// A claim that was deleted in the foreground waits for its
+			// composite resource to be deleted, and reports which of its
+			// composed resources remain to help diagnose stuck deletions.
+			// We're requeued when the composite resource changes, or is
+			// deleted.
+			if deletingInForeground(cm) {
+				if err := copyRemainingResources(cm, cp); err != nil {
+					log.Debug(errRemainingResources, "error", err)
+					return reconcile.Result{}, errors.Wrap(err, errRemainingResources)
+				}
+				cm.SetConditions(xpv1.Deleting().WithMessage(fmt.Sprintf(msgFmtWaitingForComposite, cp.GetName())))
+				log.Debug("Waiting for composite resource to be deleted")
+				return reconcile.Result{Requeue: false}, errors.Wrap(resource.IgnoreNotFound(r.client.Status().Update(ctx, cm)), errUpdateClaimStatus)
+			}
 		}
 
 		// Claims do not publish connection details but may propagate XR
@@ -407,6 +447,15 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{Requeue: false}, nil
 	}
 
+	if err := adoptable(cm, cp); err != nil {
+		// We don't requeue (or return an error, which would requeue)
+		// because the claim will need human intervention before we can
+		// proceed, and we'll be queued implicitly when it is edited.
+		log.Debug(errAdoptComposite, "error", err)
+		record.Event(cm, event.Warning(reasonBind, errors.Wrap(err, errAdoptComposite)))
+		return reconcile.Result{Requeue: false}, nil
+	}
+
 	if err := r.claim.AddFinalizer(ctx, cm); err != nil {
 		log.Debug(errAddFinalizer, "error")
 		err = errors.Wrap(err, errAddFinalizer)
@@ -463,7 +512,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 
 		// We should be watching the composite resource and will have a
 		// request queued if it changes, so no need to requeue.
-		cm.SetConditions(Waiting())
+		c := Waiting()
+		if wasReady(cm) {
+			c = Unavailable()
+		}
+		cm.SetConditions(c)
 		return reconcile.Result{}, errors.Wrap(r.client.Status().Update(ctx, cm), errUpdateClaimStatus)
 	}
 
@@ -483,6 +536,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		record.Event(cm, event.Normal(reasonPropagate, "Successfully propagated connection details from composite resource"))
 	}
 
+	// We only record how long a claim took to become ready the first time
+	// it does.
+	if !wasReady(cm) {
+		r.metrics.RecordClaimReady(time.Since(cm.GetCreationTimestamp().Time))
+	}
+
 	// We have a watch on both the claim and its composite, so there's no
 	// need to requeue here.
 	cm.SetConditions(xpv1.Available())
@@ -499,3 +558,59 @@ func Waiting() xpv1.Condition {
 		Reason:             ReasonWaiting,
 	}
 }
+
+// Unavailable returns a condition that indicates the composite resource claim
+// was ready, but its composite resource is no longer ready.
+func Unavailable() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonUnavailable,
+	}
+}
+
+// wasReady returns true if the supplied claim is ready, or has been ready
+// before.
+func wasReady(cm resource.CompositeClaim) bool {
+	c := cm.GetCondition(xpv1.TypeReady)
+	return c.Status == corev1.ConditionTrue || c.Reason == ReasonUnavailable
+}
+
+// deletingInForeground returns true if the supplied claim was deleted with
+// foreground cascading deletion.
+func deletingInForeground(cm metav1.Object) bool {
+	return meta.FinalizerExists(cm, metav1.FinalizerDeleteDependents)
+}
+
+// deleteOptions returns the options with which the composite resource of the
+// supplied claim should be deleted. A claim that was deleted in the foreground
+// deletes its composite resource in the foreground too, so that the composite
+// resource lingers until its composed resources are gone.
+func deleteOptions(cm metav1.Object) []client.DeleteOption {
+	if !deletingInForeground(cm) {
+		return nil
+	}
+	return []client.DeleteOption{client.PropagationPolicy(metav1.DeletePropagationForeground)}
+}
+
+// copyRemainingResources copies the composed resources that remain while the
+// supplied composite resource is being deleted to the supplied claim.
+func copyRemainingResources(cm resource.CompositeClaim, cp resource.Composite) error {
+	ucm, ok := cm.(*claim.Unstructured)
+	if !ok {
+		return nil
+	}
+	ucp, ok := cp.(*composite.Unstructured)
+	if !ok {
+		return nil
+	}
+	rr, err := fieldpath.Pave(ucp.Object).GetValue(fieldPathRemainingResources)
+	if fieldpath.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fieldpath.Pave(ucm.Object).SetValue(fieldPathRemainingResources, rr)
+}
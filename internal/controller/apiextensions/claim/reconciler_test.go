@@ -18,7 +18,9 @@ package claim
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
@@ -36,6 +38,8 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/internal/metrics"
 )
 
 func TestReconcile(t *testing.T) {
@@ -242,6 +246,88 @@ func TestReconcile(t *testing.T) {
 				r: reconcile.Result{Requeue: false},
 			},
 		},
+		"WaitForForegroundDeletion": {
+			reason: "A claim deleted in the foreground should delete its composite resource in the foreground, and report its remaining composed resources until it is gone.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+								switch o := obj.(type) {
+								case *claim.Unstructured:
+									now := metav1.Now()
+									o.SetName(name)
+									o.SetDeletionTimestamp(&now)
+									o.SetFinalizers([]string{finalizer, metav1.FinalizerDeleteDependents})
+									o.SetResourceReference(&corev1.ObjectReference{})
+								case *composite.Unstructured:
+									o.SetName("cool-composite")
+									o.SetCreationTimestamp(metav1.Now())
+									o.SetClaimReference(&corev1.ObjectReference{Name: name})
+									o.Object["status"] = map[string]interface{}{
+										"remainingResources": []interface{}{map[string]interface{}{"name": "cool-composed"}},
+									}
+								}
+								return nil
+							}),
+							MockDelete: func(_ context.Context, _ client.Object, opts ...client.DeleteOption) error {
+								do := &client.DeleteOptions{}
+								do.ApplyOptions(opts)
+								if diff := cmp.Diff(metav1.DeletePropagationForeground, *do.PropagationPolicy); diff != "" {
+									t.Errorf("Delete(...): -want propagation policy, +got propagation policy:\n%s", diff)
+								}
+								return nil
+							},
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(obj client.Object) error {
+								got := obj.(*claim.Unstructured)
+								want := []interface{}{map[string]interface{}{"name": "cool-composed"}}
+								if diff := cmp.Diff(want, got.Object["status"].(map[string]interface{})["remainingResources"]); diff != "" {
+									t.Errorf("Status().Update(...): -want remaining resources, +got remaining resources:\n%s", diff)
+								}
+								c := xpv1.Deleting().WithMessage(fmt.Sprintf(msgFmtWaitingForComposite, "cool-composite"))
+								if diff := cmp.Diff(c, got.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+									t.Errorf("Status().Update(...): -want condition, +got condition:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+					}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
+		"AdoptNotFound": {
+			reason: "We should not requeue or configure a composite resource if a claim asks to adopt one that does not exist.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+								switch o := obj.(type) {
+								case *claim.Unstructured:
+									o.SetAnnotations(map[string]string{AnnotationKeyAdoptComposite: "true"})
+									o.SetResourceReference(&corev1.ObjectReference{})
+									return nil
+								case *composite.Unstructured:
+									return kerrors.NewNotFound(schema.GroupResource{}, "")
+								}
+								return nil
+							}),
+						},
+					}),
+					WithClaimFinalizer(resource.FinalizerFns{
+						AddFinalizerFn: func(ctx context.Context, obj resource.Object) error { return errBoom },
+					}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
 		"AddFinalizerError": {
 			reason: "We should return any error we encounter while adding the claim's finalizer",
 			args: args{
@@ -409,6 +495,44 @@ func TestReconcile(t *testing.T) {
 				r: reconcile.Result{Requeue: false},
 			},
 		},
+		"CompositeNoLongerReady": {
+			reason: "We should mark a claim that was ready unavailable if the bound composite resource is no longer ready",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+								if o, ok := obj.(*claim.Unstructured); ok {
+									o.SetResourceReference(&corev1.ObjectReference{})
+									o.SetConditions(xpv1.Available())
+								}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(obj client.Object) error {
+								got := obj.(*claim.Unstructured).GetCondition(xpv1.TypeReady)
+								if diff := cmp.Diff(Unavailable(), got, test.EquateConditions()); diff != "" {
+									t.Errorf("Status().Update(...): -want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+						Applicator: resource.ApplyFn(func(c context.Context, r client.Object, ao ...resource.ApplyOption) error {
+							return nil
+						}),
+					}),
+					WithClaimFinalizer(resource.FinalizerFns{
+						AddFinalizerFn: func(ctx context.Context, obj resource.Object) error { return nil },
+					}),
+					WithCompositeConfigurator(ConfiguratorFn(func(ctx context.Context, cm resource.CompositeClaim, cp resource.Composite) error { return nil })),
+					WithBinder(BinderFn(func(ctx context.Context, cm resource.CompositeClaim, cp resource.Composite) error { return nil })),
+					WithClaimConfigurator(ConfiguratorFn(func(ctx context.Context, cm resource.CompositeClaim, cp resource.Composite) error { return nil })),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
 		"PropagateConnectionError": {
 			reason: "We should return any error we encounter while propagating the bound composite's connection details",
 			args: args{
@@ -499,3 +623,79 @@ func TestReconcile(t *testing.T) {
 		})
 	}
 }
+
+type claimReadyRecorder struct {
+	metrics.NopRecorder
+	recorded *bool
+}
+
+func (r claimReadyRecorder) RecordClaimReady(_ time.Duration) {
+	*r.recorded = true
+}
+
+func TestReconcileRecordClaimReady(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		ready  xpv1.Condition
+		want   bool
+	}{
+		"FirstReady": {
+			reason: "We should record how long a claim took to become ready the first time it does.",
+			ready:  Waiting(),
+			want:   true,
+		},
+		"AlreadyReady": {
+			reason: "We should not record how long a claim took to become ready if it already was.",
+			ready:  xpv1.Available(),
+			want:   false,
+		},
+		"ReadyAgain": {
+			reason: "We should not record how long a claim took to become ready if it was ready before.",
+			ready:  Unavailable(),
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			recorded := false
+			mgr := &fake.Manager{}
+			r := NewReconciler(mgr, resource.CompositeClaimKind{}, resource.CompositeKind{},
+				WithClientApplicator(resource.ClientApplicator{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							switch o := obj.(type) {
+							case *claim.Unstructured:
+								o.SetResourceReference(&corev1.ObjectReference{})
+								o.SetConditions(tc.ready)
+							case *composite.Unstructured:
+								o.SetConditions(xpv1.Available())
+							}
+							return nil
+						}),
+						MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+					},
+					Applicator: resource.ApplyFn(func(c context.Context, r client.Object, ao ...resource.ApplyOption) error {
+						return nil
+					}),
+				}),
+				WithClaimFinalizer(resource.FinalizerFns{
+					AddFinalizerFn: func(ctx context.Context, obj resource.Object) error { return nil },
+				}),
+				WithCompositeConfigurator(ConfiguratorFn(func(ctx context.Context, cm resource.CompositeClaim, cp resource.Composite) error { return nil })),
+				WithBinder(BinderFn(func(ctx context.Context, cm resource.CompositeClaim, cp resource.Composite) error { return nil })),
+				WithClaimConfigurator(ConfiguratorFn(func(ctx context.Context, cm resource.CompositeClaim, cp resource.Composite) error { return nil })),
+				WithConnectionPropagator(ConnectionPropagatorFn(func(ctx context.Context, to resource.LocalConnectionSecretOwner, from resource.ConnectionSecretOwner) (propagated bool, err error) {
+					return false, nil
+				})),
+				WithMetricsRecorder(claimReadyRecorder{recorded: &recorded}),
+			)
+			if _, err := r.Reconcile(context.Background(), reconcile.Request{}); err != nil {
+				t.Fatalf("r.Reconcile(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, recorded); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want recorded, +got recorded:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+// WebhookPath is the path at which composite resource claims are validated.
+const WebhookPath = "/validate-apiextensions-crossplane-io-claims"
+
+// Error strings.
+const (
+	errListXRDs                = "cannot list CompositeResourceDefinitions"
+	errGetNamespace            = "cannot get namespace"
+	errParseNamespaceSelector  = "cannot parse claim namespace selector"
+	errListQuotas              = "cannot list CompositeResourceQuotas"
+	errListClaims              = "cannot list composite resource claims"
+	errDecodeClaim             = "cannot decode composite resource claim"
+	errGetComposition          = "cannot get Composition"
+	errListCompositions        = "cannot list Compositions"
+	errFmtNamespaceNotSelected = "%s claims may not be created in namespace %q; its labels must match %q"
+	errFmtQuotaExceeded        = "CompositeResourceQuota %q allows claims to create at most %d %s composite resources in namespace %q"
+
+	msgFmtDeprecatedField        = "%s is deprecated"
+	msgFmtDeprecatedFieldMessage = "%s is deprecated: %s"
+
+	msgFmtCompositionNotFound     = "Composition %q does not exist; this claim will not become ready until it does"
+	msgFmtCompositionIncompatible = "Composition %q does not compose %s composite resources; this claim will not become ready until it does"
+	msgFmtNoCompositionSelected   = "No Composition matches labels %q and composes %s composite resources; this claim will not become ready until one does"
+	msgFmtCannotCheckComposition  = "Cannot check whether this claim will select a Composition: %s"
+)
+
+// A NamespaceValidator is an admission handler that rejects composite resource
+// claims created in namespaces not matched by the claim namespace selector of
+// the CompositeResourceDefinition that offers them.
+type NamespaceValidator struct {
+	client client.Reader
+}
+
+// NewNamespaceValidator returns an admission handler that validates the
+// namespaces in which composite resource claims are created.
+func NewNamespaceValidator(c client.Reader) *NamespaceValidator {
+	return &NamespaceValidator{client: c}
+}
+
+// Handle an admission request for a composite resource claim.
+func (v *NamespaceValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+
+	d, err := offeredBy(ctx, v.client, schema.GroupKind{Group: req.Kind.Group, Kind: req.Kind.Kind})
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	// We only validate claims offered by an XRD with a namespace selector.
+	if d == nil || d.Spec.ClaimNamespaceSelector == nil {
+		return admission.Allowed("")
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(d.Spec.ClaimNamespaceSelector)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, errors.Wrap(err, errParseNamespaceSelector))
+	}
+
+	ns := &corev1.Namespace{}
+	if err := v.client.Get(ctx, types.NamespacedName{Name: req.Namespace}, ns); err != nil {
+		return admission.Errored(http.StatusInternalServerError, errors.Wrap(err, errGetNamespace))
+	}
+
+	if !s.Matches(labels.Set(ns.GetLabels())) {
+		return admission.Denied(fmt.Sprintf(errFmtNamespaceNotSelected, req.Kind.Kind, req.Namespace, s.String()))
+	}
+
+	return admission.Allowed("")
+}
+
+// A QuotaValidator is an admission handler that rejects composite resource
+// claims that would cause the claims in a namespace to create more composite
+// resources of a particular type than a CompositeResourceQuota allows.
+type QuotaValidator struct {
+	client client.Reader
+}
+
+// NewQuotaValidator returns an admission handler that enforces
+// CompositeResourceQuotas when composite resource claims are created.
+func NewQuotaValidator(c client.Reader) *QuotaValidator {
+	return &QuotaValidator{client: c}
+}
+
+// Handle an admission request for a composite resource claim.
+func (v *QuotaValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create {
+		return admission.Allowed("")
+	}
+
+	ql := &v1alpha1.CompositeResourceQuotaList{}
+	if err := v.client.List(ctx, ql, client.InNamespace(req.Namespace)); err != nil {
+		return admission.Errored(http.StatusInternalServerError, errors.Wrap(err, errListQuotas))
+	}
+	if len(ql.Items) == 0 {
+		return admission.Allowed("")
+	}
+
+	d, err := offeredBy(ctx, v.client, schema.GroupKind{Group: req.Kind.Group, Kind: req.Kind.Kind})
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if d == nil {
+		return admission.Allowed("")
+	}
+
+	// Each claim creates exactly one composite resource, so we count claims
+	// rather than composite resources. This ensures claims that have not yet
+	// created their composite resource count toward the quota.
+	used := int64(-1)
+	gk := d.GetCompositeGroupVersionKind().GroupKind()
+	for _, q := range ql.Items {
+		for _, l := range q.Spec.Limits {
+			if !l.Limits(gk) {
+				continue
+			}
+			if used < 0 {
+				cl := &kunstructured.UnstructuredList{}
+				cl.SetGroupVersionKind(schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind + "List"})
+				if err := v.client.List(ctx, cl, client.InNamespace(req.Namespace)); err != nil {
+					return admission.Errored(http.StatusInternalServerError, errors.Wrap(err, errListClaims))
+				}
+				used = int64(len(cl.Items))
+			}
+			if used >= l.Max {
+				return admission.Denied(fmt.Sprintf(errFmtQuotaExceeded, q.GetName(), l.Max, gk.Kind, req.Namespace))
+			}
+		}
+	}
+
+	return admission.Allowed("")
+}
+
+// A DeprecationWarner is an admission handler that warns when a composite
+// resource claim sets a field that the CompositeResourceDefinition that offers
+// it has deprecated.
+type DeprecationWarner struct {
+	client client.Reader
+}
+
+// NewDeprecationWarner returns an admission handler that warns about deprecated
+// fields when composite resource claims are created or updated.
+func NewDeprecationWarner(c client.Reader) *DeprecationWarner {
+	return &DeprecationWarner{client: c}
+}
+
+// Handle an admission request for a composite resource claim.
+func (w *DeprecationWarner) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	d, err := offeredBy(ctx, w.client, schema.GroupKind{Group: req.Kind.Group, Kind: req.Kind.Kind})
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if d == nil {
+		return admission.Allowed("")
+	}
+
+	var fields []v1.DeprecatedField
+	for _, vr := range d.Spec.Versions {
+		if vr.Name == req.Kind.Version {
+			fields = vr.DeprecatedFields
+		}
+	}
+	if len(fields) == 0 {
+		return admission.Allowed("")
+	}
+
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, errors.Wrap(err, errDecodeClaim))
+	}
+	p := fieldpath.Pave(obj)
+
+	warnings := make([]string, 0)
+	for _, f := range fields {
+		// A path that can't be expanded (e.g. because it indexes a field that
+		// isn't an array) can't be set, so we don't warn about it.
+		set, err := p.ExpandWildcards(f.Path)
+		if err != nil || len(set) == 0 {
+			continue
+		}
+		if f.Message == "" {
+			warnings = append(warnings, fmt.Sprintf(msgFmtDeprecatedField, f.Path))
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf(msgFmtDeprecatedFieldMessage, f.Path, f.Message))
+	}
+	if len(warnings) == 0 {
+		return admission.Allowed("")
+	}
+
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// A CompositionWarner is an admission handler that warns when a composite
+// resource claim would not select any Composition, for example because the
+// Composition it references does not exist. Such a claim would otherwise be
+// admitted and quietly never become ready. A CompositionWarner never denies a
+// claim; if it can't tell which Composition a claim would select it says so in
+// a warning.
+type CompositionWarner struct {
+	client client.Reader
+}
+
+// NewCompositionWarner returns an admission handler that warns when composite
+// resource claims that are created or updated would not select a Composition.
+func NewCompositionWarner(c client.Reader) *CompositionWarner {
+	return &CompositionWarner{client: c}
+}
+
+// Handle an admission request for a composite resource claim.
+func (w *CompositionWarner) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	d, err := offeredBy(ctx, w.client, schema.GroupKind{Group: req.Kind.Group, Kind: req.Kind.Kind})
+	if err != nil {
+		return admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtCannotCheckComposition, err))
+	}
+	if d == nil {
+		return admission.Allowed("")
+	}
+
+	cm := claim.New()
+	if err := json.Unmarshal(req.Object.Raw, &cm.Object); err != nil {
+		return admission.Errored(http.StatusBadRequest, errors.Wrap(err, errDecodeClaim))
+	}
+
+	// We resolve the Composition the same way the composite resource
+	// reconciler's selectors will. An enforced Composition takes precedence,
+	// then the claim's reference, then the XRD's default if the claim
+	// neither references nor selects a Composition, then the claim's labels.
+	name := ""
+	switch {
+	case d.Spec.EnforcedCompositionRef != nil:
+		name = d.Spec.EnforcedCompositionRef.Name
+	case cm.GetCompositionReference() != nil:
+		name = cm.GetCompositionReference().Name
+	case cm.GetCompositionSelector() == nil && d.Spec.DefaultCompositionRef != nil:
+		name = d.Spec.DefaultCompositionRef.Name
+	}
+
+	v, k := d.GetCompositeGroupVersionKind().ToAPIVersionAndKind()
+
+	if name != "" {
+		comp := &v1.Composition{}
+		err := w.client.Get(ctx, types.NamespacedName{Name: name}, comp)
+		if resource.IgnoreNotFound(err) != nil {
+			return admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtCannotCheckComposition, errors.Wrap(err, errGetComposition)))
+		}
+		if err != nil {
+			return admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtCompositionNotFound, name))
+		}
+		if !comp.Spec.CompatibleWith(v, k) {
+			return admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtCompositionIncompatible, name, k))
+		}
+		return admission.Allowed("")
+	}
+
+	ml := map[string]string{}
+	if sel := cm.GetCompositionSelector(); sel != nil {
+		ml = sel.MatchLabels
+	}
+	l := &v1.CompositionList{}
+	if err := w.client.List(ctx, l, client.MatchingLabels(ml)); err != nil {
+		return admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtCannotCheckComposition, errors.Wrap(err, errListCompositions)))
+	}
+	for i := range l.Items {
+		if l.Items[i].Spec.CompatibleWith(v, k) {
+			return admission.Allowed("")
+		}
+	}
+
+	return admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtNoCompositionSelected, labels.Set(ml).String(), k))
+}
+
+// offeredBy returns the CompositeResourceDefinition that offers claims of the
+// supplied group and kind, if any.
+func offeredBy(ctx context.Context, c client.Reader, gk schema.GroupKind) (*v1.CompositeResourceDefinition, error) {
+	l := &v1.CompositeResourceDefinitionList{}
+	if err := c.List(ctx, l); err != nil {
+		return nil, errors.Wrap(err, errListXRDs)
+	}
+	for i := range l.Items {
+		d := &l.Items[i]
+		if d.OffersClaim() && d.Spec.Group == gk.Group && d.Spec.ClaimNames.Kind == gk.Kind {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
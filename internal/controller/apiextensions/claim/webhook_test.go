@@ -0,0 +1,543 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package claim
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+func TestNamespaceValidatorHandle(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	xrd := v1.CompositeResourceDefinition{
+		Spec: v1.CompositeResourceDefinitionSpec{
+			Group:      "example.org",
+			ClaimNames: &extv1.CustomResourceDefinitionNames{Kind: "Database"},
+			ClaimNamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"tenant": "a"},
+			},
+		},
+	}
+
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Database"},
+		Namespace: "default",
+		Operation: admissionv1.Create,
+	}}
+
+	listXRDs := func(items ...v1.CompositeResourceDefinition) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			obj.(*v1.CompositeResourceDefinitionList).Items = items
+			return nil
+		}
+	}
+
+	getNamespace := func(l map[string]string) test.MockGetFn {
+		return func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			obj.(*corev1.Namespace).SetLabels(l)
+			return nil
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		client client.Reader
+		req    admission.Request
+		want   admission.Response
+	}{
+		"NotCreate": {
+			reason: "We should only validate namespaces when claims are created.",
+			client: &test.MockClient{},
+			req: admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Update,
+			}},
+			want: admission.Allowed(""),
+		},
+		"ListXRDsError": {
+			reason: "We should return any error encountered listing XRDs.",
+			req:    req,
+			client: &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+			want:   admission.Errored(http.StatusInternalServerError, errors.Wrap(errBoom, errListXRDs)),
+		},
+		"NoXRD": {
+			reason: "We should allow claims that are not offered by any XRD.",
+			req:    req,
+			client: &test.MockClient{MockList: listXRDs()},
+			want:   admission.Allowed(""),
+		},
+		"NoSelector": {
+			reason: "We should allow claims offered by an XRD without a namespace selector.",
+			req:    req,
+			client: &test.MockClient{MockList: listXRDs(v1.CompositeResourceDefinition{
+				Spec: v1.CompositeResourceDefinitionSpec{
+					Group:      "example.org",
+					ClaimNames: &extv1.CustomResourceDefinitionNames{Kind: "Database"},
+				},
+			})},
+			want: admission.Allowed(""),
+		},
+		"GetNamespaceError": {
+			reason: "We should return any error encountered getting the claim's namespace.",
+			req:    req,
+			client: &test.MockClient{
+				MockList: listXRDs(xrd),
+				MockGet:  test.NewMockGetFn(errBoom),
+			},
+			want: admission.Errored(http.StatusInternalServerError, errors.Wrap(errBoom, errGetNamespace)),
+		},
+		"NamespaceNotSelected": {
+			reason: "We should deny claims created in a namespace that the XRD's selector does not match.",
+			req:    req,
+			client: &test.MockClient{
+				MockList: listXRDs(xrd),
+				MockGet:  getNamespace(map[string]string{"tenant": "b"}),
+			},
+			want: admission.Denied(fmt.Sprintf(errFmtNamespaceNotSelected, "Database", "default", "tenant=a")),
+		},
+		"NamespaceSelected": {
+			reason: "We should allow claims created in a namespace that the XRD's selector matches.",
+			req:    req,
+			client: &test.MockClient{
+				MockList: listXRDs(xrd),
+				MockGet:  getNamespace(map[string]string{"tenant": "a"}),
+			},
+			want: admission.Allowed(""),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := NewNamespaceValidator(tc.client)
+			got := v.Handle(context.Background(), tc.req)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nv.Handle(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestQuotaValidatorHandle(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	xrd := v1.CompositeResourceDefinition{
+		Spec: v1.CompositeResourceDefinitionSpec{
+			Group:      "example.org",
+			Names:      extv1.CustomResourceDefinitionNames{Kind: "XDatabase"},
+			ClaimNames: &extv1.CustomResourceDefinitionNames{Kind: "Database"},
+			Versions:   []v1.CompositeResourceDefinitionVersion{{Name: "v1", Referenceable: true}},
+		},
+	}
+
+	quota := v1alpha1.CompositeResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "databases"},
+		Spec: v1alpha1.CompositeResourceQuotaSpec{
+			Limits: []v1alpha1.CompositeResourceQuotaLimit{
+				{CompositeTypeRef: v1alpha1.TypeReference{APIVersion: "example.org/v1", Kind: "XCache"}, Max: 0},
+				{CompositeTypeRef: v1alpha1.TypeReference{APIVersion: "example.org/v1beta1", Kind: "XDatabase"}, Max: 2},
+			},
+		},
+	}
+
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Kind:      metav1.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Database"},
+		Namespace: "default",
+		Operation: admissionv1.Create,
+	}}
+
+	list := func(quotas []v1alpha1.CompositeResourceQuota, claims int) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			switch l := obj.(type) {
+			case *v1alpha1.CompositeResourceQuotaList:
+				l.Items = quotas
+			case *v1.CompositeResourceDefinitionList:
+				l.Items = []v1.CompositeResourceDefinition{xrd}
+			case *kunstructured.UnstructuredList:
+				l.Items = make([]kunstructured.Unstructured, claims)
+			}
+			return nil
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		client client.Reader
+		req    admission.Request
+		want   admission.Response
+	}{
+		"NotCreate": {
+			reason: "We should only enforce quotas when claims are created.",
+			client: &test.MockClient{},
+			req: admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Update,
+			}},
+			want: admission.Allowed(""),
+		},
+		"ListQuotasError": {
+			reason: "We should return any error encountered listing quotas.",
+			client: &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+			req:    req,
+			want:   admission.Errored(http.StatusInternalServerError, errors.Wrap(errBoom, errListQuotas)),
+		},
+		"NoQuotas": {
+			reason: "We should allow claims in namespaces without quotas.",
+			client: &test.MockClient{MockList: list(nil, 10)},
+			req:    req,
+			want:   admission.Allowed(""),
+		},
+		"ListClaimsError": {
+			reason: "We should return any error encountered listing existing claims.",
+			client: &test.MockClient{MockList: func(ctx context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+				if _, ok := obj.(*kunstructured.UnstructuredList); ok {
+					return errBoom
+				}
+				return list([]v1alpha1.CompositeResourceQuota{quota}, 0)(ctx, obj, opts...)
+			}},
+			req:  req,
+			want: admission.Errored(http.StatusInternalServerError, errors.Wrap(errBoom, errListClaims)),
+		},
+		"WithinQuota": {
+			reason: "We should allow claims that would not exceed a quota.",
+			client: &test.MockClient{MockList: list([]v1alpha1.CompositeResourceQuota{quota}, 1)},
+			req:    req,
+			want:   admission.Allowed(""),
+		},
+		"QuotaExceeded": {
+			reason: "We should deny claims that would exceed a quota.",
+			client: &test.MockClient{MockList: list([]v1alpha1.CompositeResourceQuota{quota}, 2)},
+			req:    req,
+			want:   admission.Denied(fmt.Sprintf(errFmtQuotaExceeded, "databases", 2, "XDatabase", "default")),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := NewQuotaValidator(tc.client)
+			got := v.Handle(context.Background(), tc.req)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nv.Handle(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDeprecationWarnerHandle(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	xrd := v1.CompositeResourceDefinition{
+		Spec: v1.CompositeResourceDefinitionSpec{
+			Group:      "example.org",
+			ClaimNames: &extv1.CustomResourceDefinitionNames{Kind: "Database"},
+			Versions: []v1.CompositeResourceDefinitionVersion{
+				{
+					Name: "v1",
+					DeprecatedFields: []v1.DeprecatedField{
+						{Path: "spec.storageGB", Message: "Use spec.parameters.storageGB."},
+						{Path: "spec.users[*].admin"},
+						{Path: "spec.region"},
+					},
+				},
+			},
+		},
+	}
+
+	req := func(op admissionv1.Operation, version, raw string) admission.Request {
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind{Group: "example.org", Version: version, Kind: "Database"},
+			Namespace: "default",
+			Operation: op,
+			Object:    runtime.RawExtension{Raw: []byte(raw)},
+		}}
+	}
+
+	listXRDs := func(items ...v1.CompositeResourceDefinition) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			obj.(*v1.CompositeResourceDefinitionList).Items = items
+			return nil
+		}
+	}
+
+	claim := `{"spec":{"storageGB":10,"users":[{"name":"a"},{"name":"b","admin":true}]}}`
+
+	cases := map[string]struct {
+		reason string
+		client client.Reader
+		req    admission.Request
+		want   admission.Response
+	}{
+		"Delete": {
+			reason: "We should only warn about claims that are created or updated.",
+			client: &test.MockClient{},
+			req:    req(admissionv1.Delete, "v1", ""),
+			want:   admission.Allowed(""),
+		},
+		"ListXRDsError": {
+			reason: "We should return any error encountered listing XRDs.",
+			client: &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+			req:    req(admissionv1.Create, "v1", claim),
+			want:   admission.Errored(http.StatusInternalServerError, errors.Wrap(errBoom, errListXRDs)),
+		},
+		"NoXRD": {
+			reason: "We should allow claims that are not offered by any XRD.",
+			client: &test.MockClient{MockList: listXRDs()},
+			req:    req(admissionv1.Create, "v1", claim),
+			want:   admission.Allowed(""),
+		},
+		"NoDeprecatedFields": {
+			reason: "We should allow claims of a version without deprecated fields without warnings.",
+			client: &test.MockClient{MockList: listXRDs(xrd)},
+			req:    req(admissionv1.Create, "v2", claim),
+			want:   admission.Allowed(""),
+		},
+		"DecodeError": {
+			reason: "We should return any error encountered decoding the claim.",
+			client: &test.MockClient{MockList: listXRDs(xrd)},
+			req:    req(admissionv1.Create, "v1", "{"),
+			want:   admission.Errored(http.StatusBadRequest, errors.Wrap(errors.New("unexpected end of JSON input"), errDecodeClaim)),
+		},
+		"DeprecatedFieldsNotSet": {
+			reason: "We should allow claims that don't set deprecated fields without warnings.",
+			client: &test.MockClient{MockList: listXRDs(xrd)},
+			req:    req(admissionv1.Update, "v1", `{"spec":{"users":[{"name":"a"}]}}`),
+			want:   admission.Allowed(""),
+		},
+		"DeprecatedFieldsSet": {
+			reason: "We should allow claims that set deprecated fields with a warning for each field they set.",
+			client: &test.MockClient{MockList: listXRDs(xrd)},
+			req:    req(admissionv1.Create, "v1", claim),
+			want: admission.Allowed("").WithWarnings(
+				fmt.Sprintf(msgFmtDeprecatedFieldMessage, "spec.storageGB", "Use spec.parameters.storageGB."),
+				fmt.Sprintf(msgFmtDeprecatedField, "spec.users[*].admin"),
+			),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := NewDeprecationWarner(tc.client)
+			got := w.Handle(context.Background(), tc.req)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nw.Handle(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCompositionWarnerHandle(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	xrd := v1.CompositeResourceDefinition{
+		Spec: v1.CompositeResourceDefinitionSpec{
+			Group:      "example.org",
+			Names:      extv1.CustomResourceDefinitionNames{Kind: "XDatabase"},
+			ClaimNames: &extv1.CustomResourceDefinitionNames{Kind: "Database"},
+			Versions:   []v1.CompositeResourceDefinitionVersion{{Name: "v1", Referenceable: true}},
+		},
+	}
+	withDefault := *xrd.DeepCopy()
+	withDefault.Spec.DefaultCompositionRef = &xpv1.Reference{Name: "default"}
+	withEnforced := *xrd.DeepCopy()
+	withEnforced.Spec.EnforcedCompositionRef = &xpv1.Reference{Name: "enforced"}
+
+	req := func(op admissionv1.Operation, raw string) admission.Request {
+		return admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Kind:      metav1.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Database"},
+			Namespace: "default",
+			Operation: op,
+			Object:    runtime.RawExtension{Raw: []byte(raw)},
+		}}
+	}
+
+	comp := func(name, kind string) v1.Composition {
+		return v1.Composition{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.CompositionSpec{CompositeTypeRef: v1.TypeReference{APIVersion: "example.org/v1", Kind: kind}},
+		}
+	}
+
+	// Our mock lists the supplied XRDs, and Compositions named after the
+	// labels they're selected by. It gets only the supplied Compositions.
+	mock := func(xrds []v1.CompositeResourceDefinition, comps ...v1.Composition) *test.MockClient {
+		return &test.MockClient{
+			MockList: func(_ context.Context, obj client.ObjectList, opts ...client.ListOption) error {
+				switch l := obj.(type) {
+				case *v1.CompositeResourceDefinitionList:
+					l.Items = xrds
+				case *v1.CompositionList:
+					lo := &client.ListOptions{}
+					lo.ApplyOptions(opts)
+					for _, c := range comps {
+						if lo.LabelSelector.Matches(labels.Set(c.GetLabels())) {
+							l.Items = append(l.Items, c)
+						}
+					}
+				}
+				return nil
+			},
+			MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+				for _, c := range comps {
+					if c.GetName() == key.Name {
+						c.DeepCopyInto(obj.(*v1.Composition))
+						return nil
+					}
+				}
+				return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+			},
+		}
+	}
+
+	labelled := comp("labelled", "XDatabase")
+	labelled.SetLabels(map[string]string{"provider": "aws"})
+
+	cases := map[string]struct {
+		reason string
+		client client.Reader
+		req    admission.Request
+		want   admission.Response
+	}{
+		"Delete": {
+			reason: "We should only warn about claims that are created or updated.",
+			client: &test.MockClient{},
+			req:    req(admissionv1.Delete, ""),
+			want:   admission.Allowed(""),
+		},
+		"ListXRDsError": {
+			reason: "We should allow, with a warning, a claim for which we can't list XRDs.",
+			client: &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+			req:    req(admissionv1.Create, `{}`),
+			want:   admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtCannotCheckComposition, errors.Wrap(errBoom, errListXRDs))),
+		},
+		"NoXRD": {
+			reason: "We should allow claims that are not offered by any XRD.",
+			client: mock(nil),
+			req:    req(admissionv1.Create, `{}`),
+			want:   admission.Allowed(""),
+		},
+		"DecodeError": {
+			reason: "We should return any error encountered decoding the claim.",
+			client: mock([]v1.CompositeResourceDefinition{xrd}),
+			req:    req(admissionv1.Create, "{"),
+			want:   admission.Errored(http.StatusBadRequest, errors.Wrap(errors.New("unexpected end of JSON input"), errDecodeClaim)),
+		},
+		"GetCompositionError": {
+			reason: "We should allow, with a warning, a claim whose referenced Composition we can't get.",
+			client: &test.MockClient{
+				MockList: test.NewMockListFn(nil, func(obj client.ObjectList) error {
+					obj.(*v1.CompositeResourceDefinitionList).Items = []v1.CompositeResourceDefinition{xrd}
+					return nil
+				}),
+				MockGet: test.NewMockGetFn(errBoom),
+			},
+			req:  req(admissionv1.Create, `{"spec":{"compositionRef":{"name":"cool"}}}`),
+			want: admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtCannotCheckComposition, errors.Wrap(errBoom, errGetComposition))),
+		},
+		"ReferencedCompositionNotFound": {
+			reason: "We should warn about claims that reference a Composition that does not exist.",
+			client: mock([]v1.CompositeResourceDefinition{xrd}),
+			req:    req(admissionv1.Create, `{"spec":{"compositionRef":{"name":"cool"}}}`),
+			want:   admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtCompositionNotFound, "cool")),
+		},
+		"ReferencedCompositionIncompatible": {
+			reason: "We should warn about claims that reference a Composition of a different kind of composite resource.",
+			client: mock([]v1.CompositeResourceDefinition{xrd}, comp("cool", "XCache")),
+			req:    req(admissionv1.Update, `{"spec":{"compositionRef":{"name":"cool"}}}`),
+			want:   admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtCompositionIncompatible, "cool", "XDatabase")),
+		},
+		"ReferencedCompositionExists": {
+			reason: "We should allow claims that reference a compatible Composition without warnings.",
+			client: mock([]v1.CompositeResourceDefinition{xrd}, comp("cool", "XDatabase")),
+			req:    req(admissionv1.Create, `{"spec":{"compositionRef":{"name":"cool"}}}`),
+			want:   admission.Allowed(""),
+		},
+		"EnforcedCompositionNotFound": {
+			reason: "We should warn about the enforced Composition, not the referenced one, if the XRD enforces a Composition.",
+			client: mock([]v1.CompositeResourceDefinition{withEnforced}, comp("cool", "XDatabase")),
+			req:    req(admissionv1.Create, `{"spec":{"compositionRef":{"name":"cool"}}}`),
+			want:   admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtCompositionNotFound, "enforced")),
+		},
+		"DefaultCompositionNotFound": {
+			reason: "We should warn about the default Composition if the claim neither references nor selects a Composition.",
+			client: mock([]v1.CompositeResourceDefinition{withDefault}, comp("cool", "XDatabase")),
+			req:    req(admissionv1.Create, `{}`),
+			want:   admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtCompositionNotFound, "default")),
+		},
+		"DefaultCompositionIgnored": {
+			reason: "We should not consider the default Composition if the claim selects a Composition.",
+			client: mock([]v1.CompositeResourceDefinition{withDefault}, labelled),
+			req:    req(admissionv1.Create, `{"spec":{"compositionSelector":{"matchLabels":{"provider":"aws"}}}}`),
+			want:   admission.Allowed(""),
+		},
+		"ListCompositionsError": {
+			reason: "We should allow, with a warning, a claim for which we can't list Compositions.",
+			client: &test.MockClient{
+				MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+					if l, ok := obj.(*v1.CompositeResourceDefinitionList); ok {
+						l.Items = []v1.CompositeResourceDefinition{xrd}
+						return nil
+					}
+					return errBoom
+				},
+			},
+			req:  req(admissionv1.Create, `{}`),
+			want: admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtCannotCheckComposition, errors.Wrap(errBoom, errListCompositions))),
+		},
+		"NoCompositionSelected": {
+			reason: "We should warn about claims whose labels select no compatible Composition.",
+			client: mock([]v1.CompositeResourceDefinition{xrd}, labelled, comp("cache", "XCache")),
+			req:    req(admissionv1.Create, `{"spec":{"compositionSelector":{"matchLabels":{"provider":"gcp"}}}}`),
+			want:   admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtNoCompositionSelected, "provider=gcp", "XDatabase")),
+		},
+		"CompositionSelected": {
+			reason: "We should allow claims that neither reference nor select a Composition without warnings if any compatible Composition exists.",
+			client: mock([]v1.CompositeResourceDefinition{xrd}, comp("cache", "XCache"), labelled),
+			req:    req(admissionv1.Create, `{}`),
+			want:   admission.Allowed(""),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := NewCompositionWarner(tc.client)
+			got := w.Handle(context.Background(), tc.req)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nw.Handle(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
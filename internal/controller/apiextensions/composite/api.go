@@ -18,7 +18,9 @@ package composite
 
 import (
 	"context"
+	"fmt"
 	"math/rand"
+	"regexp"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
@@ -31,6 +33,7 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
@@ -53,6 +56,11 @@ const (
 	errUpdateComposite                 = "cannot update composite resource"
 	errCompositionNotCompatible        = "referenced composition is not compatible with this composite resource"
 	errGetXRD                          = "cannot get composite resource definition"
+	errConnectionSecretNamespace       = "cannot derive connection secret namespace"
+	errFmtNotString                    = "combined value must be a string, not %T"
+	errFmtSelectionRule                = "cannot evaluate composition selection rule at index %d"
+	errFmtOperatorOneValue             = "operator %s requires exactly one value"
+	errFmtUnknownOperator              = "unknown operator %s"
 )
 
 // Event reasons.
@@ -277,7 +285,7 @@ func (r *APILabelSelectorResolver) SelectComposition(ctx context.Context, cp res
 	v, k := cp.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
 
 	for _, comp := range list.Items {
-		if comp.Spec.CompositeTypeRef.APIVersion == v && comp.Spec.CompositeTypeRef.Kind == k {
+		if comp.Spec.CompatibleWith(v, k) {
 			// This composition is compatible with our composite resource.
 			candidates = append(candidates, comp.Name)
 		}
@@ -299,9 +307,10 @@ func NewAPIDefaultCompositionSelector(c client.Client, ref corev1.ObjectReferenc
 	return &APIDefaultCompositionSelector{client: c, defRef: ref, recorder: r}
 }
 
-// APIDefaultCompositionSelector selects the default composition referenced in
-// the definition of the resource if neither a reference nor selector is given
-// in composite resource.
+// APIDefaultCompositionSelector selects the composition of the first composition
+// selection rule the resource matches, or else the default composition
+// referenced in the definition of the resource, if neither a reference nor
+// selector is given in composite resource.
 type APIDefaultCompositionSelector struct {
 	client   client.Client
 	defRef   corev1.ObjectReference
@@ -318,6 +327,18 @@ func (s *APIDefaultCompositionSelector) SelectComposition(ctx context.Context, c
 	if err := s.client.Get(ctx, meta.NamespacedNameOf(&s.defRef), def); err != nil {
 		return errors.Wrap(err, errGetXRD)
 	}
+	for i, rule := range def.Spec.CompositionSelectionRules {
+		ok, err := meetsRequirements(cp, rule.Requirements)
+		if err != nil {
+			return errors.Wrapf(err, errFmtSelectionRule, i)
+		}
+		if !ok {
+			continue
+		}
+		cp.SetCompositionReference(&corev1.ObjectReference{Name: rule.CompositionRef.Name})
+		s.recorder.Event(cp, event.Normal(reasonCompositionSelection, fmt.Sprintf("Composition %q has been selected by selection rule at index %d", rule.CompositionRef.Name, i)))
+		return nil
+	}
 	if def.Spec.DefaultCompositionRef == nil {
 		return nil
 	}
@@ -326,6 +347,72 @@ func (s *APIDefaultCompositionSelector) SelectComposition(ctx context.Context, c
 	return nil
 }
 
+// meetsRequirements returns true if the fields of the supplied object meet all
+// of the supplied requirements.
+func meetsRequirements(o runtime.Object, rs []v1.FieldRequirement) (bool, error) {
+	p, err := fieldpath.PaveObject(o)
+	if err != nil {
+		return false, err
+	}
+	for _, r := range rs {
+		ok, err := meetsRequirement(p, r)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func meetsRequirement(p *fieldpath.Paved, r v1.FieldRequirement) (bool, error) {
+	v, err := p.GetValue(r.FieldPath)
+	if resource.Ignore(fieldpath.IsNotFound, err) != nil {
+		return false, err
+	}
+	exists := err == nil
+
+	// Only scalar values can be compared to strings.
+	var s string
+	scalar := true
+	switch tv := v.(type) {
+	case string:
+		s = tv
+	case bool, int64, float64:
+		s = fmt.Sprint(tv)
+	default:
+		scalar = false
+	}
+
+	switch r.Operator {
+	case v1.FieldSelectorOpExists:
+		return exists, nil
+	case v1.FieldSelectorOpDoesNotExist:
+		return !exists, nil
+	case v1.FieldSelectorOpIn:
+		return exists && scalar && oneOf(r.Values, s), nil
+	case v1.FieldSelectorOpNotIn:
+		return !exists || !scalar || !oneOf(r.Values, s), nil
+	case v1.FieldSelectorOpMatches:
+		if len(r.Values) != 1 {
+			return false, errors.Errorf(errFmtOperatorOneValue, r.Operator)
+		}
+		re, err := regexp.Compile(r.Values[0])
+		if err != nil {
+			return false, err
+		}
+		return exists && scalar && re.MatchString(s), nil
+	}
+	return false, errors.Errorf(errFmtUnknownOperator, r.Operator)
+}
+
+func oneOf(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
 // NewEnforcedCompositionSelector returns a EnforcedCompositionSelector.
 func NewEnforcedCompositionSelector(def v1.CompositeResourceDefinition, r event.Recorder) *EnforcedCompositionSelector {
 	return &EnforcedCompositionSelector{def: def, recorder: r}
@@ -376,38 +463,105 @@ func (cc *ConfiguratorChain) Configure(ctx context.Context, cp resource.Composit
 	return nil
 }
 
+// An APIConfiguratorOption configures an APIConfigurator.
+type APIConfiguratorOption func(c *APIConfigurator)
+
+// WithDefaultConnectionSecretNamespace specifies the namespace to which
+// composite resources write their connection secret when neither they nor
+// their composition specify one.
+func WithDefaultConnectionSecretNamespace(ns string) APIConfiguratorOption {
+	return func(c *APIConfigurator) {
+		c.defaultNamespace = &ns
+	}
+}
+
 // NewAPIConfigurator returns a Configurator that configures a
 // composite resource using its composition.
-func NewAPIConfigurator(c client.Client) *APIConfigurator {
-	return &APIConfigurator{client: c}
+func NewAPIConfigurator(c client.Client, opts ...APIConfiguratorOption) *APIConfigurator {
+	ac := &APIConfigurator{client: c}
+	for _, o := range opts {
+		o(ac)
+	}
+	return ac
 }
 
 // An APIConfigurator configures a composite resource using its
 // composition.
 type APIConfigurator struct {
-	client client.Client
+	client           client.Client
+	defaultNamespace *string
 }
 
 // Configure any required fields that were omitted from the composite resource
 // by copying them from its composition.
 func (c *APIConfigurator) Configure(ctx context.Context, cp resource.Composite, comp *v1.Composition) error {
 	apiVersion, kind := cp.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
-	if comp.Spec.CompositeTypeRef.APIVersion != apiVersion || comp.Spec.CompositeTypeRef.Kind != kind {
+	if !comp.Spec.CompatibleWith(apiVersion, kind) {
 		return errors.New(errCompositionNotCompatible)
 	}
 
-	if cp.GetWriteConnectionSecretToReference() != nil || comp.Spec.WriteConnectionSecretsToNamespace == nil {
+	if cp.GetWriteConnectionSecretToReference() != nil {
+		return nil
+	}
+
+	ns, err := ConnectionSecretNamespace(cp, comp.Spec)
+	if err != nil {
+		return errors.Wrap(err, errConnectionSecretNamespace)
+	}
+	if ns == nil {
+		ns = c.defaultNamespace
+	}
+	if ns == nil {
 		return nil
 	}
 
 	cp.SetWriteConnectionSecretToReference(&xpv1.SecretReference{
 		Name:      string(cp.GetUID()),
-		Namespace: *comp.Spec.WriteConnectionSecretsToNamespace,
+		Namespace: *ns,
 	})
 
 	return errors.Wrap(c.client.Update(ctx, cp), errUpdateComposite)
 }
 
+// ConnectionSecretNamespace returns the namespace in which the supplied
+// composite resource's connection secret should be written, or nil if it
+// should not be written. A namespace derived from the composite resource's
+// fields takes precedence over the composition's static namespace, which is
+// used if any of the fields are not yet set.
+func ConnectionSecretNamespace(cp resource.Composite, cs v1.CompositionSpec) (*string, error) {
+	c := cs.WriteConnectionSecretsToNamespaceFrom
+	if c == nil {
+		return cs.WriteConnectionSecretsToNamespace, nil
+	}
+
+	from, err := fieldpath.PaveObject(cp)
+	if err != nil {
+		return nil, err
+	}
+
+	in := make([]interface{}, len(c.Variables))
+	for i, v := range c.Variables {
+		iv, err := from.GetValue(v.FromFieldPath)
+		if fieldpath.IsNotFound(err) {
+			return cs.WriteConnectionSecretsToNamespace, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		in[i] = iv
+	}
+
+	out, err := c.Combine(in)
+	if err != nil {
+		return nil, err
+	}
+	ns, ok := out.(string)
+	if !ok {
+		return nil, errors.Errorf(errFmtNotString, out)
+	}
+	return &ns, nil
+}
+
 // NewAPINamingConfigurator returns a Configurator that sets the root name prefixKu
 // to its own name if it is not already set.
 func NewAPINamingConfigurator(c client.Client) *APINamingConfigurator {
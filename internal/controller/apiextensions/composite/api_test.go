@@ -17,6 +17,7 @@ package composite
 
 import (
 	"context"
+	"regexp"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -30,9 +31,11 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
@@ -579,8 +582,23 @@ func TestConfigure(t *testing.T) {
 		ConnectionSecretWriterTo: cs,
 	}
 
+	// withRegion returns an unstructured composite resource labelled with
+	// the supplied region, and with the supplied connection secret reference.
+	withRegion := func(region string, ref *xpv1.SecretReference) *composite.Unstructured {
+		xr := composite.New()
+		xr.SetUID(types.UID(cs.Ref.Name))
+		if region != "" {
+			xr.SetLabels(map[string]string{"region": region})
+		}
+		if ref != nil {
+			xr.SetWriteConnectionSecretToReference(ref)
+		}
+		return xr
+	}
+
 	type args struct {
 		kube client.Client
+		opts []APIConfiguratorOption
 		cp   resource.Composite
 		comp *v1.Composition
 	}
@@ -608,6 +626,21 @@ func TestConfigure(t *testing.T) {
 				err: errors.New(errCompositionNotCompatible),
 			},
 		},
+		"CompatibleCompositeTypeVersion": {
+			reason: "Should not return an error if the composition declares compatibility with the composite resource's version",
+			args: args{
+				comp: &v1.Composition{
+					Spec: v1.CompositionSpec{
+						CompositeTypeRef:      v1.TypeReference{APIVersion: "example.org/v1beta1", Kind: "XR"},
+						CompositeTypeVersions: []v1.CompositeTypeVersion{{APIVersion: "example.org/v1"}},
+					},
+				},
+				cp: composite.New(composite.WithGroupVersionKind(schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "XR"})),
+			},
+			want: want{
+				cp: composite.New(composite.WithGroupVersionKind(schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "XR"})),
+			},
+		},
 		"AlreadyFilled": {
 			reason: "Should be no-op if connection secret namespace is already filled",
 			args:   args{cp: cp, comp: &v1.Composition{}},
@@ -641,6 +674,88 @@ func TestConfigure(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{UID: types.UID(cs.Ref.Name)},
 			}},
 		},
+		"DefaultConnectionSecretNamespace": {
+			reason: "Should fill connection secret ref using the default namespace if the composition does not have WriteConnectionSecretsToNamespace",
+			args: args{
+				kube: &test.MockClient{MockUpdate: test.NewMockUpdateFn(nil)},
+				opts: []APIConfiguratorOption{WithDefaultConnectionSecretNamespace(cs.Ref.Namespace)},
+				cp: &fake.Composite{
+					ObjectMeta: metav1.ObjectMeta{UID: types.UID(cs.Ref.Name)},
+				},
+				comp: &v1.Composition{
+					Spec: v1.CompositionSpec{},
+				},
+			},
+			want: want{cp: cp},
+		},
+		"CompositionConnectionSecretNamespaceTakesPrecedence": {
+			reason: "Should fill connection secret ref using the composition's namespace rather than the default namespace",
+			args: args{
+				kube: &test.MockClient{MockUpdate: test.NewMockUpdateFn(nil)},
+				opts: []APIConfiguratorOption{WithDefaultConnectionSecretNamespace("default-secrets")},
+				cp: &fake.Composite{
+					ObjectMeta: metav1.ObjectMeta{UID: types.UID(cs.Ref.Name)},
+				},
+				comp: &v1.Composition{
+					Spec: v1.CompositionSpec{WriteConnectionSecretsToNamespace: &cs.Ref.Namespace},
+				},
+			},
+			want: want{cp: cp},
+		},
+		"ConnectionSecretNamespaceFrom": {
+			reason: "Should fill connection secret ref using a namespace derived from the composite resource",
+			args: args{
+				kube: &test.MockClient{MockUpdate: test.NewMockUpdateFn(nil)},
+				cp:   withRegion("us", nil),
+				comp: &v1.Composition{
+					Spec: v1.CompositionSpec{
+						WriteConnectionSecretsToNamespace: &cs.Ref.Namespace,
+						WriteConnectionSecretsToNamespaceFrom: &v1.Combine{
+							Variables: []v1.CombineVariable{{FromFieldPath: "metadata.labels[region]"}},
+							Strategy:  v1.CombineStrategyString,
+							String:    &v1.StringCombine{Format: "%s-secrets"},
+						},
+					},
+				},
+			},
+			want: want{cp: withRegion("us", &xpv1.SecretReference{Name: cs.Ref.Name, Namespace: "us-secrets"})},
+		},
+		"ConnectionSecretNamespaceFromFieldNotFound": {
+			reason: "Should fall back to the composition's static namespace if a field the namespace is derived from is not found",
+			args: args{
+				kube: &test.MockClient{MockUpdate: test.NewMockUpdateFn(nil)},
+				cp:   withRegion("", nil),
+				comp: &v1.Composition{
+					Spec: v1.CompositionSpec{
+						WriteConnectionSecretsToNamespace: &cs.Ref.Namespace,
+						WriteConnectionSecretsToNamespaceFrom: &v1.Combine{
+							Variables: []v1.CombineVariable{{FromFieldPath: "metadata.labels[region]"}},
+							Strategy:  v1.CombineStrategyString,
+							String:    &v1.StringCombine{Format: "%s-secrets"},
+						},
+					},
+				},
+			},
+			want: want{cp: withRegion("", cs.Ref)},
+		},
+		"ConnectionSecretNamespaceFromInvalid": {
+			reason: "Should fail if a namespace cannot be derived from the composite resource",
+			args: args{
+				cp: withRegion("us", nil),
+				comp: &v1.Composition{
+					Spec: v1.CompositionSpec{
+						WriteConnectionSecretsToNamespaceFrom: &v1.Combine{
+							Variables: []v1.CombineVariable{{FromFieldPath: "metadata.labels[region]"}},
+							Strategy:  v1.CombineStrategy("wat"),
+						},
+					},
+				},
+			},
+			want: want{
+				cp:  withRegion("us", nil),
+				err: errors.Wrap(errors.Errorf("combine strategy %s is not supported", "wat"), errConnectionSecretNamespace),
+			},
+		},
 		"UpdateFailed": {
 			reason: "Should fail if kube update failed",
 			args: args{
@@ -662,7 +777,7 @@ func TestConfigure(t *testing.T) {
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			c := &APIConfigurator{client: tc.args.kube}
+			c := NewAPIConfigurator(tc.args.kube, tc.args.opts...)
 			err := c.Configure(context.Background(), tc.args.cp, tc.args.comp)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nConfigure(...): -want, +got:\n%s", tc.reason, diff)
@@ -791,6 +906,11 @@ func TestSelectorResolver(t *testing.T) {
 }
 
 func TestAPIDefaultCompositionSelector(t *testing.T) {
+	withRegion := func(region string) resource.Composite {
+		cp := composite.New()
+		_ = fieldpath.Pave(cp.Object).SetValue("spec.region", region)
+		return cp
+	}
 	a, k := schema.EmptyObjectKind.GroupVersionKind().ToAPIVersionAndKind()
 	tref := v1.TypeReference{APIVersion: a, Kind: k}
 	comp := &v1.Composition{
@@ -894,6 +1014,58 @@ func TestAPIDefaultCompositionSelector(t *testing.T) {
 				},
 			},
 		},
+		"SelectionRule": {
+			reason: "Should set the composition reference of the first selection rule the composite resource matches, rather than the default",
+			args: args{
+				kube: &test.MockClient{
+					MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+						if cr, ok := obj.(*v1.CompositeResourceDefinition); ok {
+							cr.Spec.DefaultCompositionRef = &xpv1.Reference{Name: comp.Name}
+							cr.Spec.CompositionSelectionRules = []v1.CompositionSelectionRule{
+								{
+									Requirements:   []v1.FieldRequirement{{FieldPath: "spec.region", Operator: v1.FieldSelectorOpIn, Values: []string{"eu-west-1"}}},
+									CompositionRef: xpv1.Reference{Name: "eu"},
+								},
+								{
+									Requirements:   []v1.FieldRequirement{{FieldPath: "spec.region", Operator: v1.FieldSelectorOpMatches, Values: []string{"^us-gov-"}}},
+									CompositionRef: xpv1.Reference{Name: "gov-cloud"},
+								},
+							}
+						}
+						return nil
+					},
+				},
+				cp: withRegion("us-gov-west-1"),
+			},
+			want: want{
+				cp: func() resource.Composite {
+					cp := withRegion("us-gov-west-1")
+					cp.SetCompositionReference(&corev1.ObjectReference{Name: "gov-cloud"})
+					return cp
+				}(),
+			},
+		},
+		"SelectionRuleError": {
+			reason: "Should return an error if a selection rule cannot be evaluated",
+			args: args{
+				kube: &test.MockClient{
+					MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+						if cr, ok := obj.(*v1.CompositeResourceDefinition); ok {
+							cr.Spec.CompositionSelectionRules = []v1.CompositionSelectionRule{{
+								Requirements:   []v1.FieldRequirement{{FieldPath: "spec.region", Operator: v1.FieldSelectorOpMatches}},
+								CompositionRef: xpv1.Reference{Name: "gov-cloud"},
+							}}
+						}
+						return nil
+					},
+				},
+				cp: withRegion("us-gov-west-1"),
+			},
+			want: want{
+				cp:  withRegion("us-gov-west-1"),
+				err: errors.Wrapf(errors.Errorf(errFmtOperatorOneValue, v1.FieldSelectorOpMatches), errFmtSelectionRule, 0),
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -1055,3 +1227,75 @@ func TestAPINamingConfigurator(t *testing.T) {
 		})
 	}
 }
+
+func TestMeetsRequirements(t *testing.T) {
+	cp := composite.New()
+	_ = fieldpath.Pave(cp.Object).SetValue("spec", map[string]interface{}{
+		"region":   "us-gov-west-1",
+		"replicas": int64(3),
+		"tags":     []interface{}{"a"},
+	})
+	_, errRegexp := regexp.Compile("(")
+
+	type want struct {
+		ok  bool
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		rs     []v1.FieldRequirement
+		want   want
+	}{
+		"In": {
+			reason: "A field whose value is one of the values meets an In requirement.",
+			rs:     []v1.FieldRequirement{{FieldPath: "spec.region", Operator: v1.FieldSelectorOpIn, Values: []string{"eu-west-1", "us-gov-west-1"}}},
+			want:   want{ok: true},
+		},
+		"InNumber": {
+			reason: "Scalar values that aren't strings are compared as strings.",
+			rs:     []v1.FieldRequirement{{FieldPath: "spec.replicas", Operator: v1.FieldSelectorOpIn, Values: []string{"3"}}},
+			want:   want{ok: true},
+		},
+		"InArray": {
+			reason: "A field whose value is an array never meets an In requirement.",
+			rs:     []v1.FieldRequirement{{FieldPath: "spec.tags", Operator: v1.FieldSelectorOpIn, Values: []string{"a"}}},
+			want:   want{ok: false},
+		},
+		"NotInMissing": {
+			reason: "A field that doesn't exist meets a NotIn requirement.",
+			rs:     []v1.FieldRequirement{{FieldPath: "spec.zone", Operator: v1.FieldSelectorOpNotIn, Values: []string{"a"}}},
+			want:   want{ok: true},
+		},
+		"ExistsAndMatches": {
+			reason: "A composite resource must meet every requirement.",
+			rs: []v1.FieldRequirement{
+				{FieldPath: "spec.region", Operator: v1.FieldSelectorOpExists},
+				{FieldPath: "spec.region", Operator: v1.FieldSelectorOpMatches, Values: []string{"^eu-"}},
+			},
+			want: want{ok: false},
+		},
+		"DoesNotExist": {
+			reason: "A field that doesn't exist meets a DoesNotExist requirement.",
+			rs:     []v1.FieldRequirement{{FieldPath: "spec.zone", Operator: v1.FieldSelectorOpDoesNotExist}},
+			want:   want{ok: true},
+		},
+		"InvalidRegexp": {
+			reason: "We should return an error if a Matches requirement's value isn't a regular expression.",
+			rs:     []v1.FieldRequirement{{FieldPath: "spec.region", Operator: v1.FieldSelectorOpMatches, Values: []string{"("}}},
+			want:   want{err: errRegexp},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ok, err := meetsRequirements(cp, tc.rs)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nmeetsRequirements(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ok, ok); diff != "" {
+				t.Errorf("\n%s\nmeetsRequirements(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+// MaxBaseDepth is the maximum number of bases a Composition may inherit from,
+// including the bases of its base.
+const MaxBaseDepth = 5
+
+// Error strings.
+const (
+	errFmtGetBase     = "cannot get base Composition %q"
+	errFmtInheritBase = "cannot inherit from base Composition %q"
+	errFmtBaseCycle   = "base Composition %q is already inherited from"
+	errFmtBaseTooDeep = "compositions may inherit from at most %d bases"
+	errResolveBases   = "cannot resolve base Compositions"
+	errListBaseUsers  = "cannot list Compositions that may inherit from a base"
+)
+
+// ResolveBases returns a copy of the supplied Composition with the spec of any
+// base Composition it references, and of any base that base references, merged
+// into its spec. The supplied Composition is returned if it has no base.
+func ResolveBases(ctx context.Context, c client.Reader, comp *v1.Composition) (*v1.Composition, error) {
+	if comp.Spec.BaseCompositionRef == nil {
+		return comp, nil
+	}
+
+	// We fetch the whole chain of bases, then merge it from the top down so
+	// that each Composition inherits from a base that is already resolved.
+	chain := []*v1.Composition{comp}
+	seen := map[string]bool{comp.GetName(): true}
+	for ref := comp.Spec.BaseCompositionRef; ref != nil; ref = chain[len(chain)-1].Spec.BaseCompositionRef {
+		if seen[ref.Name] {
+			return nil, errors.Errorf(errFmtBaseCycle, ref.Name)
+		}
+		if len(chain)-1 >= MaxBaseDepth {
+			return nil, errors.Errorf(errFmtBaseTooDeep, MaxBaseDepth)
+		}
+		seen[ref.Name] = true
+
+		base := &v1.Composition{}
+		if err := c.Get(ctx, types.NamespacedName{Name: ref.Name}, base); err != nil {
+			return nil, errors.Wrapf(err, errFmtGetBase, ref.Name)
+		}
+		chain = append(chain, base)
+	}
+
+	spec := &chain[len(chain)-1].Spec
+	for i := len(chain) - 2; i >= 0; i-- {
+		s, err := chain[i].Spec.Inherit(spec)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtInheritBase, chain[i].Spec.BaseCompositionRef.Name)
+		}
+		spec = s
+	}
+
+	out := comp.DeepCopy()
+	out.Spec = *spec
+	return out, nil
+}
+
+// InheritingCompositions returns the names of the Compositions that inherit
+// from the named base Composition, either directly or because their base
+// inherits from it.
+func InheritingCompositions(ctx context.Context, c client.Reader, base string) ([]string, error) {
+	l := &v1.CompositionList{}
+	if err := c.List(ctx, l); err != nil {
+		return nil, errors.Wrap(err, errListBaseUsers)
+	}
+
+	children := map[string][]string{}
+	for _, comp := range l.Items {
+		if ref := comp.Spec.BaseCompositionRef; ref != nil {
+			children[ref.Name] = append(children[ref.Name], comp.GetName())
+		}
+	}
+
+	// Bases may not form a cycle, but we don't trust that they don't.
+	names := make([]string, 0)
+	seen := map[string]bool{base: true}
+	for queue := children[base]; len(queue) > 0; queue = queue[1:] {
+		n := queue[0]
+		if seen[n] {
+			continue
+		}
+		seen[n] = true
+		names = append(names, n)
+		queue = append(queue, children[n]...)
+	}
+	return names, nil
+}
+
+// An InheritingCompositionFetcher fetches a Composition using another
+// CompositionFetcher, then resolves any base Compositions it inherits from.
+// CompositionRevisions record the resolved spec of their Composition, so only
+// revisions created before bases were resolved into them reference a base.
+type InheritingCompositionFetcher struct {
+	wrapped CompositionFetcher
+	reader  client.Reader
+}
+
+// NewInheritingCompositionFetcher returns a CompositionFetcher that resolves
+// the base Compositions of the Compositions fetched by the supplied fetcher.
+func NewInheritingCompositionFetcher(f CompositionFetcher, r client.Reader) *InheritingCompositionFetcher {
+	return &InheritingCompositionFetcher{wrapped: f, reader: r}
+}
+
+// Fetch the Composition for the supplied composite resource, with any bases
+// it inherits from resolved.
+func (f *InheritingCompositionFetcher) Fetch(ctx context.Context, cr resource.Composite) (*v1.Composition, error) {
+	comp, err := f.wrapped.Fetch(ctx, cr)
+	if err != nil {
+		return nil, err
+	}
+	comp, err = ResolveBases(ctx, f.reader, comp)
+	return comp, errors.Wrap(err, errResolveBases)
+}
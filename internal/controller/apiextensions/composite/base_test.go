@@ -0,0 +1,241 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestResolveBases(t *testing.T) {
+	ref := v1.TypeReference{APIVersion: "example.org/v1", Kind: "XR"}
+
+	// comp returns a Composition with the supplied name and base, that
+	// composes resources with the supplied names.
+	comp := func(name, base string, resources ...string) *v1.Composition {
+		c := &v1.Composition{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       v1.CompositionSpec{CompositeTypeRef: ref},
+		}
+		if base != "" {
+			c.Spec.BaseCompositionRef = &xpv1.Reference{Name: base}
+		}
+		for _, r := range resources {
+			c.Spec.Resources = append(c.Spec.Resources, v1.ComposedTemplate{Name: pointer.StringPtr(r)})
+		}
+		return c
+	}
+
+	// getFrom returns a MockGetFn that gets the supplied Compositions.
+	getFrom := func(comps ...*v1.Composition) test.MockGetFn {
+		return func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			for _, c := range comps {
+				if c.GetName() == key.Name {
+					c.DeepCopyInto(obj.(*v1.Composition))
+					return nil
+				}
+			}
+			return errBoom
+		}
+	}
+
+	type args struct {
+		c    client.Reader
+		comp *v1.Composition
+	}
+	type want struct {
+		comp *v1.Composition
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoBase": {
+			reason: "A Composition without a base should be returned unchanged.",
+			args: args{
+				comp: comp("child", "", "a"),
+			},
+			want: want{
+				comp: comp("child", "", "a"),
+			},
+		},
+		"GetBaseError": {
+			reason: "We should return any error encountered getting a base.",
+			args: args{
+				c:    &test.MockClient{MockGet: getFrom()},
+				comp: comp("child", "base", "a"),
+			},
+			want: want{
+				err: errors.Wrapf(errBoom, errFmtGetBase, "base"),
+			},
+		},
+		"Cycle": {
+			reason: "We should return an error if a Composition inherits from itself.",
+			args: args{
+				c:    &test.MockClient{MockGet: getFrom(comp("base", "child"))},
+				comp: comp("child", "base", "a"),
+			},
+			want: want{
+				err: errors.Errorf(errFmtBaseCycle, "child"),
+			},
+		},
+		"TooDeep": {
+			reason: "We should return an error if a Composition inherits from too many bases.",
+			args: args{
+				c: &test.MockClient{MockGet: getFrom(
+					comp("b1", "b2"), comp("b2", "b3"), comp("b3", "b4"), comp("b4", "b5"), comp("b5", "b6"), comp("b6", ""),
+				)},
+				comp: comp("child", "b1"),
+			},
+			want: want{
+				err: errors.Errorf(errFmtBaseTooDeep, MaxBaseDepth),
+			},
+		},
+		"InheritError": {
+			reason: "We should return an error if a Composition cannot inherit from its base.",
+			args: args{
+				c:    &test.MockClient{MockGet: getFrom(comp("base", "", "a"))},
+				comp: &v1.Composition{ObjectMeta: metav1.ObjectMeta{Name: "child"}, Spec: v1.CompositionSpec{BaseCompositionRef: &xpv1.Reference{Name: "base"}}},
+			},
+			want: want{
+				err: errors.Wrapf(errors.Errorf("composition is compatible with %s %s, but its base is compatible with %s %s", "", "", ref.APIVersion, ref.Kind), errFmtInheritBase, "base"),
+			},
+		},
+		"InheritChain": {
+			reason: "A Composition should inherit the resources of its base, and of its base's base.",
+			args: args{
+				c:    &test.MockClient{MockGet: getFrom(comp("base", "root", "b"), comp("root", "", "a"))},
+				comp: comp("child", "base", "c"),
+			},
+			want: want{
+				comp: comp("child", "", "a", "b", "c"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ResolveBases(context.Background(), tc.args.c, tc.args.comp)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolveBases(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.comp, got); diff != "" {
+				t.Errorf("\n%s\nResolveBases(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestInheritingCompositions(t *testing.T) {
+	comp := func(name, base string) v1.Composition {
+		c := v1.Composition{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if base != "" {
+			c.Spec.BaseCompositionRef = &xpv1.Reference{Name: base}
+		}
+		return c
+	}
+
+	// listOf returns a MockListFn that lists the supplied Compositions.
+	listOf := func(comps ...v1.Composition) test.MockListFn {
+		return test.NewMockListFn(nil, func(obj client.ObjectList) error {
+			obj.(*v1.CompositionList).Items = comps
+			return nil
+		})
+	}
+
+	type args struct {
+		c    client.Reader
+		base string
+	}
+	type want struct {
+		names []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ListError": {
+			reason: "We should return any error encountered listing Compositions.",
+			args: args{
+				c:    &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+				base: "base",
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errListBaseUsers),
+			},
+		},
+		"NoneInherit": {
+			reason: "We should return no names if no Composition inherits from the base.",
+			args: args{
+				c:    &test.MockClient{MockList: listOf(comp("base", ""), comp("other", ""))},
+				base: "base",
+			},
+			want: want{
+				names: []string{},
+			},
+		},
+		"Transitive": {
+			reason: "We should return Compositions that inherit from the base directly or via another base.",
+			args: args{
+				c:    &test.MockClient{MockList: listOf(comp("root", ""), comp("base", "root"), comp("child", "base"), comp("other", ""))},
+				base: "root",
+			},
+			want: want{
+				names: []string{"base", "child"},
+			},
+		},
+		"Cycle": {
+			reason: "We should not loop forever if Compositions inherit from each other.",
+			args: args{
+				c:    &test.MockClient{MockList: listOf(comp("a", "b"), comp("b", "a"))},
+				base: "a",
+			},
+			want: want{
+				names: []string{"b"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := InheritingCompositions(context.Background(), tc.args.c, tc.args.base)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nInheritingCompositions(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.names, got); diff != "" {
+				t.Errorf("\n%s\nInheritingCompositions(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -19,13 +19,16 @@ package composite
 import (
 	"context"
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -42,23 +45,27 @@ import (
 
 // Error strings
 const (
-	errMixed       = "cannot mix named and anonymous resource templates"
-	errDuplicate   = "resource template names must be unique within their Composition"
-	errGetComposed = "cannot get composed resource"
-	errGCComposed  = "cannot garbage collect composed resource"
-	errApply       = "cannot apply composed resource"
-	errFetchSecret = "cannot fetch connection secret"
-	errReadiness   = "cannot check whether composed resource is ready"
-	errUnmarshal   = "cannot unmarshal base template"
-	errGetSecret   = "cannot get connection secret of composed resource"
-	errNamePrefix  = "name prefix is not found in labels"
-	errKindChanged = "cannot change the kind of an existing composed resource"
-	errName        = "cannot use dry-run create to name composed resource"
-
-	errFmtPatch          = "cannot apply the patch at index %d"
-	errFmtConnDetailKey  = "connection detail of type %q key is not set"
-	errFmtConnDetailVal  = "connection detail of type %q value is not set"
-	errFmtConnDetailPath = "connection detail of type %q fromFieldPath is not set"
+	errMixed        = "cannot mix named and anonymous resource templates"
+	errDuplicate    = "resource template names must be unique within their Composition"
+	errGetComposed  = "cannot get composed resource"
+	errGCComposed   = "cannot garbage collect composed resource"
+	errApply        = "cannot apply composed resource"
+	errFetchSecret  = "cannot fetch connection secret"
+	errReadiness    = "cannot check whether composed resource is ready"
+	errUnmarshal    = "cannot unmarshal base template"
+	errGetSecret    = "cannot get connection secret of composed resource"
+	errNamePrefix   = "name prefix is not found in labels"
+	errKindChanged  = "cannot change the kind of an existing composed resource"
+	errName         = "cannot use dry-run create to name composed resource"
+	errGetObserved  = "cannot get observed resource"
+	errListObserved = "cannot list observed resources"
+
+	errFmtPatch              = "cannot apply the patch at index %d"
+	errFmtConnDetailKey      = "connection detail of type %q key is not set"
+	errFmtConnDetailVal      = "connection detail of type %q value is not set"
+	errFmtConnDetailPath     = "connection detail of type %q fromFieldPath is not set"
+	errFmtConnDetailFromPath = "cannot get connection detail from field path %q"
+	errFmtObservedMatches    = "observe-only resource template must match exactly one resource, but matches %d"
 )
 
 // Annotation keys.
@@ -343,10 +350,21 @@ func (r *APIDryRunRenderer) Render(ctx context.Context, cp resource.Composite, c
 	cd.SetName(name)
 	cd.SetNamespace(namespace)
 
+	debug := debuggingPatches(cp)
+	applied := make([]AppliedPatch, 0)
 	for i := range t.Patches {
 		if err := t.Patches[i].Apply(cp, cd, patchTypesFromXR()...); err != nil {
+			if debug {
+				setAppliedPatches(cd, append(applied, describeFailedPatch(i, t.Patches[i], err)))
+			}
 			return errors.Wrapf(err, errFmtPatch, i)
 		}
+		if !debug {
+			continue
+		}
+		if ap, ok := describePatch(i, t.Patches[i], cp, cd); ok {
+			applied = append(applied, ap)
+		}
 	}
 
 	// Composed labels and annotations should be rendered after patches are applied
@@ -360,6 +378,10 @@ func (r *APIDryRunRenderer) Render(ctx context.Context, cp resource.Composite, c
 		SetCompositionResourceName(cd, *t.Name)
 	}
 
+	if debug {
+		setAppliedPatches(cd, applied)
+	}
+
 	// We do this last to ensure that a Composition cannot influence owner (and
 	// especially controller) references.
 	or := meta.AsController(meta.TypedReferenceTo(cp, cp.GetObjectKind().GroupVersionKind()))
@@ -383,6 +405,49 @@ func (r *APIDryRunRenderer) Render(ctx context.Context, cp resource.Composite, c
 	return errors.Wrap(r.client.Create(ctx, cd, client.DryRunAll), errName)
 }
 
+// An APIObserver observes the existing resources of observe-only resource
+// templates by reading them from an API server.
+type APIObserver struct {
+	client client.Reader
+}
+
+// NewAPIObserver returns an Observer that reads the existing resources of
+// observe-only resource templates from an API server.
+func NewAPIObserver(c client.Reader) *APIObserver {
+	return &APIObserver{client: c}
+}
+
+// Observe the existing resource identified by the supplied observe-only
+// template by reading it into the supplied composed resource. Only the
+// apiVersion and kind of the template's base are used.
+func (o *APIObserver) Observe(ctx context.Context, _ resource.Composite, cd resource.Composed, t v1.ComposedTemplate) error {
+	tm := &metav1.TypeMeta{}
+	if err := json.Unmarshal(t.Base.Raw, tm); err != nil {
+		return errors.Wrap(err, errUnmarshal)
+	}
+	cd.GetObjectKind().SetGroupVersionKind(tm.GroupVersionKind())
+
+	oo := t.ObserveOnly
+	nn := types.NamespacedName{Namespace: pointer.StringDeref(oo.Namespace, ""), Name: pointer.StringDeref(oo.Name, "")}
+
+	// An existing resource that isn't identified by name must be the only
+	// resource that matches the template's labels.
+	if oo.Name == nil {
+		l := &kunstructured.UnstructuredList{}
+		l.SetAPIVersion(tm.APIVersion)
+		l.SetKind(tm.Kind + "List")
+		if err := o.client.List(ctx, l, client.InNamespace(nn.Namespace), client.MatchingLabels(oo.MatchLabels)); err != nil {
+			return errors.Wrap(err, errListObserved)
+		}
+		if len(l.Items) != 1 {
+			return errors.Errorf(errFmtObservedMatches, len(l.Items))
+		}
+		nn = types.NamespacedName{Namespace: l.Items[0].GetNamespace(), Name: l.Items[0].GetName()}
+	}
+
+	return errors.Wrap(o.client.Get(ctx, nn, cd), errGetObserved)
+}
+
 // RenderComposite renders the supplied composite resource using the supplied composed
 // resource and template.
 func RenderComposite(_ context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) error {
@@ -460,7 +525,13 @@ func (cdf *APIConnectionDetailsFetcher) FetchConnectionDetails(ctx context.Conte
 			case d.FromFieldPath == nil:
 				return nil, errors.Errorf(errFmtConnDetailPath, tp)
 			default:
-				_ = extractFieldPathValue(cd, d, conn)
+				// It's possible the field path will be populated at some
+				// point in the future, so we don't consider a missing field
+				// an error. Anything else (e.g. an invalid field path) is
+				// a problem with the Composition that should be surfaced.
+				if err := extractFieldPathValue(cd, d, conn); err != nil && !fieldpath.IsNotFound(err) {
+					return nil, errors.Wrapf(err, errFmtConnDetailFromPath, *d.FromFieldPath)
+				}
 			}
 		case v1.ConnectionDetailTypeUnknown:
 			// We weren't able to determine the type of this connection detail.
@@ -566,3 +637,21 @@ func IsReady(_ context.Context, cd resource.Composed, t v1.ComposedTemplate) (bo
 	}
 	return true, nil
 }
+
+// PropagateExternalName returns the external name of the supplied composite
+// resource, so that it's propagated to the supplied composed resource.
+func PropagateExternalName(_ context.Context, cp resource.Composite, _ resource.Composed, _ v1.ComposedTemplate) (string, error) {
+	return meta.GetExternalName(cp), nil
+}
+
+// readinessTimedOut returns true if the supplied composed resource has been
+// unready for longer than the supplied timeout. We measure from the last time
+// its Ready condition transitioned, or from when it was created if it has no
+// Ready condition.
+func readinessTimedOut(cd resource.Composed, timeout time.Duration, now time.Time) bool {
+	since := cd.GetCondition(xpv1.TypeReady).LastTransitionTime.Time
+	if since.IsZero() {
+		since = cd.GetCreationTimestamp().Time
+	}
+	return !since.IsZero() && now.Sub(since) > timeout
+}
@@ -19,11 +19,13 @@ package composite
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -33,6 +35,7 @@ import (
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
@@ -170,6 +173,11 @@ func TestRender(t *testing.T) {
 	ctrl := true
 	tmpl, _ := json.Marshal(&fake.Managed{})
 
+	// A patch without a field path to patch from fails.
+	broken := v1.Patch{Type: v1.PatchTypeFromCompositeFieldPath}
+	errBroken := broken.Apply(&fake.Composite{}, &fake.Composed{})
+	failed, _ := json.Marshal([]AppliedPatch{{Type: v1.PatchTypeFromCompositeFieldPath, Error: errBroken.Error()}})
+
 	type args struct {
 		ctx context.Context
 		cp  resource.Composite
@@ -234,6 +242,28 @@ func TestRender(t *testing.T) {
 				err: errors.Wrap(errBoom, errName),
 			},
 		},
+		"PatchErrorDebugged": {
+			reason: "The error a patch failed with should be recorded if the composite resource debugs patches",
+			args: args{
+				cp: &fake.Composite{ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{xcrd.LabelKeyNamePrefixForComposed: "ola"},
+					Annotations: map[string]string{AnnotationKeyDebugPatches: "true"},
+				}},
+				cd: &fake.Composed{ObjectMeta: metav1.ObjectMeta{Name: "cd"}},
+				t: v1.ComposedTemplate{
+					Base:    runtime.RawExtension{Raw: tmpl},
+					Patches: []v1.Patch{broken},
+				},
+			},
+			want: want{
+				cd: &fake.Composed{ObjectMeta: metav1.ObjectMeta{
+					Name:         "cd",
+					GenerateName: "ola-",
+					Annotations:  map[string]string{AnnotationKeyAppliedPatches: string(failed)},
+				}},
+				err: errors.Wrapf(errBroken, errFmtPatch, 0),
+			},
+		},
 		"Success": {
 			reason: "Configuration should result in the right object with correct generateName",
 			client: &test.MockClient{MockCreate: test.NewMockCreateFn(nil)},
@@ -274,6 +304,115 @@ func TestRender(t *testing.T) {
 	}
 }
 
+func TestAPIObserverObserve(t *testing.T) {
+	base := runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"Network","spec":{"ignored":true}}`)}
+	gvk := schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Network"}
+
+	// Our mock Get returns a resource with the requested name and namespace.
+	get := func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+		obj.SetNamespace(key.Namespace)
+		obj.SetName(key.Name)
+		obj.SetLabels(map[string]string{"shared": "true"})
+		return nil
+	}
+	observed := func(namespace, name string) resource.Composed {
+		cd := composed.New(composed.FromReference(corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "Network", Namespace: namespace, Name: name}))
+		cd.SetLabels(map[string]string{"shared": "true"})
+		return cd
+	}
+	list := func(items ...kunstructured.Unstructured) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			obj.(*kunstructured.UnstructuredList).Items = items
+			return nil
+		}
+	}
+	item := func(namespace, name string) kunstructured.Unstructured {
+		u := kunstructured.Unstructured{}
+		u.SetNamespace(namespace)
+		u.SetName(name)
+		return u
+	}
+
+	type want struct {
+		cd  resource.Composed
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		client client.Reader
+		t      v1.ComposedTemplate
+		want   want
+	}{
+		"InvalidTemplate": {
+			reason: "We should return an error if we can't determine the kind of the existing resource.",
+			t:      v1.ComposedTemplate{Base: runtime.RawExtension{Raw: []byte("olala")}, ObserveOnly: &v1.ObserveOnly{Name: pointer.String("shared")}},
+			want: want{
+				cd:  composed.New(),
+				err: errors.Wrap(errors.New("invalid character 'o' looking for beginning of value"), errUnmarshal),
+			},
+		},
+		"GetError": {
+			reason: "We should return any error encountered getting the existing resource.",
+			client: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			t:      v1.ComposedTemplate{Base: base, ObserveOnly: &v1.ObserveOnly{Name: pointer.String("shared")}},
+			want: want{
+				cd:  composed.New(composed.FromReference(corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "Network"})),
+				err: errors.Wrap(errBoom, errGetObserved),
+			},
+		},
+		"ByName": {
+			reason: "We should read the existing resource identified by name.",
+			client: &test.MockClient{MockGet: get},
+			t:      v1.ComposedTemplate{Base: base, ObserveOnly: &v1.ObserveOnly{Name: pointer.String("shared"), Namespace: pointer.String("default")}},
+			want: want{
+				cd: observed("default", "shared"),
+			},
+		},
+		"ListError": {
+			reason: "We should return any error encountered listing existing resources.",
+			client: &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+			t:      v1.ComposedTemplate{Base: base, ObserveOnly: &v1.ObserveOnly{MatchLabels: map[string]string{"shared": "true"}}},
+			want: want{
+				cd:  composed.New(composed.FromReference(corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "Network"})),
+				err: errors.Wrap(errBoom, errListObserved),
+			},
+		},
+		"TooManyMatches": {
+			reason: "We should return an error if more than one existing resource matches the template's labels.",
+			client: &test.MockClient{MockList: list(item("", "a"), item("", "b"))},
+			t:      v1.ComposedTemplate{Base: base, ObserveOnly: &v1.ObserveOnly{MatchLabels: map[string]string{"shared": "true"}}},
+			want: want{
+				cd:  composed.New(composed.FromReference(corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "Network"})),
+				err: errors.Errorf(errFmtObservedMatches, 2),
+			},
+		},
+		"ByLabels": {
+			reason: "We should read the only existing resource that matches the template's labels.",
+			client: &test.MockClient{MockList: list(item("default", "shared")), MockGet: get},
+			t:      v1.ComposedTemplate{Base: base, ObserveOnly: &v1.ObserveOnly{MatchLabels: map[string]string{"shared": "true"}}},
+			want: want{
+				cd: observed("default", "shared"),
+			},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o := NewAPIObserver(tc.client)
+			cd := composed.New()
+			err := o.Observe(context.Background(), &fake.Composite{}, cd, tc.t)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cd, cd); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if tc.want.err == nil && cd.GetObjectKind().GroupVersionKind() != gvk {
+				t.Errorf("\n%s\nObserve(...): want kind %s, got %s", tc.reason, gvk, cd.GetObjectKind().GroupVersionKind())
+			}
+		})
+	}
+}
+
 func TestAssociateByOrder(t *testing.T) {
 	t0 := v1.ComposedTemplate{Base: runtime.RawExtension{Raw: []byte("zero")}}
 	t1 := v1.ComposedTemplate{Base: runtime.RawExtension{Raw: []byte("one")}}
@@ -827,6 +966,43 @@ func TestFetch(t *testing.T) {
 				},
 			},
 		},
+		"FieldPathNotFound": {
+			reason: "Should not publish or error on a field path that does not exist yet",
+			args: args{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(nil)},
+				cd:   &fake.Composed{},
+				t: v1.ComposedTemplate{ConnectionDetails: []v1.ConnectionDetail{
+					{
+						Name:          pointer.StringPtr("endpoint"),
+						FromFieldPath: pointer.StringPtr("status.atProvider.endpoint"),
+						Type:          &fromField,
+					},
+				}},
+			},
+			want: want{
+				conn: nil,
+			},
+		},
+		"ErrFieldPathInvalid": {
+			reason: "Should return an error if the field path is invalid",
+			args: args{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(nil)},
+				cd:   &fake.Composed{},
+				t: v1.ComposedTemplate{ConnectionDetails: []v1.ConnectionDetail{
+					{
+						Name:          pointer.StringPtr("endpoint"),
+						FromFieldPath: pointer.StringPtr("status[endpoint"),
+						Type:          &fromField,
+					},
+				}},
+			},
+			want: want{
+				err: errors.Wrapf(func() error {
+					_, err := fieldpath.Pave(map[string]interface{}{}).GetValue("status[endpoint")
+					return err
+				}(), errFmtConnDetailFromPath, "status[endpoint"),
+			},
+		},
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
@@ -1059,3 +1235,67 @@ func TestIsReady(t *testing.T) {
 		})
 	}
 }
+
+func TestReadinessTimedOut(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	timeout := 10 * time.Minute
+
+	unready := func(since time.Time) xpv1.Condition {
+		c := xpv1.Creating()
+		c.LastTransitionTime = metav1.NewTime(since)
+		return c
+	}
+
+	cases := map[string]struct {
+		reason string
+		cd     *composed.Unstructured
+		want   bool
+	}{
+		"NotCreated": {
+			reason: "A resource with no creation timestamp and no Ready condition should never time out.",
+			cd:     composed.New(),
+			want:   false,
+		},
+		"CreatedRecently": {
+			reason: "A resource with no Ready condition that was created within the timeout should not time out.",
+			cd: func() *composed.Unstructured {
+				cd := composed.New()
+				cd.SetCreationTimestamp(metav1.NewTime(now.Add(-time.Minute)))
+				return cd
+			}(),
+			want: false,
+		},
+		"CreatedLongAgo": {
+			reason: "A resource with no Ready condition that was created before the timeout should time out.",
+			cd: func() *composed.Unstructured {
+				cd := composed.New()
+				cd.SetCreationTimestamp(metav1.NewTime(now.Add(-time.Hour)))
+				return cd
+			}(),
+			want: true,
+		},
+		"TransitionedRecently": {
+			reason: "A resource whose Ready condition transitioned within the timeout should not time out, regardless of when it was created.",
+			cd: func() *composed.Unstructured {
+				cd := composed.New(composed.WithConditions(unready(now.Add(-time.Minute))))
+				cd.SetCreationTimestamp(metav1.NewTime(now.Add(-time.Hour)))
+				return cd
+			}(),
+			want: false,
+		},
+		"TransitionedLongAgo": {
+			reason: "A resource whose Ready condition transitioned before the timeout should time out.",
+			cd:     composed.New(composed.WithConditions(unready(now.Add(-time.Hour)))),
+			want:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := readinessTimedOut(tc.cd, timeout, now)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nreadinessTimedOut(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
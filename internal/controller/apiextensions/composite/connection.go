@@ -174,7 +174,7 @@ type SecretStoreConnectionDetailsConfigurator struct {
 // by copying them from its composition.
 func (c *SecretStoreConnectionDetailsConfigurator) Configure(ctx context.Context, cp resource.Composite, comp *v1.Composition) error {
 	apiVersion, kind := cp.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
-	if comp.Spec.CompositeTypeRef.APIVersion != apiVersion || comp.Spec.CompositeTypeRef.Kind != kind {
+	if !comp.Spec.CompatibleWith(apiVersion, kind) {
 		return errors.New(errCompositionNotCompatible)
 	}
 
@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+// Annotation keys used to debug patches.
+const (
+	// AnnotationKeyDebugPatches may be set to "true" on a composite resource
+	// to record which patches were applied to each of its composed resources.
+	AnnotationKeyDebugPatches = "apiextensions.crossplane.io/debug-patches"
+
+	// AnnotationKeyAppliedPatches is set on each resource composed by a
+	// composite resource that debugs patches. Its value is a JSON array of
+	// AppliedPatch.
+	AnnotationKeyAppliedPatches = "apiextensions.crossplane.io/applied-patches"
+)
+
+const (
+	// maxAppliedPatchValueBytes is the largest JSON encoded value that is
+	// recorded for an applied patch. Larger values are summarized.
+	maxAppliedPatchValueBytes = 256
+
+	// maxAppliedPatchesBytes is the most applied patches that are recorded
+	// for a composed resource, in JSON encoded bytes. An annotation may be at
+	// most 256KiB, shared with every other annotation of the resource.
+	maxAppliedPatchesBytes = 16 * 1024
+)
+
+// An AppliedPatch describes what a patch did when a composed resource was last
+// rendered.
+type AppliedPatch struct {
+	// Index of the patch in its resource template.
+	Index int `json:"index"`
+
+	// Type of the patch.
+	Type v1.PatchType `json:"type"`
+
+	// FromFieldPaths the patch read from the composite resource.
+	FromFieldPaths []string `json:"fromFieldPaths,omitempty"`
+
+	// ToFieldPath the patch wrote to the composed resource.
+	ToFieldPath string `json:"toFieldPath,omitempty"`
+
+	// Applied is false if the patch was skipped because a field it reads
+	// from didn't exist, or because it failed.
+	Applied bool `json:"applied"`
+
+	// Value of the composed resource's ToFieldPath after the patch was
+	// applied.
+	Value interface{} `json:"value,omitempty"`
+
+	// Error the patch failed with, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// debuggingPatches returns true if the supplied composite resource asks to
+// record the patches applied to its composed resources.
+func debuggingPatches(cp metav1.Object) bool {
+	return cp.GetAnnotations()[AnnotationKeyDebugPatches] == "true"
+}
+
+// describePatch describes what the supplied patch did when it was applied from
+// the supplied composite resource to the supplied composed resource. It returns
+// false if the patch is not of a type that is applied when rendering a composed
+// resource.
+func describePatch(i int, p v1.Patch, cp, cd runtime.Object) (AppliedPatch, bool) {
+	ap := newAppliedPatch(i, p)
+	if p.Type != v1.PatchTypeFromCompositeFieldPath && p.Type != v1.PatchTypeCombineFromComposite {
+		return ap, false
+	}
+
+	if from, err := fieldpath.PaveObject(cp); err == nil {
+		for _, fp := range ap.FromFieldPaths {
+			if _, err := from.GetValue(fp); err != nil {
+				ap.Applied = false
+			}
+		}
+	}
+
+	if !ap.Applied || ap.ToFieldPath == "" {
+		return ap, true
+	}
+	to, err := fieldpath.PaveObject(cd)
+	if err != nil {
+		return ap, true
+	}
+	v, err := to.GetValue(ap.ToFieldPath)
+	if err != nil {
+		return ap, true
+	}
+	ap.Value = v
+	if j, err := json.Marshal(v); err != nil || len(j) > maxAppliedPatchValueBytes {
+		ap.Value = fmt.Sprintf("(%d bytes omitted)", len(j))
+	}
+	return ap, true
+}
+
+// describeFailedPatch describes the supplied patch, which failed with the
+// supplied error.
+func describeFailedPatch(i int, p v1.Patch, err error) AppliedPatch {
+	ap := newAppliedPatch(i, p)
+	ap.Applied = false
+	ap.Error = err.Error()
+	return ap
+}
+
+// newAppliedPatch describes the field paths of the supplied patch.
+func newAppliedPatch(i int, p v1.Patch) AppliedPatch {
+	ap := AppliedPatch{Index: i, Type: p.Type, Applied: true}
+	if p.ToFieldPath != nil {
+		ap.ToFieldPath = *p.ToFieldPath
+	}
+
+	switch p.Type {
+	case v1.PatchTypeFromCompositeFieldPath:
+		if p.FromFieldPath != nil {
+			ap.FromFieldPaths = []string{*p.FromFieldPath}
+		}
+	case v1.PatchTypeCombineFromComposite:
+		if p.Combine != nil {
+			for _, v := range p.Combine.Variables {
+				ap.FromFieldPaths = append(ap.FromFieldPaths, v.FromFieldPath)
+			}
+		}
+	}
+	return ap
+}
+
+// setAppliedPatches records the supplied applied patches on the supplied
+// composed resource. Patches that would exceed maxAppliedPatchesBytes are not
+// recorded.
+func setAppliedPatches(cd metav1.Object, aps []AppliedPatch) {
+	size := 2 // The surrounding brackets.
+	for i := range aps {
+		j, _ := json.Marshal(aps[i])
+		if size += len(j) + 1; size > maxAppliedPatchesBytes {
+			aps = aps[:i]
+			break
+		}
+	}
+	j, _ := json.Marshal(aps)
+	meta.AddAnnotations(cd, map[string]string{AnnotationKeyAppliedPatches: string(j)})
+}
+
+// recordAppliedPatches records the applied patches of the supplied composed
+// resource, which failed to render, on the existing composed resource. A
+// composed resource that fails to render isn't applied, so this is the only
+// way to record which of its patches failed. It does nothing if no patches
+// were recorded, or if the composed resource doesn't exist yet.
+func recordAppliedPatches(ctx context.Context, c client.Client, cd resource.Composed) error {
+	v, ok := cd.GetAnnotations()[AnnotationKeyAppliedPatches]
+	if !ok || cd.GetName() == "" {
+		return nil
+	}
+	p, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{AnnotationKeyAppliedPatches: v},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	existing := composed.New(composed.FromReference(*meta.ReferenceTo(cd, cd.GetObjectKind().GroupVersionKind())))
+	return resource.IgnoreNotFound(c.Patch(ctx, existing, client.RawPatch(types.MergePatchType, p)))
+}
+
+// pruneAppliedPatches returns an ApplyOption that removes the applied patches
+// annotation from the current object if the desired object doesn't have it,
+// i.e. if its composite resource no longer debugs patches. It is only called
+// if the object exists.
+func pruneAppliedPatches() resource.ApplyOption {
+	return func(_ context.Context, current, desired runtime.Object) error {
+		c, ok := current.(metav1.Object)
+		if !ok {
+			return nil
+		}
+		d, ok := desired.(interface {
+			metav1.Object
+			runtime.Unstructured
+		})
+		if !ok {
+			return nil
+		}
+		if _, ok := c.GetAnnotations()[AnnotationKeyAppliedPatches]; !ok {
+			return nil
+		}
+		if _, ok := d.GetAnnotations()[AnnotationKeyAppliedPatches]; ok {
+			return nil
+		}
+
+		// A null value removes a field when it's merged with the current
+		// object.
+		return fieldpath.Pave(d.UnstructuredContent()).SetValue("metadata.annotations["+AnnotationKeyAppliedPatches+"]", nil)
+	}
+}
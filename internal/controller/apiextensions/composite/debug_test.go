@@ -0,0 +1,268 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestDescribePatch(t *testing.T) {
+	cp := composite.New()
+	cp.SetName("cool-xr")
+	cp.SetLabels(map[string]string{"big": strings.Repeat("a", maxAppliedPatchValueBytes)})
+	cd := composed.New()
+	cd.SetName("cool-cd")
+	cd.SetAnnotations(map[string]string{"xr": "cool-xr", "big": strings.Repeat("a", maxAppliedPatchValueBytes)})
+
+	type want struct {
+		ap AppliedPatch
+		ok bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		p      v1.Patch
+		want   want
+	}{
+		"Applied": {
+			reason: "We should record the value a patch wrote to the composed resource.",
+			p:      v1.Patch{Type: v1.PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.String("metadata.name"), ToFieldPath: pointer.String("metadata.annotations[xr]")},
+			want: want{
+				ap: AppliedPatch{Type: v1.PatchTypeFromCompositeFieldPath, FromFieldPaths: []string{"metadata.name"}, ToFieldPath: "metadata.annotations[xr]", Applied: true, Value: "cool-xr"},
+				ok: true,
+			},
+		},
+		"NotApplied": {
+			reason: "We should record that a patch was not applied if the field it reads from doesn't exist.",
+			p: v1.Patch{Type: v1.PatchTypeCombineFromComposite, ToFieldPath: pointer.String("metadata.annotations[xr]"), Combine: &v1.Combine{
+				Variables: []v1.CombineVariable{{FromFieldPath: "metadata.name"}, {FromFieldPath: "metadata.namespace"}},
+			}},
+			want: want{
+				ap: AppliedPatch{Type: v1.PatchTypeCombineFromComposite, FromFieldPaths: []string{"metadata.name", "metadata.namespace"}, ToFieldPath: "metadata.annotations[xr]"},
+				ok: true,
+			},
+		},
+		"LargeValue": {
+			reason: "We should summarize rather than record large values.",
+			p:      v1.Patch{Type: v1.PatchTypeFromCompositeFieldPath, FromFieldPath: pointer.String("metadata.labels[big]"), ToFieldPath: pointer.String("metadata.annotations[big]")},
+			want: want{
+				ap: AppliedPatch{Type: v1.PatchTypeFromCompositeFieldPath, FromFieldPaths: []string{"metadata.labels[big]"}, ToFieldPath: "metadata.annotations[big]", Applied: true, Value: "(258 bytes omitted)"},
+				ok: true,
+			},
+		},
+		"ToComposite": {
+			reason: "We should not describe patches that aren't applied when rendering a composed resource.",
+			p:      v1.Patch{Type: v1.PatchTypeToCompositeFieldPath, FromFieldPath: pointer.String("metadata.name")},
+			want: want{
+				ap: AppliedPatch{Type: v1.PatchTypeToCompositeFieldPath, Applied: true},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ap, ok := describePatch(0, tc.p, cp, cd)
+			if diff := cmp.Diff(tc.want.ap, ap); diff != "" {
+				t.Errorf("\n%s\ndescribePatch(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ok, ok); diff != "" {
+				t.Errorf("\n%s\ndescribePatch(...): -want ok, +got ok:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSetAppliedPatches(t *testing.T) {
+	ap := AppliedPatch{Type: v1.PatchTypeFromCompositeFieldPath, FromFieldPaths: []string{"spec.a"}, ToFieldPath: "spec.b", Applied: true, Value: strings.Repeat("a", 200)}
+	many := make([]AppliedPatch, 200)
+	for i := range many {
+		many[i] = ap
+		many[i].Index = i
+	}
+
+	cd := &fake.Composed{}
+	setAppliedPatches(cd, many)
+
+	v := cd.GetAnnotations()[AnnotationKeyAppliedPatches]
+	if len(v) > maxAppliedPatchesBytes {
+		t.Errorf("setAppliedPatches(...): recorded %d bytes, want at most %d", len(v), maxAppliedPatchesBytes)
+	}
+	got := []AppliedPatch{}
+	if err := json.Unmarshal([]byte(v), &got); err != nil {
+		t.Fatalf("json.Unmarshal(...): %s", err)
+	}
+	if diff := cmp.Diff(many[:len(got)], got); diff != "" {
+		t.Errorf("setAppliedPatches(...): -want, +got:\n%s", diff)
+	}
+	if len(got) == 0 || len(got) == len(many) {
+		t.Errorf("setAppliedPatches(...): recorded %d of %d patches, want some but not all", len(got), len(many))
+	}
+}
+
+func TestRecordAppliedPatches(t *testing.T) {
+	errBoom := errors.New("boom")
+	withPatches := func(name string) *composed.Unstructured {
+		cd := composed.New()
+		cd.SetAPIVersion("example.org/v1")
+		cd.SetKind("Composed")
+		cd.SetName(name)
+		cd.SetAnnotations(map[string]string{AnnotationKeyAppliedPatches: `[{"index":0,"applied":false,"error":"boom"}]`})
+		return cd
+	}
+
+	type want struct {
+		patch string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		client client.Client
+		cd     *composed.Unstructured
+		want   want
+	}{
+		"NotDebugged": {
+			reason: "Nothing should be recorded if no patches were recorded when rendering the composed resource.",
+			client: &test.MockClient{},
+			cd:     composed.New(),
+		},
+		"NotNamed": {
+			reason: "Nothing should be recorded if the composed resource doesn't exist yet.",
+			client: &test.MockClient{},
+			cd:     withPatches(""),
+		},
+		"NotFound": {
+			reason: "A composed resource that doesn't exist should be ignored.",
+			client: &test.MockClient{MockPatch: test.NewMockPatchFn(kerrors.NewNotFound(schema.GroupResource{}, "cd"))},
+			cd:     withPatches("cd"),
+		},
+		"PatchError": {
+			reason: "Errors patching the existing composed resource should be returned.",
+			client: &test.MockClient{MockPatch: test.NewMockPatchFn(errBoom)},
+			cd:     withPatches("cd"),
+			want:   want{err: errBoom},
+		},
+		"Success": {
+			reason: "The applied patches should be merged into the existing composed resource's annotations.",
+			cd:     withPatches("cd"),
+			want: want{
+				patch: `{"metadata":{"annotations":{"apiextensions.crossplane.io/applied-patches":"[{\"index\":0,\"applied\":false,\"error\":\"boom\"}]"}}}`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			patch := ""
+			c := tc.client
+			if c == nil {
+				c = &test.MockClient{MockPatch: func(_ context.Context, obj client.Object, p client.Patch, _ ...client.PatchOption) error {
+					if obj.GetName() != tc.cd.GetName() {
+						t.Errorf("Patch(...): want name %q, got %q", tc.cd.GetName(), obj.GetName())
+					}
+					b, _ := p.Data(obj)
+					patch = string(b)
+					return nil
+				}}
+			}
+			err := recordAppliedPatches(context.Background(), c, tc.cd)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nrecordAppliedPatches(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.patch, patch); diff != "" {
+				t.Errorf("\n%s\nrecordAppliedPatches(...): -want patch, +got patch:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPruneAppliedPatches(t *testing.T) {
+	withAnnotations := func(a map[string]string) *composed.Unstructured {
+		cd := composed.New()
+		cd.SetAnnotations(a)
+		return cd
+	}
+
+	type args struct {
+		current *composed.Unstructured
+		desired *composed.Unstructured
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   map[string]interface{}
+	}{
+		"NeverDebugged": {
+			reason: "Nothing should be pruned from a composed resource whose patches were never recorded.",
+			args: args{
+				current: withAnnotations(map[string]string{"cool": "true"}),
+				desired: withAnnotations(nil),
+			},
+			want: nil,
+		},
+		"StillDebugged": {
+			reason: "The applied patches should not be pruned while the composite resource debugs patches.",
+			args: args{
+				current: withAnnotations(map[string]string{AnnotationKeyAppliedPatches: "[]"}),
+				desired: withAnnotations(map[string]string{AnnotationKeyAppliedPatches: "[]"}),
+			},
+			want: map[string]interface{}{AnnotationKeyAppliedPatches: "[]"},
+		},
+		"NoLongerDebugged": {
+			reason: "The applied patches should be pruned once the composite resource no longer debugs patches.",
+			args: args{
+				current: withAnnotations(map[string]string{AnnotationKeyAppliedPatches: "[]"}),
+				desired: withAnnotations(nil),
+			},
+			want: map[string]interface{}{AnnotationKeyAppliedPatches: nil},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := pruneAppliedPatches()(context.Background(), tc.args.current, tc.args.desired)
+			if diff := cmp.Diff(nil, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\npruneAppliedPatches(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			md, _ := tc.args.desired.Object["metadata"].(map[string]interface{})
+			got, _ := md["annotations"].(map[string]interface{})
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\npruneAppliedPatches(...): -want annotations, +got annotations:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+)
+
+// FieldPathRemainingResources is the field path at which the composed
+// resources that still exist while a composite resource is being deleted are
+// recorded.
+const FieldPathRemainingResources = "status.remainingResources"
+
+// Reasons a composed resource may still exist while its composite resource is
+// being deleted.
+const (
+	reasonAwaitingGarbageCollection = "Waiting for the composed resource to be garbage collected"
+	reasonNotControlled             = "The composed resource is not controlled by this composite resource and will not be garbage collected"
+
+	reasonFmtAwaitingFinalizers = "Waiting for the composed resource's finalizers to be removed: %s"
+)
+
+// A RemainingResource is a composed resource that still exists while its
+// composite resource is being deleted.
+type RemainingResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+
+	// Reason the composed resource still exists.
+	Reason string `json:"reason"`
+}
+
+// A DeletionDiagnoser determines which of a composite resource's composed
+// resources still exist, and why, while it is being deleted.
+type DeletionDiagnoser interface {
+	DiagnoseDeletion(ctx context.Context, cr resource.Composite) ([]RemainingResource, error)
+}
+
+// A DeletionDiagnoserFn determines which of a composite resource's composed
+// resources still exist, and why, while it is being deleted.
+type DeletionDiagnoserFn func(ctx context.Context, cr resource.Composite) ([]RemainingResource, error)
+
+// DiagnoseDeletion of the supplied composite resource.
+func (fn DeletionDiagnoserFn) DiagnoseDeletion(ctx context.Context, cr resource.Composite) ([]RemainingResource, error) {
+	return fn(ctx, cr)
+}
+
+// An APIDeletionDiagnoser diagnoses composite resource deletion by reading the
+// composed resources it references from the API server.
+type APIDeletionDiagnoser struct {
+	client client.Reader
+}
+
+// NewAPIDeletionDiagnoser returns a DeletionDiagnoser that reads the composed
+// resources referenced by a composite resource from the API server.
+func NewAPIDeletionDiagnoser(c client.Reader) *APIDeletionDiagnoser {
+	return &APIDeletionDiagnoser{client: c}
+}
+
+// DiagnoseDeletion returns the composed resources referenced by the supplied
+// composite resource that still exist.
+func (d *APIDeletionDiagnoser) DiagnoseDeletion(ctx context.Context, cr resource.Composite) ([]RemainingResource, error) {
+	var rr []RemainingResource
+	for _, ref := range cr.GetResourceReferences() {
+		// We never rendered this resource, so it can't still exist.
+		if ref.Name == "" {
+			continue
+		}
+		cd := composed.New(composed.FromReference(ref))
+		err := d.client.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, cd)
+		if kerrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, errGetComposed)
+		}
+
+		r := RemainingResource{
+			APIVersion: ref.APIVersion,
+			Kind:       ref.Kind,
+			Name:       ref.Name,
+			Namespace:  ref.Namespace,
+		}
+		switch {
+		case meta.WasDeleted(cd) && len(cd.GetFinalizers()) > 0:
+			r.Reason = fmt.Sprintf(reasonFmtAwaitingFinalizers, strings.Join(cd.GetFinalizers(), ", "))
+		case !controlledBy(cr, cd):
+			r.Reason = reasonNotControlled
+		default:
+			r.Reason = reasonAwaitingGarbageCollection
+		}
+		rr = append(rr, r)
+	}
+	return rr, nil
+}
+
+func controlledBy(cr resource.Composite, cd resource.Composed) bool {
+	c := metav1.GetControllerOf(cd)
+	return c != nil && c.UID == cr.GetUID()
+}
+
+// SetRemainingResources records the supplied remaining composed resources in
+// the status of the supplied composite resource. It is a no-op for composite
+// resources that are not unstructured.
+func SetRemainingResources(cr resource.Composite, rr []RemainingResource) error {
+	u, ok := cr.(*composite.Unstructured)
+	if !ok {
+		return nil
+	}
+	if len(rr) == 0 {
+		kunstructured.RemoveNestedField(u.Object, "status", "remainingResources")
+		return nil
+	}
+	return fieldpath.Pave(u.Object).SetValue(FieldPathRemainingResources, rr)
+}
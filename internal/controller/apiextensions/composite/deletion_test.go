@@ -0,0 +1,138 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestDiagnoseDeletion(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := metav1.Now()
+	uid := types.UID("definitely-a-uuid")
+
+	ref := corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "Bucket", Name: "cool-bucket"}
+
+	cr := func() resource.Composite {
+		cr := composite.New()
+		cr.SetUID(uid)
+		cr.SetResourceReferences([]corev1.ObjectReference{{}, ref})
+		return cr
+	}
+
+	controlled := func(obj client.Object) {
+		meta.AddOwnerReference(obj, meta.AsController(&xpv1.TypedReference{APIVersion: "example.org/v1", Kind: "XBucket", Name: "cool-xr", UID: uid}))
+	}
+
+	type want struct {
+		rr  []RemainingResource
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		client client.Reader
+		want   want
+	}{
+		"GetComposedError": {
+			reason: "We should return any error encountered getting a composed resource.",
+			client: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			want: want{
+				err: errors.Wrap(errBoom, errGetComposed),
+			},
+		},
+		"ComposedResourceGone": {
+			reason: "We should not report composed resources that no longer exist.",
+			client: &test.MockClient{MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, ""))},
+			want:   want{},
+		},
+		"AwaitingFinalizers": {
+			reason: "We should report composed resources that are blocked by finalizers.",
+			client: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+				controlled(obj)
+				obj.SetDeletionTimestamp(&now)
+				obj.SetFinalizers([]string{"finalizer.managedresource.crossplane.io", "example.org/cleanup"})
+				return nil
+			})},
+			want: want{
+				rr: []RemainingResource{{
+					APIVersion: ref.APIVersion,
+					Kind:       ref.Kind,
+					Name:       ref.Name,
+					Reason:     fmt.Sprintf(reasonFmtAwaitingFinalizers, "finalizer.managedresource.crossplane.io, example.org/cleanup"),
+				}},
+			},
+		},
+		"NotControlled": {
+			reason: "We should report composed resources that will not be garbage collected because we don't control them.",
+			client: &test.MockClient{MockGet: test.NewMockGetFn(nil)},
+			want: want{
+				rr: []RemainingResource{{
+					APIVersion: ref.APIVersion,
+					Kind:       ref.Kind,
+					Name:       ref.Name,
+					Reason:     reasonNotControlled,
+				}},
+			},
+		},
+		"AwaitingGarbageCollection": {
+			reason: "We should report composed resources that have not yet been garbage collected.",
+			client: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+				controlled(obj)
+				return nil
+			})},
+			want: want{
+				rr: []RemainingResource{{
+					APIVersion: ref.APIVersion,
+					Kind:       ref.Kind,
+					Name:       ref.Name,
+					Reason:     reasonAwaitingGarbageCollection,
+				}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := NewAPIDeletionDiagnoser(tc.client)
+			rr, err := d.DiagnoseDeletion(context.Background(), cr())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nd.DiagnoseDeletion(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.rr, rr); diff != "" {
+				t.Errorf("\n%s\nd.DiagnoseDeletion(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
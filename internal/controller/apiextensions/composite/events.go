@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
+)
+
+const (
+	reasonComposedResource event.Reason = "ComposedResourceError"
+
+	msgFmtComposedError = "Composed resource %s %q: %s"
+)
+
+// A ComposedEventPolicy determines which objects are told about significant
+// events of composed resources, for example a provider failing to create a
+// composed resource's external resource.
+type ComposedEventPolicy string
+
+// Composed event policies.
+const (
+	// ComposedEventPolicyNone doesn't propagate composed resource events.
+	ComposedEventPolicyNone ComposedEventPolicy = "None"
+
+	// ComposedEventPolicyComposite propagates composed resource events to
+	// the composite resource.
+	ComposedEventPolicyComposite ComposedEventPolicy = "Composite"
+
+	// ComposedEventPolicyClaim propagates composed resource events to the
+	// composite resource, and to its claim if it has one.
+	ComposedEventPolicyClaim ComposedEventPolicy = "Claim"
+)
+
+// A ComposedEventPropagator surfaces significant events of a composed resource
+// to the composite resource that composed it.
+type ComposedEventPropagator interface {
+	// PropagateEvents of the supplied composed resource. Propagation is best
+	// effort; failing to propagate an event doesn't fail the reconcile.
+	PropagateEvents(ctx context.Context, cp resource.Composite, cd resource.Composed)
+}
+
+// A ComposedEventPropagatorFn surfaces significant events of a composed
+// resource to the composite resource that composed it.
+type ComposedEventPropagatorFn func(ctx context.Context, cp resource.Composite, cd resource.Composed)
+
+// PropagateEvents of the supplied composed resource.
+func (fn ComposedEventPropagatorFn) PropagateEvents(ctx context.Context, cp resource.Composite, cd resource.Composed) {
+	fn(ctx, cp, cd)
+}
+
+// A ConditionEventPropagator records a warning event for a composite resource,
+// and optionally its claim, while one of its composed resources reports that
+// it is failing to sync, i.e. that its provider can't create, update, or
+// observe its external resource. The recorder is expected to rate limit
+// identical events.
+type ConditionEventPropagator struct {
+	client client.Reader
+	record event.Recorder
+	claims bool
+}
+
+// A ConditionEventPropagatorOption configures a ConditionEventPropagator.
+type ConditionEventPropagatorOption func(p *ConditionEventPropagator)
+
+// WithClaimEvents specifies that events should also be recorded for the claim
+// of the composite resource, if it has one.
+func WithClaimEvents() ConditionEventPropagatorOption {
+	return func(p *ConditionEventPropagator) {
+		p.claims = true
+	}
+}
+
+// NewConditionEventPropagator returns a ConditionEventPropagator that records
+// events using the supplied recorder. The client is used to read claims.
+func NewConditionEventPropagator(c client.Reader, r event.Recorder, opts ...ConditionEventPropagatorOption) *ConditionEventPropagator {
+	p := &ConditionEventPropagator{client: c, record: r}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// PropagateEvents records a warning event if the supplied composed resource is
+// failing to sync.
+func (p *ConditionEventPropagator) PropagateEvents(ctx context.Context, cp resource.Composite, cd resource.Composed) {
+	c := cd.GetCondition(xpv1.TypeSynced)
+	if c.Status != corev1.ConditionFalse || c.Message == "" {
+		return
+	}
+	e := event.Warning(reasonComposedResource, errors.Errorf(msgFmtComposedError, cd.GetObjectKind().GroupVersionKind().Kind, cd.GetName(), c.Message))
+	p.record.Event(cp, e)
+
+	ref := cp.GetClaimReference()
+	if !p.claims || ref == nil {
+		return
+	}
+	cm := claim.New(claim.WithGroupVersionKind(ref.GroupVersionKind()))
+	if err := p.client.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, cm); err != nil {
+		return
+	}
+	p.record.Event(cm, e)
+}
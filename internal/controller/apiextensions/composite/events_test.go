@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+var _ ComposedEventPropagator = &ConditionEventPropagator{}
+
+// A recordedEvent is an event recorded for the named object.
+type recordedEvent struct {
+	Object  string
+	Type    event.Type
+	Reason  event.Reason
+	Message string
+}
+
+// An eventRecorder records the events it is asked to record.
+type eventRecorder struct {
+	events *[]recordedEvent
+}
+
+func (r eventRecorder) Event(obj runtime.Object, e event.Event) {
+	m, _ := obj.(metav1.Object)
+	*r.events = append(*r.events, recordedEvent{Object: m.GetName(), Type: e.Type, Reason: e.Reason, Message: e.Message})
+}
+
+func (r eventRecorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestConditionEventPropagator(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	failing := func() resource.Composed {
+		cd := composed.New(composed.FromReference(corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "Bucket", Name: "cool-bucket"}))
+		cd.SetConditions(xpv1.ReconcileError(errBoom))
+		return cd
+	}
+	xr := func(claim *corev1.ObjectReference) resource.Composite {
+		return &fake.Composite{ObjectMeta: metav1.ObjectMeta{Name: "cool-xr"}, ClaimReferencer: fake.ClaimReferencer{Ref: claim}}
+	}
+	claimRef := &corev1.ObjectReference{APIVersion: "example.org/v1", Kind: "Claim", Namespace: "default", Name: "cool-claim"}
+	warning := func(obj string) recordedEvent {
+		return recordedEvent{Object: obj, Type: event.TypeWarning, Reason: reasonComposedResource, Message: `Composed resource Bucket "cool-bucket": boom`}
+	}
+
+	type args struct {
+		client client.Reader
+		opts   []ConditionEventPropagatorOption
+		cp     resource.Composite
+		cd     resource.Composed
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   []recordedEvent
+	}{
+		"Synced": {
+			reason: "We should not record an event for a composed resource that is syncing successfully.",
+			args: args{
+				cp: xr(nil),
+				cd: func() resource.Composed {
+					cd := composed.New()
+					cd.SetConditions(xpv1.ReconcileSuccess())
+					return cd
+				}(),
+			},
+			want: []recordedEvent{},
+		},
+		"CompositeOnly": {
+			reason: "We should record an event for the composite resource, but not its claim, unless claim events are enabled.",
+			args: args{
+				cp: xr(claimRef),
+				cd: failing(),
+			},
+			want: []recordedEvent{warning("cool-xr")},
+		},
+		"Claim": {
+			reason: "We should record an event for both the composite resource and its claim when claim events are enabled.",
+			args: args{
+				client: &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					obj.SetName("cool-claim")
+					return nil
+				}},
+				opts: []ConditionEventPropagatorOption{WithClaimEvents()},
+				cp:   xr(claimRef),
+				cd:   failing(),
+			},
+			want: []recordedEvent{warning("cool-xr"), warning("cool-claim")},
+		},
+		"NoClaim": {
+			reason: "We should record an event only for the composite resource if it has no claim.",
+			args: args{
+				opts: []ConditionEventPropagatorOption{WithClaimEvents()},
+				cp:   xr(nil),
+				cd:   failing(),
+			},
+			want: []recordedEvent{warning("cool-xr")},
+		},
+		"ErrGetClaim": {
+			reason: "We should still record an event for the composite resource if we can't get its claim.",
+			args: args{
+				client: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				opts:   []ConditionEventPropagatorOption{WithClaimEvents()},
+				cp:     xr(claimRef),
+				cd:     failing(),
+			},
+			want: []recordedEvent{warning("cool-xr")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := []recordedEvent{}
+			p := NewConditionEventPropagator(tc.args.client, eventRecorder{events: &got}, tc.args.opts...)
+			p.PropagateEvents(context.Background(), tc.args.cp, tc.args.cd)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nPropagateEvents(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+const (
+	errFmtGateCheck   = "cannot check gate on resource %q"
+	errFmtGateUnknown = "gate waits for unknown resource %q"
+	errFmtGatedExists = "cannot determine whether gated composed resource %d exists"
+
+	msgFmtGateClosed = "Waiting to create composed resource %d: gate on resource %q is not open"
+)
+
+// GateOpen returns true if the supplied composed resource opens the supplied
+// gate.
+func GateOpen(ctx context.Context, cd resource.Composed, g v1.Gate) (bool, error) {
+	if c := g.Condition; c != nil {
+		want := c.Status
+		if want == "" {
+			want = corev1.ConditionTrue
+		}
+		if cd.GetCondition(c.Type).Status != want {
+			return false, nil
+		}
+		if len(g.Checks) == 0 {
+			return true, nil
+		}
+	}
+	return IsReady(ctx, cd, v1.ComposedTemplate{ReadinessChecks: g.Checks})
+}
+
+// closedGate returns the name of the resource the first closed gate of the
+// supplied template waits for, or an empty string if all of its gates are
+// open. The supplied resource states are keyed by template name. Only resources
+// that have been applied or observed this reconcile can open a gate; others may
+// not exist, or may not reflect their actual state.
+func closedGate(ctx context.Context, t v1.ComposedTemplate, named map[string]*composedRenderState) (string, error) {
+	for _, g := range t.Gates {
+		cd, ok := named[g.ResourceName]
+		if !ok {
+			return "", errors.Errorf(errFmtGateUnknown, g.ResourceName)
+		}
+		if !cd.rendered || !(cd.applied || cd.observeOnly) {
+			return g.ResourceName, nil
+		}
+		open, err := GateOpen(ctx, cd.resource, g)
+		if err != nil {
+			return "", errors.Wrapf(err, errFmtGateCheck, g.ResourceName)
+		}
+		if !open {
+			return g.ResourceName, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestGateOpen(t *testing.T) {
+	synced := func() *composed.Unstructured {
+		cd := composed.New()
+		cd.SetConditions(xpv1.ReconcileSuccess())
+		return cd
+	}
+	ready := func() *composed.Unstructured {
+		cd := synced()
+		cd.SetConditions(xpv1.Available())
+		cd.Object["status"].(map[string]interface{})["phase"] = "Running"
+		return cd
+	}
+
+	type args struct {
+		cd *composed.Unstructured
+		g  v1.Gate
+	}
+	type want struct {
+		open bool
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"DefaultReady": {
+			reason: "A gate with no condition or checks should open when the resource is ready.",
+			args:   args{cd: ready(), g: v1.Gate{ResourceName: "a"}},
+			want:   want{open: true},
+		},
+		"DefaultNotReady": {
+			reason: "A gate with no condition or checks should not open when the resource is not ready.",
+			args:   args{cd: synced(), g: v1.Gate{ResourceName: "a"}},
+			want:   want{open: false},
+		},
+		"ConditionMet": {
+			reason: "A gate should open when the resource reports its condition, even if the resource is not ready.",
+			args:   args{cd: synced(), g: v1.Gate{ResourceName: "a", Condition: &v1.GateCondition{Type: xpv1.TypeSynced}}},
+			want:   want{open: true},
+		},
+		"ConditionStatusNotMet": {
+			reason: "A gate should not open when the resource's condition doesn't have the required status.",
+			args:   args{cd: synced(), g: v1.Gate{ResourceName: "a", Condition: &v1.GateCondition{Type: xpv1.TypeSynced, Status: corev1.ConditionFalse}}},
+			want:   want{open: false},
+		},
+		"ConditionMissing": {
+			reason: "A gate should not open when the resource doesn't report its condition.",
+			args:   args{cd: composed.New(), g: v1.Gate{ResourceName: "a", Condition: &v1.GateCondition{Type: xpv1.TypeSynced}}},
+			want:   want{open: false},
+		},
+		"ChecksPassed": {
+			reason: "A gate should open when the resource passes its checks.",
+			args: args{cd: ready(), g: v1.Gate{ResourceName: "a", Checks: []v1.ReadinessCheck{
+				{Type: v1.ReadinessCheckTypeMatchString, FieldPath: "status.phase", MatchString: "Running"},
+			}}},
+			want: want{open: true},
+		},
+		"ConditionMetChecksFailed": {
+			reason: "A gate should not open when the resource reports its condition but fails its checks.",
+			args: args{cd: synced(), g: v1.Gate{ResourceName: "a", Condition: &v1.GateCondition{Type: xpv1.TypeSynced}, Checks: []v1.ReadinessCheck{
+				{Type: v1.ReadinessCheckTypeNonEmpty, FieldPath: "status.phase"},
+			}}},
+			want: want{open: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			open, err := GateOpen(context.Background(), tc.args.cd, tc.args.g)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGateOpen(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.open, open); diff != "" {
+				t.Errorf("\n%s\nGateOpen(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestClosedGate(t *testing.T) {
+	ready := composed.New()
+	ready.SetConditions(xpv1.Available())
+
+	type args struct {
+		t     v1.ComposedTemplate
+		named map[string]*composedRenderState
+	}
+	type want struct {
+		name string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoGates": {
+			reason: "A template with no gates has no closed gate.",
+			args:   args{t: v1.ComposedTemplate{}},
+		},
+		"Open": {
+			reason: "A gate on an applied resource that opens it should be open.",
+			args: args{
+				t:     v1.ComposedTemplate{Gates: []v1.Gate{{ResourceName: "a"}}},
+				named: map[string]*composedRenderState{"a": {resource: ready, rendered: true, applied: true}},
+			},
+		},
+		"ObservedOpen": {
+			reason: "A gate on an observed resource that opens it should be open.",
+			args: args{
+				t:     v1.ComposedTemplate{Gates: []v1.Gate{{ResourceName: "a"}}},
+				named: map[string]*composedRenderState{"a": {resource: ready, rendered: true, observeOnly: true}},
+			},
+		},
+		"NotApplied": {
+			reason: "A gate on a resource we didn't apply should be closed, because the resource may not exist.",
+			args: args{
+				t:     v1.ComposedTemplate{Gates: []v1.Gate{{ResourceName: "a"}}},
+				named: map[string]*composedRenderState{"a": {resource: ready, rendered: true}},
+			},
+			want: want{name: "a"},
+		},
+		"Closed": {
+			reason: "We should return the name of the resource of the first closed gate.",
+			args: args{
+				t: v1.ComposedTemplate{Gates: []v1.Gate{{ResourceName: "a"}, {ResourceName: "b"}}},
+				named: map[string]*composedRenderState{
+					"a": {resource: ready, rendered: true, applied: true},
+					"b": {resource: composed.New(), rendered: true, applied: true},
+				},
+			},
+			want: want{name: "b"},
+		},
+		"UnknownResource": {
+			reason: "We should return an error if a gate waits for an unknown resource.",
+			args: args{
+				t:     v1.ComposedTemplate{Gates: []v1.Gate{{ResourceName: "nope"}}},
+				named: map[string]*composedRenderState{},
+			},
+			want: want{err: errors.Errorf(errFmtGateUnknown, "nope")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := closedGate(context.Background(), tc.args.t, tc.args.named)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nclosedGate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.name, got); diff != "" {
+				t.Errorf("\n%s\nclosedGate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+// AnnotationKeyPropagatedMetadata is set on each composed resource that a
+// Composition propagates metadata to. Its value is a JSON encoded
+// MetadataPropagation, listing the keys of the labels and annotations that were
+// propagated from the composite resource when the composed resource was last
+// rendered.
+const AnnotationKeyPropagatedMetadata = "apiextensions.crossplane.io/propagated-metadata"
+
+// setPropagatedMetadata records which of the labels and annotations the
+// supplied policy propagates exist on the supplied composite resource, and
+// thus were propagated to the supplied composed resource.
+func setPropagatedMetadata(cp, cd metav1.Object, mp *v1.MetadataPropagation) {
+	if mp == nil {
+		return
+	}
+	propagated := v1.MetadataPropagation{
+		Labels:      existingKeys(cp.GetLabels(), mp.Labels),
+		Annotations: existingKeys(cp.GetAnnotations(), mp.Annotations),
+	}
+	if len(propagated.Labels) == 0 && len(propagated.Annotations) == 0 {
+		return
+	}
+	j, _ := json.Marshal(propagated)
+	meta.AddAnnotations(cd, map[string]string{AnnotationKeyPropagatedMetadata: string(j)})
+}
+
+// getPropagatedMetadata returns the labels and annotations that were last
+// propagated to the supplied composed resource.
+func getPropagatedMetadata(cd metav1.Object) v1.MetadataPropagation {
+	mp := v1.MetadataPropagation{}
+	if v, ok := cd.GetAnnotations()[AnnotationKeyPropagatedMetadata]; ok {
+		_ = json.Unmarshal([]byte(v), &mp)
+	}
+	return mp
+}
+
+func existingKeys(m map[string]string, keys []string) []string {
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if _, ok := m[k]; ok {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// pruneStaleMetadata returns an ApplyOption that removes labels and
+// annotations that were previously propagated to the current object, but which
+// the desired object no longer has. Applying a composed resource merges it with
+// the current object, so without this they would never be removed. It is only
+// called if the object exists.
+func pruneStaleMetadata() resource.ApplyOption {
+	return func(_ context.Context, current, desired runtime.Object) error {
+		c, ok := current.(metav1.Object)
+		if !ok {
+			return nil
+		}
+		d, ok := desired.(interface {
+			metav1.Object
+			runtime.Unstructured
+		})
+		if !ok {
+			return nil
+		}
+		if _, ok := c.GetAnnotations()[AnnotationKeyPropagatedMetadata]; !ok {
+			return nil
+		}
+
+		prev := getPropagatedMetadata(c)
+		dl, da := d.GetLabels(), d.GetAnnotations()
+
+		// A null value removes a field when it's merged with the current
+		// object.
+		p := fieldpath.Pave(d.UnstructuredContent())
+		for _, k := range prev.Labels {
+			if _, ok := dl[k]; !ok {
+				if err := p.SetValue("metadata.labels["+k+"]", nil); err != nil {
+					return err
+				}
+			}
+		}
+		for _, k := range prev.Annotations {
+			if _, ok := da[k]; !ok {
+				if err := p.SetValue("metadata.annotations["+k+"]", nil); err != nil {
+					return err
+				}
+			}
+		}
+		if _, ok := da[AnnotationKeyPropagatedMetadata]; !ok {
+			return p.SetValue("metadata.annotations["+AnnotationKeyPropagatedMetadata+"]", nil)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestSetPropagatedMetadata(t *testing.T) {
+	type args struct {
+		cp metav1.Object
+		mp *v1.MetadataPropagation
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   map[string]string
+	}{
+		"NoPolicy": {
+			reason: "Nothing should be recorded if the Composition doesn't propagate metadata.",
+			args: args{
+				cp: &fake.Composite{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"cool": "true"}}},
+			},
+			want: nil,
+		},
+		"NothingPropagated": {
+			reason: "Nothing should be recorded if the composite resource has none of the propagated metadata.",
+			args: args{
+				cp: &fake.Composite{},
+				mp: &v1.MetadataPropagation{Labels: []string{"cool"}},
+			},
+			want: nil,
+		},
+		"Propagated": {
+			reason: "Only the propagated keys that exist on the composite resource should be recorded.",
+			args: args{
+				cp: &fake.Composite{ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"cool": "true", "other": "true"},
+					Annotations: map[string]string{"owner": "me"},
+				}},
+				mp: &v1.MetadataPropagation{Labels: []string{"cool", "missing"}, Annotations: []string{"owner"}},
+			},
+			want: map[string]string{AnnotationKeyPropagatedMetadata: `{"labels":["cool"],"annotations":["owner"]}`},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cd := &fake.Composed{}
+			setPropagatedMetadata(tc.args.cp, cd, tc.args.mp)
+			if diff := cmp.Diff(tc.want, cd.GetAnnotations()); diff != "" {
+				t.Errorf("\n%s\nsetPropagatedMetadata(...): -want annotations, +got annotations:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPruneStaleMetadata(t *testing.T) {
+	withMetadata := func(labels, annotations map[string]string) *composed.Unstructured {
+		cd := composed.New()
+		cd.SetLabels(labels)
+		cd.SetAnnotations(annotations)
+		return cd
+	}
+	propagated := `{"labels":["cool","gone"],"annotations":["owner"]}`
+
+	type args struct {
+		current *composed.Unstructured
+		desired *composed.Unstructured
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   map[string]interface{}
+	}{
+		"NeverPropagated": {
+			reason: "Nothing should be pruned from a composed resource that metadata was never propagated to.",
+			args: args{
+				current: withMetadata(map[string]string{"gone": "true"}, nil),
+				desired: withMetadata(nil, nil),
+			},
+			want: map[string]interface{}{},
+		},
+		"PruneStale": {
+			reason: "Previously propagated metadata that is no longer desired should be removed.",
+			args: args{
+				current: withMetadata(
+					map[string]string{"cool": "true", "gone": "true"},
+					map[string]string{"owner": "me", AnnotationKeyPropagatedMetadata: propagated},
+				),
+				desired: withMetadata(
+					map[string]string{"cool": "true"},
+					map[string]string{AnnotationKeyPropagatedMetadata: `{"labels":["cool"]}`},
+				),
+			},
+			want: map[string]interface{}{
+				"labels": map[string]interface{}{"cool": "true", "gone": nil},
+				"annotations": map[string]interface{}{
+					"owner":                         nil,
+					AnnotationKeyPropagatedMetadata: `{"labels":["cool"]}`,
+				},
+			},
+		},
+		"NoLongerPropagating": {
+			reason: "All previously propagated metadata, and the record of it, should be removed if nothing is propagated.",
+			args: args{
+				current: withMetadata(
+					map[string]string{"cool": "true", "gone": "true"},
+					map[string]string{"owner": "me", AnnotationKeyPropagatedMetadata: propagated},
+				),
+				desired: withMetadata(nil, nil),
+			},
+			want: map[string]interface{}{
+				"labels":      map[string]interface{}{"cool": nil, "gone": nil},
+				"annotations": map[string]interface{}{"owner": nil, AnnotationKeyPropagatedMetadata: nil},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := pruneStaleMetadata()(context.Background(), tc.args.current, tc.args.desired)
+			if diff := cmp.Diff(nil, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\npruneStaleMetadata(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			got, _ := tc.args.desired.Object["metadata"].(map[string]interface{})
+			if got == nil {
+				got = map[string]interface{}{}
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\npruneStaleMetadata(...): -want metadata, +got metadata:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
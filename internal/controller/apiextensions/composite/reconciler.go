@@ -18,11 +18,17 @@ package composite
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -39,6 +45,8 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
 
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/internal/loglevel"
+	"github.com/crossplane/crossplane/internal/metrics"
 )
 
 const (
@@ -59,13 +67,30 @@ const (
 	errConfigure       = "cannot configure composite resource"
 	errPublish         = "cannot publish connection details"
 	errUnpublish       = "cannot unpublish connection details"
+	errDiagnoseDelete  = "cannot determine which composed resources remain"
 	errRenderCD        = "cannot render composed resource"
+	errObserveCD       = "cannot observe existing resource"
+	errExternalName    = "cannot determine external name of composed resource"
 	errRenderCR        = "cannot render composite resource"
 	errValidate        = "refusing to use invalid Composition"
 	errInline          = "cannot inline Composition patch sets"
 	errAssociate       = "cannot associate composed resources with Composition resource templates"
 
-	errFmtRender = "cannot render composed resource from resource template at index %d"
+	errFmtRender            = "cannot render composed resource from resource template at index %d"
+	errFmtObserve           = "cannot observe existing resource of observe-only resource template at index %d"
+	errFmtExternalName      = "cannot determine external name of composed resource from resource template at index %d"
+	errFmtTooManyComposed   = "refusing to compose %d resources, which exceeds the maximum of %d"
+	errFmtRenderedTooLarge  = "rendered composed resource is %d bytes, which exceeds the maximum of %d"
+	errMeasureRenderedBytes = "cannot determine size of rendered composed resource"
+
+	errFmtExternalNameConflict = "refusing to change external name of composed resource %q from %q to %q"
+
+	errFmtSecretPatches = "composition patches secret data into fields that are not secret: %s"
+
+	msgFmtRemaining = "Waiting for %d composed resource(s) to be deleted; see status.remainingResources"
+
+	msgFmtReadinessTimeout = "Composed resource %s %q has not become ready within its readiness timeout of %s"
+	msgFmtTimedOut         = "Composed resource(s) did not become ready within their readiness timeout: %s"
 )
 
 // Event reasons.
@@ -143,6 +168,22 @@ func (fn RendererFn) Render(ctx context.Context, cp resource.Composite, cd resou
 	return fn(ctx, cp, cd, t)
 }
 
+// An Observer is used to observe the existing resource of an observe-only
+// resource template.
+type Observer interface {
+	Observe(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) error
+}
+
+// An ObserverFn may be used to observe the existing resource of an observe-only
+// resource template.
+type ObserverFn func(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) error
+
+// Observe the existing resource identified by the supplied template by reading
+// it into the supplied composed resource.
+func (fn ObserverFn) Observe(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) error {
+	return fn(ctx, cp, cd, t)
+}
+
 // A ConnectionDetailsFetcherFn fetches the connection details of the supplied
 // composed resource, if any.
 type ConnectionDetailsFetcherFn func(ctx context.Context, cd resource.Composed, t v1.ComposedTemplate) (managed.ConnectionDetails, error)
@@ -165,6 +206,26 @@ func (fn ReadinessCheckerFn) IsReady(ctx context.Context, cd resource.Composed,
 	return fn(ctx, cd, t)
 }
 
+// An ExternalNamer determines the external name of a composed resource that
+// does not specify its own.
+type ExternalNamer interface {
+	// ExternalName returns the external name of the supplied composed
+	// resource, or an empty string if it should have none. It is called each
+	// time the composed resource is rendered, and must return the same name
+	// each time; the external name of a composed resource can't be changed
+	// once set.
+	ExternalName(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) (string, error)
+}
+
+// An ExternalNamerFn determines the external name of a composed resource that
+// does not specify its own.
+type ExternalNamerFn func(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) (string, error)
+
+// ExternalName returns the external name of the supplied composed resource.
+func (fn ExternalNamerFn) ExternalName(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) (string, error) {
+	return fn(ctx, cp, cd, t)
+}
+
 // ReconcilerOption is used to configure the Reconciler.
 type ReconcilerOption func(*Reconciler)
 
@@ -232,6 +293,14 @@ func WithRenderer(rd Renderer) ReconcilerOption {
 	}
 }
 
+// WithObserver specifies how the Reconciler should observe the existing
+// resources of observe-only resource templates.
+func WithObserver(o Observer) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.composed.Observer = o
+	}
+}
+
 // WithConnectionDetailsFetcher specifies how the Reconciler should fetch the
 // connection details of composed resources.
 func WithConnectionDetailsFetcher(f ConnectionDetailsFetcher) ReconcilerOption {
@@ -289,6 +358,85 @@ func WithCompositeRenderer(rd Renderer) ReconcilerOption {
 	}
 }
 
+// WithMetricsRecorder specifies how the Reconciler should record metrics.
+func WithMetricsRecorder(m metrics.Recorder) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.metrics = m
+	}
+}
+
+// Limits constrain what a composite resource may compose. A limit of zero
+// means no limit.
+type Limits struct {
+	// MaxComposedResources is the maximum number of resources a composite
+	// resource may compose.
+	MaxComposedResources int
+
+	// MaxRenderedBytes is the maximum size of a rendered composed resource,
+	// when serialized to JSON.
+	MaxRenderedBytes int
+}
+
+// WithLimits specifies what a composite resource may compose. There are no
+// limits by default.
+func WithLimits(l Limits) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.limits = l
+	}
+}
+
+// WithSecretPatchPolicy specifies what the Reconciler should do when a
+// Composition patches secret data into fields that are not secret. Such
+// patches are allowed by default.
+func WithSecretPatchPolicy(p SecretPatchPolicy) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.secretPatchPolicy = p
+	}
+}
+
+// WithExternalNamePropagation specifies that the Reconciler should propagate
+// the external name of a composite resource to any composed resource that does
+// not specify its own, and refuse to change the external name of any composed
+// resource that already has one.
+func WithExternalNamePropagation() ReconcilerOption {
+	return WithExternalNamer(ExternalNamerFn(PropagateExternalName))
+}
+
+// WithExternalNamer specifies how the Reconciler should name any composed
+// resource that does not specify its own external name. The Reconciler refuses
+// to change the external name of any composed resource that already has one.
+// Composed resources are not named by default.
+func WithExternalNamer(n ExternalNamer) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.composed.ExternalNamer = n
+	}
+}
+
+// WithDefaultComposedLabels specifies labels the Reconciler should add to every
+// resource it composes. Labels set by a Composition take precedence.
+func WithDefaultComposedLabels(l map[string]string) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.defaultLabels = l
+	}
+}
+
+// WithComposedEventPropagator specifies how the Reconciler should surface
+// significant events of composed resources. Events are not propagated by
+// default.
+func WithComposedEventPropagator(p ComposedEventPropagator) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.composed.ComposedEventPropagator = p
+	}
+}
+
+// WithDeletionDiagnoser specifies how the Reconciler should determine which
+// composed resources still exist while a composite resource is being deleted.
+func WithDeletionDiagnoser(d DeletionDiagnoser) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.composite.DeletionDiagnoser = d
+	}
+}
+
 type composition struct {
 	CompositionFetcher
 	CompositionValidator
@@ -300,13 +448,17 @@ type compositeResource struct {
 	CompositionSelector
 	Configurator
 	Renderer
+	DeletionDiagnoser
 	managed.ConnectionPublisher
 }
 
 type composedResource struct {
 	Renderer
+	Observer
 	ConnectionDetailsFetcher
 	ReadinessChecker
+	ExternalNamer
+	ComposedEventPropagator
 }
 
 // NewReconciler returns a new Reconciler of composite resources.
@@ -321,7 +473,7 @@ func NewReconciler(mgr manager.Manager, of resource.CompositeKind, opts ...Recon
 		newComposite: nc,
 
 		composition: composition{
-			CompositionFetcher: NewAPICompositionFetcher(kube),
+			CompositionFetcher: NewInheritingCompositionFetcher(NewAPICompositionFetcher(kube), kube),
 			CompositionValidator: ValidationChain{
 				CompositionValidatorFn(RejectMixedTemplates),
 				CompositionValidatorFn(RejectDuplicateNames),
@@ -335,16 +487,20 @@ func NewReconciler(mgr manager.Manager, of resource.CompositeKind, opts ...Recon
 			Configurator:        NewConfiguratorChain(NewAPINamingConfigurator(kube), NewAPIConfigurator(kube)),
 			ConnectionPublisher: NewAPIFilteredSecretPublisher(kube, []string{}),
 			Renderer:            RendererFn(RenderComposite),
+			DeletionDiagnoser:   NewAPIDeletionDiagnoser(kube),
 		},
 
 		composed: composedResource{
 			Renderer:                 NewAPIDryRunRenderer(kube),
+			Observer:                 NewAPIObserver(kube),
 			ReadinessChecker:         ReadinessCheckerFn(IsReady),
 			ConnectionDetailsFetcher: NewAPIConnectionDetailsFetcher(kube),
+			ComposedEventPropagator:  ComposedEventPropagatorFn(func(_ context.Context, _ resource.Composite, _ resource.Composed) {}),
 		},
 
-		log:    logging.NewNopLogger(),
-		record: event.NewNopRecorder(),
+		log:     logging.NewNopLogger(),
+		record:  event.NewNopRecorder(),
+		metrics: metrics.NewNopRecorder(),
 
 		pollInterval: defaultPollInterval,
 	}
@@ -364,8 +520,13 @@ type Reconciler struct {
 	composite   compositeResource
 	composed    composedResource
 
-	log    logging.Logger
-	record event.Recorder
+	log     logging.Logger
+	record  event.Recorder
+	metrics metrics.Recorder
+
+	limits            Limits
+	secretPatchPolicy SecretPatchPolicy
+	defaultLabels     map[string]string
 
 	pollInterval time.Duration
 }
@@ -376,6 +537,8 @@ type Reconciler struct {
 type composedRenderState struct {
 	resource       resource.Composed
 	rendered       bool
+	observeOnly    bool
+	applied        bool
 	appliedPatches []v1.Patch
 }
 
@@ -397,6 +560,10 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGet)
 	}
 
+	// Objects annotated for tracing are logged at debug level for a while,
+	// regardless of the controller's log level.
+	log = loglevel.ForObject(log, cr)
+
 	log = log.WithValues(
 		"uid", cr.GetUID(),
 		"version", cr.GetResourceVersion(),
@@ -413,6 +580,29 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			return reconcile.Result{}, err
 		}
 
+		// Composed resources are garbage collected once we remove our
+		// finalizer, but the composite resource will linger until they're
+		// gone if it was deleted in the foreground. We record which composed
+		// resources remain, and why, to help diagnose stuck deletions.
+		rr, err := r.composite.DiagnoseDeletion(ctx, cr)
+		if err != nil {
+			log.Debug(errDiagnoseDelete, "error", err)
+			err = errors.Wrap(err, errDiagnoseDelete)
+			r.record.Event(cr, event.Warning(reasonDelete, err))
+			return reconcile.Result{}, err
+		}
+		if len(rr) > 0 {
+			cr.SetConditions(xpv1.Deleting().WithMessage(fmt.Sprintf(msgFmtRemaining, len(rr))))
+			if err := SetRemainingResources(cr, rr); err != nil {
+				log.Debug(errDiagnoseDelete, "error", err)
+				return reconcile.Result{}, errors.Wrap(err, errDiagnoseDelete)
+			}
+			if err := r.client.Status().Update(ctx, cr); resource.IgnoreNotFound(err) != nil {
+				log.Debug(errUpdateStatus, "error", err)
+				return reconcile.Result{}, errors.Wrap(err, errUpdateStatus)
+			}
+		}
+
 		if err := r.composite.RemoveFinalizer(ctx, cr); err != nil {
 			log.Debug(errRemoveFinalizer, "error")
 			err = errors.Wrap(err, errRemoveFinalizer)
@@ -420,6 +610,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			return reconcile.Result{}, err
 		}
 
+		if len(rr) > 0 {
+			log.Debug("Waiting for composed resources to be deleted", "remaining", len(rr))
+			return reconcile.Result{RequeueAfter: r.pollInterval}, nil
+		}
+
 		log.Debug("Successfully deleted composite resource")
 		return reconcile.Result{Requeue: false}, nil
 	}
@@ -453,6 +648,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	// webhook, not by this controller.
 	if err := r.composition.Validate(comp); err != nil {
 		log.Debug(errValidate, "error", err)
+		r.metrics.RecordRenderFailure(metrics.RenderFailureInvalidComposition)
 		err = errors.Wrap(err, errValidate)
 		r.record.Event(cr, event.Warning(reasonCompose, err))
 		return reconcile.Result{}, err
@@ -465,15 +661,24 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, err
 	}
 
-	// Inline PatchSets from Composition Spec before composing resources.
-	ct, err := comp.Spec.ComposedTemplates()
+	// Inline PatchSets from Composition Spec before composing resources. Any
+	// patches overridden for the XR's version are inlined too.
+	ct, err := comp.Spec.ComposedTemplatesForVersion(cr.GetObjectKind().GroupVersionKind().GroupVersion().String())
 	if err != nil {
 		log.Debug(errInline, "error", err)
+		r.metrics.RecordRenderFailure(metrics.RenderFailurePatchSets)
 		err = errors.Wrap(err, errInline)
 		r.record.Event(cr, event.Warning(reasonCompose, err))
 		return reconcile.Result{}, err
 	}
 
+	if err := r.checkSecretPatches(cr, ct); err != nil {
+		log.Debug(err.Error())
+		r.metrics.RecordRenderFailure(metrics.RenderFailureSecretPatch)
+		r.record.Event(cr, event.Warning(reasonCompose, err))
+		return reconcile.Result{}, err
+	}
+
 	tas, err := r.composition.AssociateTemplates(ctx, cr, ct)
 	if err != nil {
 		log.Debug(errAssociate, "error", err)
@@ -482,6 +687,16 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, err
 	}
 
+	// Refusing to compose any resources is safer than composing some of them,
+	// because we'd have to decide which of them to garbage collect.
+	if max := r.limits.MaxComposedResources; max > 0 && len(tas) > max {
+		err := errors.Errorf(errFmtTooManyComposed, len(tas), max)
+		log.Debug(err.Error())
+		r.metrics.RecordRenderFailure(metrics.RenderFailureLimitExceeded)
+		r.record.Event(cr, event.Warning(reasonCompose, err))
+		return reconcile.Result{}, err
+	}
+
 	// We optimistically render all composed resources that we are able to
 	// with the expectation that any that we fail to render will
 	// subsequently have their error corrected by manual intervention or
@@ -490,11 +705,58 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	cds := make([]composedRenderState, len(tas))
 	for i, ta := range tas {
 		cd := composed.New(composed.FromReference(ta.Reference))
+
+		// We read, but never write, the existing resource of an observe-only
+		// template. We don't record its name because we don't own it; a
+		// recorded resource could be garbage collected.
+		if ta.Template.ObserveOnly != nil {
+			observed := true
+			if err := r.composed.Observe(ctx, cr, cd, ta.Template); err != nil {
+				log.Debug(errObserveCD, "error", err, "index", i)
+				r.record.Event(cr, event.Warning(reasonCompose, errors.Wrapf(err, errFmtObserve, i)))
+				observed = false
+			}
+			cds[i] = composedRenderState{resource: cd, rendered: observed, observeOnly: true}
+			gvk := cd.GetObjectKind().GroupVersionKind()
+			refs[i] = corev1.ObjectReference{APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind}
+			continue
+		}
+
 		rendered := true
 		if err := r.composed.Render(ctx, cr, cd, ta.Template); err != nil {
 			log.Debug(errRenderCD, "error", err, "index", i)
+			r.metrics.RecordRenderFailure(metrics.RenderFailureComposedResource)
 			r.record.Event(cr, event.Warning(reasonCompose, errors.Wrapf(err, errFmtRender, i)))
 			rendered = false
+			if err := recordAppliedPatches(ctx, r.client, cd); err != nil {
+				log.Debug("Cannot record applied patches", "error", err, "index", i)
+			}
+		}
+		setPropagatedMetadata(cr, cd, comp.Spec.PropagateMetadata)
+
+		if r.composed.ExternalNamer != nil && meta.GetExternalName(cd) == "" {
+			en, err := r.composed.ExternalName(ctx, cr, cd, ta.Template)
+			if err != nil {
+				log.Debug(errExternalName, "error", err, "index", i)
+				r.record.Event(cr, event.Warning(reasonCompose, errors.Wrapf(err, errFmtExternalName, i)))
+				rendered = false
+			}
+			if en != "" {
+				meta.SetExternalName(cd, en)
+			}
+		}
+
+		addDefaultLabels(cd, r.defaultLabels)
+
+		// A resource that is too large to store is treated as though we
+		// were unable to render it, so that we don't try to apply it.
+		if rendered {
+			if err := checkRenderedBytes(cd, r.limits.MaxRenderedBytes); err != nil {
+				log.Debug(errRenderCD, "error", err, "index", i)
+				r.metrics.RecordRenderFailure(metrics.RenderFailureLimitExceeded)
+				r.record.Event(cr, event.Warning(reasonCompose, errors.Wrapf(err, errFmtRender, i)))
+				rendered = false
+			}
 		}
 
 		cds[i] = composedRenderState{
@@ -520,14 +782,63 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	// We apply all of our composed resources before we observe them and
 	// update the composite resource accordingly in the loop below. This
 	// ensures that issues observing and processing one composed resource
-	// won't block the application of another.
-	for _, cd := range cds {
+	// won't block the application of another. Resources with gates are
+	// applied last, so that the resources their gates wait for reflect their
+	// current state.
+	named := make(map[string]*composedRenderState, len(cds))
+	gated := make([]int, 0)
+	for i := range cds {
+		cd := &cds[i]
+		if n := tas[i].Template.Name; n != nil {
+			named[*n] = cd
+		}
+
 		// If we were unable to render the composed resource we should not try
-		// and apply it.
-		if !cd.rendered {
+		// and apply it. We never apply observe-only resources.
+		if !cd.rendered || cd.observeOnly {
 			continue
 		}
-		if err := r.client.Apply(ctx, cd.resource, append(mergeOptions(cd.appliedPatches), resource.MustBeControllableBy(cr.GetUID()))...); err != nil {
+		if len(tas[i].Template.Gates) > 0 {
+			gated = append(gated, i)
+			continue
+		}
+		if err := r.applyComposed(ctx, cr, cd); err != nil {
+			log.Debug(errApply, "error", err)
+			err = errors.Wrap(err, errApply)
+			r.record.Event(cr, event.Warning(reasonCompose, err))
+			return reconcile.Result{}, err
+		}
+	}
+
+	for _, i := range gated {
+		cd := &cds[i]
+
+		// Gates only delay the creation of a composed resource. We treat a
+		// resource that is waiting for a gate as though we were unable to
+		// render it, so that the composite resource won't become ready.
+		exists, err := r.composedExists(ctx, cd.resource)
+		if err != nil {
+			log.Debug("Cannot get gated composed resource", "error", err, "index", i)
+			err = errors.Wrapf(err, errFmtGatedExists, i)
+			r.record.Event(cr, event.Warning(reasonCompose, err))
+			return reconcile.Result{}, err
+		}
+		if !exists {
+			name, err := closedGate(ctx, tas[i].Template, named)
+			if err != nil {
+				log.Debug("Cannot evaluate composed resource gates", "error", err, "index", i)
+				r.record.Event(cr, event.Warning(reasonCompose, err))
+				cd.rendered = false
+				continue
+			}
+			if name != "" {
+				log.Debug("Composed resource is waiting for a gate", "index", i, "resource-name", name)
+				r.record.Event(cr, event.Normal(reasonCompose, fmt.Sprintf(msgFmtGateClosed, i, name)))
+				cd.rendered = false
+				continue
+			}
+		}
+		if err := r.applyComposed(ctx, cr, cd); err != nil {
 			log.Debug(errApply, "error", err)
 			err = errors.Wrap(err, errApply)
 			r.record.Event(cr, event.Warning(reasonCompose, err))
@@ -537,7 +848,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 
 	conn := managed.ConnectionDetails{}
 	ready := 0
-	for i, tpl := range comp.Spec.Resources {
+	degraded := false
+	timedOut := make([]string, 0)
+	for i := range tas {
+		// The associated template includes any patches overridden for the
+		// XR's version, which may also patch the XR.
+		tpl := tas[i].Template
 		cd := cds[i]
 
 		// If we were unable to render the composed resource we should not try
@@ -548,6 +864,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 
 		if err := r.composite.Render(ctx, cr, cd.resource, tpl); err != nil {
 			log.Debug(errRenderCR, "error", err)
+			r.metrics.RecordRenderFailure(metrics.RenderFailureCompositeResource)
 			err = errors.Wrap(err, errRenderCR)
 			r.record.Event(cr, event.Warning(reasonCompose, err))
 			return reconcile.Result{}, err
@@ -565,6 +882,8 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			conn[key] = val
 		}
 
+		r.composed.PropagateEvents(ctx, cr, cd.resource)
+
 		rdy, err := r.composed.IsReady(ctx, cd.resource, tpl)
 		if err != nil {
 			log.Debug(errReadiness, "error", err)
@@ -575,6 +894,23 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 
 		if rdy {
 			ready++
+			continue
+		}
+
+		rt := tpl.ReadinessTimeout
+		if rt == nil || !readinessTimedOut(cd.resource, rt.Duration.Duration, time.Now()) {
+			continue
+		}
+
+		r.record.Event(cr, event.Warning(reasonCompose, errors.Errorf(msgFmtReadinessTimeout, cd.resource.GetObjectKind().GroupVersionKind().Kind, cd.resource.GetName(), rt.Duration.Duration)))
+		switch rt.GetFailurePolicy() {
+		case v1.ReadinessFailureContinue:
+			ready++
+		case v1.ReadinessFailureMarkDegraded:
+			degraded = true
+			timedOut = append(timedOut, cd.resource.GetName())
+		case v1.ReadinessFailureBlock:
+			timedOut = append(timedOut, cd.resource.GetName())
 		}
 	}
 
@@ -620,6 +956,8 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		r.record.Event(cr, event.Normal(reasonPublish, "Successfully published connection details"))
 	}
 
+	r.metrics.RecordComposedResources(len(refs))
+
 	// TODO(muvaf):
 	// * Report which resources are not ready.
 	// * If a resource becomes Unavailable at some point, should we still report
@@ -627,7 +965,14 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	if ready != len(refs) {
 		// We want to requeue to wait for our composed resources to
 		// become ready, since we can't watch them.
-		cr.SetConditions(xpv1.Creating())
+		c := xpv1.Creating()
+		if degraded {
+			c = xpv1.Unavailable()
+		}
+		if len(timedOut) > 0 {
+			c = c.WithMessage(fmt.Sprintf(msgFmtTimedOut, strings.Join(timedOut, ", ")))
+		}
+		cr.SetConditions(c)
 		return reconcile.Result{Requeue: true}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
 	}
 
@@ -638,6 +983,125 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	return reconcile.Result{RequeueAfter: r.pollInterval}, errors.Wrap(r.client.Status().Update(ctx, cr), errUpdateStatus)
 }
 
+// applyComposed applies the supplied composed resource, recording whether
+// applying it corrected drift from its desired state.
+func (r *Reconciler) applyComposed(ctx context.Context, cr resource.Composite, cd *composedRenderState) error {
+	rv := ""
+	ao := append(mergeOptions(cd.appliedPatches), resource.MustBeControllableBy(cr.GetUID()), observeResourceVersion(&rv))
+	if r.composed.ExternalNamer != nil {
+		ao = append(ao, externalNameMustNotChange())
+	}
+
+	// Stale metadata is pruned last, because merge options may reset the
+	// desired object from its unstructured content.
+	ao = append(ao, pruneStaleMetadata(), pruneAppliedPatches())
+	if err := r.client.Apply(ctx, cd.resource, ao...); err != nil {
+		return err
+	}
+	cd.applied = true
+
+	// An existing composed resource's version only changes if applying
+	// it changed it, i.e. if it had drifted from its desired state.
+	if rv != "" && rv != cd.resource.GetResourceVersion() {
+		r.metrics.RecordDriftCorrection()
+	}
+	return nil
+}
+
+// composedExists returns true if the supplied composed resource exists.
+func (r *Reconciler) composedExists(ctx context.Context, cd resource.Composed) (bool, error) {
+	if cd.GetName() == "" {
+		return false, nil
+	}
+	existing := composed.New(composed.FromReference(*meta.ReferenceTo(cd, cd.GetObjectKind().GroupVersionKind())))
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: cd.GetNamespace(), Name: cd.GetName()}, existing)
+	if kerrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// addDefaultLabels adds the supplied labels to the supplied object, except for
+// any it already has.
+func addDefaultLabels(o metav1.Object, l map[string]string) {
+	add := make(map[string]string, len(l))
+	for k, v := range l {
+		if _, ok := o.GetLabels()[k]; !ok {
+			add[k] = v
+		}
+	}
+	if len(add) > 0 {
+		meta.AddLabels(o, add)
+	}
+}
+
+// observeResourceVersion returns an ApplyOption that records the resource
+// version of the current object. It is only called if the object exists.
+func observeResourceVersion(rv *string) resource.ApplyOption {
+	return func(_ context.Context, current, _ runtime.Object) error {
+		if m, ok := current.(metav1.Object); ok {
+			*rv = m.GetResourceVersion()
+		}
+		return nil
+	}
+}
+
+// externalNameMustNotChange returns an ApplyOption that returns an error if
+// the current object has an external name, and the desired object has a
+// different one. It is only called if the object exists.
+func externalNameMustNotChange() resource.ApplyOption {
+	return func(_ context.Context, current, desired runtime.Object) error {
+		c, ok := current.(metav1.Object)
+		if !ok {
+			return nil
+		}
+		d, ok := desired.(metav1.Object)
+		if !ok {
+			return nil
+		}
+		ce, de := meta.GetExternalName(c), meta.GetExternalName(d)
+		if ce != "" && de != "" && ce != de {
+			return errors.Errorf(errFmtExternalNameConflict, c.GetName(), ce, de)
+		}
+		return nil
+	}
+}
+
+// checkSecretPatches returns an error if the supplied templates patch secret
+// data into fields that are not secret and the Reconciler's policy denies it.
+// A warning is emitted instead if the policy allows it with a warning.
+func (r *Reconciler) checkSecretPatches(cr resource.Composite, ct []v1.ComposedTemplate) error {
+	if r.secretPatchPolicy != SecretPatchPolicyWarn && r.secretPatchPolicy != SecretPatchPolicyDeny {
+		return nil
+	}
+	sp := SecretPatches(ct)
+	if len(sp) == 0 {
+		return nil
+	}
+	err := errors.Errorf(errFmtSecretPatches, strings.Join(sp, "; "))
+	if r.secretPatchPolicy == SecretPatchPolicyDeny {
+		return err
+	}
+	r.record.Event(cr, event.Warning(reasonCompose, err))
+	return nil
+}
+
+// checkRenderedBytes returns an error if the supplied resource is larger than
+// the supplied maximum number of bytes when serialized to JSON.
+func checkRenderedBytes(cd resource.Composed, max int) error {
+	if max <= 0 {
+		return nil
+	}
+	b, err := json.Marshal(cd)
+	if err != nil {
+		return errors.Wrap(err, errMeasureRenderedBytes)
+	}
+	if len(b) > max {
+		return errors.Errorf(errFmtRenderedTooLarge, len(b), max)
+	}
+	return nil
+}
+
 // filterToXRPatches selects patches defined in composed templates,
 // whose type is one of the XR-targeting patches
 // (e.g. v1.PatchTypeToCompositeFieldPath or v1.PatchTypeCombineToComposite)
@@ -18,22 +18,29 @@ package composite
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
@@ -43,6 +50,8 @@ import (
 func TestReconcile(t *testing.T) {
 	errBoom := errors.New("boom")
 	cd := managed.ConnectionDetails{"a": []byte("b")}
+	readinessContinue := v1.ReadinessFailureContinue
+	readinessMarkDegraded := v1.ReadinessFailureMarkDegraded
 
 	type args struct {
 		mgr  manager.Manager
@@ -183,6 +192,80 @@ func TestReconcile(t *testing.T) {
 				err: nil,
 			},
 		},
+		"DiagnoseDeletionError": {
+			reason: "We should return any error encountered while determining which composed resources remain.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+								if o, ok := obj.(*composite.Unstructured); ok {
+									now := metav1.Now()
+									o.SetDeletionTimestamp(&now)
+								}
+								return nil
+							}),
+						},
+					}),
+					WithConnectionPublishers(managed.ConnectionPublisherFns{
+						UnpublishConnectionFn: func(ctx context.Context, o resource.ConnectionSecretOwner, c managed.ConnectionDetails) error {
+							return nil
+						},
+					}),
+					WithDeletionDiagnoser(DeletionDiagnoserFn(func(ctx context.Context, cr resource.Composite) ([]RemainingResource, error) {
+						return nil, errBoom
+					})),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errDiagnoseDelete),
+			},
+		},
+		"SuccessfulDeleteWithRemainingResources": {
+			reason: "We should record remaining composed resources and requeue when they still exist.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+								if o, ok := obj.(*composite.Unstructured); ok {
+									now := metav1.Now()
+									o.SetDeletionTimestamp(&now)
+								}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(obj client.Object) error {
+								cr := obj.(*composite.Unstructured)
+								want := xpv1.Deleting().WithMessage(fmt.Sprintf(msgFmtRemaining, 1))
+								if diff := cmp.Diff(want, cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+									t.Errorf("\nReason: We should set a Deleting condition.\n-want, +got:\n%s", diff)
+								}
+								rr, _ := fieldpath.Pave(cr.Object).GetValue(FieldPathRemainingResources)
+								wantRR := []interface{}{map[string]interface{}{"apiVersion": "", "kind": "", "name": "cool-resource", "reason": reasonAwaitingGarbageCollection}}
+								if diff := cmp.Diff(wantRR, rr); diff != "" {
+									t.Errorf("\nReason: We should record the remaining composed resources.\n-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+					}),
+					WithCompositeFinalizer(resource.NewNopFinalizer()),
+					WithConnectionPublishers(managed.ConnectionPublisherFns{
+						UnpublishConnectionFn: func(ctx context.Context, o resource.ConnectionSecretOwner, c managed.ConnectionDetails) error {
+							return nil
+						},
+					}),
+					WithDeletionDiagnoser(DeletionDiagnoserFn(func(ctx context.Context, cr resource.Composite) ([]RemainingResource, error) {
+						return []RemainingResource{{Name: "cool-resource", Reason: reasonAwaitingGarbageCollection}}, nil
+					})),
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: defaultPollInterval},
+			},
+		},
 		"AddFinalizerError": {
 			reason: "We should return any error encountered while adding finalizer.",
 			args: args{
@@ -374,6 +457,77 @@ func TestReconcile(t *testing.T) {
 				err: errors.Wrap(errBoom, errAssociate),
 			},
 		},
+		"TooManyComposedResourcesError": {
+			reason: "We should return an error if a Composition would compose more resources than our limit allows.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil),
+						},
+					}),
+					WithCompositeFinalizer(resource.NewNopFinalizer()),
+					WithCompositionSelector(CompositionSelectorFn(func(_ context.Context, cr resource.Composite) error {
+						cr.SetCompositionReference(&corev1.ObjectReference{})
+						return nil
+					})),
+					WithCompositionFetcher(CompositionFetcherFn(func(_ context.Context, _ resource.Composite) (*v1.Composition, error) {
+						return &v1.Composition{}, nil
+					})),
+					WithCompositionValidator(CompositionValidatorFn(func(_ *v1.Composition) error { return nil })),
+					WithConfigurator(ConfiguratorFn(func(_ context.Context, _ resource.Composite, _ *v1.Composition) error {
+						return nil
+					})),
+					WithCompositionTemplateAssociator(CompositionTemplateAssociatorFn(func(context.Context, resource.Composite, []v1.ComposedTemplate) ([]TemplateAssociation, error) {
+						return []TemplateAssociation{{}, {}}, nil
+					})),
+					WithLimits(Limits{MaxComposedResources: 1}),
+				},
+			},
+			want: want{
+				err: errors.Errorf(errFmtTooManyComposed, 2, 1),
+			},
+		},
+		"SecretPatchDeniedError": {
+			reason: "We should return an error if a Composition patches secret data into fields that are not secret and our policy denies it.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil),
+						},
+					}),
+					WithCompositeFinalizer(resource.NewNopFinalizer()),
+					WithCompositionSelector(CompositionSelectorFn(func(_ context.Context, cr resource.Composite) error {
+						cr.SetCompositionReference(&corev1.ObjectReference{})
+						return nil
+					})),
+					WithCompositionFetcher(CompositionFetcherFn(func(_ context.Context, _ resource.Composite) (*v1.Composition, error) {
+						c := &v1.Composition{Spec: v1.CompositionSpec{
+							Resources: []v1.ComposedTemplate{{
+								Base: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Secret"}`)},
+								Patches: []v1.Patch{{
+									Type:          v1.PatchTypeToCompositeFieldPath,
+									FromFieldPath: pointer.String("data.password"),
+									ToFieldPath:   pointer.String("status.password"),
+								}},
+							}},
+						}}
+						return c, nil
+					})),
+					WithCompositionValidator(CompositionValidatorFn(func(_ *v1.Composition) error { return nil })),
+					WithConfigurator(ConfiguratorFn(func(_ context.Context, _ resource.Composite, _ *v1.Composition) error {
+						return nil
+					})),
+					WithSecretPatchPolicy(SecretPatchPolicyDeny),
+				},
+			},
+			want: want{
+				err: errors.Errorf(errFmtSecretPatches, `resource 0 patch 0 copies secret data from "data.password" to composite resource field "status.password"`),
+			},
+		},
 		"UpdateCompositeError": {
 			reason: "We should return any error encountered while updating our composite resource with references.",
 			args: args{
@@ -581,6 +735,76 @@ func TestReconcile(t *testing.T) {
 				err: errors.Wrap(errBoom, errRenderCR),
 			},
 		},
+		"CompositeRenderPatchOverrides": {
+			reason: "We should render the Composite using any patches overridden for its version.",
+			args: args{
+				mgr: &fake.Manager{},
+				of:  resource.CompositeKind(schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "XR"}),
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet:          test.NewMockGetFn(nil),
+							MockUpdate:       test.NewMockUpdateFn(nil),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+						Applicator: resource.ApplyFn(func(c context.Context, r client.Object, ao ...resource.ApplyOption) error {
+							return nil
+						}),
+					}),
+					WithCompositeFinalizer(resource.NewNopFinalizer()),
+					WithCompositionSelector(CompositionSelectorFn(func(_ context.Context, cr resource.Composite) error {
+						cr.SetCompositionReference(&corev1.ObjectReference{})
+						return nil
+					})),
+					WithCompositionFetcher(CompositionFetcherFn(func(_ context.Context, _ resource.Composite) (*v1.Composition, error) {
+						c := &v1.Composition{Spec: v1.CompositionSpec{
+							CompositeTypeRef: v1.TypeReference{APIVersion: "example.org/v1beta1", Kind: "XR"},
+							CompositeTypeVersions: []v1.CompositeTypeVersion{{
+								APIVersion: "example.org/v1",
+								PatchOverrides: []v1.PatchOverride{{
+									ResourceName: "a",
+									Patches: []v1.Patch{{
+										Type:          v1.PatchTypeToCompositeFieldPath,
+										FromFieldPath: pointer.StringPtr("status.id"),
+										ToFieldPath:   pointer.StringPtr("status.parameters.id"),
+									}},
+								}},
+							}},
+							Resources: []v1.ComposedTemplate{{
+								Name: pointer.StringPtr("a"),
+								Patches: []v1.Patch{{
+									Type:          v1.PatchTypeToCompositeFieldPath,
+									FromFieldPath: pointer.StringPtr("status.id"),
+									ToFieldPath:   pointer.StringPtr("status.id"),
+								}},
+							}},
+						}}
+						return c, nil
+					})),
+					WithCompositionValidator(CompositionValidatorFn(func(_ *v1.Composition) error { return nil })),
+					WithConfigurator(ConfiguratorFn(func(_ context.Context, _ resource.Composite, _ *v1.Composition) error {
+						return nil
+					})),
+					WithRenderer(RendererFn(func(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) error {
+						return nil
+					})),
+					WithCompositeRenderer(RendererFn(func(ctx context.Context, cp resource.Composite, cd resource.Composed, tpl v1.ComposedTemplate) error {
+						want := []v1.Patch{{
+							Type:          v1.PatchTypeToCompositeFieldPath,
+							FromFieldPath: pointer.StringPtr("status.id"),
+							ToFieldPath:   pointer.StringPtr("status.parameters.id"),
+						}}
+						if diff := cmp.Diff(want, tpl.Patches); diff != "" {
+							t.Errorf("Render(...): -want patches, +got patches:\n%s", diff)
+						}
+						return errBoom
+					})),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errRenderCR),
+			},
+		},
 		"CompositeUpdateError": {
 			reason: "We should return any error encountered while updating the Composite.",
 			args: args{
@@ -781,6 +1005,55 @@ func TestReconcile(t *testing.T) {
 				r: reconcile.Result{Requeue: true},
 			},
 		},
+		"RenderedComposedResourceTooLarge": {
+			reason: "We should not apply a composed resource that is larger than our limit allows.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet:          test.NewMockGetFn(nil),
+							MockUpdate:       test.NewMockUpdateFn(nil),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+						Applicator: resource.ApplyFn(func(c context.Context, r client.Object, ao ...resource.ApplyOption) error {
+							// We should only apply the composite resource.
+							if _, ok := r.(resource.Composed); ok {
+								return errBoom
+							}
+							return nil
+						}),
+					}),
+					WithCompositeFinalizer(resource.NewNopFinalizer()),
+					WithCompositionSelector(CompositionSelectorFn(func(_ context.Context, cr resource.Composite) error {
+						cr.SetCompositionReference(&corev1.ObjectReference{})
+						return nil
+					})),
+					WithCompositionFetcher(CompositionFetcherFn(func(_ context.Context, _ resource.Composite) (*v1.Composition, error) {
+						c := &v1.Composition{Spec: v1.CompositionSpec{
+							Resources: []v1.ComposedTemplate{{}},
+						}}
+						return c, nil
+					})),
+					WithCompositionValidator(CompositionValidatorFn(func(_ *v1.Composition) error { return nil })),
+					WithConfigurator(ConfiguratorFn(func(_ context.Context, _ resource.Composite, _ *v1.Composition) error {
+						return nil
+					})),
+					WithRenderer(RendererFn(func(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) error {
+						return nil
+					})),
+					WithConnectionPublishers(managed.ConnectionPublisherFns{
+						PublishConnectionFn: func(ctx context.Context, o resource.ConnectionSecretOwner, c managed.ConnectionDetails) (published bool, err error) {
+							return false, nil
+						},
+					}),
+					WithLimits(Limits{MaxRenderedBytes: 1}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: true},
+			},
+		},
 		"ComposedResourcesReady": {
 			reason: "We should requeue after our poll interval if all of our composed resources are ready.",
 			args: args{
@@ -836,6 +1109,322 @@ func TestReconcile(t *testing.T) {
 				r: reconcile.Result{RequeueAfter: defaultPollInterval},
 			},
 		},
+		"ObserveOnlyResourcesReady": {
+			reason: "We should observe, but never render or apply, the existing resources of observe-only templates.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil),
+							MockUpdate: test.NewMockUpdateFn(nil, func(obj client.Object) error {
+								// We shouldn't record the name of an observed resource.
+								want := []corev1.ObjectReference{{APIVersion: "example.org/v1", Kind: "Network"}}
+								if diff := cmp.Diff(want, obj.(resource.Composite).GetResourceReferences()); diff != "" {
+									t.Errorf("Update(...): -want resource references, +got resource references:\n%s", diff)
+								}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+						Applicator: resource.ApplyFn(func(c context.Context, r client.Object, ao ...resource.ApplyOption) error {
+							// We should only apply the composite resource.
+							if _, ok := r.(resource.Composed); ok {
+								return errBoom
+							}
+							return nil
+						}),
+					}),
+					WithCompositeFinalizer(resource.NewNopFinalizer()),
+					WithCompositionSelector(CompositionSelectorFn(func(_ context.Context, cr resource.Composite) error {
+						cr.SetCompositionReference(&corev1.ObjectReference{})
+						return nil
+					})),
+					WithCompositionFetcher(CompositionFetcherFn(func(_ context.Context, _ resource.Composite) (*v1.Composition, error) {
+						c := &v1.Composition{Spec: v1.CompositionSpec{
+							Resources: []v1.ComposedTemplate{{ObserveOnly: &v1.ObserveOnly{Name: pointer.String("shared")}}},
+						}}
+						return c, nil
+					})),
+					WithCompositionValidator(CompositionValidatorFn(func(_ *v1.Composition) error { return nil })),
+					WithConfigurator(ConfiguratorFn(func(_ context.Context, _ resource.Composite, _ *v1.Composition) error {
+						return nil
+					})),
+					WithRenderer(RendererFn(func(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) error {
+						return errBoom
+					})),
+					WithObserver(ObserverFn(func(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) error {
+						cd.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Network"})
+						cd.SetName("shared")
+						return nil
+					})),
+					WithConnectionDetailsFetcher(ConnectionDetailsFetcherFn(func(ctx context.Context, _ resource.Composed, t v1.ComposedTemplate) (managed.ConnectionDetails, error) {
+						return nil, nil
+					})),
+					WithReadinessChecker(ReadinessCheckerFn(func(ctx context.Context, cd resource.Composed, t v1.ComposedTemplate) (ready bool, err error) {
+						return true, nil
+					})),
+					WithConnectionPublishers(managed.ConnectionPublisherFns{
+						PublishConnectionFn: func(ctx context.Context, o resource.ConnectionSecretOwner, got managed.ConnectionDetails) (published bool, err error) {
+							return false, nil
+						},
+					}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: defaultPollInterval},
+			},
+		},
+		"CustomExternalNamer": {
+			reason: "We should name composed resources that don't specify an external name using the supplied ExternalNamer.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet:          test.NewMockGetFn(nil),
+							MockUpdate:       test.NewMockUpdateFn(nil),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+						Applicator: resource.ApplyFn(func(c context.Context, r client.Object, ao ...resource.ApplyOption) error {
+							if _, ok := r.(resource.Composed); !ok {
+								return nil
+							}
+							if en := meta.GetExternalName(r); en != "org-db" {
+								t.Errorf("Apply(...): want external name %q, got %q", "org-db", en)
+							}
+							return nil
+						}),
+					}),
+					WithCompositeFinalizer(resource.NewNopFinalizer()),
+					WithCompositionSelector(CompositionSelectorFn(func(_ context.Context, cr resource.Composite) error {
+						cr.SetCompositionReference(&corev1.ObjectReference{})
+						return nil
+					})),
+					WithCompositionFetcher(CompositionFetcherFn(func(_ context.Context, _ resource.Composite) (*v1.Composition, error) {
+						c := &v1.Composition{Spec: v1.CompositionSpec{
+							Resources: []v1.ComposedTemplate{{Name: pointer.String("db")}},
+						}}
+						return c, nil
+					})),
+					WithCompositionValidator(CompositionValidatorFn(func(_ *v1.Composition) error { return nil })),
+					WithConfigurator(ConfiguratorFn(func(_ context.Context, _ resource.Composite, _ *v1.Composition) error {
+						return nil
+					})),
+					WithRenderer(RendererFn(func(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) error {
+						return nil
+					})),
+					WithExternalNamer(ExternalNamerFn(func(_ context.Context, _ resource.Composite, _ resource.Composed, t v1.ComposedTemplate) (string, error) {
+						return "org-" + *t.Name, nil
+					})),
+					WithConnectionDetailsFetcher(ConnectionDetailsFetcherFn(func(ctx context.Context, _ resource.Composed, t v1.ComposedTemplate) (managed.ConnectionDetails, error) {
+						return nil, nil
+					})),
+					WithReadinessChecker(ReadinessCheckerFn(func(ctx context.Context, cd resource.Composed, t v1.ComposedTemplate) (ready bool, err error) {
+						return true, nil
+					})),
+					WithConnectionPublishers(managed.ConnectionPublisherFns{
+						PublishConnectionFn: func(ctx context.Context, o resource.ConnectionSecretOwner, got managed.ConnectionDetails) (published bool, err error) {
+							return false, nil
+						},
+					}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: defaultPollInterval},
+			},
+		},
+		"ContinueAfterReadinessTimeout": {
+			reason: "We should consider a composed resource ready once it times out if its failure policy is Continue.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet:          test.NewMockGetFn(nil),
+							MockUpdate:       test.NewMockUpdateFn(nil),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+						Applicator: resource.ApplyFn(func(c context.Context, r client.Object, ao ...resource.ApplyOption) error {
+							return nil
+						}),
+					}),
+					WithCompositeFinalizer(resource.NewNopFinalizer()),
+					WithCompositionSelector(CompositionSelectorFn(func(_ context.Context, cr resource.Composite) error {
+						cr.SetCompositionReference(&corev1.ObjectReference{})
+						return nil
+					})),
+					WithCompositionFetcher(CompositionFetcherFn(func(_ context.Context, _ resource.Composite) (*v1.Composition, error) {
+						c := &v1.Composition{Spec: v1.CompositionSpec{
+							Resources: []v1.ComposedTemplate{{ReadinessTimeout: &v1.ReadinessTimeout{
+								Duration:      metav1.Duration{Duration: time.Minute},
+								FailurePolicy: &readinessContinue,
+							}}},
+						}}
+						return c, nil
+					})),
+					WithCompositionValidator(CompositionValidatorFn(func(_ *v1.Composition) error { return nil })),
+					WithConfigurator(ConfiguratorFn(func(_ context.Context, _ resource.Composite, _ *v1.Composition) error {
+						return nil
+					})),
+					WithRenderer(RendererFn(func(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) error {
+						// Our one resource was created long ago.
+						cd.SetName("slow")
+						cd.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-time.Hour)))
+						return nil
+					})),
+					WithConnectionDetailsFetcher(ConnectionDetailsFetcherFn(func(ctx context.Context, _ resource.Composed, t v1.ComposedTemplate) (managed.ConnectionDetails, error) {
+						return nil, nil
+					})),
+					WithReadinessChecker(ReadinessCheckerFn(func(ctx context.Context, cd resource.Composed, t v1.ComposedTemplate) (ready bool, err error) {
+						// Our one resource never becomes ready.
+						return false, nil
+					})),
+					WithConnectionPublishers(managed.ConnectionPublisherFns{
+						PublishConnectionFn: func(ctx context.Context, o resource.ConnectionSecretOwner, got managed.ConnectionDetails) (published bool, err error) {
+							return false, nil
+						},
+					}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: defaultPollInterval},
+			},
+		},
+		"ComposedResourceWaitingForGate": {
+			reason: "We should not create a composed resource until its gates are open, and the composite resource should not be ready while it waits.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+								// The gated resource doesn't exist yet.
+								if _, ok := obj.(*composed.Unstructured); ok {
+									return kerrors.NewNotFound(schema.GroupResource{}, "")
+								}
+								return nil
+							}),
+							MockUpdate: test.NewMockUpdateFn(nil),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(obj client.Object) error {
+								want := xpv1.Creating()
+								if got := obj.(resource.Composite).GetCondition(xpv1.TypeReady); !want.Equal(got) {
+									t.Errorf("StatusUpdate(...): want Ready condition %+v, got %+v", want, got)
+								}
+								return nil
+							}),
+						},
+						Applicator: resource.ApplyFn(func(c context.Context, r client.Object, ao ...resource.ApplyOption) error {
+							// The database's gate is closed because the
+							// network isn't ready, so we shouldn't create it.
+							if r.GetName() == "database" {
+								return errBoom
+							}
+							return nil
+						}),
+					}),
+					WithCompositeFinalizer(resource.NewNopFinalizer()),
+					WithCompositionSelector(CompositionSelectorFn(func(_ context.Context, cr resource.Composite) error {
+						cr.SetCompositionReference(&corev1.ObjectReference{})
+						return nil
+					})),
+					WithCompositionFetcher(CompositionFetcherFn(func(_ context.Context, _ resource.Composite) (*v1.Composition, error) {
+						c := &v1.Composition{Spec: v1.CompositionSpec{
+							Resources: []v1.ComposedTemplate{
+								{Name: pointer.String("network")},
+								{Name: pointer.String("database"), Gates: []v1.Gate{{ResourceName: "network"}}},
+							},
+						}}
+						return c, nil
+					})),
+					WithCompositionValidator(CompositionValidatorFn(func(_ *v1.Composition) error { return nil })),
+					WithConfigurator(ConfiguratorFn(func(_ context.Context, _ resource.Composite, _ *v1.Composition) error {
+						return nil
+					})),
+					WithRenderer(RendererFn(func(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) error {
+						cd.SetName(*t.Name)
+						return nil
+					})),
+					WithConnectionDetailsFetcher(ConnectionDetailsFetcherFn(func(ctx context.Context, _ resource.Composed, t v1.ComposedTemplate) (managed.ConnectionDetails, error) {
+						return nil, nil
+					})),
+					WithReadinessChecker(ReadinessCheckerFn(func(ctx context.Context, cd resource.Composed, t v1.ComposedTemplate) (ready bool, err error) {
+						return true, nil
+					})),
+					WithConnectionPublishers(managed.ConnectionPublisherFns{
+						PublishConnectionFn: func(ctx context.Context, o resource.ConnectionSecretOwner, got managed.ConnectionDetails) (published bool, err error) {
+							return false, nil
+						},
+					}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: true},
+			},
+		},
+		"MarkDegradedAfterReadinessTimeout": {
+			reason: "We should mark the composite resource unavailable, and explain why, once a composed resource times out if its failure policy is MarkDegraded.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet:    test.NewMockGetFn(nil),
+							MockUpdate: test.NewMockUpdateFn(nil),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(obj client.Object) error {
+								want := xpv1.Unavailable().WithMessage(fmt.Sprintf(msgFmtTimedOut, "slow"))
+								if got := obj.(resource.Composite).GetCondition(xpv1.TypeReady); !want.Equal(got) {
+									t.Errorf("StatusUpdate(...): want Ready condition %+v, got %+v", want, got)
+								}
+								return nil
+							}),
+						},
+						Applicator: resource.ApplyFn(func(c context.Context, r client.Object, ao ...resource.ApplyOption) error {
+							return nil
+						}),
+					}),
+					WithCompositeFinalizer(resource.NewNopFinalizer()),
+					WithCompositionSelector(CompositionSelectorFn(func(_ context.Context, cr resource.Composite) error {
+						cr.SetCompositionReference(&corev1.ObjectReference{})
+						return nil
+					})),
+					WithCompositionFetcher(CompositionFetcherFn(func(_ context.Context, _ resource.Composite) (*v1.Composition, error) {
+						c := &v1.Composition{Spec: v1.CompositionSpec{
+							Resources: []v1.ComposedTemplate{{ReadinessTimeout: &v1.ReadinessTimeout{
+								Duration:      metav1.Duration{Duration: time.Minute},
+								FailurePolicy: &readinessMarkDegraded,
+							}}},
+						}}
+						return c, nil
+					})),
+					WithCompositionValidator(CompositionValidatorFn(func(_ *v1.Composition) error { return nil })),
+					WithConfigurator(ConfiguratorFn(func(_ context.Context, _ resource.Composite, _ *v1.Composition) error {
+						return nil
+					})),
+					WithRenderer(RendererFn(func(ctx context.Context, cp resource.Composite, cd resource.Composed, t v1.ComposedTemplate) error {
+						// Our one resource was created long ago.
+						cd.SetName("slow")
+						cd.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-time.Hour)))
+						return nil
+					})),
+					WithConnectionDetailsFetcher(ConnectionDetailsFetcherFn(func(ctx context.Context, _ resource.Composed, t v1.ComposedTemplate) (managed.ConnectionDetails, error) {
+						return nil, nil
+					})),
+					WithReadinessChecker(ReadinessCheckerFn(func(ctx context.Context, cd resource.Composed, t v1.ComposedTemplate) (ready bool, err error) {
+						// Our one resource never becomes ready.
+						return false, nil
+					})),
+					WithConnectionPublishers(managed.ConnectionPublisherFns{
+						PublishConnectionFn: func(ctx context.Context, o resource.ConnectionSecretOwner, got managed.ConnectionDetails) (published bool, err error) {
+							return false, nil
+						},
+					}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: true},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -910,3 +1499,102 @@ func TestFilterToXRPatches(t *testing.T) {
 		})
 	}
 }
+
+func TestExternalNameMustNotChange(t *testing.T) {
+	withExternalName := func(en string) *fake.Composed {
+		cd := &fake.Composed{ObjectMeta: metav1.ObjectMeta{Name: "cool-composed"}}
+		meta.SetExternalName(cd, en)
+		return cd
+	}
+
+	type args struct {
+		current runtime.Object
+		desired runtime.Object
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"CurrentHasNoExternalName": {
+			reason: "We should allow an external name to be set if the current object has none.",
+			args: args{
+				current: &fake.Composed{},
+				desired: withExternalName("cool"),
+			},
+			want: nil,
+		},
+		"Unchanged": {
+			reason: "We should allow an external name to be applied unchanged.",
+			args: args{
+				current: withExternalName("cool"),
+				desired: withExternalName("cool"),
+			},
+			want: nil,
+		},
+		"Changed": {
+			reason: "We should return an error if the desired external name differs from the current one.",
+			args: args{
+				current: withExternalName("cool"),
+				desired: withExternalName("wat"),
+			},
+			want: errors.Errorf(errFmtExternalNameConflict, "cool-composed", "cool", "wat"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := externalNameMustNotChange()(context.Background(), tc.args.current, tc.args.desired)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nexternalNameMustNotChange(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAddDefaultLabels(t *testing.T) {
+	type args struct {
+		o metav1.Object
+		l map[string]string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   map[string]string
+	}{
+		"NoDefaults": {
+			reason: "An object should be unchanged when there are no default labels.",
+			args: args{
+				o: &metav1.ObjectMeta{},
+			},
+			want: nil,
+		},
+		"AddDefaults": {
+			reason: "Default labels should be added to an object that doesn't have them.",
+			args: args{
+				o: &metav1.ObjectMeta{Labels: map[string]string{"app": "db"}},
+				l: map[string]string{"example.org/cluster": "prod"},
+			},
+			want: map[string]string{"app": "db", "example.org/cluster": "prod"},
+		},
+		"ExistingTakePrecedence": {
+			reason: "Labels an object already has should take precedence over default labels.",
+			args: args{
+				o: &metav1.ObjectMeta{Labels: map[string]string{"example.org/cluster": "staging"}},
+				l: map[string]string{"example.org/cluster": "prod", "example.org/env": "dev"},
+			},
+			want: map[string]string{"example.org/cluster": "staging", "example.org/env": "dev"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			addDefaultLabels(tc.args.o, tc.args.l)
+			if diff := cmp.Diff(tc.want, tc.args.o.GetLabels()); diff != "" {
+				t.Errorf("\n%s\naddDefaultLabels(...): -want labels, +got labels:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
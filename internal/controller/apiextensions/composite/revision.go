@@ -40,10 +40,29 @@ func AsCompositionSpec(crs v1alpha1.CompositionRevisionSpec) v1.CompositionSpec
 		},
 		PatchSets:                         make([]v1.PatchSet, len(crs.PatchSets)),
 		Resources:                         make([]v1.ComposedTemplate, len(crs.Resources)),
+		BaseCompositionRef:                crs.BaseCompositionRef,
 		WriteConnectionSecretsToNamespace: crs.WriteConnectionSecretsToNamespace,
 		PublishConnectionDetailsWithStoreConfigRef: crs.PublishConnectionDetailsWithStoreConfigRef,
 	}
 
+	if crs.WriteConnectionSecretsToNamespaceFrom != nil {
+		cs.WriteConnectionSecretsToNamespaceFrom = AsCompositionCombine(*crs.WriteConnectionSecretsToNamespaceFrom)
+	}
+
+	if len(crs.CompositeTypeVersions) > 0 {
+		cs.CompositeTypeVersions = make([]v1.CompositeTypeVersion, len(crs.CompositeTypeVersions))
+		for i := range crs.CompositeTypeVersions {
+			cs.CompositeTypeVersions[i] = AsCompositionCompositeTypeVersion(crs.CompositeTypeVersions[i])
+		}
+	}
+
+	if crs.PropagateMetadata != nil {
+		cs.PropagateMetadata = &v1.MetadataPropagation{
+			Labels:      crs.PropagateMetadata.Labels,
+			Annotations: crs.PropagateMetadata.Annotations,
+		}
+	}
+
 	for i := range crs.PatchSets {
 		cs.PatchSets[i] = AsCompositionPatchSet(crs.PatchSets[i])
 	}
@@ -55,6 +74,27 @@ func AsCompositionSpec(crs v1alpha1.CompositionRevisionSpec) v1.CompositionSpec
 	return cs
 }
 
+// AsCompositionCompositeTypeVersion translates a composition revision's
+// composite type version to a composition composite type version.
+func AsCompositionCompositeTypeVersion(rv v1alpha1.CompositeTypeVersion) v1.CompositeTypeVersion {
+	v := v1.CompositeTypeVersion{APIVersion: rv.APIVersion}
+	if len(rv.PatchOverrides) == 0 {
+		return v
+	}
+
+	v.PatchOverrides = make([]v1.PatchOverride, len(rv.PatchOverrides))
+	for i, ro := range rv.PatchOverrides {
+		v.PatchOverrides[i] = v1.PatchOverride{
+			ResourceName: ro.ResourceName,
+			Patches:      make([]v1.Patch, len(ro.Patches)),
+		}
+		for j := range ro.Patches {
+			v.PatchOverrides[i].Patches[j] = AsCompositionPatch(ro.Patches[j])
+		}
+	}
+	return v
+}
+
 // AsCompositionPatchSet translates a composition revision's patch set to a
 // composition patch set.
 func AsCompositionPatchSet(rps v1alpha1.PatchSet) v1.PatchSet {
@@ -80,6 +120,22 @@ func AsCompositionComposedTemplate(rct v1alpha1.ComposedTemplate) v1.ComposedTem
 		ReadinessChecks:   make([]v1.ReadinessCheck, len(rct.ReadinessChecks)),
 	}
 
+	if rct.ReadinessTimeout != nil {
+		ct.ReadinessTimeout = &v1.ReadinessTimeout{Duration: rct.ReadinessTimeout.Duration}
+		if rct.ReadinessTimeout.FailurePolicy != nil {
+			p := v1.ReadinessFailurePolicy(*rct.ReadinessTimeout.FailurePolicy)
+			ct.ReadinessTimeout.FailurePolicy = &p
+		}
+	}
+
+	if rct.ObserveOnly != nil {
+		ct.ObserveOnly = &v1.ObserveOnly{
+			Name:        rct.ObserveOnly.Name,
+			Namespace:   rct.ObserveOnly.Namespace,
+			MatchLabels: rct.ObserveOnly.MatchLabels,
+		}
+	}
+
 	for i := range rct.Patches {
 		ct.Patches[i] = AsCompositionPatch(rct.Patches[i])
 	}
@@ -92,9 +148,48 @@ func AsCompositionComposedTemplate(rct v1alpha1.ComposedTemplate) v1.ComposedTem
 		ct.ReadinessChecks[i] = AsCompositionReadinessCheck(rct.ReadinessChecks[i])
 	}
 
+	for i := range rct.Gates {
+		ct.Gates = append(ct.Gates, AsCompositionGate(rct.Gates[i]))
+	}
+
 	return ct
 }
 
+// AsCompositionGate translates a composition revision's gate to a composition
+// gate.
+func AsCompositionGate(rg v1alpha1.Gate) v1.Gate {
+	g := v1.Gate{ResourceName: rg.ResourceName}
+
+	if rg.Condition != nil {
+		g.Condition = &v1.GateCondition{Type: rg.Condition.Type, Status: rg.Condition.Status}
+	}
+
+	for i := range rg.Checks {
+		g.Checks = append(g.Checks, AsCompositionReadinessCheck(rg.Checks[i]))
+	}
+
+	return g
+}
+
+// AsCompositionCombine translates a composition revision's combine to a
+// composition combine.
+func AsCompositionCombine(rc v1alpha1.Combine) *v1.Combine {
+	c := &v1.Combine{
+		Strategy:  v1.CombineStrategy(rc.Strategy),
+		Variables: make([]v1.CombineVariable, len(rc.Variables)),
+	}
+
+	if rc.String != nil {
+		c.String = &v1.StringCombine{Format: rc.String.Format}
+	}
+
+	for i := range rc.Variables {
+		c.Variables[i].FromFieldPath = rc.Variables[i].FromFieldPath
+	}
+
+	return c
+}
+
 // AsCompositionPatch translates a composition revision's patch to a
 // composition patch.
 func AsCompositionPatch(rp v1alpha1.Patch) v1.Patch {
@@ -107,27 +202,23 @@ func AsCompositionPatch(rp v1alpha1.Patch) v1.Patch {
 	}
 
 	if rp.Combine != nil {
-		p.Combine = &v1.Combine{
-			Strategy:  v1.CombineStrategy(rp.Combine.Strategy),
-			Variables: make([]v1.CombineVariable, len(rp.Combine.Variables)),
-		}
-
-		if rp.Combine.String != nil {
-			p.Combine.String = &v1.StringCombine{Format: rp.Combine.String.Format}
-		}
-
-		for i := range rp.Combine.Variables {
-			p.Combine.Variables[i].FromFieldPath = rp.Combine.Variables[i].FromFieldPath
-		}
+		p.Combine = AsCompositionCombine(*rp.Combine)
 	}
 
 	for i := range rp.Transforms {
 		p.Transforms[i] = AsCompositionTransform(rp.Transforms[i])
 	}
 
-	if rp.Policy != nil && rp.Policy.FromFieldPath != nil {
-		pol := v1.FromFieldPathPolicy(*rp.Policy.FromFieldPath)
-		p.Policy = &v1.PatchPolicy{FromFieldPath: &pol}
+	if rp.Policy != nil && (rp.Policy.FromFieldPath != nil || rp.Policy.TransformFailure != nil) {
+		p.Policy = &v1.PatchPolicy{TransformFailureDefault: rp.Policy.TransformFailureDefault}
+		if rp.Policy.FromFieldPath != nil {
+			pol := v1.FromFieldPathPolicy(*rp.Policy.FromFieldPath)
+			p.Policy.FromFieldPath = &pol
+		}
+		if rp.Policy.TransformFailure != nil {
+			pol := v1.TransformFailurePolicy(*rp.Policy.TransformFailure)
+			p.Policy.TransformFailure = &pol
+		}
 	}
 
 	return p
@@ -138,17 +229,44 @@ func AsCompositionPatch(rp v1alpha1.Patch) v1.Patch {
 func AsCompositionTransform(rt v1alpha1.Transform) v1.Transform {
 	t := v1.Transform{Type: v1.TransformType(rt.Type)}
 	if rt.Math != nil {
-		t.Math = &v1.MathTransform{Multiply: rt.Math.Multiply}
+		t.Math = &v1.MathTransform{
+			Type:     v1.MathTransformType(rt.Math.Type),
+			Multiply: rt.Math.Multiply,
+			Add:      rt.Math.Add,
+			Subtract: rt.Math.Subtract,
+			Divide:   rt.Math.Divide,
+			Modulo:   rt.Math.Modulo,
+			ClampMin: rt.Math.ClampMin,
+			ClampMax: rt.Math.ClampMax,
+		}
 	}
 	if rt.Map != nil {
 		t.Map = &v1.MapTransform{Pairs: rt.Map.Pairs}
 	}
+	if rt.Match != nil {
+		t.Match = &v1.MatchTransform{
+			Patterns:      make([]v1.MatchTransformPattern, len(rt.Match.Patterns)),
+			FallbackValue: rt.Match.FallbackValue,
+		}
+		for i, p := range rt.Match.Patterns {
+			t.Match.Patterns[i] = v1.MatchTransformPattern{
+				Type:    v1.MatchTransformPatternType(p.Type),
+				Literal: p.Literal,
+				Regexp:  p.Regexp,
+				Result:  p.Result,
+			}
+		}
+	}
 	if rt.String != nil {
 		t.String = &v1.StringTransform{Type: v1.StringTransformFormat,
 			Format: &rt.String.Format}
 	}
 	if rt.Convert != nil {
 		t.Convert = &v1.ConvertTransform{ToType: rt.Convert.ToType}
+		if rt.Convert.Format != nil {
+			f := v1.ConvertTransformFormat(*rt.Convert.Format)
+			t.Convert.Format = &f
+		}
 	}
 	return t
 }
@@ -0,0 +1,188 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+// A SecretPatchPolicy determines what happens when a Composition patches
+// secret data into a field that is not secret.
+type SecretPatchPolicy string
+
+// Secret patch policies.
+const (
+	// SecretPatchPolicyAllow composes resources regardless of whether they
+	// are patched with secret data.
+	SecretPatchPolicyAllow SecretPatchPolicy = "Allow"
+
+	// SecretPatchPolicyWarn composes resources, but emits a warning event
+	// for the composite resource.
+	SecretPatchPolicyWarn SecretPatchPolicy = "Warn"
+
+	// SecretPatchPolicyDeny refuses to compose any resources.
+	SecretPatchPolicyDeny SecretPatchPolicy = "Deny"
+)
+
+// Secret data fields.
+var secretDataFields = []string{"data", "stringData"}
+
+// SecretPatches returns a description of each patch of the supplied templates
+// that would copy the data of a composed Secret into a field that is not
+// secret. Patching a Secret's data into the composite resource is always
+// considered a leak, because anyone who can read the composite resource (or
+// its claim) could read it. So is patching a composite resource field that was
+// patched from a Secret's data into another composed resource, unless that
+// resource is also a Secret and the field is part of its data.
+func SecretPatches(ct []v1.ComposedTemplate) []string { // nolint:gocyclo
+	// NOTE: Secret data written to connection details doesn't concern us;
+	// connection details are always written to a Secret.
+
+	out := make([]string, 0)
+	tainted := make([]string, 0)
+	for i, t := range ct {
+		if !isSecret(t) {
+			continue
+		}
+		for j, p := range t.Patches {
+			for _, from := range patchSources(p, v1.PatchTypeToCompositeFieldPath, v1.PatchTypeCombineToComposite) {
+				if !isSecretDataPath(from) {
+					continue
+				}
+				to := patchDestination(p)
+				tainted = append(tainted, to)
+				out = append(out, fmt.Sprintf("%s patch %d copies secret data from %q to composite resource field %q", templateName(t, i), j, from, to))
+			}
+		}
+	}
+
+	if len(tainted) == 0 {
+		return out
+	}
+
+	for i, t := range ct {
+		secret := isSecret(t)
+		for j, p := range t.Patches {
+			to := patchDestination(p)
+			if secret && isSecretDataPath(to) {
+				continue
+			}
+			for _, from := range patchSources(p, v1.PatchTypeFromCompositeFieldPath, v1.PatchTypeCombineFromComposite) {
+				if !overlapsAny(from, tainted) {
+					continue
+				}
+				out = append(out, fmt.Sprintf("%s patch %d copies secret data from composite resource field %q to %q", templateName(t, i), j, from, to))
+			}
+		}
+	}
+
+	return out
+}
+
+// patchSources returns the field paths a patch of one of the supplied types
+// reads from.
+func patchSources(p v1.Patch, types ...v1.PatchType) []string {
+	match := false
+	for _, t := range types {
+		if p.Type == t {
+			match = true
+		}
+	}
+	if !match {
+		return nil
+	}
+
+	switch p.Type { // nolint:exhaustive
+	case v1.PatchTypeCombineFromComposite, v1.PatchTypeCombineToComposite:
+		if p.Combine == nil {
+			return nil
+		}
+		out := make([]string, len(p.Combine.Variables))
+		for i, v := range p.Combine.Variables {
+			out[i] = v.FromFieldPath
+		}
+		return out
+	}
+	if p.FromFieldPath == nil {
+		return nil
+	}
+	return []string{*p.FromFieldPath}
+}
+
+// patchDestination returns the field path a patch writes to.
+func patchDestination(p v1.Patch) string {
+	if p.ToFieldPath != nil {
+		return *p.ToFieldPath
+	}
+	if p.FromFieldPath != nil {
+		return *p.FromFieldPath
+	}
+	return ""
+}
+
+// isSecret returns true if the supplied template composes a Secret.
+func isSecret(t v1.ComposedTemplate) bool {
+	tm := metav1.TypeMeta{}
+	if err := json.Unmarshal(t.Base.Raw, &tm); err != nil {
+		return false
+	}
+	return tm.APIVersion == "v1" && tm.Kind == "Secret"
+}
+
+// isSecretDataPath returns true if the supplied field path is within the
+// data of a Secret.
+func isSecretDataPath(path string) bool {
+	for _, f := range secretDataFields {
+		if contains(f, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlapsAny returns true if the supplied field path is any of the supplied
+// paths, is within one of them, or contains one of them.
+func overlapsAny(path string, paths []string) bool {
+	for _, p := range paths {
+		if contains(p, path) || contains(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns true if field path b is, or is within, field path a.
+func contains(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	return b == a || strings.HasPrefix(b, a+".") || strings.HasPrefix(b, a+"[")
+}
+
+func templateName(t v1.ComposedTemplate, i int) string {
+	if t.Name != nil {
+		return strconv.Quote(*t.Name)
+	}
+	return fmt.Sprintf("resource %d", i)
+}
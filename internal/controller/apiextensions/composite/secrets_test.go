@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestSecretPatches(t *testing.T) {
+	secret := runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Secret"}`)}
+	cm := runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap"}`)}
+
+	toXR := v1.Patch{
+		Type:          v1.PatchTypeToCompositeFieldPath,
+		FromFieldPath: pointer.String("data.password"),
+		ToFieldPath:   pointer.String("status.password"),
+	}
+
+	cases := map[string]struct {
+		reason string
+		ct     []v1.ComposedTemplate
+		want   []string
+	}{
+		"NoSecrets": {
+			reason: "Patches between resources that aren't Secrets should not be reported.",
+			ct: []v1.ComposedTemplate{{
+				Base:    cm,
+				Patches: []v1.Patch{toXR},
+			}},
+			want: []string{},
+		},
+		"SecretMetadata": {
+			reason: "Patches from a Secret's metadata should not be reported.",
+			ct: []v1.ComposedTemplate{{
+				Base: secret,
+				Patches: []v1.Patch{{
+					Type:          v1.PatchTypeToCompositeFieldPath,
+					FromFieldPath: pointer.String("metadata.name"),
+					ToFieldPath:   pointer.String("status.secretName"),
+				}},
+			}},
+			want: []string{},
+		},
+		"SecretDataToComposite": {
+			reason: "Patches from a Secret's data to the composite resource should be reported.",
+			ct: []v1.ComposedTemplate{{
+				Name:    pointer.String("secret"),
+				Base:    secret,
+				Patches: []v1.Patch{toXR},
+			}},
+			want: []string{
+				`"secret" patch 0 copies secret data from "data.password" to composite resource field "status.password"`,
+			},
+		},
+		"CombineSecretDataToComposite": {
+			reason: "Combine patches that read a Secret's data should be reported.",
+			ct: []v1.ComposedTemplate{{
+				Base: secret,
+				Patches: []v1.Patch{{
+					Type: v1.PatchTypeCombineToComposite,
+					Combine: &v1.Combine{Variables: []v1.CombineVariable{
+						{FromFieldPath: "metadata.name"},
+						{FromFieldPath: "stringData[password]"},
+					}},
+					ToFieldPath: pointer.String("status.dsn"),
+				}},
+			}},
+			want: []string{
+				`resource 0 patch 0 copies secret data from "stringData[password]" to composite resource field "status.dsn"`,
+			},
+		},
+		"SecretDataToConfigMap": {
+			reason: "Patches that copy composite resource fields derived from a Secret's data to another resource should be reported.",
+			ct: []v1.ComposedTemplate{
+				{
+					Base:    secret,
+					Patches: []v1.Patch{toXR},
+				},
+				{
+					Base: cm,
+					Patches: []v1.Patch{
+						{
+							Type:          v1.PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.String("status"),
+							ToFieldPath:   pointer.String("metadata.annotations[status]"),
+						},
+						{
+							Type:          v1.PatchTypeFromCompositeFieldPath,
+							FromFieldPath: pointer.String("spec.region"),
+							ToFieldPath:   pointer.String("data.region"),
+						},
+					},
+				},
+			},
+			want: []string{
+				`resource 0 patch 0 copies secret data from "data.password" to composite resource field "status.password"`,
+				`resource 1 patch 0 copies secret data from composite resource field "status" to "metadata.annotations[status]"`,
+			},
+		},
+		"SecretDataToSecret": {
+			reason: "Patches that copy composite resource fields derived from a Secret's data to another Secret's data should only be reported once.",
+			ct: []v1.ComposedTemplate{
+				{
+					Base:    secret,
+					Patches: []v1.Patch{toXR},
+				},
+				{
+					Base: secret,
+					Patches: []v1.Patch{{
+						Type:          v1.PatchTypeFromCompositeFieldPath,
+						FromFieldPath: pointer.String("status.password"),
+						ToFieldPath:   pointer.String("stringData.password"),
+					}},
+				},
+			},
+			want: []string{
+				`resource 0 patch 0 copies secret data from "data.password" to composite resource field "status.password"`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := SecretPatches(tc.ct)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nSecretPatches(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"context"
+
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+// CompositionUsers returns a MapFunc that maps a Composition or
+// CompositionRevision to requests to reconcile the composite resources of the
+// supplied kind that use it, so that they promptly pick up any changes to it.
+// Composite resources that use a Composition that inherits from a changed
+// Composition are also reconciled. Composite resources that can't be listed
+// pick up changes the next time they are polled.
+func CompositionUsers(c client.Reader, of resource.CompositeKind) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		var name string
+		var ref v1.TypeReference
+		names := map[string]bool{}
+		switch o := obj.(type) {
+		case *v1.Composition:
+			name, ref = o.GetName(), o.Spec.CompositeTypeRef
+
+			// A Composition and its base are compatible with the same
+			// kind of composite resource. Compositions we can't list
+			// pick up changes to their base the next time they're used.
+			inheriting, _ := InheritingCompositions(context.TODO(), c, name)
+			for _, n := range inheriting {
+				names[n] = true
+			}
+		case *v1alpha1.CompositionRevision:
+			name = o.GetLabels()[v1alpha1.LabelCompositionName]
+			ref = v1.TypeReference{APIVersion: o.Spec.CompositeTypeRef.APIVersion, Kind: o.Spec.CompositeTypeRef.Kind}
+		default:
+			return nil
+		}
+
+		// A Composition may only be used by composite resources of the kind
+		// it composes. We compare only the group and kind, because a
+		// Composition may be used by any version of that kind.
+		gvk := schema.GroupVersionKind(of)
+		if name == "" || schema.FromAPIVersionAndKind(ref.APIVersion, ref.Kind).GroupKind() != gvk.GroupKind() {
+			return nil
+		}
+		names[name] = true
+
+		l := &kunstructured.UnstructuredList{}
+		l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(context.TODO(), l); err != nil {
+			return nil
+		}
+
+		reqs := make([]reconcile.Request, 0)
+		for i := range l.Items {
+			cp := &composite.Unstructured{Unstructured: l.Items[i]}
+			if r := cp.GetCompositionReference(); r != nil && names[r.Name] {
+				reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: cp.GetName()}})
+			}
+		}
+		return reqs
+	}
+}
+
+// InheritingCompositionsOf returns a MapFunc that maps a Composition to
+// requests to reconcile the Compositions that inherit from it, so that they
+// promptly pick up any changes to it.
+func InheritingCompositionsOf(c client.Reader) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		if _, ok := obj.(*v1.Composition); !ok {
+			return nil
+		}
+		names, err := InheritingCompositions(context.TODO(), c, obj.GetName())
+		if err != nil {
+			return nil
+		}
+		reqs := make([]reconcile.Request, len(names))
+		for i, n := range names {
+			reqs[i] = reconcile.Request{NamespacedName: types.NamespacedName{Name: n}}
+		}
+		return reqs
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composite
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+)
+
+func TestCompositionUsers(t *testing.T) {
+	errBoom := errors.New("boom")
+	of := resource.CompositeKind(schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "XDatabase"})
+
+	xr := func(name, comp string) kunstructured.Unstructured {
+		cp := composite.New()
+		cp.SetName(name)
+		if comp != "" {
+			cp.SetCompositionReference(&corev1.ObjectReference{Name: comp})
+		}
+		return cp.Unstructured
+	}
+
+	// Our mock lists five composite resources, two of which use the
+	// Composition named "cool" and one of which uses the Composition named
+	// "cooler", which inherits from "cool".
+	list := test.NewMockListFn(nil, func(obj client.ObjectList) error {
+		if l, ok := obj.(*v1.CompositionList); ok {
+			l.Items = []v1.Composition{{
+				ObjectMeta: metav1.ObjectMeta{Name: "cooler"},
+				Spec:       v1.CompositionSpec{BaseCompositionRef: &xpv1.Reference{Name: "cool"}},
+			}}
+			return nil
+		}
+		l := obj.(*kunstructured.UnstructuredList)
+		if l.GetKind() != "XDatabaseList" {
+			return errors.Errorf("unexpected list kind %q", l.GetKind())
+		}
+		l.Items = []kunstructured.Unstructured{xr("a", "cool"), xr("b", "other"), xr("c", ""), xr("d", "cool"), xr("e", "cooler")}
+		return nil
+	})
+	want := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "a"}},
+		{NamespacedName: types.NamespacedName{Name: "d"}},
+	}
+	wantInheriting := append(want, reconcile.Request{NamespacedName: types.NamespacedName{Name: "e"}})
+
+	type args struct {
+		client client.Reader
+		obj    client.Object
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   []reconcile.Request
+	}{
+		"NotAComposition": {
+			reason: "Objects that aren't Compositions or CompositionRevisions should map to no requests.",
+			args: args{
+				client: &test.MockClient{MockList: list},
+				obj:    &v1.CompositeResourceDefinition{},
+			},
+			want: nil,
+		},
+		"DifferentKind": {
+			reason: "A Composition of a different kind of composite resource should map to no requests.",
+			args: args{
+				client: &test.MockClient{MockList: list},
+				obj: &v1.Composition{
+					ObjectMeta: metav1.ObjectMeta{Name: "cool"},
+					Spec:       v1.CompositionSpec{CompositeTypeRef: v1.TypeReference{APIVersion: "example.org/v1", Kind: "XCache"}},
+				},
+			},
+			want: nil,
+		},
+		"ListError": {
+			reason: "A Composition should map to no requests if we can't list composite resources.",
+			args: args{
+				client: &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+				obj: &v1.Composition{
+					ObjectMeta: metav1.ObjectMeta{Name: "cool"},
+					Spec:       v1.CompositionSpec{CompositeTypeRef: v1.TypeReference{APIVersion: "example.org/v1", Kind: "XDatabase"}},
+				},
+			},
+			want: nil,
+		},
+		"Composition": {
+			reason: "A Composition should map to requests for the composite resources that use it, or a Composition that inherits from it.",
+			args: args{
+				client: &test.MockClient{MockList: list},
+				obj: &v1.Composition{
+					ObjectMeta: metav1.ObjectMeta{Name: "cool"},
+					Spec:       v1.CompositionSpec{CompositeTypeRef: v1.TypeReference{APIVersion: "example.org/v1beta1", Kind: "XDatabase"}},
+				},
+			},
+			want: wantInheriting,
+		},
+		"CompositionRevision": {
+			reason: "A CompositionRevision should map to requests for the composite resources that use its Composition.",
+			args: args{
+				client: &test.MockClient{MockList: list},
+				obj: &v1alpha1.CompositionRevision{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "cool-abc123",
+						Labels: map[string]string{v1alpha1.LabelCompositionName: "cool"},
+					},
+					Spec: v1alpha1.CompositionRevisionSpec{CompositeTypeRef: v1alpha1.TypeReference{APIVersion: "example.org/v1", Kind: "XDatabase"}},
+				},
+			},
+			want: want,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := CompositionUsers(tc.args.client, of)(tc.args.obj)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nCompositionUsers(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
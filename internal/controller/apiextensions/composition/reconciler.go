@@ -25,10 +25,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
-	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
@@ -39,6 +40,9 @@ import (
 
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+	"github.com/crossplane/crossplane/internal/controller/apiextensions/composite"
+	apiextensionscontroller "github.com/crossplane/crossplane/internal/controller/apiextensions/controller"
+	"github.com/crossplane/crossplane/internal/events"
 )
 
 const (
@@ -48,6 +52,7 @@ const (
 // Error strings
 const (
 	errGet             = "cannot get Composition"
+	errResolveBases    = "cannot resolve base Compositions"
 	errListRevs        = "cannot list CompositionRevisions"
 	errCreateRev       = "cannot create CompositionRevision"
 	errUpdateRevStatus = "cannot update CompositionRevision status"
@@ -61,17 +66,19 @@ const (
 
 // Setup adds a controller that reconciles Compositions by creating new
 // CompositionRevisions for each revision of the Composition's spec.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
+func Setup(mgr ctrl.Manager, o apiextensionscontroller.Options) error {
 	name := "revisions/" + strings.ToLower(v1.CompositionGroupKind)
 
 	r := NewReconciler(mgr,
 		WithLogger(o.Logger.WithValues("controller", name)),
-		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		WithRecorder(events.NewDedupingRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)), o.EventSuppressionWindow)))
 
+	// A Composition is revised when any base it inherits from changes.
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		For(&v1.Composition{}).
 		Owns(&v1alpha1.CompositionRevision{}).
+		Watches(&source.Kind{Type: &v1.Composition{}}, handler.EnqueueRequestsFromMapFunc(composite.InheritingCompositionsOf(mgr.GetClient()))).
 		WithOptions(o.ForControllerRuntime()).
 		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
 }
@@ -139,7 +146,17 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, nil
 	}
 
-	currentHash := comp.Spec.Hash()
+	// Revisions record the spec of a Composition with any bases it inherits
+	// from merged into it, so that an XR pinned to a revision isn't
+	// affected by later changes to those bases.
+	resolved, err := composite.ResolveBases(ctx, r.client, comp)
+	if err != nil {
+		log.Debug(errResolveBases, "error", err)
+		r.record.Event(comp, event.Warning(reasonCreateRev, errors.Wrap(err, errResolveBases)))
+		return reconcile.Result{}, errors.Wrap(err, errResolveBases)
+	}
+
+	currentHash := resolved.Spec.Hash()
 
 	log = log.WithValues(
 		"uid", comp.GetUID(),
@@ -193,7 +210,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, nil
 	}
 
-	if err := r.client.Create(ctx, NewCompositionRevision(comp, latestRev+1, currentHash)); err != nil {
+	if err := r.client.Create(ctx, NewCompositionRevision(resolved, latestRev+1, currentHash)); err != nil {
 		log.Debug(errCreateRev, "error", err)
 		r.record.Event(comp, event.Warning(reasonCreateRev, err))
 		return reconcile.Result{}, errors.Wrap(err, errCreateRev)
@@ -25,6 +25,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -33,6 +34,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
+
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
 )
@@ -119,6 +121,29 @@ func TestReconcile(t *testing.T) {
 		},
 	}
 
+	// Inherits from the below base composition.
+	child := &v1.Composition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cool-child",
+			UID:  types.UID("no-you-uid"),
+		},
+		Spec: v1.CompositionSpec{BaseCompositionRef: &xpv1.Reference{Name: "cool-base"}},
+	}
+	base := &v1.Composition{
+		ObjectMeta: metav1.ObjectMeta{Name: "cool-base"},
+		Spec:       v1.CompositionSpec{WriteConnectionSecretsToNamespace: pointer.String("cool-namespace")},
+	}
+
+	// getChild gets the above child composition, or its base.
+	getChild := func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+		if key.Name == base.GetName() {
+			*obj.(*v1.Composition) = *base
+			return nil
+		}
+		*obj.(*v1.Composition) = *child
+		return nil
+	}
+
 	type args struct {
 		mgr  manager.Manager
 		opts []ReconcilerOption
@@ -255,6 +280,25 @@ func TestReconcile(t *testing.T) {
 				err: errors.Wrap(errBoom, errUpdateRevStatus),
 			},
 		},
+		"ResolveBasesError": {
+			reason: "We should return any error encountered while resolving the bases of a Composition.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+							if key.Name == base.GetName() {
+								return errBoom
+							}
+							*obj.(*v1.Composition) = *child
+							return nil
+						},
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errors.Wrapf(errBoom, "cannot get base Composition %q", base.GetName()), errResolveBases),
+			},
+		},
 		"CreateCompositionRevisionError": {
 			reason: "We should return any error encountered while creating a CompositionRevision.",
 			args: args{
@@ -320,6 +364,32 @@ func TestReconcile(t *testing.T) {
 				err: nil,
 			},
 		},
+		"SuccessfulCreationWithBase": {
+			reason: "We should create a CompositionRevision with the resolved spec of a Composition that inherits from a base.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet:  getChild,
+						MockList: test.NewMockListFn(nil),
+						MockCreate: test.NewMockCreateFn(nil, func(got client.Object) error {
+							resolved := child.DeepCopy()
+							resolved.Spec = v1.CompositionSpec{WriteConnectionSecretsToNamespace: pointer.String("cool-namespace")}
+							want := NewCompositionRevision(resolved, 1, resolved.Spec.Hash())
+
+							if diff := cmp.Diff(want, got); diff != "" {
+								t.Errorf("Create(): -want, +got:\n%s", diff)
+							}
+
+							return nil
+						}),
+					},
+				},
+			},
+			want: want{
+				r:   reconcile.Result{},
+				err: nil,
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -57,10 +57,29 @@ func NewCompositionRevisionSpec(cs v1.CompositionSpec, revision int64) v1alpha1.
 		},
 		PatchSets:                         make([]v1alpha1.PatchSet, len(cs.PatchSets)),
 		Resources:                         make([]v1alpha1.ComposedTemplate, len(cs.Resources)),
+		BaseCompositionRef:                cs.BaseCompositionRef,
 		WriteConnectionSecretsToNamespace: cs.WriteConnectionSecretsToNamespace,
 		PublishConnectionDetailsWithStoreConfigRef: cs.PublishConnectionDetailsWithStoreConfigRef,
 	}
 
+	if cs.WriteConnectionSecretsToNamespaceFrom != nil {
+		rs.WriteConnectionSecretsToNamespaceFrom = NewCompositionRevisionCombine(*cs.WriteConnectionSecretsToNamespaceFrom)
+	}
+
+	if len(cs.CompositeTypeVersions) > 0 {
+		rs.CompositeTypeVersions = make([]v1alpha1.CompositeTypeVersion, len(cs.CompositeTypeVersions))
+		for i := range cs.CompositeTypeVersions {
+			rs.CompositeTypeVersions[i] = NewCompositionRevisionCompositeTypeVersion(cs.CompositeTypeVersions[i])
+		}
+	}
+
+	if cs.PropagateMetadata != nil {
+		rs.PropagateMetadata = &v1alpha1.MetadataPropagation{
+			Labels:      cs.PropagateMetadata.Labels,
+			Annotations: cs.PropagateMetadata.Annotations,
+		}
+	}
+
 	for i := range cs.PatchSets {
 		rs.PatchSets[i] = NewCompositionRevisionPatchSet(cs.PatchSets[i])
 	}
@@ -72,6 +91,27 @@ func NewCompositionRevisionSpec(cs v1.CompositionSpec, revision int64) v1alpha1.
 	return rs
 }
 
+// NewCompositionRevisionCompositeTypeVersion translates a composition's
+// composite type version to a composition revision composite type version.
+func NewCompositionRevisionCompositeTypeVersion(v v1.CompositeTypeVersion) v1alpha1.CompositeTypeVersion {
+	rv := v1alpha1.CompositeTypeVersion{APIVersion: v.APIVersion}
+	if len(v.PatchOverrides) == 0 {
+		return rv
+	}
+
+	rv.PatchOverrides = make([]v1alpha1.PatchOverride, len(v.PatchOverrides))
+	for i, o := range v.PatchOverrides {
+		rv.PatchOverrides[i] = v1alpha1.PatchOverride{
+			ResourceName: o.ResourceName,
+			Patches:      make([]v1alpha1.Patch, len(o.Patches)),
+		}
+		for j := range o.Patches {
+			rv.PatchOverrides[i].Patches[j] = NewCompositionRevisionPatch(o.Patches[j])
+		}
+	}
+	return rv
+}
+
 // NewCompositionRevisionPatchSet translates a composition's patch set to a
 // composition revision patch set.
 func NewCompositionRevisionPatchSet(ps v1.PatchSet) v1alpha1.PatchSet {
@@ -97,6 +137,22 @@ func NewCompositionRevisionComposedTemplate(ct v1.ComposedTemplate) v1alpha1.Com
 		ReadinessChecks:   make([]v1alpha1.ReadinessCheck, len(ct.ReadinessChecks)),
 	}
 
+	if ct.ReadinessTimeout != nil {
+		rct.ReadinessTimeout = &v1alpha1.ReadinessTimeout{Duration: ct.ReadinessTimeout.Duration}
+		if ct.ReadinessTimeout.FailurePolicy != nil {
+			p := v1alpha1.ReadinessFailurePolicy(*ct.ReadinessTimeout.FailurePolicy)
+			rct.ReadinessTimeout.FailurePolicy = &p
+		}
+	}
+
+	if ct.ObserveOnly != nil {
+		rct.ObserveOnly = &v1alpha1.ObserveOnly{
+			Name:        ct.ObserveOnly.Name,
+			Namespace:   ct.ObserveOnly.Namespace,
+			MatchLabels: ct.ObserveOnly.MatchLabels,
+		}
+	}
+
 	for i := range ct.Patches {
 		rct.Patches[i] = NewCompositionRevisionPatch(ct.Patches[i])
 	}
@@ -109,9 +165,48 @@ func NewCompositionRevisionComposedTemplate(ct v1.ComposedTemplate) v1alpha1.Com
 		rct.ReadinessChecks[i] = NewCompositionRevisionReadinessCheck(ct.ReadinessChecks[i])
 	}
 
+	for i := range ct.Gates {
+		rct.Gates = append(rct.Gates, NewCompositionRevisionGate(ct.Gates[i]))
+	}
+
 	return rct
 }
 
+// NewCompositionRevisionGate translates a composition's gate to a composition
+// revision gate.
+func NewCompositionRevisionGate(g v1.Gate) v1alpha1.Gate {
+	rg := v1alpha1.Gate{ResourceName: g.ResourceName}
+
+	if g.Condition != nil {
+		rg.Condition = &v1alpha1.GateCondition{Type: g.Condition.Type, Status: g.Condition.Status}
+	}
+
+	for i := range g.Checks {
+		rg.Checks = append(rg.Checks, NewCompositionRevisionReadinessCheck(g.Checks[i]))
+	}
+
+	return rg
+}
+
+// NewCompositionRevisionCombine translates a composition's combine to a
+// composition revision combine.
+func NewCompositionRevisionCombine(c v1.Combine) *v1alpha1.Combine {
+	rc := &v1alpha1.Combine{
+		Strategy:  v1alpha1.CombineStrategy(c.Strategy),
+		Variables: make([]v1alpha1.CombineVariable, len(c.Variables)),
+	}
+
+	if c.String != nil {
+		rc.String = &v1alpha1.StringCombine{Format: c.String.Format}
+	}
+
+	for i := range c.Variables {
+		rc.Variables[i].FromFieldPath = c.Variables[i].FromFieldPath
+	}
+
+	return rc
+}
+
 // NewCompositionRevisionPatch translates a composition's patch to a
 // composition revision patch.
 func NewCompositionRevisionPatch(p v1.Patch) v1alpha1.Patch {
@@ -124,27 +219,23 @@ func NewCompositionRevisionPatch(p v1.Patch) v1alpha1.Patch {
 	}
 
 	if p.Combine != nil {
-		rp.Combine = &v1alpha1.Combine{
-			Strategy:  v1alpha1.CombineStrategy(p.Combine.Strategy),
-			Variables: make([]v1alpha1.CombineVariable, len(p.Combine.Variables)),
-		}
-
-		if p.Combine.String != nil {
-			rp.Combine.String = &v1alpha1.StringCombine{Format: p.Combine.String.Format}
-		}
-
-		for i := range p.Combine.Variables {
-			rp.Combine.Variables[i].FromFieldPath = p.Combine.Variables[i].FromFieldPath
-		}
+		rp.Combine = NewCompositionRevisionCombine(*p.Combine)
 	}
 
 	for i := range p.Transforms {
 		rp.Transforms[i] = NewCompositionRevisionTransform(p.Transforms[i])
 	}
 
-	if p.Policy != nil && p.Policy.FromFieldPath != nil {
-		pol := v1alpha1.FromFieldPathPolicy(*p.Policy.FromFieldPath)
-		rp.Policy = &v1alpha1.PatchPolicy{FromFieldPath: &pol}
+	if p.Policy != nil && (p.Policy.FromFieldPath != nil || p.Policy.TransformFailure != nil) {
+		rp.Policy = &v1alpha1.PatchPolicy{TransformFailureDefault: p.Policy.TransformFailureDefault}
+		if p.Policy.FromFieldPath != nil {
+			pol := v1alpha1.FromFieldPathPolicy(*p.Policy.FromFieldPath)
+			rp.Policy.FromFieldPath = &pol
+		}
+		if p.Policy.TransformFailure != nil {
+			pol := v1alpha1.TransformFailurePolicy(*p.Policy.TransformFailure)
+			rp.Policy.TransformFailure = &pol
+		}
 	}
 
 	return rp
@@ -155,16 +246,43 @@ func NewCompositionRevisionPatch(p v1.Patch) v1alpha1.Patch {
 func NewCompositionRevisionTransform(t v1.Transform) v1alpha1.Transform {
 	rt := v1alpha1.Transform{Type: v1alpha1.TransformType(t.Type)}
 	if t.Math != nil {
-		rt.Math = &v1alpha1.MathTransform{Multiply: t.Math.Multiply}
+		rt.Math = &v1alpha1.MathTransform{
+			Type:     v1alpha1.MathTransformType(t.Math.Type),
+			Multiply: t.Math.Multiply,
+			Add:      t.Math.Add,
+			Subtract: t.Math.Subtract,
+			Divide:   t.Math.Divide,
+			Modulo:   t.Math.Modulo,
+			ClampMin: t.Math.ClampMin,
+			ClampMax: t.Math.ClampMax,
+		}
 	}
 	if t.Map != nil {
 		rt.Map = &v1alpha1.MapTransform{Pairs: t.Map.Pairs}
 	}
+	if t.Match != nil {
+		rt.Match = &v1alpha1.MatchTransform{
+			Patterns:      make([]v1alpha1.MatchTransformPattern, len(t.Match.Patterns)),
+			FallbackValue: t.Match.FallbackValue,
+		}
+		for i, p := range t.Match.Patterns {
+			rt.Match.Patterns[i] = v1alpha1.MatchTransformPattern{
+				Type:    v1alpha1.MatchTransformPatternType(p.Type),
+				Literal: p.Literal,
+				Regexp:  p.Regexp,
+				Result:  p.Result,
+			}
+		}
+	}
 	if t.String != nil {
 		rt.String = &v1alpha1.StringTransform{Format: *t.String.Format}
 	}
 	if t.Convert != nil {
 		rt.Convert = &v1alpha1.ConvertTransform{ToType: t.Convert.ToType}
+		if t.Convert.Format != nil {
+			f := v1alpha1.ConvertTransformFormat(*t.Convert.Format)
+			rt.Convert.Format = &f
+		}
 	}
 	return rt
 }
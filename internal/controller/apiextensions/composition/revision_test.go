@@ -18,6 +18,7 @@ package composition
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,6 +33,8 @@ import (
 
 func TestNewCompositionRevision(t *testing.T) {
 	sf := "f"
+	continuePolicyV1 := v1.ReadinessFailureContinue
+	continuePolicyV1alpha1 := v1alpha1.ReadinessFailureContinue
 	comp := &v1.Composition{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "coolcomp",
@@ -41,6 +44,22 @@ func TestNewCompositionRevision(t *testing.T) {
 				APIVersion: "v",
 				Kind:       "k",
 			},
+			CompositeTypeVersions: []v1.CompositeTypeVersion{{
+				APIVersion: "v2",
+				PatchOverrides: []v1.PatchOverride{{
+					ResourceName: "r",
+					Patches: []v1.Patch{{
+						Type:          v1.PatchType("t"),
+						FromFieldPath: pointer.String("from"),
+						Transforms:    []v1.Transform{},
+					}},
+				}},
+			}},
+			WriteConnectionSecretsToNamespaceFrom: &v1.Combine{
+				Strategy:  v1.CombineStrategy("s"),
+				Variables: []v1.CombineVariable{{FromFieldPath: "p"}},
+				String:    &v1.StringCombine{Format: "f"},
+			},
 			PatchSets: []v1.PatchSet{{
 				Name: "p",
 				Patches: []v1.Patch{{
@@ -83,6 +102,15 @@ func TestNewCompositionRevision(t *testing.T) {
 			Resources: []v1.ComposedTemplate{{
 				Name: pointer.String("t"),
 				Base: runtime.RawExtension{Raw: []byte("bytes")},
+				ObserveOnly: &v1.ObserveOnly{
+					Name:        pointer.String("o"),
+					Namespace:   pointer.String("ns"),
+					MatchLabels: map[string]string{"k": "v"},
+				},
+				ReadinessTimeout: &v1.ReadinessTimeout{
+					Duration:      metav1.Duration{Duration: time.Minute},
+					FailurePolicy: &continuePolicyV1,
+				},
 				Patches: []v1.Patch{{
 					Type:          v1.PatchType("t"),
 					FromFieldPath: pointer.String("from"),
@@ -165,6 +193,22 @@ func TestNewCompositionRevision(t *testing.T) {
 				APIVersion: "v",
 				Kind:       "k",
 			},
+			CompositeTypeVersions: []v1alpha1.CompositeTypeVersion{{
+				APIVersion: "v2",
+				PatchOverrides: []v1alpha1.PatchOverride{{
+					ResourceName: "r",
+					Patches: []v1alpha1.Patch{{
+						Type:          v1alpha1.PatchType("t"),
+						FromFieldPath: pointer.String("from"),
+						Transforms:    []v1alpha1.Transform{},
+					}},
+				}},
+			}},
+			WriteConnectionSecretsToNamespaceFrom: &v1alpha1.Combine{
+				Strategy:  v1alpha1.CombineStrategy("s"),
+				Variables: []v1alpha1.CombineVariable{{FromFieldPath: "p"}},
+				String:    &v1alpha1.StringCombine{Format: "f"},
+			},
 			PatchSets: []v1alpha1.PatchSet{{
 				Name: "p",
 				Patches: []v1alpha1.Patch{{
@@ -207,6 +251,15 @@ func TestNewCompositionRevision(t *testing.T) {
 			Resources: []v1alpha1.ComposedTemplate{{
 				Name: pointer.String("t"),
 				Base: runtime.RawExtension{Raw: []byte("bytes")},
+				ObserveOnly: &v1alpha1.ObserveOnly{
+					Name:        pointer.String("o"),
+					Namespace:   pointer.String("ns"),
+					MatchLabels: map[string]string{"k": "v"},
+				},
+				ReadinessTimeout: &v1alpha1.ReadinessTimeout{
+					Duration:      metav1.Duration{Duration: time.Minute},
+					FailurePolicy: &continuePolicyV1alpha1,
+				},
 				Patches: []v1alpha1.Patch{{
 					Type:          v1alpha1.PatchType("t"),
 					FromFieldPath: pointer.String("from"),
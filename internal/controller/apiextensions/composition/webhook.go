@@ -0,0 +1,208 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/internal/controller/apiextensions/composite"
+)
+
+// WebhookPath is the path at which Compositions are validated.
+const WebhookPath = "/validate-apiextensions-crossplane-io-v1-composition"
+
+// Error strings.
+const (
+	errDecodeComposition = "cannot decode Composition"
+
+	errFmtDecodeBase          = "cannot decode spec.resources[%d].base"
+	errFmtBaseMissingTypeMeta = "spec.resources[%d].base must specify an apiVersion and kind"
+	msgFmtKindNotInstalled    = "spec.resources[%d].base is a %s %s, which isn't installed yet; the Composition can't be used until it is"
+	errFmtInvalidInheriting   = "Composition %q inherits from this Composition, and would be invalid: %s"
+)
+
+// A Validator is an admission handler that rejects invalid Compositions,
+// including Compositions that conflict with the base they inherit from, and
+// bases that would make a Composition that inherits from them invalid. It
+// warns about Compositions that compose kinds of resource that aren't yet
+// installed.
+type Validator struct {
+	client client.Reader
+	mapper kmeta.RESTMapper
+}
+
+// NewValidator returns an admission handler that validates Compositions. The
+// supplied RESTMapper is used to determine whether the kinds of resource a
+// Composition composes are installed.
+func NewValidator(c client.Reader, m kmeta.RESTMapper) *Validator {
+	return &Validator{client: c, mapper: m}
+}
+
+// Handle an admission request for a Composition.
+func (v *Validator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	comp := &v1.Composition{}
+	if err := json.Unmarshal(req.Object.Raw, comp); err != nil {
+		return admission.Errored(http.StatusBadRequest, errors.Wrap(err, errDecodeComposition))
+	}
+
+	resolved := comp
+	if comp.Spec.BaseCompositionRef != nil {
+		r, err := composite.ResolveBases(ctx, v.client, comp)
+
+		// We allow a Composition to be created before its bases, for example
+		// when a package installs both at once. It can't be used until they
+		// exist.
+		if kerrors.IsNotFound(err) {
+			return admission.Allowed("")
+		}
+		if err != nil {
+			return admission.Denied(err.Error())
+		}
+		resolved = r
+	}
+
+	if err := validate(resolved); err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	gvks, err := composedKinds(resolved)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	if req.Operation == admissionv1.Create || req.Operation == admissionv1.Update {
+		if resp := v.validateInheriting(ctx, comp); resp != nil {
+			return *resp
+		}
+	}
+
+	// We allow a Composition to be created before the kinds of resource it
+	// composes are installed, for example when a package installs a
+	// Composition before the provider it depends on. We warn in case the
+	// kind was misspelled.
+	warnings := make([]string, 0)
+	for i, gvk := range gvks {
+		if _, err := v.mapper.RESTMapping(gvk.GroupKind(), gvk.Version); kmeta.IsNoMatchError(err) {
+			warnings = append(warnings, fmt.Sprintf(msgFmtKindNotInstalled, i, gvk.GroupVersion(), gvk.Kind))
+		}
+	}
+	if len(warnings) == 0 {
+		return admission.Allowed("")
+	}
+
+	return admission.Allowed("").WithWarnings(warnings...)
+}
+
+// validateInheriting returns a response denying the supplied Composition if
+// it's the base of a Composition that would be invalid if it inherited from
+// the supplied Composition. It returns nil if the supplied Composition should
+// not be denied.
+func (v *Validator) validateInheriting(ctx context.Context, comp *v1.Composition) *admission.Response {
+	names, err := composite.InheritingCompositions(ctx, v.client, comp.GetName())
+	if err != nil {
+		resp := admission.Errored(http.StatusInternalServerError, err)
+		return &resp
+	}
+
+	// We resolve each inheriting Composition's bases as though the supplied
+	// Composition had already been admitted.
+	c := &admittedReader{Reader: v.client, comp: comp}
+	for _, name := range names {
+		ic := &v1.Composition{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, ic); err != nil {
+			continue
+		}
+
+		// An inheriting Composition whose bases don't all exist can't be
+		// used yet, so it can't be made invalid.
+		resolved, err := composite.ResolveBases(ctx, c, ic)
+		if kerrors.IsNotFound(err) {
+			continue
+		}
+		if err == nil {
+			err = validate(resolved)
+		}
+		if err != nil {
+			resp := admission.Denied(fmt.Sprintf(errFmtInvalidInheriting, name, err))
+			return &resp
+		}
+	}
+	return nil
+}
+
+// validate returns an error if the supplied Composition (including one
+// produced by inheriting from a base) is one that the composite resource
+// reconciler would refuse to use.
+func validate(comp *v1.Composition) error {
+	for _, fn := range []func(*v1.Composition) error{composite.RejectMixedTemplates, composite.RejectDuplicateNames} {
+		if err := fn(comp); err != nil {
+			return err
+		}
+	}
+	return comp.ValidateCreate()
+}
+
+// An admittedReader reads Compositions as though the supplied Composition had
+// been admitted.
+type admittedReader struct {
+	client.Reader
+	comp *v1.Composition
+}
+
+// Get the supplied Composition if it's the one being admitted, or otherwise
+// get the requested object from the wrapped client.Reader.
+func (r *admittedReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if c, ok := obj.(*v1.Composition); ok && key.Name == r.comp.GetName() {
+		r.comp.DeepCopyInto(c)
+		return nil
+	}
+	return r.Reader.Get(ctx, key, obj)
+}
+
+// composedKinds returns the kind of resource composed by each of the supplied
+// Composition's resource templates. It returns an error if any template's base
+// doesn't specify an apiVersion and kind.
+func composedKinds(comp *v1.Composition) ([]schema.GroupVersionKind, error) {
+	gvks := make([]schema.GroupVersionKind, len(comp.Spec.Resources))
+	for i, t := range comp.Spec.Resources {
+		tm := &metav1.TypeMeta{}
+		if len(t.Base.Raw) > 0 {
+			if err := json.Unmarshal(t.Base.Raw, tm); err != nil {
+				return nil, errors.Wrapf(err, errFmtDecodeBase, i)
+			}
+		}
+		if tm.APIVersion == "" || tm.Kind == "" {
+			return nil, errors.Errorf(errFmtBaseMissingTypeMeta, i)
+		}
+		gvks[i] = tm.GroupVersionKind()
+	}
+	return gvks, nil
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package composition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	admissionv1 "k8s.io/api/admission/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestValidatorHandle(t *testing.T) {
+	errBoom := errors.New("boom")
+	ref := v1.TypeReference{APIVersion: "example.org/v1", Kind: "XR"}
+
+	raw := func(c *v1.Composition) runtime.RawExtension {
+		b, _ := json.Marshal(c)
+		return runtime.RawExtension{Raw: b}
+	}
+
+	widget := runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"Widget"}`)}
+	gadget := runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"Gadget"}`)}
+
+	mapper := kmeta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "Widget"}, kmeta.RESTScopeRoot)
+
+	base := &v1.Composition{Spec: v1.CompositionSpec{
+		CompositeTypeRef: ref,
+		Resources:        []v1.ComposedTemplate{{Name: pointer.StringPtr("a"), Base: widget}},
+	}}
+	withBase := test.NewMockGetFn(nil, func(obj client.Object) error {
+		base.DeepCopyInto(obj.(*v1.Composition))
+		return nil
+	})
+
+	// A Composition that inherits from the above base.
+	child := &v1.Composition{
+		ObjectMeta: metav1.ObjectMeta{Name: "child"},
+		Spec: v1.CompositionSpec{
+			CompositeTypeRef:   ref,
+			BaseCompositionRef: &xpv1.Reference{Name: "base"},
+			Resources:          []v1.ComposedTemplate{{Name: pointer.StringPtr("b"), Base: widget}},
+		},
+	}
+	withChild := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			child.DeepCopyInto(obj.(*v1.Composition))
+			return nil
+		}),
+		MockList: test.NewMockListFn(nil, func(obj client.ObjectList) error {
+			obj.(*v1.CompositionList).Items = []v1.Composition{*child}
+			return nil
+		}),
+	}
+
+	cases := map[string]struct {
+		reason string
+		c      client.Reader
+		req    admissionv1.AdmissionRequest
+		want   admission.Response
+	}{
+		"DecodeError": {
+			reason: "We should return an error if we can't decode the Composition.",
+			req:    admissionv1.AdmissionRequest{Object: runtime.RawExtension{Raw: []byte("{")}},
+			want:   admission.Errored(http.StatusBadRequest, errors.Wrap(errors.New("unexpected end of JSON input"), errDecodeComposition)),
+		},
+		"NoBase": {
+			reason: "We should allow a valid Composition without a base.",
+			req:    admissionv1.AdmissionRequest{Object: raw(base)},
+			want:   admission.Allowed(""),
+		},
+		"BaseNotFound": {
+			reason: "We should allow a Composition whose base doesn't exist yet.",
+			c:      &test.MockClient{MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "base"))},
+			req: admissionv1.AdmissionRequest{Object: raw(&v1.Composition{Spec: v1.CompositionSpec{
+				CompositeTypeRef:   ref,
+				BaseCompositionRef: &xpv1.Reference{Name: "base"},
+			}})},
+			want: admission.Allowed(""),
+		},
+		"MixedTemplates": {
+			reason: "We should deny a Composition that inherits a mix of named and anonymous resources.",
+			c: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+				obj.(*v1.Composition).Spec = v1.CompositionSpec{
+					CompositeTypeRef: ref,
+					Resources:        []v1.ComposedTemplate{{Name: pointer.StringPtr("a"), Base: widget}, {Base: widget}},
+				}
+				return nil
+			})},
+			req: admissionv1.AdmissionRequest{Object: raw(&v1.Composition{Spec: v1.CompositionSpec{
+				CompositeTypeRef:   ref,
+				BaseCompositionRef: &xpv1.Reference{Name: "base"},
+			}})},
+			want: admission.Denied("cannot mix named and anonymous resource templates"),
+		},
+		"Conflict": {
+			reason: "We should deny a Composition that conflicts with its base.",
+			c:      &test.MockClient{MockGet: withBase},
+			req: admissionv1.AdmissionRequest{Object: raw(&v1.Composition{Spec: v1.CompositionSpec{
+				CompositeTypeRef:   v1.TypeReference{APIVersion: "example.org/v1", Kind: "Other"},
+				BaseCompositionRef: &xpv1.Reference{Name: "base"},
+			}})},
+			want: admission.Denied("cannot inherit from base Composition \"base\": composition is compatible with example.org/v1 Other, but its base is compatible with example.org/v1 XR"),
+		},
+		"InvalidOverride": {
+			reason: "We should deny a Composition whose patch overrides reference a resource neither it nor its base defines.",
+			c:      &test.MockClient{MockGet: withBase},
+			req: admissionv1.AdmissionRequest{Object: raw(&v1.Composition{Spec: v1.CompositionSpec{
+				CompositeTypeRef:   ref,
+				BaseCompositionRef: &xpv1.Reference{Name: "base"},
+				CompositeTypeVersions: []v1.CompositeTypeVersion{{
+					APIVersion:     "example.org/v2",
+					PatchOverrides: []v1.PatchOverride{{ResourceName: "b"}},
+				}},
+			}})},
+			want: admission.Denied("spec.compositeTypeVersions[0].patchOverrides[0] overrides unknown resource \"b\""),
+		},
+		"Valid": {
+			reason: "We should allow a Composition that overrides resources inherited from its base.",
+			c:      &test.MockClient{MockGet: withBase},
+			req: admissionv1.AdmissionRequest{Object: raw(&v1.Composition{Spec: v1.CompositionSpec{
+				CompositeTypeRef:   ref,
+				BaseCompositionRef: &xpv1.Reference{Name: "base"},
+				CompositeTypeVersions: []v1.CompositeTypeVersion{{
+					APIVersion:     "example.org/v2",
+					PatchOverrides: []v1.PatchOverride{{ResourceName: "a"}},
+				}},
+				Resources: []v1.ComposedTemplate{{Name: pointer.StringPtr("b"), Base: widget}},
+			}})},
+			want: admission.Allowed(""),
+		},
+		"DuplicateNames": {
+			reason: "We should deny a Composition whose resource templates don't have unique names.",
+			req: admissionv1.AdmissionRequest{Object: raw(&v1.Composition{Spec: v1.CompositionSpec{
+				CompositeTypeRef: ref,
+				Resources:        []v1.ComposedTemplate{{Name: pointer.StringPtr("a"), Base: widget}, {Name: pointer.StringPtr("a"), Base: widget}},
+			}})},
+			want: admission.Denied("resource template names must be unique within their Composition"),
+		},
+		"MissingTypeMeta": {
+			reason: "We should deny a Composition with a resource template whose base doesn't specify an apiVersion and kind.",
+			req: admissionv1.AdmissionRequest{Object: raw(&v1.Composition{Spec: v1.CompositionSpec{
+				CompositeTypeRef: ref,
+				Resources:        []v1.ComposedTemplate{{Base: widget}, {Base: runtime.RawExtension{Raw: []byte(`{"kind":"Widget"}`)}}},
+			}})},
+			want: admission.Denied(fmt.Sprintf(errFmtBaseMissingTypeMeta, 1)),
+		},
+		"KindNotInstalled": {
+			reason: "We should allow a Composition that composes a kind of resource that isn't installed yet, with a warning.",
+			req: admissionv1.AdmissionRequest{Object: raw(&v1.Composition{Spec: v1.CompositionSpec{
+				CompositeTypeRef: ref,
+				Resources:        []v1.ComposedTemplate{{Base: widget}, {Base: gadget}},
+			}})},
+			want: admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtKindNotInstalled, 1, "example.org/v1", "Gadget")),
+		},
+		"ListInheritingError": {
+			reason: "We should return an error if we can't list the Compositions that may inherit from a Composition.",
+			c:      &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+			req:    admissionv1.AdmissionRequest{Operation: admissionv1.Update, Object: raw(base)},
+			want:   admission.Errored(http.StatusInternalServerError, errors.Wrap(errBoom, "cannot list Compositions that may inherit from a base")),
+		},
+		"InvalidInheriting": {
+			reason: "We should deny an update to a base that would make a Composition that inherits from it invalid.",
+			c:      withChild,
+			req: admissionv1.AdmissionRequest{Operation: admissionv1.Update, Object: raw(&v1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Name: "base"},
+				Spec: v1.CompositionSpec{
+					CompositeTypeRef: v1.TypeReference{APIVersion: "example.org/v1", Kind: "Other"},
+					Resources:        []v1.ComposedTemplate{{Name: pointer.StringPtr("a"), Base: widget}},
+				},
+			})},
+			want: admission.Denied(fmt.Sprintf(errFmtInvalidInheriting, "child", "cannot inherit from base Composition \"base\": composition is compatible with example.org/v1 XR, but its base is compatible with example.org/v1 Other")),
+		},
+		"ValidInheriting": {
+			reason: "We should allow an update to a base that leaves the Compositions that inherit from it valid.",
+			c:      withChild,
+			req: admissionv1.AdmissionRequest{Operation: admissionv1.Update, Object: raw(&v1.Composition{
+				ObjectMeta: metav1.ObjectMeta{Name: "base"},
+				Spec: v1.CompositionSpec{
+					CompositeTypeRef: ref,
+					Resources:        []v1.ComposedTemplate{{Name: pointer.StringPtr("a"), Base: widget}},
+				},
+			})},
+			want: admission.Allowed(""),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := NewValidator(tc.c, mapper)
+			got := v.Handle(context.Background(), admission.Request{AdmissionRequest: tc.req})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nHandle(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
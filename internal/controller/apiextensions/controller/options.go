@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controller contains options specific to apiextensions controllers.
+package controller
+
+import (
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+
+	"github.com/crossplane/crossplane/internal/controller/apiextensions/composite"
+	"github.com/crossplane/crossplane/internal/engine"
+)
+
+// Options specific to apiextensions controllers.
+type Options struct {
+	controller.Options
+
+	// ControllerEngine starts and stops the composite resource and claim
+	// controllers. Controllers started by the same engine share the caches
+	// of any kinds of object they watch in common.
+	ControllerEngine *engine.Engine
+
+	// CompositeLimits constrain what composite resources may compose.
+	CompositeLimits composite.Limits
+
+	// CompositeSecretPatchPolicy determines what composite resource
+	// controllers do when a Composition patches secret data into fields that
+	// are not secret.
+	CompositeSecretPatchPolicy composite.SecretPatchPolicy
+
+	// ComposedEventPolicy determines which objects composite resource
+	// controllers tell about significant events of composed resources.
+	ComposedEventPolicy composite.ComposedEventPolicy
+
+	// DefaultComposedLabels are added to every resource composite resource
+	// controllers compose, unless a Composition sets them.
+	DefaultComposedLabels map[string]string
+
+	// EventSuppressionWindow is how long identical events about the same
+	// object are suppressed for once one has been recorded.
+	EventSuppressionWindow time.Duration
+}
@@ -18,16 +18,17 @@ package definition
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
-	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	kmeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	kcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -45,9 +46,14 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured"
 
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
-	"github.com/crossplane/crossplane/apis/secrets/v1alpha1"
+	"github.com/crossplane/crossplane/apis/apiextensions/v1alpha1"
+	secretsv1alpha1 "github.com/crossplane/crossplane/apis/secrets/v1alpha1"
 	"github.com/crossplane/crossplane/internal/controller/apiextensions/composite"
+	apiextensionscontroller "github.com/crossplane/crossplane/internal/controller/apiextensions/controller"
+	"github.com/crossplane/crossplane/internal/engine"
+	"github.com/crossplane/crossplane/internal/events"
 	"github.com/crossplane/crossplane/internal/features"
+	"github.com/crossplane/crossplane/internal/metrics"
 	"github.com/crossplane/crossplane/internal/xcrd"
 )
 
@@ -66,6 +72,9 @@ const (
 	errDeleteCRD       = "cannot delete composite resource CustomResourceDefinition"
 	errListCRs         = "cannot list defined composite resources"
 	errDeleteCRs       = "cannot delete defined composite resources"
+	errOrphanCRD       = "cannot orphan composite resource CustomResourceDefinition"
+
+	errFmtDeletionBlocked = "deletion is blocked by %d composite resources; delete them, or set spec.deletionPolicy to Cascade or Orphan"
 )
 
 // Wait strings.
@@ -79,12 +88,13 @@ const (
 	reasonRenderCRD   event.Reason = "RenderCRD"
 	reasonEstablishXR event.Reason = "EstablishComposite"
 	reasonTerminateXR event.Reason = "TerminateComposite"
+	reasonCountUsage  event.Reason = "CountUsage"
 )
 
 // A ControllerEngine can start and stop Kubernetes controllers on demand.
 type ControllerEngine interface {
 	IsRunning(name string) bool
-	Start(name string, o kcontroller.Options, w ...controller.Watch) error
+	Start(name string, o kcontroller.Options, w ...engine.Watch) error
 	Stop(name string)
 	Err(name string) error
 }
@@ -107,13 +117,19 @@ func (fn CRDRenderFn) Render(d *v1.CompositeResourceDefinition) (*extv1.CustomRe
 
 // Setup adds a controller that reconciles CompositeResourceDefinitions by
 // defining a composite resource and starting a controller to reconcile it.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
+func Setup(mgr ctrl.Manager, o apiextensionscontroller.Options) error {
 	name := "defined/" + strings.ToLower(v1.CompositeResourceDefinitionGroupKind)
 
 	r := NewReconciler(mgr,
 		WithLogger(o.Logger.WithValues("controller", name)),
-		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
-		WithOptions(o))
+		WithRecorder(events.NewDedupingRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)), o.EventSuppressionWindow)),
+		WithControllerEngine(o.ControllerEngine),
+		WithCompositeLimits(o.CompositeLimits),
+		WithCompositeSecretPatchPolicy(o.CompositeSecretPatchPolicy),
+		WithComposedEventPolicy(o.ComposedEventPolicy),
+		WithDefaultComposedLabels(o.DefaultComposedLabels),
+		WithUsageCounter(NewAPIUsageCounter(mgr.GetAPIReader())),
+		WithOptions(o.Options))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
@@ -140,6 +156,39 @@ func WithRecorder(er event.Recorder) ReconcilerOption {
 	}
 }
 
+// WithCompositeLimits specifies what the composite resources reconciled by new
+// composite resource controllers may compose.
+func WithCompositeLimits(l composite.Limits) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.limits = l
+	}
+}
+
+// WithCompositeSecretPatchPolicy specifies what new composite resource
+// controllers should do when a Composition patches secret data into fields
+// that are not secret.
+func WithCompositeSecretPatchPolicy(p composite.SecretPatchPolicy) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.secretPatchPolicy = p
+	}
+}
+
+// WithComposedEventPolicy specifies which objects new composite resource
+// controllers should tell about significant events of composed resources.
+func WithComposedEventPolicy(p composite.ComposedEventPolicy) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.composedEventPolicy = p
+	}
+}
+
+// WithDefaultComposedLabels specifies labels that new composite resource
+// controllers should add to every resource they compose.
+func WithDefaultComposedLabels(l map[string]string) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.defaultComposedLabels = l
+	}
+}
+
 // WithOptions lets the Reconciler know which options to pass to new composite
 // resource controllers.
 func WithOptions(o controller.Options) ReconcilerOption {
@@ -172,6 +221,14 @@ func WithCRDRenderer(c CRDRenderer) ReconcilerOption {
 	}
 }
 
+// WithUsageCounter specifies how the Reconciler should count the composite
+// resources and claims of a CompositeResourceDefinition.
+func WithUsageCounter(c UsageCounter) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.composite.UsageCounter = c
+	}
+}
+
 // WithClientApplicator specifies how the Reconciler should interact with the
 // Kubernetes API.
 func WithClientApplicator(ca resource.ClientApplicator) ReconcilerOption {
@@ -184,6 +241,7 @@ type definition struct {
 	CRDRenderer
 	ControllerEngine
 	resource.Finalizer
+	UsageCounter
 }
 
 // NewReconciler returns a Reconciler of CompositeResourceDefinitions.
@@ -200,13 +258,16 @@ func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
 
 		composite: definition{
 			CRDRenderer:      CRDRenderFn(xcrd.ForCompositeResource),
-			ControllerEngine: controller.NewEngine(mgr),
+			ControllerEngine: engine.New(mgr),
 			Finalizer:        resource.NewAPIFinalizer(kube, finalizer),
+			UsageCounter:     NewAPIUsageCounter(kube),
 		},
 
 		log:    logging.NewNopLogger(),
 		record: event.NewNopRecorder(),
 
+		configs: &engine.ConfigTracker{},
+
 		options: controller.DefaultOptions(),
 	}
 
@@ -226,7 +287,13 @@ type Reconciler struct {
 	log    logging.Logger
 	record event.Recorder
 
-	options controller.Options
+	configs *engine.ConfigTracker
+
+	options               controller.Options
+	limits                composite.Limits
+	secretPatchPolicy     composite.SecretPatchPolicy
+	composedEventPolicy   composite.ComposedEventPolicy
+	defaultComposedLabels map[string]string
 }
 
 // Reconcile a CompositeResourceDefinition by defining a new kind of composite
@@ -296,6 +363,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			// just in case. This is a no-op if the controller was
 			// already stopped.
 			r.composite.Stop(composite.ControllerName(d.GetName()))
+			r.configs.Stopped(composite.ControllerName(d.GetName()))
 			log.Debug("Stopped composite resource controller")
 			r.record.Event(d, event.Normal(reasonTerminateXR, "Stopped composite resource controller"))
 
@@ -312,19 +380,20 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			return reconcile.Result{Requeue: false}, nil
 		}
 
-		// NOTE(muvaf): When user deletes CompositeResourceDefinition
-		// object the deletion signal does not cascade to the owned
-		// resource until owner is gone. But owner has its own finalizer
-		// that depends on having no instance of the CRD because it
-		// cannot go away before stopping the controller. So, we need to
-		// delete all defined custom resources manually here.
-		o := &kunstructured.Unstructured{}
-		o.SetGroupVersionKind(d.GetCompositeGroupVersionKind())
-		if err := r.client.DeleteAllOf(ctx, o); err != nil && !kmeta.IsNoMatchError(err) && !kerrors.IsNotFound(err) {
-			log.Debug(errDeleteCRs, "error", err)
-			err = errors.Wrap(err, errDeleteCRs)
-			r.record.Event(d, event.Warning(reasonTerminateXR, err))
-			return reconcile.Result{}, err
+		// We'd delete the CRD, and thus all of our composite resources,
+		// if we remained its controller. Once it's orphaned we'll stop
+		// our controller and remove our finalizer per the above.
+		if d.GetDeletionPolicy() == v1.DeletionOrphan {
+			removeOwnerReference(crd, d.GetUID())
+			if err := r.client.Update(ctx, crd); err != nil {
+				log.Debug(errOrphanCRD, "error", err)
+				err = errors.Wrap(err, errOrphanCRD)
+				r.record.Event(d, event.Warning(reasonTerminateXR, err))
+				return reconcile.Result{}, err
+			}
+			log.Debug("Orphaned composite resource CustomResourceDefinition")
+			r.record.Event(d, event.Normal(reasonTerminateXR, "Orphaned composite resource CustomResourceDefinition"))
+			return reconcile.Result{Requeue: true}, nil
 		}
 
 		l := &kunstructured.UnstructuredList{}
@@ -336,10 +405,36 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			return reconcile.Result{}, err
 		}
 
+		// We don't delete composite resources unless we're told to. We
+		// requeue to check whether they've been deleted, because we
+		// won't be requeued implicitly when they are.
+		if len(l.Items) > 0 && d.GetDeletionPolicy() == v1.DeletionBlock {
+			err := errors.Errorf(errFmtDeletionBlocked, len(l.Items))
+			log.Debug("Cannot delete composite resource CustomResourceDefinition", "error", err)
+			r.record.Event(d, event.Warning(reasonTerminateXR, err))
+			return reconcile.Result{Requeue: true}, nil
+		}
+
+		// NOTE(muvaf): When user deletes CompositeResourceDefinition
+		// object the deletion signal does not cascade to the owned
+		// resource until owner is gone. But owner has its own finalizer
+		// that depends on having no instance of the CRD because it
+		// cannot go away before stopping the controller. So, we need to
+		// delete all defined custom resources manually here.
+		//
 		// Controller should be stopped only after all instances are
 		// gone so that deletion logic of the instances are processed by
 		// the controller.
 		if len(l.Items) > 0 {
+			for _, xr := range deleteFirst(l.Items) {
+				xr := xr
+				if err := r.client.Delete(ctx, &xr); resource.IgnoreNotFound(err) != nil {
+					log.Debug(errDeleteCRs, "error", err)
+					err = errors.Wrap(err, errDeleteCRs)
+					r.record.Event(d, event.Warning(reasonTerminateXR, err))
+					return reconcile.Result{}, err
+				}
+			}
 			log.Debug(waitCRDelete)
 			r.record.Event(d, event.Normal(reasonTerminateXR, waitCRDelete))
 			return reconcile.Result{Requeue: true}, nil
@@ -348,6 +443,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		// The controller should be stopped before the deletion of CRD
 		// so that it doesn't crash.
 		r.composite.Stop(composite.ControllerName(d.GetName()))
+		r.configs.Stopped(composite.ControllerName(d.GetName()))
 		log.Debug("Stopped composite resource controller")
 		r.record.Event(d, event.Normal(reasonTerminateXR, "Stopped composite resource controller"))
 
@@ -391,6 +487,34 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		log.Debug("Composite resource controller encountered an error", "error", err)
 	}
 
+	// We count the composite resources and claims of the XRD when we
+	// reconcile it, so that platform teams can tell whether it's safe to
+	// delete or change. Counting them is best effort; it shouldn't stop us
+	// reconciling. We only requeue to count them again if we couldn't.
+	result := reconcile.Result{Requeue: false}
+	if now := time.Now(); needsUsageSample(d, now) {
+		composites, claims, err := r.composite.CountUsage(ctx, d)
+		if err != nil {
+			log.Debug("Cannot count composite resources and claims", "error", err)
+			r.record.Event(d, event.Warning(reasonCountUsage, err))
+			result = reconcile.Result{RequeueAfter: usageSampleInterval}
+		} else {
+			d.Status.Usage = sampleUsage(d.Status.Usage, composites, claims, metav1.NewTime(now))
+		}
+	}
+
+	// A paused XRD keeps its CRD, and thus its composite resources, but
+	// nothing reconciles them until it is unpaused. This is a no-op if the
+	// controller was already stopped.
+	if d.IsPaused() {
+		r.composite.Stop(composite.ControllerName(d.GetName()))
+		r.configs.Stopped(composite.ControllerName(d.GetName()))
+		log.Debug("Paused composite resource controller")
+		r.record.Event(d, event.Normal(reasonEstablishXR, "Paused composite resource controller"))
+		d.Status.SetConditions(v1.PausedComposite())
+		return result, errors.Wrap(r.client.Status().Update(ctx, d), errUpdateStatus)
+	}
+
 	observed := d.Status.Controllers.CompositeResourceTypeRef
 	desired := v1.TypeReferenceTo(d.GetCompositeGroupVersionKind())
 	if observed.APIVersion != "" && observed != desired {
@@ -403,6 +527,23 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			"desired-version", desired.APIVersion))
 	}
 
+	// Composite resource controllers are configured with some of the
+	// definition's spec fields when they're started, so we restart the
+	// controller when they change.
+	var ns string
+	if d.Spec.DefaultConnectionSecretNamespace != nil {
+		ns = *d.Spec.DefaultConnectionSecretNamespace
+	}
+	cfg := fmt.Sprintf("%+v", struct {
+		DefaultConnectionSecretNamespace string
+		ClaimNaming                      v1.ClaimNaming
+	}{ns, d.GetClaimNaming()})
+	if r.configs.Changed(composite.ControllerName(d.GetName()), cfg) {
+		r.composite.Stop(composite.ControllerName(d.GetName()))
+		log.Debug("Controller configuration changed; stopped composite resource controller", "config", cfg)
+		r.record.Event(d, event.Normal(reasonEstablishXR, "Controller configuration changed; stopped composite resource controller"))
+	}
+
 	recorder := r.record.WithAnnotations("controller", composite.ControllerName(d.GetName()))
 
 	o := []composite.ReconcilerOption{
@@ -414,6 +555,30 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		)),
 		composite.WithLogger(log.WithValues("controller", composite.ControllerName(d.GetName()))),
 		composite.WithRecorder(recorder),
+		composite.WithMetricsRecorder(metrics.NewPrometheusRecorder(d.GetName())),
+		composite.WithLimits(r.limits),
+		composite.WithSecretPatchPolicy(r.secretPatchPolicy),
+		composite.WithDefaultComposedLabels(r.defaultComposedLabels),
+	}
+
+	switch r.composedEventPolicy {
+	case composite.ComposedEventPolicyComposite:
+		o = append(o, composite.WithComposedEventPropagator(composite.NewConditionEventPropagator(r.client, recorder)))
+	case composite.ComposedEventPolicyClaim:
+		o = append(o, composite.WithComposedEventPropagator(composite.NewConditionEventPropagator(r.client, recorder, composite.WithClaimEvents())))
+	case composite.ComposedEventPolicyNone:
+	}
+
+	if d.GetClaimNaming().PropagateExternalName {
+		o = append(o, composite.WithExternalNamePropagation())
+	}
+
+	// Composite resources write their connection secrets to the definition's
+	// default namespace, if any, when their Composition doesn't specify one.
+	ac := composite.NewAPIConfigurator(r.client)
+	if ns != "" {
+		ac = composite.NewAPIConfigurator(r.client, composite.WithDefaultConnectionSecretNamespace(ns))
+		o = append(o, composite.WithConfigurator(composite.NewConfiguratorChain(composite.NewAPINamingConfigurator(r.client), ac)))
 	}
 
 	// We only want to enable CompositionRevision support if the relevant
@@ -421,7 +586,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	// its default CompositionFetcher.
 	if r.options.Features.Enabled(features.EnableAlphaCompositionRevisions) {
 		a := resource.ClientApplicator{Client: r.client, Applicator: resource.NewAPIPatchingApplicator(r.client)}
-		o = append(o, composite.WithCompositionFetcher(composite.NewAPIRevisionFetcher(a)))
+		o = append(o, composite.WithCompositionFetcher(composite.NewInheritingCompositionFetcher(composite.NewAPIRevisionFetcher(a), r.client)))
 	}
 
 	// We only want to enable ExternalSecretStore support if the relevant
@@ -433,19 +598,19 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	if r.options.Features.Enabled(features.EnableAlphaExternalSecretStores) {
 		pc := []managed.ConnectionPublisher{
 			composite.NewAPIFilteredSecretPublisher(r.client, d.GetConnectionSecretKeys()),
-			composite.NewSecretStoreConnectionPublisher(connection.NewDetailsManager(r.client, v1alpha1.StoreConfigGroupVersionKind), d.GetConnectionSecretKeys()),
+			composite.NewSecretStoreConnectionPublisher(connection.NewDetailsManager(r.client, secretsv1alpha1.StoreConfigGroupVersionKind), d.GetConnectionSecretKeys()),
 		}
 		o = append(o, composite.WithConnectionPublishers(pc...))
 
 		fc := composite.ConnectionDetailsFetcherChain{
 			composite.NewAPIConnectionDetailsFetcher(r.client),
-			composite.NewSecretStoreConnectionDetailsFetcher(connection.NewDetailsManager(r.client, v1alpha1.StoreConfigGroupVersionKind)),
+			composite.NewSecretStoreConnectionDetailsFetcher(connection.NewDetailsManager(r.client, secretsv1alpha1.StoreConfigGroupVersionKind)),
 		}
 		o = append(o, composite.WithConnectionDetailsFetcher(fc))
 
 		cc := composite.NewConfiguratorChain(
 			composite.NewAPINamingConfigurator(r.client),
-			composite.NewAPIConfigurator(r.client),
+			ac,
 			composite.NewSecretStoreConnectionDetailsConfigurator(r.client),
 		)
 		o = append(o, composite.WithConfigurator(cc))
@@ -458,15 +623,56 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	u := &kunstructured.Unstructured{}
 	u.SetGroupVersionKind(d.GetCompositeGroupVersionKind())
 
-	if err := r.composite.Start(composite.ControllerName(d.GetName()), ko, controller.For(u, &handler.EnqueueRequestForObject{})); err != nil {
+	// We requeue composite resources when the Composition they use changes,
+	// rather than waiting for them to be polled. Composite resources use the
+	// revisions of their Composition when CompositionRevisions are enabled,
+	// so we wait for the new revision to be created.
+	var comp client.Object = &v1.Composition{}
+	if r.options.Features.Enabled(features.EnableAlphaCompositionRevisions) {
+		comp = &v1alpha1.CompositionRevision{}
+	}
+	users := handler.EnqueueRequestsFromMapFunc(composite.CompositionUsers(r.client, resource.CompositeKind(d.GetCompositeGroupVersionKind())))
+
+	if err := r.composite.Start(composite.ControllerName(d.GetName()), ko, engine.For(u, &handler.EnqueueRequestForObject{}), engine.For(comp, users)); err != nil {
 		log.Debug(errStartController, "error", err)
 		err = errors.Wrap(err, errStartController)
 		r.record.Event(d, event.Warning(reasonEstablishXR, err))
 		return reconcile.Result{}, err
 	}
+	r.configs.Started(composite.ControllerName(d.GetName()), cfg)
 
 	d.Status.Controllers.CompositeResourceTypeRef = v1.TypeReferenceTo(d.GetCompositeGroupVersionKind())
 	d.Status.SetConditions(v1.WatchingComposite())
 	r.record.Event(d, event.Normal(reasonEstablishXR, "(Re)started composite resource controller"))
-	return reconcile.Result{Requeue: false}, errors.Wrap(r.client.Status().Update(ctx, d), errUpdateStatus)
+	return result, errors.Wrap(r.client.Status().Update(ctx, d), errUpdateStatus)
+}
+
+// deleteFirst returns the supplied composite resources that should be deleted
+// first. A composite resource that is part of another composite resource, or
+// that is bound to a claim, is deleted along with its parent or claim. We only
+// delete such composite resources once they're all that remain.
+func deleteFirst(xrs []kunstructured.Unstructured) []kunstructured.Unstructured {
+	roots := make([]kunstructured.Unstructured, 0, len(xrs))
+	for _, xr := range xrs {
+		_, claimed, _ := kunstructured.NestedMap(xr.Object, "spec", "claimRef")
+		if metav1.GetControllerOf(&xr) == nil && !claimed {
+			roots = append(roots, xr)
+		}
+	}
+	if len(roots) == 0 {
+		return xrs
+	}
+	return roots
+}
+
+// removeOwnerReference removes any owner reference to the supplied UID from
+// the supplied object.
+func removeOwnerReference(o metav1.Object, uid types.UID) {
+	refs := make([]metav1.OwnerReference, 0, len(o.GetOwnerReferences()))
+	for _, ref := range o.GetOwnerReferences() {
+		if ref.UID != uid {
+			refs = append(refs, ref)
+		}
+	}
+	o.SetOwnerReferences(refs)
 }
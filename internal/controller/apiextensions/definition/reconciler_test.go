@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,22 +33,26 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
-	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/internal/engine"
 )
 
+// ignoreSampleTimes ignores the times at which usage was sampled, which are
+// tested by TestSampleUsage.
+var ignoreSampleTimes = cmpopts.IgnoreFields(v1.CompositeResourceDefinitionUsage{}, "LastSampleTime", "LastTransitionTime")
+
 type MockEngine struct {
 	ControllerEngine
-	MockStart func(name string, o kcontroller.Options, w ...controller.Watch) error
+	MockStart func(name string, o kcontroller.Options, w ...engine.Watch) error
 	MockStop  func(name string)
 	MockErr   func(name string) error
 }
 
-func (m *MockEngine) Start(name string, o kcontroller.Options, w ...controller.Watch) error {
+func (m *MockEngine) Start(name string, o kcontroller.Options, w ...engine.Watch) error {
 	return m.MockStart(name, o, w...)
 }
 
@@ -64,6 +69,8 @@ func TestReconcile(t *testing.T) {
 	now := metav1.Now()
 	owner := types.UID("definitely-a-uuid")
 	ctrlr := true
+	orphan := v1.DeletionOrphan
+	cascade := v1.DeletionCascade
 
 	type args struct {
 		mgr  manager.Manager
@@ -244,8 +251,8 @@ func TestReconcile(t *testing.T) {
 				r: reconcile.Result{Requeue: false},
 			},
 		},
-		"DeleteAllCustomResourcesError": {
-			reason: "We should return any error we encounter while deleting all defined resources.",
+		"OrphanCustomResourceDefinitionError": {
+			reason: "We should return any error we encounter while orphaning the CRD we created.",
 			args: args{
 				mgr: &fake.Manager{},
 				opts: []ReconcilerOption{
@@ -257,6 +264,7 @@ func TestReconcile(t *testing.T) {
 									d := v1.CompositeResourceDefinition{}
 									d.SetUID(owner)
 									d.SetDeletionTimestamp(&now)
+									d.Spec.DeletionPolicy = &orphan
 									*v = d
 								case *extv1.CustomResourceDefinition:
 									crd := extv1.CustomResourceDefinition{}
@@ -266,7 +274,7 @@ func TestReconcile(t *testing.T) {
 								}
 								return nil
 							}),
-							MockDeleteAllOf:  test.NewMockDeleteAllOfFn(errBoom),
+							MockUpdate:       test.NewMockUpdateFn(errBoom),
 							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
 						},
 					}),
@@ -276,7 +284,51 @@ func TestReconcile(t *testing.T) {
 				},
 			},
 			want: want{
-				err: errors.Wrap(errBoom, errDeleteCRs),
+				err: errors.Wrap(errBoom, errOrphanCRD),
+			},
+		},
+		"OrphanCustomResourceDefinition": {
+			reason: "We should remove our controller reference from the CRD we created, and requeue to remove our finalizer, if our deletion policy is Orphan.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								switch v := o.(type) {
+								case *v1.CompositeResourceDefinition:
+									d := v1.CompositeResourceDefinition{}
+									d.SetUID(owner)
+									d.SetDeletionTimestamp(&now)
+									d.Spec.DeletionPolicy = &orphan
+									*v = d
+								case *extv1.CustomResourceDefinition:
+									crd := extv1.CustomResourceDefinition{}
+									crd.SetCreationTimestamp(now)
+									crd.SetOwnerReferences([]metav1.OwnerReference{{UID: owner, Controller: &ctrlr}})
+									*v = crd
+								}
+								return nil
+							}),
+							MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+								want := &extv1.CustomResourceDefinition{}
+								want.SetCreationTimestamp(now)
+								want.SetOwnerReferences([]metav1.OwnerReference{})
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("MockUpdate: -want, +got:\n%s\n", diff)
+								}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+					}),
+					WithCRDRenderer(CRDRenderFn(func(_ *v1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+						return &extv1.CustomResourceDefinition{}, nil
+					})),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: true},
 			},
 		},
 		"ListCustomResourcesError": {
@@ -301,7 +353,6 @@ func TestReconcile(t *testing.T) {
 								}
 								return nil
 							}),
-							MockDeleteAllOf:  test.NewMockDeleteAllOfFn(nil),
 							MockList:         test.NewMockListFn(errBoom),
 							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
 						},
@@ -315,8 +366,49 @@ func TestReconcile(t *testing.T) {
 				err: errors.Wrap(errBoom, errListCRs),
 			},
 		},
-		"WaitForDeleteAllOf": {
-			reason: "We should record the pending deletion of defined resources.",
+		"DeletionBlocked": {
+			reason: "We should requeue without deleting our defined resources if our deletion policy is Block.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								switch v := o.(type) {
+								case *v1.CompositeResourceDefinition:
+									d := v1.CompositeResourceDefinition{}
+									d.SetUID(owner)
+									d.SetDeletionTimestamp(&now)
+									*v = d
+								case *extv1.CustomResourceDefinition:
+									crd := extv1.CustomResourceDefinition{}
+									crd.SetCreationTimestamp(now)
+									crd.SetOwnerReferences([]metav1.OwnerReference{{UID: owner, Controller: &ctrlr}})
+									*v = crd
+								}
+								return nil
+							}),
+							MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+								v := o.(*unstructured.UnstructuredList)
+								*v = unstructured.UnstructuredList{
+									Items: []unstructured.Unstructured{{}, {}},
+								}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+					}),
+					WithCRDRenderer(CRDRenderFn(func(_ *v1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+						return &extv1.CustomResourceDefinition{}, nil
+					})),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: true},
+			},
+		},
+		"DeleteCustomResourcesError": {
+			reason: "We should return any error we encounter while deleting defined resources.",
 			args: args{
 				mgr: &fake.Manager{},
 				opts: []ReconcilerOption{
@@ -328,6 +420,7 @@ func TestReconcile(t *testing.T) {
 									d := v1.CompositeResourceDefinition{}
 									d.SetUID(owner)
 									d.SetDeletionTimestamp(&now)
+									d.Spec.DeletionPolicy = &cascade
 									*v = d
 								case *extv1.CustomResourceDefinition:
 									crd := extv1.CustomResourceDefinition{}
@@ -337,7 +430,6 @@ func TestReconcile(t *testing.T) {
 								}
 								return nil
 							}),
-							MockDeleteAllOf: test.NewMockDeleteAllOfFn(nil),
 							MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
 								v := o.(*unstructured.UnstructuredList)
 								*v = unstructured.UnstructuredList{
@@ -345,6 +437,62 @@ func TestReconcile(t *testing.T) {
 								}
 								return nil
 							}),
+							MockDelete:       test.NewMockDeleteFn(errBoom),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+					}),
+					WithCRDRenderer(CRDRenderFn(func(_ *v1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+						return &extv1.CustomResourceDefinition{}, nil
+					})),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errDeleteCRs),
+			},
+		},
+		"DeleteCustomResourcesInOrder": {
+			reason: "We should delete defined resources that aren't part of another resource or bound to a claim first, and requeue to wait for their deletion.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								switch v := o.(type) {
+								case *v1.CompositeResourceDefinition:
+									d := v1.CompositeResourceDefinition{}
+									d.SetUID(owner)
+									d.SetDeletionTimestamp(&now)
+									d.Spec.DeletionPolicy = &cascade
+									*v = d
+								case *extv1.CustomResourceDefinition:
+									crd := extv1.CustomResourceDefinition{}
+									crd.SetCreationTimestamp(now)
+									crd.SetOwnerReferences([]metav1.OwnerReference{{UID: owner, Controller: &ctrlr}})
+									*v = crd
+								}
+								return nil
+							}),
+							MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+								v := o.(*unstructured.UnstructuredList)
+								root := unstructured.Unstructured{Object: map[string]interface{}{}}
+								root.SetName("root")
+								composed := unstructured.Unstructured{Object: map[string]interface{}{}}
+								composed.SetName("composed")
+								composed.SetOwnerReferences([]metav1.OwnerReference{{UID: "parent", Controller: &ctrlr}})
+								claimed := unstructured.Unstructured{Object: map[string]interface{}{
+									"spec": map[string]interface{}{"claimRef": map[string]interface{}{"name": "claim"}},
+								}}
+								claimed.SetName("claimed")
+								*v = unstructured.UnstructuredList{Items: []unstructured.Unstructured{composed, root, claimed}}
+								return nil
+							}),
+							MockDelete: test.NewMockDeleteFn(nil, func(o client.Object) error {
+								if o.GetName() != "root" {
+									t.Errorf("MockDelete: want only the root composite resource deleted, got %q", o.GetName())
+								}
+								return nil
+							}),
 							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
 						},
 					}),
@@ -379,7 +527,6 @@ func TestReconcile(t *testing.T) {
 								}
 								return nil
 							}),
-							MockDeleteAllOf:  test.NewMockDeleteAllOfFn(nil),
 							MockList:         test.NewMockListFn(nil),
 							MockDelete:       test.NewMockDeleteFn(errBoom),
 							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
@@ -416,9 +563,8 @@ func TestReconcile(t *testing.T) {
 								}
 								return nil
 							}),
-							MockDeleteAllOf: test.NewMockDeleteAllOfFn(nil),
-							MockList:        test.NewMockListFn(nil),
-							MockDelete:      test.NewMockDeleteFn(nil),
+							MockList:   test.NewMockListFn(nil),
+							MockDelete: test.NewMockDeleteFn(nil),
 							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(got client.Object) error {
 								want := &v1.CompositeResourceDefinition{}
 								want.SetUID(owner)
@@ -539,9 +685,12 @@ func TestReconcile(t *testing.T) {
 					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
 						return nil
 					}}),
+					WithUsageCounter(UsageCounterFn(func(_ context.Context, _ *v1.CompositeResourceDefinition) (int64, *int64, error) {
+						return 0, nil, nil
+					})),
 					WithControllerEngine(&MockEngine{
 						MockErr:   func(_ string) error { return nil },
-						MockStart: func(_ string, _ kcontroller.Options, _ ...controller.Watch) error { return errBoom },
+						MockStart: func(_ string, _ kcontroller.Options, _ ...engine.Watch) error { return errBoom },
 					}),
 				},
 			},
@@ -551,7 +700,7 @@ func TestReconcile(t *testing.T) {
 			},
 		},
 		"SuccessfulStart": {
-			reason: "We should return without requeueing if we successfully ensured our CRD exists and controller is started.",
+			reason: "We should count our composite resources and not requeue if we successfully ensured our CRD exists and controller is started.",
 			args: args{
 				mgr: &fake.Manager{},
 				opts: []ReconcilerOption{
@@ -561,8 +710,9 @@ func TestReconcile(t *testing.T) {
 							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
 								want := &v1.CompositeResourceDefinition{}
 								want.Status.SetConditions(v1.WatchingComposite())
+								want.Status.Usage = &v1.CompositeResourceDefinitionUsage{Composites: 2}
 
-								if diff := cmp.Diff(want, o); diff != "" {
+								if diff := cmp.Diff(want, o, ignoreSampleTimes); diff != "" {
 									t.Errorf("-want, +got:\n%s", diff)
 								}
 								return nil
@@ -584,9 +734,12 @@ func TestReconcile(t *testing.T) {
 					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
 						return nil
 					}}),
+					WithUsageCounter(UsageCounterFn(func(_ context.Context, _ *v1.CompositeResourceDefinition) (int64, *int64, error) {
+						return 2, nil, nil
+					})),
 					WithControllerEngine(&MockEngine{
 						MockErr:   func(name string) error { return errBoom }, // This error should only be logged.
-						MockStart: func(_ string, _ kcontroller.Options, _ ...controller.Watch) error { return nil }},
+						MockStart: func(_ string, _ kcontroller.Options, _ ...engine.Watch) error { return nil }},
 					),
 				},
 			},
@@ -594,8 +747,61 @@ func TestReconcile(t *testing.T) {
 				r: reconcile.Result{Requeue: false},
 			},
 		},
+		"SuccessfulPause": {
+			reason: "We should stop our controller if our XRD is paused, and requeue after the sample interval if we can't count our composite resources.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								if d, ok := o.(*v1.CompositeResourceDefinition); ok {
+									d.SetAnnotations(map[string]string{v1.AnnotationKeyPaused: "true"})
+								}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.CompositeResourceDefinition{}
+								want.SetAnnotations(map[string]string{v1.AnnotationKeyPaused: "true"})
+								want.Status.SetConditions(v1.PausedComposite())
+
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+						Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+							return nil
+						}),
+					}),
+					WithCRDRenderer(CRDRenderFn(func(_ *v1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+						return &extv1.CustomResourceDefinition{
+							Status: extv1.CustomResourceDefinitionStatus{
+								Conditions: []extv1.CustomResourceDefinitionCondition{
+									{Type: extv1.Established, Status: extv1.ConditionTrue},
+								},
+							},
+						}, nil
+					})),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithUsageCounter(UsageCounterFn(func(_ context.Context, _ *v1.CompositeResourceDefinition) (int64, *int64, error) {
+						return 0, nil, errBoom
+					})),
+					WithControllerEngine(&MockEngine{
+						MockErr:  func(_ string) error { return nil },
+						MockStop: func(_ string) {},
+					}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: usageSampleInterval},
+			},
+		},
 		"SuccessfulUpdateControllerVersion": {
-			reason: "We should return without requeueing if we successfully ensured our CRD exists, the old controller stopped, and the new one started.",
+			reason: "We should not requeue if we successfully ensured our CRD exists, the old controller stopped, and the new one started.",
 			args: args{
 				mgr: &fake.Manager{},
 				opts: []ReconcilerOption{
@@ -618,8 +824,9 @@ func TestReconcile(t *testing.T) {
 								}
 								want.Status.Controllers.CompositeResourceTypeRef = v1.TypeReference{APIVersion: "new"}
 								want.Status.SetConditions(v1.WatchingComposite())
+								want.Status.Usage = &v1.CompositeResourceDefinitionUsage{}
 
-								if diff := cmp.Diff(want, o); diff != "" {
+								if diff := cmp.Diff(want, o, ignoreSampleTimes); diff != "" {
 									t.Errorf("-want, +got:\n%s", diff)
 								}
 								return nil
@@ -641,9 +848,12 @@ func TestReconcile(t *testing.T) {
 					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
 						return nil
 					}}),
+					WithUsageCounter(UsageCounterFn(func(_ context.Context, _ *v1.CompositeResourceDefinition) (int64, *int64, error) {
+						return 0, nil, nil
+					})),
 					WithControllerEngine(&MockEngine{
 						MockErr:   func(name string) error { return nil },
-						MockStart: func(_ string, _ kcontroller.Options, _ ...controller.Watch) error { return nil },
+						MockStart: func(_ string, _ kcontroller.Options, _ ...engine.Watch) error { return nil },
 						MockStop:  func(_ string) {},
 					}),
 				},
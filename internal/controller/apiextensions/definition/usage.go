@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"context"
+	"time"
+
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+const (
+	errCountXRs    = "cannot count composite resources"
+	errCountClaims = "cannot count composite resource claims"
+)
+
+// usageSampleInterval is the minimum interval at which we count the composite
+// resources and claims of a definition.
+const usageSampleInterval = 1 * time.Minute
+
+// usagePageSize is how many resources we list at once when the API server
+// can't tell us how many resources remain to be listed.
+const usagePageSize = 500
+
+// A UsageCounter counts the composite resources and claims of a
+// CompositeResourceDefinition.
+type UsageCounter interface {
+	// CountUsage returns the number of composite resources and claims of the
+	// supplied definition. The returned claims are nil if the definition
+	// doesn't offer a claim.
+	CountUsage(ctx context.Context, d *v1.CompositeResourceDefinition) (composites int64, claims *int64, err error)
+}
+
+// A UsageCounterFn counts the composite resources and claims of a
+// CompositeResourceDefinition.
+type UsageCounterFn func(ctx context.Context, d *v1.CompositeResourceDefinition) (composites int64, claims *int64, err error)
+
+// CountUsage returns the number of composite resources and claims of the
+// supplied definition.
+func (fn UsageCounterFn) CountUsage(ctx context.Context, d *v1.CompositeResourceDefinition) (int64, *int64, error) {
+	return fn(ctx, d)
+}
+
+// An APIUsageCounter counts composite resources and claims by listing their
+// metadata using the Kubernetes API.
+type APIUsageCounter struct {
+	client client.Reader
+}
+
+// NewAPIUsageCounter returns a UsageCounter that counts the composite resources
+// and claims of a definition by listing their metadata. The supplied reader
+// should read directly from the API server; a cached reader would start, and
+// never stop, an informer for every type it counted.
+func NewAPIUsageCounter(c client.Reader) *APIUsageCounter {
+	return &APIUsageCounter{client: c}
+}
+
+// CountUsage returns the number of composite resources and claims of the
+// supplied definition.
+func (c *APIUsageCounter) CountUsage(ctx context.Context, d *v1.CompositeResourceDefinition) (int64, *int64, error) {
+	composites, err := c.count(ctx, d.GetCompositeGroupVersionKind())
+	if err != nil {
+		return 0, nil, errors.Wrap(err, errCountXRs)
+	}
+	if !d.OffersClaim() {
+		return composites, nil, nil
+	}
+	claims, err := c.count(ctx, d.GetClaimGroupVersionKind())
+	if err != nil {
+		return 0, nil, errors.Wrap(err, errCountClaims)
+	}
+	return composites, &claims, nil
+}
+
+func (c *APIUsageCounter) count(ctx context.Context, gvk schema.GroupVersionKind) (int64, error) {
+	// We only need to know how many resources exist, so we list the metadata
+	// of only one of them and let the API server tell us how many remain. It
+	// can't always tell, in which case we page through the rest. A type that
+	// isn't served yet has no resources.
+	n := int64(0)
+	opts := []client.ListOption{client.Limit(1)}
+	for {
+		l := &metav1.PartialObjectMetadataList{}
+		l.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.client.List(ctx, l, opts...); err != nil {
+			return 0, resource.Ignore(kmeta.IsNoMatchError, err)
+		}
+		n += int64(len(l.Items))
+		if r := l.GetRemainingItemCount(); r != nil {
+			return n + *r, nil
+		}
+		if l.GetContinue() == "" {
+			return n, nil
+		}
+		opts = []client.ListOption{client.Limit(usagePageSize), client.Continue(l.GetContinue())}
+	}
+}
+
+// needsUsageSample returns true if the usage of the supplied definition was
+// never counted, or was last counted before the sample interval.
+func needsUsageSample(d *v1.CompositeResourceDefinition, now time.Time) bool {
+	u := d.Status.Usage
+	return u == nil || now.Sub(u.LastSampleTime.Time) >= usageSampleInterval
+}
+
+// sampleUsage returns the supplied observed usage updated with the supplied
+// counts. The transition time changes only if either count changed.
+func sampleUsage(observed *v1.CompositeResourceDefinitionUsage, composites int64, claims *int64, now metav1.Time) *v1.CompositeResourceDefinitionUsage {
+	u := &v1.CompositeResourceDefinitionUsage{
+		Composites:         composites,
+		Claims:             claims,
+		LastSampleTime:     now,
+		LastTransitionTime: now,
+	}
+	if observed != nil && observed.Composites == composites && equalCounts(observed.Claims, claims) {
+		u.LastTransitionTime = observed.LastTransitionTime
+	}
+	return u
+}
+
+func equalCounts(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package definition
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestAPIUsageCounterCountUsage(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	xrd := &v1.CompositeResourceDefinition{
+		Spec: v1.CompositeResourceDefinitionSpec{
+			Group:    "example.org",
+			Names:    crdNames("XWidget"),
+			Versions: []v1.CompositeResourceDefinitionVersion{{Name: "v1", Referenceable: true}},
+		},
+	}
+	withClaim := xrd.DeepCopy()
+	cn := crdNames("Widget")
+	withClaim.Spec.ClaimNames = &cn
+
+	// Our mock lists two metadata-only items of any kind, except claims, of
+	// which it lists one.
+	list := func(o client.ObjectList) error {
+		l := o.(*metav1.PartialObjectMetadataList)
+		l.Items = []metav1.PartialObjectMetadata{{}, {}}
+		if l.GetObjectKind().GroupVersionKind().Kind == "WidgetList" {
+			l.Items = l.Items[:1]
+		}
+		return nil
+	}
+
+	type args struct {
+		client client.Reader
+		d      *v1.CompositeResourceDefinition
+	}
+	type want struct {
+		composites int64
+		claims     *int64
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ErrCountXRs": {
+			reason: "We should return any error encountered counting composite resources.",
+			args: args{
+				client: &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+				d:      xrd,
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCountXRs),
+			},
+		},
+		"NoClaim": {
+			reason: "We should count only composite resources if the definition doesn't offer a claim.",
+			args: args{
+				client: &test.MockClient{MockList: test.NewMockListFn(nil, list)},
+				d:      xrd,
+			},
+			want: want{
+				composites: 2,
+			},
+		},
+		"WithClaim": {
+			reason: "We should count composite resources and claims if the definition offers a claim.",
+			args: args{
+				client: &test.MockClient{MockList: test.NewMockListFn(nil, list)},
+				d:      withClaim,
+			},
+			want: want{
+				composites: 2,
+				claims:     pointer.Int64(1),
+			},
+		},
+		"RemainingItemCount": {
+			reason: "We should list only one resource, and count the rest using the remaining item count the API server returns.",
+			args: args{
+				client: &test.MockClient{MockList: func(_ context.Context, o client.ObjectList, opts ...client.ListOption) error {
+					lo := &client.ListOptions{}
+					lo.ApplyOptions(opts)
+					if lo.Limit != 1 {
+						t.Errorf("List(...): want limit 1, got %d", lo.Limit)
+					}
+					l := o.(*metav1.PartialObjectMetadataList)
+					l.Items = []metav1.PartialObjectMetadata{{}}
+					l.SetRemainingItemCount(pointer.Int64(41))
+					l.SetContinue("more")
+					return nil
+				}},
+				d: xrd,
+			},
+			want: want{
+				composites: 42,
+			},
+		},
+		"NoRemainingItemCount": {
+			reason: "We should page through the remaining resources if the API server doesn't return a remaining item count.",
+			args: args{
+				client: &test.MockClient{MockList: func(_ context.Context, o client.ObjectList, opts ...client.ListOption) error {
+					lo := &client.ListOptions{}
+					lo.ApplyOptions(opts)
+					l := o.(*metav1.PartialObjectMetadataList)
+					switch lo.Continue {
+					case "":
+						l.Items = []metav1.PartialObjectMetadata{{}}
+						l.SetContinue("page-2")
+					case "page-2":
+						l.Items = []metav1.PartialObjectMetadata{{}, {}, {}}
+						l.SetContinue("page-3")
+					case "page-3":
+						l.Items = []metav1.PartialObjectMetadata{{}, {}}
+					}
+					return nil
+				}},
+				d: xrd,
+			},
+			want: want{
+				composites: 6,
+			},
+		},
+		"NotServed": {
+			reason: "A type that isn't served yet should have no resources.",
+			args: args{
+				client: &test.MockClient{MockList: test.NewMockListFn(&kmeta.NoKindMatchError{GroupKind: schema.GroupKind{Group: "example.org", Kind: "Widget"}})},
+				d:      withClaim,
+			},
+			want: want{
+				composites: 0,
+				claims:     pointer.Int64(0),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewAPIUsageCounter(tc.args.client)
+			composites, claims, err := c.CountUsage(context.Background(), tc.args.d)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.CountUsage(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.composites, composites); diff != "" {
+				t.Errorf("\n%s\nc.CountUsage(...): -want composites, +got composites:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.claims, claims); diff != "" {
+				t.Errorf("\n%s\nc.CountUsage(...): -want claims, +got claims:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSampleUsage(t *testing.T) {
+	then := metav1.NewTime(time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC))
+	now := metav1.NewTime(then.Add(usageSampleInterval))
+
+	type args struct {
+		observed   *v1.CompositeResourceDefinitionUsage
+		composites int64
+		claims     *int64
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   *v1.CompositeResourceDefinitionUsage
+	}{
+		"FirstSample": {
+			reason: "The first sample should transition at the time it was sampled.",
+			args: args{
+				composites: 2,
+				claims:     pointer.Int64(1),
+			},
+			want: &v1.CompositeResourceDefinitionUsage{Composites: 2, Claims: pointer.Int64(1), LastSampleTime: now, LastTransitionTime: now},
+		},
+		"Unchanged": {
+			reason: "A sample whose counts are unchanged should keep its transition time.",
+			args: args{
+				observed:   &v1.CompositeResourceDefinitionUsage{Composites: 2, Claims: pointer.Int64(1), LastSampleTime: then, LastTransitionTime: then},
+				composites: 2,
+				claims:     pointer.Int64(1),
+			},
+			want: &v1.CompositeResourceDefinitionUsage{Composites: 2, Claims: pointer.Int64(1), LastSampleTime: now, LastTransitionTime: then},
+		},
+		"ClaimsChanged": {
+			reason: "A sample whose claim count changed should transition at the time it was sampled.",
+			args: args{
+				observed:   &v1.CompositeResourceDefinitionUsage{Composites: 2, Claims: pointer.Int64(1), LastSampleTime: then, LastTransitionTime: then},
+				composites: 2,
+				claims:     pointer.Int64(0),
+			},
+			want: &v1.CompositeResourceDefinitionUsage{Composites: 2, Claims: pointer.Int64(0), LastSampleTime: now, LastTransitionTime: now},
+		},
+		"ClaimRemoved": {
+			reason: "A sample of a definition that no longer offers a claim should transition at the time it was sampled.",
+			args: args{
+				observed:   &v1.CompositeResourceDefinitionUsage{Composites: 2, Claims: pointer.Int64(0), LastSampleTime: then, LastTransitionTime: then},
+				composites: 2,
+			},
+			want: &v1.CompositeResourceDefinitionUsage{Composites: 2, LastSampleTime: now, LastTransitionTime: now},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := sampleUsage(tc.args.observed, tc.args.composites, tc.args.claims, now)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nsampleUsage(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func crdNames(kind string) extv1.CustomResourceDefinitionNames {
+	return extv1.CustomResourceDefinitionNames{Kind: kind, ListKind: kind + "List", Plural: kind + "s"}
+}
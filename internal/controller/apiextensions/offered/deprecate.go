@@ -0,0 +1,246 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offered
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/internal/controller/apiextensions/claim"
+)
+
+const (
+	// AnnotationKeyDeprecatedAt is set on a composite resource claim
+	// CustomResourceDefinition when it is superseded because the claim names
+	// of the CompositeResourceDefinition that offers it changed. Its value is
+	// the RFC3339 time at which the CRD was deprecated.
+	AnnotationKeyDeprecatedAt = "offered.apiextensions.crossplane.io/deprecated-at"
+
+	// DefaultDeprecationWindow is how long a superseded composite resource
+	// claim CustomResourceDefinition is kept before it may be deleted.
+	DefaultDeprecationWindow = 30 * 24 * time.Hour
+
+	// deprecatedPollInterval is how often we check whether a superseded
+	// CRD whose deprecation window has elapsed is still in use.
+	deprecatedPollInterval = 1 * time.Minute
+)
+
+// Error strings.
+const (
+	errListCRDs          = "cannot list CustomResourceDefinitions"
+	errDeprecateCRD      = "cannot deprecate superseded composite resource claim CustomResourceDefinition"
+	errParseDeprecatedAt = "cannot parse deprecation time of superseded composite resource claim CustomResourceDefinition"
+	errListDeprecatedCRs = "cannot list composite resource claims of superseded CustomResourceDefinition"
+	errDeleteDeprecated  = "cannot delete superseded composite resource claim CustomResourceDefinition"
+	errStartDeprecated   = "cannot start superseded composite resource claim controller"
+
+	errFmtDeprecationWarning = "%s is deprecated; use %s instead"
+)
+
+// A Deprecation describes the superseded claim CRDs of a
+// CompositeResourceDefinition.
+type Deprecation struct {
+	// Deprecated claim CRDs are superseded, but not yet deleted. Claims of
+	// their kind must still be reconciled until they are migrated.
+	Deprecated []*extv1.CustomResourceDefinition
+
+	// RequeueAfter is how long to wait before deprecating again may make
+	// progress, or zero if there are no superseded CRDs left.
+	RequeueAfter time.Duration
+}
+
+// A ClaimCRDDeprecator deprecates, and eventually deletes, composite resource
+// claim CustomResourceDefinitions that a CompositeResourceDefinition no longer
+// offers because its claim names changed.
+type ClaimCRDDeprecator interface {
+	// Deprecate all claim CRDs offered by the supplied XRD except the named
+	// current CRD.
+	Deprecate(ctx context.Context, d *v1.CompositeResourceDefinition, current string) (Deprecation, error)
+}
+
+// A ClaimCRDDeprecateFn deprecates superseded claim CRDs.
+type ClaimCRDDeprecateFn func(ctx context.Context, d *v1.CompositeResourceDefinition, current string) (Deprecation, error)
+
+// Deprecate superseded claim CRDs.
+func (fn ClaimCRDDeprecateFn) Deprecate(ctx context.Context, d *v1.CompositeResourceDefinition, current string) (Deprecation, error) {
+	return fn(ctx, d, current)
+}
+
+// An APIClaimCRDDeprecator deprecates superseded claim CRDs using the
+// Kubernetes API. Superseded CRDs are marked deprecated so that the API server
+// warns anyone still using them, and are deleted once their deprecation window
+// has elapsed and no claims of their kind remain.
+type APIClaimCRDDeprecator struct {
+	client client.Client
+	window time.Duration
+}
+
+// NewAPIClaimCRDDeprecator returns a ClaimCRDDeprecator that keeps superseded
+// CRDs for at least the supplied deprecation window.
+func NewAPIClaimCRDDeprecator(c client.Client, window time.Duration) *APIClaimCRDDeprecator {
+	return &APIClaimCRDDeprecator{client: c, window: window}
+}
+
+// Deprecate all claim CRDs offered by the supplied XRD except the named
+// current CRD.
+func (c *APIClaimCRDDeprecator) Deprecate(ctx context.Context, d *v1.CompositeResourceDefinition, current string) (Deprecation, error) {
+	l := &extv1.CustomResourceDefinitionList{}
+	if err := c.client.List(ctx, l); err != nil {
+		return Deprecation{}, errors.Wrap(err, errListCRDs)
+	}
+
+	dep := Deprecation{}
+	for i := range l.Items {
+		crd := &l.Items[i]
+
+		// The XRD also controls the cluster scoped CRD of the composite
+		// resource it defines. We only deal with claims here.
+		if crd.GetName() == current || crd.Spec.Scope != extv1.NamespaceScoped || !metav1.IsControlledBy(crd, d) {
+			continue
+		}
+
+		w, err := c.deprecate(ctx, d, crd)
+		if err != nil {
+			return Deprecation{}, err
+		}
+
+		// We only wait for CRDs we didn't delete.
+		if w == 0 {
+			continue
+		}
+		dep.Deprecated = append(dep.Deprecated, crd)
+		if dep.RequeueAfter == 0 || w < dep.RequeueAfter {
+			dep.RequeueAfter = w
+		}
+	}
+
+	return dep, nil
+}
+
+// deprecate the supplied superseded CRD. It returns how long to wait before
+// the CRD may be deleted, or zero if it was deleted.
+func (c *APIClaimCRDDeprecator) deprecate(ctx context.Context, d *v1.CompositeResourceDefinition, crd *extv1.CustomResourceDefinition) (time.Duration, error) {
+	at, ok := crd.GetAnnotations()[AnnotationKeyDeprecatedAt]
+	if !ok {
+		warning := fmt.Sprintf(errFmtDeprecationWarning, crd.Spec.Names.Kind, d.Spec.ClaimNames.Kind)
+		for i := range crd.Spec.Versions {
+			crd.Spec.Versions[i].Deprecated = true
+			crd.Spec.Versions[i].DeprecationWarning = &warning
+		}
+		meta.AddAnnotations(crd, map[string]string{AnnotationKeyDeprecatedAt: time.Now().UTC().Format(time.RFC3339)})
+		return c.window, errors.Wrap(c.client.Update(ctx, crd), errDeprecateCRD)
+	}
+
+	t, err := time.Parse(time.RFC3339, at)
+	if err != nil {
+		return 0, errors.Wrap(err, errParseDeprecatedAt)
+	}
+	if remaining := time.Until(t.Add(c.window)); remaining > 0 {
+		return remaining, nil
+	}
+
+	cl := &kunstructured.UnstructuredList{}
+	cl.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   crd.Spec.Group,
+		Version: storageVersion(crd),
+		Kind:    crd.Spec.Names.ListKind,
+	})
+	if err := c.client.List(ctx, cl, client.Limit(1)); resource.Ignore(kmeta.IsNoMatchError, err) != nil {
+		return 0, errors.Wrap(err, errListDeprecatedCRs)
+	}
+
+	// Claims of the superseded kind still exist. We keep the CRD around until
+	// they have been migrated or deleted.
+	if len(cl.Items) > 0 {
+		return deprecatedPollInterval, nil
+	}
+
+	return 0, errors.Wrap(resource.IgnoreNotFound(c.client.Delete(ctx, crd)), errDeleteDeprecated)
+}
+
+func storageVersion(crd *extv1.CustomResourceDefinition) string {
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	if len(crd.Spec.Versions) > 0 {
+		return crd.Spec.Versions[0].Name
+	}
+	return ""
+}
+
+// claimKind returns the kind of claim defined by the supplied CRD, at its
+// storage version.
+func claimKind(crd *extv1.CustomResourceDefinition) schema.GroupVersionKind {
+	return schema.GroupVersionKind{Group: crd.Spec.Group, Version: storageVersion(crd), Kind: crd.Spec.Names.Kind}
+}
+
+// deprecatedControllerName returns the name of the controller that reconciles
+// claims of the supplied superseded claim CRD of the named XRD.
+func deprecatedControllerName(xrd, crd string) string {
+	return claim.ControllerName(xrd) + "/superseded/" + crd
+}
+
+// deprecatedControllers tracks the names of the controllers that reconcile
+// claims of superseded claim CRDs, by the name of the XRD that superseded
+// them.
+type deprecatedControllers struct {
+	mu    sync.Mutex
+	names map[string][]string
+}
+
+// Replace the names of the controllers of the named XRD. It returns any names
+// that were replaced and are no longer running.
+func (c *deprecatedControllers) Replace(xrd string, names []string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	running := make(map[string]bool, len(names))
+	for _, n := range names {
+		running[n] = true
+	}
+	var stale []string
+	for _, n := range c.names[xrd] {
+		if !running[n] {
+			stale = append(stale, n)
+		}
+	}
+
+	if c.names == nil {
+		c.names = map[string][]string{}
+	}
+	c.names[xrd] = names
+	if len(names) == 0 {
+		delete(c.names, xrd)
+	}
+	return stale
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offered
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestClaimCRDDeprecate(t *testing.T) {
+	errBoom := errors.New("boom")
+	window := time.Hour
+	ctrlr := true
+
+	d := &v1.CompositeResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{UID: types.UID("definitely-a-uuid")},
+		Spec: v1.CompositeResourceDefinitionSpec{
+			ClaimNames: &extv1.CustomResourceDefinitionNames{Kind: "NewClaim"},
+		},
+	}
+
+	superseded := func(at *time.Time) extv1.CustomResourceDefinition {
+		crd := extv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "oldclaims.example.org",
+				OwnerReferences: []metav1.OwnerReference{{UID: d.GetUID(), Controller: &ctrlr}},
+			},
+			Spec: extv1.CustomResourceDefinitionSpec{
+				Group: "example.org",
+				Names: extv1.CustomResourceDefinitionNames{Kind: "OldClaim", ListKind: "OldClaimList"},
+				Scope: extv1.NamespaceScoped,
+				Versions: []extv1.CustomResourceDefinitionVersion{
+					{Name: "v1", Storage: true},
+				},
+			},
+		}
+		if at != nil {
+			meta.AddAnnotations(&crd, map[string]string{AnnotationKeyDeprecatedAt: at.Format(time.RFC3339)})
+		}
+		return crd
+	}
+
+	listCRDs := func(items ...extv1.CustomResourceDefinition) test.MockListFn {
+		return func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			switch l := obj.(type) {
+			case *extv1.CustomResourceDefinitionList:
+				l.Items = items
+			case *kunstructured.UnstructuredList:
+				l.Items = nil
+			}
+			return nil
+		}
+	}
+
+	type args struct {
+		client  client.Client
+		current string
+	}
+	type want struct {
+		wait       time.Duration
+		deprecated []string
+		err        error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ListCRDsError": {
+			reason: "We should return any error encountered listing CRDs.",
+			args: args{
+				client: &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errListCRDs),
+			},
+		},
+		"NothingSuperseded": {
+			reason: "We should ignore the current claim CRD, CRDs we don't control, and cluster scoped CRDs.",
+			args: args{
+				client: &test.MockClient{MockList: listCRDs(
+					extv1.CustomResourceDefinition{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:            "newclaims.example.org",
+							OwnerReferences: []metav1.OwnerReference{{UID: d.GetUID(), Controller: &ctrlr}},
+						},
+						Spec: extv1.CustomResourceDefinitionSpec{Scope: extv1.NamespaceScoped},
+					},
+					extv1.CustomResourceDefinition{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:            "composites.example.org",
+							OwnerReferences: []metav1.OwnerReference{{UID: d.GetUID(), Controller: &ctrlr}},
+						},
+						Spec: extv1.CustomResourceDefinitionSpec{Scope: extv1.ClusterScoped},
+					},
+					extv1.CustomResourceDefinition{
+						ObjectMeta: metav1.ObjectMeta{Name: "unrelated.example.org"},
+						Spec:       extv1.CustomResourceDefinitionSpec{Scope: extv1.NamespaceScoped},
+					},
+				)},
+				current: "newclaims.example.org",
+			},
+			want: want{
+				wait: 0,
+			},
+		},
+		"MarkDeprecated": {
+			reason: "We should mark a newly superseded CRD deprecated and wait for the deprecation window.",
+			args: args{
+				client: &test.MockClient{
+					MockList: listCRDs(superseded(nil)),
+					MockUpdate: test.NewMockUpdateFn(nil, func(obj client.Object) error {
+						crd := obj.(*extv1.CustomResourceDefinition)
+						if _, ok := crd.GetAnnotations()[AnnotationKeyDeprecatedAt]; !ok {
+							t.Errorf("Update(...): missing %s annotation", AnnotationKeyDeprecatedAt)
+						}
+						want := "OldClaim is deprecated; use NewClaim instead"
+						for _, v := range crd.Spec.Versions {
+							if !v.Deprecated || v.DeprecationWarning == nil || *v.DeprecationWarning != want {
+								t.Errorf("Update(...): version %s: want deprecated with warning %q", v.Name, want)
+							}
+						}
+						return nil
+					}),
+				},
+				current: "newclaims.example.org",
+			},
+			want: want{
+				wait:       window,
+				deprecated: []string{"oldclaims.example.org"},
+			},
+		},
+		"MarkDeprecatedError": {
+			reason: "We should return any error encountered marking a superseded CRD deprecated.",
+			args: args{
+				client: &test.MockClient{
+					MockList:   listCRDs(superseded(nil)),
+					MockUpdate: test.NewMockUpdateFn(errBoom),
+				},
+				current: "newclaims.example.org",
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errDeprecateCRD),
+			},
+		},
+		"WindowNotElapsed": {
+			reason: "We should not delete a superseded CRD before its deprecation window elapses.",
+			args: args{
+				client: &test.MockClient{
+					MockList: listCRDs(superseded(func() *time.Time { t := time.Now(); return &t }())),
+				},
+				current: "newclaims.example.org",
+			},
+			want: want{
+				wait:       window,
+				deprecated: []string{"oldclaims.example.org"},
+			},
+		},
+		"StillInUse": {
+			reason: "We should keep a superseded CRD while claims of its kind still exist.",
+			args: args{
+				client: &test.MockClient{
+					MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+						switch l := obj.(type) {
+						case *extv1.CustomResourceDefinitionList:
+							l.Items = []extv1.CustomResourceDefinition{superseded(func() *time.Time { t := time.Now().Add(-2 * window); return &t }())}
+						case *kunstructured.UnstructuredList:
+							l.Items = []kunstructured.Unstructured{{}}
+						}
+						return nil
+					},
+				},
+				current: "newclaims.example.org",
+			},
+			want: want{
+				wait:       deprecatedPollInterval,
+				deprecated: []string{"oldclaims.example.org"},
+			},
+		},
+		"DeleteUnused": {
+			reason: "We should delete a superseded CRD once its deprecation window elapses and it is unused.",
+			args: args{
+				client: &test.MockClient{
+					MockList:   listCRDs(superseded(func() *time.Time { t := time.Now().Add(-2 * window); return &t }())),
+					MockDelete: test.NewMockDeleteFn(nil),
+				},
+				current: "newclaims.example.org",
+			},
+			want: want{
+				wait: 0,
+			},
+		},
+		"DeleteUnusedError": {
+			reason: "We should return any error encountered deleting a superseded CRD.",
+			args: args{
+				client: &test.MockClient{
+					MockList:   listCRDs(superseded(func() *time.Time { t := time.Now().Add(-2 * window); return &t }())),
+					MockDelete: test.NewMockDeleteFn(errBoom),
+				},
+				current: "newclaims.example.org",
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errDeleteDeprecated),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Deprecation times are only precise to the second.
+			c := NewAPIClaimCRDDeprecator(tc.args.client, window)
+			dep, err := c.Deprecate(context.Background(), d, tc.args.current)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Deprecate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.wait, dep.RequeueAfter.Round(time.Minute)); diff != "" {
+				t.Errorf("\n%s\nc.Deprecate(...): -want wait, +got wait:\n%s", tc.reason, diff)
+			}
+			var deprecated []string
+			for _, crd := range dep.Deprecated {
+				deprecated = append(deprecated, crd.GetName())
+			}
+			if diff := cmp.Diff(tc.want.deprecated, deprecated); diff != "" {
+				t.Errorf("\n%s\nc.Deprecate(...): -want deprecated, +got deprecated:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDeprecatedControllersReplace(t *testing.T) {
+	c := &deprecatedControllers{}
+
+	if diff := cmp.Diff([]string(nil), c.Replace("xrd", []string{"a", "b"})); diff != "" {
+		t.Errorf("c.Replace(...): -want stale, +got stale:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"a"}, c.Replace("xrd", []string{"b", "c"})); diff != "" {
+		t.Errorf("c.Replace(...): -want stale, +got stale:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string(nil), c.Replace("other", nil)); diff != "" {
+		t.Errorf("c.Replace(...): -want stale, +got stale:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"b", "c"}, c.Replace("xrd", nil)); diff != "" {
+		t.Errorf("c.Replace(...): -want stale, +got stale:\n%s", diff)
+	}
+	if diff := cmp.Diff(0, len(c.names)); diff != "" {
+		t.Errorf("len(c.names): -want, +got:\n%s", diff)
+	}
+}
@@ -18,6 +18,7 @@ package offered
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"time"
 
@@ -45,7 +46,11 @@ import (
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	secretsv1alpha1 "github.com/crossplane/crossplane/apis/secrets/v1alpha1"
 	"github.com/crossplane/crossplane/internal/controller/apiextensions/claim"
+	apiextensionscontroller "github.com/crossplane/crossplane/internal/controller/apiextensions/controller"
+	"github.com/crossplane/crossplane/internal/engine"
+	"github.com/crossplane/crossplane/internal/events"
 	"github.com/crossplane/crossplane/internal/features"
+	"github.com/crossplane/crossplane/internal/metrics"
 	"github.com/crossplane/crossplane/internal/xcrd"
 )
 
@@ -56,17 +61,21 @@ const (
 
 // Error strings.
 const (
-	errGetXRD          = "cannot get CompositeResourceDefinition"
-	errRenderCRD       = "cannot render composite resource claim CustomResourceDefinition"
-	errGetCRD          = "cannot get composite resource claim CustomResourceDefinition"
-	errApplyCRD        = "cannot apply rendered composite resource claim CustomResourceDefinition"
-	errUpdateStatus    = "cannot update status of CompositeResourceDefinition"
-	errStartController = "cannot start composite resource claim controller"
-	errAddFinalizer    = "cannot add composite resource claim finalizer"
-	errRemoveFinalizer = "cannot remove composite resource claim finalizer"
-	errDeleteCRD       = "cannot delete composite resource claim CustomResourceDefinition"
-	errListCRs         = "cannot list defined composite resource claims"
-	errDeleteCR        = "cannot delete defined composite resource claim"
+	errGetXRD             = "cannot get CompositeResourceDefinition"
+	errRenderCRD          = "cannot render composite resource claim CustomResourceDefinition"
+	errGetCRD             = "cannot get composite resource claim CustomResourceDefinition"
+	errApplyCRD           = "cannot apply rendered composite resource claim CustomResourceDefinition"
+	errUpdateStatus       = "cannot update status of CompositeResourceDefinition"
+	errStartController    = "cannot start composite resource claim controller"
+	errAddFinalizer       = "cannot add composite resource claim finalizer"
+	errRemoveFinalizer    = "cannot remove composite resource claim finalizer"
+	errRemoveClaimWebhook = "cannot remove composite resource claim webhook"
+	errDeleteCRD          = "cannot delete composite resource claim CustomResourceDefinition"
+	errListCRs            = "cannot list defined composite resource claims"
+	errDeleteCR           = "cannot delete defined composite resource claim"
+	errOrphanCRD          = "cannot orphan composite resource claim CustomResourceDefinition"
+
+	errFmtDeletionBlocked = "deletion is blocked by %d composite resource claims; delete them, or set spec.deletionPolicy to Cascade or Orphan"
 )
 
 // Wait strings.
@@ -85,7 +94,7 @@ const (
 // A ControllerEngine can start and stop Kubernetes controllers on demand.
 type ControllerEngine interface {
 	IsRunning(name string) bool
-	Start(name string, o kcontroller.Options, w ...controller.Watch) error
+	Start(name string, o kcontroller.Options, w ...engine.Watch) error
 	Stop(name string)
 	Err(name string) error
 }
@@ -109,13 +118,14 @@ func (fn CRDRenderFn) Render(d *v1.CompositeResourceDefinition) (*extv1.CustomRe
 // Setup adds a controller that reconciles CompositeResourceDefinitions by
 // defining a composite resource claim and starting a controller to reconcile
 // it.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
+func Setup(mgr ctrl.Manager, o apiextensionscontroller.Options) error {
 	name := "offered/" + strings.ToLower(v1.CompositeResourceDefinitionGroupKind)
 
 	r := NewReconciler(mgr,
 		WithLogger(o.Logger.WithValues("controller", name)),
-		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
-		WithOptions(o))
+		WithRecorder(events.NewDedupingRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)), o.EventSuppressionWindow)),
+		WithControllerEngine(o.ControllerEngine),
+		WithOptions(o.Options))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
@@ -175,6 +185,14 @@ func WithCRDRenderer(c CRDRenderer) ReconcilerOption {
 	}
 }
 
+// WithClaimCRDDeprecator specifies how the Reconciler should deprecate claim
+// CustomResourceDefinitions that are superseded when claim names change.
+func WithClaimCRDDeprecator(c ClaimCRDDeprecator) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.claim.ClaimCRDDeprecator = c
+	}
+}
+
 // WithClientApplicator specifies how the Reconciler should interact with the
 // Kubernetes API.
 func WithClientApplicator(ca resource.ClientApplicator) ReconcilerOption {
@@ -197,9 +215,13 @@ func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
 
 		claim: definition{
 			CRDRenderer:      CRDRenderFn(xcrd.ForCompositeResourceClaim),
-			ControllerEngine: controller.NewEngine(mgr),
+			ControllerEngine: engine.New(mgr),
 			Finalizer:        resource.NewAPIFinalizer(kube, finalizer),
+
+			ClaimCRDDeprecator: NewAPIClaimCRDDeprecator(kube, DefaultDeprecationWindow),
 		},
+		deprecated: &deprecatedControllers{},
+		configs:    &engine.ConfigTracker{},
 
 		log:    logging.NewNopLogger(),
 		record: event.NewNopRecorder(),
@@ -217,6 +239,7 @@ type definition struct {
 	CRDRenderer
 	ControllerEngine
 	resource.Finalizer
+	ClaimCRDDeprecator
 }
 
 // A Reconciler reconciles CompositeResourceDefinitions.
@@ -224,7 +247,9 @@ type Reconciler struct {
 	mgr    manager.Manager
 	client resource.ClientApplicator
 
-	claim definition
+	claim      definition
+	deprecated *deprecatedControllers
+	configs    *engine.ConfigTracker
 
 	log    logging.Logger
 	record event.Recorder
@@ -256,6 +281,34 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		"name", d.GetName(),
 	)
 
+	// An XRD that offered a claim when we last reconciled it may have
+	// stopped offering it. Its claim CRD is orphaned, but nothing reconciles
+	// or validates claims of that kind anymore.
+	if !d.OffersClaim() && d.Status.Controllers.CompositeResourceClaimTypeRef.APIVersion != "" {
+		r.claim.Stop(claim.ControllerName(d.GetName()))
+		r.configs.Stopped(claim.ControllerName(d.GetName()))
+		r.stopDeprecated(d.GetName())
+		log.Debug("Stopped composite resource claim controller")
+		r.record.Event(d, event.Normal(reasonRedactXRC, "Stopped composite resource claim controller"))
+
+		if err := r.removeClaimWebhook(ctx, d); err != nil {
+			log.Debug(errRemoveClaimWebhook, "error", err)
+			err = errors.Wrap(err, errRemoveClaimWebhook)
+			r.record.Event(d, event.Warning(reasonRedactXRC, err))
+			return reconcile.Result{}, err
+		}
+
+		if err := r.claim.RemoveFinalizer(ctx, d); err != nil {
+			log.Debug(errRemoveFinalizer, "error", err)
+			err = errors.Wrap(err, errRemoveFinalizer)
+			r.record.Event(d, event.Warning(reasonRedactXRC, err))
+			return reconcile.Result{}, err
+		}
+
+		d.Status.Controllers.CompositeResourceClaimTypeRef = v1.TypeReference{}
+		return reconcile.Result{}, errors.Wrap(r.client.Status().Update(ctx, d), errUpdateStatus)
+	}
+
 	crd, err := r.claim.Render(d)
 	if err != nil {
 		log.Debug(errRenderCRD, "error", err)
@@ -296,9 +349,18 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			// just in case. This is a no-op if the controller was
 			// already stopped.
 			r.claim.Stop(claim.ControllerName(d.GetName()))
+			r.configs.Stopped(claim.ControllerName(d.GetName()))
+			r.stopDeprecated(d.GetName())
 			log.Debug("Stopped composite resource claim controller")
 			r.record.Event(d, event.Normal(reasonRedactXRC, "Stopped composite resource claim controller"))
 
+			if err := r.removeClaimWebhook(ctx, d); err != nil {
+				log.Debug(errRemoveClaimWebhook, "error", err)
+				err = errors.Wrap(err, errRemoveClaimWebhook)
+				r.record.Event(d, event.Warning(reasonRedactXRC, err))
+				return reconcile.Result{}, err
+			}
+
 			if err := r.claim.RemoveFinalizer(ctx, d); err != nil {
 				log.Debug(errRemoveFinalizer, "error", err)
 				err = errors.Wrap(err, errRemoveFinalizer)
@@ -312,6 +374,22 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			return reconcile.Result{Requeue: false}, nil
 		}
 
+		// We'd delete the CRD, and thus all of our claims, if we
+		// remained its controller. Once it's orphaned we'll stop our
+		// controller and remove our finalizer per the above.
+		if d.GetDeletionPolicy() == v1.DeletionOrphan {
+			removeOwnerReference(crd, d.GetUID())
+			if err := r.client.Update(ctx, crd); err != nil {
+				log.Debug(errOrphanCRD, "error", err)
+				err = errors.Wrap(err, errOrphanCRD)
+				r.record.Event(d, event.Warning(reasonRedactXRC, err))
+				return reconcile.Result{}, err
+			}
+			log.Debug("Orphaned composite resource claim CustomResourceDefinition")
+			r.record.Event(d, event.Normal(reasonRedactXRC, "Orphaned composite resource claim CustomResourceDefinition"))
+			return reconcile.Result{Requeue: true}, nil
+		}
+
 		l := &kunstructured.UnstructuredList{}
 		l.SetGroupVersionKind(d.GetClaimGroupVersionKind())
 		if err := r.client.List(ctx, l); resource.Ignore(kmeta.IsNoMatchError, err) != nil {
@@ -321,6 +399,16 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			return reconcile.Result{}, err
 		}
 
+		// We don't delete claims unless we're told to. We requeue to
+		// check whether they've been deleted, because we won't be
+		// requeued implicitly when they are.
+		if len(l.Items) > 0 && d.GetDeletionPolicy() == v1.DeletionBlock {
+			err := errors.Errorf(errFmtDeletionBlocked, len(l.Items))
+			log.Debug("Cannot delete composite resource claim CustomResourceDefinition", "error", err)
+			r.record.Event(d, event.Warning(reasonRedactXRC, err))
+			return reconcile.Result{Requeue: true}, nil
+		}
+
 		// Ensure all the custom resources we defined are gone before
 		// stopping the controller we started to reconcile them. This
 		// ensures the controller has a chance to execute its cleanup
@@ -351,9 +439,18 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		// The controller should be stopped before the deletion of CRD
 		// so that it doesn't crash.
 		r.claim.Stop(claim.ControllerName(d.GetName()))
+		r.configs.Stopped(claim.ControllerName(d.GetName()))
+		r.stopDeprecated(d.GetName())
 		log.Debug("Stopped composite resource claim controller")
 		r.record.Event(d, event.Normal(reasonRedactXRC, "Stopped composite resource claim controller"))
 
+		if err := r.removeClaimWebhook(ctx, d); err != nil {
+			log.Debug(errRemoveClaimWebhook, "error", err)
+			err = errors.Wrap(err, errRemoveClaimWebhook)
+			r.record.Event(d, event.Warning(reasonRedactXRC, err))
+			return reconcile.Result{}, err
+		}
+
 		if err := r.client.Delete(ctx, crd); resource.IgnoreNotFound(err) != nil {
 			log.Debug(errDeleteCRD, "error", err)
 			err = errors.Wrap(err, errDeleteCRD)
@@ -391,9 +488,31 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{Requeue: true}, nil
 	}
 
+	if err := r.offerClaimWebhook(ctx, d); err != nil {
+		log.Debug("Cannot offer composite resource claim webhook", "error", err)
+		r.record.Event(d, event.Warning(reasonOfferXRC, err))
+		return reconcile.Result{}, err
+	}
+
+	// A paused XRD keeps its claim CRD, and thus its claims, but nothing
+	// reconciles them until it is unpaused. This is a no-op if the
+	// controller was already stopped.
+	if d.IsPaused() {
+		r.claim.Stop(claim.ControllerName(d.GetName()))
+		r.configs.Stopped(claim.ControllerName(d.GetName()))
+		r.stopDeprecated(d.GetName())
+		log.Debug("Paused composite resource claim controller")
+		r.record.Event(d, event.Normal(reasonOfferXRC, "Paused composite resource claim controller"))
+		d.Status.SetConditions(v1.PausedClaim())
+		return reconcile.Result{Requeue: false}, errors.Wrap(r.client.Status().Update(ctx, d), errUpdateStatus)
+	}
+
 	o := []claim.ReconcilerOption{
 		claim.WithLogger(log.WithValues("controller", claim.ControllerName(d.GetName()))),
 		claim.WithRecorder(r.record.WithAnnotations("controller", claim.ControllerName(d.GetName()))),
+		claim.WithMetricsRecorder(metrics.NewPrometheusRecorder(d.GetName())),
+		claim.WithCompositeConfigurator(claim.NewAPIDryRunCompositeConfigurator(r.client, claim.WithClaimNaming(d.GetClaimNaming()))),
+		claim.WithClaimConfigurator(claim.NewAPIClaimConfigurator(r.client, claim.WithClaimStatusFields(d.GetClaimStatusFields()...))),
 	}
 
 	// We only want to enable ExternalSecretStore support if the relevant
@@ -402,7 +521,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	if r.options.Features.Enabled(features.EnableAlphaExternalSecretStores) {
 		pc := claim.ConnectionPropagatorChain{
 			claim.NewAPIConnectionPropagator(r.client),
-			connection.NewDetailsManager(r.client, secretsv1alpha1.StoreConfigGroupVersionKind),
+			claim.NewSecretStoreConnectionPropagator(connection.NewDetailsManager(r.client, secretsv1alpha1.StoreConfigGroupVersionKind)),
 		}
 
 		o = append(o, claim.WithConnectionPropagator(pc), claim.WithConnectionUnpublisher(claim.NewSecretStoreConnectionUnpublisher(connection.NewDetailsManager(r.client, secretsv1alpha1.StoreConfigGroupVersionKind))))
@@ -431,6 +550,20 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			"desired-version", desired.APIVersion))
 	}
 
+	// Claim controllers are configured with some of the definition's spec
+	// fields when they're started, so we restart them, including those of
+	// any superseded claim CRDs, when they change.
+	cfg := fmt.Sprintf("%+v", struct {
+		ClaimNaming       v1.ClaimNaming
+		ClaimStatusFields []string
+	}{d.GetClaimNaming(), d.GetClaimStatusFields()})
+	if r.configs.Changed(claim.ControllerName(d.GetName()), cfg) {
+		r.claim.Stop(claim.ControllerName(d.GetName()))
+		r.stopDeprecated(d.GetName())
+		log.Debug("Controller configuration changed; stopped composite resource claim controllers", "config", cfg)
+		r.record.Event(d, event.Normal(reasonOfferXRC, "Controller configuration changed; stopped composite resource claim controllers"))
+	}
+
 	cm := &kunstructured.Unstructured{}
 	cm.SetGroupVersionKind(d.GetClaimGroupVersionKind())
 
@@ -438,17 +571,83 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	cp.SetGroupVersionKind(d.GetCompositeGroupVersionKind())
 
 	if err := r.claim.Start(claim.ControllerName(d.GetName()), ko,
-		controller.For(cm, &handler.EnqueueRequestForObject{}),
-		controller.For(cp, &EnqueueRequestForClaim{}),
+		engine.For(cm, &handler.EnqueueRequestForObject{}),
+		engine.For(cp, &EnqueueRequestForClaim{}),
 	); err != nil {
 		log.Debug(errStartController, "error", err)
 		err = errors.Wrap(err, errStartController)
 		r.record.Event(d, event.Warning(reasonOfferXRC, err))
 		return reconcile.Result{}, err
 	}
+	r.configs.Started(claim.ControllerName(d.GetName()), cfg)
 	r.record.Event(d, event.Normal(reasonOfferXRC, "(Re)started composite resource claim controller"))
 
+	// Claim CRDs we offered under previous claim names are kept, deprecated,
+	// until their deprecation window elapses and they're no longer in use.
+	dep, err := r.claim.Deprecate(ctx, d, crd.GetName())
+	if err != nil {
+		log.Debug(errDeprecateCRD, "error", err)
+		r.record.Event(d, event.Warning(reasonOfferXRC, err))
+		return reconcile.Result{}, err
+	}
+	if dep.RequeueAfter > 0 {
+		log.Debug("Waiting for superseded composite resource claim CustomResourceDefinitions to be unused", "requeue-after", dep.RequeueAfter)
+	}
+
+	// Claims of a superseded kind are reconciled until their CRD is
+	// deleted, so that they keep working while they're migrated. We stop
+	// the controllers of any superseded CRDs that were deleted.
+	names := make([]string, len(dep.Deprecated))
+	for i, dc := range dep.Deprecated {
+		names[i] = deprecatedControllerName(d.GetName(), dc.GetName())
+	}
+	for _, name := range r.deprecated.Replace(d.GetName(), names) {
+		r.claim.Stop(name)
+	}
+	for i, dc := range dep.Deprecated {
+		dcm := &kunstructured.Unstructured{}
+		dcm.SetGroupVersionKind(claimKind(dc))
+
+		dcr := claim.NewReconciler(r.mgr,
+			resource.CompositeClaimKind(claimKind(dc)),
+			resource.CompositeKind(d.GetCompositeGroupVersionKind()),
+			append(o, claim.WithLogger(log.WithValues("controller", names[i])))...)
+
+		dko := r.options.ForControllerRuntime()
+		dko.Reconciler = ratelimiter.NewReconciler(names[i], dcr, r.options.GlobalRateLimiter)
+
+		if err := r.claim.Start(names[i], dko,
+			engine.For(dcm, &handler.EnqueueRequestForObject{}),
+			engine.For(cp, &EnqueueRequestForClaim{}),
+		); err != nil {
+			log.Debug(errStartDeprecated, "error", err, "controller", names[i])
+			err = errors.Wrap(err, errStartDeprecated)
+			r.record.Event(d, event.Warning(reasonOfferXRC, err))
+			return reconcile.Result{}, err
+		}
+	}
+
 	d.Status.Controllers.CompositeResourceClaimTypeRef = v1.TypeReferenceTo(d.GetClaimGroupVersionKind())
 	d.Status.SetConditions(v1.WatchingClaim())
-	return reconcile.Result{Requeue: false}, errors.Wrap(r.client.Status().Update(ctx, d), errUpdateStatus)
+	return reconcile.Result{RequeueAfter: dep.RequeueAfter}, errors.Wrap(r.client.Status().Update(ctx, d), errUpdateStatus)
+}
+
+// stopDeprecated stops the controllers of any superseded claim CRDs of the
+// named XRD.
+func (r *Reconciler) stopDeprecated(xrd string) {
+	for _, name := range r.deprecated.Replace(xrd, nil) {
+		r.claim.Stop(name)
+	}
+}
+
+// removeOwnerReference removes any owner reference to the supplied UID from
+// the supplied object.
+func removeOwnerReference(o metav1.Object, uid types.UID) {
+	refs := make([]metav1.OwnerReference, 0, len(o.GetOwnerReferences()))
+	for _, ref := range o.GetOwnerReferences() {
+		if ref.UID != uid {
+			refs = append(refs, ref)
+		}
+	}
+	o.SetOwnerReferences(refs)
 }
@@ -32,22 +32,23 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
-	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/internal/controller/apiextensions/claim"
+	"github.com/crossplane/crossplane/internal/engine"
 )
 
 type MockEngine struct {
 	ControllerEngine
-	MockStart func(name string, o kcontroller.Options, w ...controller.Watch) error
+	MockStart func(name string, o kcontroller.Options, w ...engine.Watch) error
 	MockStop  func(name string)
 	MockErr   func(name string) error
 }
 
-func (m *MockEngine) Start(name string, o kcontroller.Options, w ...controller.Watch) error {
+func (m *MockEngine) Start(name string, o kcontroller.Options, w ...engine.Watch) error {
 	return m.MockStart(name, o, w...)
 }
 
@@ -64,6 +65,8 @@ func TestReconcile(t *testing.T) {
 	now := metav1.Now()
 	owner := types.UID("definitely-a-uuid")
 	ctrlr := true
+	orphan := v1.DeletionOrphan
+	cascade := v1.DeletionCascade
 
 	type args struct {
 		mgr  manager.Manager
@@ -72,8 +75,14 @@ func TestReconcile(t *testing.T) {
 	type want struct {
 		r   reconcile.Result
 		err error
+
+		// started controllers, if we care which were started.
+		started []string
 	}
 
+	// The names of the controllers each case started.
+	var started []string
+
 	cases := map[string]struct {
 		reason string
 		args   args
@@ -292,6 +301,7 @@ func TestReconcile(t *testing.T) {
 									d := v1.CompositeResourceDefinition{}
 									d.SetUID(owner)
 									d.SetDeletionTimestamp(&now)
+									d.Spec.DeletionPolicy = &cascade
 									*v = d
 								case *extv1.CustomResourceDefinition:
 									crd := extv1.CustomResourceDefinition{}
@@ -334,6 +344,7 @@ func TestReconcile(t *testing.T) {
 									d := v1.CompositeResourceDefinition{}
 									d.SetUID(owner)
 									d.SetDeletionTimestamp(&now)
+									d.Spec.DeletionPolicy = &cascade
 									*v = d
 								case *extv1.CustomResourceDefinition:
 									crd := extv1.CustomResourceDefinition{}
@@ -363,6 +374,127 @@ func TestReconcile(t *testing.T) {
 				r: reconcile.Result{Requeue: true},
 			},
 		},
+		"DeletionBlocked": {
+			reason: "We should requeue without deleting our defined resources if our deletion policy is Block.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								switch v := o.(type) {
+								case *v1.CompositeResourceDefinition:
+									d := v1.CompositeResourceDefinition{}
+									d.SetUID(owner)
+									d.SetDeletionTimestamp(&now)
+									*v = d
+								case *extv1.CustomResourceDefinition:
+									crd := extv1.CustomResourceDefinition{}
+									crd.SetCreationTimestamp(now)
+									crd.SetOwnerReferences([]metav1.OwnerReference{{UID: owner, Controller: &ctrlr}})
+									*v = crd
+								}
+								return nil
+							}),
+							MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+								v := o.(*unstructured.UnstructuredList)
+								*v = unstructured.UnstructuredList{
+									Items: []unstructured.Unstructured{{}, {}},
+								}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+					}),
+					WithCRDRenderer(CRDRenderFn(func(_ *v1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+						return &extv1.CustomResourceDefinition{}, nil
+					})),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: true},
+			},
+		},
+		"OrphanCustomResourceDefinitionError": {
+			reason: "We should return any error we encounter while orphaning the CRD we created.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								switch v := o.(type) {
+								case *v1.CompositeResourceDefinition:
+									d := v1.CompositeResourceDefinition{}
+									d.SetUID(owner)
+									d.SetDeletionTimestamp(&now)
+									d.Spec.DeletionPolicy = &orphan
+									*v = d
+								case *extv1.CustomResourceDefinition:
+									crd := extv1.CustomResourceDefinition{}
+									crd.SetCreationTimestamp(now)
+									crd.SetOwnerReferences([]metav1.OwnerReference{{UID: owner, Controller: &ctrlr}})
+									*v = crd
+								}
+								return nil
+							}),
+							MockUpdate:       test.NewMockUpdateFn(errBoom),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+					}),
+					WithCRDRenderer(CRDRenderFn(func(_ *v1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+						return &extv1.CustomResourceDefinition{}, nil
+					})),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errOrphanCRD),
+			},
+		},
+		"OrphanCustomResourceDefinition": {
+			reason: "We should remove our controller reference from the CRD we created, and requeue to remove our finalizer, if our deletion policy is Orphan.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								switch v := o.(type) {
+								case *v1.CompositeResourceDefinition:
+									d := v1.CompositeResourceDefinition{}
+									d.SetUID(owner)
+									d.SetDeletionTimestamp(&now)
+									d.Spec.DeletionPolicy = &orphan
+									*v = d
+								case *extv1.CustomResourceDefinition:
+									crd := extv1.CustomResourceDefinition{}
+									crd.SetCreationTimestamp(now)
+									crd.SetOwnerReferences([]metav1.OwnerReference{{UID: owner, Controller: &ctrlr}})
+									*v = crd
+								}
+								return nil
+							}),
+							MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+								want := &extv1.CustomResourceDefinition{}
+								want.SetCreationTimestamp(now)
+								want.SetOwnerReferences([]metav1.OwnerReference{})
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("MockUpdate: -want, +got:\n%s\n", diff)
+								}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+					}),
+					WithCRDRenderer(CRDRenderFn(func(_ *v1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+						return &extv1.CustomResourceDefinition{}, nil
+					})),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: true},
+			},
+		},
 		"DeleteCustomResourceDefinitionError": {
 			reason: "We should return any error we encounter while deleting the CRD we created.",
 			args: args{
@@ -547,7 +679,7 @@ func TestReconcile(t *testing.T) {
 					}}),
 					WithControllerEngine(&MockEngine{
 						MockErr:   func(_ string) error { return nil },
-						MockStart: func(_ string, _ kcontroller.Options, _ ...controller.Watch) error { return errBoom },
+						MockStart: func(_ string, _ kcontroller.Options, _ ...engine.Watch) error { return errBoom },
 					}),
 				},
 			},
@@ -591,8 +723,119 @@ func TestReconcile(t *testing.T) {
 					}}),
 					WithControllerEngine(&MockEngine{
 						MockErr:   func(name string) error { return errBoom }, // This error should only be logged.
-						MockStart: func(_ string, _ kcontroller.Options, _ ...controller.Watch) error { return nil }},
+						MockStart: func(_ string, _ kcontroller.Options, _ ...engine.Watch) error { return nil }},
 					),
+					WithClaimCRDDeprecator(ClaimCRDDeprecateFn(func(_ context.Context, _ *v1.CompositeResourceDefinition, _ string) (Deprecation, error) {
+						return Deprecation{}, nil
+					})),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
+		"StoppedOfferingClaim": {
+			reason: "We should stop our controller, remove our finalizer, and forget our claim type if our XRD stopped offering a claim.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								if d, ok := o.(*v1.CompositeResourceDefinition); ok {
+									d.Status.Controllers.CompositeResourceClaimTypeRef = v1.TypeReference{APIVersion: "example.org/v1", Kind: "Claim"}
+								}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.CompositeResourceDefinition{}
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{RemoveFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithControllerEngine(&MockEngine{
+						MockStop: func(_ string) {},
+					}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{},
+			},
+		},
+		"StoppedOfferingClaimRemoveClaimWebhookError": {
+			reason: "We should return any error encountered removing our claim webhook configuration if our XRD stopped offering a claim.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: func(_ context.Context, _ client.ObjectKey, o client.Object) error {
+								if d, ok := o.(*v1.CompositeResourceDefinition); ok {
+									d.Status.Controllers.CompositeResourceClaimTypeRef = v1.TypeReference{APIVersion: "example.org/v1", Kind: "Claim"}
+									return nil
+								}
+								return errBoom
+							},
+						},
+					}),
+					WithControllerEngine(&MockEngine{
+						MockStop: func(_ string) {},
+					}),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errors.Wrap(errBoom, errGetClaimWebhookConfig), errRemoveClaimWebhook),
+			},
+		},
+		"SuccessfulPause": {
+			reason: "We should stop our controller and not requeue if our XRD is paused.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								if d, ok := o.(*v1.CompositeResourceDefinition); ok {
+									d.SetAnnotations(map[string]string{v1.AnnotationKeyPaused: "true"})
+								}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.CompositeResourceDefinition{}
+								want.SetAnnotations(map[string]string{v1.AnnotationKeyPaused: "true"})
+								want.Status.SetConditions(v1.PausedClaim())
+
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+						Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+							return nil
+						}),
+					}),
+					WithCRDRenderer(CRDRenderFn(func(_ *v1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+						return &extv1.CustomResourceDefinition{
+							Status: extv1.CustomResourceDefinitionStatus{
+								Conditions: []extv1.CustomResourceDefinitionCondition{
+									{Type: extv1.Established, Status: extv1.ConditionTrue},
+								},
+							},
+						}, nil
+					})),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithControllerEngine(&MockEngine{
+						MockStop: func(_ string) {},
+					}),
 				},
 			},
 			want: want{
@@ -607,7 +850,10 @@ func TestReconcile(t *testing.T) {
 					WithClientApplicator(resource.ClientApplicator{
 						Client: &test.MockClient{
 							MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
-								d := obj.(*v1.CompositeResourceDefinition)
+								d, ok := obj.(*v1.CompositeResourceDefinition)
+								if !ok {
+									return nil
+								}
 								d.Spec.ClaimNames = &extv1.CustomResourceDefinitionNames{}
 								d.Spec.Versions = []v1.CompositeResourceDefinitionVersion{
 									{Name: "old", Referenceable: false},
@@ -650,22 +896,121 @@ func TestReconcile(t *testing.T) {
 					}}),
 					WithControllerEngine(&MockEngine{
 						MockErr:   func(name string) error { return nil },
-						MockStart: func(_ string, _ kcontroller.Options, _ ...controller.Watch) error { return nil },
+						MockStart: func(_ string, _ kcontroller.Options, _ ...engine.Watch) error { return nil },
 						MockStop:  func(_ string) {},
 					}),
+					WithClaimCRDDeprecator(ClaimCRDDeprecateFn(func(_ context.Context, _ *v1.CompositeResourceDefinition, _ string) (Deprecation, error) {
+						return Deprecation{}, nil
+					})),
 				},
 			},
 			want: want{
 				r: reconcile.Result{Requeue: false},
 			},
 		},
+		"DeprecateClaimCRDError": {
+			reason: "We should return any error we encounter while deprecating superseded claim CRDs.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil),
+						},
+						Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+							return nil
+						}),
+					}),
+					WithCRDRenderer(CRDRenderFn(func(_ *v1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+						return &extv1.CustomResourceDefinition{
+							Status: extv1.CustomResourceDefinitionStatus{
+								Conditions: []extv1.CustomResourceDefinitionCondition{
+									{Type: extv1.Established, Status: extv1.ConditionTrue},
+								},
+							},
+						}, nil
+					})),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithControllerEngine(&MockEngine{
+						MockErr:   func(_ string) error { return nil },
+						MockStart: func(_ string, _ kcontroller.Options, _ ...engine.Watch) error { return nil },
+					}),
+					WithClaimCRDDeprecator(ClaimCRDDeprecateFn(func(_ context.Context, _ *v1.CompositeResourceDefinition, _ string) (Deprecation, error) {
+						return Deprecation{}, errBoom
+					})),
+				},
+			},
+			want: want{
+				err: errBoom,
+			},
+		},
+		"SuccessfulStartWithSupersededClaimCRD": {
+			reason: "We should start a controller for each superseded claim CRD, and requeue after the deprecation window if they are still pending deletion.",
+			args: args{
+				mgr: &fake.Manager{},
+				opts: []ReconcilerOption{
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet:          test.NewMockGetFn(nil),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+						Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+							return nil
+						}),
+					}),
+					WithCRDRenderer(CRDRenderFn(func(_ *v1.CompositeResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+						return &extv1.CustomResourceDefinition{
+							Status: extv1.CustomResourceDefinitionStatus{
+								Conditions: []extv1.CustomResourceDefinitionCondition{
+									{Type: extv1.Established, Status: extv1.ConditionTrue},
+								},
+							},
+						}, nil
+					})),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithControllerEngine(&MockEngine{
+						MockErr: func(_ string) error { return nil },
+						MockStart: func(name string, _ kcontroller.Options, _ ...engine.Watch) error {
+							started = append(started, name)
+							return nil
+						},
+					}),
+					WithClaimCRDDeprecator(ClaimCRDDeprecateFn(func(_ context.Context, _ *v1.CompositeResourceDefinition, _ string) (Deprecation, error) {
+						old := &extv1.CustomResourceDefinition{
+							ObjectMeta: metav1.ObjectMeta{Name: "oldclaims.example.org"},
+							Spec: extv1.CustomResourceDefinitionSpec{
+								Group:    "example.org",
+								Names:    extv1.CustomResourceDefinitionNames{Kind: "OldClaim"},
+								Versions: []extv1.CustomResourceDefinitionVersion{{Name: "v1", Storage: true}},
+							},
+						}
+						return Deprecation{Deprecated: []*extv1.CustomResourceDefinition{old}, RequeueAfter: DefaultDeprecationWindow}, nil
+					})),
+				},
+			},
+			want: want{
+				r:       reconcile.Result{RequeueAfter: DefaultDeprecationWindow},
+				started: []string{claim.ControllerName(""), deprecatedControllerName("", "oldclaims.example.org")},
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
+			started = nil
 			r := NewReconciler(tc.args.mgr, tc.args.opts...)
 			got, err := r.Reconcile(context.Background(), reconcile.Request{})
 
+			if tc.want.started != nil {
+				if diff := cmp.Diff(tc.want.started, started); diff != "" {
+					t.Errorf("\n%s\nr.Reconcile(...): -want started controllers, +got started controllers:\n%s", tc.reason, diff)
+				}
+			}
+
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s", tc.reason, diff)
 			}
@@ -30,14 +30,14 @@ import (
 )
 
 // OffersClaim accepts objects that are a CompositeResourceDefinition and offer
-// a composite resource claim.
+// a composite resource claim, or did when they were last reconciled.
 func OffersClaim() resource.PredicateFn {
 	return func(obj runtime.Object) bool {
 		d, ok := obj.(*v1.CompositeResourceDefinition)
 		if !ok {
 			return false
 		}
-		return d.OffersClaim()
+		return d.OffersClaim() || d.Status.Controllers.CompositeResourceClaimTypeRef.APIVersion != ""
 	}
 }
 
@@ -56,6 +56,18 @@ func TestOffersClaim(t *testing.T) {
 			},
 			want: true,
 		},
+		"OfferedClaim": {
+			obj: &v1.CompositeResourceDefinition{
+				Status: v1.CompositeResourceDefinitionStatus{
+					// An XRD that references a claim type offered a claim
+					// when it was last reconciled.
+					Controllers: v1.CompositeResourceDefinitionControllerStatus{
+						CompositeResourceClaimTypeRef: v1.TypeReference{APIVersion: "example.org/v1", Kind: "Claim"},
+					},
+				},
+			},
+			want: true,
+		},
 	}
 
 	for name, tc := range cases {
@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offered
+
+import (
+	"context"
+
+	admv1 "k8s.io/api/admissionregistration/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/internal/controller/apiextensions/claim"
+)
+
+const (
+	// The ValidatingWebhookConfiguration installed by Crossplane's
+	// initializer, and the webhook within it that validates XRDs. We copy
+	// its client configuration, which points to Crossplane's webhook server.
+	webhookConfigurationName = "crossplane"
+	webhookNameXRD           = "compositeresourcedefinitions.apiextensions.crossplane.io"
+
+	webhookConfigurationPrefix = "crossplane-claim-"
+)
+
+// Error strings.
+const (
+	errGetWebhookConfig   = "cannot get Crossplane ValidatingWebhookConfiguration"
+	errApplyWebhookConfig = "cannot apply composite resource claim ValidatingWebhookConfiguration"
+
+	errGetClaimWebhookConfig    = "cannot get composite resource claim ValidatingWebhookConfiguration"
+	errDeleteClaimWebhookConfig = "cannot delete composite resource claim ValidatingWebhookConfiguration"
+)
+
+// RenderClaimWebhookConfiguration renders a ValidatingWebhookConfiguration that
+// asks the supplied webhook client to validate each new claim offered by the
+// supplied XRD, and to warn about each updated claim. Claims may be updated if
+// the webhook client is unavailable, because it never denies an update.
+func RenderClaimWebhookConfiguration(d *v1.CompositeResourceDefinition, cc admv1.WebhookClientConfig) *admv1.ValidatingWebhookConfiguration {
+	path := claim.WebhookPath
+	if cc.Service != nil {
+		svc := *cc.Service
+		svc.Path = &path
+		cc.Service = &svc
+	}
+
+	fail := admv1.Fail
+	ignore := admv1.Ignore
+	none := admv1.SideEffectClassNone
+	scope := admv1.NamespacedScope
+
+	rule := admv1.Rule{
+		APIGroups:   []string{d.Spec.Group},
+		APIVersions: []string{"*"},
+		Resources:   []string{d.Spec.ClaimNames.Plural},
+		Scope:       &scope,
+	}
+
+	wc := &admv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: webhookConfigurationPrefix + d.GetName()},
+		Webhooks: []admv1.ValidatingWebhook{
+			{
+				Name:                    d.Spec.ClaimNames.Plural + "." + d.Spec.Group,
+				ClientConfig:            cc,
+				Rules:                   []admv1.RuleWithOperations{{Operations: []admv1.OperationType{admv1.Create}, Rule: rule}},
+				FailurePolicy:           &fail,
+				SideEffects:             &none,
+				AdmissionReviewVersions: []string{"v1"},
+			},
+			{
+				Name:                    "updates." + d.Spec.ClaimNames.Plural + "." + d.Spec.Group,
+				ClientConfig:            cc,
+				Rules:                   []admv1.RuleWithOperations{{Operations: []admv1.OperationType{admv1.Update}, Rule: rule}},
+				FailurePolicy:           &ignore,
+				SideEffects:             &none,
+				AdmissionReviewVersions: []string{"v1"},
+			},
+		},
+	}
+
+	meta.AddOwnerReference(wc, meta.AsController(meta.TypedReferenceTo(d, v1.CompositeResourceDefinitionGroupVersionKind)))
+	return wc
+}
+
+// offerClaimWebhook ensures claims offered by the supplied XRD are validated by
+// Crossplane's webhook server, which enforces claim namespace selectors and
+// CompositeResourceQuotas. Any claim webhook configuration of the supplied XRD
+// is removed if Crossplane's webhooks are not enabled.
+func (r *Reconciler) offerClaimWebhook(ctx context.Context, d *v1.CompositeResourceDefinition) error {
+	xp := &admv1.ValidatingWebhookConfiguration{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: webhookConfigurationName}, xp)
+	if kerrors.IsNotFound(err) {
+		return r.removeClaimWebhook(ctx, d)
+	}
+	if err != nil {
+		return errors.Wrap(err, errGetWebhookConfig)
+	}
+
+	for _, w := range xp.Webhooks {
+		if w.Name != webhookNameXRD {
+			continue
+		}
+		wc := RenderClaimWebhookConfiguration(d, w.ClientConfig)
+		return errors.Wrap(r.client.Apply(ctx, wc, resource.MustBeControllableBy(d.GetUID())), errApplyWebhookConfig)
+	}
+
+	return r.removeClaimWebhook(ctx, d)
+}
+
+// removeClaimWebhook removes the claim webhook configuration of the supplied
+// XRD, if it exists and is controlled by the XRD. Claims of a kind that isn't
+// validated by a webhook configuration can't be rejected when Crossplane's
+// webhook server isn't running.
+func (r *Reconciler) removeClaimWebhook(ctx context.Context, d *v1.CompositeResourceDefinition) error {
+	wc := &admv1.ValidatingWebhookConfiguration{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: webhookConfigurationPrefix + d.GetName()}, wc)
+	if kerrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errGetClaimWebhookConfig)
+	}
+	if !metav1.IsControlledBy(wc, d) {
+		return nil
+	}
+	return errors.Wrap(resource.IgnoreNotFound(r.client.Delete(ctx, wc)), errDeleteClaimWebhookConfig)
+}
@@ -0,0 +1,283 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package offered
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	admv1 "k8s.io/api/admissionregistration/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	"github.com/crossplane/crossplane/internal/controller/apiextensions/claim"
+)
+
+func TestRenderClaimWebhookConfiguration(t *testing.T) {
+	ctrlr := true
+	path := claim.WebhookPath
+	otherPath := "/validate-something-else"
+	fail := admv1.Fail
+	ignore := admv1.Ignore
+	none := admv1.SideEffectClassNone
+	scope := admv1.NamespacedScope
+
+	d := &v1.CompositeResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "xdatabases.example.org", UID: types.UID("definitely-a-uuid")},
+		Spec: v1.CompositeResourceDefinitionSpec{
+			Group:      "example.org",
+			ClaimNames: &extv1.CustomResourceDefinitionNames{Kind: "Database", Plural: "databases"},
+		},
+	}
+
+	cc := admv1.WebhookClientConfig{
+		Service:  &admv1.ServiceReference{Name: "crossplane-webhooks", Namespace: "crossplane-system", Path: &otherPath},
+		CABundle: []byte("ca"),
+	}
+
+	want := &admv1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "crossplane-claim-xdatabases.example.org",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: v1.CompositeResourceDefinitionGroupVersionKind.GroupVersion().String(),
+				Kind:       v1.CompositeResourceDefinitionKind,
+				Name:       d.GetName(),
+				UID:        d.GetUID(),
+				Controller: &ctrlr,
+			}},
+		},
+		Webhooks: []admv1.ValidatingWebhook{
+			{
+				Name: "databases.example.org",
+				ClientConfig: admv1.WebhookClientConfig{
+					Service:  &admv1.ServiceReference{Name: "crossplane-webhooks", Namespace: "crossplane-system", Path: &path},
+					CABundle: []byte("ca"),
+				},
+				Rules: []admv1.RuleWithOperations{{
+					Operations: []admv1.OperationType{admv1.Create},
+					Rule: admv1.Rule{
+						APIGroups:   []string{"example.org"},
+						APIVersions: []string{"*"},
+						Resources:   []string{"databases"},
+						Scope:       &scope,
+					},
+				}},
+				FailurePolicy:           &fail,
+				SideEffects:             &none,
+				AdmissionReviewVersions: []string{"v1"},
+			},
+			{
+				Name: "updates.databases.example.org",
+				ClientConfig: admv1.WebhookClientConfig{
+					Service:  &admv1.ServiceReference{Name: "crossplane-webhooks", Namespace: "crossplane-system", Path: &path},
+					CABundle: []byte("ca"),
+				},
+				Rules: []admv1.RuleWithOperations{{
+					Operations: []admv1.OperationType{admv1.Update},
+					Rule: admv1.Rule{
+						APIGroups:   []string{"example.org"},
+						APIVersions: []string{"*"},
+						Resources:   []string{"databases"},
+						Scope:       &scope,
+					},
+				}},
+				FailurePolicy:           &ignore,
+				SideEffects:             &none,
+				AdmissionReviewVersions: []string{"v1"},
+			},
+		},
+	}
+
+	got := RenderClaimWebhookConfiguration(d, cc)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("RenderClaimWebhookConfiguration(...): -want, +got:\n%s", diff)
+	}
+
+	// The supplied client config should not be mutated.
+	if *cc.Service.Path != otherPath {
+		t.Errorf("RenderClaimWebhookConfiguration(...): mutated supplied client config path to %q", *cc.Service.Path)
+	}
+}
+
+func TestOfferClaimWebhook(t *testing.T) {
+	errBoom := errors.New("boom")
+	ctrlr := true
+
+	offering := &v1.CompositeResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "xdatabases.example.org", UID: types.UID("definitely-a-uuid")},
+		Spec: v1.CompositeResourceDefinitionSpec{
+			ClaimNames: &extv1.CustomResourceDefinitionNames{},
+		},
+	}
+
+	// getWebhookConfigs returns a MockGetFn that gets the supplied Crossplane
+	// webhook configuration, and a claim webhook configuration controlled
+	// by the above XRD. Either may be nil, in which case it is not found.
+	getWebhookConfigs := func(xp, cl *admv1.ValidatingWebhookConfiguration) test.MockGetFn {
+		return func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			wc := cl
+			if key.Name == webhookConfigurationName {
+				wc = xp
+			}
+			if wc == nil {
+				return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+			}
+			wc.DeepCopyInto(obj.(*admv1.ValidatingWebhookConfiguration))
+			return nil
+		}
+	}
+	crossplane := &admv1.ValidatingWebhookConfiguration{Webhooks: []admv1.ValidatingWebhook{{Name: "something-else"}}}
+	controlled := &admv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{
+		Name:            webhookConfigurationPrefix + offering.GetName(),
+		OwnerReferences: []metav1.OwnerReference{{UID: offering.GetUID(), Controller: &ctrlr}},
+	}}
+	uncontrolled := &admv1.ValidatingWebhookConfiguration{ObjectMeta: metav1.ObjectMeta{
+		Name: webhookConfigurationPrefix + offering.GetName(),
+	}}
+
+	cases := map[string]struct {
+		reason string
+		d      *v1.CompositeResourceDefinition
+		client resource.ClientApplicator
+		want   error
+	}{
+		"WebhooksDisabled": {
+			reason: "We should do nothing if Crossplane's webhook configuration does not exist.",
+			d:      offering,
+			client: resource.ClientApplicator{
+				Client: &test.MockClient{MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, ""))},
+			},
+			want: nil,
+		},
+		"WebhooksDisabledRemoveClaimWebhook": {
+			reason: "We should remove the claim webhook configuration we control if Crossplane's webhook configuration does not exist.",
+			d:      offering,
+			client: resource.ClientApplicator{
+				Client: &test.MockClient{
+					MockGet: getWebhookConfigs(nil, controlled),
+					MockDelete: test.NewMockDeleteFn(nil, func(obj client.Object) error {
+						if diff := cmp.Diff(controlled.GetName(), obj.GetName()); diff != "" {
+							t.Errorf("Delete(...): -want, +got:\n%s", diff)
+						}
+						return nil
+					}),
+				},
+			},
+			want: nil,
+		},
+		"NoXRDWebhookRemoveClaimWebhook": {
+			reason: "We should remove the claim webhook configuration we control if Crossplane doesn't validate XRDs.",
+			d:      offering,
+			client: resource.ClientApplicator{
+				Client: &test.MockClient{
+					MockGet:    getWebhookConfigs(crossplane, controlled),
+					MockDelete: test.NewMockDeleteFn(nil),
+				},
+			},
+			want: nil,
+		},
+		"WebhooksDisabledUncontrolledClaimWebhook": {
+			reason: "We should not remove a claim webhook configuration we don't control.",
+			d:      offering,
+			client: resource.ClientApplicator{
+				Client: &test.MockClient{MockGet: getWebhookConfigs(nil, uncontrolled)},
+			},
+			want: nil,
+		},
+		"GetClaimWebhookConfigError": {
+			reason: "We should return any error encountered getting the claim webhook configuration.",
+			d:      offering,
+			client: resource.ClientApplicator{
+				Client: &test.MockClient{MockGet: func(_ context.Context, key client.ObjectKey, _ client.Object) error {
+					if key.Name == webhookConfigurationName {
+						return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+					}
+					return errBoom
+				}},
+			},
+			want: errors.Wrap(errBoom, errGetClaimWebhookConfig),
+		},
+		"DeleteClaimWebhookConfigError": {
+			reason: "We should return any error encountered deleting the claim webhook configuration.",
+			d:      offering,
+			client: resource.ClientApplicator{
+				Client: &test.MockClient{
+					MockGet:    getWebhookConfigs(nil, controlled),
+					MockDelete: test.NewMockDeleteFn(errBoom),
+				},
+			},
+			want: errors.Wrap(errBoom, errDeleteClaimWebhookConfig),
+		},
+		"GetWebhookConfigError": {
+			reason: "We should return any error encountered getting Crossplane's webhook configuration.",
+			d:      offering,
+			client: resource.ClientApplicator{
+				Client: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			},
+			want: errors.Wrap(errBoom, errGetWebhookConfig),
+		},
+		"ApplyWebhookConfigError": {
+			reason: "We should return any error encountered applying the claim webhook configuration.",
+			d:      offering,
+			client: resource.ClientApplicator{
+				Client: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					obj.(*admv1.ValidatingWebhookConfiguration).Webhooks = []admv1.ValidatingWebhook{{Name: webhookNameXRD}}
+					return nil
+				})},
+				Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+					return errBoom
+				}),
+			},
+			want: errors.Wrap(errBoom, errApplyWebhookConfig),
+		},
+		"Success": {
+			reason: "We should apply a claim webhook configuration if Crossplane's webhooks are enabled.",
+			d:      offering,
+			client: resource.ClientApplicator{
+				Client: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					obj.(*admv1.ValidatingWebhookConfiguration).Webhooks = []admv1.ValidatingWebhook{{Name: webhookNameXRD}}
+					return nil
+				})},
+				Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+					return nil
+				}),
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewReconciler(&fake.Manager{}, WithClientApplicator(tc.client))
+			err := r.offerClaimWebhook(context.Background(), tc.d)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.offerClaimWebhook(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
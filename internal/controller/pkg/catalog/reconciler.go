@@ -0,0 +1,328 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package catalog implements the controller that refreshes the package
+// catalogs.
+package catalog
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+	"github.com/crossplane/crossplane/internal/controller/pkg/revision"
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+const (
+	reconcileTimeout = 3 * time.Minute
+
+	// defaultRefreshInterval is how often a catalog is refreshed if it
+	// doesn't specify an interval.
+	defaultRefreshInterval = 1 * time.Hour
+)
+
+const (
+	errGetCatalog      = "cannot get package catalog"
+	errUpdateStatus    = "cannot update package catalog status"
+	errBadRepository   = "repository is not a valid reference"
+	errFetchTags       = "cannot fetch repository tags"
+	errNoVersions      = "repository has no semantic version tags"
+	errFetchPackage    = "cannot fetch package"
+	errParsePackage    = "cannot parse package"
+	errNotOneMeta      = "package does not have exactly one meta type"
+	errNotPackage      = "package meta type is not a Provider or Configuration"
+	errFmtRefreshRepos = "cannot fully list some repositories: %s"
+)
+
+// ReconcilerOption is used to configure the Reconciler.
+type ReconcilerOption func(*Reconciler)
+
+// WithLogger specifies how the Reconciler should log messages.
+func WithLogger(log logging.Logger) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.log = log
+	}
+}
+
+// WithFetcher specifies how the Reconciler should fetch repository tags.
+func WithFetcher(f xpkg.Fetcher) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.fetcher = f
+	}
+}
+
+// WithParser specifies how the Reconciler should parse packages.
+func WithParser(p parser.Parser) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.parser = p
+	}
+}
+
+// WithParserBackend specifies how the Reconciler should fetch the contents of
+// packages.
+func WithParserBackend(b parser.Backend) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.backend = b
+	}
+}
+
+// WithDefaultRegistry specifies the registry from which repositories that
+// don't specify a registry are read.
+func WithDefaultRegistry(registry string) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.registry = registry
+	}
+}
+
+// Reconciler reconciles package catalogs.
+type Reconciler struct {
+	client   client.Client
+	log      logging.Logger
+	fetcher  xpkg.Fetcher
+	parser   parser.Parser
+	backend  parser.Backend
+	registry string
+}
+
+// Setup adds a controller that reconciles PackageCatalogs.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := "packages/" + strings.ToLower(v1alpha1.PackageCatalogGroupKind)
+
+	cs, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize clientset")
+	}
+	f, err := xpkg.NewK8sFetcher(cs, o.Namespace, o.FetcherOptions...)
+	if err != nil {
+		return errors.Wrap(err, "cannot build fetcher")
+	}
+	metaScheme, err := xpkg.BuildMetaScheme()
+	if err != nil {
+		return errors.New("cannot build meta scheme for package parser")
+	}
+	objScheme, err := xpkg.BuildObjectScheme()
+	if err != nil {
+		return errors.New("cannot build object scheme for package parser")
+	}
+
+	r := NewReconciler(mgr,
+		WithLogger(o.Logger.WithValues("controller", name)),
+		WithFetcher(f),
+		WithParser(xpkg.NewStreamParser(metaScheme, objScheme)),
+		WithParserBackend(revision.NewImageBackend(f, revision.WithDefaultRegistry(o.DefaultRegistry))),
+		WithDefaultRegistry(o.DefaultRegistry),
+	)
+
+	// We refresh catalogs periodically, so we needn't be told about updates
+	// to their status.
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.PackageCatalog{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(o.ForControllerRuntime()).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// NewReconciler creates a new package catalog reconciler.
+func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
+	r := &Reconciler{
+		client:  mgr.GetClient(),
+		log:     logging.NewNopLogger(),
+		fetcher: xpkg.NewNopFetcher(),
+		parser:  parser.New(nil, nil),
+		backend: parser.NewNopBackend(),
+	}
+
+	for _, f := range opts {
+		f(r)
+	}
+
+	return r
+}
+
+// Reconcile a package catalog by listing the packages in its repositories.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+	log.Debug("Reconciling")
+
+	ctx, cancel := context.WithTimeout(ctx, reconcileTimeout)
+	defer cancel()
+
+	c := &v1alpha1.PackageCatalog{}
+	if err := r.client.Get(ctx, req.NamespacedName, c); err != nil {
+		// There's no need to requeue if we no longer exist. Otherwise
+		// we'll be requeued implicitly because we return an error.
+		log.Debug(errGetCatalog, "error", err)
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetCatalog)
+	}
+
+	if meta.WasDeleted(c) {
+		return reconcile.Result{Requeue: false}, nil
+	}
+
+	interval := defaultRefreshInterval
+	if c.Spec.RefreshInterval != nil {
+		interval = c.Spec.RefreshInterval.Duration
+	}
+
+	// We're reconciled each time Crossplane starts, and each sync period. We
+	// don't refresh until we're due unless our repositories changed.
+	if last := c.Status.LastRefreshTime; last != nil && listed(c) {
+		if wait := time.Until(last.Add(interval)); wait > 0 {
+			return reconcile.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	pkgs := make([]v1alpha1.CatalogPackage, len(c.Spec.Repositories))
+	failed := []string{}
+	for i, repo := range c.Spec.Repositories {
+		pkgs[i] = r.list(ctx, repo, c.Spec.PackagePullSecrets)
+		if pkgs[i].Message != "" {
+			log.Debug("Cannot fully list repository", "repository", repo, "error", pkgs[i].Message)
+			failed = append(failed, repo)
+		}
+	}
+
+	now := metav1.Now()
+	c.Status.Packages = pkgs
+	c.Status.LastRefreshTime = &now
+	c.SetConditions(xpv1.ReconcileSuccess())
+	if len(failed) > 0 {
+		c.SetConditions(xpv1.ReconcileError(errors.Errorf(errFmtRefreshRepos, strings.Join(failed, ", "))))
+	}
+
+	return reconcile.Result{RequeueAfter: interval}, errors.Wrap(r.client.Status().Update(ctx, c), errUpdateStatus)
+}
+
+// list the supplied repository. The kind, name, and description of the
+// package are read from its newest release, or its newest pre-release if it
+// has no releases. Any error encountered is recorded in the package's message.
+func (r *Reconciler) list(ctx context.Context, repo string, secrets []corev1.LocalObjectReference) v1alpha1.CatalogPackage {
+	p := v1alpha1.CatalogPackage{Repository: repo}
+
+	ref, err := name.ParseReference(repo, name.WithDefaultRegistry(r.registry))
+	if err != nil {
+		p.Message = errors.Wrap(err, errBadRepository).Error()
+		return p
+	}
+
+	tags, err := r.fetcher.Tags(ctx, ref, v1.RefNames(secrets)...)
+	if err != nil {
+		p.Message = errors.Wrap(err, errFetchTags).Error()
+		return p
+	}
+
+	vs := make([]*semver.Version, 0, len(tags))
+	for _, t := range tags {
+		v, err := semver.NewVersion(t)
+		if err != nil {
+			continue
+		}
+		vs = append(vs, v)
+	}
+	if len(vs) == 0 {
+		p.Message = errNoVersions
+		return p
+	}
+	sort.Sort(sort.Reverse(semver.Collection(vs)))
+
+	p.Versions = make([]string, len(vs))
+	for i, v := range vs {
+		p.Versions[i] = v.Original()
+	}
+	newest := vs[0]
+	for _, v := range vs {
+		if v.Prerelease() == "" {
+			newest = v
+			break
+		}
+	}
+
+	// The parser backend fetches the package source of the supplied
+	// revision. Nothing is installed.
+	pr := &v1.ProviderRevision{Spec: v1.PackageRevisionSpec{
+		Package:            ref.Context().Tag(newest.Original()).String(),
+		PackagePullSecrets: secrets,
+	}}
+	rc, err := r.backend.Init(ctx, revision.PackageRevision(pr))
+	if err != nil {
+		p.Message = errors.Wrap(err, errFetchPackage).Error()
+		return p
+	}
+	pkg, err := r.parser.Parse(ctx, rc)
+	if err != nil {
+		p.Message = errors.Wrap(err, errParsePackage).Error()
+		return p
+	}
+	if len(pkg.GetMeta()) != 1 {
+		p.Message = errNotOneMeta
+		return p
+	}
+
+	pm, _ := xpkg.ConvertToHub(pkg.GetMeta()[0])
+	switch m := pm.(type) {
+	case *pkgmetav1.Provider:
+		p.Kind = pkgmetav1.ProviderKind
+		p.Name = m.GetName()
+		p.Description = m.GetAnnotations()[pkgmetav1.AnnotationDescription]
+	case *pkgmetav1.Configuration:
+		p.Kind = pkgmetav1.ConfigurationKind
+		p.Name = m.GetName()
+		p.Description = m.GetAnnotations()[pkgmetav1.AnnotationDescription]
+	default:
+		p.Message = errNotPackage
+	}
+	return p
+}
+
+// listed returns true if the supplied catalog lists exactly the repositories it
+// specifies.
+func listed(c *v1alpha1.PackageCatalog) bool {
+	if len(c.Status.Packages) != len(c.Spec.Repositories) {
+		return false
+	}
+	for i := range c.Spec.Repositories {
+		if c.Status.Packages[i].Repository != c.Spec.Repositories[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,253 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package catalog
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+	"github.com/crossplane/crossplane/internal/xpkg"
+	xpkgfake "github.com/crossplane/crossplane/internal/xpkg/fake"
+)
+
+var providerBytes = []byte(`apiVersion: meta.pkg.crossplane.io/v1
+kind: Provider
+metadata:
+  name: provider-test
+  annotations:
+    meta.crossplane.io/description: A test provider.
+spec:
+  controller:
+    image: crossplane/provider-test-controller:v0.2.0`)
+
+func TestReconcile(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := metav1.Now()
+	repo := "xpkg.example.org/crossplane/provider-test"
+
+	metaScheme, _ := xpkg.BuildMetaScheme()
+	objScheme, _ := xpkg.BuildObjectScheme()
+
+	// withCatalog returns a GetFn that gets a catalog of the test repository.
+	withCatalog := func(s v1alpha1.PackageCatalogStatus) test.MockGetFn {
+		return test.NewMockGetFn(nil, func(o client.Object) error {
+			c := o.(*v1alpha1.PackageCatalog)
+			c.Spec.Repositories = []string{repo}
+			c.Status = s
+			return nil
+		})
+	}
+
+	// wantStatus returns a StatusUpdateFn that checks the updated status.
+	wantStatus := func(s v1alpha1.PackageCatalogStatus) test.MockStatusUpdateFn {
+		return test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+			c := o.(*v1alpha1.PackageCatalog)
+			if c.Status.LastRefreshTime == nil {
+				t.Errorf("Status().Update(...): want last refresh time to be set")
+			}
+			if diff := cmp.Diff(s, c.Status, cmpopts.IgnoreTypes(&metav1.Time{}, metav1.Time{})); diff != "" {
+				t.Errorf("Status().Update(...): -want, +got:\n%s", diff)
+			}
+			return nil
+		})
+	}
+
+	type args struct {
+		mgr manager.Manager
+		req reconcile.Request
+		rec []ReconcilerOption
+	}
+	type want struct {
+		r   reconcile.Result
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"CatalogNotFound": {
+			reason: "We should not return an error if the catalog was not found.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+					},
+				},
+			},
+			want: want{
+				r: reconcile.Result{},
+			},
+		},
+		"ErrGetCatalog": {
+			reason: "We should return an error if getting the catalog fails.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(errBoom),
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errGetCatalog),
+			},
+		},
+		"RefreshNotDue": {
+			reason: "We should not refresh a catalog that was refreshed less than one interval ago.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: withCatalog(v1alpha1.PackageCatalogStatus{
+							Packages:        []v1alpha1.CatalogPackage{{Repository: repo}},
+							LastRefreshTime: &now,
+						}),
+						MockStatusUpdate: func(_ context.Context, _ client.Object, _ ...client.UpdateOption) error {
+							t.Errorf("Status().Update(...): catalog should not be refreshed")
+							return nil
+						},
+					},
+				},
+				rec: []ReconcilerOption{
+					WithFetcher(&xpkgfake.MockFetcher{
+						MockTags: func() ([]string, error) {
+							t.Errorf("Tags(...): catalog should not be refreshed")
+							return nil, nil
+						},
+					}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: defaultRefreshInterval},
+			},
+		},
+		"RefreshSuccessful": {
+			reason: "We should list the semantic versions of each repository newest first, and read the package's metadata from its newest release.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: withCatalog(v1alpha1.PackageCatalogStatus{}),
+						MockStatusUpdate: wantStatus(v1alpha1.PackageCatalogStatus{
+							ConditionedStatus: xpv1.ConditionedStatus{Conditions: []xpv1.Condition{xpv1.ReconcileSuccess()}},
+							Packages: []v1alpha1.CatalogPackage{{
+								Repository:  repo,
+								Kind:        "Provider",
+								Name:        "provider-test",
+								Description: "A test provider.",
+								Versions:    []string{"v0.3.0-rc.1", "v0.2.0", "v0.1.0"},
+							}},
+						}),
+					},
+				},
+				rec: []ReconcilerOption{
+					WithFetcher(&xpkgfake.MockFetcher{
+						MockTags: xpkgfake.NewMockTagsFn([]string{"v0.1.0", "latest", "v0.3.0-rc.1", "v0.2.0"}, nil),
+					}),
+					WithParser(parser.New(metaScheme, objScheme)),
+					WithParserBackend(parser.NewEchoBackend(string(providerBytes))),
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: defaultRefreshInterval},
+			},
+		},
+		"ErrFetchTags": {
+			reason: "We should record why a repository could not be listed and report that the catalog was not fully refreshed.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: withCatalog(v1alpha1.PackageCatalogStatus{}),
+						MockStatusUpdate: wantStatus(v1alpha1.PackageCatalogStatus{
+							ConditionedStatus: xpv1.ConditionedStatus{Conditions: []xpv1.Condition{xpv1.ReconcileError(errors.Errorf(errFmtRefreshRepos, repo))}},
+							Packages: []v1alpha1.CatalogPackage{{
+								Repository: repo,
+								Message:    errors.Wrap(errBoom, errFetchTags).Error(),
+							}},
+						}),
+					},
+				},
+				rec: []ReconcilerOption{
+					WithFetcher(&xpkgfake.MockFetcher{
+						MockTags: xpkgfake.NewMockTagsFn(nil, errBoom),
+					}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: defaultRefreshInterval},
+			},
+		},
+		"NoVersions": {
+			reason: "We should record that a repository has no semantic version tags.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: withCatalog(v1alpha1.PackageCatalogStatus{}),
+						MockStatusUpdate: wantStatus(v1alpha1.PackageCatalogStatus{
+							ConditionedStatus: xpv1.ConditionedStatus{Conditions: []xpv1.Condition{xpv1.ReconcileError(errors.Errorf(errFmtRefreshRepos, repo))}},
+							Packages: []v1alpha1.CatalogPackage{{
+								Repository: repo,
+								Message:    errNoVersions,
+							}},
+						}),
+					},
+				},
+				rec: []ReconcilerOption{
+					WithFetcher(&xpkgfake.MockFetcher{
+						MockTags: xpkgfake.NewMockTagsFn([]string{"latest", "main"}, nil),
+					}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: defaultRefreshInterval},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewReconciler(tc.args.mgr, tc.args.rec...)
+			got, err := r.Reconcile(context.Background(), tc.args.req)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			// A catalog that isn't due to be refreshed is requeued once it is,
+			// which is slightly less than one interval from now.
+			approx := cmp.Comparer(func(a, b time.Duration) bool { return a-b < time.Minute && b-a < time.Minute })
+			if diff := cmp.Diff(tc.want.r, got, approx); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
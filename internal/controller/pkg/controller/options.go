@@ -18,12 +18,52 @@ limitations under the License.
 package controller
 
 import (
+	"time"
+
 	"github.com/crossplane/crossplane/internal/xpkg"
 
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/feature"
 )
 
+// An ApplyConflictPolicy determines what the package manager does when applying
+// an object would change a field that was set by another field manager, for
+// example a user or an admission controller.
+type ApplyConflictPolicy string
+
+// Apply conflict policies.
+const (
+	// ApplyConflictPolicyForce takes ownership of conflicting fields,
+	// overwriting their values.
+	ApplyConflictPolicyForce ApplyConflictPolicy = "Force"
+
+	// ApplyConflictPolicyFail refuses to apply the object, and reports the
+	// conflict in the conditions of the package revision.
+	ApplyConflictPolicyFail ApplyConflictPolicy = "Fail"
+)
+
+// An UnhealthyDependencyPolicy determines what the package manager does when a
+// dependency of a package is installed, but its active revision is not
+// healthy.
+type UnhealthyDependencyPolicy string
+
+// Unhealthy dependency policies.
+const (
+	// UnhealthyDependencyPolicyIgnore considers an installed dependency
+	// resolved regardless of its health.
+	UnhealthyDependencyPolicyIgnore UnhealthyDependencyPolicy = "Ignore"
+
+	// UnhealthyDependencyPolicyReport reports unhealthy dependencies in the
+	// conditions of the package revision, but otherwise considers them
+	// installed.
+	UnhealthyDependencyPolicyReport UnhealthyDependencyPolicy = "Report"
+
+	// UnhealthyDependencyPolicyBlock considers unhealthy dependencies not
+	// installed, so the package revision isn't installed until they are
+	// healthy.
+	UnhealthyDependencyPolicyBlock UnhealthyDependencyPolicy = "Block"
+)
+
 // Options specific to pkg controllers.
 type Options struct {
 	controller.Options
@@ -31,6 +71,11 @@ type Options struct {
 	// Cache for package OCI images.
 	Cache xpkg.PackageCache
 
+	// ScopedCache caches the contents of each package's revisions in a
+	// directory of the package's own, rather than alongside those of every
+	// other package.
+	ScopedCache bool
+
 	// Namespace used to unpack and run packages.
 	Namespace string
 
@@ -46,6 +91,36 @@ type Options struct {
 	// injected to CRDs so that API server can make calls to the providers.
 	WebhookTLSSecretName string
 
+	// DisableRuntimeRepair stops provider revisions from watching their
+	// runtime resources for changes made out-of-band. Such changes are
+	// instead repaired the next time the revision is reconciled.
+	DisableRuntimeRepair bool
+
+	// ApplyConflictPolicy determines what happens when applying a package or
+	// runtime object would change a field set by another field manager.
+	ApplyConflictPolicy ApplyConflictPolicy
+
+	// UnhealthyDependencyPolicy determines what happens when a dependency of
+	// a package is installed but not healthy.
+	UnhealthyDependencyPolicy UnhealthyDependencyPolicy
+
+	// MaxConcurrentEstablishers is the maximum number of objects a package
+	// revision may create or take ownership of at the same time.
+	MaxConcurrentEstablishers int
+
+	// EventSuppressionWindow is how long identical events about the same
+	// object are suppressed for once one has been recorded.
+	EventSuppressionWindow time.Duration
+
+	// ActivationWebhookURL is called before a package revision is activated.
+	// The revision is activated only if the webhook allows it. An empty URL
+	// disables the webhook.
+	ActivationWebhookURL string
+
+	// DefaultLabels are added to every provider Deployment, unless its
+	// ControllerConfig sets them.
+	DefaultLabels map[string]string
+
 	// Features that should be enabled.
 	Features *feature.Flags
 }
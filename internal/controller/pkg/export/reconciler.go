@@ -0,0 +1,211 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package export implements the controller that exports the configuration of
+// the control plane.
+package export
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+	"github.com/crossplane/crossplane/internal/controlplane"
+)
+
+const (
+	reconcileTimeout = 1 * time.Minute
+)
+
+const (
+	errGetExport    = "cannot get control plane export"
+	errUpdateStatus = "cannot update control plane export status"
+	errCollect      = "cannot collect control plane configuration"
+	errMarshal      = "cannot marshal control plane configuration"
+	errApplyCM      = "cannot apply export ConfigMap"
+
+	errFmtTooLarge = "exported control plane is %d bytes, which exceeds the limit of %d bytes a ConfigMap can hold"
+)
+
+// ReconcilerOption is used to configure the Reconciler.
+type ReconcilerOption func(*Reconciler)
+
+// WithLogger specifies how the Reconciler should log messages.
+func WithLogger(log logging.Logger) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.log = log
+	}
+}
+
+// WithNamespace specifies the namespace in which the Reconciler creates the
+// ConfigMaps it exports to.
+func WithNamespace(ns string) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.namespace = ns
+	}
+}
+
+// Reconciler reconciles control plane exports.
+type Reconciler struct {
+	client    resource.ClientApplicator
+	log       logging.Logger
+	namespace string
+}
+
+// Setup adds a controller that reconciles ControlPlaneExports.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := "packages/" + strings.ToLower(v1alpha1.ControlPlaneExportGroupKind)
+
+	r := NewReconciler(mgr,
+		WithLogger(o.Logger.WithValues("controller", name)),
+		WithNamespace(o.Namespace),
+	)
+
+	// We export periodically, so we needn't be told about updates to our
+	// status.
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.ControlPlaneExport{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		WithOptions(o.ForControllerRuntime()).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// NewReconciler creates a new control plane export reconciler.
+func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
+	r := &Reconciler{
+		client: resource.ClientApplicator{
+			Client:     mgr.GetClient(),
+			Applicator: resource.NewAPIPatchingApplicator(mgr.GetClient()),
+		},
+		log: logging.NewNopLogger(),
+	}
+
+	for _, f := range opts {
+		f(r)
+	}
+
+	return r
+}
+
+// Reconcile a control plane export by exporting the configuration of the
+// control plane to a ConfigMap.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+	log.Debug("Reconciling")
+
+	ctx, cancel := context.WithTimeout(ctx, reconcileTimeout)
+	defer cancel()
+
+	e := &v1alpha1.ControlPlaneExport{}
+	if err := r.client.Get(ctx, req.NamespacedName, e); err != nil {
+		// There's no need to requeue if we no longer exist. Otherwise
+		// we'll be requeued implicitly because we return an error.
+		log.Debug(errGetExport, "error", err)
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetExport)
+	}
+
+	// The ConfigMap we exported to is garbage collected when we're deleted.
+	if meta.WasDeleted(e) {
+		return reconcile.Result{Requeue: false}, nil
+	}
+
+	// We're reconciled each time Crossplane starts, and each sync period. We
+	// don't export again until we're due unless our spec changed.
+	if last := e.Status.LastExportTime; last != nil && e.Status.ObservedGeneration == e.GetGeneration() {
+		if e.Spec.Interval == nil {
+			return reconcile.Result{}, nil
+		}
+		if wait := time.Until(last.Add(e.Spec.Interval.Duration)); wait > 0 {
+			return reconcile.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	objs, err := controlplane.Collect(ctx, r.client)
+	if err != nil {
+		log.Debug(errCollect, "error", err)
+		err = errors.Wrap(err, errCollect)
+		e.SetConditions(xpv1.ReconcileError(err))
+		_ = r.client.Status().Update(ctx, e)
+		return reconcile.Result{}, err
+	}
+	data, err := controlplane.Marshal(objs)
+	if err != nil {
+		log.Debug(errMarshal, "error", err)
+		err = errors.Wrap(err, errMarshal)
+		e.SetConditions(xpv1.ReconcileError(err))
+		_ = r.client.Status().Update(ctx, e)
+		return reconcile.Result{}, err
+	}
+
+	// The export won't get any smaller if we try again right away, so we
+	// don't return an error. We try again after the interval, or the next
+	// time we're reconciled, in case objects have since been deleted.
+	if len(data) > controlplane.MaxExportSize {
+		err := errors.Errorf(errFmtTooLarge, len(data), controlplane.MaxExportSize)
+		log.Debug("Cannot export control plane", "error", err)
+		e.SetConditions(xpv1.ReconcileError(err))
+		result := reconcile.Result{}
+		if e.Spec.Interval != nil {
+			result.RequeueAfter = e.Spec.Interval.Duration
+		}
+		return result, errors.Wrap(r.client.Status().Update(ctx, e), errUpdateStatus)
+	}
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       r.namespace,
+			Name:            e.Spec.ConfigMapName,
+			OwnerReferences: []metav1.OwnerReference{meta.AsController(meta.TypedReferenceTo(e, v1alpha1.ControlPlaneExportGroupVersionKind))},
+		},
+		Data: map[string]string{controlplane.KeyExport: string(data)},
+	}
+	if err := r.client.Apply(ctx, cm); err != nil {
+		log.Debug(errApplyCM, "error", err)
+		err = errors.Wrap(err, errApplyCM)
+		e.SetConditions(xpv1.ReconcileError(err))
+		_ = r.client.Status().Update(ctx, e)
+		return reconcile.Result{}, err
+	}
+
+	now := metav1.Now()
+	e.Status.ExportedObjects = len(objs)
+	e.Status.LastExportTime = &now
+	e.Status.ObservedGeneration = e.GetGeneration()
+	e.SetConditions(xpv1.ReconcileSuccess())
+
+	result := reconcile.Result{}
+	if e.Spec.Interval != nil {
+		result.RequeueAfter = e.Spec.Interval.Duration
+	}
+	return result, errors.Wrap(r.client.Status().Update(ctx, e), errUpdateStatus)
+}
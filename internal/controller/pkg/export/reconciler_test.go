@@ -0,0 +1,220 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+	"github.com/crossplane/crossplane/internal/controlplane"
+)
+
+func TestReconcile(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := metav1.Now()
+	interval := &metav1.Duration{Duration: time.Hour}
+
+	// withExport returns a GetFn that gets an export with the supplied
+	// interval and status. Any ConfigMap it gets doesn't exist.
+	withExport := func(i *metav1.Duration, s v1alpha1.ControlPlaneExportStatus) test.MockGetFn {
+		return func(_ context.Context, _ client.ObjectKey, o client.Object) error {
+			e, ok := o.(*v1alpha1.ControlPlaneExport)
+			if !ok {
+				return kerrors.NewNotFound(schema.GroupResource{}, "")
+			}
+			e.SetName("backup")
+			e.SetGeneration(1)
+			e.Spec.ConfigMapName = "backup"
+			e.Spec.Interval = i
+			e.Status = s
+			return nil
+		}
+	}
+
+	type args struct {
+		mgr manager.Manager
+	}
+	type want struct {
+		r   reconcile.Result
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ExportNotFound": {
+			reason: "We should not return an error if the export was not found.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+					},
+				},
+			},
+			want: want{
+				r: reconcile.Result{},
+			},
+		},
+		"ErrGetExport": {
+			reason: "We should return an error if getting the export fails.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(errBoom),
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errGetExport),
+			},
+		},
+		"ExportedOnce": {
+			reason: "We should not export again if we exported this generation and no interval is specified.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: withExport(nil, v1alpha1.ControlPlaneExportStatus{LastExportTime: &now, ObservedGeneration: 1}),
+						MockList: func(_ context.Context, _ client.ObjectList, _ ...client.ListOption) error {
+							t.Errorf("List(...): control plane should not be exported")
+							return nil
+						},
+					},
+				},
+			},
+			want: want{
+				r: reconcile.Result{},
+			},
+		},
+		"ErrCollect": {
+			reason: "We should return an error if we can't collect the control plane configuration.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet:          withExport(nil, v1alpha1.ControlPlaneExportStatus{}),
+						MockList:         test.NewMockListFn(errBoom),
+						MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errors.Wrapf(errBoom, "cannot list %s", v1alpha1.ControllerConfigKind), errCollect),
+			},
+		},
+		"ExportTooLarge": {
+			reason: "We should not export the control plane if it is too large to fit in a ConfigMap, but should try again after the interval.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: withExport(interval, v1alpha1.ControlPlaneExportStatus{}),
+						MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+							if l, ok := o.(*v1.ProviderList); ok {
+								p := v1.Provider{ObjectMeta: metav1.ObjectMeta{Name: "provider-test"}}
+								p.SetAnnotations(map[string]string{"large": strings.Repeat("a", controlplane.MaxExportSize)})
+								l.Items = []v1.Provider{p}
+							}
+							return nil
+						}),
+						MockCreate: func(_ context.Context, _ client.Object, _ ...client.CreateOption) error {
+							t.Errorf("Create(...): control plane should not be exported")
+							return nil
+						},
+						MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+							c := o.(*v1alpha1.ControlPlaneExport).GetCondition(xpv1.TypeSynced)
+							if c.Status != corev1.ConditionFalse || !strings.Contains(c.Message, "exceeds the limit") {
+								t.Errorf("Status().Update(...): want Synced condition reporting the export is too large, got %v", c)
+							}
+							return nil
+						}),
+					},
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: time.Hour},
+			},
+		},
+		"Exported": {
+			reason: "We should export the control plane to a ConfigMap, and export it again after the interval.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: withExport(interval, v1alpha1.ControlPlaneExportStatus{}),
+						MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+							if l, ok := o.(*v1.ProviderList); ok {
+								l.Items = []v1.Provider{{ObjectMeta: metav1.ObjectMeta{Name: "provider-test"}}}
+							}
+							return nil
+						}),
+						MockCreate: test.NewMockCreateFn(nil, func(o client.Object) error {
+							cm := o.(*corev1.ConfigMap)
+							want := "apiVersion: pkg.crossplane.io/v1\nkind: Provider\nmetadata:\n  name: provider-test\nspec:\n  package: \"\"\n"
+							if diff := cmp.Diff(want, cm.Data[controlplane.KeyExport]); diff != "" {
+								t.Errorf("Create(...): -want, +got:\n%s", diff)
+							}
+							return nil
+						}),
+						MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+							want := v1alpha1.ControlPlaneExportStatus{ExportedObjects: 1, ObservedGeneration: 1}
+							want.SetConditions(xpv1.ReconcileSuccess())
+							if diff := cmp.Diff(want, o.(*v1alpha1.ControlPlaneExport).Status, cmpopts.IgnoreTypes(&metav1.Time{}, metav1.Time{})); diff != "" {
+								t.Errorf("Status().Update(...): -want, +got:\n%s", diff)
+							}
+							return nil
+						}),
+					},
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: time.Hour},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewReconciler(tc.args.mgr)
+			got, err := r.Reconcile(context.Background(), reconcile.Request{})
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.r, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
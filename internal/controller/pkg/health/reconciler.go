@@ -0,0 +1,292 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package health implements the controller that summarizes the health of
+// Crossplane.
+package health
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	extv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+)
+
+const (
+	reconcileTimeout = 1 * time.Minute
+
+	// checkInterval is how often the health of Crossplane is checked, even if
+	// none of the objects it depends on change. Certificates expire without
+	// anything changing.
+	checkInterval = 1 * time.Minute
+
+	// certificateExpiryThreshold is how long before it expires the webhook
+	// certificate is considered unhealthy, so that it can be rotated in time.
+	certificateExpiryThreshold = 30 * 24 * time.Hour
+
+	keyTLSCert = "tls.crt"
+)
+
+const (
+	errGetClusterHealth = "cannot get cluster health"
+	errUpdateStatus     = "cannot update cluster health status"
+	errListRevisions    = "cannot list package revisions"
+	errListXRDs         = "cannot list CompositeResourceDefinitions"
+	errGetSecret        = "cannot get webhook TLS secret"
+
+	errNoCert      = "secret has no TLS certificate"
+	errParseCert   = "cannot parse TLS certificate"
+	errFmtExpiring = "TLS certificate expires at %s"
+	errFmtExpired  = "TLS certificate expired at %s"
+
+	errFmtUnhealthy = "Crossplane is unhealthy: %s"
+)
+
+// ReconcilerOption is used to configure the Reconciler.
+type ReconcilerOption func(*Reconciler)
+
+// WithLogger specifies how the Reconciler should log messages.
+func WithLogger(log logging.Logger) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.log = log
+	}
+}
+
+// WithWebhookTLSSecret specifies the Secret from which the Reconciler should
+// read the TLS certificate served by Crossplane's webhooks.
+func WithWebhookTLSSecret(nn types.NamespacedName) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.secret = nn
+	}
+}
+
+// Reconciler reconciles the health of Crossplane.
+type Reconciler struct {
+	client client.Client
+	log    logging.Logger
+	secret types.NamespacedName
+}
+
+// Setup adds a controller that reconciles the ClusterHealth.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := "packages/" + strings.ToLower(v1alpha1.ClusterHealthGroupKind)
+
+	opts := []ReconcilerOption{WithLogger(o.Logger.WithValues("controller", name))}
+	if o.WebhookTLSSecretName != "" {
+		opts = append(opts, WithWebhookTLSSecret(types.NamespacedName{Namespace: o.Namespace, Name: o.WebhookTLSSecretName}))
+	}
+	r := NewReconciler(mgr, opts...)
+
+	// We check our health whenever a package revision or XRD changes, and
+	// periodically. We needn't be told about updates to our own status.
+	h := handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: v1alpha1.ClusterHealthName}}}
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.ClusterHealth{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &v1.ProviderRevision{}}, h).
+		Watches(&source.Kind{Type: &v1.ConfigurationRevision{}}, h).
+		Watches(&source.Kind{Type: &extv1.CompositeResourceDefinition{}}, h).
+		WithOptions(o.ForControllerRuntime()).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// NewReconciler creates a new cluster health reconciler.
+func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
+	r := &Reconciler{
+		client: mgr.GetClient(),
+		log:    logging.NewNopLogger(),
+	}
+
+	for _, f := range opts {
+		f(r)
+	}
+
+	return r
+}
+
+// Reconcile the health of Crossplane by summarizing the health of its package
+// revisions, CompositeResourceDefinitions, and webhook certificate.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+	log.Debug("Reconciling")
+
+	ctx, cancel := context.WithTimeout(ctx, reconcileTimeout)
+	defer cancel()
+
+	h := &v1alpha1.ClusterHealth{}
+	if err := r.client.Get(ctx, req.NamespacedName, h); err != nil {
+		// There's no need to requeue if we no longer exist. Otherwise
+		// we'll be requeued implicitly because we return an error.
+		log.Debug(errGetClusterHealth, "error", err)
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetClusterHealth)
+	}
+
+	if meta.WasDeleted(h) {
+		return reconcile.Result{Requeue: false}, nil
+	}
+
+	if err := r.check(ctx, &h.Status); err != nil {
+		log.Debug("Cannot check health", "error", err)
+		h.SetConditions(xpv1.ReconcileError(err))
+		return reconcile.Result{RequeueAfter: checkInterval}, errors.Wrap(r.client.Status().Update(ctx, h), errUpdateStatus)
+	}
+
+	now := metav1.Now()
+	h.Status.LastCheckTime = &now
+	h.SetConditions(xpv1.ReconcileSuccess(), ready(h.Status))
+
+	return reconcile.Result{RequeueAfter: checkInterval}, errors.Wrap(r.client.Status().Update(ctx, h), errUpdateStatus)
+}
+
+// check the health of Crossplane, recording it in the supplied status.
+func (r *Reconciler) check(ctx context.Context, s *v1alpha1.ClusterHealthStatus) error {
+	s.UnhealthyPackageRevisions = nil
+	s.UnresolvedDependencies = nil
+	lists := []struct {
+		gvk schema.GroupVersionKind
+		l   v1.PackageRevisionList
+	}{
+		{gvk: v1.ProviderRevisionGroupVersionKind, l: &v1.ProviderRevisionList{}},
+		{gvk: v1.ConfigurationRevisionGroupVersionKind, l: &v1.ConfigurationRevisionList{}},
+	}
+	for _, rl := range lists {
+		gvk, l := rl.gvk, rl.l
+		if err := r.client.List(ctx, l); err != nil {
+			return errors.Wrap(err, errListRevisions)
+		}
+		for _, pr := range l.GetRevisions() {
+			// Inactive revisions are not expected to be healthy.
+			if pr.GetDesiredState() != v1.PackageRevisionActive {
+				continue
+			}
+			if c := pr.GetCondition(v1.TypeHealthy); c.Status == corev1.ConditionFalse {
+				s.UnhealthyPackageRevisions = append(s.UnhealthyPackageRevisions, unhealthy(gvk, pr.GetName(), c))
+			}
+			if c := pr.GetCondition(v1.TypeDependenciesResolved); c.Status == corev1.ConditionFalse {
+				s.UnresolvedDependencies = append(s.UnresolvedDependencies, unhealthy(gvk, pr.GetName(), c))
+			}
+		}
+	}
+
+	xrds := &extv1.CompositeResourceDefinitionList{}
+	if err := r.client.List(ctx, xrds); err != nil {
+		return errors.Wrap(err, errListXRDs)
+	}
+	s.FailingDefinitions = nil
+	for i := range xrds.Items {
+		d := &xrds.Items[i]
+		if c := d.Status.GetCondition(extv1.TypeEstablished); c.Status != corev1.ConditionTrue {
+			s.FailingDefinitions = append(s.FailingDefinitions, unhealthy(extv1.CompositeResourceDefinitionGroupVersionKind, d.GetName(), c))
+			continue
+		}
+		if c := d.Status.GetCondition(extv1.TypeOffered); d.OffersClaim() && c.Status != corev1.ConditionTrue {
+			s.FailingDefinitions = append(s.FailingDefinitions, unhealthy(extv1.CompositeResourceDefinitionGroupVersionKind, d.GetName(), c))
+		}
+	}
+
+	s.WebhookCertificate = nil
+	if r.secret.Name == "" {
+		return nil
+	}
+	sec := &corev1.Secret{}
+	if err := r.client.Get(ctx, r.secret, sec); err != nil {
+		return errors.Wrap(err, errGetSecret)
+	}
+	s.WebhookCertificate = certificate(sec, time.Now())
+	return nil
+}
+
+// certificate returns the status of the TLS certificate in the supplied
+// Secret at the supplied time.
+func certificate(s *corev1.Secret, now time.Time) *v1alpha1.WebhookCertificateStatus {
+	cs := &v1alpha1.WebhookCertificateStatus{SecretName: s.GetName()}
+	b, _ := pem.Decode(s.Data[keyTLSCert])
+	if b == nil {
+		cs.Message = errNoCert
+		return cs
+	}
+	crt, err := x509.ParseCertificate(b.Bytes)
+	if err != nil {
+		cs.Message = errors.Wrap(err, errParseCert).Error()
+		return cs
+	}
+	na := metav1.NewTime(crt.NotAfter)
+	cs.NotAfter = &na
+	switch {
+	case !now.Before(crt.NotAfter):
+		cs.Message = fmt.Sprintf(errFmtExpired, crt.NotAfter.UTC().Format(time.RFC3339))
+	case now.Add(certificateExpiryThreshold).After(crt.NotAfter):
+		cs.Message = fmt.Sprintf(errFmtExpiring, crt.NotAfter.UTC().Format(time.RFC3339))
+	}
+	return cs
+}
+
+// ready returns a Ready condition summarizing the supplied status.
+func ready(s v1alpha1.ClusterHealthStatus) xpv1.Condition {
+	problems := make([]string, 0)
+	if n := len(s.UnhealthyPackageRevisions); n > 0 {
+		problems = append(problems, fmt.Sprintf("%d unhealthy package revision(s)", n))
+	}
+	if n := len(s.UnresolvedDependencies); n > 0 {
+		problems = append(problems, fmt.Sprintf("%d package revision(s) with unresolved dependencies", n))
+	}
+	if n := len(s.FailingDefinitions); n > 0 {
+		problems = append(problems, fmt.Sprintf("%d failing CompositeResourceDefinition(s)", n))
+	}
+	if c := s.WebhookCertificate; c != nil && c.Message != "" {
+		problems = append(problems, "webhook certificate: "+c.Message)
+	}
+	if len(problems) == 0 {
+		return xpv1.Available()
+	}
+	return xpv1.Unavailable().WithMessage(fmt.Sprintf(errFmtUnhealthy, strings.Join(problems, ", ")))
+}
+
+// unhealthy returns an UnhealthyObject describing the supplied object, which
+// is unhealthy per the supplied condition.
+func unhealthy(gvk schema.GroupVersionKind, name string, c xpv1.Condition) v1alpha1.UnhealthyObject {
+	v, k := gvk.ToAPIVersionAndKind()
+	return v1alpha1.UnhealthyObject{APIVersion: v, Kind: k, Name: name, Reason: c.Reason, Message: c.Message}
+}
@@ -0,0 +1,356 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package health
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apiextensionsv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	errBoom := errors.New("boom")
+	notAfter := time.Now().Add(365 * 24 * time.Hour).Truncate(time.Second)
+	secret := types.NamespacedName{Namespace: "crossplane-system", Name: "webhook-tls-secret"}
+
+	unhealthyProvider := v1.ProviderRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "provider-aws-abc123"},
+		Spec:       v1.PackageRevisionSpec{DesiredState: v1.PackageRevisionActive},
+	}
+	unhealthyProvider.SetConditions(v1.Unhealthy().WithMessage("boom"), v1.ResolvedDependencies())
+
+	inactiveProvider := v1.ProviderRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "provider-aws-def456"},
+		Spec:       v1.PackageRevisionSpec{DesiredState: v1.PackageRevisionInactive},
+	}
+	inactiveProvider.SetConditions(v1.Unhealthy())
+
+	unresolvedConfiguration := v1.ConfigurationRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "platform-ref-aws-abc123"},
+		Spec:       v1.PackageRevisionSpec{DesiredState: v1.PackageRevisionActive},
+	}
+	unresolvedConfiguration.SetConditions(v1.Healthy(), v1.MissingDependencies())
+
+	established := apiextensionsv1.CompositeResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "xdatabases.example.org"}}
+	established.Status.SetConditions(apiextensionsv1.WatchingComposite())
+
+	notOffered := apiextensionsv1.CompositeResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "xcaches.example.org"},
+		Spec:       apiextensionsv1.CompositeResourceDefinitionSpec{ClaimNames: &extv1.CustomResourceDefinitionNames{Kind: "Cache"}},
+	}
+	notOffered.Status.SetConditions(apiextensionsv1.WatchingComposite())
+
+	notEstablished := apiextensionsv1.CompositeResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "xbuckets.example.org"}}
+
+	// list returns a ListFn that lists the supplied revisions and XRDs.
+	list := func(prs []v1.ProviderRevision, crs []v1.ConfigurationRevision, xrds []apiextensionsv1.CompositeResourceDefinition) test.MockListFn {
+		return test.NewMockListFn(nil, func(o client.ObjectList) error {
+			switch l := o.(type) {
+			case *v1.ProviderRevisionList:
+				l.Items = prs
+			case *v1.ConfigurationRevisionList:
+				l.Items = crs
+			case *apiextensionsv1.CompositeResourceDefinitionList:
+				l.Items = xrds
+			}
+			return nil
+		})
+	}
+
+	// get returns a GetFn that gets the ClusterHealth, and a Secret containing
+	// a certificate that expires at the supplied time.
+	get := func(notAfter time.Time) test.MockGetFn {
+		return test.NewMockGetFn(nil, func(o client.Object) error {
+			if s, ok := o.(*corev1.Secret); ok {
+				s.SetName(secret.Name)
+				s.Data = map[string][]byte{keyTLSCert: cert(t, notAfter)}
+			}
+			return nil
+		})
+	}
+
+	// wantStatus returns a StatusUpdateFn that checks the updated status.
+	wantStatus := func(s v1alpha1.ClusterHealthStatus) test.MockStatusUpdateFn {
+		return test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+			h := o.(*v1alpha1.ClusterHealth)
+			if diff := cmp.Diff(s, h.Status, cmpopts.IgnoreFields(v1alpha1.ClusterHealthStatus{}, "LastCheckTime"), cmpopts.IgnoreFields(xpv1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("Status().Update(...): -want, +got:\n%s", diff)
+			}
+			return nil
+		})
+	}
+
+	type args struct {
+		mgr manager.Manager
+		req reconcile.Request
+		rec []ReconcilerOption
+	}
+	type want struct {
+		r   reconcile.Result
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ClusterHealthNotFound": {
+			reason: "We should not return an error if the ClusterHealth was not found.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+					},
+				},
+			},
+			want: want{
+				r: reconcile.Result{},
+			},
+		},
+		"ErrGetClusterHealth": {
+			reason: "We should return an error if getting the ClusterHealth fails.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(errBoom),
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errGetClusterHealth),
+			},
+		},
+		"ErrListRevisions": {
+			reason: "We should report that we could not check our health if listing package revisions fails.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet:  test.NewMockGetFn(nil),
+						MockList: test.NewMockListFn(errBoom),
+						MockStatusUpdate: wantStatus(v1alpha1.ClusterHealthStatus{
+							ConditionedStatus: xpv1.ConditionedStatus{Conditions: []xpv1.Condition{xpv1.ReconcileError(errors.Wrap(errBoom, errListRevisions))}},
+						}),
+					},
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: checkInterval},
+			},
+		},
+		"ErrGetSecret": {
+			reason: "We should report that we could not check our health if getting the webhook TLS secret fails.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: func(_ context.Context, _ client.ObjectKey, o client.Object) error {
+							if _, ok := o.(*corev1.Secret); ok {
+								return errBoom
+							}
+							return nil
+						},
+						MockList: list(nil, nil, nil),
+						MockStatusUpdate: wantStatus(v1alpha1.ClusterHealthStatus{
+							ConditionedStatus: xpv1.ConditionedStatus{Conditions: []xpv1.Condition{xpv1.ReconcileError(errors.Wrap(errBoom, errGetSecret))}},
+						}),
+					},
+				},
+				rec: []ReconcilerOption{WithWebhookTLSSecret(secret)},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: checkInterval},
+			},
+		},
+		"Healthy": {
+			reason: "We should report that we're ready if nothing is unhealthy.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet:  get(notAfter),
+						MockList: list([]v1.ProviderRevision{inactiveProvider}, nil, []apiextensionsv1.CompositeResourceDefinition{established}),
+						MockStatusUpdate: wantStatus(v1alpha1.ClusterHealthStatus{
+							ConditionedStatus:  xpv1.ConditionedStatus{Conditions: []xpv1.Condition{xpv1.ReconcileSuccess(), xpv1.Available()}},
+							WebhookCertificate: &v1alpha1.WebhookCertificateStatus{SecretName: secret.Name, NotAfter: &metav1.Time{Time: notAfter}},
+						}),
+					},
+				},
+				rec: []ReconcilerOption{WithWebhookTLSSecret(secret)},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: checkInterval},
+			},
+		},
+		"Unhealthy": {
+			reason: "We should summarize everything that is unhealthy, and report that we're not ready.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: get(notAfter),
+						MockList: list(
+							[]v1.ProviderRevision{unhealthyProvider, inactiveProvider},
+							[]v1.ConfigurationRevision{unresolvedConfiguration},
+							[]apiextensionsv1.CompositeResourceDefinition{established, notOffered, notEstablished},
+						),
+						MockStatusUpdate: wantStatus(v1alpha1.ClusterHealthStatus{
+							ConditionedStatus: xpv1.ConditionedStatus{Conditions: []xpv1.Condition{
+								xpv1.ReconcileSuccess(),
+								xpv1.Unavailable().WithMessage(fmt.Sprintf(errFmtUnhealthy, "1 unhealthy package revision(s), 1 package revision(s) with unresolved dependencies, 2 failing CompositeResourceDefinition(s)")),
+							}},
+							UnhealthyPackageRevisions: []v1alpha1.UnhealthyObject{{
+								APIVersion: v1.SchemeGroupVersion.String(),
+								Kind:       v1.ProviderRevisionKind,
+								Name:       "provider-aws-abc123",
+								Reason:     v1.ReasonUnhealthy,
+								Message:    "boom",
+							}},
+							UnresolvedDependencies: []v1alpha1.UnhealthyObject{{
+								APIVersion: v1.SchemeGroupVersion.String(),
+								Kind:       v1.ConfigurationRevisionKind,
+								Name:       "platform-ref-aws-abc123",
+								Reason:     v1.ReasonMissingDependencies,
+							}},
+							FailingDefinitions: []v1alpha1.UnhealthyObject{
+								{
+									APIVersion: apiextensionsv1.SchemeGroupVersion.String(),
+									Kind:       apiextensionsv1.CompositeResourceDefinitionKind,
+									Name:       "xcaches.example.org",
+								},
+								{
+									APIVersion: apiextensionsv1.SchemeGroupVersion.String(),
+									Kind:       apiextensionsv1.CompositeResourceDefinitionKind,
+									Name:       "xbuckets.example.org",
+								},
+							},
+						}),
+					},
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: checkInterval},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewReconciler(tc.args.mgr, tc.args.rec...)
+			got, err := r.Reconcile(context.Background(), tc.args.req)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.r, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCertificate(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	healthy := now.Add(365 * 24 * time.Hour)
+	expiring := now.Add(24 * time.Hour)
+	expired := now.Add(-24 * time.Hour)
+
+	cases := map[string]struct {
+		reason string
+		s      *corev1.Secret
+		want   *v1alpha1.WebhookCertificateStatus
+	}{
+		"NoCertificate": {
+			reason: "A secret without a certificate should be unhealthy.",
+			s:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cool"}},
+			want:   &v1alpha1.WebhookCertificateStatus{SecretName: "cool", Message: errNoCert},
+		},
+		"Healthy": {
+			reason: "A certificate that won't expire soon should be healthy.",
+			s:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cool"}, Data: map[string][]byte{keyTLSCert: cert(t, healthy)}},
+			want:   &v1alpha1.WebhookCertificateStatus{SecretName: "cool", NotAfter: &metav1.Time{Time: healthy}},
+		},
+		"Expiring": {
+			reason: "A certificate that will expire soon should be unhealthy.",
+			s:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cool"}, Data: map[string][]byte{keyTLSCert: cert(t, expiring)}},
+			want: &v1alpha1.WebhookCertificateStatus{
+				SecretName: "cool",
+				NotAfter:   &metav1.Time{Time: expiring},
+				Message:    fmt.Sprintf(errFmtExpiring, "2022-06-02T00:00:00Z"),
+			},
+		},
+		"Expired": {
+			reason: "A certificate that has expired should be unhealthy.",
+			s:      &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "cool"}, Data: map[string][]byte{keyTLSCert: cert(t, expired)}},
+			want: &v1alpha1.WebhookCertificateStatus{
+				SecretName: "cool",
+				NotAfter:   &metav1.Time{Time: expired},
+				Message:    fmt.Sprintf(errFmtExpired, "2022-05-31T00:00:00Z"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := certificate(tc.s, now)
+			if diff := cmp.Diff(tc.want, got, cmp.Comparer(func(a, b metav1.Time) bool { return a.Equal(&b) })); diff != "" {
+				t.Errorf("\n%s\ncertificate(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// cert returns a PEM encoded self-signed certificate that expires at the
+// supplied time.
+func cert(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: notAfter.Add(-2 * 365 * 24 * time.Hour), NotAfter: notAfter}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -18,15 +18,19 @@ package manager
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"strings"
 	"time"
 
+	"github.com/google/go-containerregistry/pkg/name"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/utils/pointer"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -38,6 +42,9 @@ import (
 
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
 	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+	"github.com/crossplane/crossplane/internal/controller/pkg/revision"
+	"github.com/crossplane/crossplane/internal/events"
+	"github.com/crossplane/crossplane/internal/loglevel"
 	"github.com/crossplane/crossplane/internal/xpkg"
 )
 
@@ -61,6 +68,8 @@ const (
 
 	errUpdateStatus                  = "cannot update package status"
 	errUpdateInactivePackageRevision = "cannot update inactive package revision"
+	errUpdateRevisionStatus          = "cannot update package revision status"
+	errAdmitActivation               = "cannot determine whether package revision may be activated"
 
 	errUnhealthyPackageRevision     = "current package revision is unhealthy"
 	errUnknownPackageRevisionHealth = "current package revision health is unknown"
@@ -76,6 +85,7 @@ const (
 	reasonTransitionRevision event.Reason = "TransitionRevision"
 	reasonGarbageCollect     event.Reason = "GarbageCollect"
 	reasonInstall            event.Reason = "InstallPackageRevision"
+	reasonActivate           event.Reason = "ActivatePackageRevision"
 )
 
 // ReconcilerOption is used to configure the Reconciler.
@@ -118,6 +128,14 @@ func WithRevisioner(d Revisioner) ReconcilerOption {
 	}
 }
 
+// WithActivationGate specifies how the Reconciler should determine whether a
+// package revision may be activated.
+func WithActivationGate(g revision.ActivationGate) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.gate = g
+	}
+}
+
 // WithLogger specifies how the Reconciler should log messages.
 func WithLogger(log logging.Logger) ReconcilerOption {
 	return func(r *Reconciler) {
@@ -136,6 +154,7 @@ func WithRecorder(er event.Recorder) ReconcilerOption {
 type Reconciler struct {
 	client               resource.ClientApplicator
 	pkg                  Revisioner
+	gate                 revision.ActivationGate
 	log                  logging.Logger
 	record               event.Recorder
 	webhookTLSSecretName *string
@@ -165,16 +184,17 @@ func SetupProvider(mgr ctrl.Manager, o controller.Options) error {
 		WithNewPackageFn(np),
 		WithNewPackageRevisionFn(nr),
 		WithNewPackageRevisionListFn(nrl),
-		WithRevisioner(NewPackageRevisioner(f, WithDefaultRegistry(o.DefaultRegistry))),
+		WithRevisioner(NewPackageRevisioner(f, WithDefaultRegistry(o.DefaultRegistry), WithFetchTimeInterval(o.PollInterval))),
+		WithActivationGate(revision.NewActivationGate(o, f)),
 		WithLogger(o.Logger.WithValues("controller", name)),
-		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		WithRecorder(events.NewDedupingRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)), o.EventSuppressionWindow)),
 	}
 	if o.WebhookTLSSecretName != "" {
 		opts = append(opts, WithWebhookTLSSecretName(o.WebhookTLSSecretName))
 	}
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
-		For(&v1.Provider{}).
+		For(&v1.Provider{}, builder.WithPredicates(specChanged())).
 		Owns(&v1.ProviderRevision{}).
 		WithOptions(o.ForControllerRuntime()).
 		Complete(ratelimiter.NewReconciler(name, NewReconciler(mgr, opts...), o.GlobalRateLimiter))
@@ -200,19 +220,26 @@ func SetupConfiguration(mgr ctrl.Manager, o controller.Options) error {
 		WithNewPackageFn(np),
 		WithNewPackageRevisionFn(nr),
 		WithNewPackageRevisionListFn(nrl),
-		WithRevisioner(NewPackageRevisioner(fetcher, WithDefaultRegistry(o.DefaultRegistry))),
+		WithRevisioner(NewPackageRevisioner(fetcher, WithDefaultRegistry(o.DefaultRegistry), WithFetchTimeInterval(o.PollInterval))),
+		WithActivationGate(revision.NewActivationGate(o, fetcher)),
 		WithLogger(o.Logger.WithValues("controller", name)),
-		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		WithRecorder(events.NewDedupingRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)), o.EventSuppressionWindow)),
 	)
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
-		For(&v1.Configuration{}).
+		For(&v1.Configuration{}, builder.WithPredicates(specChanged())).
 		Owns(&v1.ConfigurationRevision{}).
 		WithOptions(o.ForControllerRuntime()).
 		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
 }
 
+// specChanged returns a predicate that ignores updates that only change a
+// package's status, such as those we make when we reconcile it.
+func specChanged() predicate.Predicate {
+	return predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+}
+
 // NewReconciler creates a new package reconciler.
 func NewReconciler(mgr ctrl.Manager, opts ...ReconcilerOption) *Reconciler {
 	r := &Reconciler{
@@ -221,6 +248,7 @@ func NewReconciler(mgr ctrl.Manager, opts ...ReconcilerOption) *Reconciler {
 			Applicator: resource.NewAPIPatchingApplicator(mgr.GetClient()),
 		},
 		pkg:    NewNopRevisioner(),
+		gate:   revision.NewNopActivationGate(),
 		log:    logging.NewNopLogger(),
 		record: event.NewNopRecorder(),
 	}
@@ -248,6 +276,8 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetPackage)
 	}
 
+	log = loglevel.ForObject(log, p)
+
 	log = log.WithValues(
 		"uid", p.GetUID(),
 		"version", p.GetResourceVersion(),
@@ -277,15 +307,17 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{Requeue: true}, errors.Wrap(r.client.Status().Update(ctx, p), errUpdateStatus)
 	}
 
-	// Set the current revision and identifier.
+	// Set the current revision, identifier, and version.
 	p.SetCurrentRevision(revisionName)
 	p.SetCurrentIdentifier(p.GetSource())
+	p.SetInstalledVersion(packageVersion(p.GetSource()))
 
 	pr := r.newPackageRevision()
 	maxRevision := int64(0)
 	oldestRevision := int64(math.MaxInt64)
 	oldestRevisionIndex := -1
 	revisions := prs.GetRevisions()
+	active := make([]v1.PackageRevision, 0)
 
 	// Check to see if revision already exists.
 	for index, rev := range revisions {
@@ -311,16 +343,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			continue
 		}
 		if rev.GetDesiredState() == v1.PackageRevisionActive {
-			// If revision is not the current revision, set to
-			// inactive. This should always be done, regardless of
-			// the package's revision activation policy.
-			rev.SetDesiredState(v1.PackageRevisionInactive)
-			if err := r.client.Apply(ctx, rev, resource.MustBeControllableBy(p.GetUID())); err != nil {
-				log.Debug(errUpdateInactivePackageRevision, "error", err)
-				err = errors.Wrap(err, errUpdateInactivePackageRevision)
-				r.record.Event(p, event.Warning(reasonTransitionRevision, err))
-				return reconcile.Result{}, err
-			}
+			active = append(active, rev)
 		}
 	}
 
@@ -343,6 +366,10 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		}
 	}
 
+	// The revision's annotations are those of its package's metadata.
+	p.SetPackageMetadata(xpkg.Metadata(pr.GetAnnotations()))
+	p.SetDependencyStatus(dependencyStatus(pr))
+
 	if pr.GetCondition(v1.TypeHealthy).Status == corev1.ConditionTrue {
 		p.SetConditions(v1.Healthy())
 		r.record.Event(p, event.Normal(reasonInstall, "Successfully installed package revision"))
@@ -358,19 +385,59 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 
 	// Create the non-existent package revision.
 	pr.SetName(revisionName)
-	pr.SetLabels(map[string]string{v1.LabelParentPackage: p.GetName()})
+	labels := map[string]string{v1.LabelParentPackage: p.GetName()}
+	if v, ok := p.GetLabels()[v1.LabelResolvedDependency]; ok {
+		labels[v1.LabelResolvedDependency] = v
+	}
+	pr.SetLabels(labels)
 	pr.SetSource(p.GetSource())
 	pr.SetPackagePullPolicy(p.GetPackagePullPolicy())
 	pr.SetPackagePullSecrets(p.GetPackagePullSecrets())
 	pr.SetIgnoreCrossplaneConstraints(p.GetIgnoreCrossplaneConstraints())
+	pr.SetChannel(p.GetChannel())
 	pr.SetSkipDependencyResolution(p.GetSkipDependencyResolution())
 	pr.SetControllerConfigRef(p.GetControllerConfigRef())
 	pr.SetWebhookTLSSecretName(r.webhookTLSSecretName)
 
 	// If current revision is not active and we have an automatic or
-	// undefined activation policy, always activate.
+	// undefined activation policy, always activate it - if it may be
+	// activated. We ask before we deactivate any other revision, so that a
+	// revision that may not be activated doesn't leave the package without
+	// an active revision.
+	admitted := ""
+	denied := false
 	if pr.GetDesiredState() != v1.PackageRevisionActive && (p.GetActivationPolicy() == nil || *p.GetActivationPolicy() == v1.AutomaticActivation) {
-		pr.SetDesiredState(v1.PackageRevisionActive)
+		id, err := r.gate.Admit(ctx, pr)
+		switch {
+		case revision.IsActivationDenied(err):
+			denied = true
+			pr.SetDesiredState(v1.PackageRevisionInactive)
+			p.SetConditions(v1.PolicyDenied().WithMessage(err.Error()))
+			r.record.Event(p, event.Warning(reasonActivate, err))
+		case err != nil:
+			log.Debug(errAdmitActivation, "error", err)
+			err = errors.Wrap(err, errAdmitActivation)
+			r.record.Event(p, event.Warning(reasonActivate, err))
+			return reconcile.Result{}, err
+		default:
+			admitted = id
+			pr.SetDesiredState(v1.PackageRevisionActive)
+		}
+	}
+
+	// If revision is not the current revision, set to inactive. This should
+	// always be done, regardless of the package's revision activation
+	// policy, unless the current revision was denied activation.
+	if !denied {
+		for _, rev := range active {
+			rev.SetDesiredState(v1.PackageRevisionInactive)
+			if err := r.client.Apply(ctx, rev, resource.MustBeControllableBy(p.GetUID())); err != nil {
+				log.Debug(errUpdateInactivePackageRevision, "error", err)
+				err = errors.Wrap(err, errUpdateInactivePackageRevision)
+				r.record.Event(p, event.Warning(reasonTransitionRevision, err))
+				return reconcile.Result{}, err
+			}
+		}
 	}
 
 	controlRef := meta.AsController(meta.TypedReferenceTo(p, p.GetObjectKind().GroupVersionKind()))
@@ -383,6 +450,18 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, err
 	}
 
+	// Record that the revision was admitted, so that it isn't asked about
+	// again.
+	if admitted != "" {
+		pr.SetAdmittedPackage(admitted)
+		if err := r.client.Status().Update(ctx, pr); err != nil {
+			log.Debug(errUpdateRevisionStatus, "error", err)
+			err = errors.Wrap(err, errUpdateRevisionStatus)
+			r.record.Event(p, event.Warning(reasonActivate, err))
+			return reconcile.Result{}, err
+		}
+	}
+
 	p.SetConditions(v1.Active())
 
 	// If current revision is still not active, the package is inactive.
@@ -394,5 +473,30 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	// package, the health of the package is not set until the revision reports
 	// its health. If updating from an existing revision, the package health
 	// will match the health of the old revision until the next reconcile.
-	return pullBasedRequeue(p.GetPackagePullPolicy()), errors.Wrap(r.client.Status().Update(ctx, p), errUpdateStatus)
+	result := pullBasedRequeue(p.GetPackagePullPolicy())
+	if denied {
+		result = reconcile.Result{RequeueAfter: revision.DeniedRecheckInterval}
+	}
+	return result, errors.Wrap(r.client.Status().Update(ctx, p), errUpdateStatus)
+}
+
+// packageVersion returns the version of the supplied package source, i.e. its
+// tag or digest, or an empty string if it can't be parsed.
+func packageVersion(src string) string {
+	ref, err := xpkg.ParseSource(src, name.DefaultRegistry)
+	if err != nil {
+		return ""
+	}
+	return ref.Identifier()
+}
+
+// dependencyStatus summarizes the dependencies of the supplied package
+// revision as the number installed out of the number found, or returns an
+// empty string if it has no dependencies.
+func dependencyStatus(pr v1.PackageRevision) string {
+	found, installed, _ := pr.GetDependencyStatus()
+	if found == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", installed, found)
 }
@@ -34,7 +34,9 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/revision"
 )
 
 var _ Revisioner = &MockRevisioner{}
@@ -117,6 +119,7 @@ func TestReconcile(t *testing.T) {
 							MockList: test.NewMockListFn(errBoom),
 						},
 					},
+					gate:   revision.NewNopActivationGate(),
 					log:    logging.NewNopLogger(),
 					record: event.NewNopRecorder(),
 				},
@@ -164,6 +167,51 @@ func TestReconcile(t *testing.T) {
 					pkg: &MockRevisioner{
 						MockRevision: NewMockRevisionFn("test-1234567", nil),
 					},
+					gate:   revision.NewNopActivationGate(),
+					log:    logging.NewNopLogger(),
+					record: event.NewNopRecorder(),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
+		"SuccessfulNoExistingRevisionsPropagateResolvedDependency": {
+			reason: "We should label the revision of a package that was installed to satisfy a dependency.",
+			args: args{
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: &Reconciler{
+					newPackage:             func() v1.Package { return &v1.Configuration{} },
+					newPackageRevision:     func() v1.PackageRevision { return &v1.ConfigurationRevision{} },
+					newPackageRevisionList: func() v1.PackageRevisionList { return &v1.ConfigurationRevisionList{} },
+					client: resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								p := o.(*v1.Configuration)
+								p.SetName("test")
+								p.SetLabels(map[string]string{v1.LabelResolvedDependency: "true"})
+								p.SetGroupVersionKind(v1.ConfigurationGroupVersionKind)
+								p.SetActivationPolicy(&v1.AutomaticActivation)
+								return nil
+							}),
+							MockList:         test.NewMockListFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+							want := map[string]string{
+								v1.LabelParentPackage:      "test",
+								v1.LabelResolvedDependency: "true",
+							}
+							if diff := cmp.Diff(want, o.GetLabels()); diff != "" {
+								t.Errorf("-want, +got:\n%s", diff)
+							}
+							return nil
+						}),
+					},
+					pkg: &MockRevisioner{
+						MockRevision: NewMockRevisionFn("test-1234567", nil),
+					},
+					gate:   revision.NewNopActivationGate(),
 					log:    logging.NewNopLogger(),
 					record: event.NewNopRecorder(),
 				},
@@ -213,6 +261,7 @@ func TestReconcile(t *testing.T) {
 					pkg: &MockRevisioner{
 						MockRevision: NewMockRevisionFn("test-1234567", nil),
 					},
+					gate:   revision.NewNopActivationGate(),
 					log:    logging.NewNopLogger(),
 					record: event.NewNopRecorder(),
 				},
@@ -260,6 +309,7 @@ func TestReconcile(t *testing.T) {
 					pkg: &MockRevisioner{
 						MockRevision: NewMockRevisionFn("test-1234567", nil),
 					},
+					gate:   revision.NewNopActivationGate(),
 					log:    logging.NewNopLogger(),
 					record: event.NewNopRecorder(),
 				},
@@ -318,6 +368,139 @@ func TestReconcile(t *testing.T) {
 					pkg: &MockRevisioner{
 						MockRevision: NewMockRevisionFn("test-1234567", nil),
 					},
+					gate:   revision.NewNopActivationGate(),
+					log:    logging.NewNopLogger(),
+					record: event.NewNopRecorder(),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
+		"SuccessfulReportInstalledVersionAndDependencies": {
+			reason: "We should report the version of the current revision, and a summary of its dependencies.",
+			args: args{
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: &Reconciler{
+					newPackage:             func() v1.Package { return &v1.Configuration{} },
+					newPackageRevision:     func() v1.PackageRevision { return &v1.ConfigurationRevision{} },
+					newPackageRevisionList: func() v1.PackageRevisionList { return &v1.ConfigurationRevisionList{} },
+					client: resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								p := o.(*v1.Configuration)
+								p.SetName("test")
+								p.SetSource("crossplane/getting-started-with-aws:v1.2.3")
+								p.SetGroupVersionKind(v1.ConfigurationGroupVersionKind)
+								return nil
+							}),
+							MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+								l := o.(*v1.ConfigurationRevisionList)
+								cr := v1.ConfigurationRevision{
+									ObjectMeta: metav1.ObjectMeta{
+										Name: "test-1234567",
+									},
+								}
+								cr.SetConditions(v1.Healthy())
+								cr.SetDependencyStatus(3, 2, 0)
+								c := v1.ConfigurationRevisionList{
+									Items: []v1.ConfigurationRevision{cr},
+								}
+								*l = c
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.Configuration{}
+								want.SetName("test")
+								want.SetSource("crossplane/getting-started-with-aws:v1.2.3")
+								want.SetGroupVersionKind(v1.ConfigurationGroupVersionKind)
+								want.SetCurrentRevision("test-1234567")
+								want.SetCurrentIdentifier("crossplane/getting-started-with-aws:v1.2.3")
+								want.SetInstalledVersion("v1.2.3")
+								want.SetDependencyStatus("2/3")
+								want.SetConditions(v1.Healthy())
+								want.SetConditions(v1.Active())
+								if diff := cmp.Diff(want, o, test.EquateConditions()); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+						Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+							return nil
+						}),
+					},
+					pkg: &MockRevisioner{
+						MockRevision: NewMockRevisionFn("test-1234567", nil),
+					},
+					gate:   revision.NewNopActivationGate(),
+					log:    logging.NewNopLogger(),
+					record: event.NewNopRecorder(),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
+		"SuccessfulPropagatePackageMetadata": {
+			reason: "We should surface the metadata of the current revision's package in our status.",
+			args: args{
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: &Reconciler{
+					newPackage:             func() v1.Package { return &v1.Configuration{} },
+					newPackageRevision:     func() v1.PackageRevision { return &v1.ConfigurationRevision{} },
+					newPackageRevisionList: func() v1.PackageRevisionList { return &v1.ConfigurationRevisionList{} },
+					client: resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								p := o.(*v1.Configuration)
+								p.SetName("test")
+								p.SetGroupVersionKind(v1.ConfigurationGroupVersionKind)
+								return nil
+							}),
+							MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+								l := o.(*v1.ConfigurationRevisionList)
+								cr := v1.ConfigurationRevision{
+									ObjectMeta: metav1.ObjectMeta{
+										Name: "test-1234567",
+										Annotations: map[string]string{
+											pkgmetav1.AnnotationDescription: "A test configuration.",
+											pkgmetav1.AnnotationSource:      "github.com/crossplane/configuration-test",
+										},
+									},
+								}
+								cr.SetConditions(v1.Healthy())
+								c := v1.ConfigurationRevisionList{
+									Items: []v1.ConfigurationRevision{cr},
+								}
+								*l = c
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.Configuration{}
+								want.SetName("test")
+								want.SetGroupVersionKind(v1.ConfigurationGroupVersionKind)
+								want.SetCurrentRevision("test-1234567")
+								want.SetPackageMetadata(&v1.PackageMetadata{
+									Description: "A test configuration.",
+									Source:      "github.com/crossplane/configuration-test",
+								})
+								want.SetConditions(v1.Healthy())
+								want.SetConditions(v1.Active())
+								if diff := cmp.Diff(want, o, test.EquateConditions()); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+						Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+							return nil
+						}),
+					},
+					pkg: &MockRevisioner{
+						MockRevision: NewMockRevisionFn("test-1234567", nil),
+					},
+					gate:   revision.NewNopActivationGate(),
 					log:    logging.NewNopLogger(),
 					record: event.NewNopRecorder(),
 				},
@@ -396,6 +579,7 @@ func TestReconcile(t *testing.T) {
 					pkg: &MockRevisioner{
 						MockRevision: NewMockRevisionFn("test-1234567", nil),
 					},
+					gate:   revision.NewNopActivationGate(),
 					log:    logging.NewNopLogger(),
 					record: event.NewNopRecorder(),
 				},
@@ -455,6 +639,7 @@ func TestReconcile(t *testing.T) {
 					pkg: &MockRevisioner{
 						MockRevision: NewMockRevisionFn("test-1234567", nil),
 					},
+					gate:   revision.NewNopActivationGate(),
 					log:    logging.NewNopLogger(),
 					record: event.NewNopRecorder(),
 				},
@@ -515,6 +700,7 @@ func TestReconcile(t *testing.T) {
 					pkg: &MockRevisioner{
 						MockRevision: NewMockRevisionFn("test-1234567", nil),
 					},
+					gate:   revision.NewNopActivationGate(),
 					log:    logging.NewNopLogger(),
 					record: event.NewNopRecorder(),
 				},
@@ -612,6 +798,164 @@ func TestReconcile(t *testing.T) {
 					pkg: &MockRevisioner{
 						MockRevision: NewMockRevisionFn("test-1234567", nil),
 					},
+					gate:   revision.NewNopActivationGate(),
+					log:    logging.NewNopLogger(),
+					record: event.NewNopRecorder(),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
+		"ActivationDenied": {
+			reason: "We should not activate a revision that is denied activation, nor deactivate the active revision.",
+			args: args{
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: &Reconciler{
+					newPackage:             func() v1.Package { return &v1.Configuration{} },
+					newPackageRevision:     func() v1.PackageRevision { return &v1.ConfigurationRevision{} },
+					newPackageRevisionList: func() v1.PackageRevisionList { return &v1.ConfigurationRevisionList{} },
+					client: resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								p := o.(*v1.Configuration)
+								p.SetName("test")
+								p.SetGroupVersionKind(v1.ConfigurationGroupVersionKind)
+								return nil
+							}),
+							MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+								l := o.(*v1.ConfigurationRevisionList)
+								cr := v1.ConfigurationRevision{ObjectMeta: metav1.ObjectMeta{Name: "test-old"}}
+								cr.SetRevision(1)
+								cr.SetDesiredState(v1.PackageRevisionActive)
+								l.Items = []v1.ConfigurationRevision{cr}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.Configuration{}
+								want.SetName("test")
+								want.SetGroupVersionKind(v1.ConfigurationGroupVersionKind)
+								want.SetCurrentRevision("test-1234567")
+								want.SetConditions(v1.PolicyDenied().WithMessage(errBoom.Error()))
+								want.SetConditions(v1.Inactive())
+								if diff := cmp.Diff(want, o, test.EquateConditions()); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+							pr := o.(v1.PackageRevision)
+							if pr.GetName() != "test-1234567" || pr.GetDesiredState() != v1.PackageRevisionInactive {
+								t.Errorf("Apply(...): want only the denied revision to be applied, inactive; got %q, %q", pr.GetName(), pr.GetDesiredState())
+							}
+							return nil
+						}),
+					},
+					pkg: &MockRevisioner{
+						MockRevision: NewMockRevisionFn("test-1234567", nil),
+					},
+					gate: revision.ActivationGateFn(func(_ context.Context, _ v1.PackageRevision) (string, error) {
+						return "", revision.NewActivationDeniedError(errBoom)
+					}),
+					log:    logging.NewNopLogger(),
+					record: event.NewNopRecorder(),
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: revision.DeniedRecheckInterval},
+			},
+		},
+		"ErrAdmitActivation": {
+			reason: "We should return an error, without deactivating the active revision, if we can't determine whether a revision may be activated.",
+			args: args{
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: &Reconciler{
+					newPackage:             func() v1.Package { return &v1.Configuration{} },
+					newPackageRevision:     func() v1.PackageRevision { return &v1.ConfigurationRevision{} },
+					newPackageRevisionList: func() v1.PackageRevisionList { return &v1.ConfigurationRevisionList{} },
+					client: resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								p := o.(*v1.Configuration)
+								p.SetName("test")
+								p.SetGroupVersionKind(v1.ConfigurationGroupVersionKind)
+								return nil
+							}),
+							MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+								l := o.(*v1.ConfigurationRevisionList)
+								cr := v1.ConfigurationRevision{ObjectMeta: metav1.ObjectMeta{Name: "test-old"}}
+								cr.SetRevision(1)
+								cr.SetDesiredState(v1.PackageRevisionActive)
+								l.Items = []v1.ConfigurationRevision{cr}
+								return nil
+							}),
+						},
+						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+							t.Errorf("Apply(...): no revision should be applied")
+							return nil
+						}),
+					},
+					pkg: &MockRevisioner{
+						MockRevision: NewMockRevisionFn("test-1234567", nil),
+					},
+					gate: revision.ActivationGateFn(func(_ context.Context, _ v1.PackageRevision) (string, error) {
+						return "", errBoom
+					}),
+					log:    logging.NewNopLogger(),
+					record: event.NewNopRecorder(),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errAdmitActivation),
+			},
+		},
+		"ActivationAdmitted": {
+			reason: "We should activate an admitted revision, deactivate the previously active revision, and record that the revision was admitted.",
+			args: args{
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: &Reconciler{
+					newPackage:             func() v1.Package { return &v1.Configuration{} },
+					newPackageRevision:     func() v1.PackageRevision { return &v1.ConfigurationRevision{} },
+					newPackageRevisionList: func() v1.PackageRevisionList { return &v1.ConfigurationRevisionList{} },
+					client: resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								p := o.(*v1.Configuration)
+								p.SetName("test")
+								p.SetGroupVersionKind(v1.ConfigurationGroupVersionKind)
+								return nil
+							}),
+							MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+								l := o.(*v1.ConfigurationRevisionList)
+								cr := v1.ConfigurationRevision{ObjectMeta: metav1.ObjectMeta{Name: "test-old"}}
+								cr.SetRevision(1)
+								cr.SetDesiredState(v1.PackageRevisionActive)
+								l.Items = []v1.ConfigurationRevision{cr}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								if pr, ok := o.(v1.PackageRevision); ok && pr.GetAdmittedPackage() != "sha256:cool" {
+									t.Errorf("Status().Update(...): want admitted package recorded, got %q", pr.GetAdmittedPackage())
+								}
+								return nil
+							}),
+						},
+						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+							pr := o.(v1.PackageRevision)
+							want := map[string]v1.PackageRevisionDesiredState{"test-old": v1.PackageRevisionInactive, "test-1234567": v1.PackageRevisionActive}
+							if diff := cmp.Diff(want[pr.GetName()], pr.GetDesiredState()); diff != "" {
+								t.Errorf("Apply(%s): -want desired state, +got:\n%s", pr.GetName(), diff)
+							}
+							return nil
+						}),
+					},
+					pkg: &MockRevisioner{
+						MockRevision: NewMockRevisionFn("test-1234567", nil),
+					},
+					gate: revision.ActivationGateFn(func(_ context.Context, _ v1.PackageRevision) (string, error) {
+						return "sha256:cool", nil
+					}),
 					log:    logging.NewNopLogger(),
 					record: event.NewNopRecorder(),
 				},
@@ -691,6 +1035,7 @@ func TestReconcile(t *testing.T) {
 					pkg: &MockRevisioner{
 						MockRevision: NewMockRevisionFn("test-1234567", nil),
 					},
+					gate:   revision.NewNopActivationGate(),
 					log:    logging.NewNopLogger(),
 					record: event.NewNopRecorder(),
 				},
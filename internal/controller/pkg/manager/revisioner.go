@@ -18,9 +18,13 @@ package manager
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
@@ -32,6 +36,10 @@ const (
 	errFetchPackage = "failed to fetch package digest from remote"
 )
 
+// defaultFetchTimeInterval is how often a package revisioner records that it
+// fetched a package's digest, if the digest didn't change.
+const defaultFetchTimeInterval = 1 * time.Minute
+
 // Revisioner extracts a revision name for a package source.
 type Revisioner interface {
 	Revision(context.Context, v1.Package) (string, error)
@@ -41,6 +49,7 @@ type Revisioner interface {
 type PackageRevisioner struct {
 	fetcher  xpkg.Fetcher
 	registry string
+	interval time.Duration
 }
 
 // A PackageRevisionerOption sets configuration for a package revisioner.
@@ -53,10 +62,19 @@ func WithDefaultRegistry(registry string) PackageRevisionerOption {
 	}
 }
 
+// WithFetchTimeInterval sets how often a package revisioner records that it
+// fetched a package's digest, if the digest didn't change.
+func WithFetchTimeInterval(d time.Duration) PackageRevisionerOption {
+	return func(r *PackageRevisioner) {
+		r.interval = d
+	}
+}
+
 // NewPackageRevisioner returns a new PackageRevisioner.
 func NewPackageRevisioner(fetcher xpkg.Fetcher, opts ...PackageRevisionerOption) *PackageRevisioner {
 	r := &PackageRevisioner{
-		fetcher: fetcher,
+		fetcher:  fetcher,
+		interval: defaultFetchTimeInterval,
 	}
 	for _, opt := range opts {
 		opt(r)
@@ -64,8 +82,16 @@ func NewPackageRevisioner(fetcher xpkg.Fetcher, opts ...PackageRevisionerOption)
 	return r
 }
 
-// Revision extracts a revision name for a package source.
+// Revision extracts a revision name for a package source. It records when it
+// last fetched the package's digest, if the fetch changed the package's current
+// revision or if it hasn't recorded a fetch within its fetch time interval.
 func (r *PackageRevisioner) Revision(ctx context.Context, p v1.Package) (string, error) {
+	// Local packages can't be pulled, so each source must identify distinct
+	// package contents.
+	if xpkg.IsLocalSource(p.GetSource()) {
+		h := sha256.Sum256([]byte(p.GetSource()))
+		return xpkg.FriendlyID(p.GetName(), hex.EncodeToString(h[:])), nil
+	}
 	pullPolicy := p.GetPackagePullPolicy()
 	if pullPolicy != nil && *pullPolicy == corev1.PullNever {
 		return xpkg.FriendlyID(p.GetName(), p.GetSource()), nil
@@ -83,7 +109,14 @@ func (r *PackageRevisioner) Revision(ctx context.Context, p v1.Package) (string,
 	if err != nil || d == nil {
 		return "", errors.Wrap(err, errFetchPackage)
 	}
-	return xpkg.FriendlyID(p.GetName(), d.Digest.Hex), nil
+	id := xpkg.FriendlyID(p.GetName(), d.Digest.Hex)
+
+	// Recording every fetch would change the package's status, and thus
+	// trigger another reconcile, every time we reconcile it.
+	if t := p.GetLastFetchTime(); t == nil || id != p.GetCurrentRevision() || time.Since(t.Time) >= r.interval {
+		p.SetLastFetchTime(&metav1.Time{Time: time.Now()})
+	}
+	return id, nil
 }
 
 // NopRevisioner returns an empty revision name.
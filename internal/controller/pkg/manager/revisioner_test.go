@@ -19,8 +19,10 @@ package manager
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	gcrv1 "github.com/google/go-containerregistry/pkg/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
@@ -42,8 +44,9 @@ func TestPackageRevisioner(t *testing.T) {
 	}
 
 	type want struct {
-		err    error
-		digest string
+		err     error
+		digest  string
+		fetched bool
 	}
 
 	cases := map[string]struct {
@@ -70,6 +73,28 @@ func TestPackageRevisioner(t *testing.T) {
 				digest: "provider-aws-my-revision",
 			},
 		},
+		"SuccessfulLocalSource": {
+			reason: "Should return a friendly identifier derived from the source if the package is local.",
+			args: args{
+				f: &fake.MockFetcher{
+					// Local packages should not be fetched.
+					MockHead: fake.NewMockHeadFn(nil, errBoom),
+				},
+				pkg: &v1.Provider{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "provider-aws",
+					},
+					Spec: v1.ProviderSpec{
+						PackageSpec: v1.PackageSpec{
+							Package: "file://provider-aws:dev-1234567",
+						},
+					},
+				},
+			},
+			want: want{
+				digest: "provider-aws-cfe212c95cb1",
+			},
+		},
 		"SuccessfulPullIfNotPresentSameSource": {
 			reason: "Should return the existing package revision if identifier did not change.",
 			args: args{
@@ -95,6 +120,111 @@ func TestPackageRevisioner(t *testing.T) {
 				digest: "return-me",
 			},
 		},
+		"SuccessfulFetch": {
+			reason: "Should return a friendly identifier derived from the fetched digest, and record when it was fetched.",
+			args: args{
+				f: &fake.MockFetcher{
+					MockHead: fake.NewMockHeadFn(&gcrv1.Descriptor{Digest: gcrv1.Hash{Algorithm: "sha256", Hex: "ecc25c121431dfc7058754427f97c034ecde26d4aafa0da16d6a47b3e1296ad5"}}, nil),
+				},
+				pkg: &v1.Provider{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "provider-aws",
+					},
+					Spec: v1.ProviderSpec{
+						PackageSpec: v1.PackageSpec{
+							Package: "crossplane/provider-aws:v0.28.0",
+						},
+					},
+				},
+			},
+			want: want{
+				digest:  "provider-aws-ecc25c121431",
+				fetched: true,
+			},
+		},
+		"SuccessfulFetchRecentlyRecorded": {
+			reason: "Should not record a fetch that didn't change the current revision if a fetch was recorded recently.",
+			args: args{
+				f: &fake.MockFetcher{
+					MockHead: fake.NewMockHeadFn(&gcrv1.Descriptor{Digest: gcrv1.Hash{Algorithm: "sha256", Hex: "ecc25c121431dfc7058754427f97c034ecde26d4aafa0da16d6a47b3e1296ad5"}}, nil),
+				},
+				pkg: &v1.Provider{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "provider-aws",
+					},
+					Spec: v1.ProviderSpec{
+						PackageSpec: v1.PackageSpec{
+							Package: "crossplane/provider-aws:v0.28.0",
+						},
+					},
+					Status: v1.ProviderStatus{
+						PackageStatus: v1.PackageStatus{
+							CurrentRevision: "provider-aws-ecc25c121431",
+							LastFetchTime:   &metav1.Time{Time: time.Now()},
+						},
+					},
+				},
+			},
+			want: want{
+				digest: "provider-aws-ecc25c121431",
+			},
+		},
+		"SuccessfulFetchRevisionChanged": {
+			reason: "Should record a fetch that changed the current revision even if a fetch was recorded recently.",
+			args: args{
+				f: &fake.MockFetcher{
+					MockHead: fake.NewMockHeadFn(&gcrv1.Descriptor{Digest: gcrv1.Hash{Algorithm: "sha256", Hex: "ecc25c121431dfc7058754427f97c034ecde26d4aafa0da16d6a47b3e1296ad5"}}, nil),
+				},
+				pkg: &v1.Provider{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "provider-aws",
+					},
+					Spec: v1.ProviderSpec{
+						PackageSpec: v1.PackageSpec{
+							Package: "crossplane/provider-aws:v0.28.0",
+						},
+					},
+					Status: v1.ProviderStatus{
+						PackageStatus: v1.PackageStatus{
+							CurrentRevision: "provider-aws-0123456789ab",
+							LastFetchTime:   &metav1.Time{Time: time.Now()},
+						},
+					},
+				},
+			},
+			want: want{
+				digest:  "provider-aws-ecc25c121431",
+				fetched: true,
+			},
+		},
+		"SuccessfulFetchRecordedLongAgo": {
+			reason: "Should record a fetch that didn't change the current revision if no fetch was recorded recently.",
+			args: args{
+				f: &fake.MockFetcher{
+					MockHead: fake.NewMockHeadFn(&gcrv1.Descriptor{Digest: gcrv1.Hash{Algorithm: "sha256", Hex: "ecc25c121431dfc7058754427f97c034ecde26d4aafa0da16d6a47b3e1296ad5"}}, nil),
+				},
+				pkg: &v1.Provider{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "provider-aws",
+					},
+					Spec: v1.ProviderSpec{
+						PackageSpec: v1.PackageSpec{
+							Package: "crossplane/provider-aws:v0.28.0",
+						},
+					},
+					Status: v1.ProviderStatus{
+						PackageStatus: v1.PackageStatus{
+							CurrentRevision: "provider-aws-ecc25c121431",
+							LastFetchTime:   &metav1.Time{Time: time.Now().Add(-2 * defaultFetchTimeInterval)},
+						},
+					},
+				},
+			},
+			want: want{
+				digest:  "provider-aws-ecc25c121431",
+				fetched: true,
+			},
+		},
 		"ErrParseRef": {
 			reason: "Should return an error if we cannot parse reference from package source image.",
 			args: args{
@@ -133,6 +263,7 @@ func TestPackageRevisioner(t *testing.T) {
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
 			r := NewPackageRevisioner(tc.args.f)
+			before := tc.args.pkg.GetLastFetchTime()
 			h, err := r.Revision(context.TODO(), tc.args.pkg)
 
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
@@ -141,6 +272,9 @@ func TestPackageRevisioner(t *testing.T) {
 			if diff := cmp.Diff(tc.want.digest, h, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nr.Name(...): -want, +got:\n%s", tc.reason, diff)
 			}
+			if diff := cmp.Diff(tc.want.fetched, tc.args.pkg.GetLastFetchTime() != before); diff != "" {
+				t.Errorf("\n%s\nr.Name(...): -want fetched, +got fetched:\n%s", tc.reason, diff)
+			}
 		})
 	}
 }
@@ -0,0 +1,337 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+	"github.com/crossplane/crossplane/internal/controller/pkg/revision"
+	"github.com/crossplane/crossplane/internal/version"
+	xpwebhook "github.com/crossplane/crossplane/internal/webhook"
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+// WebhookPath is the path at which packages are validated.
+const WebhookPath = "/validate-pkg-crossplane-io-v1-packages"
+
+// Error strings.
+const (
+	errDecodePackage = "cannot decode package"
+	errParseSource   = "cannot parse package source"
+
+	errGetLock                    = "cannot get lock"
+	errInitBackend                = "cannot fetch package"
+	errParsePackage               = "cannot parse package"
+	errNotOneConfiguration        = "package must contain exactly one Configuration"
+	errFmtCheckDependencies       = "cannot check the dependencies of package %q; they will be checked once it is installed: %s"
+	errFmtConflictingDependencies = "updating to package %q would introduce conflicting dependencies: %s"
+	msgFmtMissingDependencies     = "updating to package %q will install missing dependencies: %s"
+	msgFmtUnsatisfied             = "%s is installed at %s, but %s requires %s"
+	msgFmtInvalidConstraints      = "%s requires %s, which is not a valid version constraint"
+)
+
+// dependencyCheckTimeout bounds how long we spend fetching a package in order
+// to check its dependencies. It's well below the API server's default webhook
+// timeout, so that a slow registry can't cause an update to be rejected.
+const dependencyCheckTimeout = 5 * time.Second
+
+// lockName is the name of the lock in which the package manager records the
+// installed packages and their dependencies.
+const lockName = "lock"
+
+// SetupWebhook registers the handlers that validate packages with the webhook
+// server of the supplied manager.
+func SetupWebhook(mgr ctrl.Manager, o controller.Options) error {
+	cs, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return errors.Wrap(err, "failed to initialize clientset")
+	}
+	f, err := xpkg.NewK8sFetcher(cs, o.Namespace, o.FetcherOptions...)
+	if err != nil {
+		return errors.Wrap(err, "cannot build fetcher")
+	}
+	metaScheme, err := xpkg.BuildMetaScheme()
+	if err != nil {
+		return errors.New("cannot build meta scheme for package parser")
+	}
+	objScheme, err := xpkg.BuildObjectScheme()
+	if err != nil {
+		return errors.New("cannot build object scheme for package parser")
+	}
+
+	mgr.GetWebhookServer().Register(WebhookPath, &webhook.Admission{Handler: xpwebhook.MultiValidatingHandler(
+		NewSourceValidator(xpkg.NewAPISourcePolicy(mgr.GetClient()), o.DefaultRegistry),
+		NewDependencyValidator(mgr.GetClient(),
+			revision.NewImageBackend(f, revision.WithDefaultRegistry(o.DefaultRegistry)),
+			xpkg.NewStreamParser(metaScheme, objScheme)),
+	)})
+	return nil
+}
+
+// A SourceValidator is an admission handler that rejects packages whose source
+// is not permitted by a source policy.
+type SourceValidator struct {
+	policy   xpkg.SourcePolicy
+	registry string
+}
+
+// NewSourceValidator returns an admission handler that validates the sources
+// of packages against the supplied policy. Sources that don't specify a
+// registry are assumed to use the supplied default registry.
+func NewSourceValidator(p xpkg.SourcePolicy, defaultRegistry string) *SourceValidator {
+	return &SourceValidator{policy: p, registry: defaultRegistry}
+}
+
+// Handle an admission request for a package.
+func (v *SourceValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	src, err := source(req.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// We don't reject updates that leave the source unchanged, so that
+	// packages installed before a policy was created can still be updated.
+	if req.Operation == admissionv1.Update {
+		old, err := source(req.OldObject)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if old == src {
+			return admission.Allowed("")
+		}
+	}
+
+	ref, err := xpkg.ParseSource(src, v.registry)
+	if err != nil {
+		return admission.Denied(errors.Wrap(err, errParseSource).Error())
+	}
+
+	if err := v.policy.Permit(ctx, ref.Context()); err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	return admission.Allowed("")
+}
+
+func source(o runtime.RawExtension) (string, error) {
+	m := map[string]interface{}{}
+	if err := json.Unmarshal(o.Raw, &m); err != nil {
+		return "", errors.Wrap(err, errDecodePackage)
+	}
+	s, err := fieldpath.Pave(m).GetString("spec.package")
+	return s, errors.Wrap(err, errDecodePackage)
+}
+
+// A DependencyValidator is an admission handler that checks the dependencies
+// of the package a Configuration is being updated to against the packages that
+// are installed. It warns about dependencies that are missing, which will be
+// installed, and rejects updates that would introduce dependencies that
+// conflict with installed packages. Updates are allowed with a warning if the
+// package can't be fetched in time, in which case its dependencies are checked
+// as usual once it is installed.
+type DependencyValidator struct {
+	client  client.Reader
+	backend parser.Backend
+	parser  parser.Parser
+}
+
+// NewDependencyValidator returns an admission handler that checks the
+// dependencies of the package a Configuration is being updated to. The
+// supplied backend should fetch the package of the supplied revision.
+func NewDependencyValidator(c client.Reader, b parser.Backend, p parser.Parser) *DependencyValidator {
+	return &DependencyValidator{client: c, backend: b, parser: p}
+}
+
+// Handle an admission request for a package.
+func (v *DependencyValidator) Handle(ctx context.Context, req admission.Request) admission.Response { //nolint:gocyclo // Mostly checks to skip.
+	if req.Operation != admissionv1.Update || req.Kind.Kind != v1.ConfigurationKind {
+		return admission.Allowed("")
+	}
+
+	c := &v1.Configuration{}
+	if err := json.Unmarshal(req.Object.Raw, c); err != nil {
+		return admission.Errored(http.StatusBadRequest, errors.Wrap(err, errDecodePackage))
+	}
+	old := &v1.Configuration{}
+	if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+		return admission.Errored(http.StatusBadRequest, errors.Wrap(err, errDecodePackage))
+	}
+	if c.GetSource() == old.GetSource() {
+		return admission.Allowed("")
+	}
+	if c.GetSkipDependencyResolution() != nil && *c.GetSkipDependencyResolution() {
+		return admission.Allowed("")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dependencyCheckTimeout)
+	defer cancel()
+
+	cfg, err := v.fetch(ctx, c)
+	if err != nil {
+		return admission.Allowed("").WithWarnings(fmt.Sprintf(errFmtCheckDependencies, c.GetSource(), err))
+	}
+
+	lock := &v1beta1.Lock{}
+	if err := v.client.Get(ctx, types.NamespacedName{Name: lockName}, lock); resource.IgnoreNotFound(err) != nil {
+		return admission.Allowed("").WithWarnings(fmt.Sprintf(errFmtCheckDependencies, c.GetSource(), errors.Wrap(err, errGetLock)))
+	}
+
+	missing, conflicts := checkDependencies(c, cfg, lock.Packages)
+	if len(conflicts) > 0 {
+		return admission.Denied(fmt.Sprintf(errFmtConflictingDependencies, c.GetSource(), strings.Join(conflicts, "; ")))
+	}
+	if len(missing) > 0 {
+		return admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtMissingDependencies, c.GetSource(), strings.Join(missing, ", ")))
+	}
+	return admission.Allowed("")
+}
+
+// fetch the package the supplied Configuration is being updated to.
+func (v *DependencyValidator) fetch(ctx context.Context, c *v1.Configuration) (pkgmetav1.Pkg, error) {
+	// The backend fetches the package of a revision, so we describe the
+	// revision that would be created for the updated Configuration.
+	pr := &v1.ConfigurationRevision{Spec: v1.PackageRevisionSpec{
+		Package:            c.GetSource(),
+		PackagePullSecrets: c.GetPackagePullSecrets(),
+	}}
+	rc, err := v.backend.Init(ctx, revision.PackageRevision(pr))
+	if err != nil {
+		return nil, errors.Wrap(err, errInitBackend)
+	}
+	pkg, err := v.parser.Parse(ctx, rc)
+	if err != nil {
+		return nil, errors.Wrap(err, errParsePackage)
+	}
+	if len(pkg.GetMeta()) != 1 {
+		return nil, errors.New(errNotOneConfiguration)
+	}
+	cfg, ok := xpkg.TryConvertToPkg(pkg.GetMeta()[0], &pkgmetav1.Configuration{})
+	if !ok {
+		return nil, errors.New(errNotOneConfiguration)
+	}
+	return cfg, nil
+}
+
+// checkDependencies checks the dependencies of the supplied Configuration
+// package against the supplied installed packages. It returns the required
+// dependencies that aren't installed, and descriptions of any conflicts, i.e.
+// dependencies that are installed at a version that doesn't satisfy the
+// package's constraints, or installed packages whose constraints the package
+// wouldn't satisfy.
+func checkDependencies(c *v1.Configuration, cfg pkgmetav1.Pkg, installed []v1beta1.LockPackage) (missing, conflicts []string) { //nolint:gocyclo // Only slightly over.
+	ref, err := xpkg.ParseSource(c.GetSource(), "")
+	if err != nil {
+		// The source validator rejects packages with invalid sources.
+		return nil, nil
+	}
+	self := v1beta1.LockPackage{
+		Source: xpkg.ParsePackageSourceFromReference(ref),
+		Type:   v1beta1.ConfigurationPackageType,
+	}
+	for _, dep := range cfg.GetDependencies() {
+		d := v1beta1.Dependency{Constraints: dep.Version, Optional: dep.Optional, Channel: string(c.GetChannel())}
+		switch {
+		case dep.Configuration != nil:
+			d.Package, d.Type = *dep.Configuration, v1beta1.ConfigurationPackageType
+		case dep.Provider != nil:
+			d.Package, d.Type = *dep.Provider, v1beta1.ProviderPackageType
+		default:
+			// Dependencies on an API group are resolved against installed
+			// package revisions once the Configuration is installed.
+			continue
+		}
+		self.Dependencies = append(self.Dependencies, d)
+	}
+
+	bySource := make(map[string]v1beta1.LockPackage, len(installed))
+	for _, p := range installed {
+		bySource[p.Source] = p
+	}
+
+	// Check that our dependencies are installed at versions we accept.
+	for _, d := range self.Expand(installed...).Dependencies {
+		p, ok := bySource[d.Package]
+		if !ok {
+			if !d.Optional {
+				missing = append(missing, d.Package)
+			}
+			continue
+		}
+		if msg := unsatisfied(p.Source, p.Version, self.Source, d.Constraints, c.GetChannel()); msg != "" {
+			conflicts = append(conflicts, msg)
+		}
+	}
+
+	// Check that we would satisfy the installed packages that depend on us.
+	for _, p := range v1beta1.ExpandWildcards(installed...) {
+		if p.Source == self.Source {
+			continue
+		}
+		for _, d := range p.Dependencies {
+			if d.Package != self.Source {
+				continue
+			}
+			if msg := unsatisfied(self.Source, ref.Identifier(), p.Source, d.Constraints, v1.PackageChannel(d.Channel)); msg != "" {
+				conflicts = append(conflicts, msg)
+			}
+		}
+	}
+	return missing, conflicts
+}
+
+// unsatisfied returns a description of why the supplied version of the
+// dependency doesn't satisfy the dependent package's constraints, or an empty
+// string if it does. Versions that aren't semantic versions, e.g. digests,
+// can't be checked and are assumed to satisfy any constraints.
+func unsatisfied(dependency, ver, dependent, constraints string, ch v1.PackageChannel) string {
+	sv, err := semver.NewVersion(ver)
+	if err != nil {
+		return ""
+	}
+	cs, err := version.ParseConstraints(constraints)
+	if err != nil {
+		return fmt.Sprintf(msgFmtInvalidConstraints, dependent, constraints)
+	}
+	if xpkg.Satisfies(cs, ch, sv) {
+		return ""
+	}
+	return fmt.Sprintf(msgFmtUnsatisfied, dependency, ver, dependent, constraints)
+}
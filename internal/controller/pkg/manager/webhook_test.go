@@ -0,0 +1,349 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	admissionv1 "k8s.io/api/admission/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+func TestSourceValidatorHandle(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	pkg := func(source string) runtime.RawExtension {
+		return runtime.RawExtension{Raw: []byte(fmt.Sprintf(`{"spec":{"package":%q}}`, source))}
+	}
+
+	// Only permit packages from the crossplane organization in the default
+	// registry.
+	policy := xpkg.SourcePolicyFn(func(_ context.Context, repo name.Repository) error {
+		if repo.RegistryStr() != "xpkg.example.org" || repo.RepositoryStr() != "crossplane/provider-nop" {
+			return errBoom
+		}
+		return nil
+	})
+
+	cases := map[string]struct {
+		reason string
+		req    admissionv1.AdmissionRequest
+		want   admission.Response
+	}{
+		"DecodeError": {
+			reason: "We should return an error if we can't decode the package.",
+			req: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object:    runtime.RawExtension{Raw: []byte("{")},
+			},
+			want: admission.Errored(http.StatusBadRequest, errors.Wrap(errors.New("unexpected end of JSON input"), errDecodePackage)),
+		},
+		"Permitted": {
+			reason: "We should allow a package whose source is permitted by the policy.",
+			req: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object:    pkg("crossplane/provider-nop:v0.1.0"),
+			},
+			want: admission.Allowed(""),
+		},
+		"NotPermitted": {
+			reason: "We should deny a package whose source is not permitted by the policy.",
+			req: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Object:    pkg("index.docker.io/crossplane/provider-nop:v0.1.0"),
+			},
+			want: admission.Denied(errBoom.Error()),
+		},
+		"UpdateSourceUnchanged": {
+			reason: "We should allow an update that doesn't change a package's source, even if it's not permitted.",
+			req: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Update,
+				Object:    pkg("index.docker.io/crossplane/provider-nop:v0.1.0"),
+				OldObject: pkg("index.docker.io/crossplane/provider-nop:v0.1.0"),
+			},
+			want: admission.Allowed(""),
+		},
+		"UpdateSourceChanged": {
+			reason: "We should deny an update that changes a package's source to one that is not permitted.",
+			req: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Update,
+				Object:    pkg("index.docker.io/crossplane/provider-nop:v0.2.0"),
+				OldObject: pkg("index.docker.io/crossplane/provider-nop:v0.1.0"),
+			},
+			want: admission.Denied(errBoom.Error()),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := NewSourceValidator(policy, "xpkg.example.org")
+			got := v.Handle(context.Background(), admission.Request{AdmissionRequest: tc.req})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nHandle(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+var _ parser.Backend = &ErrBackend{}
+
+type ErrBackend struct{ err error }
+
+func (e *ErrBackend) Init(_ context.Context, _ ...parser.BackendOption) (io.ReadCloser, error) {
+	return nil, e.err
+}
+
+func TestDependencyValidatorHandle(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cfg := func(source string, skip bool) runtime.RawExtension {
+		return runtime.RawExtension{Raw: []byte(fmt.Sprintf(`{"spec":{"package":%q,"skipDependencyResolution":%t}}`, source, skip))}
+	}
+	kind := metav1.GroupVersionKind{Group: v1.Group, Version: v1.Version, Kind: v1.ConfigurationKind}
+
+	// The package we update to depends on provider-aws v1.0.0 or later.
+	meta := strings.Join([]string{
+		"apiVersion: meta.pkg.crossplane.io/v1",
+		"kind: Configuration",
+		"metadata:",
+		"  name: platform",
+		"spec:",
+		"  dependsOn:",
+		"  - provider: xpkg.example.org/acme/provider-aws",
+		"    version: \">=v1.0.0\"",
+	}, "\n")
+
+	lock := func(pkgs ...v1beta1.LockPackage) *v1beta1.Lock {
+		return &v1beta1.Lock{Packages: pkgs}
+	}
+	self := v1beta1.LockPackage{
+		Source:  "xpkg.example.org/acme/platform",
+		Version: "v1.0.0",
+		Dependencies: []v1beta1.Dependency{{
+			Package:     "xpkg.example.org/acme/provider-aws",
+			Type:        v1beta1.ProviderPackageType,
+			Constraints: ">=v0.5.0",
+		}},
+	}
+
+	type args struct {
+		client  client.Reader
+		backend parser.Backend
+		req     admissionv1.AdmissionRequest
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   admission.Response
+	}{
+		"NotConfigurationUpdate": {
+			reason: "We should allow any request that doesn't update a Configuration.",
+			args: args{
+				req: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Kind:      kind,
+					Object:    cfg("xpkg.example.org/acme/platform:v2.0.0", false),
+				},
+			},
+			want: admission.Allowed(""),
+		},
+		"DecodeError": {
+			reason: "We should return an error if we can't decode the package.",
+			args: args{
+				req: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Kind:      kind,
+					Object:    runtime.RawExtension{Raw: []byte("{")},
+				},
+			},
+			want: admission.Errored(http.StatusBadRequest, errors.Wrap(errors.New("unexpected end of JSON input"), errDecodePackage)),
+		},
+		"SourceUnchanged": {
+			reason: "We should allow an update that doesn't change a Configuration's package.",
+			args: args{
+				req: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Kind:      kind,
+					Object:    cfg("xpkg.example.org/acme/platform:v1.0.0", false),
+					OldObject: cfg("xpkg.example.org/acme/platform:v1.0.0", false),
+				},
+			},
+			want: admission.Allowed(""),
+		},
+		"SkipDependencyResolution": {
+			reason: "We should allow an update to a Configuration that skips dependency resolution.",
+			args: args{
+				req: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Kind:      kind,
+					Object:    cfg("xpkg.example.org/acme/platform:v2.0.0", true),
+					OldObject: cfg("xpkg.example.org/acme/platform:v1.0.0", true),
+				},
+			},
+			want: admission.Allowed(""),
+		},
+		"ErrFetchPackage": {
+			reason: "We should allow an update with a warning if we can't fetch the package it updates to.",
+			args: args{
+				backend: &ErrBackend{err: errBoom},
+				req: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Kind:      kind,
+					Object:    cfg("xpkg.example.org/acme/platform:v2.0.0", false),
+					OldObject: cfg("xpkg.example.org/acme/platform:v1.0.0", false),
+				},
+			},
+			want: admission.Allowed("").WithWarnings(fmt.Sprintf(errFmtCheckDependencies, "xpkg.example.org/acme/platform:v2.0.0", errors.Wrap(errBoom, errInitBackend))),
+		},
+		"ErrGetLock": {
+			reason: "We should allow an update with a warning if we can't get the lock.",
+			args: args{
+				client:  &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				backend: parser.NewEchoBackend(meta),
+				req: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Kind:      kind,
+					Object:    cfg("xpkg.example.org/acme/platform:v2.0.0", false),
+					OldObject: cfg("xpkg.example.org/acme/platform:v1.0.0", false),
+				},
+			},
+			want: admission.Allowed("").WithWarnings(fmt.Sprintf(errFmtCheckDependencies, "xpkg.example.org/acme/platform:v2.0.0", errors.Wrap(errBoom, errGetLock))),
+		},
+		"MissingDependencies": {
+			reason: "We should allow an update with a warning if it would introduce dependencies that aren't installed.",
+			args: args{
+				client:  &test.MockClient{MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, lockName))},
+				backend: parser.NewEchoBackend(meta),
+				req: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Kind:      kind,
+					Object:    cfg("xpkg.example.org/acme/platform:v2.0.0", false),
+					OldObject: cfg("xpkg.example.org/acme/platform:v1.0.0", false),
+				},
+			},
+			want: admission.Allowed("").WithWarnings(fmt.Sprintf(msgFmtMissingDependencies, "xpkg.example.org/acme/platform:v2.0.0", "xpkg.example.org/acme/provider-aws")),
+		},
+		"ConflictingDependency": {
+			reason: "We should deny an update if it would depend on an installed package at a version it doesn't accept.",
+			args: args{
+				client: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+					*o.(*v1beta1.Lock) = *lock(*self.DeepCopy(), v1beta1.LockPackage{
+						Source:  "xpkg.example.org/acme/provider-aws",
+						Type:    v1beta1.ProviderPackageType,
+						Version: "v0.9.0",
+					})
+					return nil
+				})},
+				backend: parser.NewEchoBackend(meta),
+				req: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Kind:      kind,
+					Object:    cfg("xpkg.example.org/acme/platform:v2.0.0", false),
+					OldObject: cfg("xpkg.example.org/acme/platform:v1.0.0", false),
+				},
+			},
+			want: admission.Denied(fmt.Sprintf(errFmtConflictingDependencies, "xpkg.example.org/acme/platform:v2.0.0",
+				fmt.Sprintf(msgFmtUnsatisfied, "xpkg.example.org/acme/provider-aws", "v0.9.0", "xpkg.example.org/acme/platform", ">=v1.0.0"))),
+		},
+		"ConflictingDependent": {
+			reason: "We should deny an update if an installed package depends on the Configuration at a version the update doesn't satisfy.",
+			args: args{
+				client: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+					*o.(*v1beta1.Lock) = *lock(*self.DeepCopy(), v1beta1.LockPackage{
+						Source:  "xpkg.example.org/acme/provider-aws",
+						Type:    v1beta1.ProviderPackageType,
+						Version: "v1.2.0",
+					}, v1beta1.LockPackage{
+						Source:  "xpkg.example.org/acme/app",
+						Type:    v1beta1.ConfigurationPackageType,
+						Version: "v1.0.0",
+						Dependencies: []v1beta1.Dependency{{
+							Package:     "xpkg.example.org/acme/platform",
+							Type:        v1beta1.ConfigurationPackageType,
+							Constraints: ">=v1.0.0 <v2.0.0",
+						}},
+					})
+					return nil
+				})},
+				backend: parser.NewEchoBackend(meta),
+				req: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Kind:      kind,
+					Object:    cfg("xpkg.example.org/acme/platform:v2.0.0", false),
+					OldObject: cfg("xpkg.example.org/acme/platform:v1.0.0", false),
+				},
+			},
+			want: admission.Denied(fmt.Sprintf(errFmtConflictingDependencies, "xpkg.example.org/acme/platform:v2.0.0",
+				fmt.Sprintf(msgFmtUnsatisfied, "xpkg.example.org/acme/platform", "v2.0.0", "xpkg.example.org/acme/app", ">=v1.0.0 <v2.0.0"))),
+		},
+		"DependenciesSatisfied": {
+			reason: "We should allow an update whose dependencies are installed at versions it accepts.",
+			args: args{
+				client: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+					*o.(*v1beta1.Lock) = *lock(*self.DeepCopy(), v1beta1.LockPackage{
+						Source:  "xpkg.example.org/acme/provider-aws",
+						Type:    v1beta1.ProviderPackageType,
+						Version: "v1.2.0",
+					})
+					return nil
+				})},
+				backend: parser.NewEchoBackend(meta),
+				req: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Kind:      kind,
+					Object:    cfg("xpkg.example.org/acme/platform:v2.0.0", false),
+					OldObject: cfg("xpkg.example.org/acme/platform:v1.0.0", false),
+				},
+			},
+			want: admission.Allowed(""),
+		},
+	}
+
+	metaScheme, _ := xpkg.BuildMetaScheme()
+	objScheme, _ := xpkg.BuildObjectScheme()
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := NewDependencyValidator(tc.args.client, tc.args.backend, parser.New(metaScheme, objScheme))
+			got := v.Handle(context.Background(), admission.Request{AdmissionRequest: tc.args.req})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nHandle(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -19,15 +19,23 @@ package pkg
 import (
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/crossplane/crossplane/internal/controller/pkg/catalog"
 	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+	"github.com/crossplane/crossplane/internal/controller/pkg/export"
+	"github.com/crossplane/crossplane/internal/controller/pkg/health"
 	"github.com/crossplane/crossplane/internal/controller/pkg/manager"
+	"github.com/crossplane/crossplane/internal/controller/pkg/propagation"
 	"github.com/crossplane/crossplane/internal/controller/pkg/resolver"
 	"github.com/crossplane/crossplane/internal/controller/pkg/revision"
+	"github.com/crossplane/crossplane/internal/features"
 )
 
 // Setup package controllers.
 func Setup(mgr ctrl.Manager, o controller.Options) error {
 	for _, setup := range []func(ctrl.Manager, controller.Options) error{
+		catalog.Setup,
+		export.Setup,
+		health.Setup,
 		manager.SetupConfiguration,
 		manager.SetupProvider,
 		resolver.Setup,
@@ -38,5 +46,11 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 			return err
 		}
 	}
+
+	// Propagating packages requires access to other clusters, so we don't
+	// start the controller unless the feature flag is enabled.
+	if o.Features.Enabled(features.EnableAlphaPackagePropagation) {
+		return propagation.Setup(mgr, o)
+	}
 	return nil
 }
@@ -0,0 +1,416 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package propagation implements the controller that propagates packages to
+// spoke clusters.
+package propagation
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+)
+
+const (
+	reconcileTimeout = 3 * time.Minute
+
+	// defaultRefreshInterval is how often the health of propagated packages
+	// is read if a propagation doesn't specify an interval.
+	defaultRefreshInterval = 1 * time.Minute
+)
+
+const (
+	errGetPropagation   = "cannot get package propagation"
+	errUpdateStatus     = "cannot update package propagation status"
+	errBadSelector      = "cannot parse package selector"
+	errListPackages     = "cannot list packages"
+	errGetKubeconfig    = "cannot get kubeconfig secret"
+	errFmtNoKubeconfig  = "kubeconfig secret has no key %q"
+	errParseKubeconfig  = "cannot parse kubeconfig"
+	errConnect          = "cannot connect to cluster"
+	errApplyPackage     = "cannot apply package"
+	errListPropagated   = "cannot list propagated packages"
+	errFmtDelete        = "cannot delete %s %q, which is no longer propagated"
+	errFmtNotPropagated = "refusing to adopt existing %s %q, which was not propagated by package propagation %q"
+	errFmtUnreachable   = "cannot propagate packages to some clusters: %s"
+	errFmtUnhealthy     = "packages are not healthy in some clusters: %s"
+)
+
+// A ClusterConnector connects to spoke clusters.
+type ClusterConnector interface {
+	// Connect returns a client for the supplied spoke cluster, which is
+	// described by the supplied kubeconfig.
+	Connect(ctx context.Context, c v1alpha1.SpokeCluster, kubeconfig []byte) (client.Client, error)
+}
+
+// A ClusterConnectorFn is a function that satisfies ClusterConnector.
+type ClusterConnectorFn func(ctx context.Context, c v1alpha1.SpokeCluster, kubeconfig []byte) (client.Client, error)
+
+// Connect returns a client for the supplied spoke cluster, which is described
+// by the supplied kubeconfig.
+func (fn ClusterConnectorFn) Connect(ctx context.Context, c v1alpha1.SpokeCluster, kubeconfig []byte) (client.Client, error) {
+	return fn(ctx, c, kubeconfig)
+}
+
+type cachedClient struct {
+	kubeconfig []byte
+	client     client.Client
+}
+
+// A KubeconfigConnector connects to the cluster a kubeconfig describes.
+type KubeconfigConnector struct {
+	scheme *runtime.Scheme
+
+	mx      sync.Mutex
+	clients map[xpv1.SecretKeySelector]cachedClient
+}
+
+// NewKubeconfigConnector returns a ClusterConnector whose clients use the
+// supplied scheme.
+func NewKubeconfigConnector(s *runtime.Scheme) *KubeconfigConnector {
+	return &KubeconfigConnector{scheme: s, clients: map[xpv1.SecretKeySelector]cachedClient{}}
+}
+
+// Connect returns a client for the supplied spoke cluster, which is described
+// by the supplied kubeconfig. Clients are cached per kubeconfig secret, and
+// replaced when the kubeconfig changes.
+func (c *KubeconfigConnector) Connect(_ context.Context, sc v1alpha1.SpokeCluster, kubeconfig []byte) (client.Client, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if cc, ok := c.clients[sc.KubeconfigSecretRef]; ok && bytes.Equal(cc.kubeconfig, kubeconfig) {
+		return cc.client, nil
+	}
+
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, errors.Wrap(err, errParseKubeconfig)
+	}
+	kc, err := client.New(cfg, client.Options{Scheme: c.scheme})
+	if err != nil {
+		return nil, err
+	}
+	c.clients[sc.KubeconfigSecretRef] = cachedClient{kubeconfig: kubeconfig, client: kc}
+	return kc, nil
+}
+
+// ReconcilerOption is used to configure the Reconciler.
+type ReconcilerOption func(*Reconciler)
+
+// WithLogger specifies how the Reconciler should log messages.
+func WithLogger(log logging.Logger) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.log = log
+	}
+}
+
+// WithClusterConnector specifies how the Reconciler should connect to spoke
+// clusters.
+func WithClusterConnector(c ClusterConnector) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.connector = c
+	}
+}
+
+// Reconciler reconciles package propagations.
+type Reconciler struct {
+	client    client.Client
+	log       logging.Logger
+	connector ClusterConnector
+}
+
+// Setup adds a controller that reconciles PackagePropagations.
+func Setup(mgr ctrl.Manager, o controller.Options) error {
+	name := "packages/" + strings.ToLower(v1alpha1.PackagePropagationGroupKind)
+
+	r := NewReconciler(mgr,
+		WithLogger(o.Logger.WithValues("controller", name)),
+		WithClusterConnector(NewKubeconfigConnector(mgr.GetScheme())),
+	)
+
+	// We propagate packages whenever a package that may be selected changes,
+	// and periodically so that we can read their health from the spoke
+	// clusters. We needn't be told about updates to our own status.
+	h := handler.EnqueueRequestsFromMapFunc(r.propagations)
+	changed := builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}))
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		For(&v1alpha1.PackagePropagation{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &v1.Provider{}}, h, changed).
+		Watches(&source.Kind{Type: &v1.Configuration{}}, h, changed).
+		WithOptions(o.ForControllerRuntime()).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// NewReconciler creates a new package propagation reconciler.
+func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
+	r := &Reconciler{
+		client:    mgr.GetClient(),
+		log:       logging.NewNopLogger(),
+		connector: NewKubeconfigConnector(mgr.GetScheme()),
+	}
+
+	for _, f := range opts {
+		f(r)
+	}
+
+	return r
+}
+
+// Reconcile a package propagation by propagating the packages it selects to
+// its spoke clusters, and reading their health.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+	log.Debug("Reconciling")
+
+	ctx, cancel := context.WithTimeout(ctx, reconcileTimeout)
+	defer cancel()
+
+	pp := &v1alpha1.PackagePropagation{}
+	if err := r.client.Get(ctx, req.NamespacedName, pp); err != nil {
+		// There's no need to requeue if we no longer exist. Otherwise
+		// we'll be requeued implicitly because we return an error.
+		log.Debug(errGetPropagation, "error", err)
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetPropagation)
+	}
+
+	// Packages we propagated are left in the spoke clusters when we're
+	// deleted.
+	if meta.WasDeleted(pp) {
+		return reconcile.Result{Requeue: false}, nil
+	}
+
+	interval := defaultRefreshInterval
+	if pp.Spec.RefreshInterval != nil {
+		interval = pp.Spec.RefreshInterval.Duration
+	}
+
+	pkgs, err := r.selected(ctx, pp.Spec.PackageSelector)
+	if err != nil {
+		log.Debug(errListPackages, "error", err)
+		pp.SetConditions(xpv1.ReconcileError(err))
+		return reconcile.Result{RequeueAfter: interval}, errors.Wrap(r.client.Status().Update(ctx, pp), errUpdateStatus)
+	}
+
+	clusters := make([]v1alpha1.SpokeClusterStatus, len(pp.Spec.Clusters))
+	unreachable, unhealthy := []string{}, []string{}
+	for i, c := range pp.Spec.Clusters {
+		clusters[i] = r.propagate(ctx, c, pp.GetName(), pkgs)
+		if clusters[i].Message != "" {
+			log.Debug("Cannot propagate packages", "cluster", c.Name, "error", clusters[i].Message)
+			unreachable = append(unreachable, c.Name)
+			unhealthy = append(unhealthy, c.Name)
+			continue
+		}
+		for _, p := range clusters[i].Packages {
+			if !p.Healthy {
+				unhealthy = append(unhealthy, c.Name)
+				break
+			}
+		}
+	}
+
+	pp.Status.Clusters = clusters
+	pp.SetConditions(xpv1.ReconcileSuccess(), xpv1.Available())
+	if len(unreachable) > 0 {
+		pp.SetConditions(xpv1.ReconcileError(errors.Errorf(errFmtUnreachable, strings.Join(unreachable, ", "))))
+	}
+	if len(unhealthy) > 0 {
+		pp.SetConditions(xpv1.Unavailable().WithMessage(errors.Errorf(errFmtUnhealthy, strings.Join(unhealthy, ", ")).Error()))
+	}
+
+	return reconcile.Result{RequeueAfter: interval}, errors.Wrap(r.client.Status().Update(ctx, pp), errUpdateStatus)
+}
+
+// selected returns the Providers and Configurations that match the supplied
+// selector.
+func (r *Reconciler) selected(ctx context.Context, sel metav1.LabelSelector) ([]v1.Package, error) {
+	s, err := metav1.LabelSelectorAsSelector(&sel)
+	if err != nil {
+		return nil, errors.Wrap(err, errBadSelector)
+	}
+	pl := &v1.ProviderList{}
+	if err := r.client.List(ctx, pl, client.MatchingLabelsSelector{Selector: s}); err != nil {
+		return nil, errors.Wrap(err, errListPackages)
+	}
+	cl := &v1.ConfigurationList{}
+	if err := r.client.List(ctx, cl, client.MatchingLabelsSelector{Selector: s}); err != nil {
+		return nil, errors.Wrap(err, errListPackages)
+	}
+	pkgs := make([]v1.Package, 0, len(pl.Items)+len(cl.Items))
+	for i := range pl.Items {
+		pkgs = append(pkgs, &pl.Items[i])
+	}
+	for i := range cl.Items {
+		pkgs = append(pkgs, &cl.Items[i])
+	}
+	return pkgs, nil
+}
+
+// propagate the supplied packages to the supplied cluster, and remove any
+// packages the named propagation previously propagated that are no longer
+// selected. Any error encountered is recorded in the cluster's message, or in
+// the message of the package that could not be propagated.
+func (r *Reconciler) propagate(ctx context.Context, c v1alpha1.SpokeCluster, propagation string, pkgs []v1.Package) v1alpha1.SpokeClusterStatus {
+	s := v1alpha1.SpokeClusterStatus{Name: c.Name}
+
+	ref := c.KubeconfigSecretRef
+	sec := &corev1.Secret{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, sec); err != nil {
+		s.Message = errors.Wrap(err, errGetKubeconfig).Error()
+		return s
+	}
+	kc, ok := sec.Data[ref.Key]
+	if !ok {
+		s.Message = errors.Errorf(errFmtNoKubeconfig, ref.Key).Error()
+		return s
+	}
+	spoke, err := r.connector.Connect(ctx, c, kc)
+	if err != nil {
+		s.Message = errors.Wrap(err, errConnect).Error()
+		return s
+	}
+
+	ap := resource.NewAPIPatchingApplicator(spoke)
+	keep := map[string]bool{}
+	s.Packages = make([]v1alpha1.PropagatedPackage, len(pkgs))
+	for i, pkg := range pkgs {
+		kind, d := propagated(pkg, propagation)
+		keep[kind+"/"+pkg.GetName()] = true
+		p := v1alpha1.PropagatedPackage{Kind: kind, Name: pkg.GetName(), Package: pkg.GetSource()}
+
+		// Applying the package updates it with its observed state in the
+		// spoke cluster, including its health. We don't adopt packages we
+		// didn't propagate; they may be managed by someone else.
+		if err := ap.Apply(ctx, d, mustBePropagatedBy(kind, propagation)); err != nil {
+			p.Message = errors.Wrap(err, errApplyPackage).Error()
+			s.Packages[i] = p
+			continue
+		}
+		h := d.GetCondition(v1.TypeHealthy)
+		p.Healthy = h.Status == corev1.ConditionTrue
+		if !p.Healthy {
+			p.Message = h.Message
+		}
+		s.Packages[i] = p
+	}
+
+	if err := prune(ctx, spoke, propagation, keep); err != nil {
+		s.Message = err.Error()
+	}
+	return s
+}
+
+// propagated returns the kind of the supplied Provider or Configuration, and
+// the package that should be applied to a spoke cluster to propagate it.
+func propagated(pkg v1.Package, propagation string) (string, v1.Package) {
+	labels := map[string]string{}
+	for k, v := range pkg.GetLabels() {
+		labels[k] = v
+	}
+	labels[v1alpha1.LabelPropagation] = propagation
+	om := metav1.ObjectMeta{Name: pkg.GetName(), Labels: labels}
+
+	if c, ok := pkg.(*v1.Configuration); ok {
+		return v1.ConfigurationKind, &v1.Configuration{ObjectMeta: om, Spec: *c.Spec.DeepCopy()}
+	}
+	p := pkg.(*v1.Provider)
+	return v1.ProviderKind, &v1.Provider{ObjectMeta: om, Spec: *p.Spec.DeepCopy()}
+}
+
+// mustBePropagatedBy returns an ApplyOption that refuses to update an existing
+// package that was not propagated by the named propagation.
+func mustBePropagatedBy(kind, propagation string) resource.ApplyOption {
+	return func(_ context.Context, current, _ runtime.Object) error {
+		m := current.(metav1.Object)
+		if m.GetLabels()[v1alpha1.LabelPropagation] != propagation {
+			return errors.Errorf(errFmtNotPropagated, kind, m.GetName(), propagation)
+		}
+		return nil
+	}
+}
+
+// prune deletes the packages the named propagation propagated to the supplied
+// cluster, unless they should be kept.
+func prune(ctx context.Context, spoke client.Client, propagation string, keep map[string]bool) error {
+	del := func(kind string, o client.Object) error {
+		if keep[kind+"/"+o.GetName()] {
+			return nil
+		}
+		return errors.Wrapf(resource.IgnoreNotFound(spoke.Delete(ctx, o)), errFmtDelete, kind, o.GetName())
+	}
+
+	pl := &v1.ProviderList{}
+	if err := spoke.List(ctx, pl, client.MatchingLabels{v1alpha1.LabelPropagation: propagation}); err != nil {
+		return errors.Wrap(err, errListPropagated)
+	}
+	for i := range pl.Items {
+		if err := del(v1.ProviderKind, &pl.Items[i]); err != nil {
+			return err
+		}
+	}
+	cl := &v1.ConfigurationList{}
+	if err := spoke.List(ctx, cl, client.MatchingLabels{v1alpha1.LabelPropagation: propagation}); err != nil {
+		return errors.Wrap(err, errListPropagated)
+	}
+	for i := range cl.Items {
+		if err := del(v1.ConfigurationKind, &cl.Items[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// propagations returns a request for every package propagation.
+func (r *Reconciler) propagations(_ client.Object) []reconcile.Request {
+	l := &v1alpha1.PackagePropagationList{}
+	if err := r.client.List(context.TODO(), l); err != nil {
+		r.log.Debug("Cannot list package propagations", "error", err)
+		return nil
+	}
+	reqs := make([]reconcile.Request, len(l.Items))
+	for i := range l.Items {
+		reqs[i] = reconcile.Request{NamespacedName: types.NamespacedName{Name: l.Items[i].GetName()}}
+	}
+	return reqs
+}
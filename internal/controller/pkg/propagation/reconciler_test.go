@@ -0,0 +1,298 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package propagation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+)
+
+var _ ClusterConnector = &KubeconfigConnector{}
+
+func TestReconcile(t *testing.T) {
+	errBoom := errors.New("boom")
+	pkg := "xpkg.example.org/crossplane/provider-test:v0.1.0"
+
+	// hub returns a client for a hub cluster with a propagation to a spoke
+	// cluster named "spoke", and a provider it selects.
+	hub := func(getSecret error, s v1alpha1.PackagePropagationStatus) *test.MockClient {
+		return &test.MockClient{
+			MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+				switch o := o.(type) {
+				case *v1alpha1.PackagePropagation:
+					o.SetName("fleet")
+					o.Spec.Clusters = []v1alpha1.SpokeCluster{{
+						Name:                "spoke",
+						KubeconfigSecretRef: xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: "crossplane-system", Name: "spoke"}, Key: "kubeconfig"},
+					}}
+				case *corev1.Secret:
+					if getSecret != nil {
+						return getSecret
+					}
+					o.Data = map[string][]byte{"kubeconfig": []byte("cool")}
+				}
+				return nil
+			}),
+			MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+				if l, ok := o.(*v1.ProviderList); ok {
+					l.Items = []v1.Provider{{
+						ObjectMeta: metav1.ObjectMeta{Name: "provider-test"},
+						Spec:       v1.ProviderSpec{PackageSpec: v1.PackageSpec{Package: pkg}},
+					}}
+				}
+				return nil
+			}),
+			MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+				got := o.(*v1alpha1.PackagePropagation).Status
+				if diff := cmp.Diff(s, got, cmpopts.IgnoreTypes(metav1.Time{})); diff != "" {
+					t.Errorf("Status().Update(...): -want, +got:\n%s", diff)
+				}
+				return nil
+			}),
+		}
+	}
+
+	// spoke returns a connector to a spoke cluster in which propagated
+	// packages have the supplied health. The spoke cluster has a stale
+	// provider that should be deleted.
+	spoke := func(healthy bool) ClusterConnector {
+		return ClusterConnectorFn(func(_ context.Context, _ v1alpha1.SpokeCluster, kubeconfig []byte) (client.Client, error) {
+			if string(kubeconfig) != "cool" {
+				t.Errorf("Connect(...): want kubeconfig %q, got %q", "cool", kubeconfig)
+			}
+			return &test.MockClient{
+				MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+				MockCreate: test.NewMockCreateFn(nil, func(o client.Object) error {
+					p := o.(*v1.Provider)
+					if diff := cmp.Diff(map[string]string{v1alpha1.LabelPropagation: "fleet"}, p.GetLabels()); diff != "" {
+						t.Errorf("Create(...): -want labels, +got labels:\n%s", diff)
+					}
+					if healthy {
+						p.SetConditions(v1.Healthy())
+						return nil
+					}
+					p.SetConditions(v1.Unhealthy().WithMessage("boom"))
+					return nil
+				}),
+				MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+					if l, ok := o.(*v1.ProviderList); ok {
+						l.Items = []v1.Provider{{ObjectMeta: metav1.ObjectMeta{Name: "provider-stale"}}}
+					}
+					return nil
+				}),
+				MockDelete: test.NewMockDeleteFn(nil, func(o client.Object) error {
+					if o.GetName() != "provider-stale" {
+						t.Errorf("Delete(...): deleted %q, which is still propagated", o.GetName())
+					}
+					return nil
+				}),
+			}, nil
+		})
+	}
+
+	// unpropagated returns a connector to a spoke cluster in which the
+	// selected provider already exists, but was not propagated.
+	unpropagated := ClusterConnectorFn(func(_ context.Context, _ v1alpha1.SpokeCluster, _ []byte) (client.Client, error) {
+		return &test.MockClient{
+			MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+				o.SetName("provider-test")
+				o.SetLabels(map[string]string{"owner": "someone-else"})
+				return nil
+			}),
+			MockList: test.NewMockListFn(nil),
+		}, nil
+	})
+
+	type args struct {
+		mgr manager.Manager
+		rec []ReconcilerOption
+	}
+	type want struct {
+		r   reconcile.Result
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"PropagationNotFound": {
+			reason: "We should not return an error if the propagation was not found.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+					},
+				},
+			},
+			want: want{
+				r: reconcile.Result{},
+			},
+		},
+		"ErrGetPropagation": {
+			reason: "We should return an error if getting the propagation fails.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet: test.NewMockGetFn(errBoom),
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errGetPropagation),
+			},
+		},
+		"ErrListPackages": {
+			reason: "We should report that the propagation failed to sync if we can't list the packages it selects.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: &test.MockClient{
+						MockGet:  test.NewMockGetFn(nil),
+						MockList: test.NewMockListFn(errBoom),
+						MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+							want := v1alpha1.PackagePropagationStatus{}
+							want.SetConditions(xpv1.ReconcileError(errors.Wrap(errBoom, errListPackages)))
+							if diff := cmp.Diff(want, o.(*v1alpha1.PackagePropagation).Status, cmpopts.IgnoreTypes(metav1.Time{})); diff != "" {
+								t.Errorf("Status().Update(...): -want, +got:\n%s", diff)
+							}
+							return nil
+						}),
+					},
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: defaultRefreshInterval},
+			},
+		},
+		"ClusterUnreachable": {
+			reason: "We should report clusters we can't propagate packages to.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: hub(errBoom, func() v1alpha1.PackagePropagationStatus {
+						s := v1alpha1.PackagePropagationStatus{Clusters: []v1alpha1.SpokeClusterStatus{{
+							Name:    "spoke",
+							Message: errors.Wrap(errBoom, errGetKubeconfig).Error(),
+						}}}
+						s.SetConditions(
+							xpv1.ReconcileError(errors.Errorf(errFmtUnreachable, "spoke")),
+							xpv1.Unavailable().WithMessage(errors.Errorf(errFmtUnhealthy, "spoke").Error()),
+						)
+						return s
+					}()),
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: defaultRefreshInterval},
+			},
+		},
+		"PropagatedHealthy": {
+			reason: "We should propagate selected packages, delete packages that are no longer selected, and be available if all packages are healthy.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: hub(nil, func() v1alpha1.PackagePropagationStatus {
+						s := v1alpha1.PackagePropagationStatus{Clusters: []v1alpha1.SpokeClusterStatus{{
+							Name:     "spoke",
+							Packages: []v1alpha1.PropagatedPackage{{Kind: v1.ProviderKind, Name: "provider-test", Package: pkg, Healthy: true}},
+						}}}
+						s.SetConditions(xpv1.ReconcileSuccess(), xpv1.Available())
+						return s
+					}()),
+				},
+				rec: []ReconcilerOption{WithClusterConnector(spoke(true))},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: defaultRefreshInterval},
+			},
+		},
+		"PropagatedUnhealthy": {
+			reason: "We should be unavailable if a propagated package is unhealthy.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: hub(nil, func() v1alpha1.PackagePropagationStatus {
+						s := v1alpha1.PackagePropagationStatus{Clusters: []v1alpha1.SpokeClusterStatus{{
+							Name:     "spoke",
+							Packages: []v1alpha1.PropagatedPackage{{Kind: v1.ProviderKind, Name: "provider-test", Package: pkg, Message: "boom"}},
+						}}}
+						s.SetConditions(xpv1.ReconcileSuccess(), xpv1.Unavailable().WithMessage(errors.Errorf(errFmtUnhealthy, "spoke").Error()))
+						return s
+					}()),
+				},
+				rec: []ReconcilerOption{WithClusterConnector(spoke(false))},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: defaultRefreshInterval},
+			},
+		},
+		"RefuseToAdopt": {
+			reason: "We should not adopt a package that exists in the spoke cluster but was not propagated.",
+			args: args{
+				mgr: &fake.Manager{
+					Client: hub(nil, func() v1alpha1.PackagePropagationStatus {
+						s := v1alpha1.PackagePropagationStatus{Clusters: []v1alpha1.SpokeClusterStatus{{
+							Name: "spoke",
+							Packages: []v1alpha1.PropagatedPackage{{
+								Kind:    v1.ProviderKind,
+								Name:    "provider-test",
+								Package: pkg,
+								Message: errors.Wrap(errors.Errorf(errFmtNotPropagated, v1.ProviderKind, "provider-test", "fleet"), errApplyPackage).Error(),
+							}},
+						}}}
+						s.SetConditions(xpv1.ReconcileSuccess(), xpv1.Unavailable().WithMessage(errors.Errorf(errFmtUnhealthy, "spoke").Error()))
+						return s
+					}()),
+				},
+				rec: []ReconcilerOption{WithClusterConnector(unpropagated)},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: defaultRefreshInterval},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewReconciler(tc.args.mgr, tc.args.rec...)
+			got, err := r.Reconcile(context.Background(), reconcile.Request{})
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.r, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -19,11 +19,9 @@ package resolver
 import (
 	"context"
 	"fmt"
-	"sort"
 	"strings"
 	"time"
 
-	"github.com/Masterminds/semver"
 	"github.com/google/go-containerregistry/pkg/name"
 	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -40,6 +38,7 @@ import (
 	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
 	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
 	"github.com/crossplane/crossplane/internal/dag"
+	"github.com/crossplane/crossplane/internal/version"
 	"github.com/crossplane/crossplane/internal/xpkg"
 )
 
@@ -65,6 +64,8 @@ const (
 	errNoValidVersionFmt    = "dependency (%s) does not have version in constraints (%s)"
 	errInvalidPackageType   = "cannot create invalid package dependency type"
 	errCreateDependency     = "cannot create dependency package"
+	errWildcardDependency   = "cannot install wildcard dependency that matches no package"
+	errSourceNotPermitted   = "dependency package source is not permitted"
 )
 
 // ReconcilerOption is used to configure the Reconciler.
@@ -98,6 +99,14 @@ func WithFetcher(f xpkg.Fetcher) ReconcilerOption {
 	}
 }
 
+// WithSourcePolicy specifies which sources the Reconciler may install
+// dependency packages from.
+func WithSourcePolicy(p xpkg.SourcePolicy) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.policy = p
+	}
+}
+
 // Reconciler reconciles packages.
 type Reconciler struct {
 	client  client.Client
@@ -105,6 +114,7 @@ type Reconciler struct {
 	lock    resource.Finalizer
 	newDag  dag.NewDAGFn
 	fetcher xpkg.Fetcher
+	policy  xpkg.SourcePolicy
 }
 
 // Setup adds a controller that reconciles the Lock.
@@ -123,6 +133,7 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 	r := NewReconciler(mgr,
 		WithLogger(o.Logger.WithValues("controller", name)),
 		WithFetcher(f),
+		WithSourcePolicy(xpkg.NewAPISourcePolicy(mgr.GetClient())),
 	)
 
 	return ctrl.NewControllerManagedBy(mgr).
@@ -142,6 +153,7 @@ func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
 		log:     logging.NewNopLogger(),
 		newDag:  dag.NewMapDag,
 		fetcher: xpkg.NewNopFetcher(),
+		policy:  xpkg.NewNopSourcePolicy(),
 	}
 
 	for _, f := range opts {
@@ -191,7 +203,8 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	)
 
 	dag := r.newDag()
-	implied, err := dag.Init(v1beta1.ToNodes(lock.Packages...))
+	pkgs := v1beta1.ExpandWildcards(lock.Packages...)
+	implied, err := dag.Init(v1beta1.ToNodes(pkgs...))
 	if err != nil {
 		log.Debug(errBuildDAG, "error", err)
 		return reconcile.Result{}, errors.Wrap(err, errBuildDAG)
@@ -205,7 +218,33 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, errors.Wrap(err, errSortDAG)
 	}
 
-	if len(implied) == 0 {
+	// Optional dependencies are never installed unless another package
+	// requires them.
+	required := map[string]bool{}
+	for _, lp := range pkgs {
+		for _, d := range lp.Dependencies {
+			if !d.Optional {
+				required[d.Identifier()] = true
+			}
+		}
+	}
+
+	// We can't know which package in a family should satisfy a wildcard
+	// dependency that matches nothing, so we never install one.
+	installable := implied[:0]
+	for _, n := range implied {
+		d, ok := n.(*v1beta1.Dependency)
+		if ok && d.IsWildcard() {
+			log.Debug(errWildcardDependency, "dependency", d.Identifier())
+			continue
+		}
+		if ok && d.Optional && !required[d.Identifier()] {
+			continue
+		}
+		installable = append(installable, n)
+	}
+
+	if len(installable) == 0 {
 		return reconcile.Result{Requeue: false}, nil
 	}
 
@@ -213,12 +252,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	// modifies the Lock. We only create the first implied node as we will
 	// be requeued when it adds itself to the Lock, at which point we will
 	// check for missing nodes again.
-	dep, ok := implied[0].(*v1beta1.Dependency)
+	dep, ok := installable[0].(*v1beta1.Dependency)
 	if !ok {
 		log.Debug(errInvalidDependency, "error", errors.Errorf(errMissingDependencyFmt, dep.Identifier()))
 		return reconcile.Result{Requeue: false}, nil
 	}
-	c, err := semver.NewConstraint(dep.Constraints)
+	c, err := version.ParseConstraints(dep.Constraints)
 	if err != nil {
 		log.Debug(errInvalidConstraint, "error", err)
 		return reconcile.Result{Requeue: false}, nil
@@ -229,6 +268,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{Requeue: false}, nil
 	}
 
+	if err := r.policy.Permit(ctx, ref.Context()); err != nil {
+		log.Debug(errSourceNotPermitted, "error", err)
+		return reconcile.Result{}, errors.Wrap(err, errSourceNotPermitted)
+	}
+
 	// NOTE(hasheddan): we will be unable to fetch tags for private
 	// dependencies because we do not attach any secrets. Consider copying
 	// secrets from parent dependencies.
@@ -238,27 +282,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, errors.Wrap(err, errFetchTags)
 	}
 
-	vs := []*semver.Version{}
-	for _, r := range tags {
-		v, err := semver.NewVersion(r)
-		if err != nil {
-			// We skip any tags that are not valid semantic versions.
-			continue
-		}
-		vs = append(vs, v)
-	}
-
-	sort.Sort(semver.Collection(vs))
-	var addVer string
-	for _, v := range vs {
-		if c.Check(v) {
-			addVer = v.Original()
-		}
-	}
+	v := xpkg.NewestSatisfyingVersion(c, v1.PackageChannel(dep.Channel), tags)
 
 	// NOTE(hasheddan): consider creating event on package revision
 	// dictating constraints.
-	if addVer == "" {
+	if v == nil {
 		log.Debug(errNoValidVersion, errors.Errorf(errNoValidVersionFmt, dep.Identifier(), dep.Constraints))
 		return reconcile.Result{Requeue: false}, nil
 	}
@@ -279,7 +307,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	// no packagePullSecrets are set. Settings can be modified manually
 	// after dependency creation to address this.
 	pack.SetName(xpkg.ToDNSLabel(ref.Context().RepositoryStr()))
-	pack.SetSource(fmt.Sprintf(packageTagFmt, ref.String(), addVer))
+	pack.SetLabels(map[string]string{v1.LabelResolvedDependency: "true"})
+	pack.SetSource(fmt.Sprintf(packageTagFmt, ref.String(), v.Original()))
+	pack.SetChannel(v1.PackageChannel(dep.Channel))
 
 	// NOTE(hasheddan): consider making the lock the controller of packages
 	// it creates.
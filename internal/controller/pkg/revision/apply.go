@@ -0,0 +1,224 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+)
+
+// FieldManager is the name of the field manager the package manager uses when
+// it applies package and runtime objects.
+const FieldManager = "crossplane-package-manager"
+
+// clientSideFieldManager is the field manager the API server recorded for the
+// fields the package manager set before it used server-side apply. The API
+// server derives it from Crossplane's user agent.
+const clientSideFieldManager = "crossplane"
+
+const (
+	errGetGVK               = "cannot determine the type of object to apply"
+	errGetCurrent           = "cannot get current object"
+	errDecodeManagedFields  = "cannot decode managed fields"
+	errEncodeManagedFields  = "cannot encode managed fields"
+	errUpgradeManagedFields = "cannot upgrade managed fields to server-side apply"
+)
+
+// applyOptions returns the options used to apply an object according to the
+// supplied conflict policy.
+func applyOptions(p controller.ApplyConflictPolicy, fieldManager string, opts ...client.PatchOption) []client.PatchOption {
+	o := []client.PatchOption{client.FieldOwner(fieldManager)}
+	if p != controller.ApplyConflictPolicyFail {
+		o = append(o, client.ForceOwnership)
+	}
+	return append(o, opts...)
+}
+
+// IsApplyConflict returns true if the supplied error indicates that an object
+// could not be applied because doing so would change fields that were set by
+// another field manager. Other conflicts, such as an update of a stale object,
+// are not apply conflicts. An error that aggregates the errors encountered
+// establishing a set of resources is an apply conflict if any of them is.
+func IsApplyConflict(err error) bool {
+	return anyEstablishError(err, isApplyConflict)
+}
+
+func isApplyConflict(err error) bool {
+	var s kerrors.APIStatus
+	if !errors.As(err, &s) || s.Status().Reason != metav1.StatusReasonConflict || s.Status().Details == nil {
+		return false
+	}
+	for _, c := range s.Status().Details.Causes {
+		if c.Type == metav1.CauseTypeFieldManagerConflict {
+			return true
+		}
+	}
+	return false
+}
+
+// A jsonPatchOp is an operation of a JSON patch.
+type jsonPatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// upgradeManagedFields makes the supplied field manager the server-side apply
+// owner of all of the fields of the supplied current object that are owned by
+// the package manager's client-side field manager, in the same way as
+// k8s.io/client-go/util/csaupgrade. Otherwise fields that a package set before
+// the package manager used server-side apply would remain owned by the
+// client-side field manager, and would never be removed from the object if
+// the package stopped setting them. It does nothing if the client-side field
+// manager owns no fields.
+func upgradeManagedFields(ctx context.Context, c client.Client, current client.Object, manager string) error {
+	fields := &fieldpath.Set{}
+	upgraded := make([]metav1.ManagedFieldsEntry, 0, len(current.GetManagedFields()))
+	found, apiVersion := false, ""
+	for _, e := range current.GetManagedFields() {
+		if e.Manager != clientSideFieldManager || e.Operation != metav1.ManagedFieldsOperationUpdate || e.Subresource != "" {
+			upgraded = append(upgraded, e)
+			continue
+		}
+		s, err := fieldSet(e)
+		if err != nil {
+			return err
+		}
+		fields = fields.Union(s)
+		found, apiVersion = true, e.APIVersion
+	}
+	if !found {
+		return nil
+	}
+
+	// Our existing server-side apply entry, if any, keeps the fields it owns.
+	i := len(upgraded)
+	for j, e := range upgraded {
+		if e.Manager == manager && e.Operation == metav1.ManagedFieldsOperationApply && e.Subresource == "" {
+			s, err := fieldSet(e)
+			if err != nil {
+				return err
+			}
+			fields = fields.Union(s)
+			apiVersion = e.APIVersion
+			i = j
+			break
+		}
+	}
+	raw, err := fields.ToJSON()
+	if err != nil {
+		return errors.Wrap(err, errEncodeManagedFields)
+	}
+	now := metav1.Now()
+	e := metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  metav1.ManagedFieldsOperationApply,
+		APIVersion: apiVersion,
+		Time:       &now,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: raw},
+	}
+	if i < len(upgraded) {
+		upgraded[i] = e
+	} else {
+		upgraded = append(upgraded, e)
+	}
+
+	// We only upgrade the managed fields we read, so that we don't lose any
+	// ownership that changed since.
+	patch, err := json.Marshal([]jsonPatchOp{
+		{Op: "test", Path: "/metadata/resourceVersion", Value: current.GetResourceVersion()},
+		{Op: "replace", Path: "/metadata/managedFields", Value: upgraded},
+	})
+	if err != nil {
+		return errors.Wrap(err, errEncodeManagedFields)
+	}
+	o, _ := current.DeepCopyObject().(client.Object)
+	return errors.Wrap(c.Patch(ctx, o, client.RawPatch(types.JSONPatchType, patch)), errUpgradeManagedFields)
+}
+
+// fieldSet returns the set of fields recorded by the supplied entry.
+func fieldSet(e metav1.ManagedFieldsEntry) (*fieldpath.Set, error) {
+	s := &fieldpath.Set{}
+	if e.FieldsV1 == nil {
+		return s, nil
+	}
+	return s, errors.Wrap(s.FromJSON(bytes.NewReader(e.FieldsV1.Raw)), errDecodeManagedFields)
+}
+
+// A ServerSideApplicator applies objects using server-side apply. Fields of an
+// object that are set by other field managers, and that the applied object
+// doesn't specify, are preserved.
+type ServerSideApplicator struct {
+	client client.Client
+	policy controller.ApplyConflictPolicy
+}
+
+// NewServerSideApplicator returns a ServerSideApplicator that resolves
+// conflicts according to the supplied policy.
+func NewServerSideApplicator(c client.Client, p controller.ApplyConflictPolicy) *ServerSideApplicator {
+	return &ServerSideApplicator{client: c, policy: p}
+}
+
+// Apply the supplied object. Any ApplyOptions are called with the current state
+// of the object, if it exists, and ownership of any fields set before we used
+// server-side apply is upgraded. The supplied object is updated with the
+// result.
+func (a *ServerSideApplicator) Apply(ctx context.Context, o client.Object, ao ...resource.ApplyOption) error {
+	// Server-side apply requires that we specify the type of object we're
+	// applying, but objects we render typically don't.
+	if o.GetObjectKind().GroupVersionKind().Empty() {
+		gvk, err := apiutil.GVKForObject(o, a.client.Scheme())
+		if err != nil {
+			return errors.Wrap(err, errGetGVK)
+		}
+		o.GetObjectKind().SetGroupVersionKind(gvk)
+	}
+
+	current, _ := o.DeepCopyObject().(client.Object)
+	err := a.client.Get(ctx, types.NamespacedName{Name: o.GetName(), Namespace: o.GetNamespace()}, current)
+	if resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errGetCurrent)
+	}
+	if err == nil {
+		for _, fn := range ao {
+			if err := fn(ctx, current, o); err != nil {
+				return err
+			}
+		}
+		if err := upgradeManagedFields(ctx, a.client, current, FieldManager); err != nil {
+			return err
+		}
+	}
+
+	o.SetResourceVersion("")
+	o.SetManagedFields(nil)
+	return a.client.Patch(ctx, o, client.Apply, applyOptions(a.policy, FieldManager)...)
+}
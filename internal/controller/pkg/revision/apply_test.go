@@ -0,0 +1,401 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+)
+
+var _ resource.Applicator = &ServerSideApplicator{}
+
+func TestServerSideApplicatorApply(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	s := runtime.NewScheme()
+	_ = corev1.AddToScheme(s)
+
+	type args struct {
+		c  client.Client
+		p  controller.ApplyConflictPolicy
+		o  client.Object
+		ao []resource.ApplyOption
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"Successful": {
+			reason: "We should apply an object of the type known to our scheme, forcing ownership of conflicting fields.",
+			args: args{
+				c: &test.MockClient{
+					MockScheme: test.NewMockSchemeFn(s),
+					MockGet:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "sa")),
+					MockPatch: func(_ context.Context, obj client.Object, p client.Patch, opts ...client.PatchOption) error {
+						po := &client.PatchOptions{}
+						po.ApplyOptions(opts)
+						if p != client.Apply || po.FieldManager != FieldManager || po.Force == nil || !*po.Force {
+							t.Errorf("Patch(...): want forced server-side apply as %q, got patch type %s, options %+v", FieldManager, p.Type(), po)
+						}
+						if diff := cmp.Diff(corev1.SchemeGroupVersion.WithKind("ServiceAccount"), obj.GetObjectKind().GroupVersionKind()); diff != "" {
+							t.Errorf("Patch(...): -want GVK, +got GVK:\n%s", diff)
+						}
+						if obj.GetResourceVersion() != "" {
+							t.Errorf("Patch(...): want no resource version, got %q", obj.GetResourceVersion())
+						}
+						return nil
+					},
+				},
+				p: controller.ApplyConflictPolicyForce,
+				o: &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "sa", ResourceVersion: "1"}},
+			},
+		},
+		"ApplyOptionsSeeCurrent": {
+			reason: "We should call any ApplyOptions with the current state of an existing object.",
+			args: args{
+				c: &test.MockClient{
+					MockScheme: test.NewMockSchemeFn(s),
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						obj.SetResourceVersion("42")
+						return nil
+					}),
+					MockPatch: test.NewMockPatchFn(nil),
+				},
+				o: &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "sa"}},
+				ao: []resource.ApplyOption{func(_ context.Context, current, _ runtime.Object) error {
+					if rv := current.(metav1.Object).GetResourceVersion(); rv != "42" {
+						t.Errorf("ApplyOption(...): want current resource version 42, got %q", rv)
+					}
+					return nil
+				}},
+			},
+		},
+		"UpgradeManagedFields": {
+			reason: "We should upgrade the ownership of fields an existing object's client-side field manager set before we apply it.",
+			args: args{
+				c: &test.MockClient{
+					MockScheme: test.NewMockSchemeFn(s),
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						obj.SetResourceVersion("42")
+						obj.SetManagedFields([]metav1.ManagedFieldsEntry{{
+							Manager:    clientSideFieldManager,
+							Operation:  metav1.ManagedFieldsOperationUpdate,
+							APIVersion: "v1",
+							FieldsType: "FieldsV1",
+							FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{}}}`)},
+						}})
+						return nil
+					}),
+					MockPatch: func(_ context.Context, obj client.Object, p client.Patch, _ ...client.PatchOption) error {
+						if p == client.Apply {
+							return nil
+						}
+						if p.Type() != types.JSONPatchType {
+							t.Errorf("Patch(...): want JSON patch of managed fields, got patch type %s", p.Type())
+						}
+						return nil
+					},
+				},
+				o: &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "sa"}},
+			},
+		},
+		"ErrUpgradeManagedFields": {
+			reason: "We should return an error if we can't upgrade the ownership of fields set before we used server-side apply.",
+			args: args{
+				c: &test.MockClient{
+					MockScheme: test.NewMockSchemeFn(s),
+					MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+						obj.SetManagedFields([]metav1.ManagedFieldsEntry{{
+							Manager:   clientSideFieldManager,
+							Operation: metav1.ManagedFieldsOperationUpdate,
+						}})
+						return nil
+					}),
+					MockPatch: test.NewMockPatchFn(errBoom),
+				},
+				o: &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "sa"}},
+			},
+			want: errors.Wrap(errBoom, errUpgradeManagedFields),
+		},
+		"ApplyOptionsSkippedWhenNotFound": {
+			reason: "We should not call any ApplyOptions if the object doesn't exist yet.",
+			args: args{
+				c: &test.MockClient{
+					MockScheme: test.NewMockSchemeFn(s),
+					MockGet:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "sa")),
+					MockPatch:  test.NewMockPatchFn(nil),
+				},
+				o:  &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "sa"}},
+				ao: []resource.ApplyOption{func(_ context.Context, _, _ runtime.Object) error { return errBoom }},
+			},
+		},
+		"ErrUnknownType": {
+			reason: "We should return an error if we can't determine the type of the object.",
+			args: args{
+				c: &test.MockClient{MockScheme: test.NewMockSchemeFn(runtime.NewScheme())},
+				o: &corev1.ServiceAccount{},
+			},
+			want: errors.Wrap(runtime.NewNotRegisteredErrForType(runtime.NewScheme().Name(), reflect.TypeOf(corev1.ServiceAccount{})), errGetGVK),
+		},
+		"ErrGetCurrent": {
+			reason: "We should return an error if we can't get the current object.",
+			args: args{
+				c: &test.MockClient{
+					MockScheme: test.NewMockSchemeFn(s),
+					MockGet:    test.NewMockGetFn(errBoom),
+				},
+				o: &corev1.ServiceAccount{},
+			},
+			want: errors.Wrap(errBoom, errGetCurrent),
+		},
+		"ErrApply": {
+			reason: "We should return any error encountered while applying the object.",
+			args: args{
+				c: &test.MockClient{
+					MockScheme: test.NewMockSchemeFn(s),
+					MockGet:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "sa")),
+					MockPatch:  test.NewMockPatchFn(errBoom),
+				},
+				o: &corev1.ServiceAccount{},
+			},
+			want: errBoom,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			a := NewServerSideApplicator(tc.args.c, tc.args.p)
+			err := a.Apply(context.Background(), tc.args.o, tc.args.ao...)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIsApplyConflict(t *testing.T) {
+	fieldConflict := kerrors.NewApplyConflict([]metav1.StatusCause{{
+		Type:    metav1.CauseTypeFieldManagerConflict,
+		Message: `conflict with "kubectl"`,
+		Field:   ".spec.replicas",
+	}}, "Apply failed with 1 conflict")
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   bool
+	}{
+		"FieldManagerConflict": {
+			reason: "A wrapped conflict with another field manager should be an apply conflict.",
+			err:    errors.Wrap(fieldConflict, "wrapped"),
+			want:   true,
+		},
+		"AggregatedFieldManagerConflict": {
+			reason: "Errors establishing several resources should be an apply conflict if any of them is, not only the first.",
+			err:    errors.Wrap(establishErrors{errors.New("boom"), fieldConflict}, "wrapped"),
+			want:   true,
+		},
+		"AggregatedOtherErrors": {
+			reason: "Errors establishing several resources should not be an apply conflict if none of them is.",
+			err:    establishErrors{errors.New("boom"), errors.New("bang")},
+			want:   false,
+		},
+		"StaleObjectConflict": {
+			reason: "A conflict caused by updating a stale object should not be an apply conflict.",
+			err:    kerrors.NewConflict(schema.GroupResource{}, "sa", errors.New("the object has been modified")),
+			want:   false,
+		},
+		"OtherError": {
+			reason: "An arbitrary error should not be an apply conflict.",
+			err:    errors.New("boom"),
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := IsApplyConflict(tc.err)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nIsApplyConflict(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpgradeManagedFields(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := metav1.Now()
+
+	csa := metav1.ManagedFieldsEntry{
+		Manager:    clientSideFieldManager,
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:a":{}}}}`)},
+	}
+	ssa := metav1.ManagedFieldsEntry{
+		Manager:    FieldManager,
+		Operation:  metav1.ManagedFieldsOperationApply,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:b":{}}}}`)},
+	}
+	other := metav1.ManagedFieldsEntry{
+		Manager:    "kubectl",
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:c":{}}}}`)},
+	}
+
+	type want struct {
+		patch []jsonPatchOp
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		current []metav1.ManagedFieldsEntry
+		err     error
+		want    want
+	}{
+		"NothingToUpgrade": {
+			reason:  "We should not patch an object whose client-side field manager owns no fields.",
+			current: []metav1.ManagedFieldsEntry{ssa, other},
+		},
+		"NoApplyEntry": {
+			reason:  "We should move the fields owned by the client-side field manager to a new server-side apply entry.",
+			current: []metav1.ManagedFieldsEntry{csa, other},
+			want: want{
+				patch: []jsonPatchOp{
+					{Op: "test", Path: "/metadata/resourceVersion", Value: "42"},
+					{Op: "replace", Path: "/metadata/managedFields", Value: []metav1.ManagedFieldsEntry{other, {
+						Manager:    FieldManager,
+						Operation:  metav1.ManagedFieldsOperationApply,
+						APIVersion: "v1",
+						Time:       &now,
+						FieldsType: "FieldsV1",
+						FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:a":{}}}}`)},
+					}}},
+				},
+			},
+		},
+		"MergeIntoApplyEntry": {
+			reason:  "We should merge the fields owned by the client-side field manager into our existing server-side apply entry.",
+			current: []metav1.ManagedFieldsEntry{ssa, csa, other},
+			want: want{
+				patch: []jsonPatchOp{
+					{Op: "test", Path: "/metadata/resourceVersion", Value: "42"},
+					{Op: "replace", Path: "/metadata/managedFields", Value: []metav1.ManagedFieldsEntry{{
+						Manager:    FieldManager,
+						Operation:  metav1.ManagedFieldsOperationApply,
+						APIVersion: "v1",
+						Time:       &now,
+						FieldsType: "FieldsV1",
+						FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:a":{},"f:b":{}}}}`)},
+					}, other}},
+				},
+			},
+		},
+		"ErrPatch": {
+			reason:  "We should return any error encountered while patching the managed fields.",
+			current: []metav1.ManagedFieldsEntry{csa},
+			err:     errBoom,
+			want: want{
+				patch: []jsonPatchOp{
+					{Op: "test", Path: "/metadata/resourceVersion", Value: "42"},
+					{Op: "replace", Path: "/metadata/managedFields", Value: []metav1.ManagedFieldsEntry{{
+						Manager:    FieldManager,
+						Operation:  metav1.ManagedFieldsOperationApply,
+						APIVersion: "v1",
+						Time:       &now,
+						FieldsType: "FieldsV1",
+						FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{"f:a":{}}}}`)},
+					}}},
+				},
+				err: errors.Wrap(errBoom, errUpgradeManagedFields),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			patched := false
+			c := &test.MockClient{
+				MockPatch: func(_ context.Context, obj client.Object, p client.Patch, _ ...client.PatchOption) error {
+					b, _ := p.Data(obj)
+					want, _ := json.Marshal(tc.want.patch)
+
+					// The upgraded entry is timestamped when we upgrade it, so
+					// we compare patches ignoring time.
+					if diff := cmp.Diff(normalizeTimes(t, want), normalizeTimes(t, b)); diff != "" {
+						t.Errorf("\n%s\nPatch(...): -want, +got:\n%s", tc.reason, diff)
+					}
+					patched = true
+					return tc.err
+				},
+			}
+			current := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "sa", ResourceVersion: "42", ManagedFields: tc.current}}
+			err := upgradeManagedFields(context.Background(), c, current, FieldManager)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nupgradeManagedFields(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.patch != nil, patched); diff != "" {
+				t.Errorf("\n%s\nupgradeManagedFields(...): -want patched, +got patched:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// normalizeTimes decodes the supplied JSON patch, removing the time of any
+// managed fields entry.
+func normalizeTimes(t *testing.T, patch []byte) []map[string]interface{} {
+	t.Helper()
+	ops := []map[string]interface{}{}
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		t.Fatalf("json.Unmarshal(...): %v", err)
+	}
+	for _, op := range ops {
+		entries, ok := op["value"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, e := range entries {
+			delete(e.(map[string]interface{}), "time")
+		}
+	}
+	return ops
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+const (
+	errListPolicies                = "cannot list package source policies"
+	errParseControllerImage        = "cannot parse controller image"
+	errResolveControllerImage      = "cannot resolve controller image digest"
+	errFmtControllerImageNotPinned = "controller image %q must be pinned by digest according to PackageSourcePolicy %q"
+	errFmtVerifyControllerImage    = "cannot verify controller image according to PackageSourcePolicy %q"
+	errNoPublicKeys                = "no public keys were supplied"
+	errDecodePublicKey             = "cannot decode PEM encoded public key"
+	errParsePublicKey              = "cannot parse public key"
+	errNotECDSAPublicKey           = "public key is not an ECDSA public key"
+	errFetchSignature              = "cannot fetch controller image signature"
+	errGetSignatureManifest        = "cannot get controller image signature manifest"
+	errGetSignatureLayers          = "cannot get controller image signature layers"
+	errReadSignaturePayload        = "cannot read controller image signature payload"
+	errFmtNoValidSignature         = "no signature of %q could be verified"
+)
+
+const (
+	// signatureTagSuffix is appended to the digest of an image to form the
+	// tag at which cosign stores its signatures.
+	signatureTagSuffix = ".sig"
+
+	// signatureAnnotation is the layer annotation at which cosign stores the
+	// base64 encoded signature of the layer's payload.
+	signatureAnnotation = "dev.cosignproject.cosign/signature"
+)
+
+// A ControllerImagePolicy determines whether a provider's controller image may
+// be run.
+type ControllerImagePolicy interface {
+	// Pin returns the supplied controller image, pinned by digest if policy
+	// requires it to be. It returns an error if the image may not be run.
+	Pin(ctx context.Context, image string, secrets ...string) (string, error)
+}
+
+// A ControllerImagePolicyFn is a function that satisfies
+// ControllerImagePolicy.
+type ControllerImagePolicyFn func(ctx context.Context, image string, secrets ...string) (string, error)
+
+// Pin returns the supplied controller image, pinned by digest if policy
+// requires it to be.
+func (fn ControllerImagePolicyFn) Pin(ctx context.Context, image string, secrets ...string) (string, error) {
+	return fn(ctx, image, secrets...)
+}
+
+// NopControllerImagePolicy allows any controller image to be run.
+type NopControllerImagePolicy struct{}
+
+// NewNopControllerImagePolicy creates a new NopControllerImagePolicy.
+func NewNopControllerImagePolicy() *NopControllerImagePolicy {
+	return &NopControllerImagePolicy{}
+}
+
+// Pin returns the supplied image unchanged.
+func (p *NopControllerImagePolicy) Pin(_ context.Context, image string, _ ...string) (string, error) {
+	return image, nil
+}
+
+// An APIControllerImagePolicy allows a controller image to be run only if it
+// satisfies every PackageSourcePolicy in the API server.
+type APIControllerImagePolicy struct {
+	client  client.Reader
+	fetcher xpkg.Fetcher
+}
+
+// NewAPIControllerImagePolicy creates a new APIControllerImagePolicy.
+func NewAPIControllerImagePolicy(c client.Reader, f xpkg.Fetcher) *APIControllerImagePolicy {
+	return &APIControllerImagePolicy{client: c, fetcher: f}
+}
+
+// Pin returns the supplied controller image, pinned by digest if any
+// PackageSourcePolicy requires it to be resolved or verified. It returns an
+// error if any PackageSourcePolicy requires the image to be pinned by digest
+// but it is not, or if the image's signature cannot be verified.
+func (p *APIControllerImagePolicy) Pin(ctx context.Context, image string, secrets ...string) (string, error) { // nolint:gocyclo
+	l := &v1alpha1.PackageSourcePolicyList{}
+	if err := p.client.List(ctx, l); err != nil {
+		return "", errors.Wrap(err, errListPolicies)
+	}
+
+	required, resolve := "", false
+	for _, sp := range l.Items {
+		cip := sp.Spec.ControllerImages
+		if cip == nil {
+			continue
+		}
+		if cip.Digest == v1alpha1.DigestPolicyRequired && required == "" {
+			required = sp.GetName()
+		}
+		// We verify the signature of a particular digest, so we must run
+		// that digest rather than a tag that could later be moved.
+		if cip.Digest == v1alpha1.DigestPolicyResolve || len(cip.PublicKeys) > 0 {
+			resolve = true
+		}
+	}
+
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", errors.Wrap(err, errParseControllerImage)
+	}
+	d, pinned := ref.(name.Digest)
+	if !pinned {
+		if required != "" {
+			return "", errors.Errorf(errFmtControllerImageNotPinned, image, required)
+		}
+		if !resolve {
+			return image, nil
+		}
+		desc, err := p.fetcher.Head(ctx, ref, secrets...)
+		if err != nil {
+			return "", errors.Wrap(err, errResolveControllerImage)
+		}
+		d = ref.Context().Digest(desc.Digest.String())
+
+		// We keep the tag, which Kubernetes ignores, so that it's clear which
+		// version of the controller the digest corresponds to.
+		image = image + "@" + desc.Digest.String()
+	}
+
+	for _, sp := range l.Items {
+		cip := sp.Spec.ControllerImages
+		if cip == nil || len(cip.PublicKeys) == 0 {
+			continue
+		}
+		if err := verifySignature(ctx, p.fetcher, d, cip.PublicKeys, secrets...); err != nil {
+			return "", errors.Wrapf(err, errFmtVerifyControllerImage, sp.GetName())
+		}
+	}
+
+	return image, nil
+}
+
+// A signaturePayload is the subset of a cosign simple signing payload that we
+// need in order to verify which image a signature applies to.
+type signaturePayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// verifySignature returns nil if the supplied image digest has a cosign
+// signature that can be verified by any of the supplied PEM encoded public
+// keys.
+func verifySignature(ctx context.Context, f xpkg.Fetcher, d name.Digest, keys []string, secrets ...string) error { // nolint:gocyclo
+	pks, err := parsePublicKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	tag := d.Context().Tag(strings.Replace(d.DigestStr(), ":", "-", 1) + signatureTagSuffix)
+	img, err := f.Fetch(ctx, tag, secrets...)
+	if err != nil {
+		return errors.Wrap(err, errFetchSignature)
+	}
+	m, err := img.Manifest()
+	if err != nil {
+		return errors.Wrap(err, errGetSignatureManifest)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return errors.Wrap(err, errGetSignatureLayers)
+	}
+
+	for i, desc := range m.Layers {
+		if i >= len(layers) {
+			break
+		}
+		sig, err := base64.StdEncoding.DecodeString(desc.Annotations[signatureAnnotation])
+		if err != nil || len(sig) == 0 {
+			continue
+		}
+		payload, err := readLayer(layers[i].Compressed)
+		if err != nil {
+			return errors.Wrap(err, errReadSignaturePayload)
+		}
+		sum := sha256.Sum256(payload)
+		for _, pk := range pks {
+			if !ecdsa.VerifyASN1(pk, sum[:], sig) {
+				continue
+			}
+			sp := &signaturePayload{}
+			if err := json.Unmarshal(payload, sp); err != nil {
+				continue
+			}
+			// A valid signature is only meaningful if it signs this image.
+			if sp.Critical.Image.DockerManifestDigest == d.DigestStr() {
+				return nil
+			}
+		}
+	}
+
+	return errors.Errorf(errFmtNoValidSignature, d.String())
+}
+
+func parsePublicKeys(keys []string) ([]*ecdsa.PublicKey, error) {
+	if len(keys) == 0 {
+		return nil, errors.New(errNoPublicKeys)
+	}
+	pks := make([]*ecdsa.PublicKey, len(keys))
+	for i, k := range keys {
+		b, _ := pem.Decode([]byte(k))
+		if b == nil {
+			return nil, errors.New(errDecodePublicKey)
+		}
+		pk, err := x509.ParsePKIXPublicKey(b.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, errParsePublicKey)
+		}
+		epk, ok := pk.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, errors.New(errNotECDSAPublicKey)
+		}
+		pks[i] = epk
+	}
+	return pks, nil
+}
+
+func readLayer(open func() (io.ReadCloser, error)) ([]byte, error) {
+	rc, err := open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	conregv1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+	"github.com/crossplane/crossplane/internal/xpkg/fake"
+)
+
+var _ ControllerImagePolicy = &NopControllerImagePolicy{}
+var _ ControllerImagePolicy = &APIControllerImagePolicy{}
+
+func TestAPIControllerImagePolicyPin(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	digest := conregv1.Hash{Algorithm: "sha256", Hex: fmt.Sprintf("%x", sha256.Sum256([]byte("controller")))}
+	other := conregv1.Hash{Algorithm: "sha256", Hex: fmt.Sprintf("%x", sha256.Sum256([]byte("other")))}
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	encode := func(k *ecdsa.PrivateKey) string {
+		b, _ := x509.MarshalPKIXPublicKey(&k.PublicKey)
+		return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: b}))
+	}
+
+	// sign returns a cosign signature image that signs the supplied digest
+	// using the supplied key.
+	sign := func(k *ecdsa.PrivateKey, h conregv1.Hash) conregv1.Image {
+		payload := []byte(fmt.Sprintf(`{"critical":{"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"}}`, h.String()))
+		sum := sha256.Sum256(payload)
+		sig, _ := ecdsa.SignASN1(rand.Reader, k, sum[:])
+		img, _ := mutate.Append(empty.Image, mutate.Addendum{
+			Layer:       static.NewLayer(payload, types.MediaType("application/vnd.dev.cosign.simplesigning.v1+json")),
+			Annotations: map[string]string{signatureAnnotation: base64.StdEncoding.EncodeToString(sig)},
+		})
+		return img
+	}
+
+	withPolicies := func(cips ...v1alpha1.ControllerImagePolicy) test.MockListFn {
+		return test.NewMockListFn(nil, func(obj client.ObjectList) error {
+			l := obj.(*v1alpha1.PackageSourcePolicyList)
+			for i := range cips {
+				sp := v1alpha1.PackageSourcePolicy{Spec: v1alpha1.PackageSourcePolicySpec{ControllerImages: &cips[i]}}
+				sp.SetName(fmt.Sprintf("policy-%d", i))
+				l.Items = append(l.Items, sp)
+			}
+			return nil
+		})
+	}
+
+	type args struct {
+		image string
+	}
+	type want struct {
+		image string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		client  client.Reader
+		fetcher *fake.MockFetcher
+		args    args
+		want    want
+	}{
+		"ErrListPolicies": {
+			reason: "We should return any error encountered listing policies.",
+			client: &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+			args:   args{image: "crossplane/provider-nop:v0.1.0"},
+			want:   want{err: errors.Wrap(errBoom, errListPolicies)},
+		},
+		"NoControllerImagePolicy": {
+			reason: "Images referenced by tag should be unchanged when no policy constrains them.",
+			client: &test.MockClient{MockList: withPolicies()},
+			args:   args{image: "crossplane/provider-nop:v0.1.0"},
+			want:   want{image: "crossplane/provider-nop:v0.1.0"},
+		},
+		"ErrDigestRequired": {
+			reason: "We should return an error if an image referenced by tag must be pinned by digest.",
+			client: &test.MockClient{MockList: withPolicies(v1alpha1.ControllerImagePolicy{Digest: v1alpha1.DigestPolicyRequired})},
+			args:   args{image: "crossplane/provider-nop:v0.1.0"},
+			want:   want{err: errors.Errorf(errFmtControllerImageNotPinned, "crossplane/provider-nop:v0.1.0", "policy-0")},
+		},
+		"SuccessfulDigestRequired": {
+			reason: "Images that are pinned by digest should satisfy a policy that requires it.",
+			client: &test.MockClient{MockList: withPolicies(v1alpha1.ControllerImagePolicy{Digest: v1alpha1.DigestPolicyRequired})},
+			args:   args{image: "crossplane/provider-nop@" + digest.String()},
+			want:   want{image: "crossplane/provider-nop@" + digest.String()},
+		},
+		"ErrResolveDigest": {
+			reason: "We should return any error encountered resolving a tag to a digest.",
+			client: &test.MockClient{MockList: withPolicies(v1alpha1.ControllerImagePolicy{Digest: v1alpha1.DigestPolicyResolve})},
+			fetcher: &fake.MockFetcher{
+				MockHead: fake.NewMockHeadFn(nil, errBoom),
+			},
+			args: args{image: "crossplane/provider-nop:v0.1.0"},
+			want: want{err: errors.Wrap(errBoom, errResolveControllerImage)},
+		},
+		"SuccessfulResolveDigest": {
+			reason: "Images referenced by tag should be pinned to the digest the tag resolves to.",
+			client: &test.MockClient{MockList: withPolicies(v1alpha1.ControllerImagePolicy{Digest: v1alpha1.DigestPolicyResolve})},
+			fetcher: &fake.MockFetcher{
+				MockHead: fake.NewMockHeadFn(&conregv1.Descriptor{Digest: digest}, nil),
+			},
+			args: args{image: "crossplane/provider-nop:v0.1.0"},
+			want: want{image: "crossplane/provider-nop:v0.1.0@" + digest.String()},
+		},
+		"ErrFetchSignature": {
+			reason: "We should return any error encountered fetching an image's signature.",
+			client: &test.MockClient{MockList: withPolicies(v1alpha1.ControllerImagePolicy{PublicKeys: []string{encode(key)}})},
+			fetcher: &fake.MockFetcher{
+				MockFetch: fake.NewMockFetchFn(nil, errBoom),
+			},
+			args: args{image: "crossplane/provider-nop@" + digest.String()},
+			want: want{err: errors.Wrapf(errors.Wrap(errBoom, errFetchSignature), errFmtVerifyControllerImage, "policy-0")},
+		},
+		"ErrDecodePublicKey": {
+			reason: "We should return an error if a public key is not PEM encoded.",
+			client: &test.MockClient{MockList: withPolicies(v1alpha1.ControllerImagePolicy{PublicKeys: []string{"key"}})},
+			args:   args{image: "crossplane/provider-nop@" + digest.String()},
+			want:   want{err: errors.Wrapf(errors.New(errDecodePublicKey), errFmtVerifyControllerImage, "policy-0")},
+		},
+		"ErrSignedByOtherKey": {
+			reason: "We should return an error if an image is not signed by any of the supplied keys.",
+			client: &test.MockClient{MockList: withPolicies(v1alpha1.ControllerImagePolicy{PublicKeys: []string{encode(key)}})},
+			fetcher: &fake.MockFetcher{
+				MockFetch: fake.NewMockFetchFn(sign(otherKey, digest), nil),
+			},
+			args: args{image: "crossplane/provider-nop@" + digest.String()},
+			want: want{err: errors.Wrapf(errors.Errorf(errFmtNoValidSignature, "crossplane/provider-nop@"+digest.String()), errFmtVerifyControllerImage, "policy-0")},
+		},
+		"ErrSignatureOfOtherImage": {
+			reason: "We should return an error if a valid signature signs a different image.",
+			client: &test.MockClient{MockList: withPolicies(v1alpha1.ControllerImagePolicy{PublicKeys: []string{encode(key)}})},
+			fetcher: &fake.MockFetcher{
+				MockFetch: fake.NewMockFetchFn(sign(key, other), nil),
+			},
+			args: args{image: "crossplane/provider-nop@" + digest.String()},
+			want: want{err: errors.Wrapf(errors.Errorf(errFmtNoValidSignature, "crossplane/provider-nop@"+digest.String()), errFmtVerifyControllerImage, "policy-0")},
+		},
+		"SuccessfulVerifySignature": {
+			reason: "Images referenced by tag should be pinned by digest and verified when a policy specifies public keys.",
+			client: &test.MockClient{MockList: withPolicies(v1alpha1.ControllerImagePolicy{PublicKeys: []string{encode(otherKey), encode(key)}})},
+			fetcher: &fake.MockFetcher{
+				MockHead:  fake.NewMockHeadFn(&conregv1.Descriptor{Digest: digest}, nil),
+				MockFetch: fake.NewMockFetchFn(sign(key, digest), nil),
+			},
+			args: args{image: "crossplane/provider-nop:v0.1.0"},
+			want: want{image: "crossplane/provider-nop:v0.1.0@" + digest.String()},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := NewAPIControllerImagePolicy(tc.client, tc.fetcher)
+			got, err := p.Pin(context.Background(), tc.args.image)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\np.Pin(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.image, got); diff != "" {
+				t.Errorf("\n%s\np.Pin(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
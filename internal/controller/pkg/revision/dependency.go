@@ -18,36 +18,99 @@ package revision
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/Masterminds/semver"
-	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
 	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
 	"github.com/crossplane/crossplane/internal/dag"
+	"github.com/crossplane/crossplane/internal/metrics"
+	"github.com/crossplane/crossplane/internal/version"
 	"github.com/crossplane/crossplane/internal/xpkg"
 )
 
 const (
 	lockName = "lock"
+	kindCRD  = "CustomResourceDefinition"
 
 	errNotMeta                   = "meta type is not a valid package"
 	errGetOrCreateLock           = "cannot get or create lock"
 	errIncompatibleDependencyFmt = "incompatible dependencies: %+v"
+	errDependencyTypeMismatchFmt = "dependencies installed as a different type of package: %+v"
 	errMissingDependenciesFmt    = "missing dependencies: %+v"
+	errMissingAPIGroupsFmt       = "no installed package provides API groups: %+v"
+	errListRevisions             = "cannot list package revisions"
 	errDependencyNotInGraph      = "dependency is not present in graph"
 	errDependencyNotLockPackage  = "dependency in graph is not a lock package"
+	errLockConflict              = "lock was updated by another package revision"
+	errWaitingOnLock             = "lock was created by another package revision"
+	errGetDependencyRevision     = "cannot get dependency package revision"
+	errDegradedDependenciesFmt   = "unhealthy dependencies: %+v"
 )
 
+// A lockConflictError indicates that the Lock could not be updated because it
+// was updated by someone else after we read it.
+type lockConflictError struct{ error }
+
+func (e lockConflictError) Unwrap() error { return e.error }
+
+// IsLockConflict returns true if the supplied error indicates that the Lock
+// could not be updated because it was updated concurrently.
+func IsLockConflict(err error) bool {
+	return errors.As(err, &lockConflictError{})
+}
+
+// A waitingOnLockError indicates that the Lock did not exist when we read it,
+// but was created by someone else before we could create it.
+type waitingOnLockError struct{ error }
+
+func (e waitingOnLockError) Unwrap() error { return e.error }
+
+// IsWaitingOnLock returns true if the supplied error indicates that the Lock
+// could not be read because it was being created concurrently.
+func IsWaitingOnLock(err error) bool {
+	return errors.As(err, &waitingOnLockError{})
+}
+
+// A dependencyTypeMismatchError indicates that a dependency is installed, but
+// as a different type of package than the one that was declared.
+type dependencyTypeMismatchError struct{ error }
+
+func (e dependencyTypeMismatchError) Unwrap() error { return e.error }
+
+// IsDependencyTypeMismatch returns true if the supplied error indicates that a
+// dependency declared as one type of package, for example a Provider, is
+// installed as another, for example a Configuration.
+func IsDependencyTypeMismatch(err error) bool {
+	return errors.As(err, &dependencyTypeMismatchError{})
+}
+
+// A degradedDependenciesError indicates that one or more dependencies are
+// installed, but their active revisions are not healthy.
+type degradedDependenciesError struct{ error }
+
+func (e degradedDependenciesError) Unwrap() error { return e.error }
+
+// IsDegradedDependencies returns true if the supplied error indicates that a
+// dependency is installed, but is not healthy.
+func IsDegradedDependencies(err error) bool {
+	return errors.As(err, &degradedDependenciesError{})
+}
+
 // DependencyManager is a lock on packages.
 type DependencyManager interface {
-	Resolve(ctx context.Context, pkg runtime.Object, pr v1.PackageRevision) (found, installed, invalid int, err error)
+	Resolve(ctx context.Context, pkg runtime.Object, pr v1.PackageRevision) (found, installed, invalid, optional int, err error)
 	RemoveSelf(ctx context.Context, pr v1.PackageRevision) error
 }
 
@@ -56,40 +119,101 @@ type PackageDependencyManager struct {
 	client      client.Client
 	newDag      dag.NewDAGFn
 	packageType v1beta1.PackageType
+	metrics     metrics.LockRecorder
+	unhealthy   controller.UnhealthyDependencyPolicy
+}
+
+// A PackageDependencyManagerOption configures a PackageDependencyManager.
+type PackageDependencyManagerOption func(m *PackageDependencyManager)
+
+// WithLockMetricsRecorder specifies how the PackageDependencyManager should
+// record metrics about contention for the Lock.
+func WithLockMetricsRecorder(r metrics.LockRecorder) PackageDependencyManagerOption {
+	return func(m *PackageDependencyManager) {
+		m.metrics = r
+	}
+}
+
+// WithUnhealthyDependencyPolicy specifies what the PackageDependencyManager
+// should do when a dependency is installed, but its active revision is not
+// healthy. Unhealthy dependencies are ignored by default.
+func WithUnhealthyDependencyPolicy(p controller.UnhealthyDependencyPolicy) PackageDependencyManagerOption {
+	return func(m *PackageDependencyManager) {
+		m.unhealthy = p
+	}
 }
 
 // NewPackageDependencyManager creates a new PackageDependencyManager.
-func NewPackageDependencyManager(c client.Client, nd dag.NewDAGFn, t v1beta1.PackageType) *PackageDependencyManager {
-	return &PackageDependencyManager{
+func NewPackageDependencyManager(c client.Client, nd dag.NewDAGFn, t v1beta1.PackageType, opts ...PackageDependencyManagerOption) *PackageDependencyManager {
+	m := &PackageDependencyManager{
 		client:      c,
 		newDag:      nd,
 		packageType: t,
+		metrics:     metrics.NewNopLockRecorder(),
+		unhealthy:   controller.UnhealthyDependencyPolicyIgnore,
 	}
+	for _, o := range opts {
+		o(m)
+	}
+	return m
 }
 
-// Resolve resolves package dependencies.
-func (m *PackageDependencyManager) Resolve(ctx context.Context, pkg runtime.Object, pr v1.PackageRevision) (found, installed, invalid int, err error) { // nolint:gocyclo
-	pack, ok := xpkg.TryConvertToPkg(pkg, &pkgmetav1.Provider{}, &pkgmetav1.Configuration{})
+// Resolve resolves package dependencies. Missing optional dependencies are
+// not considered found or installed, and are instead counted separately.
+// Depending on the unhealthy dependency policy, dependencies whose active
+// revision is not healthy may be reported, or not considered installed.
+func (m *PackageDependencyManager) Resolve(ctx context.Context, pkg runtime.Object, pr v1.PackageRevision) (found, installed, invalid, optional int, err error) { // nolint:gocyclo
+	po, err := xpkg.ConvertToHub(pkg)
+	if err != nil {
+		return found, installed, invalid, optional, errors.Wrap(err, errNotMeta)
+	}
+	pack, ok := po.(pkgmetav1.Pkg)
 	if !ok {
-		return found, installed, invalid, errors.New(errNotMeta)
+		return found, installed, invalid, optional, errors.New(errNotMeta)
+	}
+
+	// Dependencies on an API group are resolved to the installed package
+	// that provides it, if any.
+	var providers map[string]v1beta1.Dependency
+	for _, dep := range pack.GetDependencies() {
+		if dep.APIGroup != nil {
+			if providers, err = m.apiGroupProviders(ctx, pr); err != nil {
+				return found, installed, invalid, optional, err
+			}
+			break
+		}
 	}
 
 	// Copy package dependencies into Lock Dependencies.
-	sources := make([]v1beta1.Dependency, len(pack.GetDependencies()))
-	for i, dep := range pack.GetDependencies() {
+	sources := make([]v1beta1.Dependency, 0, len(pack.GetDependencies()))
+	var unprovided []string
+	for _, dep := range pack.GetDependencies() {
 		pdep := v1beta1.Dependency{}
-		if dep.Configuration != nil {
+		switch {
+		case dep.Configuration != nil:
 			pdep.Package = *dep.Configuration
 			pdep.Type = v1beta1.ConfigurationPackageType
-		} else if dep.Provider != nil {
+		case dep.Provider != nil:
 			pdep.Package = *dep.Provider
 			pdep.Type = v1beta1.ProviderPackageType
+		case dep.APIGroup != nil:
+			p, ok := providers[*dep.APIGroup]
+			if !ok {
+				if !dep.Optional {
+					unprovided = append(unprovided, *dep.APIGroup)
+				}
+				continue
+			}
+			pdep.Package = p.Package
+			pdep.Type = p.Type
 		}
 		pdep.Constraints = dep.Version
-		sources[i] = pdep
+		pdep.Optional = dep.Optional
+		pdep.Channel = string(pr.GetChannel())
+		sources = append(sources, pdep)
 	}
 
-	found = len(sources)
+	found = len(sources) + len(unprovided)
 
 	// Get the lock.
 	lock := &v1beta1.Lock{}
@@ -98,124 +222,267 @@ func (m *PackageDependencyManager) Resolve(ctx context.Context, pkg runtime.Obje
 		// If lock does not exist and we are inactive then we can return early
 		// because our only operation would be to remove self.
 		if pr.GetDesiredState() == v1.PackageRevisionInactive {
-			return found, installed, invalid, nil
+			return found, installed, invalid, optional, nil
 		}
 		lock.Name = lockName
 		err = m.client.Create(ctx, lock, &client.CreateOptions{})
+
+		// Another revision created the Lock after we tried to get it. We'll
+		// read it next time we're reconciled.
+		if kerrors.IsAlreadyExists(err) {
+			err = waitingOnLockError{errors.Wrap(err, errWaitingOnLock)}
+		}
 	}
 	if err != nil {
-		return found, installed, invalid, errors.Wrap(err, errGetOrCreateLock)
+		return found, installed, invalid, optional, errors.Wrap(err, errGetOrCreateLock)
 	}
 
-	prRef, err := name.ParseReference(pr.GetSource(), name.WithDefaultRegistry(""))
+	prRef, err := xpkg.ParseSource(pr.GetSource(), "")
 	if err != nil {
-		return found, installed, invalid, err
+		return found, installed, invalid, optional, err
 	}
 
 	lockRef := xpkg.ParsePackageSourceFromReference(prRef)
+
+	// NOTE(hasheddan): consider adding health of package to lock so that it can
+	// be rolled up to any dependent packages. Until then the health of direct
+	// dependencies is read from their revisions, if our policy requires it.
+	self := v1beta1.LockPackage{
+		Name:         pr.GetName(),
+		Type:         m.packageType,
+		Source:       lockRef,
+		Version:      prRef.Identifier(),
+		Dependencies: sources,
+		Resolved:     pr.GetLabels()[v1.LabelResolvedDependency] == "true",
+	}
+
+	// A package without dependencies that is alone in the Lock has nothing to
+	// resolve, so there's no need to build a graph.
+	if len(sources) == 0 && len(unprovided) == 0 && len(lock.Packages) <= 1 {
+		return found, installed, invalid, optional, m.resolveAlone(ctx, lock, self, pr.GetDesiredState())
+	}
+
 	selfIndex := intPointer(-1)
 	d := m.newDag()
-	implied, err := d.Init(v1beta1.ToNodes(lock.Packages...), dag.FindIndex(lockRef, selfIndex))
+	pkgs := v1beta1.ExpandWildcards(lock.Packages...)
+	implied, err := d.Init(v1beta1.ToNodes(pkgs...), dag.FindIndex(lockRef, selfIndex))
 	if err != nil {
-		return found, installed, invalid, err
+		return found, installed, invalid, optional, err
 	}
 
 	// If we are inactive, all we want to do is remove self.
 	if pr.GetDesiredState() == v1.PackageRevisionInactive {
 		if *selfIndex >= 0 {
 			lock.Packages = append(lock.Packages[:*selfIndex], lock.Packages[*selfIndex+1:]...)
-			return found, installed, invalid, m.client.Update(ctx, lock)
+			return found, installed, invalid, optional, m.update(ctx, lock)
 		}
-		return found, installed, invalid, nil
-	}
-
-	// NOTE(hasheddan): consider adding health of package to lock so that it can
-	// be rolled up to any dependent packages.
-	self := v1beta1.LockPackage{
-		Name:         pr.GetName(),
-		Type:         m.packageType,
-		Source:       lockRef,
-		Version:      prRef.Identifier(),
-		Dependencies: sources,
+		return found, installed, invalid, optional, nil
 	}
 
 	// If we don't exist in lock then we should add self.
 	if *selfIndex == -1 {
 		lock.Packages = append(lock.Packages, self)
-		if err := m.client.Update(ctx, lock); err != nil {
-			return found, installed, invalid, err
+		if err := m.update(ctx, lock); err != nil {
+			return found, installed, invalid, optional, err
 		}
+	}
+
+	// Keep our record of whether we were installed to satisfy a dependency up
+	// to date, so that tooling can tell which packages were pinned by a human.
+	if *selfIndex >= 0 && lock.Packages[*selfIndex].Resolved != self.Resolved {
+		lock.Packages[*selfIndex].Resolved = self.Resolved
+		if err := m.update(ctx, lock); err != nil {
+			return found, installed, invalid, optional, err
+		}
+	}
+
+	// Nothing installs a package to provide an API group, so there's no
+	// point resolving anything else until one is installed.
+	if len(unprovided) != 0 {
+		return found, installed, invalid, optional, errors.Errorf(errMissingAPIGroupsFmt, unprovided)
+	}
+
+	// Any wildcard dependencies are resolved to the installed packages they
+	// match. A wildcard that matches nothing remains, and is missing.
+	expanded := self.Expand(lock.Packages...)
+
+	if *selfIndex == -1 {
 		// Package may exist in the graph as a dependency, or may not exist at
 		// all. We need to either convert it to a full node or add it.
-		d.AddOrUpdateNodes(&self)
+		d.AddOrUpdateNodes(&expanded)
 
 		// If any direct dependencies are missing we skip checking for
 		// transitive ones.
 		var missing []string
-		for _, dep := range self.Dependencies {
+		for _, dep := range expanded.Dependencies {
 			if d.NodeExists(dep.Identifier()) {
 				installed++
 				continue
 			}
+			if dep.Optional {
+				optional++
+				continue
+			}
 			missing = append(missing, dep.Identifier())
 		}
-		if installed != found {
-			return found, installed, invalid, errors.Errorf(errMissingDependenciesFmt, missing)
+		found = len(expanded.Dependencies) - optional
+		if len(missing) != 0 {
+			return found, installed, invalid, optional, errors.Errorf(errMissingDependenciesFmt, missing)
+		}
+
+		// Only optional dependencies may be missing at this point. We add
+		// edges to them so that they're implied, and thus traceable.
+		if optional > 0 {
+			imp, err := d.AddEdges(map[string][]dag.Node{expanded.Identifier(): expanded.Neighbors()})
+			if err != nil {
+				return found, installed, invalid, optional, err
+			}
+			implied = append(implied, imp...)
 		}
 	}
 
 	tree, err := d.TraceNode(lockRef)
 	if err != nil {
-		return found, installed, invalid, err
+		return found, installed, invalid, optional, err
 	}
-	found = len(tree)
-	installed = found
-	// Check if any dependencies or transitive dependencies are missing (implied).
+	// Check if any dependencies or transitive dependencies are missing
+	// (implied). A missing dependency is optional only if no package in our
+	// tree requires it.
+	required := requiredDependencies(tree, lockRef, append(pkgs, expanded)...)
+	optional = 0
 	var missing []string
 	for _, imp := range implied {
-		if _, ok := tree[imp.Identifier()]; ok {
-			installed--
-			missing = append(missing, imp.Identifier())
+		if _, ok := tree[imp.Identifier()]; !ok {
+			continue
 		}
+		if dep, ok := imp.(*v1beta1.Dependency); ok && dep.Optional && !required[dep.Identifier()] {
+			optional++
+			continue
+		}
+		missing = append(missing, imp.Identifier())
 	}
+	found = len(tree) - optional
+	installed = found - len(missing)
 	if len(missing) != 0 {
-		return found, installed, invalid, errors.Errorf(errMissingDependenciesFmt, missing)
+		return found, installed, invalid, optional, errors.Errorf(errMissingDependenciesFmt, missing)
 	}
 
 	// All of our dependencies and transitive dependencies must exist. Check
-	// that neighbors have valid versions.
-	var invalidDeps []string
-	for _, dep := range self.Dependencies {
+	// that neighbors are the type of package we declared, and have valid
+	// versions.
+	var invalidDeps, mismatched []string
+	var lps []*v1beta1.LockPackage
+	for _, dep := range expanded.Dependencies {
 		n, err := d.GetNode(dep.Package)
 		if err != nil {
-			return found, installed, invalid, errors.New(errDependencyNotInGraph)
+			return found, installed, invalid, optional, errors.New(errDependencyNotInGraph)
 		}
 		lp, ok := n.(*v1beta1.LockPackage)
+		if !ok && dep.Optional {
+			// A missing optional dependency has no version to check.
+			continue
+		}
 		if !ok {
-			return found, installed, invalid, errors.New(errDependencyNotLockPackage)
+			return found, installed, invalid, optional, errors.New(errDependencyNotLockPackage)
 		}
-		c, err := semver.NewConstraint(dep.Constraints)
+		if lp.Type != dep.Type {
+			mismatched = append(mismatched, fmt.Sprintf("%s (%s installed as %s)", lp.Identifier(), dep.Type, lp.Type))
+			continue
+		}
+		c, err := version.ParseConstraints(dep.Constraints)
 		if err != nil {
-			return found, installed, invalid, err
+			return found, installed, invalid, optional, err
 		}
 		v, err := semver.NewVersion(lp.Version)
 		if err != nil {
-			return found, installed, invalid, err
+			return found, installed, invalid, optional, err
 		}
-		if !c.Check(v) {
+		if !xpkg.Satisfies(c, pr.GetChannel(), v) {
 			invalidDeps = append(invalidDeps, lp.Identifier())
+			continue
 		}
+		lps = append(lps, lp)
+	}
+	invalid = len(invalidDeps) + len(mismatched)
+	if len(mismatched) > 0 {
+		return found, installed, invalid, optional, dependencyTypeMismatchError{errors.Errorf(errDependencyTypeMismatchFmt, mismatched)}
 	}
-	invalid = len(invalidDeps)
 	if invalid > 0 {
-		return found, installed, invalid, errors.Errorf(errIncompatibleDependencyFmt, invalidDeps)
+		return found, installed, invalid, optional, errors.Errorf(errIncompatibleDependencyFmt, invalidDeps)
+	}
+	if m.unhealthy != controller.UnhealthyDependencyPolicyReport && m.unhealthy != controller.UnhealthyDependencyPolicyBlock {
+		return found, installed, invalid, optional, nil
 	}
-	return found, installed, invalid, nil
+	unhealthy, err := m.unhealthyDependencies(ctx, lps)
+	if err != nil {
+		return found, installed, invalid, optional, err
+	}
+	if len(unhealthy) == 0 {
+		return found, installed, invalid, optional, nil
+	}
+	if m.unhealthy == controller.UnhealthyDependencyPolicyBlock {
+		installed -= len(unhealthy)
+	}
+	return found, installed, invalid, optional, degradedDependenciesError{errors.Errorf(errDegradedDependenciesFmt, unhealthy)}
+}
+
+// unhealthyDependencies returns the identifiers of the supplied dependencies
+// whose active revision is missing or not healthy.
+func (m *PackageDependencyManager) unhealthyDependencies(ctx context.Context, lps []*v1beta1.LockPackage) ([]string, error) {
+	var unhealthy []string
+	for _, lp := range lps {
+		// Packages recorded in the Lock by older versions of Crossplane
+		// may not name their revision.
+		if lp.Name == "" {
+			continue
+		}
+		var rev v1.PackageRevision = &v1.ProviderRevision{}
+		if lp.Type == v1beta1.ConfigurationPackageType {
+			rev = &v1.ConfigurationRevision{}
+		}
+		err := m.client.Get(ctx, types.NamespacedName{Name: lp.Name}, rev)
+		if kerrors.IsNotFound(err) {
+			unhealthy = append(unhealthy, lp.Identifier())
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, errGetDependencyRevision)
+		}
+		if rev.GetCondition(v1.TypeHealthy).Status != corev1.ConditionTrue {
+			unhealthy = append(unhealthy, lp.Identifier())
+		}
+	}
+	return unhealthy, nil
+}
+
+// resolveAlone records the supplied package, which has no dependencies, in a
+// Lock that contains at most that package. An inactive package is removed from
+// the Lock instead.
+func (m *PackageDependencyManager) resolveAlone(ctx context.Context, lock *v1beta1.Lock, self v1beta1.LockPackage, state v1.PackageRevisionDesiredState) error {
+	i := -1
+	for j := range lock.Packages {
+		if lock.Packages[j].Source == self.Source {
+			i = j
+		}
+	}
+	switch {
+	case state == v1.PackageRevisionInactive && i >= 0:
+		lock.Packages = append(lock.Packages[:i], lock.Packages[i+1:]...)
+	case state == v1.PackageRevisionInactive:
+		return nil
+	case i == -1:
+		lock.Packages = append(lock.Packages, self)
+	case lock.Packages[i].Resolved != self.Resolved:
+		lock.Packages[i].Resolved = self.Resolved
+	default:
+		return nil
+	}
+	return m.update(ctx, lock)
 }
 
 // RemoveSelf removes a package from the lock.
 func (m *PackageDependencyManager) RemoveSelf(ctx context.Context, pr v1.PackageRevision) error {
-	prRef, err := name.ParseReference(pr.GetSource(), name.WithDefaultRegistry(""))
+	prRef, err := xpkg.ParseSource(pr.GetSource(), "")
 	if err != nil {
 		return err
 	}
@@ -236,12 +503,89 @@ func (m *PackageDependencyManager) RemoveSelf(ctx context.Context, pr v1.Package
 	for i, lp := range lock.Packages {
 		if lp.Source == lockRef {
 			lock.Packages = append(lock.Packages[:i], lock.Packages[i+1:]...)
-			return m.client.Update(ctx, lock)
+			return m.update(ctx, lock)
 		}
 	}
 	return nil
 }
 
+// update the supplied Lock. Conflicts are recorded so that operators can tell
+// when many package revisions are contending for the Lock.
+func (m *PackageDependencyManager) update(ctx context.Context, lock *v1beta1.Lock) error {
+	err := m.client.Update(ctx, lock)
+	if kerrors.IsConflict(err) {
+		m.metrics.RecordLockConflict(string(m.packageType))
+		return lockConflictError{errors.Wrap(err, errLockConflict)}
+	}
+	return err
+}
+
+// requiredDependencies returns the identifiers of the dependencies that the
+// self package, or any package in its tree, does not declare to be optional.
+func requiredDependencies(tree map[string]dag.Node, self string, pkgs ...v1beta1.LockPackage) map[string]bool {
+	required := map[string]bool{}
+	for _, lp := range pkgs {
+		if _, ok := tree[lp.Identifier()]; !ok && lp.Identifier() != self {
+			continue
+		}
+		for _, dep := range lp.Dependencies {
+			if !dep.Optional {
+				required[dep.Identifier()] = true
+			}
+		}
+	}
+	return required
+}
+
 func intPointer(i int) *int {
 	return &i
 }
+
+// apiGroupProviders returns a dependency on the package that provides each API
+// group, keyed by API group. A package provides an API group if its active
+// revision defines a CRD or XRD in the group. The package that owns the
+// supplied revision is never considered to provide an API group.
+func (m *PackageDependencyManager) apiGroupProviders(ctx context.Context, pr v1.PackageRevision) (map[string]v1beta1.Dependency, error) {
+	self := ""
+	if ref, err := xpkg.ParseSource(pr.GetSource(), ""); err == nil {
+		self = xpkg.ParsePackageSourceFromReference(ref)
+	}
+
+	providers := map[string]v1beta1.Dependency{}
+	for _, rl := range []struct {
+		t v1beta1.PackageType
+		l v1.PackageRevisionList
+	}{
+		{t: v1beta1.ProviderPackageType, l: &v1.ProviderRevisionList{}},
+		{t: v1beta1.ConfigurationPackageType, l: &v1.ConfigurationRevisionList{}},
+	} {
+		if err := m.client.List(ctx, rl.l); err != nil {
+			return nil, errors.Wrap(err, errListRevisions)
+		}
+		for _, rev := range rl.l.GetRevisions() {
+			if rev.GetDesiredState() != v1.PackageRevisionActive {
+				continue
+			}
+			ref, err := xpkg.ParseSource(rev.GetSource(), "")
+			if err != nil {
+				continue
+			}
+			src := xpkg.ParsePackageSourceFromReference(ref)
+			if src == self {
+				continue
+			}
+			for _, o := range rev.GetObjects() {
+				if o.Kind != kindCRD && o.Kind != xpextv1.CompositeResourceDefinitionKind {
+					continue
+				}
+				// CRDs and XRDs are named <plural>.<group>.
+				parts := strings.SplitN(o.Name, ".", 2)
+				if len(parts) != 2 {
+					continue
+				}
+				providers[parts[1]] = v1beta1.Dependency{Package: src, Type: rl.t}
+			}
+		}
+	}
+	return providers, nil
+}
@@ -18,29 +18,36 @@ package revision
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
 	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
 	"github.com/crossplane/crossplane/internal/dag"
 	dagfake "github.com/crossplane/crossplane/internal/dag/fake"
+	"github.com/crossplane/crossplane/internal/metrics"
 )
 
 var _ DependencyManager = &PackageDependencyManager{}
 
 func TestResolve(t *testing.T) {
 	errBoom := errors.New("boom")
+	errExists := kerrors.NewAlreadyExists(schema.GroupResource{}, lockName)
+	errConflict := kerrors.NewConflict(schema.GroupResource{}, lockName, errBoom)
 
 	type args struct {
 		dep  *PackageDependencyManager
@@ -53,6 +60,7 @@ func TestResolve(t *testing.T) {
 		total     int
 		installed int
 		invalid   int
+		optional  int
 	}
 
 	cases := map[string]struct {
@@ -67,7 +75,7 @@ func TestResolve(t *testing.T) {
 				meta: &v1.Configuration{},
 			},
 			want: want{
-				err: errors.New(errNotMeta),
+				err: errors.Wrap(errors.New(`Configuration "" is not a known package meta type`), errNotMeta),
 			},
 		},
 		"ErrGetLock": {
@@ -104,6 +112,26 @@ func TestResolve(t *testing.T) {
 				err: errors.Wrap(errBoom, errGetOrCreateLock),
 			},
 		},
+		"ErrWaitingOnLock": {
+			reason: "Should return an error indicating we're waiting on the lock if it was created after we tried to get it.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+						MockCreate: test.NewMockCreateFn(errExists),
+					},
+				},
+				meta: &pkgmetav1.Configuration{},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(waitingOnLockError{errors.Wrap(errExists, errWaitingOnLock)}, errGetOrCreateLock),
+			},
+		},
 		"SuccessfulInactiveNoLock": {
 			reason: "Should not return error if we are inactive and lock does not exist.",
 			args: args{
@@ -137,7 +165,13 @@ func TestResolve(t *testing.T) {
 						}
 					},
 				},
-				meta: &pkgmetav1.Configuration{},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{{Provider: pointer.StringPtr("not-here-1"), Version: ">=v0.1.0"}},
+						},
+					},
+				},
 				pr: &v1.ConfigurationRevision{
 					Spec: v1.PackageRevisionSpec{
 						Package: "hasheddan/config-nop-a:v0.0.1",
@@ -145,7 +179,8 @@ func TestResolve(t *testing.T) {
 				},
 			},
 			want: want{
-				err: errBoom,
+				total: 1,
+				err:   errBoom,
 			},
 		},
 		"SuccessfulInactiveAlreadyRemoved": {
@@ -258,6 +293,48 @@ func TestResolve(t *testing.T) {
 				err: errBoom,
 			},
 		},
+		"ErrLockConflict": {
+			reason: "Should return an error indicating the lock was updated concurrently if our update conflicts.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							l := obj.(*v1beta1.Lock)
+							l.Packages = []v1beta1.LockPackage{
+								{
+									Source: "hasheddan/config-nop-a",
+								},
+							}
+							return nil
+						}),
+						MockUpdate: test.NewMockUpdateFn(errConflict),
+					},
+					metrics: metrics.NewNopLockRecorder(),
+					newDag: func() dag.DAG {
+						return &dagfake.MockDag{
+							MockInit: func(nodes []dag.Node, fns ...dag.NodeFn) ([]dag.Node, error) {
+								for i, n := range nodes {
+									for _, f := range fns {
+										f(i, n)
+									}
+								}
+								return nil, nil
+							},
+						}
+					},
+				},
+				meta: &pkgmetav1.Configuration{},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "hasheddan/config-nop-a:v0.0.1",
+						DesiredState: v1.PackageRevisionInactive,
+					},
+				},
+			},
+			want: want{
+				err: lockConflictError{errors.Wrap(errConflict, errLockConflict)},
+			},
+		},
 		"SuccessfulSelfExistNoDependencies": {
 			reason: "Should not return error if self exists and has no dependencies.",
 			args: args{
@@ -511,12 +588,14 @@ func TestResolve(t *testing.T) {
 								if s == "not-here-1" {
 									return &v1beta1.LockPackage{
 										Source:  "not-here-1",
+										Type:    v1beta1.ProviderPackageType,
 										Version: "v0.0.1",
 									}, nil
 								}
 								if s == "not-here-2" {
 									return &v1beta1.LockPackage{
 										Source:  "not-here-2",
+										Type:    v1beta1.ProviderPackageType,
 										Version: "v0.0.1",
 									}, nil
 								}
@@ -611,12 +690,14 @@ func TestResolve(t *testing.T) {
 								if s == "not-here-1" {
 									return &v1beta1.LockPackage{
 										Source:  "not-here-1",
+										Type:    v1beta1.ProviderPackageType,
 										Version: "v0.20.0",
 									}, nil
 								}
 								if s == "not-here-2" {
 									return &v1beta1.LockPackage{
 										Source:  "not-here-2",
+										Type:    v1beta1.ProviderPackageType,
 										Version: "v0.100.1",
 									}, nil
 								}
@@ -654,24 +735,1054 @@ func TestResolve(t *testing.T) {
 				invalid:   0,
 			},
 		},
-	}
-
-	for name, tc := range cases {
-		t.Run(name, func(t *testing.T) {
-			total, installed, invalid, err := tc.args.dep.Resolve(context.TODO(), tc.args.meta, tc.args.pr)
-
-			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
-				t.Errorf("\n%s\np.Resolve(...): -want error, +got error:\n%s", tc.reason, diff)
-			}
-			if diff := cmp.Diff(tc.want.total, total); diff != "" {
-				t.Errorf("\n%s\nTotal(...): -want, +got:\n%s", tc.reason, diff)
-			}
-			if diff := cmp.Diff(tc.want.installed, installed); diff != "" {
-				t.Errorf("\n%s\nInstalled(...): -want, +got:\n%s", tc.reason, diff)
-			}
-			if diff := cmp.Diff(tc.want.invalid, invalid); diff != "" {
-				t.Errorf("\n%s\nInvalid(...): -want, +got:\n%s", tc.reason, diff)
-			}
-		})
+		"SuccessfulHealthyDependencies": {
+			reason: "Should not return error if all dependencies are healthy.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+							switch o := obj.(type) {
+							case *v1beta1.Lock:
+								o.Packages = []v1beta1.LockPackage{
+									{
+										Source:  "hasheddan/config-nop-a",
+										Type:    v1beta1.ConfigurationPackageType,
+										Version: "v0.0.1",
+										Dependencies: []v1beta1.Dependency{
+											{
+												Package:     "hasheddan/provider-nop-a",
+												Type:        v1beta1.ProviderPackageType,
+												Constraints: ">=v0.1.0",
+											},
+										},
+									},
+									{
+										Name:    "provider-nop-a-1234",
+										Source:  "hasheddan/provider-nop-a",
+										Type:    v1beta1.ProviderPackageType,
+										Version: "v0.2.0",
+									},
+								}
+							case *v1.ProviderRevision:
+								o.SetConditions(v1.Healthy())
+							}
+							return nil
+						},
+						MockUpdate: test.NewMockUpdateFn(nil),
+					},
+					newDag:      dag.NewMapDag,
+					packageType: v1beta1.ConfigurationPackageType,
+					unhealthy:   controller.UnhealthyDependencyPolicyBlock,
+				},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{
+								{
+									Provider: pointer.StringPtr("hasheddan/provider-nop-a"),
+									Version:  ">=v0.1.0",
+								},
+							},
+						},
+					},
+				},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "hasheddan/config-nop-a:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				total:     1,
+				installed: 1,
+			},
+		},
+		"ErrReportUnhealthyDependencies": {
+			reason: "Should report, but still count as installed, a dependency whose revision is unhealthy if our policy is to report unhealthy dependencies.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+							switch o := obj.(type) {
+							case *v1beta1.Lock:
+								o.Packages = []v1beta1.LockPackage{
+									{
+										Source:  "hasheddan/config-nop-a",
+										Type:    v1beta1.ConfigurationPackageType,
+										Version: "v0.0.1",
+										Dependencies: []v1beta1.Dependency{
+											{
+												Package:     "hasheddan/provider-nop-a",
+												Type:        v1beta1.ProviderPackageType,
+												Constraints: ">=v0.1.0",
+											},
+										},
+									},
+									{
+										Name:    "provider-nop-a-1234",
+										Source:  "hasheddan/provider-nop-a",
+										Type:    v1beta1.ProviderPackageType,
+										Version: "v0.2.0",
+									},
+								}
+							case *v1.ProviderRevision:
+								o.SetConditions(v1.Unhealthy())
+							}
+							return nil
+						},
+						MockUpdate: test.NewMockUpdateFn(nil),
+					},
+					newDag:      dag.NewMapDag,
+					packageType: v1beta1.ConfigurationPackageType,
+					unhealthy:   controller.UnhealthyDependencyPolicyReport,
+				},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{
+								{
+									Provider: pointer.StringPtr("hasheddan/provider-nop-a"),
+									Version:  ">=v0.1.0",
+								},
+							},
+						},
+					},
+				},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "hasheddan/config-nop-a:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				err:       degradedDependenciesError{errors.Errorf(errDegradedDependenciesFmt, []string{"hasheddan/provider-nop-a"})},
+				total:     1,
+				installed: 1,
+			},
+		},
+		"ErrBlockMissingDependencyRevision": {
+			reason: "Should not count as installed a dependency whose revision doesn't exist if our policy is to block on unhealthy dependencies.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+							switch o := obj.(type) {
+							case *v1beta1.Lock:
+								o.Packages = []v1beta1.LockPackage{
+									{
+										Source:  "hasheddan/config-nop-a",
+										Type:    v1beta1.ConfigurationPackageType,
+										Version: "v0.0.1",
+										Dependencies: []v1beta1.Dependency{
+											{
+												Package:     "hasheddan/provider-nop-a",
+												Type:        v1beta1.ProviderPackageType,
+												Constraints: ">=v0.1.0",
+											},
+										},
+									},
+									{
+										Name:    "provider-nop-a-1234",
+										Source:  "hasheddan/provider-nop-a",
+										Type:    v1beta1.ProviderPackageType,
+										Version: "v0.2.0",
+									},
+								}
+							case *v1.ProviderRevision:
+								return kerrors.NewNotFound(schema.GroupResource{}, "provider-nop-a-1234")
+							}
+							return nil
+						},
+						MockUpdate: test.NewMockUpdateFn(nil),
+					},
+					newDag:      dag.NewMapDag,
+					packageType: v1beta1.ConfigurationPackageType,
+					unhealthy:   controller.UnhealthyDependencyPolicyBlock,
+				},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{
+								{
+									Provider: pointer.StringPtr("hasheddan/provider-nop-a"),
+									Version:  ">=v0.1.0",
+								},
+							},
+						},
+					},
+				},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "hasheddan/config-nop-a:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				err:       degradedDependenciesError{errors.Errorf(errDegradedDependenciesFmt, []string{"hasheddan/provider-nop-a"})},
+				total:     1,
+				installed: 0,
+			},
+		},
+		"ErrorSelfExistDependencyTypeMismatch": {
+			reason: "Should return error if self exists and a dependency is installed as a different type of package.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							l := obj.(*v1beta1.Lock)
+							l.Packages = []v1beta1.LockPackage{
+								{
+									Source: "hasheddan/config-nop-a",
+									Dependencies: []v1beta1.Dependency{
+										{
+											Package: "not-here-1",
+											Type:    v1beta1.ProviderPackageType,
+										},
+										{
+											Package: "not-here-2",
+											Type:    v1beta1.ConfigurationPackageType,
+										},
+									},
+								},
+								{
+									Source: "not-here-1",
+									Dependencies: []v1beta1.Dependency{
+										{
+											Package: "not-here-3",
+											Type:    v1beta1.ProviderPackageType,
+										},
+									},
+								},
+							}
+							return nil
+						}),
+						MockUpdate: test.NewMockUpdateFn(nil),
+					},
+					newDag: func() dag.DAG {
+						return &dagfake.MockDag{
+							MockInit: func(nodes []dag.Node, fns ...dag.NodeFn) ([]dag.Node, error) {
+								for i, n := range nodes {
+									for _, f := range fns {
+										f(i, n)
+									}
+								}
+								return nil, nil
+							},
+							MockTraceNode: func(_ string) (map[string]dag.Node, error) {
+								return map[string]dag.Node{
+									"not-here-1": &v1beta1.Dependency{},
+									"not-here-2": &v1beta1.Dependency{},
+									"not-here-3": &v1beta1.Dependency{},
+								}, nil
+							},
+							MockGetNode: func(s string) (dag.Node, error) {
+								if s == "not-here-1" {
+									return &v1beta1.LockPackage{
+										Source:  "not-here-1",
+										Type:    v1beta1.ProviderPackageType,
+										Version: "v0.20.0",
+									}, nil
+								}
+								if s == "not-here-2" {
+									return &v1beta1.LockPackage{
+										Source:  "not-here-2",
+										Type:    v1beta1.ConfigurationPackageType,
+										Version: "v0.100.1",
+									}, nil
+								}
+								return nil, nil
+							},
+						}
+					},
+				},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{
+								{
+									Provider: pointer.StringPtr("not-here-1"),
+									Version:  ">=v0.1.0",
+								},
+								{
+									Provider: pointer.StringPtr("not-here-2"),
+									Version:  ">=v0.1.0",
+								},
+							},
+						},
+					},
+				},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "hasheddan/config-nop-a:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				total:     3,
+				installed: 3,
+				invalid:   1,
+				err:       dependencyTypeMismatchError{errors.Errorf(errDependencyTypeMismatchFmt, []string{"not-here-2 (Provider installed as Configuration)"})},
+			},
+		},
+		"SuccessfulWildcardDependency": {
+			reason: "Should not return error if a wildcard dependency matches installed packages with valid versions.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							l := obj.(*v1beta1.Lock)
+							l.Packages = []v1beta1.LockPackage{
+								{
+									Source:  "example.org/provider-aws-s3",
+									Type:    v1beta1.ProviderPackageType,
+									Version: "v1.1.0",
+								},
+								{
+									Source:  "example.org/provider-aws-ec2",
+									Type:    v1beta1.ProviderPackageType,
+									Version: "v1.0.0",
+								},
+								{
+									Source:  "example.org/provider-gcp-storage",
+									Type:    v1beta1.ProviderPackageType,
+									Version: "v0.1.0",
+								},
+							}
+							return nil
+						}),
+						MockUpdate: test.NewMockUpdateFn(nil),
+					},
+					newDag: dag.NewMapDag,
+				},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{
+								{
+									Provider: pointer.StringPtr("example.org/provider-aws-*"),
+									Version:  ">=v1.0.0",
+								},
+							},
+						},
+					},
+				},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "example.org/config-aws:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				total:     2,
+				installed: 2,
+			},
+		},
+		"ErrorWildcardDependencyInvalidVersion": {
+			reason: "Should return error if any package matched by a wildcard dependency has an invalid version.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							l := obj.(*v1beta1.Lock)
+							l.Packages = []v1beta1.LockPackage{
+								{
+									Source:  "example.org/config-aws",
+									Type:    v1beta1.ConfigurationPackageType,
+									Version: "v0.0.1",
+									Dependencies: []v1beta1.Dependency{
+										{
+											Package:     "example.org/provider-aws-*",
+											Type:        v1beta1.ProviderPackageType,
+											Constraints: ">=v1.0.0",
+										},
+									},
+								},
+								{
+									Source:  "example.org/provider-aws-s3",
+									Type:    v1beta1.ProviderPackageType,
+									Version: "v1.1.0",
+								},
+								{
+									Source:  "example.org/provider-aws-ec2",
+									Type:    v1beta1.ProviderPackageType,
+									Version: "v0.5.0",
+								},
+							}
+							return nil
+						}),
+					},
+					newDag: dag.NewMapDag,
+				},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{
+								{
+									Provider: pointer.StringPtr("example.org/provider-aws-*"),
+									Version:  ">=v1.0.0",
+								},
+							},
+						},
+					},
+				},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "example.org/config-aws:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				total:     2,
+				installed: 2,
+				invalid:   1,
+				err:       errors.Errorf(errIncompatibleDependencyFmt, []string{"example.org/provider-aws-ec2"}),
+			},
+		},
+		"ErrorWildcardDependencyNoMatch": {
+			reason: "Should return error if a wildcard dependency matches no installed package.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							l := obj.(*v1beta1.Lock)
+							l.Packages = []v1beta1.LockPackage{
+								{
+									Source:  "example.org/provider-gcp-storage",
+									Type:    v1beta1.ProviderPackageType,
+									Version: "v1.0.0",
+								},
+							}
+							return nil
+						}),
+						MockUpdate: test.NewMockUpdateFn(nil),
+					},
+					newDag: dag.NewMapDag,
+				},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{
+								{
+									Provider: pointer.StringPtr("example.org/provider-aws-*"),
+									Version:  ">=v1.0.0",
+								},
+							},
+						},
+					},
+				},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "example.org/config-aws:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				total: 1,
+				err:   errors.Errorf(errMissingDependenciesFmt, []string{"example.org/provider-aws-*"}),
+			},
+		},
+		"SuccessfulAPIGroupDependency": {
+			reason: "Should not return error if an API group dependency is provided by an installed package with a valid version.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+							if l, ok := obj.(*v1.ProviderRevisionList); ok {
+								l.Items = []v1.ProviderRevision{
+									{
+										Spec: v1.PackageRevisionSpec{Package: "example.org/provider-aws-ec2:v0.9.0", DesiredState: v1.PackageRevisionInactive},
+										Status: v1.PackageRevisionStatus{ObjectRefs: []xpv1.TypedReference{
+											{Kind: kindCRD, Name: "instances.ec2.aws.example.org"},
+										}},
+									},
+									{
+										Spec: v1.PackageRevisionSpec{Package: "example.org/provider-aws-ec2:v1.0.0", DesiredState: v1.PackageRevisionActive},
+										Status: v1.PackageRevisionStatus{ObjectRefs: []xpv1.TypedReference{
+											{Kind: kindCRD, Name: "instances.ec2.aws.example.org"},
+										}},
+									},
+								}
+							}
+							return nil
+						},
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							l := obj.(*v1beta1.Lock)
+							l.Packages = []v1beta1.LockPackage{
+								{
+									Source:  "example.org/provider-aws-ec2",
+									Type:    v1beta1.ProviderPackageType,
+									Version: "v1.0.0",
+								},
+							}
+							return nil
+						}),
+						MockUpdate: test.NewMockUpdateFn(nil),
+					},
+					newDag: dag.NewMapDag,
+				},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{
+								{
+									APIGroup: pointer.StringPtr("ec2.aws.example.org"),
+									Version:  ">=v1.0.0",
+								},
+							},
+						},
+					},
+				},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "example.org/config-aws:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				total:     1,
+				installed: 1,
+			},
+		},
+		"ErrorAPIGroupDependencyNotProvided": {
+			reason: "Should return error if no installed package provides an API group dependency.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+							if l, ok := obj.(*v1.ProviderRevisionList); ok {
+								l.Items = []v1.ProviderRevision{
+									{
+										Spec: v1.PackageRevisionSpec{Package: "example.org/provider-aws:v1.0.0", DesiredState: v1.PackageRevisionActive},
+										Status: v1.PackageRevisionStatus{ObjectRefs: []xpv1.TypedReference{
+											{Kind: kindCRD, Name: "providerconfigs.aws.example.org"},
+										}},
+									},
+								}
+							}
+							return nil
+						},
+						MockGet:    test.NewMockGetFn(nil),
+						MockUpdate: test.NewMockUpdateFn(nil),
+					},
+					newDag: dag.NewMapDag,
+				},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{
+								{
+									APIGroup: pointer.StringPtr("ec2.aws.example.org"),
+									Version:  ">=v1.0.0",
+								},
+							},
+						},
+					},
+				},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "example.org/config-aws:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				total: 1,
+				err:   errors.Errorf(errMissingAPIGroupsFmt, []string{"ec2.aws.example.org"}),
+			},
+		},
+		"ErrListRevisions": {
+			reason: "Should return error if we can't list the package revisions that might provide an API group dependency.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockList: test.NewMockListFn(errBoom),
+					},
+				},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{
+								{
+									APIGroup: pointer.StringPtr("ec2.aws.example.org"),
+									Version:  ">=v1.0.0",
+								},
+							},
+						},
+					},
+				},
+				pr: &v1.ConfigurationRevision{},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errListRevisions),
+			},
+		},
+		"SuccessfulSelfNotExistMissingOptionalDependency": {
+			reason: "Should not return error if self does not exist and only optional dependencies are missing.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							l := obj.(*v1beta1.Lock)
+							l.Packages = []v1beta1.LockPackage{
+								{
+									Source:  "example.org/provider-a",
+									Type:    v1beta1.ProviderPackageType,
+									Version: "v1.0.0",
+								},
+							}
+							return nil
+						}),
+						MockUpdate: test.NewMockUpdateFn(nil),
+					},
+					newDag: dag.NewMapDag,
+				},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{
+								{
+									Provider: pointer.StringPtr("example.org/provider-a"),
+									Version:  ">=v1.0.0",
+								},
+								{
+									Provider: pointer.StringPtr("example.org/provider-b"),
+									Version:  ">=v1.0.0",
+									Optional: true,
+								},
+							},
+						},
+					},
+				},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "example.org/config-a:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				total:     1,
+				installed: 1,
+				optional:  1,
+			},
+		},
+		"SuccessfulSelfExistMissingTransitiveOptionalDependency": {
+			reason: "Should not return error if self exists and only transitive optional dependencies are missing.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							l := obj.(*v1beta1.Lock)
+							l.Packages = []v1beta1.LockPackage{
+								{
+									Source:  "example.org/config-a",
+									Type:    v1beta1.ConfigurationPackageType,
+									Version: "v1.0.0",
+									Dependencies: []v1beta1.Dependency{
+										{
+											Package:     "example.org/config-b",
+											Type:        v1beta1.ConfigurationPackageType,
+											Constraints: ">=v1.0.0",
+										},
+									},
+								},
+								{
+									Source:  "example.org/config-b",
+									Type:    v1beta1.ConfigurationPackageType,
+									Version: "v1.0.0",
+									Dependencies: []v1beta1.Dependency{
+										{
+											Package:     "example.org/provider-c",
+											Type:        v1beta1.ProviderPackageType,
+											Constraints: ">=v1.0.0",
+											Optional:    true,
+										},
+									},
+								},
+							}
+							return nil
+						}),
+					},
+					newDag: dag.NewMapDag,
+				},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{
+								{
+									Configuration: pointer.StringPtr("example.org/config-b"),
+									Version:       ">=v1.0.0",
+								},
+							},
+						},
+					},
+				},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "example.org/config-a:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				total:     1,
+				installed: 1,
+				optional:  1,
+			},
+		},
+		"ErrorSelfExistOptionalDependencyRequiredTransitively": {
+			reason: "Should return error if an optional dependency is missing and another package in our tree requires it.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							l := obj.(*v1beta1.Lock)
+							l.Packages = []v1beta1.LockPackage{
+								{
+									Source:  "example.org/config-a",
+									Type:    v1beta1.ConfigurationPackageType,
+									Version: "v1.0.0",
+									Dependencies: []v1beta1.Dependency{
+										{
+											Package:     "example.org/config-b",
+											Type:        v1beta1.ConfigurationPackageType,
+											Constraints: ">=v1.0.0",
+										},
+										{
+											Package:     "example.org/provider-c",
+											Type:        v1beta1.ProviderPackageType,
+											Constraints: ">=v1.0.0",
+											Optional:    true,
+										},
+									},
+								},
+								{
+									Source:  "example.org/config-b",
+									Type:    v1beta1.ConfigurationPackageType,
+									Version: "v1.0.0",
+									Dependencies: []v1beta1.Dependency{
+										{
+											Package:     "example.org/provider-c",
+											Type:        v1beta1.ProviderPackageType,
+											Constraints: ">=v1.0.0",
+										},
+									},
+								},
+							}
+							return nil
+						}),
+					},
+					newDag: dag.NewMapDag,
+				},
+				meta: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{
+							DependsOn: []pkgmetav1.Dependency{
+								{
+									Configuration: pointer.StringPtr("example.org/config-b"),
+									Version:       ">=v1.0.0",
+								},
+								{
+									Provider: pointer.StringPtr("example.org/provider-c"),
+									Version:  ">=v1.0.0",
+									Optional: true,
+								},
+							},
+						},
+					},
+				},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "example.org/config-a:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				total:     2,
+				installed: 1,
+				err:       errors.Errorf(errMissingDependenciesFmt, []string{"example.org/provider-c"}),
+			},
+		},
+		"SuccessfulSelfNotExistResolved": {
+			reason: "Should record that we were installed to satisfy a dependency when adding self to the lock.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil),
+						MockUpdate: test.NewMockUpdateFn(nil, func(obj client.Object) error {
+							l := obj.(*v1beta1.Lock)
+							if len(l.Packages) != 1 || !l.Packages[0].Resolved {
+								return errBoom
+							}
+							return nil
+						}),
+					},
+					newDag: dag.NewMapDag,
+				},
+				meta: &pkgmetav1.Configuration{},
+				pr: &v1.ConfigurationRevision{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: map[string]string{v1.LabelResolvedDependency: "true"},
+					},
+					Spec: v1.PackageRevisionSpec{
+						Package:      "example.org/config-a:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{},
+		},
+		"SuccessfulSelfExistUpdateResolved": {
+			reason: "Should update the lock if whether we were installed to satisfy a dependency has changed.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							l := obj.(*v1beta1.Lock)
+							l.Packages = []v1beta1.LockPackage{
+								{
+									Source:   "example.org/config-a",
+									Type:     v1beta1.ConfigurationPackageType,
+									Version:  "v0.0.1",
+									Resolved: true,
+								},
+							}
+							return nil
+						}),
+						MockUpdate: test.NewMockUpdateFn(nil, func(obj client.Object) error {
+							l := obj.(*v1beta1.Lock)
+							if len(l.Packages) != 1 || l.Packages[0].Resolved {
+								return errBoom
+							}
+							return nil
+						}),
+					},
+					newDag: dag.NewMapDag,
+				},
+				meta: &pkgmetav1.Configuration{},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "example.org/config-a:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{},
+		},
+		"SuccessfulAloneInLock": {
+			reason: "Should not build a graph or update the lock if we have no dependencies and are already alone in the lock.",
+			args: args{
+				dep: &PackageDependencyManager{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							l := obj.(*v1beta1.Lock)
+							l.Packages = []v1beta1.LockPackage{
+								{
+									Source:  "example.org/config-a",
+									Type:    v1beta1.ConfigurationPackageType,
+									Version: "v0.0.1",
+								},
+							}
+							return nil
+						}),
+						MockUpdate: test.NewMockUpdateFn(errBoom),
+					},
+					newDag: func() dag.DAG {
+						t.Errorf("Resolve(...): should not build a graph for a package that is alone in the lock")
+						return dag.NewMapDag()
+					},
+				},
+				meta: &pkgmetav1.Configuration{},
+				pr: &v1.ConfigurationRevision{
+					Spec: v1.PackageRevisionSpec{
+						Package:      "example.org/config-a:v0.0.1",
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			total, installed, invalid, optional, err := tc.args.dep.Resolve(context.TODO(), tc.args.meta, tc.args.pr)
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\np.Resolve(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.total, total); diff != "" {
+				t.Errorf("\n%s\nTotal(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.installed, installed); diff != "" {
+				t.Errorf("\n%s\nInstalled(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.invalid, invalid); diff != "" {
+				t.Errorf("\n%s\nInvalid(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.optional, optional); diff != "" {
+				t.Errorf("\n%s\nOptional(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// Dimensions of the Lock used to benchmark dependency resolution. These are
+// intended to be representative of a large control plane.
+const (
+	scalePackages = 1000
+	scaleEdges    = 5
+)
+
+// newScaleLock returns a Lock with the supplied number of packages, each of
+// which depends on up to the supplied number of packages that precede it. The
+// resulting graph is acyclic, and the last package transitively depends on
+// every other package.
+func newScaleLock(packages, edges int) *v1beta1.Lock {
+	l := &v1beta1.Lock{Packages: make([]v1beta1.LockPackage, packages)}
+	for i := range l.Packages {
+		lp := v1beta1.LockPackage{
+			Name:    fmt.Sprintf("config-%d", i),
+			Type:    v1beta1.ConfigurationPackageType,
+			Source:  fmt.Sprintf("example.org/config-%d", i),
+			Version: "v1.0.0",
+		}
+		for j := 1; j <= edges && i-j >= 0; j++ {
+			lp.Dependencies = append(lp.Dependencies, v1beta1.Dependency{
+				Package:     fmt.Sprintf("example.org/config-%d", i-j),
+				Type:        v1beta1.ConfigurationPackageType,
+				Constraints: ">=v1.0.0",
+			})
+		}
+		l.Packages[i] = lp
+	}
+	return l
+}
+
+// newScaleManager returns a PackageDependencyManager that resolves against the
+// supplied Lock, and a revision and meta for the last package in that Lock.
+func newScaleManager(l *v1beta1.Lock) (*PackageDependencyManager, runtime.Object, v1.PackageRevision) {
+	m := &PackageDependencyManager{
+		client: &test.MockClient{
+			MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+				l.DeepCopyInto(obj.(*v1beta1.Lock))
+				return nil
+			}),
+			MockUpdate: test.NewMockUpdateFn(nil),
+		},
+		newDag:      dag.NewMapDag,
+		packageType: v1beta1.ConfigurationPackageType,
+	}
+
+	self := l.Packages[len(l.Packages)-1]
+	deps := make([]pkgmetav1.Dependency, len(self.Dependencies))
+	for i, d := range self.Dependencies {
+		deps[i] = pkgmetav1.Dependency{Configuration: pointer.StringPtr(d.Package), Version: d.Constraints}
+	}
+	meta := &pkgmetav1.Configuration{Spec: pkgmetav1.ConfigurationSpec{MetaSpec: pkgmetav1.MetaSpec{DependsOn: deps}}}
+	pr := &v1.ConfigurationRevision{
+		Spec: v1.PackageRevisionSpec{
+			Package:      self.Source + ":" + self.Version,
+			DesiredState: v1.PackageRevisionActive,
+		},
+	}
+	return m, meta, pr
+}
+
+func BenchmarkResolve(b *testing.B) {
+	m, meta, pr := newScaleManager(newScaleLock(scalePackages, scaleEdges))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, _, _, err := m.Resolve(context.Background(), meta, pr); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkResolveAlone compares resolving a package without dependencies that
+// is alone in the Lock, which needn't build a graph, to resolving one that
+// shares the Lock with an unrelated package.
+func BenchmarkResolveAlone(b *testing.B) {
+	cases := map[string]int{
+		"Alone":    1,
+		"NotAlone": 2,
+	}
+	for name, packages := range cases {
+		m, meta, pr := newScaleManager(newScaleLock(packages, 0))
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, _, _, _, err := m.Resolve(context.Background(), meta, pr); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// countingDAG is a DAG that counts the calls made to it that walk the graph.
+type countingDAG struct {
+	dag.DAG
+	traces int
+	gets   int
+}
+
+func (d *countingDAG) TraceNode(identifier string) (map[string]dag.Node, error) {
+	d.traces++
+	return d.DAG.TraceNode(identifier)
+}
+
+func (d *countingDAG) GetNode(identifier string) (dag.Node, error) {
+	d.gets++
+	return d.DAG.GetNode(identifier)
+}
+
+// TestResolveScale checks that resolving a package with many transitive
+// dependencies does a fixed amount of work per direct dependency, rather than
+// per package in the Lock. Run BenchmarkResolve to measure its performance.
+func TestResolveScale(t *testing.T) {
+	m, meta, pr := newScaleManager(newScaleLock(scalePackages, scaleEdges))
+
+	reads := 0
+	get := m.client.(*test.MockClient).MockGet
+	m.client.(*test.MockClient).MockGet = func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+		reads++
+		return get(ctx, key, obj)
+	}
+	d := &countingDAG{DAG: dag.NewMapDag()}
+	m.newDag = func() dag.DAG { return d }
+
+	found, installed, invalid, _, err := m.Resolve(context.Background(), meta, pr)
+	if err != nil {
+		t.Fatalf("Resolve(...): %s", err)
+	}
+	if diff := cmp.Diff(scalePackages-1, found); diff != "" {
+		t.Errorf("Resolve(...): -want found, +got found:\n%s", diff)
+	}
+	if diff := cmp.Diff(scalePackages-1, installed); diff != "" {
+		t.Errorf("Resolve(...): -want installed, +got installed:\n%s", diff)
+	}
+	if diff := cmp.Diff(0, invalid); diff != "" {
+		t.Errorf("Resolve(...): -want invalid, +got invalid:\n%s", diff)
+	}
+	if diff := cmp.Diff(1, reads); diff != "" {
+		t.Errorf("Resolve(...): -want API reads, +got API reads:\n%s", diff)
+	}
+	if diff := cmp.Diff(1, d.traces); diff != "" {
+		t.Errorf("Resolve(...): -want graph traces, +got graph traces:\n%s", diff)
+	}
+	if diff := cmp.Diff(scaleEdges, d.gets); diff != "" {
+		t.Errorf("Resolve(...): -want graph node lookups, +got graph node lookups:\n%s", diff)
 	}
 }
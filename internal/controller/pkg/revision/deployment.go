@@ -22,6 +22,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 
 	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
@@ -51,6 +52,15 @@ const (
 	webhookPort             = 9443
 )
 
+const (
+	errFmtUnknownStrategyType  = "unknown deployment strategy type %q"
+	errRecreateRollingUpdate   = "rollingUpdate may not be specified when the deployment strategy type is Recreate"
+	errFmtInvalidRollingValue  = "invalid %s"
+	errFmtNegativeRollingValue = "%s must not be negative"
+	errMaxUnavailableOver100   = "maxUnavailable must not be more than 100%"
+	errZeroSurgeUnavailable    = "maxSurge and maxUnavailable must not both be 0"
+)
+
 func buildProviderDeployment(provider *pkgmetav1.Provider, revision v1.PackageRevision, cc *v1alpha1.ControllerConfig, namespace string) (*corev1.ServiceAccount, *appsv1.Deployment, *corev1.Service) { // nolint:gocyclo
 	s := &corev1.ServiceAccount{
 		ObjectMeta: metav1.ObjectMeta{
@@ -164,7 +174,7 @@ func buildProviderDeployment(provider *pkgmetav1.Provider, revision v1.PackageRe
 
 		port := corev1.ContainerPort{
 			Name:          webhookPortName,
-			ContainerPort: webhookPort,
+			ContainerPort: providerWebhookPort(provider),
 		}
 		d.Spec.Template.Spec.Containers[0].Ports = append(d.Spec.Template.Spec.Containers[0].Ports,
 			port)
@@ -188,6 +198,9 @@ func buildProviderDeployment(provider *pkgmetav1.Provider, revision v1.PackageRe
 		if cc.Spec.Replicas != nil {
 			d.Spec.Replicas = cc.Spec.Replicas
 		}
+		if cc.Spec.Strategy != nil {
+			d.Spec.Strategy = *cc.Spec.Strategy
+		}
 		if cc.Spec.Image != nil {
 			d.Spec.Template.Spec.Containers[0].Image = *cc.Spec.Image
 		}
@@ -261,11 +274,73 @@ func buildProviderDeployment(provider *pkgmetav1.Provider, revision v1.PackageRe
 			Ports: []corev1.ServicePort{
 				{
 					Protocol:   corev1.ProtocolTCP,
-					Port:       9443,
-					TargetPort: intstr.FromInt(9443),
+					Port:       webhookPort,
+					TargetPort: intstr.FromInt(int(providerWebhookPort(provider))),
 				},
 			},
 		},
 	}
 	return s, d, svc
 }
+
+// providerWebhookPort returns the port on which the supplied provider's
+// controller serves webhooks.
+func providerWebhookPort(provider *pkgmetav1.Provider) int32 {
+	if w := provider.Spec.Controller.Webhooks; w != nil && w.Port != nil {
+		return *w.Port
+	}
+	return webhookPort
+}
+
+// validateDeploymentStrategy returns an error if the supplied strategy would be
+// rejected by the API server, or would stop the deployment from ever rolling
+// out new pods.
+func validateDeploymentStrategy(s appsv1.DeploymentStrategy) error {
+	switch s.Type {
+	case "", appsv1.RollingUpdateDeploymentStrategyType:
+	case appsv1.RecreateDeploymentStrategyType:
+		if s.RollingUpdate != nil {
+			return errors.New(errRecreateRollingUpdate)
+		}
+		return nil
+	default:
+		return errors.Errorf(errFmtUnknownStrategyType, s.Type)
+	}
+	if s.RollingUpdate == nil {
+		return nil
+	}
+
+	// The API server defaults whichever of maxSurge and maxUnavailable is
+	// omitted to 25%, so only explicit values can both be zero.
+	surge, err := rollingValue("maxSurge", s.RollingUpdate.MaxSurge)
+	if err != nil {
+		return err
+	}
+	unavailable, err := rollingValue("maxUnavailable", s.RollingUpdate.MaxUnavailable)
+	if err != nil {
+		return err
+	}
+	if v := s.RollingUpdate.MaxUnavailable; v != nil && v.Type == intstr.String && unavailable > 100 {
+		return errors.New(errMaxUnavailableOver100)
+	}
+	if s.RollingUpdate.MaxSurge != nil && s.RollingUpdate.MaxUnavailable != nil && surge == 0 && unavailable == 0 {
+		return errors.New(errZeroSurgeUnavailable)
+	}
+	return nil
+}
+
+// rollingValue returns the supplied maxSurge or maxUnavailable value, as a
+// percentage if it is one. Omitted values are returned as -1.
+func rollingValue(field string, v *intstr.IntOrString) (int, error) {
+	if v == nil {
+		return -1, nil
+	}
+	i, err := intstr.GetScaledValueFromIntOrPercent(v, 100, true)
+	if err != nil {
+		return 0, errors.Wrapf(err, errFmtInvalidRollingValue, field)
+	}
+	if i < 0 {
+		return 0, errors.Errorf(errFmtNegativeRollingValue, field)
+	}
+	return i, nil
+}
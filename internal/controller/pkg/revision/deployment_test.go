@@ -25,8 +25,11 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
@@ -59,6 +62,12 @@ func withAdditionalEnvVar(env corev1.EnvVar) deploymentModifier {
 	}
 }
 
+func withStrategy(s appsv1.DeploymentStrategy) deploymentModifier {
+	return func(d *appsv1.Deployment) {
+		d.Spec.Strategy = s
+	}
+}
+
 func withAdditionalPort(port corev1.ContainerPort) deploymentModifier {
 	return func(d *appsv1.Deployment) {
 		d.Spec.Template.Spec.Containers[0].Ports = append(d.Spec.Template.Spec.Containers[0].Ports, port)
@@ -203,6 +212,18 @@ func TestBuildProviderDeployment(t *testing.T) {
 		},
 	}
 
+	providerWithWebhookPort := &pkgmetav1.Provider{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pkg",
+		},
+		Spec: pkgmetav1.ProviderSpec{
+			Controller: pkgmetav1.ControllerSpec{
+				Image:    &img,
+				Webhooks: &pkgmetav1.WebhookSpec{Port: pointer.Int32(9000)},
+			},
+		},
+	}
+
 	revisionWithoutCC := &v1.ProviderRevision{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: "rev-123",
@@ -237,6 +258,15 @@ func TestBuildProviderDeployment(t *testing.T) {
 		},
 	}
 
+	ccRecreate := &v1alpha1.ControllerConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: revisionWithCC.Name,
+		},
+		Spec: v1alpha1.ControllerConfigSpec{
+			Strategy: &appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+		},
+	}
+
 	cc := &v1alpha1.ControllerConfig{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: revisionWithCC.Name,
@@ -251,6 +281,9 @@ func TestBuildProviderDeployment(t *testing.T) {
 		},
 	}
 
+	svcWithWebhookPort := service(providerWithWebhookPort, revisionWithoutCCWithWebhook)
+	svcWithWebhookPort.Spec.Ports[0].TargetPort = intstr.FromInt(9000)
+
 	cases := map[string]struct {
 		reason string
 		fields args
@@ -302,6 +335,39 @@ func TestBuildProviderDeployment(t *testing.T) {
 				svc: service(providerWithImage, revisionWithoutCCWithWebhook),
 			},
 		},
+		"ImgNoCCWithWebhookPort": {
+			reason: "If the meta provider declares a webhook port, the deployment should serve webhooks on it behind the given service.",
+			fields: args{
+				provider: providerWithWebhookPort,
+				revision: revisionWithoutCCWithWebhook,
+				cc:       nil,
+			},
+			want: want{
+				sa: serviceaccount(revisionWithoutCCWithWebhook),
+				d: deployment(providerWithWebhookPort, revisionWithoutCCWithWebhook.GetName(), img,
+					withAdditionalVolume(corev1.Volume{
+						Name: webhookVolumeName,
+						VolumeSource: corev1.VolumeSource{
+							Secret: &corev1.SecretVolumeSource{
+								SecretName: webhookTLSSecretName,
+								Items: []corev1.KeyToPath{
+									{Key: "tls.crt", Path: "tls.crt"},
+									{Key: "tls.key", Path: "tls.key"},
+								},
+							},
+						},
+					}),
+					withAdditionalVolumeMount(corev1.VolumeMount{
+						Name:      webhookVolumeName,
+						ReadOnly:  true,
+						MountPath: webhookTLSCertDir,
+					}),
+					withAdditionalEnvVar(corev1.EnvVar{Name: webhookTLSCertDirEnvVar, Value: webhookTLSCertDir}),
+					withAdditionalPort(corev1.ContainerPort{Name: webhookPortName, ContainerPort: 9000}),
+				),
+				svc: svcWithWebhookPort,
+			},
+		},
 		"ImgNoCC": {
 			reason: "If the meta provider specifies a controller image and no ControllerConfig is reference, the specified image should be used.",
 			fields: args{
@@ -332,6 +398,19 @@ func TestBuildProviderDeployment(t *testing.T) {
 				svc: service(providerWithImage, revisionWithCC),
 			},
 		},
+		"StrategyCC": {
+			reason: "If a ControllerConfig is referenced and it specifies a deployment strategy it should be used.",
+			fields: args{
+				provider: providerWithImage,
+				revision: revisionWithCC,
+				cc:       ccRecreate,
+			},
+			want: want{
+				sa:  serviceaccount(revisionWithCC),
+				d:   deployment(providerWithImage, revisionWithCC.GetName(), img, withStrategy(appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType})),
+				svc: service(providerWithImage, revisionWithCC),
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -351,3 +430,91 @@ func TestBuildProviderDeployment(t *testing.T) {
 	}
 
 }
+
+func TestValidateDeploymentStrategy(t *testing.T) {
+	zero := intstr.FromInt(0)
+	one := intstr.FromInt(1)
+	cases := map[string]struct {
+		reason string
+		s      appsv1.DeploymentStrategy
+		want   error
+	}{
+		"Default": {
+			reason: "An unspecified strategy should be valid.",
+			s:      appsv1.DeploymentStrategy{},
+		},
+		"Recreate": {
+			reason: "A Recreate strategy should be valid.",
+			s:      appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+		},
+		"NoSurge": {
+			reason: "A RollingUpdate strategy that never surges should be valid.",
+			s: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &zero, MaxUnavailable: &one},
+			},
+		},
+		"OnlyZeroSurge": {
+			reason: "A RollingUpdate strategy that omits maxUnavailable should be valid, because it is defaulted to a non-zero value.",
+			s: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &zero},
+			},
+		},
+		"UnknownType": {
+			reason: "We should reject unknown strategy types.",
+			s:      appsv1.DeploymentStrategy{Type: "BlueGreen"},
+			want:   errors.Errorf(errFmtUnknownStrategyType, "BlueGreen"),
+		},
+		"RecreateRollingUpdate": {
+			reason: "We should reject a Recreate strategy that configures rolling updates.",
+			s: appsv1.DeploymentStrategy{
+				Type:          appsv1.RecreateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &zero},
+			},
+			want: errors.New(errRecreateRollingUpdate),
+		},
+		"NegativeSurge": {
+			reason: "We should reject a negative maxSurge.",
+			s: appsv1.DeploymentStrategy{
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: intstrPtr(intstr.FromInt(-1))},
+			},
+			want: errors.Errorf(errFmtNegativeRollingValue, "maxSurge"),
+		},
+		"InvalidPercent": {
+			reason: "We should reject a maxUnavailable that is neither an integer nor a percentage.",
+			s: appsv1.DeploymentStrategy{
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxUnavailable: intstrPtr(intstr.FromString("half"))},
+			},
+			want: errors.Wrapf(errors.New(`invalid value for IntOrString: invalid type: string is not a percentage`), errFmtInvalidRollingValue, "maxUnavailable"),
+		},
+		"UnavailableOver100": {
+			reason: "We should reject a maxUnavailable of more than 100%.",
+			s: appsv1.DeploymentStrategy{
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxUnavailable: intstrPtr(intstr.FromString("150%"))},
+			},
+			want: errors.New(errMaxUnavailableOver100),
+		},
+		"ZeroSurgeAndUnavailable": {
+			reason: "We should reject a strategy that could never roll out new pods.",
+			s: appsv1.DeploymentStrategy{
+				Type:          appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &zero, MaxUnavailable: intstrPtr(intstr.FromString("0%"))},
+			},
+			want: errors.New(errZeroSurgeUnavailable),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateDeploymentStrategy(tc.s)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nvalidateDeploymentStrategy(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func intstrPtr(v intstr.IntOrString) *intstr.IntOrString {
+	return &v
+}
@@ -18,15 +18,20 @@ package revision
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"strings"
+	"sync"
 
 	admv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -36,7 +41,9 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
+	xpextv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
 )
 
 const (
@@ -45,11 +52,31 @@ const (
 	errConversionWithNoWebhookCA    = "cannot deploy a CRD with webhook conversion strategy without having a TLS bundle"
 	errGetWebhookTLSSecret          = "cannot get webhook tls secret"
 	errWebhookSecretWithoutCABundle = "the value for the key tls.crt cannot be empty"
+
+	errFmtEstablish        = "cannot establish %d resources: %s"
+	errFmtResourceConflict = "%s %q cannot define %s, which %s %q already defines"
 )
 
+// A resourceConflictError indicates that a resource can't be established
+// because it is controlled by a revision of an unrelated package.
+type resourceConflictError struct{ error }
+
+func (e resourceConflictError) Unwrap() error { return e.error }
+
+// IsResourceConflict returns true if the supplied error indicates that two
+// unrelated packages define the same resource, for example the same CRD. An
+// error that aggregates the errors encountered establishing a set of resources
+// is a resource conflict if any of them is.
+func IsResourceConflict(err error) bool {
+	return anyEstablishError(err, func(err error) bool {
+		return errors.As(err, &resourceConflictError{})
+	})
+}
+
 // An Establisher establishes control or ownership of a set of resources in the
 // API server by checking that control or ownership can be established for all
-// resources and then establishing it.
+// resources and then establishing it. It returns references to the resources
+// it established, even if it fails to establish some of them.
 type Establisher interface {
 	Establish(ctx context.Context, objects []runtime.Object, parent v1.PackageRevision, control bool) ([]xpv1.TypedReference, error)
 }
@@ -68,18 +95,59 @@ func (*NopEstablisher) Establish(_ context.Context, _ []runtime.Object, _ v1.Pac
 }
 
 // APIEstablisher establishes control or ownership of resources in the API
-// server for a parent.
+// server for a parent. It uses server-side apply, so fields of a resource that
+// were set by other field managers, such as users or admission controllers, are
+// preserved unless the parent's resource also sets them.
 type APIEstablisher struct {
-	client    client.Client
-	namespace string
+	client      client.Client
+	namespace   string
+	conflicts   controller.ApplyConflictPolicy
+	concurrency int
+	progress    ProgressReporter
+}
+
+// An EstablisherOption configures an APIEstablisher.
+type EstablisherOption func(e *APIEstablisher)
+
+// WithApplyConflictPolicy specifies what an APIEstablisher should do when
+// establishing control of a resource would change fields that were set by
+// another field manager. Conflicting fields are overwritten by default.
+func WithApplyConflictPolicy(p controller.ApplyConflictPolicy) EstablisherOption {
+	return func(e *APIEstablisher) {
+		e.conflicts = p
+	}
+}
+
+// WithMaxConcurrency specifies the maximum number of resources an
+// APIEstablisher checks or establishes at the same time. Resources are
+// established one at a time by default.
+func WithMaxConcurrency(n int) EstablisherOption {
+	return func(e *APIEstablisher) {
+		e.concurrency = n
+	}
+}
+
+// WithEstablishProgressReporter specifies how an APIEstablisher should report
+// how many resources it has established while it establishes them. Progress is
+// not reported by default.
+func WithEstablishProgressReporter(p ProgressReporter) EstablisherOption {
+	return func(e *APIEstablisher) {
+		e.progress = p
+	}
 }
 
 // NewAPIEstablisher creates a new APIEstablisher.
-func NewAPIEstablisher(client client.Client, namespace string) *APIEstablisher {
-	return &APIEstablisher{
-		client:    client,
-		namespace: namespace,
+func NewAPIEstablisher(client client.Client, namespace string, opts ...EstablisherOption) *APIEstablisher {
+	e := &APIEstablisher{
+		client:      client,
+		namespace:   namespace,
+		conflicts:   controller.ApplyConflictPolicyForce,
+		concurrency: 1,
+	}
+	for _, o := range opts {
+		o(e)
 	}
+	return e
 }
 
 // currentDesired caches resources while checking for control or ownership so
@@ -89,13 +157,21 @@ type currentDesired struct {
 	Current resource.Object
 	Desired resource.Object
 	Exists  bool
+
+	// Established is true if the parent already established control of the
+	// current resource, applying the desired resource.
+	Established bool
 }
 
 // Establish checks that control or ownership of resources can be established by
-// parent, then establishes it.
-func (e *APIEstablisher) Establish(ctx context.Context, objs []runtime.Object, parent v1.PackageRevision, control bool) ([]xpv1.TypedReference, error) { // nolint:gocyclo
-	allObjs := []currentDesired{}
-	resourceRefs := []xpv1.TypedReference{}
+// parent, then establishes it. Resources are checked and established
+// concurrently, up to the APIEstablisher's maximum concurrency. No resource is
+// established unless control or ownership can be established for all of them.
+// A parent that isn't yet healthy, for example because it was interrupted while
+// it was being installed, skips resources it already established. References
+// to the resources that were established are returned even if establishing
+// others fails, so that installation can be resumed.
+func (e *APIEstablisher) Establish(ctx context.Context, objs []runtime.Object, parent v1.PackageRevision, control bool) ([]xpv1.TypedReference, error) {
 	var webhookTLSCert []byte
 	if parent.GetWebhookTLSSecretName() != nil {
 		s := &corev1.Secret{}
@@ -108,181 +184,482 @@ func (e *APIEstablisher) Establish(ctx context.Context, objs []runtime.Object, p
 		}
 		webhookTLSCert = s.Data["tls.crt"]
 	}
-	for _, res := range objs {
-		// Assert desired object to resource.Object so that we can access its
-		// metadata.
-		d, ok := res.(resource.Object)
-		if !ok {
-			return nil, errors.New(errAssertResourceObj)
-		}
 
-		// The generated webhook configurations have a static hard-coded name
-		// that the developers of the providers can't affect. Here, we make sure
-		// to distinguish one from the other by setting the name to the parent
-		// since there is always a single ValidatingWebhookConfiguration and/or
-		// single MutatingWebhookConfiguration object in a provider package.
-		// See https://github.com/kubernetes-sigs/controller-tools/issues/658
-		switch conf := res.(type) {
-		case *admv1.ValidatingWebhookConfiguration:
-			if len(webhookTLSCert) == 0 {
-				continue
+	allObjs := make([]*currentDesired, len(objs))
+	if err := e.forEach(len(objs), func(i int) error {
+		cd, err := e.check(ctx, objs[i], parent, control, webhookTLSCert)
+		allObjs[i] = cd
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	progress := e.newProgress(ctx, parent, len(objs))
+
+	established := make([]bool, len(allObjs))
+	err := e.forEach(len(allObjs), func(i int) error {
+		cd := allObjs[i]
+		if cd == nil {
+			return nil
+		}
+		var err error
+		switch {
+		case cd.Established:
+		case !cd.Exists:
+			// Only create a missing resource if we are going to control it.
+			// This prevents an inactive revision from racing to create a
+			// resource before an active revision of the same parent.
+			if control {
+				err = e.control(ctx, nil, cd.Desired, parent)
 			}
-			if pkgRef, ok := GetPackageOwnerReference(parent); ok {
-				conf.SetName(fmt.Sprintf("crossplane-%s-%s", strings.ToLower(pkgRef.Kind), pkgRef.Name))
+		default:
+			err = e.establish(ctx, cd.Current, cd.Desired, parent, control)
+		}
+		established[i] = err == nil
+		if err == nil {
+			progress.established()
+		}
+		return err
+	})
+	progress.done()
+
+	resourceRefs := []xpv1.TypedReference{}
+	for i, cd := range allObjs {
+		if cd == nil || !established[i] {
+			continue
+		}
+		resourceRefs = append(resourceRefs, *meta.TypedReferenceTo(cd.Desired, cd.Desired.GetObjectKind().GroupVersionKind()))
+	}
+	return resourceRefs, err
+}
+
+// newProgress returns an establishProgress that reports the progress of
+// establishing the supplied number of the supplied parent's resources.
+func (e *APIEstablisher) newProgress(ctx context.Context, parent v1.PackageRevision, total int) *establishProgress {
+	p := &establishProgress{ctx: ctx, parent: parent, total: total, reported: -1}
+	if e.progress != nil {
+		p.reporter = e.progress
+		p.report = parent.DeepCopyObject().(v1.PackageRevision)
+	}
+	return p
+}
+
+// An establishProgress counts the resources of a parent that have been
+// established, and reports the count as it crosses each tenth of the total, so
+// that establishing many resources doesn't cause as many status updates.
+//
+// Resources are established concurrently, and reference the parent while they
+// are, so progress is reported on a copy of the parent. Once all resources are
+// established the parent is updated with the reported progress and resource
+// version, so that its next update doesn't conflict.
+type establishProgress struct {
+	ctx      context.Context
+	parent   v1.PackageRevision
+	report   v1.PackageRevision
+	reporter ProgressReporter
+
+	mx       sync.Mutex
+	applied  int
+	total    int
+	reported int
+}
+
+// established records that a resource was established.
+func (p *establishProgress) established() {
+	if p.reporter == nil {
+		return
+	}
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.applied++
+
+	// The reconciler reports that all resources were established once
+	// they are.
+	tenth := p.applied * 10 / p.total
+	if p.applied == p.total || tenth <= p.reported {
+		return
+	}
+	p.reported = tenth
+
+	// Progress is informational, so we don't fail to establish resources
+	// if we can't report it.
+	_ = p.reporter.Report(p.ctx, p.report, v1.PackageRevisionProgress{
+		Phase:          v1.PackageRevisionEstablishing,
+		ObjectsApplied: int64(p.applied),
+		ObjectsTotal:   int64(p.total),
+	})
+}
+
+// done updates the parent with any reported progress.
+func (p *establishProgress) done() {
+	if p.reporter == nil || p.reported < 0 {
+		return
+	}
+	p.parent.SetProgress(p.report.GetProgress())
+	p.parent.SetResourceVersion(p.report.GetResourceVersion())
+}
+
+// check that control or ownership of the supplied resource can be established
+// by parent. It returns nil if the resource should not be established at all.
+func (e *APIEstablisher) check(ctx context.Context, res runtime.Object, parent v1.PackageRevision, control bool, webhookTLSCert []byte) (*currentDesired, error) { // nolint:gocyclo
+	// Assert desired object to resource.Object so that we can access its
+	// metadata.
+	d, ok := res.(resource.Object)
+	if !ok {
+		return nil, errors.New(errAssertResourceObj)
+	}
+
+	// The generated webhook configurations have a static hard-coded name
+	// that the developers of the providers can't affect. Here, we make sure
+	// to distinguish one from the other by setting the name to the parent
+	// since there is always a single ValidatingWebhookConfiguration and/or
+	// single MutatingWebhookConfiguration object in a provider package.
+	// See https://github.com/kubernetes-sigs/controller-tools/issues/658
+	switch conf := res.(type) {
+	case *admv1.ValidatingWebhookConfiguration:
+		if len(webhookTLSCert) == 0 {
+			return nil, nil
+		}
+		if pkgRef, ok := GetPackageOwnerReference(parent); ok {
+			conf.SetName(fmt.Sprintf("crossplane-%s-%s", strings.ToLower(pkgRef.Kind), pkgRef.Name))
+		}
+		for i := range conf.Webhooks {
+			conf.Webhooks[i].ClientConfig.CABundle = webhookTLSCert
+			if conf.Webhooks[i].ClientConfig.Service == nil {
+				conf.Webhooks[i].ClientConfig.Service = &admv1.ServiceReference{}
 			}
-			for i := range conf.Webhooks {
-				conf.Webhooks[i].ClientConfig.CABundle = webhookTLSCert
-				if conf.Webhooks[i].ClientConfig.Service == nil {
-					conf.Webhooks[i].ClientConfig.Service = &admv1.ServiceReference{}
-				}
-				conf.Webhooks[i].ClientConfig.Service.Name = parent.GetName()
-				conf.Webhooks[i].ClientConfig.Service.Namespace = e.namespace
-				conf.Webhooks[i].ClientConfig.Service.Port = pointer.Int32(webhookPort)
+			conf.Webhooks[i].ClientConfig.Service.Name = parent.GetName()
+			conf.Webhooks[i].ClientConfig.Service.Namespace = e.namespace
+			conf.Webhooks[i].ClientConfig.Service.Port = pointer.Int32(webhookPort)
+		}
+	case *admv1.MutatingWebhookConfiguration:
+		if len(webhookTLSCert) == 0 {
+			return nil, nil
+		}
+		if pkgRef, ok := GetPackageOwnerReference(parent); ok {
+			conf.SetName(fmt.Sprintf("crossplane-%s-%s", strings.ToLower(pkgRef.Kind), pkgRef.Name))
+		}
+		for i := range conf.Webhooks {
+			conf.Webhooks[i].ClientConfig.CABundle = webhookTLSCert
+			if conf.Webhooks[i].ClientConfig.Service == nil {
+				conf.Webhooks[i].ClientConfig.Service = &admv1.ServiceReference{}
 			}
-		case *admv1.MutatingWebhookConfiguration:
+			conf.Webhooks[i].ClientConfig.Service.Name = parent.GetName()
+			conf.Webhooks[i].ClientConfig.Service.Namespace = e.namespace
+			conf.Webhooks[i].ClientConfig.Service.Port = pointer.Int32(webhookPort)
+		}
+	case *extv1.CustomResourceDefinition:
+		if conf.Spec.Conversion != nil && conf.Spec.Conversion.Strategy == extv1.WebhookConverter {
 			if len(webhookTLSCert) == 0 {
-				continue
+				return nil, errors.New(errConversionWithNoWebhookCA)
 			}
-			if pkgRef, ok := GetPackageOwnerReference(parent); ok {
-				conf.SetName(fmt.Sprintf("crossplane-%s-%s", strings.ToLower(pkgRef.Kind), pkgRef.Name))
+			if conf.Spec.Conversion.Webhook == nil {
+				conf.Spec.Conversion.Webhook = &extv1.WebhookConversion{}
 			}
-			for i := range conf.Webhooks {
-				conf.Webhooks[i].ClientConfig.CABundle = webhookTLSCert
-				if conf.Webhooks[i].ClientConfig.Service == nil {
-					conf.Webhooks[i].ClientConfig.Service = &admv1.ServiceReference{}
-				}
-				conf.Webhooks[i].ClientConfig.Service.Name = parent.GetName()
-				conf.Webhooks[i].ClientConfig.Service.Namespace = e.namespace
-				conf.Webhooks[i].ClientConfig.Service.Port = pointer.Int32(webhookPort)
+			if conf.Spec.Conversion.Webhook.ClientConfig == nil {
+				conf.Spec.Conversion.Webhook.ClientConfig = &extv1.WebhookClientConfig{}
 			}
-		case *extv1.CustomResourceDefinition:
-			if conf.Spec.Conversion != nil && conf.Spec.Conversion.Strategy == extv1.WebhookConverter {
-				if len(webhookTLSCert) == 0 {
-					return nil, errors.New(errConversionWithNoWebhookCA)
-				}
-				if conf.Spec.Conversion.Webhook == nil {
-					conf.Spec.Conversion.Webhook = &extv1.WebhookConversion{}
-				}
-				if conf.Spec.Conversion.Webhook.ClientConfig == nil {
-					conf.Spec.Conversion.Webhook.ClientConfig = &extv1.WebhookClientConfig{}
-				}
-				if conf.Spec.Conversion.Webhook.ClientConfig.Service == nil {
-					conf.Spec.Conversion.Webhook.ClientConfig.Service = &extv1.ServiceReference{}
-				}
-				conf.Spec.Conversion.Webhook.ClientConfig.CABundle = webhookTLSCert
-				conf.Spec.Conversion.Webhook.ClientConfig.Service.Name = parent.GetName()
-				conf.Spec.Conversion.Webhook.ClientConfig.Service.Namespace = e.namespace
-				conf.Spec.Conversion.Webhook.ClientConfig.Service.Port = pointer.Int32(webhookPort)
+			if conf.Spec.Conversion.Webhook.ClientConfig.Service == nil {
+				conf.Spec.Conversion.Webhook.ClientConfig.Service = &extv1.ServiceReference{}
 			}
+			conf.Spec.Conversion.Webhook.ClientConfig.CABundle = webhookTLSCert
+			conf.Spec.Conversion.Webhook.ClientConfig.Service.Name = parent.GetName()
+			conf.Spec.Conversion.Webhook.ClientConfig.Service.Namespace = e.namespace
+			conf.Spec.Conversion.Webhook.ClientConfig.Service.Port = pointer.Int32(webhookPort)
 		}
+	}
 
-		// Make a copy of the desired object to be populated with existing
-		// object, if it exists.
-		copy := res.DeepCopyObject()
-		current, ok := copy.(client.Object)
-		if !ok {
-			return nil, errors.New(errAssertClientObj)
-		}
-		err := e.client.Get(ctx, types.NamespacedName{Name: d.GetName(), Namespace: d.GetNamespace()}, current)
-		if resource.IgnoreNotFound(err) != nil {
-			return nil, err
-		}
+	// Make a copy of the desired object to be populated with existing
+	// object, if it exists.
+	copy := res.DeepCopyObject()
+	current, ok := copy.(client.Object)
+	if !ok {
+		return nil, errors.New(errAssertClientObj)
+	}
+	err := e.client.Get(ctx, types.NamespacedName{Name: d.GetName(), Namespace: d.GetNamespace()}, current)
+	if resource.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
 
-		// If resource does not already exist, we must attempt to dry run create
-		// it.
-		if kerrors.IsNotFound(err) {
-			// Add to objects as not existing.
-			allObjs = append(allObjs, currentDesired{
-				Desired: d,
-				Current: nil,
-				Exists:  false,
-			})
-			// We will not create a resource if we are not going to control it,
-			// so we don't need to check with dry run.
-			if control {
-				if err := e.create(ctx, d, parent, client.DryRunAll); err != nil {
-					return nil, err
-				}
+	// If resource does not already exist, we must attempt to dry run create
+	// it.
+	if kerrors.IsNotFound(err) {
+		// We will not create a resource if we are not going to control it,
+		// so we don't need to check with dry run.
+		if control {
+			if err := e.control(ctx, nil, d, parent, client.DryRunAll); err != nil {
+				return nil, err
 			}
-			continue
 		}
+		return &currentDesired{Desired: d, Current: nil, Exists: false}, nil
+	}
 
-		c := current.(resource.Object)
-		// Add to objects as existing.
-		allObjs = append(allObjs, currentDesired{
-			Desired: d,
-			Current: c,
-			Exists:  true,
-		})
-
-		if err := e.update(ctx, c, d, parent, control, client.DryRunAll); err != nil {
+	c := current.(resource.Object)
+	if control && resuming(parent) {
+		// A resource we already established needn't be checked or
+		// established again.
+		ok, err := alreadyEstablished(c, d, parent)
+		if err != nil {
 			return nil, err
 		}
+		if ok {
+			return &currentDesired{Desired: d, Current: c, Exists: true, Established: true}, nil
+		}
+	}
+	if err := conflict(c, d, parent); err != nil {
+		return nil, err
 	}
+	if err := e.establish(ctx, c, d, parent, control, client.DryRunAll); err != nil {
+		return nil, err
+	}
+	return &currentDesired{Desired: d, Current: c, Exists: true}, nil
+}
 
-	for _, cd := range allObjs {
-		if !cd.Exists {
-			// Only create a missing resource if we are going to control it.
-			// This prevents an inactive revision from racing to create a
-			// resource before an active revision of the same parent.
-			if control {
-				if err := e.create(ctx, cd.Desired, parent); err != nil {
-					return nil, err
-				}
-			}
-			resourceRefs = append(resourceRefs, *meta.TypedReferenceTo(cd.Desired, cd.Desired.GetObjectKind().GroupVersionKind()))
-			continue
+// forEach calls fn for each index below n, running up to the APIEstablisher's
+// maximum concurrency calls at a time. It waits for all calls to return, then
+// returns their errors in index order, so that the error returned for a set of
+// resources doesn't depend on the order in which calls happened to finish.
+func (e *APIEstablisher) forEach(n int, fn func(i int) error) error {
+	limit := e.concurrency
+	if limit < 1 {
+		limit = 1
+	}
+
+	errs := make([]error, n)
+	sem := make(chan struct{}, limit)
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+
+	return aggregate(errs)
+}
+
+// establishErrors are the errors encountered establishing a set of resources,
+// in the order of the resources.
+type establishErrors []error
+
+// Error returns the messages of all errors, in order.
+func (e establishErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+	return fmt.Sprintf(errFmtEstablish, len(e), strings.Join(msgs, "; "))
+}
+
+// anyEstablishError returns true if the supplied function returns true for
+// the supplied error or, if it aggregates the errors encountered establishing
+// a set of resources, for any of those errors.
+func anyEstablishError(err error, fn func(error) bool) bool {
+	var errs establishErrors
+	if !errors.As(err, &errs) {
+		return fn(err)
+	}
+	for _, e := range errs {
+		if fn(e) {
+			return true
 		}
+	}
+	return false
+}
 
-		if err := e.update(ctx, cd.Current, cd.Desired, parent, control); err != nil {
-			return nil, err
+// aggregate the supplied errors, ignoring any that are nil. A single error is
+// returned as is.
+func aggregate(errs []error) error {
+	agg := establishErrors{}
+	for _, err := range errs {
+		if err != nil {
+			agg = append(agg, err)
 		}
-		resourceRefs = append(resourceRefs, *meta.TypedReferenceTo(cd.Desired, cd.Desired.GetObjectKind().GroupVersionKind()))
 	}
+	switch len(agg) {
+	case 0:
+		return nil
+	case 1:
+		return agg[0]
+	default:
+		return agg
+	}
+}
 
-	return resourceRefs, nil
+// establish control or ownership of an existing resource.
+func (e *APIEstablisher) establish(ctx context.Context, current, desired resource.Object, parent resource.Object, control bool, opts ...client.PatchOption) error {
+	if !control {
+		return e.own(ctx, desired, parent, opts...)
+	}
+	return e.control(ctx, current, desired, parent, opts...)
 }
 
-func (e *APIEstablisher) create(ctx context.Context, obj resource.Object, parent resource.Object, opts ...client.CreateOption) error {
-	refs := []metav1.OwnerReference{
-		meta.AsController(meta.TypedReferenceTo(parent, parent.GetObjectKind().GroupVersionKind())),
+// control applies the desired resource with the parent as its controller.
+func (e *APIEstablisher) control(ctx context.Context, current, desired resource.Object, parent resource.Object, opts ...client.PatchOption) error {
+	ref := meta.AsController(meta.TypedReferenceTo(parent, parent.GetObjectKind().GroupVersionKind()))
+
+	// We can't take control of a resource that is controlled by something
+	// else, for example another active revision.
+	if current != nil {
+		if err := meta.AddControllerReference(&metav1.ObjectMeta{Name: current.GetName(), OwnerReferences: current.GetOwnerReferences()}, ref); err != nil {
+			return err
+		}
+
+		// Upgrading the ownership of fields we set before we used
+		// server-side apply doesn't change the resource, so we do it even
+		// when we're only doing a dry run. Otherwise the dry run could
+		// report conflicts with our own client-side field manager.
+		if err := upgradeManagedFields(ctx, e.client, current, FieldManager); err != nil {
+			return err
+		}
 	}
+
+	o, err := applied(desired, parent)
+	if err != nil {
+		return err
+	}
+	return e.client.Patch(ctx, o, client.Apply, applyOptions(e.conflicts, FieldManager, opts...)...)
+}
+
+// applied returns the object that is applied to give the parent control of the
+// desired resource. It is annotated with a hash of itself.
+func applied(desired resource.Object, parent resource.Object) (client.Object, error) {
 	// We add the parent as `owner` of the resources so that the resource doesn't
 	// get deleted when the new revision doesn't include it in order not to lose
-	// user data, such as custom resources of an old CRD.
+	// user data, such as custom resources of an old CRD. Owner references that
+	// were set by other field managers, such as inactive revisions, are
+	// preserved by server-side apply.
+	refs := []metav1.OwnerReference{meta.AsController(meta.TypedReferenceTo(parent, parent.GetObjectKind().GroupVersionKind()))}
 	if pkgRef, ok := GetPackageOwnerReference(parent); ok {
 		pkgRef.Controller = pointer.BoolPtr(false)
 		refs = append(refs, pkgRef)
 	}
-	// Overwrite any owner references on the desired object.
-	obj.SetOwnerReferences(refs)
-	return e.client.Create(ctx, obj, opts...)
+	desired.SetOwnerReferences(refs)
+
+	// Applying updates the applied object with the result, which we don't
+	// want when we're only doing a dry run.
+	o, ok := desired.DeepCopyObject().(client.Object)
+	if !ok {
+		return nil, errors.New(errAssertClientObj)
+	}
+	o.SetResourceVersion("")
+	o.SetManagedFields(nil)
+	meta.RemoveAnnotations(o, v1.AnnotationEstablishedHash)
+
+	j, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	h := fnv.New64a()
+	h.Write(j) //nolint:errcheck // Writing to a hash never errors.
+	meta.AddAnnotations(o, map[string]string{v1.AnnotationEstablishedHash: fmt.Sprintf("%x", h.Sum64())})
+	return o, nil
 }
 
-func (e *APIEstablisher) update(ctx context.Context, current, desired resource.Object, parent resource.Object, control bool, opts ...client.UpdateOption) error {
-	// We add the parent as `owner` of the resources so that the resource doesn't
-	// get deleted when the new revision doesn't include it in order not to lose
-	// user data, such as custom resources of an old CRD.
+// resuming returns true if the supplied parent may be resuming its
+// installation. A healthy parent establishes all of its resources, so that any
+// that were changed out-of-band are repaired.
+func resuming(parent v1.PackageRevision) bool {
+	return parent.GetCondition(v1.TypeHealthy).Status != corev1.ConditionTrue
+}
+
+// alreadyEstablished returns true if the parent controls the current resource,
+// and last applied the desired resource to it.
+func alreadyEstablished(current, desired resource.Object, parent resource.Object) (bool, error) {
+	if c := metav1.GetControllerOf(current); c == nil || c.UID != parent.GetUID() {
+		return false, nil
+	}
+	o, err := applied(desired, parent)
+	if err != nil {
+		return false, err
+	}
+	h := current.GetAnnotations()[v1.AnnotationEstablishedHash]
+	return h != "" && h == o.GetAnnotations()[v1.AnnotationEstablishedHash], nil
+}
+
+// own applies only the parent's owner references to the desired resource. A
+// parent that doesn't control a resource uses its own field manager, so that
+// applying its owner references doesn't remove the fields the controlling
+// parent applied using FieldManager. It always takes ownership of its owner
+// references, which may previously have made it the controller.
+func (e *APIEstablisher) own(ctx context.Context, desired resource.Object, parent resource.Object, opts ...client.PatchOption) error {
+	refs := []metav1.OwnerReference{meta.AsOwner(meta.TypedReferenceTo(parent, parent.GetObjectKind().GroupVersionKind()))}
 	if pkgRef, ok := GetPackageOwnerReference(parent); ok {
 		pkgRef.Controller = pointer.BoolPtr(false)
-		meta.AddOwnerReference(current, pkgRef)
+		refs = append(refs, pkgRef)
 	}
 
-	if !control {
-		meta.AddOwnerReference(current, meta.AsOwner(meta.TypedReferenceTo(parent, parent.GetObjectKind().GroupVersionKind())))
-		return e.client.Update(ctx, current, opts...)
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(desired.GetObjectKind().GroupVersionKind())
+	o.SetName(desired.GetName())
+	o.SetNamespace(desired.GetNamespace())
+	o.SetOwnerReferences(refs)
+	return e.client.Patch(ctx, o, client.Apply, applyOptions(controller.ApplyConflictPolicyForce, FieldManager+"/"+parent.GetName(), opts...)...)
+}
+
+// conflict returns an error if the current resource is controlled by a revision
+// of a package other than the parent's. Resources controlled by another
+// revision of the parent's package are expected to change hands on upgrade.
+func conflict(current, desired resource.Object, parent resource.Object) error {
+	ctrl := metav1.GetControllerOf(current)
+	if ctrl == nil || ctrl.UID == parent.GetUID() || !isPackageGroup(ctrl.APIVersion) {
+		return nil
+	}
+	ours, ok := GetPackageOwnerReference(parent)
+	if !ok {
+		return nil
+	}
+	var theirs []metav1.OwnerReference
+	for _, ref := range current.GetOwnerReferences() {
+		if (ref.Kind != v1.ProviderKind && ref.Kind != v1.ConfigurationKind) || !isPackageGroup(ref.APIVersion) {
+			continue
+		}
+		if ref.UID == ours.UID {
+			return nil
+		}
+		theirs = append(theirs, ref)
 	}
+	if len(theirs) == 0 {
+		return nil
+	}
+	return resourceConflictError{errors.Errorf(errFmtResourceConflict, ours.Kind, ours.Name, strings.Join(defines(desired), ", "), theirs[0].Kind, theirs[0].Name)}
+}
 
-	// If desire is to control object, we attempt to update the object by
-	// setting the desired owner references equal to that of the current, adding
-	// a controller reference to the parent, and setting the desired resource
-	// version to that of the current.
-	desired.SetOwnerReferences(current.GetOwnerReferences())
-	if err := meta.AddControllerReference(desired, meta.AsController(meta.TypedReferenceTo(parent, parent.GetObjectKind().GroupVersionKind()))); err != nil {
-		return err
+// isPackageGroup returns true if the supplied API version is in the
+// pkg.crossplane.io API group.
+func isPackageGroup(apiVersion string) bool {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	return err == nil && gv.Group == v1.Group
+}
+
+// defines returns the kinds the supplied resource defines, for example each
+// version of a CRD's kind. Resources that don't define kinds are described by
+// their own kind and name.
+func defines(o resource.Object) []string {
+	switch d := o.(type) {
+	case *extv1.CustomResourceDefinition:
+		gvks := make([]string, 0, len(d.Spec.Versions))
+		for _, v := range d.Spec.Versions {
+			gvks = append(gvks, schema.GroupVersionKind{Group: d.Spec.Group, Version: v.Name, Kind: d.Spec.Names.Kind}.String())
+		}
+		return gvks
+	case *xpextv1.CompositeResourceDefinition:
+		gvks := make([]string, 0, 2*len(d.Spec.Versions))
+		for _, v := range d.Spec.Versions {
+			gvks = append(gvks, schema.GroupVersionKind{Group: d.Spec.Group, Version: v.Name, Kind: d.Spec.Names.Kind}.String())
+			if d.Spec.ClaimNames != nil {
+				gvks = append(gvks, schema.GroupVersionKind{Group: d.Spec.Group, Version: v.Name, Kind: d.Spec.ClaimNames.Kind}.String())
+			}
+		}
+		return gvks
 	}
-	desired.SetResourceVersion(current.GetResourceVersion())
-	return e.client.Update(ctx, desired, opts...)
+	return []string{fmt.Sprintf("%s %q", o.GetObjectKind().GroupVersionKind().Kind, o.GetName())}
 }
 
 // GetPackageOwnerReference returns the owner reference that points to the owner
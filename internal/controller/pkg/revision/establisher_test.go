@@ -18,6 +18,8 @@ package revision
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -26,16 +28,21 @@ import (
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
+
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
 )
 
 var _ Establisher = &APIEstablisher{}
@@ -45,6 +52,20 @@ func TestAPIEstablisherEstablish(t *testing.T) {
 	webhookTLSSecretName := "webhook-tls"
 	caBundle := []byte("CABUNDLE")
 
+	installing := &v1.ProviderRevision{ObjectMeta: metav1.ObjectMeta{Name: "test", UID: "test-uid"}}
+	healthy := installing.DeepCopy()
+	healthy.SetConditions(v1.Healthy())
+
+	// established returns a CRD as the installing parent applied it.
+	established := func(annotations map[string]string) func(o client.Object) error {
+		return func(o client.Object) error {
+			a, _ := applied(&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "ref-me"}}, installing)
+			a.(*extv1.CustomResourceDefinition).DeepCopyInto(o.(*extv1.CustomResourceDefinition))
+			meta.AddAnnotations(o, annotations)
+			return nil
+		}
+	}
+
 	type args struct {
 		est     *APIEstablisher
 		objs    []runtime.Object
@@ -67,8 +88,61 @@ func TestAPIEstablisherEstablish(t *testing.T) {
 			args: args{
 				est: &APIEstablisher{
 					client: &test.MockClient{
-						MockGet:    test.NewMockGetFn(nil),
-						MockUpdate: test.NewMockUpdateFn(nil),
+						MockGet:   test.NewMockGetFn(nil),
+						MockPatch: test.NewMockPatchFn(nil),
+					},
+				},
+				objs: []runtime.Object{
+					&extv1.CustomResourceDefinition{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "ref-me",
+						},
+					},
+				},
+				parent: &v1.ProviderRevision{
+					ObjectMeta: metav1.ObjectMeta{
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								Name: "provider-name",
+								UID:  "some-unique-uid-2312",
+							},
+						},
+						Labels: map[string]string{
+							v1.LabelParentPackage: "provider-name",
+						},
+					},
+				},
+				control: true,
+			},
+			want: want{
+				refs: []xpv1.TypedReference{{Name: "ref-me"}},
+			},
+		},
+		"UpgradeManagedFieldsEstablishControl": {
+			reason: "We should upgrade the ownership of fields we set before we used server-side apply when we establish control of an existing object.",
+			args: args{
+				est: &APIEstablisher{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+							o.SetManagedFields([]metav1.ManagedFieldsEntry{{
+								Manager:    clientSideFieldManager,
+								Operation:  metav1.ManagedFieldsOperationUpdate,
+								APIVersion: "apiextensions.k8s.io/v1",
+								FieldsType: "FieldsV1",
+								FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:group":{}}}`)},
+							}})
+							return nil
+						}),
+						MockPatch: func(_ context.Context, obj client.Object, p client.Patch, _ ...client.PatchOption) error {
+							if p == client.Apply {
+								return nil
+							}
+							b, _ := p.Data(obj)
+							if p.Type() != types.JSONPatchType || !strings.Contains(string(b), FieldManager) {
+								t.Errorf("Patch(...): want JSON patch upgrading managed fields to %q, got %s patch %s", FieldManager, p.Type(), b)
+							}
+							return nil
+						},
 					},
 				},
 				objs: []runtime.Object{
@@ -102,8 +176,8 @@ func TestAPIEstablisherEstablish(t *testing.T) {
 			args: args{
 				est: &APIEstablisher{
 					client: &test.MockClient{
-						MockGet:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
-						MockCreate: test.NewMockCreateFn(nil),
+						MockGet:   test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+						MockPatch: test.NewMockPatchFn(nil),
 					},
 				},
 				objs: []runtime.Object{
@@ -148,7 +222,7 @@ func TestAPIEstablisherEstablish(t *testing.T) {
 							}
 							return kerrors.NewNotFound(schema.GroupResource{}, "")
 						},
-						MockCreate: test.NewMockCreateFn(nil),
+						MockPatch: test.NewMockPatchFn(nil),
 					},
 				},
 				objs: []runtime.Object{
@@ -218,8 +292,8 @@ func TestAPIEstablisherEstablish(t *testing.T) {
 			args: args{
 				est: &APIEstablisher{
 					client: &test.MockClient{
-						MockGet:    test.NewMockGetFn(nil),
-						MockUpdate: test.NewMockUpdateFn(nil),
+						MockGet:   test.NewMockGetFn(nil),
+						MockPatch: test.NewMockPatchFn(nil),
 					},
 				},
 				objs: []runtime.Object{
@@ -241,8 +315,8 @@ func TestAPIEstablisherEstablish(t *testing.T) {
 			args: args{
 				est: &APIEstablisher{
 					client: &test.MockClient{
-						MockGet:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
-						MockCreate: test.NewMockCreateFn(errBoom),
+						MockGet:   test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+						MockPatch: test.NewMockPatchFn(errBoom),
 					},
 				},
 				objs: []runtime.Object{
@@ -264,8 +338,8 @@ func TestAPIEstablisherEstablish(t *testing.T) {
 			args: args{
 				est: &APIEstablisher{
 					client: &test.MockClient{
-						MockGet:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
-						MockCreate: test.NewMockCreateFn(nil),
+						MockGet:   test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+						MockPatch: test.NewMockPatchFn(nil),
 					},
 				},
 				objs: []runtime.Object{
@@ -340,12 +414,12 @@ func TestAPIEstablisherEstablish(t *testing.T) {
 			},
 		},
 		"FailedCreate": {
-			reason: "Cannot establish control of object if we cannot create it.",
+			reason: "Cannot establish control of object if we cannot apply it.",
 			args: args{
 				est: &APIEstablisher{
 					client: &test.MockClient{
-						MockGet:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
-						MockCreate: test.NewMockCreateFn(errBoom),
+						MockGet:   test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+						MockPatch: test.NewMockPatchFn(errBoom),
 					},
 				},
 				objs: []runtime.Object{
@@ -367,12 +441,12 @@ func TestAPIEstablisherEstablish(t *testing.T) {
 			},
 		},
 		"FailedUpdate": {
-			reason: "Cannot establish control of object if we cannot update it.",
+			reason: "Cannot establish control of existing object if we cannot apply it.",
 			args: args{
 				est: &APIEstablisher{
 					client: &test.MockClient{
-						MockGet:    test.NewMockGetFn(nil),
-						MockUpdate: test.NewMockUpdateFn(errBoom),
+						MockGet:   test.NewMockGetFn(nil),
+						MockPatch: test.NewMockPatchFn(errBoom),
 					},
 				},
 				objs: []runtime.Object{
@@ -393,6 +467,359 @@ func TestAPIEstablisherEstablish(t *testing.T) {
 				err: errBoom,
 			},
 		},
+		"ConflictPolicyFail": {
+			reason: "We should not take ownership of fields set by other field managers if our conflict policy is Fail.",
+			args: args{
+				est: NewAPIEstablisher(&test.MockClient{
+					MockGet: test.NewMockGetFn(nil),
+					MockPatch: func(_ context.Context, _ client.Object, p client.Patch, opts ...client.PatchOption) error {
+						po := &client.PatchOptions{}
+						po.ApplyOptions(opts)
+						if p != client.Apply || po.FieldManager != FieldManager || po.Force != nil {
+							t.Errorf("Patch(...): want server-side apply as %q without force, got patch type %s, options %+v", FieldManager, p.Type(), po)
+						}
+						return nil
+					},
+				}, "", WithApplyConflictPolicy(controller.ApplyConflictPolicyFail)),
+				objs: []runtime.Object{
+					&extv1.CustomResourceDefinition{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "ref-me",
+						},
+					},
+				},
+				parent:  &v1.ProviderRevision{},
+				control: true,
+			},
+			want: want{
+				refs: []xpv1.TypedReference{{Name: "ref-me"}},
+			},
+		},
+		"SuccessfulConcurrentEstablishControl": {
+			reason: "References to objects established concurrently should be returned in the order of the objects.",
+			args: args{
+				est: NewAPIEstablisher(&test.MockClient{
+					MockGet:   test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+					MockPatch: test.NewMockPatchFn(nil),
+				}, "", WithMaxConcurrency(2)),
+				objs: []runtime.Object{
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "c"}},
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "d"}},
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "e"}},
+				},
+				parent:  &v1.ProviderRevision{},
+				control: true,
+			},
+			want: want{
+				refs: []xpv1.TypedReference{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}},
+			},
+		},
+		"FailedConcurrentEstablishControl": {
+			reason: "Errors establishing objects concurrently should be returned in the order of the objects.",
+			args: args{
+				est: NewAPIEstablisher(&test.MockClient{
+					MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+					MockPatch: func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.PatchOption) error {
+						if obj.GetName() == "a" {
+							return nil
+						}
+						return errors.Wrap(errBoom, obj.GetName())
+					},
+				}, "", WithMaxConcurrency(3)),
+				objs: []runtime.Object{
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "c"}},
+				},
+				parent:  &v1.ProviderRevision{},
+				control: true,
+			},
+			want: want{
+				err: establishErrors{errors.Wrap(errBoom, "b"), errors.Wrap(errBoom, "c")},
+			},
+		},
+		"SkipAlreadyEstablished": {
+			reason: "A parent that is being installed should not establish objects it already established again.",
+			args: args{
+				est: NewAPIEstablisher(&test.MockClient{
+					MockGet:   test.NewMockGetFn(nil, established(nil)),
+					MockPatch: test.NewMockPatchFn(errBoom),
+				}, ""),
+				objs:    []runtime.Object{&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "ref-me"}}},
+				parent:  installing.DeepCopy(),
+				control: true,
+			},
+			want: want{
+				refs: []xpv1.TypedReference{{Name: "ref-me"}},
+			},
+		},
+		"ReestablishChanged": {
+			reason: "A parent that is being installed should establish objects it applied different content to.",
+			args: args{
+				est: NewAPIEstablisher(&test.MockClient{
+					MockGet:   test.NewMockGetFn(nil, established(map[string]string{v1.AnnotationEstablishedHash: "stale"})),
+					MockPatch: test.NewMockPatchFn(errBoom),
+				}, ""),
+				objs:    []runtime.Object{&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "ref-me"}}},
+				parent:  installing.DeepCopy(),
+				control: true,
+			},
+			want: want{
+				err: errBoom,
+			},
+		},
+		"ReestablishWhenHealthy": {
+			reason: "A healthy parent should establish all of its objects, in case they were changed out-of-band.",
+			args: args{
+				est: NewAPIEstablisher(&test.MockClient{
+					MockGet:   test.NewMockGetFn(nil, established(nil)),
+					MockPatch: test.NewMockPatchFn(errBoom),
+				}, ""),
+				objs:    []runtime.Object{&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "ref-me"}}},
+				parent:  healthy.DeepCopy(),
+				control: true,
+			},
+			want: want{
+				err: errBoom,
+			},
+		},
+		"PartiallyEstablished": {
+			reason: "References to the objects that were established should be returned even if others could not be.",
+			args: args{
+				est: NewAPIEstablisher(&test.MockClient{
+					MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+					MockPatch: func(_ context.Context, obj client.Object, _ client.Patch, opts ...client.PatchOption) error {
+						po := &client.PatchOptions{}
+						po.ApplyOptions(opts)
+						if len(po.DryRun) == 0 && obj.GetName() == "b" {
+							return errBoom
+						}
+						return nil
+					},
+				}, "", WithMaxConcurrency(2)),
+				objs: []runtime.Object{
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+				},
+				parent:  installing.DeepCopy(),
+				control: true,
+			},
+			want: want{
+				refs: []xpv1.TypedReference{{Name: "a"}},
+				err:  errBoom,
+			},
+		},
+		"EstablishOwnershipAppliesOnlyOwnerReferences": {
+			reason: "A parent that doesn't control an object should apply only its owner references, using its own field manager.",
+			args: args{
+				est: &APIEstablisher{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil),
+						MockPatch: func(_ context.Context, obj client.Object, _ client.Patch, opts ...client.PatchOption) error {
+							po := &client.PatchOptions{}
+							po.ApplyOptions(opts)
+							if po.FieldManager != FieldManager+"/inactive" {
+								t.Errorf("Patch(...): want field manager %q, got %q", FieldManager+"/inactive", po.FieldManager)
+							}
+							want := &unstructured.Unstructured{}
+							want.SetGroupVersionKind(extv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+							want.SetName("ref-me")
+							want.SetOwnerReferences([]metav1.OwnerReference{{
+								APIVersion: v1.ProviderRevisionGroupVersionKind.GroupVersion().String(),
+								Kind:       v1.ProviderRevisionKind,
+								Name:       "inactive",
+							}})
+							if diff := cmp.Diff(want, obj); diff != "" {
+								t.Errorf("Patch(...): -want, +got:\n%s", diff)
+							}
+							return nil
+						},
+					},
+				},
+				objs: []runtime.Object{
+					&extv1.CustomResourceDefinition{
+						TypeMeta: metav1.TypeMeta{
+							APIVersion: extv1.SchemeGroupVersion.String(),
+							Kind:       "CustomResourceDefinition",
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "ref-me",
+						},
+						Spec: extv1.CustomResourceDefinitionSpec{
+							Group: "example.org",
+						},
+					},
+				},
+				parent: &v1.ProviderRevision{
+					TypeMeta: metav1.TypeMeta{
+						APIVersion: v1.ProviderRevisionGroupVersionKind.GroupVersion().String(),
+						Kind:       v1.ProviderRevisionKind,
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "inactive",
+					},
+				},
+				control: false,
+			},
+			want: want{
+				refs: []xpv1.TypedReference{{
+					APIVersion: extv1.SchemeGroupVersion.String(),
+					Kind:       "CustomResourceDefinition",
+					Name:       "ref-me",
+				}},
+			},
+		},
+		"ErrControlledByAnother": {
+			reason: "We should not take control of an object that is controlled by something else.",
+			args: args{
+				est: &APIEstablisher{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							obj.SetOwnerReferences([]metav1.OwnerReference{{
+								Kind:       v1.ProviderRevisionKind,
+								Name:       "other",
+								UID:        "other-uid",
+								Controller: pointer.BoolPtr(true),
+							}})
+							return nil
+						}),
+						MockPatch: test.NewMockPatchFn(nil),
+					},
+				},
+				objs: []runtime.Object{
+					&extv1.CustomResourceDefinition{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "ref-me",
+						},
+					},
+				},
+				parent: &v1.ProviderRevision{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test",
+						UID:  "test-uid",
+					},
+				},
+				control: true,
+			},
+			want: want{
+				err: errors.Errorf("ref-me is already controlled by %s other (UID other-uid)", v1.ProviderRevisionKind),
+			},
+		},
+		"ErrResourceConflict": {
+			reason: "We should not establish an object that is controlled by a revision of an unrelated package, naming both packages and the kinds the object defines.",
+			args: args{
+				est: &APIEstablisher{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							obj.SetOwnerReferences([]metav1.OwnerReference{
+								{
+									APIVersion: v1.ProviderRevisionGroupVersionKind.GroupVersion().String(),
+									Kind:       v1.ProviderRevisionKind,
+									Name:       "provider-a-1234",
+									UID:        "provider-a-1234-uid",
+									Controller: pointer.BoolPtr(true),
+								},
+								{
+									APIVersion: v1.ProviderGroupVersionKind.GroupVersion().String(),
+									Kind:       v1.ProviderKind,
+									Name:       "provider-a",
+									UID:        "provider-a-uid",
+								},
+							})
+							return nil
+						}),
+						MockPatch: test.NewMockPatchFn(nil),
+					},
+				},
+				objs: []runtime.Object{
+					&extv1.CustomResourceDefinition{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "widgets.example.org",
+						},
+						Spec: extv1.CustomResourceDefinitionSpec{
+							Group:    "example.org",
+							Names:    extv1.CustomResourceDefinitionNames{Kind: "Widget"},
+							Versions: []extv1.CustomResourceDefinitionVersion{{Name: "v1alpha1"}, {Name: "v1"}},
+						},
+					},
+				},
+				parent: &v1.ProviderRevision{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "provider-b-5678",
+						UID:  "provider-b-5678-uid",
+						OwnerReferences: []metav1.OwnerReference{{
+							APIVersion: v1.ProviderGroupVersionKind.GroupVersion().String(),
+							Kind:       v1.ProviderKind,
+							Name:       "provider-b",
+							UID:        "provider-b-uid",
+						}},
+						Labels: map[string]string{
+							v1.LabelParentPackage: "provider-b",
+						},
+					},
+				},
+				control: false,
+			},
+			want: want{
+				err: resourceConflictError{errors.Errorf(errFmtResourceConflict, v1.ProviderKind, "provider-b", "example.org/v1alpha1, Kind=Widget, example.org/v1, Kind=Widget", v1.ProviderKind, "provider-a")},
+			},
+		},
+		"NoResourceConflictSamePackage": {
+			reason: "We should establish ownership of an object that is controlled by another revision of the same package.",
+			args: args{
+				est: &APIEstablisher{
+					client: &test.MockClient{
+						MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+							obj.SetOwnerReferences([]metav1.OwnerReference{
+								{
+									APIVersion: v1.ProviderRevisionGroupVersionKind.GroupVersion().String(),
+									Kind:       v1.ProviderRevisionKind,
+									Name:       "provider-a-1234",
+									UID:        "provider-a-1234-uid",
+									Controller: pointer.BoolPtr(true),
+								},
+								{
+									APIVersion: v1.ProviderGroupVersionKind.GroupVersion().String(),
+									Kind:       v1.ProviderKind,
+									Name:       "provider-a",
+									UID:        "provider-a-uid",
+								},
+							})
+							return nil
+						}),
+						MockPatch: test.NewMockPatchFn(nil),
+					},
+				},
+				objs: []runtime.Object{
+					&extv1.CustomResourceDefinition{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "widgets.example.org",
+						},
+					},
+				},
+				parent: &v1.ProviderRevision{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "provider-a-5678",
+						UID:  "provider-a-5678-uid",
+						OwnerReferences: []metav1.OwnerReference{{
+							APIVersion: v1.ProviderGroupVersionKind.GroupVersion().String(),
+							Kind:       v1.ProviderKind,
+							Name:       "provider-a",
+							UID:        "provider-a-uid",
+						}},
+						Labels: map[string]string{
+							v1.LabelParentPackage: "provider-a",
+						},
+					},
+				},
+				control: false,
+			},
+			want: want{
+				refs: []xpv1.TypedReference{{Name: "widgets.example.org"}},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -409,6 +836,44 @@ func TestAPIEstablisherEstablish(t *testing.T) {
 	}
 }
 
+func TestAPIEstablisherProgress(t *testing.T) {
+	var reported []int64
+	progress := ProgressReporterFn(func(_ context.Context, pr v1.PackageRevision, p v1.PackageRevisionProgress) error {
+		reported = append(reported, p.ObjectsApplied)
+		pr.SetProgress(&p)
+		pr.SetResourceVersion(fmt.Sprintf("%d", p.ObjectsApplied))
+		return nil
+	})
+
+	e := NewAPIEstablisher(&test.MockClient{
+		MockGet:   test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+		MockPatch: test.NewMockPatchFn(nil),
+	}, "", WithMaxConcurrency(3), WithEstablishProgressReporter(progress))
+	objs := []runtime.Object{
+		&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+		&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+		&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "c"}},
+	}
+	parent := &v1.ProviderRevision{}
+
+	if _, err := e.Establish(context.Background(), objs, parent, true); err != nil {
+		t.Fatalf("e.Establish(...): %s", err)
+	}
+
+	// The last resource is reported by the reconciler, once all resources
+	// are established.
+	if diff := cmp.Diff([]int64{1, 2}, reported); diff != "" {
+		t.Errorf("e.Establish(...): -want reported objects applied, +got:\n%s", diff)
+	}
+	want := &v1.PackageRevisionProgress{Phase: v1.PackageRevisionEstablishing, ObjectsApplied: 2, ObjectsTotal: 3}
+	if diff := cmp.Diff(want, parent.GetProgress()); diff != "" {
+		t.Errorf("e.Establish(...): -want parent progress, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff("2", parent.GetResourceVersion()); diff != "" {
+		t.Errorf("e.Establish(...): -want parent resource version, +got:\n%s", diff)
+	}
+}
+
 func TestGetPackageOwnerReference(t *testing.T) {
 	type args struct {
 		revision resource.Object
@@ -471,3 +936,38 @@ func TestGetPackageOwnerReference(t *testing.T) {
 		})
 	}
 }
+
+func TestIsResourceConflict(t *testing.T) {
+	conflict := resourceConflictError{errors.New("conflict")}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   bool
+	}{
+		"ResourceConflict": {
+			reason: "A wrapped resource conflict should be a resource conflict.",
+			err:    errors.Wrap(conflict, "wrapped"),
+			want:   true,
+		},
+		"AggregatedResourceConflict": {
+			reason: "Errors establishing several resources should be a resource conflict if any of them is, not only the first.",
+			err:    establishErrors{errors.New("boom"), conflict},
+			want:   true,
+		},
+		"OtherError": {
+			reason: "An arbitrary error should not be a resource conflict.",
+			err:    establishErrors{errors.New("boom"), errors.New("bang")},
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := IsResourceConflict(tc.err)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nIsResourceConflict(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+const (
+	errResolvePackageDigest = "cannot resolve package digest"
+	errEncodeReview         = "cannot encode activation review"
+	errCallGate             = "cannot call activation webhook"
+	errDecodeVerdict        = "cannot decode activation webhook verdict"
+	errFmtGateStatus        = "activation webhook returned HTTP status %d"
+	errFmtActivationDenied  = "activation of package %q was denied"
+
+	gateTimeout = 30 * time.Second
+)
+
+// An ActivationGate determines whether a package revision may be activated.
+type ActivationGate interface {
+	// Admit returns an error if the supplied package revision may not be
+	// activated. The error satisfies IsActivationDenied if activation was
+	// denied, rather than the gate failing to reach a verdict. Otherwise it
+	// returns an identifier of the admitted package, which should be
+	// recorded as the revision's admitted package.
+	Admit(ctx context.Context, pr v1.PackageRevision) (string, error)
+}
+
+// An ActivationGateFn is a function that satisfies ActivationGate.
+type ActivationGateFn func(ctx context.Context, pr v1.PackageRevision) (string, error)
+
+// Admit returns an error if the supplied package revision may not be
+// activated.
+func (fn ActivationGateFn) Admit(ctx context.Context, pr v1.PackageRevision) (string, error) {
+	return fn(ctx, pr)
+}
+
+// NopActivationGate admits every package revision.
+type NopActivationGate struct{}
+
+// NewNopActivationGate creates a new NopActivationGate.
+func NewNopActivationGate() *NopActivationGate {
+	return &NopActivationGate{}
+}
+
+// Admit never returns an error, nor an identifier of the admitted package.
+func (g *NopActivationGate) Admit(_ context.Context, _ v1.PackageRevision) (string, error) {
+	return "", nil
+}
+
+type activationDeniedError struct{ error }
+
+// NewActivationDeniedError returns an error indicating that an ActivationGate
+// denied activation of a package revision.
+func NewActivationDeniedError(err error) error {
+	return activationDeniedError{err}
+}
+
+// IsActivationDenied returns true if the supplied error indicates that an
+// ActivationGate denied activation of a package revision.
+func IsActivationDenied(err error) bool {
+	return errors.As(err, &activationDeniedError{})
+}
+
+// An ActivationReview is sent to an activation webhook to ask whether a
+// package revision may be activated.
+type ActivationReview struct {
+	// Kind of the package revision, e.g. ProviderRevision.
+	Kind string `json:"kind"`
+
+	// Name of the package revision.
+	Name string `json:"name"`
+
+	// Package the revision was unpacked from, e.g.
+	// xpkg.upbound.io/crossplane/provider-aws:v0.28.0.
+	Package string `json:"package"`
+
+	// Digest of the package image, e.g. sha256:ecc25c12.... It is empty if
+	// the package can't be pulled, i.e. it's local or its pull policy is
+	// Never, and its source is not pinned by digest.
+	Digest string `json:"digest,omitempty"`
+}
+
+// An ActivationVerdict is returned by an activation webhook.
+type ActivationVerdict struct {
+	// Allowed is true if the package revision may be activated.
+	Allowed bool `json:"allowed"`
+
+	// Message explaining the verdict, e.g. the vulnerabilities that were
+	// found. It is surfaced in the revision's conditions if activation is
+	// denied.
+	Message string `json:"message,omitempty"`
+}
+
+// A WebhookActivationGate admits a package revision only if an activation
+// webhook allows it, for example a webhook that scans the package's image for
+// vulnerabilities.
+type WebhookActivationGate struct {
+	url      string
+	client   *http.Client
+	fetcher  xpkg.Fetcher
+	registry string
+}
+
+// A WebhookActivationGateOption configures a WebhookActivationGate.
+type WebhookActivationGateOption func(g *WebhookActivationGate)
+
+// WithGateHTTPClient specifies the HTTP client used to call the activation
+// webhook.
+func WithGateHTTPClient(c *http.Client) WebhookActivationGateOption {
+	return func(g *WebhookActivationGate) {
+		g.client = c
+	}
+}
+
+// WithGateDefaultRegistry sets the default registry used to resolve package
+// digests.
+func WithGateDefaultRegistry(registry string) WebhookActivationGateOption {
+	return func(g *WebhookActivationGate) {
+		g.registry = registry
+	}
+}
+
+// NewWebhookActivationGate creates a new WebhookActivationGate that POSTs an
+// ActivationReview to the supplied URL.
+func NewWebhookActivationGate(url string, f xpkg.Fetcher, opts ...WebhookActivationGateOption) *WebhookActivationGate {
+	g := &WebhookActivationGate{
+		url:     url,
+		client:  &http.Client{Timeout: gateTimeout},
+		fetcher: f,
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	return g
+}
+
+// Admit returns an error if the activation webhook doesn't allow the supplied
+// package revision to be activated. The package is identified by its digest,
+// or by its source if it has no digest. The webhook isn't asked about a
+// revision whose admitted package is already the identified package.
+func (g *WebhookActivationGate) Admit(ctx context.Context, pr v1.PackageRevision) (string, error) {
+	d, err := g.digest(ctx, pr)
+	if err != nil {
+		return "", errors.Wrap(err, errResolvePackageDigest)
+	}
+	id := d
+	if id == "" {
+		id = pr.GetSource()
+	}
+	if id == pr.GetAdmittedPackage() {
+		return id, nil
+	}
+	rv := ActivationReview{
+		Kind:    revisionKind(pr),
+		Name:    pr.GetName(),
+		Package: pr.GetSource(),
+		Digest:  d,
+	}
+	body, err := json.Marshal(rv)
+	if err != nil {
+		return "", errors.Wrap(err, errEncodeReview)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.url, bytes.NewReader(body))
+	if err != nil {
+		return "", errors.Wrap(err, errCallGate)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := g.client.Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, errCallGate)
+	}
+	defer rsp.Body.Close() //nolint:errcheck
+	if rsp.StatusCode < 200 || rsp.StatusCode > 299 {
+		return "", errors.Errorf(errFmtGateStatus, rsp.StatusCode)
+	}
+	v := ActivationVerdict{}
+	if err := json.NewDecoder(rsp.Body).Decode(&v); err != nil {
+		return "", errors.Wrap(err, errDecodeVerdict)
+	}
+	if v.Allowed {
+		return id, nil
+	}
+	err = errors.Errorf(errFmtActivationDenied, pr.GetSource())
+	if v.Message != "" {
+		err = errors.Errorf("%s: %s", err, v.Message)
+	}
+	return "", activationDeniedError{err}
+}
+
+// revisionKind returns the kind of the supplied package revision, which may
+// not have its type metadata set.
+func revisionKind(pr v1.PackageRevision) string {
+	if k := pr.GetObjectKind().GroupVersionKind().Kind; k != "" {
+		return k
+	}
+	switch pr.(type) {
+	case *v1.ProviderRevision:
+		return v1.ProviderRevisionKind
+	case *v1.ConfigurationRevision:
+		return v1.ConfigurationRevisionKind
+	}
+	return ""
+}
+
+// digest returns the digest of the supplied revision's package image, or an
+// empty string if the package can't be pulled.
+func (g *WebhookActivationGate) digest(ctx context.Context, pr v1.PackageRevision) (string, error) {
+	if xpkg.IsLocalSource(pr.GetSource()) {
+		return "", nil
+	}
+	ref, err := name.ParseReference(pr.GetSource(), name.WithDefaultRegistry(g.registry))
+	if err != nil {
+		return "", errors.Wrap(err, errBadReference)
+	}
+	if d, ok := ref.(name.Digest); ok {
+		return d.DigestStr(), nil
+	}
+	if p := pr.GetPackagePullPolicy(); p != nil && *p == corev1.PullNever {
+		return "", nil
+	}
+	desc, err := g.fetcher.Head(ctx, ref, v1.RefNames(pr.GetPackagePullSecrets())...)
+	if err != nil {
+		return "", err
+	}
+	return desc.Digest.String(), nil
+}
+
+// NewActivationGate returns the ActivationGate configured by the supplied
+// options.
+func NewActivationGate(o controller.Options, f xpkg.Fetcher) ActivationGate {
+	if o.ActivationWebhookURL == "" {
+		return NewNopActivationGate()
+	}
+	return NewWebhookActivationGate(o.ActivationWebhookURL, f, WithGateDefaultRegistry(o.DefaultRegistry))
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	gcrv1 "github.com/google/go-containerregistry/pkg/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/internal/xpkg"
+	"github.com/crossplane/crossplane/internal/xpkg/fake"
+)
+
+var _ ActivationGate = &WebhookActivationGate{}
+var _ ActivationGate = &NopActivationGate{}
+
+func TestWebhookActivationGate(t *testing.T) {
+	errBoom := errors.New("boom")
+	digest := "sha256:ecc25c121431dfc7058754427f97c034ecde26d4aafa0da16d6a47b3e1296ad5"
+	never := corev1.PullNever
+
+	// verdict returns a webhook that expects the supplied review and returns
+	// the supplied verdict.
+	verdict := func(want ActivationReview, v ActivationVerdict) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			got := ActivationReview{}
+			_ = json.NewDecoder(r.Body).Decode(&got)
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("webhook: -want review, +got review:\n%s", diff)
+			}
+			_ = json.NewEncoder(w).Encode(v)
+		}
+	}
+
+	pr := func(pkg string, pull *corev1.PullPolicy) v1.PackageRevision {
+		pr := &v1.ProviderRevision{Spec: v1.PackageRevisionSpec{Package: pkg, PackagePullPolicy: pull}}
+		pr.SetName("cool")
+		return pr
+	}
+	admitted := func(pkg, id string) v1.PackageRevision {
+		pr := pr(pkg, nil)
+		pr.SetAdmittedPackage(id)
+		return pr
+	}
+
+	type args struct {
+		handler http.HandlerFunc
+		fetcher xpkg.Fetcher
+		pr      v1.PackageRevision
+	}
+	type want struct {
+		id     string
+		err    error
+		denied bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Allowed": {
+			reason: "We should admit a revision the webhook allows, sending it the resolved package digest.",
+			args: args{
+				handler: verdict(ActivationReview{Kind: v1.ProviderRevisionKind, Name: "cool", Package: "crossplane/provider-test:v0.1.0", Digest: digest}, ActivationVerdict{Allowed: true}),
+				fetcher: &fake.MockFetcher{MockHead: fake.NewMockHeadFn(&gcrv1.Descriptor{Digest: gcrv1.Hash{Algorithm: "sha256", Hex: digest[7:]}}, nil)},
+				pr:      pr("crossplane/provider-test:v0.1.0", nil),
+			},
+			want: want{
+				id: digest,
+			},
+		},
+		"AlreadyAdmitted": {
+			reason: "We should not ask the webhook about a revision that was already admitted with the same digest.",
+			args: args{
+				fetcher: &fake.MockFetcher{MockHead: fake.NewMockHeadFn(&gcrv1.Descriptor{Digest: gcrv1.Hash{Algorithm: "sha256", Hex: digest[7:]}}, nil)},
+				pr:      admitted("crossplane/provider-test:v0.1.0", digest),
+			},
+			want: want{
+				id: digest,
+			},
+		},
+		"PinnedByDigest": {
+			reason: "We should not resolve the digest of a package that is pinned by digest.",
+			args: args{
+				handler: verdict(ActivationReview{Kind: v1.ProviderRevisionKind, Name: "cool", Package: "crossplane/provider-test@" + digest, Digest: digest}, ActivationVerdict{Allowed: true}),
+				fetcher: &fake.MockFetcher{MockHead: fake.NewMockHeadFn(nil, errBoom)},
+				pr:      pr("crossplane/provider-test@"+digest, nil),
+			},
+			want: want{
+				id: digest,
+			},
+		},
+		"PullNever": {
+			reason: "We should not resolve the digest of a package that is never pulled.",
+			args: args{
+				handler: verdict(ActivationReview{Kind: v1.ProviderRevisionKind, Name: "cool", Package: "crossplane/provider-test:v0.1.0"}, ActivationVerdict{Allowed: true}),
+				fetcher: &fake.MockFetcher{MockHead: fake.NewMockHeadFn(nil, errBoom)},
+				pr:      pr("crossplane/provider-test:v0.1.0", &never),
+			},
+			want: want{
+				id: "crossplane/provider-test:v0.1.0",
+			},
+		},
+		"Denied": {
+			reason: "We should deny a revision the webhook denies, explaining why.",
+			args: args{
+				handler: verdict(ActivationReview{Kind: v1.ProviderRevisionKind, Name: "cool", Package: "crossplane/provider-test@" + digest, Digest: digest}, ActivationVerdict{Message: "2 critical vulnerabilities"}),
+				pr:      pr("crossplane/provider-test@"+digest, nil),
+			},
+			want: want{
+				err:    activationDeniedError{errors.Errorf("%s: %s", errors.Errorf(errFmtActivationDenied, "crossplane/provider-test@"+digest), "2 critical vulnerabilities")},
+				denied: true,
+			},
+		},
+		"ErrResolveDigest": {
+			reason: "We should return an error if we can't resolve the package digest.",
+			args: args{
+				fetcher: &fake.MockFetcher{MockHead: fake.NewMockHeadFn(nil, errBoom)},
+				pr:      pr("crossplane/provider-test:v0.1.0", nil),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errResolvePackageDigest),
+			},
+		},
+		"ErrStatus": {
+			reason: "We should return an error, rather than deny the revision, if the webhook fails.",
+			args: args{
+				handler: func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusInternalServerError) },
+				pr:      pr("crossplane/provider-test@"+digest, nil),
+			},
+			want: want{
+				err: errors.Errorf(errFmtGateStatus, http.StatusInternalServerError),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := tc.args.handler
+			if h == nil {
+				h = func(_ http.ResponseWriter, _ *http.Request) { t.Errorf("webhook: unexpected call") }
+			}
+			srv := httptest.NewServer(h)
+			defer srv.Close()
+
+			g := NewWebhookActivationGate(srv.URL, tc.args.fetcher, WithGateHTTPClient(srv.Client()))
+			id, err := g.Admit(context.Background(), tc.args.pr)
+			if diff := cmp.Diff(tc.want.id, id); diff != "" {
+				t.Errorf("\n%s\ng.Admit(...): -want, +got:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ng.Admit(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.denied, IsActivationDenied(err)); diff != "" {
+				t.Errorf("\n%s\nIsActivationDenied(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -18,19 +18,31 @@ package revision
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
 	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+	"github.com/crossplane/crossplane/internal/initializer"
+	"github.com/crossplane/crossplane/internal/metrics"
 	"github.com/crossplane/crossplane/internal/xpkg"
 )
 
@@ -45,30 +57,172 @@ const (
 	errApplyProviderSA               = "cannot apply provider package service account"
 	errApplyProviderService          = "cannot apply provider package service"
 	errUnavailableProviderDeployment = "provider package deployment is unavailable"
+	errFmtGetReferencedSecret        = "cannot get Secret %q referenced by controller config"
+	errControllerImagePolicy         = "controller image is not permitted by policy"
+	errUnschedulableController       = "provider controller cannot be scheduled"
+	errInvalidDeploymentStrategy     = "invalid provider deployment strategy"
+	errGenerateWebhookTLSCert        = "cannot generate provider webhook tls certificate"
+	errApplyWebhookTLSSecret         = "cannot apply provider webhook tls secret"
 )
 
-// A Hooks performs operations before and after a revision establishes objects.
+const (
+	reasonRepairRuntime event.Reason = "RepairRuntime"
+)
+
+// A Hooks performs operations at each stage of reconciling a revision. Hooks
+// may be used to extend the package manager, for example to verify a package's
+// signature or to check it against a policy.
 type Hooks interface {
+	// Fetch performs operations meant to happen after a package has been
+	// fetched and parsed, but before it is linted.
+	Fetch(context.Context, *parser.Package, v1.PackageRevision) error
+
+	// Verify performs operations meant to happen after a package has been
+	// linted and checked for compatibility, but before its dependencies are
+	// resolved.
+	Verify(context.Context, runtime.Object, v1.PackageRevision) error
+
 	// Pre performs operations meant to happen before establishing objects.
 	Pre(context.Context, runtime.Object, v1.PackageRevision) error
 
-	// Post performs operations meant to happen after establishing objects.
+	// Post performs operations meant to happen after establishing objects,
+	// for example running a provider's controller.
 	Post(context.Context, runtime.Object, v1.PackageRevision) error
 }
 
+// A HookChain runs multiple hooks at each stage, in order. It returns the
+// first error it encounters at a stage.
+type HookChain []Hooks
+
+// Fetch runs the Fetch operations of each hook in the chain.
+func (hc HookChain) Fetch(ctx context.Context, pkg *parser.Package, pr v1.PackageRevision) error {
+	for _, h := range hc {
+		if err := h.Fetch(ctx, pkg, pr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify runs the Verify operations of each hook in the chain.
+func (hc HookChain) Verify(ctx context.Context, pkg runtime.Object, pr v1.PackageRevision) error {
+	for _, h := range hc {
+		if err := h.Verify(ctx, pkg, pr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Pre runs the Pre operations of each hook in the chain.
+func (hc HookChain) Pre(ctx context.Context, pkg runtime.Object, pr v1.PackageRevision) error {
+	for _, h := range hc {
+		if err := h.Pre(ctx, pkg, pr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Post runs the Post operations of each hook in the chain.
+func (hc HookChain) Post(ctx context.Context, pkg runtime.Object, pr v1.PackageRevision) error {
+	for _, h := range hc {
+		if err := h.Post(ctx, pkg, pr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ProviderHooks performs operations for a provider package that requires a
 // controller before and after the revision establishes objects.
 type ProviderHooks struct {
-	client    resource.ClientApplicator
-	namespace string
+	client     resource.ClientApplicator
+	namespace  string
+	images     ControllerImagePolicy
+	scheduling SchedulingValidator
+	certs      initializer.CertificateGenerator
+	record     event.Recorder
+	metrics    metrics.RuntimeRecorder
+	labels     map[string]string
+}
+
+// A ProviderHooksOption configures ProviderHooks.
+type ProviderHooksOption func(h *ProviderHooks)
+
+// WithControllerImagePolicy specifies the policy a provider's controller image
+// must satisfy before its deployment is applied.
+func WithControllerImagePolicy(p ControllerImagePolicy) ProviderHooksOption {
+	return func(h *ProviderHooks) {
+		h.images = p
+	}
+}
+
+// WithSchedulingValidator specifies how ProviderHooks should determine whether
+// a provider's controller can be scheduled before its deployment is applied.
+func WithSchedulingValidator(v SchedulingValidator) ProviderHooksOption {
+	return func(h *ProviderHooks) {
+		h.scheduling = v
+	}
+}
+
+// WithWebhookCertificateGenerator specifies how ProviderHooks should generate
+// the TLS serving certificates of providers that declare webhooks.
+func WithWebhookCertificateGenerator(cg initializer.CertificateGenerator) ProviderHooksOption {
+	return func(h *ProviderHooks) {
+		h.certs = cg
+	}
+}
+
+// WithRuntimeEventRecorder specifies how ProviderHooks should report that a
+// provider's runtime resources were repaired.
+func WithRuntimeEventRecorder(e event.Recorder) ProviderHooksOption {
+	return func(h *ProviderHooks) {
+		h.record = e
+	}
+}
+
+// WithRuntimeMetricsRecorder specifies how ProviderHooks should record metrics
+// about repairs to a provider's runtime resources.
+func WithRuntimeMetricsRecorder(m metrics.RuntimeRecorder) ProviderHooksOption {
+	return func(h *ProviderHooks) {
+		h.metrics = m
+	}
+}
+
+// WithDefaultDeploymentLabels specifies labels ProviderHooks should add to
+// every provider deployment. Labels set by a ControllerConfig take precedence.
+func WithDefaultDeploymentLabels(l map[string]string) ProviderHooksOption {
+	return func(h *ProviderHooks) {
+		h.labels = l
+	}
 }
 
 // NewProviderHooks creates a new ProviderHooks.
-func NewProviderHooks(client resource.ClientApplicator, namespace string) *ProviderHooks {
-	return &ProviderHooks{
-		client:    client,
-		namespace: namespace,
+func NewProviderHooks(client resource.ClientApplicator, namespace string, opts ...ProviderHooksOption) *ProviderHooks {
+	h := &ProviderHooks{
+		client:     client,
+		namespace:  namespace,
+		images:     NewNopControllerImagePolicy(),
+		scheduling: NewNopSchedulingValidator(),
+		certs:      initializer.NewRootCAGenerator(),
+		record:     event.NewNopRecorder(),
+		metrics:    metrics.NewNopRuntimeRecorder(),
+	}
+	for _, o := range opts {
+		o(h)
 	}
+	return h
+}
+
+// Fetch is a no op for provider packages.
+func (h *ProviderHooks) Fetch(context.Context, *parser.Package, v1.PackageRevision) error {
+	return nil
+}
+
+// Verify is a no op for provider packages.
+func (h *ProviderHooks) Verify(context.Context, runtime.Object, v1.PackageRevision) error {
+	return nil
 }
 
 // Pre cleans up a packaged controller and service account if the revision is
@@ -87,6 +241,18 @@ func (h *ProviderHooks) Pre(ctx context.Context, pkg runtime.Object, pr v1.Packa
 
 	provRev.Status.PermissionRequests = pkgProvider.Spec.Controller.PermissionRequests
 
+	// A provider that declares webhooks is served a certificate of its own,
+	// rather than the one shared by Crossplane and all other providers. The
+	// revision must reference it before its objects are established, so that
+	// its webhook configurations and conversion webhooks trust it.
+	if pkgProvider.Spec.Controller.Webhooks != nil {
+		n, err := h.ensureWebhookTLSSecret(ctx, pr)
+		if err != nil {
+			return err
+		}
+		pr.SetWebhookTLSSecretName(&n)
+	}
+
 	// TODO(hasheddan): update any status fields relevant to package revisions.
 
 	// Do not clean up SA and controller if revision is not inactive.
@@ -126,18 +292,66 @@ func (h *ProviderHooks) Post(ctx context.Context, pkg runtime.Object, pr v1.Pack
 		return errors.Wrap(err, errControllerConfig)
 	}
 	s, d, svc := buildProviderDeployment(pkgProvider, pr, cc, h.namespace)
-	if err := h.client.Apply(ctx, s); err != nil {
+	addDefaultLabels(d, h.labels)
+
+	if err := validateDeploymentStrategy(d.Spec.Strategy); err != nil {
+		return errors.Wrap(err, errInvalidDeploymentStrategy)
+	}
+
+	// Rotated credentials are only read from the environment when the
+	// controller starts, so we roll out the deployment when they change.
+	if cc != nil && cc.Spec.RestartOnSecretChange != nil && *cc.Spec.RestartOnSecretChange {
+		sum, err := h.secretChecksum(ctx, d.Spec.Template.Spec)
+		if err != nil {
+			return err
+		}
+		a := make(map[string]string, len(d.Spec.Template.GetAnnotations())+1)
+		for k, v := range d.Spec.Template.GetAnnotations() {
+			a[k] = v
+		}
+		a[v1.AnnotationSecretChecksum] = sum
+		d.Spec.Template.SetAnnotations(a)
+	}
+
+	// Reuse the digest we pinned the controller image to previously, if any, so
+	// that the deployment doesn't change when a tag is moved.
+	image := d.Spec.Template.Spec.Containers[0].Image
+	if pinned := pr.GetControllerImage(); strings.HasPrefix(pinned, image+"@") {
+		image = pinned
+	}
+	image, err = h.images.Pin(ctx, image, v1.RefNames(pr.GetPackagePullSecrets())...)
+	if err != nil {
+		return errors.Wrap(err, errControllerImagePolicy)
+	}
+	d.Spec.Template.Spec.Containers[0].Image = image
+	pr.SetControllerImage(image)
+
+	// A deployment whose pods can't be scheduled would never become available,
+	// so we tell the user why rather than applying it.
+	if err := h.scheduling.Validate(ctx, d.Spec.Template.Spec); err != nil {
+		return errors.Wrap(err, errUnschedulableController)
+	}
+
+	// We summarize what we rendered before applying it, because applying
+	// updates our objects with their observed state.
+	ms := []v1.RuntimeManifest{renderedManifest("ServiceAccount", s), renderedManifest("Deployment", d)}
+	if pr.GetWebhookTLSSecretName() != nil {
+		ms = append(ms, renderedManifest("Service", svc))
+	}
+
+	if err := h.apply(ctx, pr, "ServiceAccount", s); err != nil {
 		return errors.Wrap(err, errApplyProviderSA)
 	}
-	if err := h.client.Apply(ctx, d); err != nil {
+	if err := h.apply(ctx, pr, "Deployment", d); err != nil {
 		return errors.Wrap(err, errApplyProviderDeployment)
 	}
 	if pr.GetWebhookTLSSecretName() != nil {
-		if err := h.client.Apply(ctx, svc); err != nil {
+		if err := h.apply(ctx, pr, "Service", svc); err != nil {
 			return errors.Wrap(err, errApplyProviderService)
 		}
 	}
 	pr.SetControllerReference(xpv1.Reference{Name: d.GetName()})
+	pr.SetRuntimeManifests(ms)
 
 	for _, c := range d.Status.Conditions {
 		if c.Type == appsv1.DeploymentAvailable {
@@ -150,6 +364,144 @@ func (h *ProviderHooks) Post(ctx context.Context, pkg runtime.Object, pr v1.Pack
 	return nil
 }
 
+// addDefaultLabels adds the supplied labels to the supplied object, except for
+// any it already has. The object's labels may be shared with a ControllerConfig,
+// so they're copied rather than modified.
+func addDefaultLabels(o metav1.Object, l map[string]string) {
+	if len(l) == 0 {
+		return
+	}
+	labels := make(map[string]string, len(l)+len(o.GetLabels()))
+	for k, v := range l {
+		labels[k] = v
+	}
+	for k, v := range o.GetLabels() {
+		labels[k] = v
+	}
+	o.SetLabels(labels)
+}
+
+// ensureWebhookTLSSecret ensures the supplied revision has a TLS Secret with a
+// certificate valid for its service, and returns the name of the Secret. The
+// Secret is owned by the revision, so it's garbage collected with it.
+func (h *ProviderHooks) ensureWebhookTLSSecret(ctx context.Context, pr v1.PackageRevision) (string, error) {
+	s := &corev1.Secret{}
+	err := h.client.Get(ctx, types.NamespacedName{Namespace: h.namespace, Name: pr.GetName()}, s)
+	if resource.IgnoreNotFound(err) != nil {
+		return "", errors.Wrap(err, errGetWebhookTLSSecret)
+	}
+	exists := err == nil
+	if len(s.Data[corev1.TLSPrivateKeyKey]) != 0 && len(s.Data[corev1.TLSCertKey]) != 0 {
+		return s.GetName(), nil
+	}
+
+	key, crt, err := h.certs.Generate(fmt.Sprintf("%s.%s.svc", pr.GetName(), h.namespace))
+	if err != nil {
+		return "", errors.Wrap(err, errGenerateWebhookTLSCert)
+	}
+	if s.Data == nil {
+		s.Data = make(map[string][]byte, 2)
+	}
+	s.Data[corev1.TLSPrivateKeyKey] = key
+	s.Data[corev1.TLSCertKey] = crt
+
+	if exists {
+		return s.GetName(), errors.Wrap(h.client.Update(ctx, s), errApplyWebhookTLSSecret)
+	}
+	s.SetName(pr.GetName())
+	s.SetNamespace(h.namespace)
+	s.SetOwnerReferences([]metav1.OwnerReference{meta.AsController(meta.TypedReferenceTo(pr, v1.ProviderRevisionGroupVersionKind))})
+	s.Type = corev1.SecretTypeTLS
+	return s.GetName(), errors.Wrap(h.client.Create(ctx, s), errApplyWebhookTLSSecret)
+}
+
+// apply applies a runtime resource of the supplied kind for the supplied
+// revision. It emits an event and records a metric if doing so repaired the
+// resource, i.e. if the resource was updated, or if it was recreated after we
+// previously created it.
+func (h *ProviderHooks) apply(ctx context.Context, pr v1.PackageRevision, kind string, o client.Object) error {
+	existed, rv := false, ""
+	err := h.client.Apply(ctx, o, func(_ context.Context, current, _ runtime.Object) error {
+		existed = true
+		if m, ok := current.(metav1.Object); ok {
+			rv = m.GetResourceVersion()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// We only set our controller reference once we've applied our runtime
+	// resources, so if it's set they must have existed before.
+	recreated := !existed && pr.GetControllerReference().Name != ""
+	updated := existed && rv != o.GetResourceVersion()
+	if !recreated && !updated {
+		return nil
+	}
+
+	h.metrics.RecordRuntimeRepair(pr.GetLabels()[v1.LabelParentPackage], kind)
+	if recreated {
+		h.record.Event(pr, event.Normal(reasonRepairRuntime, fmt.Sprintf("Recreated %s %q", kind, o.GetName())))
+		return nil
+	}
+	h.record.Event(pr, event.Normal(reasonRepairRuntime, fmt.Sprintf("Updated %s %q to match its desired state", kind, o.GetName())))
+	return nil
+}
+
+// renderedManifest summarizes the supplied kind of rendered runtime resource.
+func renderedManifest(kind string, o client.Object) v1.RuntimeManifest {
+	m := v1.RuntimeManifest{Kind: kind, Name: o.GetName(), Hash: "unknown"}
+	y, err := yaml.Marshal(o)
+	if err != nil {
+		// This should be impossible given we're marshalling a known, strongly
+		// typed struct.
+		return m
+	}
+	h := fnv.New64a()
+	h.Write(y) //nolint:errcheck // Writing to a hash never errors.
+	m.Hash = fmt.Sprintf("%x", h.Sum64())
+	return m
+}
+
+// secretChecksum returns a checksum of the Secrets referenced by the env and
+// envFrom of the supplied pod's containers. Secrets that don't exist are
+// ignored; the pod won't start until they do.
+func (h *ProviderHooks) secretChecksum(ctx context.Context, spec corev1.PodSpec) (string, error) {
+	names := map[string]bool{}
+	for _, c := range spec.Containers {
+		for _, ef := range c.EnvFrom {
+			if ef.SecretRef != nil {
+				names[ef.SecretRef.Name] = true
+			}
+		}
+		for _, e := range c.Env {
+			if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+				names[e.ValueFrom.SecretKeyRef.Name] = true
+			}
+		}
+	}
+	data := make(map[string]map[string][]byte, len(names))
+	for n := range names {
+		s := &corev1.Secret{}
+		err := h.client.Get(ctx, types.NamespacedName{Namespace: h.namespace, Name: n}, s)
+		if resource.IgnoreNotFound(err) != nil {
+			return "", errors.Wrapf(err, errFmtGetReferencedSecret, n)
+		}
+		data[n] = s.Data
+	}
+
+	// Maps are marshalled with sorted keys, so the checksum is stable.
+	j, err := json.Marshal(data)
+	if err != nil {
+		// This should be impossible given we're marshalling maps of bytes.
+		return "", err
+	}
+	sum := fnv.New64a()
+	sum.Write(j) //nolint:errcheck // Writing to a hash never errors.
+	return fmt.Sprintf("%x", sum.Sum64()), nil
+}
+
 func (h *ProviderHooks) getControllerConfig(ctx context.Context, pr v1.PackageRevision) (*v1alpha1.ControllerConfig, error) {
 	var cc *v1alpha1.ControllerConfig
 	if pr.GetControllerConfigRef() != nil {
@@ -170,6 +522,16 @@ func NewConfigurationHooks() *ConfigurationHooks {
 	return &ConfigurationHooks{}
 }
 
+// Fetch is a no op for configuration packages.
+func (h *ConfigurationHooks) Fetch(context.Context, *parser.Package, v1.PackageRevision) error {
+	return nil
+}
+
+// Verify is a no op for configuration packages.
+func (h *ConfigurationHooks) Verify(context.Context, runtime.Object, v1.PackageRevision) error {
+	return nil
+}
+
 // Pre sets status fields based on the configuration package.
 func (h *ConfigurationHooks) Pre(ctx context.Context, pkg runtime.Object, pr v1.PackageRevision) error {
 	return nil
@@ -188,6 +550,16 @@ func NewNopHooks() *NopHooks {
 	return &NopHooks{}
 }
 
+// Fetch does nothing and returns nil.
+func (h *NopHooks) Fetch(context.Context, *parser.Package, v1.PackageRevision) error {
+	return nil
+}
+
+// Verify does nothing and returns nil.
+func (h *NopHooks) Verify(context.Context, runtime.Object, v1.PackageRevision) error {
+	return nil
+}
+
 // Pre does nothing and returns nil.
 func (h *NopHooks) Pre(context.Context, runtime.Object, v1.PackageRevision) error {
 	return nil
@@ -21,9 +21,14 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -41,8 +46,24 @@ var (
 	versionDep  = "v0.1.1"
 )
 
+// MockCertificateGenerator is used to mock certificate generator.
+type MockCertificateGenerator struct {
+	MockGenerate func(domain ...string) (key []byte, crt []byte, err error)
+}
+
+// Generate calls MockGenerate.
+func (m *MockCertificateGenerator) Generate(domain ...string) (key []byte, crt []byte, err error) {
+	return m.MockGenerate(domain...)
+}
+
 func TestHookPre(t *testing.T) {
 	errBoom := errors.New("boom")
+	secretName := "provider-test-1234"
+	webhooks := pkgmetav1.ProviderSpec{
+		Controller: pkgmetav1.ControllerSpec{
+			Webhooks: &pkgmetav1.WebhookSpec{},
+		},
+	}
 
 	type args struct {
 		hook Hooks
@@ -110,6 +131,128 @@ func TestHookPre(t *testing.T) {
 				},
 			},
 		},
+		"ProviderWebhooksGenerateSecret": {
+			reason: "Should generate a TLS secret for the revision of a provider that declares webhooks, and reference it.",
+			args: args{
+				hook: &ProviderHooks{
+					namespace: "crossplane-system",
+					client: resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+							MockCreate: test.NewMockCreateFn(nil, func(o client.Object) error {
+								want := &corev1.Secret{
+									ObjectMeta: metav1.ObjectMeta{
+										Name:      secretName,
+										Namespace: "crossplane-system",
+										OwnerReferences: []metav1.OwnerReference{{
+											APIVersion: v1.SchemeGroupVersion.String(),
+											Kind:       v1.ProviderRevisionKind,
+											Name:       secretName,
+											Controller: pointer.Bool(true),
+										}},
+									},
+									Type: corev1.SecretTypeTLS,
+									Data: map[string][]byte{
+										corev1.TLSPrivateKeyKey: []byte("key"),
+										corev1.TLSCertKey:       []byte("crt"),
+									},
+								}
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("Create(...): -want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+					},
+					certs: &MockCertificateGenerator{
+						MockGenerate: func(domain ...string) ([]byte, []byte, error) {
+							if diff := cmp.Diff([]string{secretName + ".crossplane-system.svc"}, domain); diff != "" {
+								t.Errorf("Generate(...): -want, +got:\n%s", diff)
+							}
+							return []byte("key"), []byte("crt"), nil
+						},
+					},
+				},
+				pkg: &pkgmetav1.Provider{Spec: webhooks},
+				rev: &v1.ProviderRevision{
+					ObjectMeta: metav1.ObjectMeta{Name: secretName},
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				rev: &v1.ProviderRevision{
+					ObjectMeta: metav1.ObjectMeta{Name: secretName},
+					Spec: v1.PackageRevisionSpec{
+						DesiredState:         v1.PackageRevisionActive,
+						WebhookTLSSecretName: &secretName,
+					},
+				},
+			},
+		},
+		"ProviderWebhooksSecretExists": {
+			reason: "Should not regenerate a TLS secret that already contains a certificate.",
+			args: args{
+				hook: &ProviderHooks{
+					client: resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								s := o.(*corev1.Secret)
+								s.SetName(secretName)
+								s.Data = map[string][]byte{
+									corev1.TLSPrivateKeyKey: []byte("key"),
+									corev1.TLSCertKey:       []byte("crt"),
+								}
+								return nil
+							}),
+						},
+					},
+				},
+				pkg: &pkgmetav1.Provider{Spec: webhooks},
+				rev: &v1.ProviderRevision{
+					ObjectMeta: metav1.ObjectMeta{Name: secretName},
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				rev: &v1.ProviderRevision{
+					ObjectMeta: metav1.ObjectMeta{Name: secretName},
+					Spec: v1.PackageRevisionSpec{
+						DesiredState:         v1.PackageRevisionActive,
+						WebhookTLSSecretName: &secretName,
+					},
+				},
+			},
+		},
+		"ErrProviderWebhooksGetSecret": {
+			reason: "Should return error if we fail to get the TLS secret of a provider that declares webhooks.",
+			args: args{
+				hook: &ProviderHooks{
+					client: resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(errBoom),
+						},
+					},
+				},
+				pkg: &pkgmetav1.Provider{Spec: webhooks},
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errGetWebhookTLSSecret),
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+		},
 		"Configuration": {
 			reason: "Should always update status for configuration revisions.",
 			args: args{
@@ -339,6 +482,8 @@ func TestHookPost(t *testing.T) {
 			reason: "Should return error if we fail to apply service account for active providerrevision.",
 			args: args{
 				hook: &ProviderHooks{
+					scheduling: NewNopSchedulingValidator(),
+					images:     NewNopControllerImagePolicy(),
 					client: resource.ClientApplicator{
 						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
 							switch o.(type) {
@@ -371,6 +516,8 @@ func TestHookPost(t *testing.T) {
 			reason: "Should return error if we fail to get controller config for active provider revision.",
 			args: args{
 				hook: &ProviderHooks{
+					scheduling: NewNopSchedulingValidator(),
+					images:     NewNopControllerImagePolicy(),
 					client: resource.ClientApplicator{
 						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
 							return nil
@@ -406,6 +553,8 @@ func TestHookPost(t *testing.T) {
 			reason: "Should return error if we fail to apply deployment for active provider revision.",
 			args: args{
 				hook: &ProviderHooks{
+					scheduling: NewNopSchedulingValidator(),
+					images:     NewNopControllerImagePolicy(),
 					client: resource.ClientApplicator{
 						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
 							switch o.(type) {
@@ -438,6 +587,8 @@ func TestHookPost(t *testing.T) {
 			reason: "Should return error if deployment is unavailable for provider revision.",
 			args: args{
 				hook: &ProviderHooks{
+					scheduling: NewNopSchedulingValidator(),
+					images:     NewNopControllerImagePolicy(),
 					client: resource.ClientApplicator{
 						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
 							d, ok := o.(*appsv1.Deployment)
@@ -465,6 +616,12 @@ func TestHookPost(t *testing.T) {
 					Spec: v1.PackageRevisionSpec{
 						DesiredState: v1.PackageRevisionActive,
 					},
+					Status: v1.PackageRevisionStatus{
+						RuntimeManifests: []v1.RuntimeManifest{
+							{Kind: "ServiceAccount", Hash: "38df1a74f586b6f"},
+							{Kind: "Deployment", Hash: "37a5e5b3deafc92f"},
+						},
+					},
 				},
 				err: errors.Errorf("%s: %s", errUnavailableProviderDeployment, errBoom.Error()),
 			},
@@ -473,8 +630,47 @@ func TestHookPost(t *testing.T) {
 			reason: "Should not return error if successfully applied service account and deployment for active provider revision.",
 			args: args{
 				hook: &ProviderHooks{
+					scheduling: NewNopSchedulingValidator(),
+					images:     NewNopControllerImagePolicy(),
+					client: resource.ClientApplicator{
+						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+							return nil
+						}),
+					},
+				},
+				pkg: &pkgmetav1.Provider{},
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+					Status: v1.PackageRevisionStatus{
+						RuntimeManifests: []v1.RuntimeManifest{
+							{Kind: "ServiceAccount", Hash: "38df1a74f586b6f"},
+							{Kind: "Deployment", Hash: "37a5e5b3deafc92f"},
+						},
+					},
+				},
+			},
+		},
+		"SuccessfulProviderApplyDefaultLabels": {
+			reason: "Should add the default labels to the deployment of an active provider revision.",
+			args: args{
+				hook: &ProviderHooks{
+					scheduling: NewNopSchedulingValidator(),
+					images:     NewNopControllerImagePolicy(),
+					labels:     map[string]string{"example.org/cluster": "prod"},
 					client: resource.ClientApplicator{
 						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+							if d, ok := o.(*appsv1.Deployment); ok && d.GetLabels()["example.org/cluster"] != "prod" {
+								return errors.Errorf("unexpected labels %v", d.GetLabels())
+							}
 							return nil
 						}),
 					},
@@ -491,6 +687,165 @@ func TestHookPost(t *testing.T) {
 					Spec: v1.PackageRevisionSpec{
 						DesiredState: v1.PackageRevisionActive,
 					},
+					Status: v1.PackageRevisionStatus{
+						RuntimeManifests: []v1.RuntimeManifest{
+							{Kind: "ServiceAccount", Hash: "38df1a74f586b6f"},
+							{Kind: "Deployment", Hash: "e1daa9a2014949ce"},
+						},
+					},
+				},
+			},
+		},
+		"ErrControllerImagePolicy": {
+			reason: "Should return error if the controller image is not permitted by policy.",
+			args: args{
+				hook: &ProviderHooks{
+					scheduling: NewNopSchedulingValidator(),
+					images: ControllerImagePolicyFn(func(_ context.Context, _ string, _ ...string) (string, error) {
+						return "", errBoom
+					}),
+					client: resource.ClientApplicator{
+						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+							return nil
+						}),
+					},
+				},
+				pkg: &pkgmetav1.Provider{},
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+				err: errors.Wrap(errBoom, errControllerImagePolicy),
+			},
+		},
+		"ErrUnschedulableController": {
+			reason: "Should return error if the provider's controller cannot be scheduled.",
+			args: args{
+				hook: &ProviderHooks{
+					images: NewNopControllerImagePolicy(),
+					scheduling: SchedulingValidatorFn(func(_ context.Context, _ corev1.PodSpec) error {
+						return errBoom
+					}),
+					client: resource.ClientApplicator{
+						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+							return errors.New("unschedulable deployment should not be applied")
+						}),
+					},
+				},
+				pkg: &pkgmetav1.Provider{},
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+				err: errors.Wrap(errBoom, errUnschedulableController),
+			},
+		},
+		"SuccessfulProviderApplyPinnedImage": {
+			reason: "Should apply a deployment that runs the controller image pinned by policy, and record it.",
+			args: args{
+				hook: &ProviderHooks{
+					scheduling: NewNopSchedulingValidator(),
+					images: ControllerImagePolicyFn(func(_ context.Context, image string, _ ...string) (string, error) {
+						return image + "@sha256:cafe", nil
+					}),
+					client: resource.ClientApplicator{
+						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+							if d, ok := o.(*appsv1.Deployment); ok && d.Spec.Template.Spec.Containers[0].Image != "crossplane/provider-nop:v0.1.0@sha256:cafe" {
+								return errors.Errorf("unexpected image %q", d.Spec.Template.Spec.Containers[0].Image)
+							}
+							return nil
+						}),
+					},
+				},
+				pkg: &pkgmetav1.Provider{
+					Spec: pkgmetav1.ProviderSpec{
+						Controller: pkgmetav1.ControllerSpec{
+							Image: pointer.String("crossplane/provider-nop:v0.1.0"),
+						},
+					},
+				},
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+			want: want{
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+					Status: v1.PackageRevisionStatus{
+						ControllerImage: "crossplane/provider-nop:v0.1.0@sha256:cafe",
+						RuntimeManifests: []v1.RuntimeManifest{
+							{Kind: "ServiceAccount", Hash: "38df1a74f586b6f"},
+							{Kind: "Deployment", Hash: "70e1988dd2e8ad49"},
+						},
+					},
+				},
+			},
+		},
+		"SuccessfulProviderApplyPreviouslyPinnedImage": {
+			reason: "Should reuse the digest the controller image was previously pinned to.",
+			args: args{
+				hook: &ProviderHooks{
+					scheduling: NewNopSchedulingValidator(),
+					images: ControllerImagePolicyFn(func(_ context.Context, image string, _ ...string) (string, error) {
+						if image != "crossplane/provider-nop:v0.1.0@sha256:cafe" {
+							return "", errors.Errorf("unexpected image %q", image)
+						}
+						return image, nil
+					}),
+					client: resource.ClientApplicator{
+						Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+							return nil
+						}),
+					},
+				},
+				pkg: &pkgmetav1.Provider{
+					Spec: pkgmetav1.ProviderSpec{
+						Controller: pkgmetav1.ControllerSpec{
+							Image: pointer.String("crossplane/provider-nop:v0.1.0"),
+						},
+					},
+				},
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+					Status: v1.PackageRevisionStatus{
+						ControllerImage: "crossplane/provider-nop:v0.1.0@sha256:cafe",
+					},
+				},
+			},
+			want: want{
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+					Status: v1.PackageRevisionStatus{
+						ControllerImage: "crossplane/provider-nop:v0.1.0@sha256:cafe",
+						RuntimeManifests: []v1.RuntimeManifest{
+							{Kind: "ServiceAccount", Hash: "38df1a74f586b6f"},
+							{Kind: "Deployment", Hash: "70e1988dd2e8ad49"},
+						},
+					},
 				},
 			},
 		},
@@ -509,3 +864,175 @@ func TestHookPost(t *testing.T) {
 		})
 	}
 }
+
+type runtimeRepairs []string
+
+func (r *runtimeRepairs) RecordRuntimeRepair(_, kind string) {
+	*r = append(*r, kind)
+}
+
+func TestHookPostRepairRuntime(t *testing.T) {
+	type args struct {
+		apply resource.ApplyFn
+		rev   v1.PackageRevision
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   []string
+	}{
+		"Created": {
+			reason: "Creating runtime resources for the first time should not be considered a repair.",
+			args: args{
+				apply: func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error { return nil },
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+				},
+			},
+		},
+		"Recreated": {
+			reason: "Recreating runtime resources we previously created should be considered a repair.",
+			args: args{
+				apply: func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error { return nil },
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+					Status: v1.PackageRevisionStatus{
+						ControllerRef: xpv1.Reference{Name: "cool-rev"},
+					},
+				},
+			},
+			want: []string{"ServiceAccount", "Deployment"},
+		},
+		"Updated": {
+			reason: "Updating an existing runtime resource should be considered a repair.",
+			args: args{
+				apply: func(ctx context.Context, o client.Object, ao ...resource.ApplyOption) error {
+					current := o.DeepCopyObject().(client.Object)
+					current.SetResourceVersion("1")
+					for _, fn := range ao {
+						if err := fn(ctx, current, o); err != nil {
+							return err
+						}
+					}
+					if _, ok := o.(*appsv1.Deployment); ok {
+						o.SetResourceVersion("2")
+						return nil
+					}
+					o.SetResourceVersion("1")
+					return nil
+				},
+				rev: &v1.ProviderRevision{
+					Spec: v1.PackageRevisionSpec{
+						DesiredState: v1.PackageRevisionActive,
+					},
+					Status: v1.PackageRevisionStatus{
+						ControllerRef: xpv1.Reference{Name: "cool-rev"},
+					},
+				},
+			},
+			want: []string{"Deployment"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := &runtimeRepairs{}
+			h := NewProviderHooks(resource.ClientApplicator{Applicator: tc.args.apply}, "", WithRuntimeMetricsRecorder(got))
+			if err := h.Post(context.TODO(), &pkgmetav1.Provider{}, tc.args.rev); err != nil {
+				t.Fatalf("\n%s\nh.Post(...): %s", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, []string(*got), cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nh.Post(...): -want repairs, +got repairs:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestHookChain(t *testing.T) {
+	errBoom := errors.New("boom")
+	unreachable := func() error {
+		t.Errorf("HookChain: hooks after a hook that returned an error should not run")
+		return nil
+	}
+
+	type args struct {
+		hc  HookChain
+		run func(hc HookChain) error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"FetchSuccessful": {
+			reason: "We should return nil if all fetch hooks succeed.",
+			args: args{
+				hc: HookChain{&MockHook{MockFetch: NewMockFetchFn(nil)}, &MockHook{MockFetch: NewMockFetchFn(nil)}},
+				run: func(hc HookChain) error {
+					return hc.Fetch(context.TODO(), nil, &v1.ProviderRevision{})
+				},
+			},
+		},
+		"VerifyError": {
+			reason: "We should return the first error returned by a verify hook.",
+			args: args{
+				hc: HookChain{&MockHook{MockVerify: NewMockVerifyFn(errBoom)}, &MockHook{MockVerify: unreachable}},
+				run: func(hc HookChain) error {
+					return hc.Verify(context.TODO(), &pkgmetav1.Provider{}, &v1.ProviderRevision{})
+				},
+			},
+			want: errBoom,
+		},
+		"PreError": {
+			reason: "We should return the first error returned by a pre hook.",
+			args: args{
+				hc: HookChain{&MockHook{MockPre: NewMockPreFn(nil)}, &MockHook{MockPre: NewMockPreFn(errBoom)}, &MockHook{MockPre: unreachable}},
+				run: func(hc HookChain) error {
+					return hc.Pre(context.TODO(), &pkgmetav1.Provider{}, &v1.ProviderRevision{})
+				},
+			},
+			want: errBoom,
+		},
+		"PostError": {
+			reason: "We should return the first error returned by a post hook.",
+			args: args{
+				hc: HookChain{&MockHook{MockPost: NewMockPostFn(errBoom)}, &MockHook{MockPost: unreachable}},
+				run: func(hc HookChain) error {
+					return hc.Post(context.TODO(), &pkgmetav1.Provider{}, &v1.ProviderRevision{})
+				},
+			},
+			want: errBoom,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.args.run(tc.args.hc)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nHookChain: -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAddDefaultLabels(t *testing.T) {
+	shared := map[string]string{"example.org/cluster": "staging"}
+	d := &appsv1.Deployment{}
+	d.SetLabels(shared)
+
+	addDefaultLabels(d, map[string]string{"example.org/cluster": "prod", "example.org/env": "dev"})
+
+	want := map[string]string{"example.org/cluster": "staging", "example.org/env": "dev"}
+	if diff := cmp.Diff(want, d.GetLabels()); diff != "" {
+		t.Errorf("addDefaultLabels(...): -want labels, +got labels:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"example.org/cluster": "staging"}, shared); diff != "" {
+		t.Errorf("addDefaultLabels(...): shared labels should not be modified: -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"sync"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+)
+
+const (
+	errFmtBuildHooks = "cannot build hooks enabled by feature flag %q"
+)
+
+// ProviderHookRegistry registers the hooks ProviderRevisions run in addition
+// to their ProviderHooks.
+var ProviderHookRegistry = &HookRegistry{}
+
+// ConfigurationHookRegistry registers the hooks ConfigurationRevisions run in
+// addition to their ConfigurationHooks.
+var ConfigurationHookRegistry = &HookRegistry{}
+
+// A HookFactory builds hooks for a revision controller.
+type HookFactory func(mgr ctrl.Manager, o controller.Options) (Hooks, error)
+
+type registeredHooks struct {
+	flag feature.Flag
+	new  HookFactory
+}
+
+// A HookRegistry registers hooks that revisions run only when a particular
+// feature flag is enabled. Hooks are typically registered by an init function,
+// and run in the order they were registered.
+type HookRegistry struct {
+	mx    sync.RWMutex
+	hooks []registeredHooks
+}
+
+// Register the supplied HookFactory. The hooks it builds will run only when the
+// supplied feature flag is enabled.
+func (r *HookRegistry) Register(f feature.Flag, fn HookFactory) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.hooks = append(r.hooks, registeredHooks{flag: f, new: fn})
+}
+
+// Build the hooks whose feature flags are enabled by the supplied options.
+func (r *HookRegistry) Build(mgr ctrl.Manager, o controller.Options) (HookChain, error) {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	hc := HookChain{}
+	for _, rh := range r.hooks {
+		if !o.Features.Enabled(rh.flag) {
+			continue
+		}
+		h, err := rh.new(mgr, o)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtBuildHooks, rh.flag)
+		}
+		hc = append(hc, h)
+	}
+	return hc, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+)
+
+func TestHookRegistryBuild(t *testing.T) {
+	errBoom := errors.New("boom")
+	flagA := feature.Flag("EnableA")
+	flagB := feature.Flag("EnableB")
+
+	hookA := &MockHook{}
+	hookB := &MockHook{}
+	factory := func(h Hooks) HookFactory {
+		return func(_ ctrl.Manager, _ controller.Options) (Hooks, error) { return h, nil }
+	}
+
+	type args struct {
+		register func(r *HookRegistry)
+		enabled  []feature.Flag
+	}
+	type want struct {
+		hc  HookChain
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoneEnabled": {
+			reason: "We should not build hooks whose feature flags are not enabled.",
+			args: args{
+				register: func(r *HookRegistry) {
+					r.Register(flagA, factory(hookA))
+				},
+			},
+			want: want{
+				hc: HookChain{},
+			},
+		},
+		"SomeEnabled": {
+			reason: "We should build only the hooks whose feature flags are enabled, in the order they were registered.",
+			args: args{
+				register: func(r *HookRegistry) {
+					r.Register(flagB, factory(hookB))
+					r.Register(flagA, factory(hookA))
+					r.Register(feature.Flag("EnableC"), func(_ ctrl.Manager, _ controller.Options) (Hooks, error) {
+						t.Errorf("Build(...): hooks whose feature flag is not enabled should not be built")
+						return nil, nil
+					})
+				},
+				enabled: []feature.Flag{flagA, flagB},
+			},
+			want: want{
+				hc: HookChain{hookB, hookA},
+			},
+		},
+		"ErrBuild": {
+			reason: "We should return any error encountered building hooks.",
+			args: args{
+				register: func(r *HookRegistry) {
+					r.Register(flagA, func(_ ctrl.Manager, _ controller.Options) (Hooks, error) { return nil, errBoom })
+				},
+				enabled: []feature.Flag{flagA},
+			},
+			want: want{
+				err: errors.Wrapf(errBoom, errFmtBuildHooks, flagA),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &HookRegistry{}
+			tc.args.register(r)
+
+			f := &feature.Flags{}
+			for _, fl := range tc.args.enabled {
+				f.Enable(fl)
+			}
+
+			hc, err := r.Build(nil, controller.Options{Features: f})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nBuild(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(len(tc.want.hc), len(hc)); diff != "" {
+				t.Errorf("\n%s\nBuild(...): -want hooks, +got hooks:\n%s", tc.reason, diff)
+			}
+			for i := range tc.want.hc {
+				if i < len(hc) && tc.want.hc[i] != hc[i] {
+					t.Errorf("\n%s\nBuild(...): want hook %d to be %p, got %p", tc.reason, i, tc.want.hc[i], hc[i])
+				}
+			}
+		})
+	}
+}
@@ -35,6 +35,7 @@ const (
 	errBadReference            = "package tag is not a valid reference"
 	errFetchPackage            = "failed to fetch package from remote"
 	errGetManifest             = "failed to get package image manifest from remote"
+	errGetConfig               = "failed to get package image config from remote"
 	errFetchLayer              = "failed to fetch annotated base layer from remote"
 	errGetUncompressed         = "failed to get uncompressed contents from layer"
 	errMultipleAnnotatedLayers = "package is invalid due to multiple annotated base layers"
@@ -102,8 +103,23 @@ func (i *ImageBackend) Init(ctx context.Context, bo ...parser.BackendOption) (io
 	if err != nil {
 		return nil, errors.Wrap(err, errGetManifest)
 	}
+	// Record how the image was built. Build metadata may be supplied as image
+	// config labels, but manifest annotations take precedence.
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+	annotations := map[string]string{}
+	for k, v := range cfg.Config.Labels {
+		annotations[k] = v
+	}
+	for k, v := range manifest.Annotations {
+		annotations[k] = v
+	}
+	n.pr.SetBuildMetadata(xpkg.BuildMetadata(annotations))
 	// Determine if the image is using annotated layers.
 	var tarc io.ReadCloser
+	var layer string
 	foundAnnotated := false
 	for _, l := range manifest.Layers {
 		if a, ok := l.Annotations[layerAnnotation]; !ok || a != baseAnnotationValue {
@@ -118,11 +134,12 @@ func (i *ImageBackend) Init(ctx context.Context, bo ...parser.BackendOption) (io
 			return nil, errors.New(errMultipleAnnotatedLayers)
 		}
 		foundAnnotated = true
-		layer, err := img.LayerByDigest(l.Digest)
+		layer = l.Digest.String()
+		ly, err := img.LayerByDigest(l.Digest)
 		if err != nil {
 			return nil, errors.Wrap(err, errFetchLayer)
 		}
-		tarc, err = layer.Uncompressed()
+		tarc, err = ly.Uncompressed()
 		if err != nil {
 			return nil, errors.Wrap(err, errGetUncompressed)
 		}
@@ -151,7 +168,15 @@ func (i *ImageBackend) Init(ctx context.Context, bo ...parser.BackendOption) (io
 	// resources allocated to the underlying ReadCloser. See
 	// https://github.com/google/go-containerregistry/blob/329563766ce8131011c25fd8758a25d94d9ad81b/pkg/v1/mutate/mutate.go#L222
 	// for more info.
-	return xpkg.JoinedReadCloser(t, tarc), nil
+	rc := xpkg.JoinedReadCloser(t, tarc)
+
+	// Content read from the annotated layer is annotated with its digest, so
+	// that objects that can't be parsed can be traced back to it. Flattened
+	// content may have been read from any layer.
+	if layer != "" {
+		return xpkg.AnnotatedReadCloser(rc, xpkg.LayerAnnotation{Digest: layer}), nil
+	}
+	return rc, nil
 }
 
 // nestedBackend is a nop parser backend that conforms to the parser backend
@@ -25,6 +25,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	gcrv1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/random"
@@ -179,3 +180,65 @@ func TestImageBackend(t *testing.T) {
 		})
 	}
 }
+
+func TestImageBackendBuildMetadata(t *testing.T) {
+	streamCont := "somestreamofyaml"
+	tarBuf := new(bytes.Buffer)
+	tw := tar.NewWriter(tarBuf)
+	_ = tw.WriteHeader(&tar.Header{
+		Name: xpkg.StreamFile,
+		Mode: int64(xpkg.StreamFileMode),
+		Size: int64(len(streamCont)),
+	})
+	_, _ = io.Copy(tw, strings.NewReader(streamCont))
+	_ = tw.Close()
+	packLayer, _ := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(tarBuf.Bytes())), nil
+	})
+	packImg, _ := mutate.AppendLayers(empty.Image, packLayer)
+
+	cfg, _ := packImg.ConfigFile()
+	cfg.Config.Labels = map[string]string{
+		xpkg.AnnotationOCIRevision: "label",
+		xpkg.AnnotationOCIVersion:  "v0.1.0",
+	}
+	labelledImg, _ := mutate.ConfigFile(packImg, cfg)
+	annotatedImg := mutate.Annotations(labelledImg, map[string]string{
+		xpkg.AnnotationOCIRevision: "a1b2c3d",
+	}).(gcrv1.Image)
+
+	cases := map[string]struct {
+		reason string
+		img    gcrv1.Image
+		want   *v1.PackageBuildMetadata
+	}{
+		"NoMetadata": {
+			reason: "We should not record build metadata if the image has none.",
+			img:    packImg,
+			want:   nil,
+		},
+		"Labels": {
+			reason: "We should record build metadata supplied as image config labels.",
+			img:    labelledImg,
+			want:   &v1.PackageBuildMetadata{Revision: "label", Version: "v0.1.0"},
+		},
+		"Annotations": {
+			reason: "Build metadata supplied as manifest annotations should take precedence over labels.",
+			img:    annotatedImg,
+			want:   &v1.PackageBuildMetadata{Revision: "a1b2c3d", Version: "v0.1.0"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pr := &v1.ProviderRevision{Spec: v1.PackageRevisionSpec{Package: "test/test:latest"}}
+			b := NewImageBackend(&fake.MockFetcher{MockFetch: fake.NewMockFetchFn(tc.img, nil)})
+			if _, err := b.Init(context.TODO(), PackageRevision(pr)); err != nil {
+				t.Fatalf("b.Init(...): %s", err)
+			}
+			if diff := cmp.Diff(tc.want, pr.GetBuildMetadata()); diff != "" {
+				t.Errorf("\n%s\nb.Init(...): -want build metadata, +got build metadata:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
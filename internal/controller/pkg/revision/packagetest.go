@@ -0,0 +1,299 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+	"github.com/crossplane/crossplane/internal/features"
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+const (
+	// defaultTestTimeout is how long a test may run if it doesn't specify a
+	// timeout.
+	defaultTestTimeout = 10 * time.Minute
+
+	// testPollInterval is how often a revision is reconciled while its tests
+	// are running.
+	testPollInterval = 30 * time.Second
+)
+
+const (
+	errGetTestNamespace    = "cannot get test sandbox namespace"
+	errApplyTestNamespace  = "cannot apply test sandbox namespace"
+	errDeleteTestNamespace = "cannot delete test sandbox namespace"
+	errTestNamespaceExists = "test sandbox namespace already exists and is not controlled by this package revision"
+	errFmtDecodeTest       = "cannot decode manifest of test %q"
+	errFmtMapTest          = "cannot determine scope of resource of test %q"
+	errFmtNotNamespaced    = "resource of test %q must be namespaced"
+	errFmtGetTest          = "cannot get resource of test %q"
+	errFmtTestExists       = "resource of test %q already exists and was not created by this package revision"
+	errFmtApplyTest        = "cannot apply resource of test %q"
+	errFmtDeleteTest       = "cannot delete resource of test %q"
+
+	msgFmtAwaitingConditions = "waiting for resource to have conditions %s"
+	msgFmtTimedOut           = "resource did not have conditions %s after %s"
+	msgFmtTestsFailed        = "tests failed: %s"
+)
+
+func init() {
+	ConfigurationHookRegistry.Register(features.EnableAlphaPackageTests, func(mgr ctrl.Manager, o controller.Options) (Hooks, error) {
+		return NewTestHooks(resource.ClientApplicator{
+			Client:     mgr.GetClient(),
+			Applicator: NewServerSideApplicator(mgr.GetClient(), controller.ApplyConflictPolicyForce),
+		}, mgr.GetRESTMapper()), nil
+	})
+}
+
+// TestHooks run the tests of a Configuration package once a revision of it is
+// active. Each test creates a resource in a sandbox namespace, and passes if
+// the resource has the expected conditions before the test times out. Only
+// namespaced resources may be created by tests, and tests never modify or
+// delete resources that they didn't create.
+type TestHooks struct {
+	NopHooks
+
+	client resource.ClientApplicator
+	mapper kmeta.RESTMapper
+}
+
+// NewTestHooks creates a new TestHooks.
+func NewTestHooks(client resource.ClientApplicator, m kmeta.RESTMapper) *TestHooks {
+	return &TestHooks{client: client, mapper: m}
+}
+
+// Post runs any tests that haven't yet passed or failed, and reports their
+// results. The sandbox namespace is deleted once all tests have finished.
+func (h *TestHooks) Post(ctx context.Context, pkg runtime.Object, pr v1.PackageRevision) error {
+	po, _ := xpkg.TryConvert(pkg, &pkgmetav1.Configuration{})
+	c, ok := po.(*pkgmetav1.Configuration)
+	if !ok || len(c.Spec.Tests) == 0 || pr.GetDesiredState() != v1.PackageRevisionActive {
+		return nil
+	}
+
+	previous := map[string]v1.PackageTestResult{}
+	for _, r := range pr.GetTestResults() {
+		previous[r.Name] = r
+	}
+
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace(pr)}}
+	meta.AddOwnerReference(ns, meta.AsController(meta.TypedReferenceTo(pr, pr.GetObjectKind().GroupVersionKind())))
+
+	results := make([]v1.PackageTestResult, len(c.Spec.Tests))
+	applied := false
+	for i, t := range c.Spec.Tests {
+		r, ok := previous[t.Name]
+		if !ok {
+			r = v1.PackageTestResult{Name: t.Name, Result: v1.PackageTestRunning, StartTime: metav1.Now()}
+		}
+		if r.Result == v1.PackageTestRunning {
+			if !applied {
+				// We never take control of a namespace that we didn't
+				// create, even if it has the name of our sandbox.
+				existing := &corev1.Namespace{}
+				err := h.client.Get(ctx, types.NamespacedName{Name: ns.GetName()}, existing)
+				if resource.IgnoreNotFound(err) != nil {
+					return errors.Wrap(err, errGetTestNamespace)
+				}
+				if err == nil && !metav1.IsControlledBy(existing, pr) {
+					return errors.New(errTestNamespaceExists)
+				}
+				if err := h.client.Apply(ctx, ns); err != nil {
+					return errors.Wrap(err, errApplyTestNamespace)
+				}
+				applied = true
+			}
+			var err error
+			if r, err = h.run(ctx, t, r, pr, ns.GetName()); err != nil {
+				return err
+			}
+		}
+		results[i] = r
+	}
+
+	pr.SetTestResults(results)
+	pr.SetConditions(tested(results))
+
+	if testsRunning(results) {
+		return nil
+	}
+	return errors.Wrap(resource.IgnoreNotFound(h.client.Delete(ctx, ns)), errDeleteTestNamespace)
+}
+
+// run the supplied test, returning its updated result. The test's resource is
+// deleted once the test has passed or failed.
+func (h *TestHooks) run(ctx context.Context, t pkgmetav1.Test, r v1.PackageTestResult, pr v1.PackageRevision, ns string) (v1.PackageTestResult, error) {
+	u := &unstructured.Unstructured{}
+	if err := json.Unmarshal(t.Manifest.Raw, &u.Object); err != nil {
+		r.Result = v1.PackageTestFailed
+		r.Message = errors.Wrapf(err, errFmtDecodeTest, t.Name).Error()
+		return r, nil
+	}
+
+	timeout := defaultTestTimeout
+	if t.Timeout != nil {
+		timeout = t.Timeout.Duration
+	}
+	timedOut := time.Since(r.StartTime.Time) > timeout
+
+	// waiting returns the supplied result, which fails only if the test has
+	// timed out.
+	waiting := func(r v1.PackageTestResult, err error) (v1.PackageTestResult, error) {
+		r.Message = err.Error()
+		if timedOut {
+			r.Result = v1.PackageTestFailed
+		}
+		return r, nil
+	}
+
+	// Tests are confined to their sandbox namespace, so their resources must
+	// be namespaced. The type of the resource may not exist yet, for example
+	// because it is defined by an XRD in this package that isn't established.
+	gvk := u.GroupVersionKind()
+	m, err := h.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return waiting(r, errors.Wrapf(err, errFmtMapTest, t.Name))
+	}
+	if m.Scope.Name() != kmeta.RESTScopeNameNamespace {
+		r.Result = v1.PackageTestFailed
+		r.Message = errors.Errorf(errFmtNotNamespaced, t.Name).Error()
+		return r, nil
+	}
+
+	// The owner reference ensures the resource is garbage collected if the
+	// revision is deleted before the test finishes. It also tells us whether
+	// an existing resource was created by this revision's test.
+	u.SetNamespace(ns)
+	meta.AddOwnerReference(u, meta.AsOwner(meta.TypedReferenceTo(pr, pr.GetObjectKind().GroupVersionKind())))
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(gvk)
+	err = h.client.Get(ctx, types.NamespacedName{Namespace: ns, Name: u.GetName()}, existing)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return waiting(r, errors.Wrapf(err, errFmtGetTest, t.Name))
+	}
+	if err == nil && !ownedBy(existing, pr) {
+		r.Result = v1.PackageTestFailed
+		r.Message = errors.Errorf(errFmtTestExists, t.Name).Error()
+		return r, nil
+	}
+
+	// We apply the resource each time we run the test, which also tells us its
+	// current conditions.
+	if err := h.client.Apply(ctx, u); err != nil {
+		return waiting(r, errors.Wrapf(err, errFmtApplyTest, t.Name))
+	}
+
+	cs := xpv1.ConditionedStatus{}
+	_ = fieldpath.Pave(u.Object).GetValueInto("status", &cs)
+	unmet := make([]string, 0, len(t.Conditions))
+	for _, want := range t.Conditions {
+		if cs.GetCondition(want.Type).Status != want.Status {
+			unmet = append(unmet, fmt.Sprintf("%s=%s", want.Type, want.Status))
+		}
+	}
+
+	switch {
+	case len(unmet) == 0:
+		r.Result = v1.PackageTestPassed
+		r.Message = ""
+	case timedOut:
+		r.Result = v1.PackageTestFailed
+		r.Message = fmt.Sprintf(msgFmtTimedOut, strings.Join(unmet, ", "), timeout)
+	default:
+		r.Message = fmt.Sprintf(msgFmtAwaitingConditions, strings.Join(unmet, ", "))
+		return r, nil
+	}
+
+	return r, errors.Wrapf(resource.IgnoreNotFound(h.client.Delete(ctx, u)), errFmtDeleteTest, t.Name)
+}
+
+// ownedBy returns true if the supplied object has an owner reference to the
+// supplied revision.
+func ownedBy(o metav1.Object, pr v1.PackageRevision) bool {
+	for _, ref := range o.GetOwnerReferences() {
+		if ref.UID == pr.GetUID() {
+			return true
+		}
+	}
+	return false
+}
+
+// tested returns a condition summarizing the supplied test results.
+func tested(results []v1.PackageTestResult) xpv1.Condition {
+	failed := make([]string, 0, len(results))
+	running := false
+	for _, r := range results {
+		switch r.Result {
+		case v1.PackageTestFailed:
+			failed = append(failed, r.Name)
+		case v1.PackageTestRunning:
+			running = true
+		}
+	}
+	switch {
+	case len(failed) > 0:
+		return v1.TestsFailed().WithMessage(fmt.Sprintf(msgFmtTestsFailed, strings.Join(failed, ", ")))
+	case running:
+		return v1.TestsRunning()
+	}
+	return v1.TestsPassed()
+}
+
+// testsRunning returns true if any of the supplied tests have not yet passed or
+// failed.
+func testsRunning(results []v1.PackageTestResult) bool {
+	for _, r := range results {
+		if r.Result == v1.PackageTestRunning {
+			return true
+		}
+	}
+	return false
+}
+
+// testNamespace returns the name of the sandbox namespace in which the tests of
+// the supplied revision run. Revision names aren't always valid namespace
+// names, so we use a hash of the name.
+func testNamespace(pr v1.PackageRevision) string {
+	h := fnv.New64a()
+	h.Write([]byte(pr.GetName())) //nolint:errcheck // Writing to a hash never errors.
+	return fmt.Sprintf("crossplane-test-%x", h.Sum64())
+}
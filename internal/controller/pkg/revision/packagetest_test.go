@@ -0,0 +1,349 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+)
+
+func TestTestHooksPost(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	notFound := test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, ""))
+
+	mapper := kmeta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "XClaim"}, kmeta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: "XCluster"}, kmeta.RESTScopeRoot)
+
+	claim := runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"XClaim","metadata":{"name":"test"}}`)}
+	cluster := runtime.RawExtension{Raw: []byte(`{"apiVersion":"example.org/v1","kind":"XCluster","metadata":{"name":"test"}}`)}
+	ready := []pkgmetav1.TestCondition{{Type: xpv1.TypeReady, Status: corev1.ConditionTrue}}
+	cfg := &pkgmetav1.Configuration{
+		Spec: pkgmetav1.ConfigurationSpec{
+			Tests: []pkgmetav1.Test{{Name: "claim", Manifest: claim, Conditions: ready}},
+		},
+	}
+
+	// withReady returns an ApplyFn that reports whether the applied test
+	// resource is ready.
+	withReady := func(s corev1.ConditionStatus) resource.ApplyFn {
+		return func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+			u, ok := o.(*unstructured.Unstructured)
+			if !ok {
+				return nil
+			}
+			if u.GetNamespace() != testNamespace(&v1.ConfigurationRevision{ObjectMeta: metav1.ObjectMeta{Name: "rev"}}) {
+				t.Errorf("Apply(...): want test resource in sandbox namespace, got %q", u.GetNamespace())
+			}
+			return fieldpath.Pave(u.Object).SetValue("status", xpv1.ConditionedStatus{Conditions: []xpv1.Condition{{Type: xpv1.TypeReady, Status: s}}})
+		}
+	}
+
+	active := func(results ...v1.PackageTestResult) *v1.ConfigurationRevision {
+		pr := &v1.ConfigurationRevision{ObjectMeta: metav1.ObjectMeta{Name: "rev"}}
+		pr.SetDesiredState(v1.PackageRevisionActive)
+		pr.SetTestResults(results)
+		return pr
+	}
+
+	type args struct {
+		client resource.ClientApplicator
+		pkg    runtime.Object
+		rev    v1.PackageRevision
+	}
+	type want struct {
+		err error
+		rev v1.PackageRevision
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"NoTests": {
+			reason: "We should do nothing if the package has no tests.",
+			args: args{
+				pkg: &pkgmetav1.Configuration{},
+				rev: active(),
+			},
+			want: want{
+				rev: active(),
+			},
+		},
+		"InactiveRevision": {
+			reason: "We should not run tests of an inactive revision.",
+			args: args{
+				pkg: cfg,
+				rev: &v1.ConfigurationRevision{},
+			},
+			want: want{
+				rev: &v1.ConfigurationRevision{},
+			},
+		},
+		"Running": {
+			reason: "A test whose resource doesn't yet have the expected conditions should be running.",
+			args: args{
+				client: resource.ClientApplicator{
+					Client:     &test.MockClient{MockGet: notFound},
+					Applicator: withReady(corev1.ConditionFalse),
+				},
+				pkg: cfg,
+				rev: active(),
+			},
+			want: want{
+				rev: func() v1.PackageRevision {
+					pr := active(v1.PackageTestResult{Name: "claim", Result: v1.PackageTestRunning, Message: fmt.Sprintf(msgFmtAwaitingConditions, "Ready=True")})
+					pr.SetConditions(v1.TestsRunning())
+					return pr
+				}(),
+			},
+		},
+		"Passed": {
+			reason: "A test whose resource has the expected conditions should pass, and its resource and sandbox namespace should be deleted.",
+			args: args{
+				client: resource.ClientApplicator{
+					Client:     &test.MockClient{MockGet: notFound, MockDelete: test.NewMockDeleteFn(nil)},
+					Applicator: withReady(corev1.ConditionTrue),
+				},
+				pkg: cfg,
+				rev: active(v1.PackageTestResult{Name: "claim", Result: v1.PackageTestRunning, StartTime: metav1.Now()}),
+			},
+			want: want{
+				rev: func() v1.PackageRevision {
+					pr := active(v1.PackageTestResult{Name: "claim", Result: v1.PackageTestPassed})
+					pr.SetConditions(v1.TestsPassed())
+					return pr
+				}(),
+			},
+		},
+		"TimedOut": {
+			reason: "A test whose resource doesn't have the expected conditions before it times out should fail.",
+			args: args{
+				client: resource.ClientApplicator{
+					Client:     &test.MockClient{MockGet: notFound, MockDelete: test.NewMockDeleteFn(nil)},
+					Applicator: withReady(corev1.ConditionFalse),
+				},
+				pkg: cfg,
+				rev: active(v1.PackageTestResult{Name: "claim", Result: v1.PackageTestRunning, StartTime: metav1.NewTime(time.Now().Add(-1 * time.Hour))}),
+			},
+			want: want{
+				rev: func() v1.PackageRevision {
+					pr := active(v1.PackageTestResult{Name: "claim", Result: v1.PackageTestFailed, Message: fmt.Sprintf(msgFmtTimedOut, "Ready=True", defaultTestTimeout)})
+					pr.SetConditions(v1.TestsFailed().WithMessage(fmt.Sprintf(msgFmtTestsFailed, "claim")))
+					return pr
+				}(),
+			},
+		},
+		"ApplyTestResourceError": {
+			reason: "A test whose resource can't be applied should keep running until it times out.",
+			args: args{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{MockGet: notFound},
+					Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+						if _, ok := o.(*unstructured.Unstructured); ok {
+							return errBoom
+						}
+						return nil
+					}),
+				},
+				pkg: cfg,
+				rev: active(),
+			},
+			want: want{
+				rev: func() v1.PackageRevision {
+					pr := active(v1.PackageTestResult{Name: "claim", Result: v1.PackageTestRunning, Message: errors.Wrapf(errBoom, errFmtApplyTest, "claim").Error()})
+					pr.SetConditions(v1.TestsRunning())
+					return pr
+				}(),
+			},
+		},
+		"Finished": {
+			reason: "We should not run tests that have already finished, and should delete the sandbox namespace.",
+			args: args{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{MockGet: notFound, MockDelete: test.NewMockDeleteFn(nil, func(o client.Object) error {
+						if _, ok := o.(*corev1.Namespace); !ok {
+							t.Errorf("Delete(...): want only the sandbox namespace to be deleted, got %T", o)
+						}
+						return nil
+					})},
+					Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+						t.Errorf("Apply(...): finished tests should not be run")
+						return nil
+					}),
+				},
+				pkg: cfg,
+				rev: active(v1.PackageTestResult{Name: "claim", Result: v1.PackageTestPassed}),
+			},
+			want: want{
+				rev: func() v1.PackageRevision {
+					pr := active(v1.PackageTestResult{Name: "claim", Result: v1.PackageTestPassed})
+					pr.SetConditions(v1.TestsPassed())
+					return pr
+				}(),
+			},
+		},
+		"ClusterScoped": {
+			reason: "A test whose resource isn't namespaced should fail without creating it.",
+			args: args{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{MockGet: notFound, MockDelete: test.NewMockDeleteFn(nil)},
+					Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+						if _, ok := o.(*unstructured.Unstructured); ok {
+							t.Errorf("Apply(...): cluster scoped test resource should not be applied")
+						}
+						return nil
+					}),
+				},
+				pkg: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						Tests: []pkgmetav1.Test{{Name: "cluster", Manifest: cluster, Conditions: ready}},
+					},
+				},
+				rev: active(),
+			},
+			want: want{
+				rev: func() v1.PackageRevision {
+					pr := active(v1.PackageTestResult{Name: "cluster", Result: v1.PackageTestFailed, Message: errors.Errorf(errFmtNotNamespaced, "cluster").Error()})
+					pr.SetConditions(v1.TestsFailed().WithMessage(fmt.Sprintf(msgFmtTestsFailed, "cluster")))
+					return pr
+				}(),
+			},
+		},
+		"TestResourceExists": {
+			reason: "A test whose resource already exists, but wasn't created by the revision, should fail without changing or deleting it.",
+			args: args{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{
+						MockGet: func(_ context.Context, _ client.ObjectKey, o client.Object) error {
+							if _, ok := o.(*unstructured.Unstructured); ok {
+								return nil
+							}
+							return kerrors.NewNotFound(schema.GroupResource{}, "")
+						},
+						MockDelete: test.NewMockDeleteFn(nil, func(o client.Object) error {
+							if _, ok := o.(*corev1.Namespace); !ok {
+								t.Errorf("Delete(...): want only the sandbox namespace to be deleted, got %T", o)
+							}
+							return nil
+						}),
+					},
+					Applicator: resource.ApplyFn(func(_ context.Context, o client.Object, _ ...resource.ApplyOption) error {
+						if _, ok := o.(*unstructured.Unstructured); ok {
+							t.Errorf("Apply(...): existing test resource should not be applied")
+						}
+						return nil
+					}),
+				},
+				pkg: cfg,
+				rev: active(),
+			},
+			want: want{
+				rev: func() v1.PackageRevision {
+					pr := active(v1.PackageTestResult{Name: "claim", Result: v1.PackageTestFailed, Message: errors.Errorf(errFmtTestExists, "claim").Error()})
+					pr.SetConditions(v1.TestsFailed().WithMessage(fmt.Sprintf(msgFmtTestsFailed, "claim")))
+					return pr
+				}(),
+			},
+		},
+		"ErrTestNamespaceExists": {
+			reason: "We should return an error if the sandbox namespace exists but isn't controlled by the revision.",
+			args: args{
+				client: resource.ClientApplicator{
+					Client: &test.MockClient{MockGet: test.NewMockGetFn(nil)},
+					Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+						t.Errorf("Apply(...): existing namespace should not be applied")
+						return nil
+					}),
+				},
+				pkg: cfg,
+				rev: active(),
+			},
+			want: want{
+				err: errors.New(errTestNamespaceExists),
+				rev: active(),
+			},
+		},
+		"ErrApplyNamespace": {
+			reason: "We should return an error if we can't apply the sandbox namespace.",
+			args: args{
+				client: resource.ClientApplicator{
+					Client:     &test.MockClient{MockGet: notFound},
+					Applicator: resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error { return errBoom }),
+				},
+				pkg: cfg,
+				rev: active(),
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errApplyTestNamespace),
+				rev: active(),
+			},
+		},
+		"ErrDeleteTestResource": {
+			reason: "We should return an error if we can't delete the resource of a finished test.",
+			args: args{
+				client: resource.ClientApplicator{
+					Client:     &test.MockClient{MockGet: notFound, MockDelete: test.NewMockDeleteFn(errBoom)},
+					Applicator: withReady(corev1.ConditionTrue),
+				},
+				pkg: cfg,
+				rev: active(),
+			},
+			want: want{
+				err: errors.Wrapf(errBoom, errFmtDeleteTest, "claim"),
+				rev: active(),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			h := NewTestHooks(tc.args.client, mapper)
+			err := h.Post(context.TODO(), tc.args.pkg, tc.args.rev)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nh.Post(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.rev, tc.args.rev, cmpopts.IgnoreTypes(metav1.Time{})); diff != "" {
+				t.Errorf("\n%s\nh.Post(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
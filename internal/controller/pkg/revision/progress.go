@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+)
+
+const (
+	errReportProgress = "cannot report package revision progress"
+)
+
+// A ProgressReporter reports the progress of the installation of a package
+// revision.
+type ProgressReporter interface {
+	// Report that the supplied package revision has made the supplied
+	// progress.
+	Report(ctx context.Context, pr v1.PackageRevision, p v1.PackageRevisionProgress) error
+}
+
+// A ProgressReporterFn is a function that satisfies ProgressReporter.
+type ProgressReporterFn func(ctx context.Context, pr v1.PackageRevision, p v1.PackageRevisionProgress) error
+
+// Report that the supplied package revision has made the supplied progress.
+func (fn ProgressReporterFn) Report(ctx context.Context, pr v1.PackageRevision, p v1.PackageRevisionProgress) error {
+	return fn(ctx, pr, p)
+}
+
+// NopProgressReporter does not report progress.
+type NopProgressReporter struct{}
+
+// NewNopProgressReporter creates a new NopProgressReporter.
+func NewNopProgressReporter() *NopProgressReporter {
+	return &NopProgressReporter{}
+}
+
+// Report does nothing.
+func (r *NopProgressReporter) Report(_ context.Context, _ v1.PackageRevision, _ v1.PackageRevisionProgress) error {
+	return nil
+}
+
+// An APIProgressReporter reports progress by updating the status of a package
+// revision.
+type APIProgressReporter struct {
+	client client.Client
+}
+
+// NewAPIProgressReporter creates a new APIProgressReporter.
+func NewAPIProgressReporter(c client.Client) *APIProgressReporter {
+	return &APIProgressReporter{client: c}
+}
+
+// Report the supplied progress in the status of the supplied package revision.
+// Progress is only recorded if it's ahead of the revision's current progress,
+// so retrying a failed installation doesn't write each phase again. The status
+// is only updated while the revision is being installed, i.e. is not yet
+// healthy. Reaching the Installed phase is recorded but not written, because
+// the reconciler writes the revision's status once it's installed.
+func (r *APIProgressReporter) Report(ctx context.Context, pr v1.PackageRevision, p v1.PackageRevisionProgress) error {
+	if !ahead(p, pr.GetProgress()) {
+		return nil
+	}
+	pr.SetProgress(&p)
+	if p.Phase == v1.PackageRevisionInstalled || pr.GetCondition(v1.TypeHealthy).Status == corev1.ConditionTrue {
+		return nil
+	}
+	return errors.Wrap(r.client.Status().Update(ctx, pr), errReportProgress)
+}
+
+// phases of the installation of a package revision, in order.
+var phases = []v1.PackageRevisionPhase{
+	v1.PackageRevisionFetching,
+	v1.PackageRevisionVerifying,
+	v1.PackageRevisionResolving,
+	v1.PackageRevisionEstablishing,
+	v1.PackageRevisionInstalled,
+}
+
+// ahead returns true if the supplied progress is ahead of the supplied current
+// progress, i.e. is in a later phase, or has established more objects.
+func ahead(p v1.PackageRevisionProgress, current *v1.PackageRevisionProgress) bool {
+	if current == nil {
+		return true
+	}
+	order := func(ph v1.PackageRevisionPhase) int {
+		for i := range phases {
+			if phases[i] == ph {
+				return i
+			}
+		}
+		return -1
+	}
+	if o, c := order(p.Phase), order(current.Phase); o != c {
+		return o > c
+	}
+	return p.ObjectsApplied > current.ObjectsApplied
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+)
+
+var _ ProgressReporter = &APIProgressReporter{}
+var _ ProgressReporter = &NopProgressReporter{}
+var _ ProgressReporter = ProgressReporterFn(nil)
+
+func TestAPIProgressReporter(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	installing := func() v1.PackageRevision {
+		return &v1.ConfigurationRevision{}
+	}
+	resolving := func() v1.PackageRevision {
+		pr := &v1.ConfigurationRevision{}
+		pr.SetProgress(&v1.PackageRevisionProgress{Phase: v1.PackageRevisionResolving})
+		return pr
+	}
+	healthy := func() v1.PackageRevision {
+		pr := &v1.ConfigurationRevision{}
+		pr.SetConditions(v1.Healthy())
+		return pr
+	}
+
+	type args struct {
+		client client.Client
+		pr     v1.PackageRevision
+		p      v1.PackageRevisionProgress
+	}
+	type want struct {
+		progress *v1.PackageRevisionProgress
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Installing": {
+			reason: "We should write the progress of a revision that is being installed.",
+			args: args{
+				client: &test.MockClient{
+					MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+						want := &v1.PackageRevisionProgress{Phase: v1.PackageRevisionEstablishing, ObjectsTotal: 3}
+						if diff := cmp.Diff(want, o.(v1.PackageRevision).GetProgress()); diff != "" {
+							t.Errorf("-want, +got:\n%s", diff)
+						}
+						return nil
+					}),
+				},
+				pr: installing(),
+				p:  v1.PackageRevisionProgress{Phase: v1.PackageRevisionEstablishing, ObjectsTotal: 3},
+			},
+			want: want{
+				progress: &v1.PackageRevisionProgress{Phase: v1.PackageRevisionEstablishing, ObjectsTotal: 3},
+			},
+		},
+		"Behind": {
+			reason: "We should neither record nor write progress that is behind the revision's current progress, e.g. when retrying a failed installation.",
+			args: args{
+				client: &test.MockClient{MockStatusUpdate: func(_ context.Context, _ client.Object, _ ...client.UpdateOption) error {
+					t.Errorf("Status().Update(...): progress should not be written")
+					return nil
+				}},
+				pr: resolving(),
+				p:  v1.PackageRevisionProgress{Phase: v1.PackageRevisionFetching},
+			},
+			want: want{
+				progress: &v1.PackageRevisionProgress{Phase: v1.PackageRevisionResolving},
+			},
+		},
+		"Unchanged": {
+			reason: "We should not write progress that is the same as the revision's current progress.",
+			args: args{
+				client: &test.MockClient{MockStatusUpdate: func(_ context.Context, _ client.Object, _ ...client.UpdateOption) error {
+					t.Errorf("Status().Update(...): progress should not be written")
+					return nil
+				}},
+				pr: resolving(),
+				p:  v1.PackageRevisionProgress{Phase: v1.PackageRevisionResolving},
+			},
+			want: want{
+				progress: &v1.PackageRevisionProgress{Phase: v1.PackageRevisionResolving},
+			},
+		},
+		"MoreObjectsEstablished": {
+			reason: "We should write progress that has established more objects in the same phase.",
+			args: args{
+				client: &test.MockClient{MockStatusUpdate: test.NewMockStatusUpdateFn(nil)},
+				pr: func() v1.PackageRevision {
+					pr := &v1.ConfigurationRevision{}
+					pr.SetProgress(&v1.PackageRevisionProgress{Phase: v1.PackageRevisionEstablishing, ObjectsApplied: 1, ObjectsTotal: 3})
+					return pr
+				}(),
+				p: v1.PackageRevisionProgress{Phase: v1.PackageRevisionEstablishing, ObjectsApplied: 2, ObjectsTotal: 3},
+			},
+			want: want{
+				progress: &v1.PackageRevisionProgress{Phase: v1.PackageRevisionEstablishing, ObjectsApplied: 2, ObjectsTotal: 3},
+			},
+		},
+		"Installed": {
+			reason: "We should record, but not write, that a revision was installed.",
+			args: args{
+				client: &test.MockClient{MockStatusUpdate: test.NewMockStatusUpdateFn(errBoom)},
+				pr:     installing(),
+				p:      v1.PackageRevisionProgress{Phase: v1.PackageRevisionInstalled, ObjectsApplied: 3, ObjectsTotal: 3},
+			},
+			want: want{
+				progress: &v1.PackageRevisionProgress{Phase: v1.PackageRevisionInstalled, ObjectsApplied: 3, ObjectsTotal: 3},
+			},
+		},
+		"Healthy": {
+			reason: "We should record, but not write, the progress of a revision that is already healthy.",
+			args: args{
+				client: &test.MockClient{MockStatusUpdate: test.NewMockStatusUpdateFn(errBoom)},
+				pr:     healthy(),
+				p:      v1.PackageRevisionProgress{Phase: v1.PackageRevisionFetching},
+			},
+			want: want{
+				progress: &v1.PackageRevisionProgress{Phase: v1.PackageRevisionFetching},
+			},
+		},
+		"ErrUpdateStatus": {
+			reason: "We should return any error encountered while writing progress.",
+			args: args{
+				client: &test.MockClient{MockStatusUpdate: test.NewMockStatusUpdateFn(errBoom)},
+				pr:     installing(),
+				p:      v1.PackageRevisionProgress{Phase: v1.PackageRevisionFetching},
+			},
+			want: want{
+				progress: &v1.PackageRevisionProgress{Phase: v1.PackageRevisionFetching},
+				err:      errors.Wrap(errBoom, errReportProgress),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := NewAPIProgressReporter(tc.args.client).Report(context.Background(), tc.args.pr, tc.args.p)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Report(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.progress, tc.args.pr.GetProgress()); diff != "" {
+				t.Errorf("\n%s\nr.Report(...): -want progress, +got progress:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package revision
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"strings"
 	"time"
@@ -27,11 +28,13 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
@@ -40,18 +43,28 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
-	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
 	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
 	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
 	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
 	"github.com/crossplane/crossplane/internal/dag"
+	"github.com/crossplane/crossplane/internal/events"
+	"github.com/crossplane/crossplane/internal/loglevel"
+	"github.com/crossplane/crossplane/internal/metrics"
 	"github.com/crossplane/crossplane/internal/version"
 	"github.com/crossplane/crossplane/internal/xpkg"
 )
 
 const (
 	reconcileTimeout = 3 * time.Minute
+
+	// maxRetries is the number of consecutive times a revision may fail to be
+	// fetched, parsed, linted, or established before we stop retrying it.
+	maxRetries = 10
+
+	// DeniedRecheckInterval is how often we ask whether a revision that was
+	// denied activation may now be activated.
+	DeniedRecheckInterval = 10 * time.Minute
 )
 
 const (
@@ -64,33 +77,55 @@ const (
 	errGetCache    = "cannot get package contents from cache"
 
 	errPullPolicyNever = "failed to get pre-cached package with pull policy Never"
+	errLocalNotCached  = "local package is not in the package cache; it must be loaded again"
 
 	errAddFinalizer    = "cannot add package revision finalizer"
 	errRemoveFinalizer = "cannot remove package revision finalizer"
 
 	errInitParserBackend = "cannot initialize parser backend"
 	errParsePackage      = "cannot parse package contents"
+	errCorruptCache      = "cached package contents are corrupt"
 	errLintPackage       = "linting package contents failed"
+	errUpconvertCRDs     = "cannot convert deprecated CustomResourceDefinitions"
 	errNotOneMeta        = "cannot install package with multiple meta types"
 	errIncompatible      = "incompatible Crossplane version"
+	errChannel           = "package is not in pinned channel"
 
-	errPreHook  = "cannot run pre establish hook for package"
-	errPostHook = "cannot run post establish hook for package"
+	errBuildHooks        = "cannot build package revision hooks"
+	errBuildTransformers = "cannot build package object transformers"
+	errFetchHook         = "cannot run fetch hook for package"
+	errVerifyHook        = "cannot run verify hook for package"
+	errPreHook           = "cannot run pre establish hook for package"
+	errPostHook          = "cannot run post establish hook for package"
 
 	errEstablishControl = "cannot establish control of object"
+	errTransformObjects = "cannot transform package objects"
+	errAdmitActivation  = "cannot determine whether package revision may be activated"
 
 	errUpdateAnnotations = "cannot update annotations for package revision"
 
 	errRemoveLock  = "cannot remove package revision from Lock"
 	errResolveDeps = "cannot resolve package dependencies"
+
+	errRemoveRetryAnnotation = "cannot remove retry annotation"
+	errFmtRetriesExhausted   = "stopped retrying after repeated failures; annotate the package revision with %q to retry"
+
+	msgLintWarnings            = "package broke lint rules"
+	msgFmtResolvedDependencies = "%d dependencies installed; %d optional dependencies missing"
+	msgFmtInvalidDependencies  = "%d of %d dependencies installed at an invalid version"
+	msgFmtMissingDependencies  = "%d of %d dependencies missing"
+	msgFmtConvertedCRDs        = "converted apiextensions.k8s.io/v1beta1 CustomResourceDefinitions to apiextensions.k8s.io/v1; the package should be rebuilt with v1 CustomResourceDefinitions: %s"
 )
 
 // Event reasons.
 const (
-	reasonParse        event.Reason = "ParsePackage"
-	reasonLint         event.Reason = "LintPackage"
-	reasonDependencies event.Reason = "ResolveDependencies"
-	reasonSync         event.Reason = "SyncPackage"
+	reasonParse          event.Reason = "ParsePackage"
+	reasonLint           event.Reason = "LintPackage"
+	reasonDependencies   event.Reason = "ResolveDependencies"
+	reasonSync           event.Reason = "SyncPackage"
+	reasonRetry          event.Reason = "RetryPackage"
+	reasonDeprecatedAPIs event.Reason = "DeprecatedAPIs"
+	reasonActivate       event.Reason = "ActivatePackage"
 )
 
 // ReconcilerOption is used to configure the Reconciler.
@@ -146,14 +181,48 @@ func WithDependencyManager(m DependencyManager) ReconcilerOption {
 	}
 }
 
-// WithHooks specifies how the Reconciler should perform pre and post object
-// establishment operations.
+// WithHooks specifies how the Reconciler should perform operations at each
+// stage of reconciling a package revision.
 func WithHooks(h Hooks) ReconcilerOption {
 	return func(r *Reconciler) {
 		r.hook = h
 	}
 }
 
+// WithRetryPolicy specifies how the Reconciler should decide whether to retry
+// a package revision that could not be fetched, parsed, linted, or
+// established.
+func WithRetryPolicy(p RetryPolicy) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.retries = p
+	}
+}
+
+// WithScopedCache specifies whether the Reconciler should cache the contents of
+// each revision alongside those of the other revisions of its package, rather
+// than those of every other package.
+func WithScopedCache(scoped bool) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.scopedCache = scoped
+	}
+}
+
+// WithObjectTransformer specifies how the Reconciler should transform package
+// objects before it establishes them.
+func WithObjectTransformer(t ObjectTransformer) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.transform = t
+	}
+}
+
+// WithActivationGate specifies how the Reconciler should determine whether a
+// package revision may be activated.
+func WithActivationGate(g ActivationGate) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.gate = g
+	}
+}
+
 // WithEstablisher specifies how the Reconciler should establish package resources.
 func WithEstablisher(e Establisher) ReconcilerOption {
 	return func(r *Reconciler) {
@@ -161,6 +230,14 @@ func WithEstablisher(e Establisher) ReconcilerOption {
 	}
 }
 
+// WithProgressReporter specifies how the Reconciler should report the
+// progress of a package revision's installation.
+func WithProgressReporter(p ProgressReporter) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.progress = p
+	}
+}
+
 // WithParser specifies how the Reconciler should parse a package.
 func WithParser(p parser.Parser) ReconcilerOption {
 	return func(r *Reconciler) {
@@ -198,12 +275,18 @@ type Reconciler struct {
 	lock      DependencyManager
 	hook      Hooks
 	objects   Establisher
+	transform ObjectTransformer
+	gate      ActivationGate
+	progress  ProgressReporter
 	parser    parser.Parser
 	linter    parser.Linter
 	versioner version.Operations
 	backend   parser.Backend
 	log       logging.Logger
 	record    event.Recorder
+	retries   RetryPolicy
+
+	scopedCache bool
 
 	newPackageRevision func() v1.PackageRevision
 }
@@ -231,28 +314,64 @@ func SetupProviderRevision(mgr ctrl.Manager, o controller.Options) error {
 		return errors.Wrap(err, "cannot build fetcher for package parser")
 	}
 
+	hooks, err := ProviderHookRegistry.Build(mgr, o)
+	if err != nil {
+		return errors.Wrap(err, errBuildHooks)
+	}
+	transformers, err := ObjectTransformerRegistry.Build(mgr, o)
+	if err != nil {
+		return errors.Wrap(err, errBuildTransformers)
+	}
+	hooks = append(HookChain{NewProviderHooks(resource.ClientApplicator{
+		Client:     mgr.GetClient(),
+		Applicator: NewServerSideApplicator(mgr.GetClient(), o.ApplyConflictPolicy),
+	}, o.Namespace,
+		WithControllerImagePolicy(NewAPIControllerImagePolicy(mgr.GetClient(), fetcher)),
+		WithSchedulingValidator(NewAPISchedulingValidator(mgr.GetAPIReader())),
+		WithRuntimeEventRecorder(events.NewDedupingRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)), o.EventSuppressionWindow)),
+		WithRuntimeMetricsRecorder(metrics.NewPrometheusRuntimeRecorder()),
+		WithDefaultDeploymentLabels(o.DefaultLabels),
+	)}, hooks...)
+
 	r := NewReconciler(mgr,
 		WithCache(o.Cache),
-		WithDependencyManager(NewPackageDependencyManager(mgr.GetClient(), dag.NewMapDag, v1beta1.ProviderPackageType)),
-		WithHooks(NewProviderHooks(resource.ClientApplicator{
-			Client:     mgr.GetClient(),
-			Applicator: resource.NewAPIPatchingApplicator(mgr.GetClient()),
-		}, o.Namespace)),
-		WithEstablisher(NewAPIEstablisher(mgr.GetClient(), o.Namespace)),
+		WithDependencyManager(NewPackageDependencyManager(mgr.GetClient(), dag.NewMapDag, v1beta1.ProviderPackageType, WithLockMetricsRecorder(metrics.NewPrometheusLockRecorder()), WithUnhealthyDependencyPolicy(o.UnhealthyDependencyPolicy))),
+		WithHooks(hooks),
+		WithObjectTransformer(transformers),
+		WithActivationGate(NewActivationGate(o, fetcher)),
+		WithEstablisher(NewAPIEstablisher(mgr.GetClient(), o.Namespace, WithApplyConflictPolicy(o.ApplyConflictPolicy), WithMaxConcurrency(o.MaxConcurrentEstablishers), WithEstablishProgressReporter(NewAPIProgressReporter(mgr.GetClient())))),
+		WithProgressReporter(NewAPIProgressReporter(mgr.GetClient())),
+		WithScopedCache(o.ScopedCache),
 		WithNewPackageRevisionFn(nr),
-		WithParser(parser.New(metaScheme, objScheme)),
+		WithParser(xpkg.NewStreamParser(metaScheme, objScheme)),
 		WithParserBackend(NewImageBackend(fetcher, WithDefaultRegistry(o.DefaultRegistry))),
 		WithLinter(xpkg.NewProviderLinter()),
 		WithLogger(o.Logger.WithValues("controller", name)),
-		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		WithRecorder(events.NewDedupingRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)), o.EventSuppressionWindow)),
+		WithRetryPolicy(NewBoundedRetryPolicy(maxRetries)),
 	)
 
-	return ctrl.NewControllerManagedBy(mgr).
+	b := ctrl.NewControllerManagedBy(mgr).
 		Named(name).
-		For(&v1.ProviderRevision{}).
-		Owns(&appsv1.Deployment{}).
-		Watches(&source.Kind{Type: &v1alpha1.ControllerConfig{}}, &EnqueueRequestForReferencingProviderRevisions{
-			client: mgr.GetClient(),
+		For(&v1.ProviderRevision{})
+
+	// We watch our runtime resources so that we can promptly repair them if
+	// they're changed or deleted out-of-band. When runtime repair is
+	// disabled we still need to observe our Deployment's availability.
+	if o.DisableRuntimeRepair {
+		b = b.Owns(&appsv1.Deployment{}, builder.WithPredicates(StatusChanged()))
+	} else {
+		b = b.Owns(&appsv1.Deployment{}).
+			Owns(&corev1.ServiceAccount{}).
+			Owns(&corev1.Service{})
+	}
+
+	return b.Watches(&source.Kind{Type: &v1alpha1.ControllerConfig{}}, &EnqueueRequestForReferencingProviderRevisions{
+		client: mgr.GetClient(),
+	}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, &EnqueueRequestForProviderRevisionsReferencingSecret{
+			client:    mgr.GetClient(),
+			namespace: o.Namespace,
 		}).
 		WithOptions(o.ForControllerRuntime()).
 		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
@@ -281,17 +400,31 @@ func SetupConfigurationRevision(mgr ctrl.Manager, o controller.Options) error {
 		return errors.Wrap(err, "cannot build fetcher for package parser")
 	}
 
+	hooks, err := ConfigurationHookRegistry.Build(mgr, o)
+	if err != nil {
+		return errors.Wrap(err, errBuildHooks)
+	}
+	transformers, err := ObjectTransformerRegistry.Build(mgr, o)
+	if err != nil {
+		return errors.Wrap(err, errBuildTransformers)
+	}
+
 	r := NewReconciler(mgr,
 		WithCache(o.Cache),
-		WithDependencyManager(NewPackageDependencyManager(mgr.GetClient(), dag.NewMapDag, v1beta1.ConfigurationPackageType)),
-		WithHooks(NewConfigurationHooks()),
+		WithDependencyManager(NewPackageDependencyManager(mgr.GetClient(), dag.NewMapDag, v1beta1.ConfigurationPackageType, WithLockMetricsRecorder(metrics.NewPrometheusLockRecorder()), WithUnhealthyDependencyPolicy(o.UnhealthyDependencyPolicy))),
+		WithHooks(append(HookChain{NewConfigurationHooks()}, hooks...)),
+		WithObjectTransformer(transformers),
+		WithActivationGate(NewActivationGate(o, f)),
 		WithNewPackageRevisionFn(nr),
-		WithEstablisher(NewAPIEstablisher(mgr.GetClient(), o.Namespace)),
-		WithParser(parser.New(metaScheme, objScheme)),
+		WithEstablisher(NewAPIEstablisher(mgr.GetClient(), o.Namespace, WithApplyConflictPolicy(o.ApplyConflictPolicy), WithMaxConcurrency(o.MaxConcurrentEstablishers), WithEstablishProgressReporter(NewAPIProgressReporter(mgr.GetClient())))),
+		WithProgressReporter(NewAPIProgressReporter(mgr.GetClient())),
+		WithScopedCache(o.ScopedCache),
+		WithParser(xpkg.NewStreamParser(metaScheme, objScheme)),
 		WithParserBackend(NewImageBackend(f, WithDefaultRegistry(o.DefaultRegistry))),
 		WithLinter(xpkg.NewConfigurationLinter()),
 		WithLogger(o.Logger.WithValues("controller", name)),
-		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		WithRecorder(events.NewDedupingRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name)), o.EventSuppressionWindow)),
+		WithRetryPolicy(NewBoundedRetryPolicy(maxRetries)),
 	)
 
 	return ctrl.NewControllerManagedBy(mgr).
@@ -310,11 +443,15 @@ func NewReconciler(mgr manager.Manager, opts ...ReconcilerOption) *Reconciler {
 		revision:  resource.NewAPIFinalizer(mgr.GetClient(), finalizer),
 		hook:      NewNopHooks(),
 		objects:   NewNopEstablisher(),
+		transform: ObjectTransformerChain{},
+		gate:      NewNopActivationGate(),
+		progress:  NewNopProgressReporter(),
 		parser:    parser.New(nil, nil),
 		linter:    parser.NewPackageLinter(nil, nil, nil),
 		versioner: version.New(),
 		log:       logging.NewNopLogger(),
 		record:    event.NewNopRecorder(),
+		retries:   NewNopRetryPolicy(),
 	}
 
 	for _, f := range opts {
@@ -340,13 +477,15 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetPackageRevision)
 	}
 
+	log = loglevel.ForObject(log, pr)
+
 	if meta.WasDeleted(pr) {
 		// NOTE(hasheddan): In the event that a pre-cached package was
 		// used for this revision, delete will not remove the pre-cached
 		// package image from the cache unless it has the same name as
 		// the provider revision. Delete will not return an error so we
 		// will remove finalizer and leave the image in the cache.
-		if err := r.cache.Delete(pr.GetName()); err != nil {
+		if err := r.cache.Delete(r.cacheID(pr)); err != nil {
 			log.Debug(errDeleteCache, "error", err)
 			err = errors.Wrap(err, errDeleteCache)
 			r.record.Event(pr, event.Warning(reasonSync, err))
@@ -367,6 +506,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			r.record.Event(pr, event.Warning(reasonSync, err))
 			return reconcile.Result{}, err
 		}
+		// Our retry policy may be tracking past failures of this revision,
+		// which we'll never need again.
+		r.retries.Reset(pr)
 		return reconcile.Result{Requeue: false}, nil
 	}
 
@@ -394,18 +536,63 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	// TODO(negz): Use Unhealthy().WithMessage(...) to supply error context?
 
 	pullPolicyNever := false
-	id := pr.GetName()
+	id := r.cacheID(pr)
 	// If packagePullPolicy is Never, the identifier is the package source and
 	// contents must be in the cache.
 	if pr.GetPackagePullPolicy() != nil && *pr.GetPackagePullPolicy() == corev1.PullNever {
 		pullPolicyNever = true
 		id = pr.GetSource()
 	}
+	// Local packages can't be pulled either. Their contents must be in the
+	// cache, for example because they were loaded by the CLI.
+	local := xpkg.IsLocalSource(pr.GetSource())
+	if local {
+		pullPolicyNever = true
+		id = xpkg.LocalCacheID(pr.GetSource())
+	}
+
+	// Anyone may ask us to retry a revision that we stopped retrying, or that
+	// seems to be stuck, by annotating it. We forget about past failures and
+	// any cached package contents, so that the package is fetched again.
+	if _, ok := pr.GetAnnotations()[v1.AnnotationRetry]; ok {
+		r.retries.Reset(pr)
+		if !pullPolicyNever {
+			if err := r.cache.Delete(id); err != nil {
+				log.Debug(errDeleteCache, "error", err)
+				err = errors.Wrap(err, errDeleteCache)
+				r.record.Event(pr, event.Warning(reasonRetry, err))
+				return reconcile.Result{}, err
+			}
+		}
+		meta.RemoveAnnotations(pr, v1.AnnotationRetry)
+		if err := r.client.Update(ctx, pr); err != nil {
+			log.Debug(errRemoveRetryAnnotation, "error", err)
+			err = errors.Wrap(err, errRemoveRetryAnnotation)
+			r.record.Event(pr, event.Warning(reasonRetry, err))
+			return reconcile.Result{}, err
+		}
+		r.record.Event(pr, event.Normal(reasonRetry, "Retrying package revision"))
+	}
+
+	r.report(ctx, log, pr, v1.PackageRevisionProgress{Phase: v1.PackageRevisionFetching})
 
 	var rc io.ReadCloser
 	cacheWrite := make(chan error)
 
-	if r.cache.Has(id) {
+	// Cached package contents that can't be parsed or linted may be corrupt.
+	// We clear them so that we fetch the package again when we retry, unless
+	// we're not allowed to fetch it.
+	fromCache := r.cache.Has(id)
+	clearCache := func() {
+		if !fromCache || pullPolicyNever {
+			return
+		}
+		if err := r.cache.Delete(id); err != nil {
+			log.Debug(errDeleteCache, "error", err)
+		}
+	}
+
+	if fromCache {
 		var err error
 		rc, err = r.cache.Get(id)
 		if err != nil {
@@ -427,8 +614,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 	// packagePullPolicy is Never and contents are not in the cache so we return
 	// an error.
 	if rc == nil && pullPolicyNever {
-		log.Debug(errPullPolicyNever)
-		err := errors.New(errPullPolicyNever)
+		msg := errPullPolicyNever
+		if local {
+			msg = errLocalNotCached
+		}
+		log.Debug(msg)
+		err := errors.New(msg)
 		r.record.Event(pr, event.Warning(reasonParse, err))
 		return reconcile.Result{}, err
 	}
@@ -446,7 +637,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			log.Debug(errInitParserBackend, "error", err)
 			err = errors.Wrap(err, errInitParserBackend)
 			r.record.Event(pr, event.Warning(reasonParse, err))
-			return reconcile.Result{}, err
+			return r.retry(pr, err)
 		}
 
 		// Package is not in cache, so we write it to the cache while parsing.
@@ -454,7 +645,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		rc = xpkg.TeeReadCloser(imgrc, pipeW)
 		go func() {
 			defer pipeR.Close() //nolint:errcheck
-			if err := r.cache.Store(pr.GetName(), pipeR); err != nil {
+			if err := r.cache.Store(id, pipeR); err != nil {
 				_ = pipeR.CloseWithError(err)
 				cacheWrite <- err
 				return
@@ -474,18 +665,64 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			log.Debug(errDeleteCache, "error", err)
 		}
 	}
+	// Corrupt cached contents say nothing about the package, so we fetch it
+	// again rather than report that it can't be parsed.
+	if fromCache && !pullPolicyNever && xpkg.IsCorrupt(err) {
+		log.Debug(errCorruptCache, "error", err)
+		clearCache()
+		r.record.Event(pr, event.Warning(reasonParse, errors.Wrap(err, errCorruptCache)))
+		return reconcile.Result{Requeue: true}, nil
+	}
+	pr.SetParseError(parseError(err))
 	if err != nil {
 		pr.SetConditions(v1.Unhealthy())
 		_ = r.client.Status().Update(ctx, pr)
 		log.Debug(errParsePackage, "error", err)
 
+		clearCache()
 		err = errors.Wrap(err, errParsePackage)
 		r.record.Event(pr, event.Warning(reasonParse, err))
+		return r.retry(pr, err)
+	}
+
+	// API servers no longer serve v1beta1 CRDs, so we convert any the
+	// package contains rather than failing to install them.
+	converted, err := xpkg.UpconvertCRDs(pkg.GetObjects())
+	if err != nil {
+		pr.SetConditions(v1.Unhealthy())
+		_ = r.client.Status().Update(ctx, pr)
+
+		log.Debug(errUpconvertCRDs, "error", err)
+		err = errors.Wrap(err, errUpconvertCRDs)
+		r.record.Event(pr, event.Warning(reasonParse, err))
+		return r.retry(pr, err)
+	}
+	if len(converted) > 0 {
+		msg := fmt.Sprintf(msgFmtConvertedCRDs, strings.Join(converted, ", "))
+		pr.SetConditions(v1.ConvertedDeprecatedAPIs(msg))
+		r.record.Event(pr, event.Warning(reasonDeprecatedAPIs, errors.New(msg)))
+	}
+
+	if err := r.hook.Fetch(ctx, pkg, pr); err != nil {
+		pr.SetConditions(v1.Unhealthy())
+		_ = r.client.Status().Update(ctx, pr)
+
+		log.Debug(errFetchHook, "error", err)
+		err = errors.Wrap(err, errFetchHook)
+		r.record.Event(pr, event.Warning(reasonParse, err))
 		return reconcile.Result{}, err
 	}
 
-	// Lint package using package-specific linter.
-	if err := r.linter.Lint(pkg); err != nil {
+	r.report(ctx, log, pr, v1.PackageRevisionProgress{Phase: v1.PackageRevisionVerifying})
+
+	// Lint package using package-specific linter. A package that breaks only
+	// rules of warning severity is still installed.
+	err = r.linter.Lint(pkg)
+	if xpkg.IsLintWarnings(err) {
+		r.record.Event(pr, event.Warning(reasonLint, errors.Wrap(err, msgLintWarnings)))
+		err = nil
+	}
+	if err != nil {
 		pr.SetConditions(v1.Unhealthy())
 		_ = r.client.Status().Update(ctx, pr)
 
@@ -493,10 +730,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		// intervention, but on the off chance that we read pod logs
 		// early, which caused a linting failure, we will requeue by
 		// returning an error.
+		clearCache()
 		err = errors.Wrap(err, errLintPackage)
 		log.Debug(errLintPackage, "error", err)
 		r.record.Event(pr, event.Warning(reasonLint, err))
-		return reconcile.Result{}, err
+		return r.retry(pr, err)
 	}
 
 	// NOTE(hasheddan): the linter should check this property already, but
@@ -512,9 +750,31 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, err
 	}
 
-	pkgMeta, _ := xpkg.TryConvert(pkg.GetMeta()[0], &pkgmetav1.Provider{}, &pkgmetav1.Configuration{})
+	// Operate on the hub version of the package's metadata, regardless of
+	// the version the package shipped.
+	pkgMeta, err := xpkg.ConvertToHub(pkg.GetMeta()[0])
+	if err != nil {
+		pr.SetConditions(v1.Unhealthy())
+		_ = r.client.Status().Update(ctx, pr)
+
+		log.Debug(errLintPackage, "error", err)
+		err = errors.Wrap(err, errLintPackage)
+		r.record.Event(pr, event.Warning(reasonLint, err))
+		return reconcile.Result{}, err
+	}
 
-	pr.SetAnnotations(pkgMeta.(metav1.ObjectMetaAccessor).GetObjectMeta().GetAnnotations())
+	// The revision's annotations are those of the package metadata, except
+	// the trace annotation, which only a human may set or remove.
+	anno := map[string]string{}
+	for k, v := range pkgMeta.(metav1.ObjectMetaAccessor).GetObjectMeta().GetAnnotations() {
+		if k != loglevel.AnnotationKeyTrace {
+			anno[k] = v
+		}
+	}
+	if v, ok := pr.GetAnnotations()[loglevel.AnnotationKeyTrace]; ok {
+		anno[loglevel.AnnotationKeyTrace] = v
+	}
+	pr.SetAnnotations(anno)
 	if err := r.client.Update(ctx, pr); err != nil {
 		pr.SetConditions(v1.Unhealthy())
 		_ = r.client.Status().Update(ctx, pr)
@@ -541,13 +801,41 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		}
 	}
 
+	// Check that the package is in the channel it's pinned to, if any.
+	if err := xpkg.PackageInChannel(pr.GetChannel())(pkgMeta); err != nil {
+		pr.SetConditions(v1.Unhealthy())
+
+		// No need to requeue. Either the package or its pinned channel
+		// will need to be updated, which will trigger a new reconcile.
+		log.Debug(errChannel, "error", err)
+		err = errors.Wrap(err, errChannel)
+		r.record.Event(pr, event.Warning(reasonLint, err))
+		return reconcile.Result{Requeue: false}, errors.Wrap(r.client.Status().Update(ctx, pr), errUpdateStatus)
+	}
+
+	if err := r.hook.Verify(ctx, pkgMeta, pr); err != nil {
+		pr.SetConditions(v1.Unhealthy())
+		_ = r.client.Status().Update(ctx, pr)
+
+		log.Debug(errVerifyHook, "error", err)
+		err = errors.Wrap(err, errVerifyHook)
+		r.record.Event(pr, event.Warning(reasonLint, err))
+		return reconcile.Result{}, err
+	}
+
+	r.report(ctx, log, pr, v1.PackageRevisionProgress{Phase: v1.PackageRevisionResolving})
+
 	// Check status of package dependencies unless package specifies to skip
 	// resolution.
 	if pr.GetSkipDependencyResolution() != nil && !*pr.GetSkipDependencyResolution() {
-		found, installed, invalid, err := r.lock.Resolve(ctx, pkgMeta, pr)
+		found, installed, invalid, optional, err := r.lock.Resolve(ctx, pkgMeta, pr)
 		pr.SetDependencyStatus(int64(found), int64(installed), int64(invalid))
-		if err != nil {
-			pr.SetConditions(v1.UnknownHealth())
+		pr.SetOptionalDependencyStatus(int64(optional))
+		pr.SetConditions(dependencies(found, installed, invalid, optional, err))
+		// Unhealthy dependencies that are still considered installed are
+		// only reported; they don't prevent us from installing the package.
+		if err != nil && !(IsDegradedDependencies(err) && installed == found) {
+			pr.SetConditions(unknownHealth(err))
 			_ = r.client.Status().Update(ctx, pr)
 
 			log.Debug(errResolveDeps, "error", err)
@@ -555,6 +843,36 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 			r.record.Event(pr, event.Warning(reasonDependencies, err))
 			return reconcile.Result{}, err
 		}
+	} else {
+		// Don't report counts from before dependency resolution was skipped.
+		pr.SetDependencyStatus(0, 0, 0)
+		pr.SetOptionalDependencyStatus(0)
+		pr.SetConditions(v1.SkippedDependencyResolution())
+	}
+
+	// A revision that may not be activated must not take control of its
+	// objects, so we stop before we touch them. The package manager asks
+	// the gate before it activates a revision, so we only ask about active
+	// revisions that weren't admitted, e.g. because they were activated
+	// manually. Revisions that are already healthy were activated before
+	// the gate was enabled.
+	if pr.GetDesiredState() == v1.PackageRevisionActive && pr.GetAdmittedPackage() == "" && pr.GetCondition(v1.TypeHealthy).Status != corev1.ConditionTrue {
+		id, err := r.gate.Admit(ctx, pr)
+		if err != nil {
+			if IsActivationDenied(err) {
+				pr.SetConditions(v1.PolicyDenied().WithMessage(err.Error()))
+				r.record.Event(pr, event.Warning(reasonActivate, err))
+				return reconcile.Result{RequeueAfter: DeniedRecheckInterval}, errors.Wrap(r.client.Status().Update(ctx, pr), errUpdateStatus)
+			}
+			pr.SetConditions(v1.UnknownHealth())
+			_ = r.client.Status().Update(ctx, pr)
+
+			log.Debug(errAdmitActivation, "error", err)
+			err = errors.Wrap(err, errAdmitActivation)
+			r.record.Event(pr, event.Warning(reasonActivate, err))
+			return reconcile.Result{}, err
+		}
+		pr.SetAdmittedPackage(id)
 	}
 
 	if err := r.hook.Pre(ctx, pkgMeta, pr); err != nil {
@@ -567,24 +885,43 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, err
 	}
 
-	// Establish control or ownership of objects.
-	refs, err := r.objects.Establish(ctx, pkg.GetObjects(), pr, pr.GetDesiredState() == v1.PackageRevisionActive)
+	objs, err := r.transform.Transform(ctx, pkg.GetObjects(), pr)
 	if err != nil {
 		pr.SetConditions(v1.Unhealthy())
 		_ = r.client.Status().Update(ctx, pr)
 
+		log.Debug(errTransformObjects, "error", err)
+		err = errors.Wrap(err, errTransformObjects)
+		r.record.Event(pr, event.Warning(reasonSync, err))
+		return reconcile.Result{}, err
+	}
+
+	r.report(ctx, log, pr, v1.PackageRevisionProgress{Phase: v1.PackageRevisionEstablishing, ObjectsTotal: int64(len(objs))})
+
+	// Establish control or ownership of objects.
+	refs, err := r.objects.Establish(ctx, objs, pr, pr.GetDesiredState() == v1.PackageRevisionActive)
+	if err != nil {
+		// Record the objects that were established, so that it's clear how
+		// far installation got before it failed.
+		if len(refs) > 0 {
+			pr.SetObjects(refs)
+		}
+		pr.SetConditions(unhealthy(err))
+		_ = r.client.Status().Update(ctx, pr)
+
 		log.Debug(errEstablishControl, "error", err)
 		err = errors.Wrap(err, errEstablishControl)
 		r.record.Event(pr, event.Warning(reasonSync, err))
-		return reconcile.Result{}, err
+		return r.retry(pr, err)
 	}
 
 	// Update object list in package revision status with objects for which
 	// ownership or control has been established.
 	pr.SetObjects(refs)
+	r.report(ctx, log, pr, v1.PackageRevisionProgress{Phase: v1.PackageRevisionInstalled, ObjectsApplied: int64(len(refs)), ObjectsTotal: int64(len(objs))})
 
 	if err := r.hook.Post(ctx, pkgMeta, pr); err != nil {
-		pr.SetConditions(v1.Unhealthy())
+		pr.SetConditions(unhealthy(err))
 		_ = r.client.Status().Update(ctx, pr)
 
 		log.Debug(errPostHook, "error", err)
@@ -593,7 +930,116 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		return reconcile.Result{}, err
 	}
 
+	r.retries.Reset(pr)
 	r.record.Event(pr, event.Normal(reasonSync, "Successfully configured package revision"))
 	pr.SetConditions(v1.Healthy())
+
+	// Nothing watches the resources created by a package's tests, so we check
+	// on them periodically until they pass or fail.
+	if testsRunning(pr.GetTestResults()) {
+		return reconcile.Result{RequeueAfter: testPollInterval}, errors.Wrap(r.client.Status().Update(ctx, pr), errUpdateStatus)
+	}
 	return reconcile.Result{Requeue: false}, errors.Wrap(r.client.Status().Update(ctx, pr), errUpdateStatus)
 }
+
+// unhealthy returns a condition indicating that a revision is unhealthy because
+// of the supplied error. Conflicts with other field managers and packages are
+// called out, because resolving them typically requires a human.
+func unhealthy(err error) xpv1.Condition {
+	switch {
+	case IsApplyConflict(err):
+		return v1.FieldConflict().WithMessage(err.Error())
+	case IsResourceConflict(err):
+		return v1.ResourceConflict().WithMessage(err.Error())
+	}
+	return v1.Unhealthy()
+}
+
+// dependencies returns a condition summarizing the supplied results of a
+// dependency resolution.
+func dependencies(found, installed, invalid, optional int, err error) xpv1.Condition {
+	switch {
+	case IsDegradedDependencies(err):
+		return v1.DegradedDependencies().WithMessage(err.Error())
+	case IsDependencyTypeMismatch(err):
+		return v1.DependencyTypeMismatch().WithMessage(err.Error())
+	case invalid > 0:
+		return v1.InvalidDependencies().WithMessage(messageOf(err, fmt.Sprintf(msgFmtInvalidDependencies, invalid, found)))
+	case installed < found:
+		return v1.MissingDependencies().WithMessage(messageOf(err, fmt.Sprintf(msgFmtMissingDependencies, found-installed, found)))
+	case err != nil:
+		return v1.UnknownDependencies().WithMessage(err.Error())
+	}
+	return v1.ResolvedDependencies().WithMessage(fmt.Sprintf(msgFmtResolvedDependencies, installed, optional))
+}
+
+// messageOf returns the message of the supplied error, or the supplied message
+// if the error is nil. Resolution doesn't return an error for the missing
+// dependencies of an inactive revision, for example.
+func messageOf(err error, msg string) string {
+	if err != nil {
+		return err.Error()
+	}
+	return msg
+}
+
+// unknownHealth returns a condition indicating that the health of a revision is
+// unknown because its dependencies could not be resolved. Contention for the
+// Lock is called out, because it's typically transient.
+func unknownHealth(err error) xpv1.Condition {
+	switch {
+	case IsWaitingOnLock(err):
+		return v1.WaitingOnLock().WithMessage(err.Error())
+	case IsLockConflict(err):
+		return v1.LockConflict().WithMessage(err.Error())
+	}
+	return v1.UnknownHealth()
+}
+
+// retry returns the supplied error, which causes the supplied revision to be
+// requeued with exponential backoff, unless our retry policy says we should
+// stop retrying it.
+func (r *Reconciler) retry(pr v1.PackageRevision, err error) (reconcile.Result, error) {
+	if r.retries.Failed(pr) {
+		return reconcile.Result{}, err
+	}
+	r.record.Event(pr, event.Warning(reasonRetry, errors.Errorf(errFmtRetriesExhausted, v1.AnnotationRetry)))
+	return reconcile.Result{Requeue: false}, nil
+}
+
+// cacheID returns the identifier under which the contents of the supplied
+// revision are cached, if they are fetched rather than pre-cached.
+func (r *Reconciler) cacheID(pr v1.PackageRevision) string {
+	pkg := pr.GetLabels()[v1.LabelParentPackage]
+	if !r.scopedCache || pkg == "" {
+		return pr.GetName()
+	}
+	return xpkg.ScopedCacheID(pkg, pr.GetName())
+}
+
+// report the supplied progress of the supplied package revision. Progress is
+// informational, so we don't fail to reconcile if we can't report it.
+func (r *Reconciler) report(ctx context.Context, log logging.Logger, pr v1.PackageRevision, p v1.PackageRevisionProgress) {
+	if err := r.progress.Report(ctx, pr, p); err != nil {
+		log.Debug(errReportProgress, "error", err)
+	}
+}
+
+// parseError returns a description of the object that caused the supplied
+// error parsing a package's contents, or nil if the error wasn't caused by an
+// object.
+func parseError(err error) *v1.PackageParseError {
+	pe := &xpkg.ParseError{}
+	if !errors.As(err, &pe) {
+		return nil
+	}
+	return &v1.PackageParseError{
+		Index:      int64(pe.Index),
+		Line:       int64(pe.Line),
+		APIVersion: pe.APIVersion,
+		Kind:       pe.Kind,
+		Name:       pe.Name,
+		Layer:      pe.Layer,
+		Message:    pe.Unwrap().Error(),
+	}
+}
@@ -18,11 +18,15 @@ package revision
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -42,6 +46,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/internal/loglevel"
 	verfake "github.com/crossplane/crossplane/internal/version/fake"
 	"github.com/crossplane/crossplane/internal/xpkg"
 	xpkgfake "github.com/crossplane/crossplane/internal/xpkg/fake"
@@ -78,8 +83,18 @@ func (e *MockEstablisher) Establish(context.Context, []runtime.Object, v1.Packag
 var _ Hooks = &MockHook{}
 
 type MockHook struct {
-	MockPre  func() error
-	MockPost func() error
+	MockFetch  func() error
+	MockVerify func() error
+	MockPre    func() error
+	MockPost   func() error
+}
+
+func NewMockFetchFn(err error) func() error {
+	return func() error { return err }
+}
+
+func NewMockVerifyFn(err error) func() error {
+	return func() error { return err }
 }
 
 func NewMockPreFn(err error) func() error {
@@ -90,6 +105,20 @@ func NewMockPostFn(err error) func() error {
 	return func() error { return err }
 }
 
+func (h *MockHook) Fetch(context.Context, *parser.Package, v1.PackageRevision) error {
+	if h.MockFetch == nil {
+		return nil
+	}
+	return h.MockFetch()
+}
+
+func (h *MockHook) Verify(context.Context, runtime.Object, v1.PackageRevision) error {
+	if h.MockVerify == nil {
+		return nil
+	}
+	return h.MockVerify()
+}
+
 func (h *MockHook) Pre(context.Context, runtime.Object, v1.PackageRevision) error {
 	return h.MockPre()
 }
@@ -119,19 +148,19 @@ func (fn MockParseFn) Parse(ctx context.Context, r io.ReadCloser) (*parser.Packa
 }
 
 type MockDependencyManager struct {
-	MockResolve    func() (int, int, int, error)
+	MockResolve    func() (int, int, int, int, error)
 	MockRemoveSelf func() error
 }
 
-func NewMockResolveFn(total, installed, invalid int, err error) func() (int, int, int, error) {
-	return func() (int, int, int, error) { return total, installed, invalid, err }
+func NewMockResolveFn(total, installed, invalid, optional int, err error) func() (int, int, int, int, error) {
+	return func() (int, int, int, int, error) { return total, installed, invalid, optional, err }
 }
 
 func NewMockRemoveSelfFn(err error) func() error {
 	return func() error { return err }
 }
 
-func (m *MockDependencyManager) Resolve(ctx context.Context, pkg runtime.Object, pr v1.PackageRevision) (int, int, int, error) {
+func (m *MockDependencyManager) Resolve(ctx context.Context, pkg runtime.Object, pr v1.PackageRevision) (int, int, int, int, error) {
 	return m.MockResolve()
 }
 
@@ -139,6 +168,18 @@ func (m *MockDependencyManager) RemoveSelf(ctx context.Context, pr v1.PackageRev
 	return m.MockRemoveSelf()
 }
 
+var v1beta1CRDBytes = []byte(`apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.org
+spec:
+  group: example.org
+  version: v1alpha1
+  scope: Cluster
+  names:
+    kind: Widget
+    plural: widgets`)
+
 var providerBytes = []byte(`apiVersion: meta.pkg.crossplane.io/v1
 kind: Provider
 metadata:
@@ -153,9 +194,32 @@ spec:
 
 func TestReconcile(t *testing.T) {
 	errBoom := errors.New("boom")
+	errContention := lockConflictError{errors.Wrap(kerrors.NewConflict(schema.GroupResource{Group: "pkg.crossplane.io", Resource: "locks"}, "lock", errBoom), errLockConflict)}
+	errConflict := kerrors.NewApplyConflict([]metav1.StatusCause{{Type: metav1.CauseTypeFieldManagerConflict, Message: `conflict with "kubectl"`, Field: ".spec.versions"}}, `Apply failed with 1 conflict: conflict with "kubectl": .spec.versions`)
 	now := metav1.Now()
 	pullPolicy := corev1.PullNever
 	trueVal := true
+	badJSON := "{}\n{\"kind\": }"
+	errBadJSON := json.Unmarshal([]byte(`{"kind": }`), &json.RawMessage{})
+
+	// corrupt is a cache whose contents no longer match their digest.
+	corruptFs := afero.NewMemMapFs()
+	corrupt := xpkg.NewFsPackageCache("/cache", corruptFs, xpkg.WithCorruptionDetection())
+	_ = corrupt.Store("test", io.NopCloser(bytes.NewBuffer(providerBytes)))
+	tampered := &bytes.Buffer{}
+	gw := gzip.NewWriter(tampered)
+	_, _ = gw.Write([]byte("tampered"))
+	_ = gw.Close()
+	_ = afero.WriteFile(corruptFs, "/cache/test.gz", tampered.Bytes(), 0644)
+
+	// phases we expect a revision to report, in order, as it's installed.
+	phases := []v1.PackageRevisionPhase{
+		v1.PackageRevisionFetching,
+		v1.PackageRevisionVerifying,
+		v1.PackageRevisionResolving,
+		v1.PackageRevisionEstablishing,
+		v1.PackageRevisionInstalled,
+	}
 
 	metaScheme, _ := xpkg.BuildMetaScheme()
 	objScheme, _ := xpkg.BuildObjectScheme()
@@ -520,8 +584,110 @@ func TestReconcile(t *testing.T) {
 				err: errors.Wrap(errBoom, errInitParserBackend),
 			},
 		},
+		"ErrInitParserBackendStopRetrying": {
+			reason: "We should not return an error or requeue if our retry policy says we should stop retrying.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ConfigurationRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ConfigurationRevision)
+								pr.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithCache(&xpkgfake.MockCache{
+						MockHas: xpkgfake.NewMockCacheHasFn(false),
+					}),
+					WithParserBackend(&ErrBackend{err: errBoom}),
+					WithRetryPolicy(NewBoundedRetryPolicy(1)),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
+		"ErrRemoveRetryAnnotation": {
+			reason: "We should return an error if we fail to remove the retry annotation.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ConfigurationRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ConfigurationRevision)
+								pr.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								pr.SetAnnotations(map[string]string{v1.AnnotationRetry: "now"})
+								return nil
+							}),
+							MockUpdate: test.NewMockUpdateFn(errBoom),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithCache(&xpkgfake.MockCache{
+						MockDelete: xpkgfake.NewMockCacheDeleteFn(nil),
+					}),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errRemoveRetryAnnotation),
+			},
+		},
+		"RetryAnnotation": {
+			reason: "We should clear the cache and remove the retry annotation before fetching the package again.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ConfigurationRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ConfigurationRevision)
+								pr.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								pr.SetAnnotations(map[string]string{v1.AnnotationRetry: "now"})
+								return nil
+							}),
+							MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+								if _, ok := o.GetAnnotations()[v1.AnnotationRetry]; ok {
+									t.Errorf("retry annotation was not removed")
+								}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithCache(&xpkgfake.MockCache{
+						MockDelete: xpkgfake.NewMockCacheDeleteFn(nil),
+						MockHas:    xpkgfake.NewMockCacheHasFn(false),
+					}),
+					WithParserBackend(&ErrBackend{err: errBoom}),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errInitParserBackend),
+			},
+		},
 		"ErrParseFromCache": {
-			reason: "We should return an error if fail to parse the package from the cache.",
+			reason: "We should return an error and clear the cache if we fail to parse the package from the cache.",
 			args: args{
 				mgr: &fake.Manager{},
 				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
@@ -553,8 +719,9 @@ func TestReconcile(t *testing.T) {
 					}}),
 					WithParser(MockParseFn(func(_ context.Context, _ io.ReadCloser) (*parser.Package, error) { return nil, errBoom })),
 					WithCache(&xpkgfake.MockCache{
-						MockHas: xpkgfake.NewMockCacheHasFn(true),
-						MockGet: xpkgfake.NewMockCacheGetFn(io.NopCloser(bytes.NewBuffer(providerBytes)), nil),
+						MockHas:    xpkgfake.NewMockCacheHasFn(true),
+						MockGet:    xpkgfake.NewMockCacheGetFn(io.NopCloser(bytes.NewBuffer(providerBytes)), nil),
+						MockDelete: xpkgfake.NewMockCacheDeleteFn(nil),
 					}),
 				},
 			},
@@ -562,6 +729,90 @@ func TestReconcile(t *testing.T) {
 				err: errors.Wrap(errBoom, errParsePackage),
 			},
 		},
+		"ErrParseObject": {
+			reason: "We should record which object of the package we failed to parse.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ConfigurationRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ConfigurationRevision)
+								pr.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ConfigurationRevision{}
+								want.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetConditions(v1.Unhealthy())
+								want.SetParseError(&v1.PackageParseError{
+									Index:   1,
+									Line:    2,
+									Message: errors.Wrap(errBadJSON, "cannot decode JSON object").Error(),
+								})
+
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithParser(xpkg.NewStreamParser(runtime.NewScheme(), runtime.NewScheme())),
+					WithCache(&xpkgfake.MockCache{
+						MockHas:    xpkgfake.NewMockCacheHasFn(true),
+						MockGet:    xpkgfake.NewMockCacheGetFn(io.NopCloser(bytes.NewBufferString(badJSON)), nil),
+						MockDelete: xpkgfake.NewMockCacheDeleteFn(nil),
+					}),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errors.Errorf("%s:2: object 1: %s", xpkg.StreamFile, errors.Wrap(errBadJSON, "cannot decode JSON object")), errParsePackage),
+			},
+		},
+		"CorruptCache": {
+			reason: "We should clear corrupt cached contents and requeue to fetch the package again.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ConfigurationRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ConfigurationRevision)
+								pr.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								pr.SetName("test")
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								t.Errorf("unexpected status update")
+								return nil
+							}),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithParser(MockParseFn(func(_ context.Context, rc io.ReadCloser) (*parser.Package, error) {
+						_, err := io.ReadAll(rc)
+						return nil, err
+					})),
+					WithCache(corrupt),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: true},
+			},
+		},
 		"ErrParseFromImage": {
 			reason: "We should return an error if we fail to parse the package from the image.",
 			args: args{
@@ -895,6 +1146,51 @@ func TestReconcile(t *testing.T) {
 				err: errors.Wrap(errBoom, errUpdateAnnotations),
 			},
 		},
+		"ReplaceAnnotations": {
+			reason: "We should replace the annotations of the revision with those of the package metadata, keeping only its trace annotation.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ConfigurationRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ConfigurationRevision)
+								pr.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								pr.SetAnnotations(map[string]string{loglevel.AnnotationKeyTrace: "true", "cool": "very"})
+								return nil
+							}),
+							MockUpdate: test.NewMockUpdateFn(errBoom, func(o client.Object) error {
+								want := map[string]string{loglevel.AnnotationKeyTrace: "true", "author": "crossplane"}
+								if diff := cmp.Diff(want, o.GetAnnotations()); diff != "" {
+									t.Errorf("-want annotations, +got annotations:\n%s", diff)
+								}
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithParser(parser.New(metaScheme, objScheme)),
+					WithParserBackend(parser.NewEchoBackend(string(providerBytes))),
+					WithCache(&xpkgfake.MockCache{
+						MockHas: xpkgfake.NewMockCacheHasFn(false),
+						MockStore: func(s string, rc io.ReadCloser) error {
+							_, err := io.ReadAll(rc)
+							return err
+						},
+					}),
+					WithLinter(&MockLinter{MockLint: NewMockLintFn(nil)}),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errUpdateAnnotations),
+			},
+		},
 		"ErrResolveDependencies": {
 			reason: "We should return an error if we fail to resolve dependencies.",
 			args: args{
@@ -903,7 +1199,7 @@ func TestReconcile(t *testing.T) {
 				rec: []ReconcilerOption{
 					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ProviderRevision{} }),
 					WithDependencyManager(&MockDependencyManager{
-						MockResolve: NewMockResolveFn(0, 0, 0, errBoom),
+						MockResolve: NewMockResolveFn(0, 0, 0, 0, errBoom),
 					}),
 					WithClientApplicator(resource.ClientApplicator{
 						Client: &test.MockClient{
@@ -920,7 +1216,7 @@ func TestReconcile(t *testing.T) {
 								want.SetDesiredState(v1.PackageRevisionActive)
 								want.SetSkipDependencyResolution(pointer.BoolPtr(false))
 								want.SetAnnotations(map[string]string{"author": "crossplane"})
-								want.SetConditions(v1.UnknownHealth())
+								want.SetConditions(v1.UnknownDependencies().WithMessage(errBoom.Error()), v1.UnknownHealth())
 
 								if diff := cmp.Diff(want, o); diff != "" {
 									t.Errorf("-want, +got:\n%s", diff)
@@ -960,27 +1256,32 @@ func TestReconcile(t *testing.T) {
 				err: errors.Wrap(errBoom, errResolveDeps),
 			},
 		},
-		"ErrPreHook": {
-			reason: "We should return an error if pre establishment hook returns an error.",
+		"ErrResolveDependenciesLockConflict": {
+			reason: "We should report that there is contention for the Lock if we fail to resolve dependencies because of it.",
 			args: args{
 				mgr: &fake.Manager{},
 				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
 				rec: []ReconcilerOption{
 					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ProviderRevision{} }),
+					WithDependencyManager(&MockDependencyManager{
+						MockResolve: NewMockResolveFn(0, 0, 0, 0, errContention),
+					}),
 					WithClientApplicator(resource.ClientApplicator{
 						Client: &test.MockClient{
 							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
 								pr := o.(*v1.ProviderRevision)
 								pr.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
 								pr.SetDesiredState(v1.PackageRevisionActive)
+								pr.SetSkipDependencyResolution(pointer.BoolPtr(false))
 								return nil
 							}),
 							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
 								want := &v1.ProviderRevision{}
 								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
 								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetSkipDependencyResolution(pointer.BoolPtr(false))
 								want.SetAnnotations(map[string]string{"author": "crossplane"})
-								want.SetConditions(v1.Unhealthy())
+								want.SetConditions(v1.UnknownDependencies().WithMessage(errContention.Error()), v1.LockConflict().WithMessage(errContention.Error()))
 
 								if diff := cmp.Diff(want, o); diff != "" {
 									t.Errorf("-want, +got:\n%s", diff)
@@ -992,6 +1293,7 @@ func TestReconcile(t *testing.T) {
 								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
 								want.SetDesiredState(v1.PackageRevisionActive)
 								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								want.SetSkipDependencyResolution(pointer.BoolPtr(false))
 								if diff := cmp.Diff(want, o); diff != "" {
 									t.Errorf("-want, +got:\n%s", diff)
 								}
@@ -1002,9 +1304,6 @@ func TestReconcile(t *testing.T) {
 					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
 						return nil
 					}}),
-					WithHooks(&MockHook{
-						MockPre: NewMockPreFn(errBoom),
-					}),
 					WithParser(parser.New(metaScheme, objScheme)),
 					WithParserBackend(parser.NewEchoBackend(string(providerBytes))),
 					WithCache(&xpkgfake.MockCache{
@@ -1019,11 +1318,11 @@ func TestReconcile(t *testing.T) {
 				},
 			},
 			want: want{
-				err: errors.Wrap(errBoom, errPreHook),
+				err: errors.Wrap(errContention, errResolveDeps),
 			},
 		},
-		"ErrPostHook": {
-			reason: "We should return an error if post establishment hook returns an error.",
+		"ErrFetchHook": {
+			reason: "We should return an error if the fetch hook returns an error.",
 			args: args{
 				mgr: &fake.Manager{},
 				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
@@ -1041,7 +1340,6 @@ func TestReconcile(t *testing.T) {
 								want := &v1.ProviderRevision{}
 								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
 								want.SetDesiredState(v1.PackageRevisionActive)
-								want.SetAnnotations(map[string]string{"author": "crossplane"})
 								want.SetConditions(v1.Unhealthy())
 
 								if diff := cmp.Diff(want, o); diff != "" {
@@ -1049,26 +1347,15 @@ func TestReconcile(t *testing.T) {
 								}
 								return nil
 							}),
-							MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
-								want := &v1.ProviderRevision{}
-								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
-								want.SetDesiredState(v1.PackageRevisionActive)
-								want.SetAnnotations(map[string]string{"author": "crossplane"})
-								if diff := cmp.Diff(want, o); diff != "" {
-									t.Errorf("-want, +got:\n%s", diff)
-								}
-								return nil
-							}),
+							MockUpdate: test.NewMockUpdateFn(nil),
 						},
 					}),
 					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
 						return nil
 					}}),
 					WithHooks(&MockHook{
-						MockPre:  NewMockPreFn(nil),
-						MockPost: NewMockPostFn(errBoom),
+						MockFetch: NewMockFetchFn(errBoom),
 					}),
-					WithEstablisher(NewMockEstablisher()),
 					WithParser(parser.New(metaScheme, objScheme)),
 					WithParserBackend(parser.NewEchoBackend(string(providerBytes))),
 					WithCache(&xpkgfake.MockCache{
@@ -1083,43 +1370,222 @@ func TestReconcile(t *testing.T) {
 				},
 			},
 			want: want{
-				err: errors.Wrap(errBoom, errPostHook),
+				err: errors.Wrap(errBoom, errFetchHook),
 			},
 		},
-		"SuccessfulActiveRevision": {
-			reason: "An active revision should establish control of all of its resources.",
+		"ErrVerifyHook": {
+			reason: "We should return an error if the verify hook returns an error.",
 			args: args{
 				mgr: &fake.Manager{},
 				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
 				rec: []ReconcilerOption{
-					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ConfigurationRevision{} }),
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ProviderRevision{} }),
 					WithClientApplicator(resource.ClientApplicator{
 						Client: &test.MockClient{
 							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
-								pr := o.(*v1.ConfigurationRevision)
-								pr.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								pr := o.(*v1.ProviderRevision)
+								pr.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
 								pr.SetDesiredState(v1.PackageRevisionActive)
 								return nil
 							}),
 							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
-								want := &v1.ConfigurationRevision{}
-								want.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								want := &v1.ProviderRevision{}
+								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
 								want.SetDesiredState(v1.PackageRevisionActive)
 								want.SetAnnotations(map[string]string{"author": "crossplane"})
-								want.SetConditions(v1.Healthy())
+								want.SetConditions(v1.Unhealthy())
 
 								if diff := cmp.Diff(want, o); diff != "" {
 									t.Errorf("-want, +got:\n%s", diff)
 								}
 								return nil
 							}),
-							MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
-								want := &v1.ConfigurationRevision{}
-								want.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
-								want.SetDesiredState(v1.PackageRevisionActive)
-								want.SetAnnotations(map[string]string{"author": "crossplane"})
-								if diff := cmp.Diff(want, o); diff != "" {
-									t.Errorf("-want, +got:\n%s", diff)
+							MockUpdate: test.NewMockUpdateFn(nil),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithHooks(&MockHook{
+						MockVerify: NewMockVerifyFn(errBoom),
+					}),
+					WithParser(parser.New(metaScheme, objScheme)),
+					WithParserBackend(parser.NewEchoBackend(string(providerBytes))),
+					WithCache(&xpkgfake.MockCache{
+						MockHas: xpkgfake.NewMockCacheHasFn(false),
+						MockStore: func(s string, rc io.ReadCloser) error {
+							_, err := io.ReadAll(rc)
+							return err
+						},
+					}),
+					WithLinter(&MockLinter{MockLint: NewMockLintFn(nil)}),
+					WithVersioner(&verfake.MockVersioner{MockInConstraints: verfake.NewMockInConstraintsFn(true, nil)}),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errVerifyHook),
+			},
+		},
+		"ErrPreHook": {
+			reason: "We should return an error if pre establishment hook returns an error.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ProviderRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ProviderRevision)
+								pr.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ProviderRevision{}
+								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								want.SetConditions(v1.SkippedDependencyResolution(), v1.Unhealthy())
+
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+							MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ProviderRevision{}
+								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithHooks(&MockHook{
+						MockPre: NewMockPreFn(errBoom),
+					}),
+					WithParser(parser.New(metaScheme, objScheme)),
+					WithParserBackend(parser.NewEchoBackend(string(providerBytes))),
+					WithCache(&xpkgfake.MockCache{
+						MockHas: xpkgfake.NewMockCacheHasFn(false),
+						MockStore: func(s string, rc io.ReadCloser) error {
+							_, err := io.ReadAll(rc)
+							return err
+						},
+					}),
+					WithLinter(&MockLinter{MockLint: NewMockLintFn(nil)}),
+					WithVersioner(&verfake.MockVersioner{MockInConstraints: verfake.NewMockInConstraintsFn(true, nil)}),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errPreHook),
+			},
+		},
+		"ErrPostHook": {
+			reason: "We should return an error if post establishment hook returns an error.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ProviderRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ProviderRevision)
+								pr.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ProviderRevision{}
+								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								want.SetConditions(v1.SkippedDependencyResolution(), v1.Unhealthy())
+
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+							MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ProviderRevision{}
+								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithHooks(&MockHook{
+						MockPre:  NewMockPreFn(nil),
+						MockPost: NewMockPostFn(errBoom),
+					}),
+					WithEstablisher(NewMockEstablisher()),
+					WithParser(parser.New(metaScheme, objScheme)),
+					WithParserBackend(parser.NewEchoBackend(string(providerBytes))),
+					WithCache(&xpkgfake.MockCache{
+						MockHas: xpkgfake.NewMockCacheHasFn(false),
+						MockStore: func(s string, rc io.ReadCloser) error {
+							_, err := io.ReadAll(rc)
+							return err
+						},
+					}),
+					WithLinter(&MockLinter{MockLint: NewMockLintFn(nil)}),
+					WithVersioner(&verfake.MockVersioner{MockInConstraints: verfake.NewMockInConstraintsFn(true, nil)}),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errPostHook),
+			},
+		},
+		"SuccessfulActiveRevision": {
+			reason: "An active revision should establish control of all of its resources.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ConfigurationRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ConfigurationRevision)
+								pr.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ConfigurationRevision{}
+								want.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								want.SetConditions(v1.SkippedDependencyResolution(), v1.Healthy())
+
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+							MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ConfigurationRevision{}
+								want.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
 								}
 								return nil
 							}),
@@ -1149,6 +1615,133 @@ func TestReconcile(t *testing.T) {
 				r: reconcile.Result{Requeue: false},
 			},
 		},
+		"SuccessfulReportProgress": {
+			reason: "A revision should report each phase of its installation, in order.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ConfigurationRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ConfigurationRevision)
+								pr.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ConfigurationRevision{}
+								want.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								want.SetConditions(v1.SkippedDependencyResolution(), v1.Healthy())
+								want.SetObjects([]xpv1.TypedReference{{Name: "cool"}})
+								want.SetProgress(&v1.PackageRevisionProgress{Phase: v1.PackageRevisionInstalled, ObjectsApplied: 1})
+
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+							MockUpdate: test.NewMockUpdateFn(nil),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithHooks(NewNopHooks()),
+					WithEstablisher(&MockEstablisher{MockEstablish: NewMockEstablishFn([]xpv1.TypedReference{{Name: "cool"}}, nil)}),
+					WithProgressReporter(ProgressReporterFn(func(_ context.Context, pr v1.PackageRevision, p v1.PackageRevisionProgress) error {
+						if len(phases) == 0 || phases[0] != p.Phase {
+							t.Errorf("unexpected progress phase %q", p.Phase)
+							return nil
+						}
+						phases = phases[1:]
+						pr.SetProgress(&p)
+						return nil
+					})),
+					WithParser(parser.New(metaScheme, objScheme)),
+					WithParserBackend(parser.NewEchoBackend(string(providerBytes))),
+					WithCache(&xpkgfake.MockCache{
+						MockHas: xpkgfake.NewMockCacheHasFn(false),
+						MockStore: func(s string, rc io.ReadCloser) error {
+							_, err := io.ReadAll(rc)
+							return err
+						},
+					}),
+					WithLinter(&MockLinter{MockLint: NewMockLintFn(nil)}),
+					WithVersioner(&verfake.MockVersioner{MockInConstraints: verfake.NewMockInConstraintsFn(true, nil)}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
+		"SuccessfulConvertDeprecatedCRDs": {
+			reason: "A revision whose package contains v1beta1 CRDs should convert them and say so in its conditions.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ConfigurationRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ConfigurationRevision)
+								pr.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ConfigurationRevision{}
+								want.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								want.SetConditions(v1.ConvertedDeprecatedAPIs(fmt.Sprintf(msgFmtConvertedCRDs, "widgets.example.org")), v1.SkippedDependencyResolution(), v1.Healthy())
+
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+							MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ConfigurationRevision{}
+								want.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								want.SetConditions(v1.ConvertedDeprecatedAPIs(fmt.Sprintf(msgFmtConvertedCRDs, "widgets.example.org")))
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+
+							MockDelete: test.NewMockDeleteFn(nil),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithHooks(NewNopHooks()),
+					WithEstablisher(NewMockEstablisher()),
+					WithParser(parser.New(metaScheme, objScheme)),
+					WithParserBackend(parser.NewEchoBackend(string(providerBytes) + "\n---\n" + string(v1beta1CRDBytes))),
+					WithCache(&xpkgfake.MockCache{
+						MockHas: xpkgfake.NewMockCacheHasFn(false),
+						MockStore: func(s string, rc io.ReadCloser) error {
+							_, err := io.ReadAll(rc)
+							return err
+						},
+					}),
+					WithLinter(&MockLinter{MockLint: NewMockLintFn(nil)}),
+					WithVersioner(&verfake.MockVersioner{MockInConstraints: verfake.NewMockInConstraintsFn(true, nil)}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{Requeue: false},
+			},
+		},
 		"SuccessfulActiveRevisionIgnoreConstraints": {
 			reason: "An active revision with incompatible Crossplane version should install successfully when constraints ignored.",
 			args: args{
@@ -1170,7 +1763,7 @@ func TestReconcile(t *testing.T) {
 								want.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
 								want.SetDesiredState(v1.PackageRevisionActive)
 								want.SetAnnotations(map[string]string{"author": "crossplane"})
-								want.SetConditions(v1.Healthy())
+								want.SetConditions(v1.SkippedDependencyResolution(), v1.Healthy())
 								want.SetIgnoreCrossplaneConstraints(&trueVal)
 
 								if diff := cmp.Diff(want, o); diff != "" {
@@ -1216,6 +1809,137 @@ func TestReconcile(t *testing.T) {
 				r: reconcile.Result{Requeue: false},
 			},
 		},
+		"ErrTransformObjects": {
+			reason: "A revision whose objects can't be transformed should return an error.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ProviderRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ProviderRevision)
+								pr.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ProviderRevision{}
+								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								want.SetConditions(v1.SkippedDependencyResolution(), v1.Unhealthy())
+
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+							MockDelete: test.NewMockDeleteFn(nil),
+							MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ProviderRevision{}
+								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithHooks(NewNopHooks()),
+					WithObjectTransformer(ObjectTransformerFn(func(_ context.Context, _ []runtime.Object, _ v1.PackageRevision) ([]runtime.Object, error) {
+						return nil, errBoom
+					})),
+					WithEstablisher(&MockEstablisher{
+						MockEstablish: func() ([]xpv1.TypedReference, error) {
+							t.Errorf("Establish(...): objects that couldn't be transformed should not be established")
+							return nil, nil
+						},
+					}),
+					WithParser(parser.New(metaScheme, objScheme)),
+					WithParserBackend(parser.NewEchoBackend(string(providerBytes))),
+					WithCache(&xpkgfake.MockCache{
+						MockHas: xpkgfake.NewMockCacheHasFn(false),
+						MockStore: func(s string, rc io.ReadCloser) error {
+							_, err := io.ReadAll(rc)
+							return err
+						},
+					}),
+					WithLinter(&MockLinter{MockLint: NewMockLintFn(nil)}),
+					WithVersioner(&verfake.MockVersioner{MockInConstraints: verfake.NewMockInConstraintsFn(true, nil)}),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errTransformObjects),
+			},
+		},
+		"ActivationDenied": {
+			reason: "A revision that is denied activation should not establish its objects.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ProviderRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ProviderRevision)
+								pr.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ProviderRevision{}
+								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								want.SetConditions(v1.SkippedDependencyResolution(), v1.PolicyDenied().WithMessage("critical vulnerabilities found"))
+
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+							MockDelete: test.NewMockDeleteFn(nil),
+							MockUpdate: test.NewMockUpdateFn(nil),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithHooks(NewNopHooks()),
+					WithActivationGate(ActivationGateFn(func(_ context.Context, _ v1.PackageRevision) (string, error) {
+						return "", activationDeniedError{errors.New("critical vulnerabilities found")}
+					})),
+					WithEstablisher(&MockEstablisher{
+						MockEstablish: func() ([]xpv1.TypedReference, error) {
+							t.Errorf("Establish(...): a revision that was denied activation should not establish its objects")
+							return nil, nil
+						},
+					}),
+					WithParser(parser.New(metaScheme, objScheme)),
+					WithParserBackend(parser.NewEchoBackend(string(providerBytes))),
+					WithCache(&xpkgfake.MockCache{
+						MockHas: xpkgfake.NewMockCacheHasFn(false),
+						MockStore: func(s string, rc io.ReadCloser) error {
+							_, err := io.ReadAll(rc)
+							return err
+						},
+					}),
+					WithLinter(&MockLinter{MockLint: NewMockLintFn(nil)}),
+					WithVersioner(&verfake.MockVersioner{MockInConstraints: verfake.NewMockInConstraintsFn(true, nil)}),
+				},
+			},
+			want: want{
+				r: reconcile.Result{RequeueAfter: DeniedRecheckInterval},
+			},
+		},
 		"ErrEstablishActiveRevision": {
 			reason: "An active revision that fails to establish control should return an error.",
 			args: args{
@@ -1236,7 +1960,7 @@ func TestReconcile(t *testing.T) {
 								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
 								want.SetDesiredState(v1.PackageRevisionActive)
 								want.SetAnnotations(map[string]string{"author": "crossplane"})
-								want.SetConditions(v1.Unhealthy())
+								want.SetConditions(v1.SkippedDependencyResolution(), v1.Unhealthy())
 
 								if diff := cmp.Diff(want, o); diff != "" {
 									t.Errorf("-want, +got:\n%s", diff)
@@ -1280,6 +2004,70 @@ func TestReconcile(t *testing.T) {
 				err: errors.Wrap(errBoom, errEstablishControl),
 			},
 		},
+		"ErrEstablishFieldConflict": {
+			reason: "An active revision that conflicts with another field manager should say so in its conditions.",
+			args: args{
+				mgr: &fake.Manager{},
+				req: reconcile.Request{NamespacedName: types.NamespacedName{Name: "test"}},
+				rec: []ReconcilerOption{
+					WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ProviderRevision{} }),
+					WithClientApplicator(resource.ClientApplicator{
+						Client: &test.MockClient{
+							MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+								pr := o.(*v1.ProviderRevision)
+								pr.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								pr.SetDesiredState(v1.PackageRevisionActive)
+								return nil
+							}),
+							MockStatusUpdate: test.NewMockStatusUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ProviderRevision{}
+								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								want.SetConditions(v1.SkippedDependencyResolution(), v1.FieldConflict().WithMessage(errConflict.Error()))
+
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+							MockDelete: test.NewMockDeleteFn(nil),
+							MockUpdate: test.NewMockUpdateFn(nil, func(o client.Object) error {
+								want := &v1.ProviderRevision{}
+								want.SetGroupVersionKind(v1.ProviderRevisionGroupVersionKind)
+								want.SetDesiredState(v1.PackageRevisionActive)
+								want.SetAnnotations(map[string]string{"author": "crossplane"})
+								if diff := cmp.Diff(want, o); diff != "" {
+									t.Errorf("-want, +got:\n%s", diff)
+								}
+								return nil
+							}),
+						},
+					}),
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error {
+						return nil
+					}}),
+					WithHooks(NewNopHooks()),
+					WithEstablisher(&MockEstablisher{
+						MockEstablish: NewMockEstablishFn(nil, errConflict),
+					}),
+					WithParser(parser.New(metaScheme, objScheme)),
+					WithParserBackend(parser.NewEchoBackend(string(providerBytes))),
+					WithCache(&xpkgfake.MockCache{
+						MockHas: xpkgfake.NewMockCacheHasFn(false),
+						MockStore: func(s string, rc io.ReadCloser) error {
+							_, err := io.ReadAll(rc)
+							return err
+						},
+					}),
+					WithLinter(&MockLinter{MockLint: NewMockLintFn(nil)}),
+					WithVersioner(&verfake.MockVersioner{MockInConstraints: verfake.NewMockInConstraintsFn(true, nil)}),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errConflict, errEstablishControl),
+			},
+		},
 		"SuccessfulInactiveRevision": {
 			reason: "An inactive revision should establish ownership of all of its resources.",
 			args: args{
@@ -1300,7 +2088,7 @@ func TestReconcile(t *testing.T) {
 								want.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
 								want.SetDesiredState(v1.PackageRevisionInactive)
 								want.SetAnnotations(map[string]string{"author": "crossplane"})
-								want.SetConditions(v1.Healthy())
+								want.SetConditions(v1.SkippedDependencyResolution(), v1.Healthy())
 
 								if diff := cmp.Diff(want, o); diff != "" {
 									t.Errorf("-want, +got:\n%s", diff)
@@ -1363,7 +2151,7 @@ func TestReconcile(t *testing.T) {
 								want.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
 								want.SetDesiredState(v1.PackageRevisionInactive)
 								want.SetAnnotations(map[string]string{"author": "crossplane"})
-								want.SetConditions(v1.Unhealthy())
+								want.SetConditions(v1.SkippedDependencyResolution(), v1.Unhealthy())
 
 								if diff := cmp.Diff(want, o); diff != "" {
 									t.Errorf("-want, +got:\n%s", diff)
@@ -1423,3 +2211,115 @@ func TestReconcile(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcileDeletedResetsRetries(t *testing.T) {
+	now := metav1.Now()
+	uid := types.UID("cool-uid")
+	retries := NewBoundedRetryPolicy(3)
+	retries.Failed(&v1.ConfigurationRevision{ObjectMeta: metav1.ObjectMeta{UID: uid}})
+
+	r := NewReconciler(&fake.Manager{},
+		WithNewPackageRevisionFn(func() v1.PackageRevision { return &v1.ConfigurationRevision{} }),
+		WithDependencyManager(&MockDependencyManager{
+			MockRemoveSelf: NewMockRemoveSelfFn(nil),
+		}),
+		WithClientApplicator(resource.ClientApplicator{
+			Client: &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(o client.Object) error {
+					pr := o.(*v1.ConfigurationRevision)
+					pr.SetGroupVersionKind(v1.ConfigurationRevisionGroupVersionKind)
+					pr.SetUID(uid)
+					pr.SetDeletionTimestamp(&now)
+					return nil
+				}),
+			},
+		}),
+		WithFinalizer(resource.FinalizerFns{RemoveFinalizerFn: func(_ context.Context, _ resource.Object) error {
+			return nil
+		}}),
+		WithRetryPolicy(retries),
+	)
+	if _, err := r.Reconcile(context.Background(), reconcile.Request{}); err != nil {
+		t.Fatalf("r.Reconcile(...): %s", err)
+	}
+	if diff := cmp.Diff(map[types.UID]int{}, retries.failures); diff != "" {
+		t.Errorf("r.Reconcile(...): want failures of a deleted revision to be forgotten: -want, +got:\n%s", diff)
+	}
+}
+
+func TestDependencies(t *testing.T) {
+	errBoom := errors.New("boom")
+	errMissing := errors.Errorf(errMissingDependenciesFmt, []string{"crossplane/provider-aws"})
+	errInvalid := errors.Errorf(errIncompatibleDependencyFmt, []string{"crossplane/provider-aws"})
+	errMismatch := dependencyTypeMismatchError{errors.Errorf(errDependencyTypeMismatchFmt, []string{"crossplane/provider-aws (Provider installed as Configuration)"})}
+	errDegraded := degradedDependenciesError{errors.Errorf(errDegradedDependenciesFmt, []string{"crossplane/provider-aws"})}
+
+	type args struct {
+		found     int
+		installed int
+		invalid   int
+		optional  int
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   xpv1.Condition
+	}{
+		"Resolved": {
+			reason: "We should report that dependencies are resolved, and how many, if resolution succeeded.",
+			args:   args{found: 3, installed: 3, optional: 1},
+			want:   v1.ResolvedDependencies().WithMessage("3 dependencies installed; 1 optional dependencies missing"),
+		},
+		"Missing": {
+			reason: "We should report that dependencies are missing if fewer were installed than found.",
+			args:   args{found: 3, installed: 2, err: errMissing},
+			want:   v1.MissingDependencies().WithMessage(errMissing.Error()),
+		},
+		"MissingInactive": {
+			reason: "We should report how many dependencies are missing if fewer were installed than found, even if resolution didn't return an error, as it doesn't for an inactive revision.",
+			args:   args{found: 3},
+			want:   v1.MissingDependencies().WithMessage("3 of 3 dependencies missing"),
+		},
+		"InvalidWithoutError": {
+			reason: "We should report how many dependencies are invalid even if resolution didn't return an error.",
+			args:   args{found: 3, installed: 3, invalid: 1},
+			want:   v1.InvalidDependencies().WithMessage("1 of 3 dependencies installed at an invalid version"),
+		},
+		"Invalid": {
+			reason: "We should report that dependencies are invalid if any are installed at an invalid version.",
+			args:   args{found: 3, installed: 3, invalid: 1, err: errInvalid},
+			want:   v1.InvalidDependencies().WithMessage(errInvalid.Error()),
+		},
+		"TypeMismatch": {
+			reason: "We should report that dependencies are installed as a different type of package, even though they're counted as invalid.",
+			args:   args{found: 3, installed: 3, invalid: 1, err: errMismatch},
+			want:   v1.DependencyTypeMismatch().WithMessage(errMismatch.Error()),
+		},
+		"Degraded": {
+			reason: "We should report that dependencies are degraded if any are unhealthy, even though they're counted as installed.",
+			args:   args{found: 3, installed: 3, err: errDegraded},
+			want:   v1.DegradedDependencies().WithMessage(errDegraded.Error()),
+		},
+		"DegradedNotInstalled": {
+			reason: "We should report that dependencies are degraded, rather than missing, if unhealthy dependencies aren't counted as installed.",
+			args:   args{found: 3, installed: 2, err: errDegraded},
+			want:   v1.DegradedDependencies().WithMessage(errDegraded.Error()),
+		},
+		"Unknown": {
+			reason: "We should report that dependencies are unknown if resolution failed for another reason.",
+			args:   args{err: errBoom},
+			want:   v1.UnknownDependencies().WithMessage(errBoom.Error()),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := dependencies(tc.args.found, tc.args.installed, tc.args.invalid, tc.args.optional, tc.args.err)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ndependencies(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
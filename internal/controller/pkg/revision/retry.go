@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+)
+
+// A RetryPolicy determines whether a package revision that could not be
+// fetched, parsed, linted or established should be retried automatically.
+type RetryPolicy interface {
+	// Failed records a failed attempt to reconcile the supplied revision. It
+	// returns false if the revision should not be retried automatically.
+	Failed(pr v1.PackageRevision) bool
+
+	// Reset forgets any failed attempts to reconcile the supplied revision.
+	Reset(pr v1.PackageRevision)
+}
+
+// A NopRetryPolicy always retries.
+type NopRetryPolicy struct{}
+
+// NewNopRetryPolicy returns a RetryPolicy that always retries.
+func NewNopRetryPolicy() NopRetryPolicy { return NopRetryPolicy{} }
+
+// Failed always returns true.
+func (NopRetryPolicy) Failed(_ v1.PackageRevision) bool { return true }
+
+// Reset does nothing.
+func (NopRetryPolicy) Reset(_ v1.PackageRevision) {}
+
+// A BoundedRetryPolicy retries a revision until it has failed a maximum number
+// of consecutive times. Failed attempts are tracked in memory, and are thus
+// forgotten when Crossplane restarts.
+type BoundedRetryPolicy struct {
+	max int
+
+	mx       sync.Mutex
+	failures map[types.UID]int
+}
+
+// NewBoundedRetryPolicy returns a RetryPolicy that retries a revision until it
+// has failed the supplied number of consecutive times.
+func NewBoundedRetryPolicy(max int) *BoundedRetryPolicy {
+	return &BoundedRetryPolicy{max: max, failures: make(map[types.UID]int)}
+}
+
+// Failed records a failed attempt to reconcile the supplied revision. It
+// returns false once the revision has failed the maximum number of times.
+func (p *BoundedRetryPolicy) Failed(pr v1.PackageRevision) bool {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.failures[pr.GetUID()]++
+	return p.failures[pr.GetUID()] < p.max
+}
+
+// Reset forgets any failed attempts to reconcile the supplied revision.
+func (p *BoundedRetryPolicy) Reset(pr v1.PackageRevision) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	delete(p.failures, pr.GetUID())
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+)
+
+var _ RetryPolicy = NopRetryPolicy{}
+var _ RetryPolicy = &BoundedRetryPolicy{}
+
+func TestBoundedRetryPolicy(t *testing.T) {
+	a := &v1.ProviderRevision{}
+	a.SetUID(types.UID("a"))
+	b := &v1.ProviderRevision{}
+	b.SetUID(types.UID("b"))
+
+	p := NewBoundedRetryPolicy(2)
+
+	if diff := cmp.Diff(true, p.Failed(a)); diff != "" {
+		t.Errorf("p.Failed(a): first failure: -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(false, p.Failed(a)); diff != "" {
+		t.Errorf("p.Failed(a): second failure: -want, +got:\n%s", diff)
+	}
+
+	// Failures of one revision should not count against another.
+	if diff := cmp.Diff(true, p.Failed(b)); diff != "" {
+		t.Errorf("p.Failed(b): first failure: -want, +got:\n%s", diff)
+	}
+
+	p.Reset(a)
+	if diff := cmp.Diff(true, p.Failed(a)); diff != "" {
+		t.Errorf("p.Failed(a): failure after reset: -want, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	nodev1 "k8s.io/api/node/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errGetPriorityClass   = "cannot get priority class"
+	errGetRuntimeClass    = "cannot get runtime class"
+	errListNodes          = "cannot list nodes"
+	errFmtNoPriorityClass = "priority class %q does not exist"
+	errFmtNoRuntimeClass  = "runtime class %q does not exist"
+	errFmtNoMatchingNodes = "no nodes match node selector %v"
+)
+
+// A SchedulingValidator determines whether a provider's controller pods can be
+// scheduled as configured.
+type SchedulingValidator interface {
+	// Validate returns an error if pods with the supplied spec cannot be
+	// scheduled.
+	Validate(ctx context.Context, spec corev1.PodSpec) error
+}
+
+// A SchedulingValidatorFn is a function that satisfies SchedulingValidator.
+type SchedulingValidatorFn func(ctx context.Context, spec corev1.PodSpec) error
+
+// Validate returns an error if pods with the supplied spec cannot be scheduled.
+func (fn SchedulingValidatorFn) Validate(ctx context.Context, spec corev1.PodSpec) error {
+	return fn(ctx, spec)
+}
+
+// NopSchedulingValidator considers any pod spec schedulable.
+type NopSchedulingValidator struct{}
+
+// NewNopSchedulingValidator creates a new NopSchedulingValidator.
+func NewNopSchedulingValidator() *NopSchedulingValidator {
+	return &NopSchedulingValidator{}
+}
+
+// Validate does nothing.
+func (v *NopSchedulingValidator) Validate(_ context.Context, _ corev1.PodSpec) error {
+	return nil
+}
+
+// An APISchedulingValidator validates the scheduling configuration of a pod
+// spec against the priority classes, runtime classes, and nodes in the API
+// server.
+type APISchedulingValidator struct {
+	client client.Reader
+}
+
+// NewAPISchedulingValidator creates a new APISchedulingValidator. The supplied
+// reader should not be backed by a cache; we don't want to cache every node in
+// the cluster.
+func NewAPISchedulingValidator(c client.Reader) *APISchedulingValidator {
+	return &APISchedulingValidator{client: c}
+}
+
+// Validate returns an error if the supplied pod spec uses a priority class or
+// runtime class that doesn't exist, or a node selector that matches no nodes.
+func (v *APISchedulingValidator) Validate(ctx context.Context, spec corev1.PodSpec) error {
+	if n := spec.PriorityClassName; n != "" {
+		err := v.client.Get(ctx, types.NamespacedName{Name: n}, &schedulingv1.PriorityClass{})
+		if kerrors.IsNotFound(err) {
+			return errors.Errorf(errFmtNoPriorityClass, n)
+		}
+		if err != nil {
+			return errors.Wrap(err, errGetPriorityClass)
+		}
+	}
+
+	if spec.RuntimeClassName != nil && *spec.RuntimeClassName != "" {
+		n := *spec.RuntimeClassName
+		err := v.client.Get(ctx, types.NamespacedName{Name: n}, &nodev1.RuntimeClass{})
+		if kerrors.IsNotFound(err) {
+			return errors.Errorf(errFmtNoRuntimeClass, n)
+		}
+		if err != nil {
+			return errors.Wrap(err, errGetRuntimeClass)
+		}
+	}
+
+	if len(spec.NodeSelector) > 0 {
+		l := &corev1.NodeList{}
+		if err := v.client.List(ctx, l, client.MatchingLabels(spec.NodeSelector), client.Limit(1)); err != nil {
+			return errors.Wrap(err, errListNodes)
+		}
+		if len(l.Items) == 0 {
+			return errors.Errorf(errFmtNoMatchingNodes, spec.NodeSelector)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestAPISchedulingValidatorValidate(t *testing.T) {
+	errBoom := errors.New("boom")
+	errNotFound := kerrors.NewNotFound(schema.GroupResource{}, "")
+	selector := map[string]string{"pool": "crossplane"}
+
+	type args struct {
+		client client.Reader
+		spec   corev1.PodSpec
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"NoSchedulingHints": {
+			reason: "A pod spec without scheduling hints should be schedulable.",
+			args: args{
+				client: &test.MockClient{},
+				spec:   corev1.PodSpec{},
+			},
+			want: nil,
+		},
+		"AllExist": {
+			reason: "A pod spec whose priority class and runtime class exist, and whose node selector matches a node, should be schedulable.",
+			args: args{
+				client: &test.MockClient{
+					MockGet: test.NewMockGetFn(nil),
+					MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+						o.(*corev1.NodeList).Items = []corev1.Node{{}}
+						return nil
+					}),
+				},
+				spec: corev1.PodSpec{
+					PriorityClassName: "high",
+					RuntimeClassName:  pointer.String("gvisor"),
+					NodeSelector:      selector,
+				},
+			},
+			want: nil,
+		},
+		"NoPriorityClass": {
+			reason: "A pod spec whose priority class doesn't exist should not be schedulable.",
+			args: args{
+				client: &test.MockClient{MockGet: test.NewMockGetFn(errNotFound)},
+				spec:   corev1.PodSpec{PriorityClassName: "high"},
+			},
+			want: errors.Errorf(errFmtNoPriorityClass, "high"),
+		},
+		"ErrGetPriorityClass": {
+			reason: "We should return any error encountered getting the priority class.",
+			args: args{
+				client: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				spec:   corev1.PodSpec{PriorityClassName: "high"},
+			},
+			want: errors.Wrap(errBoom, errGetPriorityClass),
+		},
+		"NoRuntimeClass": {
+			reason: "A pod spec whose runtime class doesn't exist should not be schedulable.",
+			args: args{
+				client: &test.MockClient{MockGet: test.NewMockGetFn(errNotFound)},
+				spec:   corev1.PodSpec{RuntimeClassName: pointer.String("gvisor")},
+			},
+			want: errors.Errorf(errFmtNoRuntimeClass, "gvisor"),
+		},
+		"NoMatchingNodes": {
+			reason: "A pod spec whose node selector matches no nodes should not be schedulable.",
+			args: args{
+				client: &test.MockClient{MockList: test.NewMockListFn(nil)},
+				spec:   corev1.PodSpec{NodeSelector: selector},
+			},
+			want: errors.Errorf(errFmtNoMatchingNodes, selector),
+		},
+		"ErrListNodes": {
+			reason: "We should return any error encountered listing nodes.",
+			args: args{
+				client: &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+				spec:   corev1.PodSpec{NodeSelector: selector},
+			},
+			want: errors.Wrap(errBoom, errListNodes),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			v := NewAPISchedulingValidator(tc.args.client)
+			err := v.Validate(context.TODO(), tc.args.spec)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nv.Validate(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+	"github.com/crossplane/crossplane/internal/features"
+)
+
+const (
+	errFmtBuildTransformer = "cannot build object transformer %q"
+	errFmtTransform        = "object transformer %q failed"
+	errFmtObjectMeta       = "cannot get metadata of package object %d"
+)
+
+// ObjectTransformerRegistry registers the transformers that process the objects
+// of every package revision before they are installed.
+var ObjectTransformerRegistry = &TransformerRegistry{}
+
+// An ObjectTransformer processes the objects of a package revision before they
+// are installed. It may modify, add, or remove objects.
+type ObjectTransformer interface {
+	// Transform the supplied objects of the supplied package revision,
+	// returning the objects that should be installed.
+	Transform(ctx context.Context, objs []runtime.Object, pr v1.PackageRevision) ([]runtime.Object, error)
+}
+
+// An ObjectTransformerFn processes the objects of a package revision before
+// they are installed.
+type ObjectTransformerFn func(ctx context.Context, objs []runtime.Object, pr v1.PackageRevision) ([]runtime.Object, error)
+
+// Transform the supplied objects of the supplied package revision.
+func (fn ObjectTransformerFn) Transform(ctx context.Context, objs []runtime.Object, pr v1.PackageRevision) ([]runtime.Object, error) {
+	return fn(ctx, objs, pr)
+}
+
+// An ObjectTransformerChain runs multiple transformers in order, each
+// processing the objects returned by the last. It returns the first error it
+// encounters.
+type ObjectTransformerChain []ObjectTransformer
+
+// Transform the supplied objects by running each transformer in the chain.
+func (c ObjectTransformerChain) Transform(ctx context.Context, objs []runtime.Object, pr v1.PackageRevision) ([]runtime.Object, error) {
+	for _, t := range c {
+		var err error
+		objs, err = t.Transform(ctx, objs, pr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return objs, nil
+}
+
+// A namedTransformer identifies the registered transformer that failed.
+type namedTransformer struct {
+	name string
+	t    ObjectTransformer
+}
+
+func (n namedTransformer) Transform(ctx context.Context, objs []runtime.Object, pr v1.PackageRevision) ([]runtime.Object, error) {
+	objs, err := n.t.Transform(ctx, objs, pr)
+	return objs, errors.Wrapf(err, errFmtTransform, n.name)
+}
+
+// A TransformerFactory builds an ObjectTransformer for a revision controller.
+type TransformerFactory func(mgr ctrl.Manager, o controller.Options) (ObjectTransformer, error)
+
+type registeredTransformer struct {
+	name string
+	new  TransformerFactory
+}
+
+// A TransformerRegistry registers transformers that process package objects
+// when the EnableAlphaObjectTransformers feature flag is enabled. Transformers
+// are typically registered by an init function, and run in the order they were
+// registered.
+type TransformerRegistry struct {
+	mx           sync.RWMutex
+	transformers []registeredTransformer
+}
+
+// Register the supplied TransformerFactory under the supplied name, which is
+// used to identify the transformer in errors.
+func (r *TransformerRegistry) Register(name string, fn TransformerFactory) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.transformers = append(r.transformers, registeredTransformer{name: name, new: fn})
+}
+
+// Build the registered transformers, if the EnableAlphaObjectTransformers
+// feature flag is enabled by the supplied options.
+func (r *TransformerRegistry) Build(mgr ctrl.Manager, o controller.Options) (ObjectTransformerChain, error) {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+
+	c := ObjectTransformerChain{}
+	if !o.Features.Enabled(features.EnableAlphaObjectTransformers) {
+		return c, nil
+	}
+	for _, rt := range r.transformers {
+		t, err := rt.new(mgr, o)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtBuildTransformer, rt.name)
+		}
+		c = append(c, namedTransformer{name: rt.name, t: t})
+	}
+	return c, nil
+}
+
+// NewLabelTransformer returns an ObjectTransformer that adds the supplied
+// labels to every package object. Labels the object already has take
+// precedence.
+func NewLabelTransformer(labels map[string]string) ObjectTransformerFn {
+	return func(_ context.Context, objs []runtime.Object, _ v1.PackageRevision) ([]runtime.Object, error) {
+		for i, o := range objs {
+			m, err := meta.Accessor(o)
+			if err != nil {
+				return nil, errors.Wrapf(err, errFmtObjectMeta, i)
+			}
+			l := m.GetLabels()
+			if l == nil {
+				l = make(map[string]string, len(labels))
+			}
+			for k, v := range labels {
+				if _, ok := l[k]; !ok {
+					l[k] = v
+				}
+			}
+			m.SetLabels(l)
+		}
+		return objs, nil
+	}
+}
+
+// NewObjectFilter returns an ObjectTransformer that installs only the package
+// objects for which the supplied function returns true.
+func NewObjectFilter(keep func(o runtime.Object) bool) ObjectTransformerFn {
+	return func(_ context.Context, objs []runtime.Object, _ v1.PackageRevision) ([]runtime.Object, error) {
+		out := make([]runtime.Object, 0, len(objs))
+		for _, o := range objs {
+			if keep(o) {
+				out = append(out, o)
+			}
+		}
+		return out, nil
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package revision
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/internal/controller/pkg/controller"
+	"github.com/crossplane/crossplane/internal/features"
+)
+
+func TestTransformerRegistryBuild(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	// Each transformer appends the name of a CRD, so that we can tell which
+	// transformers ran, and in what order.
+	appendCRD := func(name string) TransformerFactory {
+		return func(_ ctrl.Manager, _ controller.Options) (ObjectTransformer, error) {
+			return ObjectTransformerFn(func(_ context.Context, objs []runtime.Object, _ v1.PackageRevision) ([]runtime.Object, error) {
+				return append(objs, &extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: name}}), nil
+			}), nil
+		}
+	}
+	fail := func(_ ctrl.Manager, _ controller.Options) (ObjectTransformer, error) {
+		return ObjectTransformerFn(func(_ context.Context, _ []runtime.Object, _ v1.PackageRevision) ([]runtime.Object, error) {
+			return nil, errBoom
+		}), nil
+	}
+
+	type args struct {
+		register func(r *TransformerRegistry)
+		enabled  bool
+	}
+	type want struct {
+		buildErr     error
+		objs         []runtime.Object
+		transformErr error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Disabled": {
+			reason: "We should not build any transformers if the feature flag is not enabled.",
+			args: args{
+				register: func(r *TransformerRegistry) {
+					r.Register("a", appendCRD("a"))
+				},
+			},
+			want: want{
+				objs: []runtime.Object{},
+			},
+		},
+		"Enabled": {
+			reason: "We should build the transformers in the order they were registered.",
+			args: args{
+				register: func(r *TransformerRegistry) {
+					r.Register("b", appendCRD("b"))
+					r.Register("a", appendCRD("a"))
+				},
+				enabled: true,
+			},
+			want: want{
+				objs: []runtime.Object{
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+				},
+			},
+		},
+		"ErrBuild": {
+			reason: "We should return any error encountered building transformers.",
+			args: args{
+				register: func(r *TransformerRegistry) {
+					r.Register("a", func(_ ctrl.Manager, _ controller.Options) (ObjectTransformer, error) { return nil, errBoom })
+				},
+				enabled: true,
+			},
+			want: want{
+				buildErr: errors.Wrapf(errBoom, errFmtBuildTransformer, "a"),
+			},
+		},
+		"ErrTransform": {
+			reason: "We should identify the transformer that failed.",
+			args: args{
+				register: func(r *TransformerRegistry) {
+					r.Register("a", appendCRD("a"))
+					r.Register("b", fail)
+				},
+				enabled: true,
+			},
+			want: want{
+				transformErr: errors.Wrapf(errBoom, errFmtTransform, "b"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &TransformerRegistry{}
+			tc.args.register(r)
+
+			f := &feature.Flags{}
+			if tc.args.enabled {
+				f.Enable(features.EnableAlphaObjectTransformers)
+			}
+
+			c, err := r.Build(nil, controller.Options{Features: f})
+			if diff := cmp.Diff(tc.want.buildErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nBuild(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+
+			objs, err := c.Transform(context.Background(), []runtime.Object{}, &v1.ProviderRevision{})
+			if diff := cmp.Diff(tc.want.transformErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nTransform(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.objs, objs); diff != "" {
+				t.Errorf("\n%s\nTransform(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLabelTransformer(t *testing.T) {
+	type want struct {
+		objs []runtime.Object
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		labels map[string]string
+		objs   []runtime.Object
+		want   want
+	}{
+		"AddLabels": {
+			reason: "We should add labels to objects, without overwriting the labels they already have.",
+			labels: map[string]string{"team": "platform", "env": "prod"},
+			objs: []runtime.Object{
+				&extv1.CustomResourceDefinition{},
+				&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"env": "dev"}}},
+			},
+			want: want{
+				objs: []runtime.Object{
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "platform", "env": "prod"}}},
+					&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "platform", "env": "dev"}}},
+				},
+			},
+		},
+		"ErrObjectMeta": {
+			reason: "We should return an error if an object has no metadata.",
+			labels: map[string]string{"team": "platform"},
+			objs:   []runtime.Object{&metav1.Status{}},
+			want: want{
+				err: errors.Wrapf(errors.New("object does not implement the Object interfaces"), errFmtObjectMeta, 0),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			objs, err := NewLabelTransformer(tc.labels).Transform(context.Background(), tc.objs, &v1.ProviderRevision{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nTransform(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.objs, objs); diff != "" {
+				t.Errorf("\n%s\nTransform(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObjectFilter(t *testing.T) {
+	keep := func(o runtime.Object) bool {
+		crd, ok := o.(*extv1.CustomResourceDefinition)
+		return ok && crd.GetName() != "drop"
+	}
+	objs := []runtime.Object{
+		&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "keep"}},
+		&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "drop"}},
+	}
+	want := []runtime.Object{
+		&extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "keep"}},
+	}
+
+	got, err := NewObjectFilter(keep).Transform(context.Background(), objs, &v1.ProviderRevision{})
+	if err != nil {
+		t.Errorf("Transform(...): %s", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Transform(...): -want, +got:\n%s", diff)
+	}
+}
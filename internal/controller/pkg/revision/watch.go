@@ -18,12 +18,15 @@ package revision
 
 import (
 	"context"
+	"reflect"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
@@ -85,3 +88,104 @@ func (e *EnqueueRequestForReferencingProviderRevisions) add(obj runtime.Object,
 		}
 	}
 }
+
+// EnqueueRequestForProviderRevisionsReferencingSecret enqueues a request for
+// all provider revisions whose ControllerConfig asks for their controller to
+// be restarted when the given Secret changes.
+type EnqueueRequestForProviderRevisionsReferencingSecret struct {
+	client    client.Client
+	namespace string
+}
+
+// Create enqueues a request for all provider revisions that should be
+// restarted when a given Secret changes.
+func (e *EnqueueRequestForProviderRevisionsReferencingSecret) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Update enqueues a request for all provider revisions that should be
+// restarted when a given Secret changes, if its data changed.
+func (e *EnqueueRequestForProviderRevisionsReferencingSecret) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	o, ok := evt.ObjectOld.(*corev1.Secret)
+	if !ok {
+		return
+	}
+	n, ok := evt.ObjectNew.(*corev1.Secret)
+	if !ok || reflect.DeepEqual(o.Data, n.Data) {
+		return
+	}
+	e.add(n, q)
+}
+
+// Delete enqueues a request for all provider revisions that should be
+// restarted when a given Secret changes.
+func (e *EnqueueRequestForProviderRevisionsReferencingSecret) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+// Generic enqueues a request for all provider revisions that should be
+// restarted when a given Secret changes.
+func (e *EnqueueRequestForProviderRevisionsReferencingSecret) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	e.add(evt.Object, q)
+}
+
+func (e *EnqueueRequestForProviderRevisionsReferencingSecret) add(obj runtime.Object, queue adder) {
+	s, ok := obj.(*corev1.Secret)
+	if !ok || s.GetNamespace() != e.namespace {
+		return
+	}
+
+	ccl := &v1alpha1.ControllerConfigList{}
+	if err := e.client.List(context.TODO(), ccl); err != nil {
+		return
+	}
+	restart := map[string]bool{}
+	for _, cc := range ccl.Items {
+		if cc.Spec.RestartOnSecretChange != nil && *cc.Spec.RestartOnSecretChange && referencesSecret(cc.Spec, s.GetName()) {
+			restart[cc.GetName()] = true
+		}
+	}
+	if len(restart) == 0 {
+		return
+	}
+
+	l := &v1.ProviderRevisionList{}
+	if err := e.client.List(context.TODO(), l); err != nil {
+		return
+	}
+	for _, pr := range l.Items {
+		ref := pr.GetControllerConfigRef()
+		if ref != nil && restart[ref.Name] {
+			queue.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: pr.GetName()}})
+		}
+	}
+}
+
+// referencesSecret returns true if the env or envFrom of the supplied
+// ControllerConfig reference the named Secret.
+func referencesSecret(spec v1alpha1.ControllerConfigSpec, name string) bool {
+	for _, ef := range spec.EnvFrom {
+		if ef.SecretRef != nil && ef.SecretRef.Name == name {
+			return true
+		}
+	}
+	for _, e := range spec.Env {
+		if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil && e.ValueFrom.SecretKeyRef.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChanged returns a predicate that accepts only creation events, and
+// updates that don't change an object's generation, i.e. that typically
+// change only its status. It ignores deletion and spec changes made
+// out-of-band, so a revision does not immediately repair them.
+func StatusChanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.ObjectOld.GetGeneration() == e.ObjectNew.GetGeneration()
+		},
+		DeleteFunc: func(_ event.DeleteEvent) bool { return false },
+	}
+}
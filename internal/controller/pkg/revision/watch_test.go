@@ -19,10 +19,13 @@ package revision
 import (
 	"testing"
 
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -38,6 +41,7 @@ import (
 
 var (
 	_ handler.EventHandler = &EnqueueRequestForReferencingProviderRevisions{}
+	_ handler.EventHandler = &EnqueueRequestForProviderRevisionsReferencingSecret{}
 )
 
 type addFn func(item interface{})
@@ -101,3 +105,103 @@ func TestAdd(t *testing.T) {
 		e.add(tc.obj, tc.queue)
 	}
 }
+
+func TestAddReferencingSecret(t *testing.T) {
+	ns := "crossplane-system"
+	restart := true
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "creds"}}
+
+	// list returns ControllerConfigs and ProviderRevisions that reference
+	// them. Only the "restart" ControllerConfig restarts on Secret changes.
+	list := test.NewMockListFn(nil, func(obj client.ObjectList) error {
+		switch l := obj.(type) {
+		case *v1alpha1.ControllerConfigList:
+			l.Items = []v1alpha1.ControllerConfig{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "restart"},
+					Spec: v1alpha1.ControllerConfigSpec{
+						RestartOnSecretChange: &restart,
+						EnvFrom:               []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "creds"}}}},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "norestart"},
+					Spec: v1alpha1.ControllerConfigSpec{
+						EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "creds"}}}},
+					},
+				},
+			}
+		case *v1.ProviderRevisionList:
+			l.Items = []v1.ProviderRevision{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "restart"},
+					Spec:       v1.PackageRevisionSpec{ControllerConfigReference: &xpv1.Reference{Name: "restart"}},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "norestart"},
+					Spec:       v1.PackageRevisionSpec{ControllerConfigReference: &xpv1.Reference{Name: "norestart"}},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "noref"},
+				},
+			}
+		}
+		return nil
+	})
+
+	cases := map[string]struct {
+		reason string
+		obj    runtime.Object
+		want   []interface{}
+	}{
+		"ObjectIsNotASecret": {
+			reason: "We should not enqueue anything for objects that aren't Secrets.",
+			obj:    &v1alpha1.ControllerConfig{},
+		},
+		"OtherNamespace": {
+			reason: "We should not enqueue anything for Secrets in other namespaces.",
+			obj:    &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "creds"}},
+		},
+		"Unreferenced": {
+			reason: "We should not enqueue anything for Secrets no ControllerConfig references.",
+			obj:    &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "other"}},
+		},
+		"SuccessfulEnqueue": {
+			reason: "We should enqueue revisions whose ControllerConfig restarts when the Secret changes.",
+			obj:    secret,
+			want:   []interface{}{reconcile.Request{NamespacedName: types.NamespacedName{Name: "restart"}}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got []interface{}
+			e := &EnqueueRequestForProviderRevisionsReferencingSecret{client: &test.MockClient{MockList: list}, namespace: ns}
+			e.add(tc.obj, addFn(func(item interface{}) { got = append(got, item) }))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ne.add(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestStatusChanged(t *testing.T) {
+	withGeneration := func(g int64) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Generation: g}}
+	}
+
+	p := StatusChanged()
+
+	if diff := cmp.Diff(true, p.Create(event.CreateEvent{Object: withGeneration(1)})); diff != "" {
+		t.Errorf("p.Create(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(true, p.Update(event.UpdateEvent{ObjectOld: withGeneration(1), ObjectNew: withGeneration(1)})); diff != "" {
+		t.Errorf("p.Update(...): status change: -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(false, p.Update(event.UpdateEvent{ObjectOld: withGeneration(1), ObjectNew: withGeneration(2)})); diff != "" {
+		t.Errorf("p.Update(...): spec change: -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(false, p.Delete(event.DeleteEvent{Object: withGeneration(1)})); diff != "" {
+		t.Errorf("p.Delete(...): -want, +got:\n%s", diff)
+	}
+}
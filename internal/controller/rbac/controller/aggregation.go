@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Labels that cause a ClusterRole to aggregate to the core Crossplane admin,
+// edit, and view ClusterRoles.
+const (
+	LabelAggregateToAdmin = "rbac.crossplane.io/aggregate-to-admin"
+	LabelAggregateToEdit  = "rbac.crossplane.io/aggregate-to-edit"
+	LabelAggregateToView  = "rbac.crossplane.io/aggregate-to-view"
+)
+
+// AggregationLabels are labels that cause the ClusterRoles the RBAC manager
+// creates to aggregate to ClusterRoles that are not managed by Crossplane, for
+// example an organisation's existing admin, edit, and view ClusterRoles.
+type AggregationLabels struct {
+	// Admin labels are added to ClusterRoles that aggregate to the core
+	// Crossplane admin ClusterRole.
+	Admin map[string]string
+
+	// Edit labels are added to ClusterRoles that aggregate to the core
+	// Crossplane edit ClusterRole.
+	Edit map[string]string
+
+	// View labels are added to ClusterRoles that aggregate to the core
+	// Crossplane view ClusterRole.
+	View map[string]string
+}
+
+// AddTo adds any aggregation labels that apply to the supplied ClusterRole,
+// depending on which core Crossplane ClusterRoles it aggregates to. Labels the
+// ClusterRole already has are never overwritten.
+func (l AggregationLabels) AddTo(o metav1.Object) {
+	existing := o.GetLabels()
+	add := map[string]string{}
+	for key, extra := range map[string]map[string]string{
+		LabelAggregateToAdmin: l.Admin,
+		LabelAggregateToEdit:  l.Edit,
+		LabelAggregateToView:  l.View,
+	} {
+		if existing[key] != "true" {
+			continue
+		}
+		for k, v := range extra {
+			if _, ok := existing[k]; ok {
+				continue
+			}
+			add[k] = v
+		}
+	}
+	if len(add) == 0 {
+		return
+	}
+	if existing == nil {
+		existing = map[string]string{}
+	}
+	for k, v := range add {
+		existing[k] = v
+	}
+	o.SetLabels(existing)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAggregationLabelsAddTo(t *testing.T) {
+	l := AggregationLabels{
+		Admin: map[string]string{"example.org/aggregate-to-admin": "true"},
+		Edit:  map[string]string{"example.org/aggregate-to-edit": "true"},
+		View:  map[string]string{"example.org/aggregate-to-view": "true"},
+	}
+
+	cases := map[string]struct {
+		reason string
+		l      AggregationLabels
+		labels map[string]string
+		want   map[string]string
+	}{
+		"NoAggregationLabels": {
+			reason: "We should not add labels if none are configured.",
+			labels: map[string]string{LabelAggregateToEdit: "true"},
+			want:   map[string]string{LabelAggregateToEdit: "true"},
+		},
+		"DoesNotAggregate": {
+			reason: "We should not add labels to a ClusterRole that doesn't aggregate to the core Crossplane ClusterRoles.",
+			l:      l,
+			want:   nil,
+		},
+		"AggregatesToView": {
+			reason: "We should add view labels to a ClusterRole that aggregates to the core Crossplane view role.",
+			l:      l,
+			labels: map[string]string{LabelAggregateToView: "true"},
+			want: map[string]string{
+				LabelAggregateToView:            "true",
+				"example.org/aggregate-to-view": "true",
+			},
+		},
+		"AggregatesToAdminAndEdit": {
+			reason: "We should add admin and edit labels to a ClusterRole that aggregates to the core Crossplane admin and edit roles.",
+			l:      l,
+			labels: map[string]string{LabelAggregateToAdmin: "true", LabelAggregateToEdit: "true"},
+			want: map[string]string{
+				LabelAggregateToAdmin:            "true",
+				LabelAggregateToEdit:             "true",
+				"example.org/aggregate-to-admin": "true",
+				"example.org/aggregate-to-edit":  "true",
+			},
+		},
+		"DoesNotOverwrite": {
+			reason: "We should not overwrite labels the ClusterRole already has.",
+			l: AggregationLabels{
+				View: map[string]string{LabelAggregateToEdit: "true"},
+			},
+			labels: map[string]string{LabelAggregateToView: "true", LabelAggregateToEdit: "false"},
+			want:   map[string]string{LabelAggregateToView: "true", LabelAggregateToEdit: "false"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Labels: tc.labels}}
+			tc.l.AddTo(cr)
+			if diff := cmp.Diff(tc.want, cr.GetLabels()); diff != "" {
+				t.Errorf("\n%s\nAddTo(...): -want labels, +got labels:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/crossplane/internal/metrics"
+)
+
+const (
+	errGetCurrent = "cannot get current object"
+)
+
+// A DryRunApplicator logs the objects it would apply instead of applying them.
+type DryRunApplicator struct {
+	client client.Reader
+	log    logging.Logger
+	kind   string
+}
+
+// NewDryRunApplicator returns an Applicator that logs the objects of the
+// supplied kind that it would create or update, without creating or updating
+// them. The supplied client is used to determine whether an object would be
+// created or updated.
+func NewDryRunApplicator(c client.Reader, log logging.Logger, kind string) *DryRunApplicator {
+	return &DryRunApplicator{client: c, log: log, kind: kind}
+}
+
+// Apply logs that the supplied object would be created or updated. Any of the
+// supplied ApplyOptions that would prevent it from being updated, for example
+// because it has not changed, are respected.
+func (a *DryRunApplicator) Apply(ctx context.Context, o client.Object, ao ...resource.ApplyOption) error {
+	current := o.DeepCopyObject().(client.Object)
+	err := a.client.Get(ctx, types.NamespacedName{Name: o.GetName(), Namespace: o.GetNamespace()}, current)
+	if kerrors.IsNotFound(err) {
+		a.log.Info("Dry run: would create object", "kind", a.kind, "name", o.GetName(), "namespace", o.GetNamespace(), "object", o)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errGetCurrent)
+	}
+
+	for _, fn := range ao {
+		if err := fn(ctx, current, o); err != nil {
+			return err
+		}
+	}
+
+	a.log.Info("Dry run: would update object", "kind", a.kind, "name", o.GetName(), "namespace", o.GetNamespace(), "object", o)
+	return nil
+}
+
+// An InstrumentedApplicator records metrics about the objects another
+// Applicator applies.
+type InstrumentedApplicator struct {
+	wrapped resource.Applicator
+	metrics metrics.RBACRecorder
+	kind    string
+}
+
+// NewInstrumentedApplicator returns an Applicator that records metrics about
+// the objects of the supplied kind that the supplied Applicator applies.
+func NewInstrumentedApplicator(a resource.Applicator, m metrics.RBACRecorder, kind string) *InstrumentedApplicator {
+	return &InstrumentedApplicator{wrapped: a, metrics: m, kind: kind}
+}
+
+// Apply the supplied object using the wrapped Applicator. An object that was
+// not applied because an ApplyOption didn't allow it is not recorded.
+func (a *InstrumentedApplicator) Apply(ctx context.Context, o client.Object, ao ...resource.ApplyOption) error {
+	err := a.wrapped.Apply(ctx, o, ao...)
+	switch {
+	case resource.IsNotAllowed(err):
+	case err != nil:
+		a.metrics.RecordApplyError(a.kind)
+	default:
+		a.metrics.RecordApply(a.kind)
+	}
+	return err
+}
+
+// NewClientApplicator returns the ClientApplicator that an RBAC controller
+// should use to apply roles or bindings of the supplied kind.
+func NewClientApplicator(c client.Client, o Options, kind string) resource.ClientApplicator {
+	var a resource.Applicator = resource.NewAPIUpdatingApplicator(c)
+	if o.DryRun {
+		a = NewDryRunApplicator(c, o.Logger, kind)
+	}
+	return resource.ClientApplicator{
+		Client:     c,
+		Applicator: NewInstrumentedApplicator(a, metrics.NewPrometheusRBACRecorder(), kind),
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestDryRunApplicatorApply(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type args struct {
+		c  client.Reader
+		ao []resource.ApplyOption
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"GetError": {
+			reason: "We should return any error encountered getting the current object.",
+			args: args{
+				c: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			},
+			want: errors.Wrap(errBoom, errGetCurrent),
+		},
+		"WouldCreate": {
+			reason: "We should not return an error if the object would be created.",
+			args: args{
+				c: &test.MockClient{MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, ""))},
+				ao: []resource.ApplyOption{func(_ context.Context, _, _ runtime.Object) error {
+					return errBoom
+				}},
+			},
+			want: nil,
+		},
+		"WouldNotUpdate": {
+			reason: "We should return any error returned by an ApplyOption if the object exists.",
+			args: args{
+				c: &test.MockClient{MockGet: test.NewMockGetFn(nil)},
+				ao: []resource.ApplyOption{func(_ context.Context, _, _ runtime.Object) error {
+					return errBoom
+				}},
+			},
+			want: errBoom,
+		},
+		"WouldUpdate": {
+			reason: "We should not return an error if the object would be updated.",
+			args: args{
+				c: &test.MockClient{MockGet: test.NewMockGetFn(nil)},
+			},
+			want: nil,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			a := NewDryRunApplicator(tc.args.c, logging.NewNopLogger(), "ClusterRole")
+			err := a.Apply(context.Background(), &rbacv1.ClusterRole{}, tc.args.ao...)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+type recorder struct {
+	applies int
+	errors  int
+}
+
+func (r *recorder) RecordApply(_ string)      { r.applies++ }
+func (r *recorder) RecordApplyError(_ string) { r.errors++ }
+
+func TestInstrumentedApplicatorApply(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err     error
+		applies int
+		errors  int
+	}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   want
+	}{
+		"NotAllowed": {
+			reason: "We should not record an object that an ApplyOption did not allow us to apply.",
+			err:    resource.NewNotAllowed("no-op"),
+			want: want{
+				err: resource.NewNotAllowed("no-op"),
+			},
+		},
+		"Error": {
+			reason: "We should record an object that we could not apply.",
+			err:    errBoom,
+			want: want{
+				err:    errBoom,
+				errors: 1,
+			},
+		},
+		"Success": {
+			reason: "We should record an object that we applied.",
+			want: want{
+				applies: 1,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &recorder{}
+			wrapped := resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error { return tc.err })
+			err := NewInstrumentedApplicator(wrapped, r, "ClusterRole").Apply(context.Background(), &rbacv1.ClusterRole{})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.applies, r.applies); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want applies, +got applies:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.errors, r.errors); diff != "" {
+				t.Errorf("\n%s\nApply(...): -want errors, +got errors:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -48,4 +48,12 @@ type Options struct {
 	// permissions may be granted to Providers that request them. The
 	// provider may request any permission that appears in the named role.
 	AllowClusterRole string
+
+	// AggregationLabels cause the ClusterRoles the RBAC manager creates to
+	// aggregate to ClusterRoles that are not managed by Crossplane.
+	AggregationLabels AggregationLabels
+
+	// DryRun causes the RBAC manager to log the roles and bindings it would
+	// create or update, rather than creating or updating them.
+	DryRun bool
 }
@@ -73,7 +73,9 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 
 	r := NewReconciler(mgr,
 		WithLogger(o.Logger.WithValues("controller", name)),
-		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		WithClientApplicator(controller.NewClientApplicator(mgr.GetClient(), o, "ClusterRole")),
+		WithClusterRoleRenderer(AggregatingClusterRoleRenderer(o.AggregationLabels)))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
@@ -203,10 +205,10 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 }
 
 // ClusterRolesDiffer returns true if the supplied objects are different
-// ClusterRoles. We consider ClusterRoles to be different if their labels and
-// rules do not match.
+// ClusterRoles. We consider ClusterRoles to be different if their labels,
+// annotations, or rules do not match.
 func ClusterRolesDiffer(current, desired runtime.Object) bool {
 	c := current.(*rbacv1.ClusterRole)
 	d := desired.(*rbacv1.ClusterRole)
-	return !cmp.Equal(c.GetLabels(), d.GetLabels()) || !cmp.Equal(c.Rules, d.Rules)
+	return !cmp.Equal(c.GetLabels(), d.GetLabels()) || !cmp.Equal(c.GetAnnotations(), d.GetAnnotations()) || !cmp.Equal(c.Rules, d.Rules)
 }
@@ -227,6 +227,23 @@ func TestClusterRolesDiffer(t *testing.T) {
 			},
 			want: true,
 		},
+		"AnnotationsDiffer": {
+			current: &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"a": "a"},
+					Annotations: map[string]string{"a": "a"},
+				},
+				Rules: []rbacv1.PolicyRule{{}},
+			},
+			desired: &rbacv1.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"a": "a"},
+					Annotations: map[string]string{"b": "b"},
+				},
+				Rules: []rbacv1.PolicyRule{{}},
+			},
+			want: true,
+		},
 		"RulesDiffer": {
 			current: &rbacv1.ClusterRole{
 				ObjectMeta: metav1.ObjectMeta{
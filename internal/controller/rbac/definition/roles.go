@@ -22,6 +22,8 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/crossplane/crossplane/internal/controller/rbac/controller"
 )
 
 const (
@@ -46,8 +48,14 @@ const (
 
 	keyXRD = "rbac.crossplane.io/xrd"
 
+	keyClaimNamespaceSelector = "rbac.crossplane.io/claim-namespace-selector"
+
 	valTrue = "true"
 
+	// valInvalidSelector is not a valid label selector, and thus selects no
+	// namespaces when parsed.
+	valInvalidSelector = "<invalid>"
+
 	suffixStatus = "/status"
 )
 
@@ -164,9 +172,38 @@ func RenderClusterRoles(d *v1.CompositeResourceDefinition) []rbacv1.ClusterRole
 		// The browse role only includes composite resources; not claims.
 	}
 
+	// Namespaced roles only aggregate the rules of the edit and view roles if
+	// the namespace may contain claims.
+	if d.Spec.ClaimNames != nil && d.Spec.ClaimNamespaceSelector != nil {
+		for _, o := range []metav1.Object{edit, view} {
+			meta.AddAnnotations(o, map[string]string{keyClaimNamespaceSelector: claimNamespaceSelector(d)})
+		}
+	}
+
 	for _, o := range []metav1.Object{system, edit, view, browse} {
 		meta.AddOwnerReference(o, meta.AsController(meta.TypedReferenceTo(d, v1.CompositeResourceDefinitionGroupVersionKind)))
 	}
 
 	return []rbacv1.ClusterRole{*system, *edit, *view, *browse}
 }
+
+// AggregatingClusterRoleRenderer returns a ClusterRoleRenderFn that renders
+// ClusterRoles using RenderClusterRoles, then adds the supplied aggregation
+// labels to them.
+func AggregatingClusterRoleRenderer(l controller.AggregationLabels) ClusterRoleRenderFn {
+	return func(d *v1.CompositeResourceDefinition) []rbacv1.ClusterRole {
+		roles := RenderClusterRoles(d)
+		for i := range roles {
+			l.AddTo(&roles[i])
+		}
+		return roles
+	}
+}
+
+func claimNamespaceSelector(d *v1.CompositeResourceDefinition) string {
+	s, err := metav1.LabelSelectorAsSelector(d.Spec.ClaimNamespaceSelector)
+	if err != nil {
+		return valInvalidSelector
+	}
+	return s.String()
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package definition
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -26,6 +27,8 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+
+	"github.com/crossplane/crossplane/internal/controller/rbac/controller"
 )
 
 func TestRenderClusterRoles(t *testing.T) {
@@ -232,6 +235,115 @@ func TestRenderClusterRoles(t *testing.T) {
 				},
 			},
 		},
+		"OffersClaimToSelectedNamespaces": {
+			reason: "An XRD that offers a claim to selected namespaces should annotate the ClusterRoles that aggregate to namespaced roles with its selector",
+			d: &v1.CompositeResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: name, UID: uid},
+				Spec: v1.CompositeResourceDefinitionSpec{
+					Group:      group,
+					Names:      extv1.CustomResourceDefinitionNames{Plural: pluralXR},
+					ClaimNames: &extv1.CustomResourceDefinitionNames{Plural: pluralXRC},
+					ClaimNamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"tenant": "a"},
+					},
+				},
+			},
+			want: []rbacv1.ClusterRole{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            namePrefix + name + nameSuffixSystem,
+						OwnerReferences: []metav1.OwnerReference{owner},
+						Labels: map[string]string{
+							keyAggregateToSystem: valTrue,
+						},
+					},
+					Rules: []rbacv1.PolicyRule{
+						{
+							APIGroups: []string{group},
+							Resources: []string{pluralXR, pluralXR + suffixStatus},
+							Verbs:     verbsEdit,
+						},
+						{
+							APIGroups: []string{group},
+							Resources: []string{pluralXRC, pluralXRC + suffixStatus},
+							Verbs:     verbsEdit,
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            namePrefix + name + nameSuffixEdit,
+						OwnerReferences: []metav1.OwnerReference{owner},
+						Labels: map[string]string{
+							keyAggregateToAdmin:   valTrue,
+							keyAggregateToNSAdmin: valTrue,
+							keyAggregateToEdit:    valTrue,
+							keyAggregateToNSEdit:  valTrue,
+							keyXRD:                name,
+						},
+						Annotations: map[string]string{
+							keyClaimNamespaceSelector: "tenant=a",
+						},
+					},
+					Rules: []rbacv1.PolicyRule{
+						{
+							APIGroups: []string{group},
+							Resources: []string{pluralXR},
+							Verbs:     verbsEdit,
+						},
+						{
+							APIGroups: []string{group},
+							Resources: []string{pluralXRC},
+							Verbs:     verbsEdit,
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            namePrefix + name + nameSuffixView,
+						OwnerReferences: []metav1.OwnerReference{owner},
+						Labels: map[string]string{
+							keyAggregateToView:   valTrue,
+							keyAggregateToNSView: valTrue,
+							keyXRD:               name,
+						},
+						Annotations: map[string]string{
+							keyClaimNamespaceSelector: "tenant=a",
+						},
+					},
+					Rules: []rbacv1.PolicyRule{
+						{
+							APIGroups: []string{group},
+							Resources: []string{pluralXR},
+							Verbs:     verbsView,
+						},
+						{
+							APIGroups: []string{group},
+							Resources: []string{pluralXRC},
+							Verbs:     verbsView,
+						},
+					},
+				},
+				{
+					// The browse role never includes claims.
+					ObjectMeta: metav1.ObjectMeta{
+						Name:            namePrefix + name + nameSuffixBrowse,
+						OwnerReferences: []metav1.OwnerReference{owner},
+						Labels: map[string]string{
+							keyAggregateToBrowse: valTrue,
+							keyXRD:               name,
+						},
+					},
+					Rules: []rbacv1.PolicyRule{
+						{
+							APIGroups: []string{group},
+							Resources: []string{pluralXR},
+							Verbs:     verbsBrowse,
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -243,3 +355,109 @@ func TestRenderClusterRoles(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregatingClusterRoleRenderer(t *testing.T) {
+	d := &v1.CompositeResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "coolcomposites.example.org"},
+		Spec: v1.CompositeResourceDefinitionSpec{
+			Group: "example.org",
+			Names: extv1.CustomResourceDefinitionNames{Plural: "coolcomposites"},
+		},
+	}
+
+	orgA := controller.AggregationLabels{
+		Admin: map[string]string{"a.example.org/admin": valTrue},
+		Edit:  map[string]string{"a.example.org/edit": valTrue},
+		View:  map[string]string{"a.example.org/view": valTrue},
+	}
+	orgB := controller.AggregationLabels{
+		Edit: map[string]string{"b.example.org/edit": valTrue},
+	}
+
+	type want struct {
+		labels map[string]map[string]string
+		differ map[string]bool
+	}
+
+	cases := map[string]struct {
+		reason  string
+		current controller.AggregationLabels
+		desired controller.AggregationLabels
+		want    want
+	}{
+		"Unchanged": {
+			reason:  "ClusterRoles should not differ if the aggregation labels are unchanged.",
+			current: orgA,
+			desired: orgA,
+			want: want{
+				labels: map[string]map[string]string{
+					nameSuffixEdit: {"a.example.org/admin": valTrue, "a.example.org/edit": valTrue},
+					nameSuffixView: {"a.example.org/view": valTrue},
+				},
+				differ: map[string]bool{},
+			},
+		},
+		"AddLabels": {
+			reason:  "Only the edit and view ClusterRoles should differ when aggregation labels are added.",
+			desired: orgA,
+			want: want{
+				labels: map[string]map[string]string{
+					nameSuffixEdit: {"a.example.org/admin": valTrue, "a.example.org/edit": valTrue},
+					nameSuffixView: {"a.example.org/view": valTrue},
+				},
+				differ: map[string]bool{nameSuffixEdit: true, nameSuffixView: true},
+			},
+		},
+		"ChangeLabels": {
+			reason:  "Aggregation labels that are no longer configured should be removed.",
+			current: orgA,
+			desired: orgB,
+			want: want{
+				labels: map[string]map[string]string{
+					nameSuffixEdit: {"b.example.org/edit": valTrue},
+				},
+				differ: map[string]bool{nameSuffixEdit: true, nameSuffixView: true},
+			},
+		},
+		"RemoveLabels": {
+			reason:  "Removing all aggregation labels should produce the same ClusterRoles as RenderClusterRoles.",
+			current: orgA,
+			want: want{
+				labels: map[string]map[string]string{},
+				differ: map[string]bool{nameSuffixEdit: true, nameSuffixView: true},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			current := AggregatingClusterRoleRenderer(tc.current)(d)
+			desired := AggregatingClusterRoleRenderer(tc.desired)(d)
+			plain := RenderClusterRoles(d)
+
+			for i := range desired {
+				suffix := strings.TrimPrefix(desired[i].GetName(), namePrefix+d.GetName())
+
+				// Aggregation labels are added to, but never replace, the
+				// labels RenderClusterRoles produces.
+				got := map[string]string{}
+				for k, v := range desired[i].GetLabels() {
+					if _, ok := plain[i].GetLabels()[k]; !ok {
+						got[k] = v
+					}
+				}
+				wl := tc.want.labels[suffix]
+				if wl == nil {
+					wl = map[string]string{}
+				}
+				if diff := cmp.Diff(wl, got); diff != "" {
+					t.Errorf("\n%s\n%s: -want aggregation labels, +got aggregation labels:\n%s\n", tc.reason, suffix, diff)
+				}
+
+				if diff := cmp.Diff(tc.want.differ[suffix], ClusterRolesDiffer(&current[i], &desired[i])); diff != "" {
+					t.Errorf("\n%s\n%s: ClusterRolesDiffer(...): -want, +got:\n%s\n", tc.reason, suffix, diff)
+				}
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
@@ -45,6 +46,8 @@ const (
 
 	keyXRD = keyPrefix + "xrd"
 
+	keyClaimNamespaceSelector = keyPrefix + "claim-namespace-selector"
+
 	keyAggregated = "aggregated-by-crossplane"
 
 	valTrue   = "true"
@@ -100,6 +103,10 @@ func RenderRoles(ns *corev1.Namespace, crs []rbacv1.ClusterRole) []rbacv1.Role {
 	// are aggregating rules from? This aggregation is likely to be surprising
 	// to the uninitiated.
 	for _, cr := range crs {
+		if !selectsNamespace(cr, ns) {
+			continue
+		}
+
 		if acrs.Select(cr) {
 			admin.Rules = append(admin.Rules, cr.Rules...)
 		}
@@ -134,3 +141,17 @@ func (s crSelector) Select(cr rbacv1.ClusterRole) bool {
 	// that this namespace accepts a claim from.
 	return l[s.keyBase] == valTrue || s.accepts[l[keyXRD]]
 }
+
+// selectsNamespace returns true unless the supplied ClusterRole pertains to an
+// XRD that only offers claims in namespaces other than the supplied one.
+func selectsNamespace(cr rbacv1.ClusterRole, ns *corev1.Namespace) bool {
+	v, ok := cr.GetAnnotations()[keyClaimNamespaceSelector]
+	if !ok {
+		return true
+	}
+	s, err := labels.Parse(v)
+	if err != nil {
+		return false
+	}
+	return s.Matches(labels.Set(ns.GetLabels()))
+}
@@ -295,6 +295,88 @@ func TestRenderClusterRoles(t *testing.T) {
 				},
 			},
 		},
+		"ANamespaceNotSelectedByAnXRD": {
+			reason: "A namespace that accepts claims should not get XRD rules if the XRD only offers its claim to namespaces with other labels.",
+			args: args{
+				ns: &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        name,
+						UID:         uid,
+						Labels:      map[string]string{"tenant": "b"},
+						Annotations: map[string]string{keyPrefix + xrdName: valAccept},
+					},
+				},
+				crs: []rbacv1.ClusterRole{
+					{
+						// This role's rules should be aggregated to the admin and edit roles.
+						ObjectMeta: metav1.ObjectMeta{
+							Name: crNameA,
+							Labels: map[string]string{
+								keyAggToAdmin:  valTrue,
+								keyBaseOfAdmin: valTrue,
+								keyAggToEdit:   valTrue,
+								keyBaseOfEdit:  valTrue,
+							},
+						},
+						Rules: []rbacv1.PolicyRule{ruleA},
+					},
+					{
+						// This role's XRD only offers its claim to namespaces labelled tenant=a.
+						ObjectMeta: metav1.ObjectMeta{
+							Name: crNameB,
+							Labels: map[string]string{
+								keyAggToAdmin: valTrue,
+								keyAggToEdit:  valTrue,
+								keyXRD:        xrdName,
+							},
+							Annotations: map[string]string{keyClaimNamespaceSelector: "tenant=a"},
+						},
+						Rules: []rbacv1.PolicyRule{ruleB},
+					},
+					{
+						// This role's XRD offers its claim to namespaces labelled tenant=b.
+						ObjectMeta: metav1.ObjectMeta{
+							Name: crNameC,
+							Labels: map[string]string{
+								keyAggToView: valTrue,
+								keyXRD:       xrdName,
+							},
+							Annotations: map[string]string{keyClaimNamespaceSelector: "tenant in (b,c)"},
+						},
+						Rules: []rbacv1.PolicyRule{ruleC},
+					},
+				},
+			},
+			want: []rbacv1.Role{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:       name,
+						Name:            nameAdmin,
+						OwnerReferences: []metav1.OwnerReference{owner},
+						Annotations:     map[string]string{keyPrefix + keyAggregated: valTrue},
+					},
+					Rules: []rbacv1.PolicyRule{ruleA},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:       name,
+						Name:            nameEdit,
+						OwnerReferences: []metav1.OwnerReference{owner},
+						Annotations:     map[string]string{keyPrefix + keyAggregated: valTrue},
+					},
+					Rules: []rbacv1.PolicyRule{ruleA},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace:       name,
+						Name:            nameView,
+						OwnerReferences: []metav1.OwnerReference{owner},
+						Annotations:     map[string]string{keyPrefix + keyAggregated: valTrue},
+					},
+					Rules: []rbacv1.PolicyRule{ruleC},
+				},
+			},
+		},
 	}
 
 	for name, tc := range cases {
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package binding
+
+import (
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/internal/controller/rbac/provider/roles"
+)
+
+const (
+	kindClusterRole = "ClusterRole"
+	kindRole        = "Role"
+)
+
+// RenderSystemClusterRoleBinding returns a ClusterRoleBinding that binds the
+// supplied subjects to the system ClusterRole of the supplied
+// ProviderRevision.
+func RenderSystemClusterRoleBinding(pr *v1.ProviderRevision, subjects []rbacv1.Subject) *rbacv1.ClusterRoleBinding {
+	n := roles.SystemClusterRoleName(pr.GetName())
+	ref := meta.AsController(meta.TypedReferenceTo(pr, v1.ProviderRevisionGroupVersionKind))
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            n,
+			OwnerReferences: []metav1.OwnerReference{ref},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     kindClusterRole,
+			Name:     n,
+		},
+		Subjects: subjects,
+	}
+}
+
+// RenderSystemRoleBinding returns a RoleBinding that binds the supplied
+// subjects to the system Role of the supplied ProviderRevision, for use when
+// the provider's runtime is scoped to the supplied namespace. Subjects in other
+// namespaces are omitted; a provider scoped to a namespace must run there.
+func RenderSystemRoleBinding(pr *v1.ProviderRevision, namespace string, subjects []rbacv1.Subject) *rbacv1.RoleBinding {
+	inNamespace := make([]rbacv1.Subject, 0, len(subjects))
+	for _, s := range subjects {
+		if s.Namespace == namespace {
+			inNamespace = append(inNamespace, s)
+		}
+	}
+
+	n := roles.SystemClusterRoleName(pr.GetName())
+	ref := meta.AsController(meta.TypedReferenceTo(pr, v1.ProviderRevisionGroupVersionKind))
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       namespace,
+			Name:            n,
+			OwnerReferences: []metav1.OwnerReference{ref},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     kindRole,
+			Name:     n,
+		},
+		Subjects: inNamespace,
+	}
+}
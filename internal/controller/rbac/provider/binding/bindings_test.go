@@ -0,0 +1,71 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package binding
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/internal/controller/rbac/provider/roles"
+)
+
+func TestRenderSystemRoleBinding(t *testing.T) {
+	prName := "revised"
+	prUID := types.UID("no-you-id")
+	ns := "tenant"
+
+	ctrl := true
+	owner := metav1.OwnerReference{
+		APIVersion: v1.ProviderRevisionGroupVersionKind.GroupVersion().String(),
+		Kind:       v1.ProviderRevisionKind,
+		Name:       prName,
+		UID:        prUID,
+		Controller: &ctrl,
+	}
+
+	pr := &v1.ProviderRevision{ObjectMeta: metav1.ObjectMeta{Name: prName, UID: prUID}}
+	subjects := []rbacv1.Subject{
+		{Kind: rbacv1.ServiceAccountKind, Namespace: ns, Name: "provider"},
+		{Kind: rbacv1.ServiceAccountKind, Namespace: "crossplane-system", Name: "provider"},
+	}
+
+	want := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       ns,
+			Name:            roles.SystemClusterRoleName(prName),
+			OwnerReferences: []metav1.OwnerReference{owner},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     kindRole,
+			Name:     roles.SystemClusterRoleName(prName),
+		},
+		Subjects: []rbacv1.Subject{
+			{Kind: rbacv1.ServiceAccountKind, Namespace: ns, Name: "provider"},
+		},
+	}
+
+	got := RenderSystemRoleBinding(pr, ns, subjects)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("RenderSystemRoleBinding(...): Only ServiceAccounts in the Role's namespace should be bound: -want, +got:\n%s\n", diff)
+	}
+}
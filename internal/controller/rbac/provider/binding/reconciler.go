@@ -23,7 +23,6 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -39,7 +38,6 @@ import (
 
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
 	"github.com/crossplane/crossplane/internal/controller/rbac/controller"
-	"github.com/crossplane/crossplane/internal/controller/rbac/provider/roles"
 )
 
 const (
@@ -48,8 +46,6 @@ const (
 	errGetPR        = "cannot get ProviderRevision"
 	errListSAs      = "cannot list ServiceAccounts"
 	errApplyBinding = "cannot apply ClusterRoleBinding"
-
-	kindClusterRole = "ClusterRole"
 )
 
 // Event reasons.
@@ -65,7 +61,8 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 
 	r := NewReconciler(mgr,
 		WithLogger(o.Logger.WithValues("controller", name)),
-		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		WithClientApplicator(controller.NewClientApplicator(mgr.GetClient(), o, "ClusterRoleBinding")))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
@@ -183,24 +180,11 @@ func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reco
 		}
 	}
 
-	n := roles.SystemClusterRoleName(pr.GetName())
-	ref := meta.AsController(meta.TypedReferenceTo(pr, v1.ProviderRevisionGroupVersionKind))
-	rb := &rbacv1.ClusterRoleBinding{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:            n,
-			OwnerReferences: []metav1.OwnerReference{ref},
-		},
-		RoleRef: rbacv1.RoleRef{
-			APIGroup: rbacv1.GroupName,
-			Kind:     kindClusterRole,
-			Name:     n,
-		},
-		Subjects: subjects,
-	}
+	rb := RenderSystemClusterRoleBinding(pr, subjects)
 
 	log = log.WithValues(
-		"binding-name", n,
-		"role-name", n,
+		"binding-name", rb.GetName(),
+		"role-name", rb.RoleRef.Name,
 		"subjects", subjects,
 	)
 
@@ -97,7 +97,9 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 	if o.AllowClusterRole == "" {
 		r := NewReconciler(mgr,
 			WithLogger(o.Logger.WithValues("controller", name)),
-			WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+			WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+			WithClientApplicator(controller.NewClientApplicator(mgr.GetClient(), o, "ClusterRole")),
+			WithClusterRoleRenderer(AggregatingClusterRoleRenderer(o.AggregationLabels)))
 
 		return ctrl.NewControllerManagedBy(mgr).
 			Named(name).
@@ -114,6 +116,8 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 	r := NewReconciler(mgr,
 		WithLogger(o.Logger.WithValues("controller", name)),
 		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		WithClientApplicator(controller.NewClientApplicator(mgr.GetClient(), o, "ClusterRole")),
+		WithClusterRoleRenderer(AggregatingClusterRoleRenderer(o.AggregationLabels)),
 		WithPermissionRequestsValidator(NewClusterRoleBackedValidator(mgr.GetClient(), o.AllowClusterRole)))
 
 	return ctrl.NewControllerManagedBy(mgr).
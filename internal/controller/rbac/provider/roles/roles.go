@@ -26,6 +26,8 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+
+	"github.com/crossplane/crossplane/internal/controller/rbac/controller"
 )
 
 const (
@@ -79,30 +81,7 @@ func SystemClusterRoleName(revisionName string) string {
 
 // RenderClusterRoles returns ClusterRoles for the supplied ProviderRevision.
 func RenderClusterRoles(pr *v1.ProviderRevision, crds []extv1.CustomResourceDefinition) []rbacv1.ClusterRole {
-	// Our list of CRDs has no guaranteed order, so we sort them in order to
-	// ensure we don't reorder our RBAC rules on each update.
-	sort.Slice(crds, func(i, j int) bool { return crds[i].GetName() < crds[j].GetName() })
-
-	groups := make([]string, 0)            // Allows deterministic iteration over groups.
-	resources := make(map[string][]string) // Resources by group.
-	for _, crd := range crds {
-		if _, ok := resources[crd.Spec.Group]; !ok {
-			resources[crd.Spec.Group] = make([]string, 0)
-			groups = append(groups, crd.Spec.Group)
-		}
-		resources[crd.Spec.Group] = append(resources[crd.Spec.Group],
-			crd.Spec.Names.Plural,
-			crd.Spec.Names.Plural+suffixStatus,
-		)
-	}
-
-	rules := []rbacv1.PolicyRule{}
-	for _, g := range groups {
-		rules = append(rules, rbacv1.PolicyRule{
-			APIGroups: []string{g},
-			Resources: resources[g],
-		})
-	}
+	rules := rulesFor(crds)
 
 	edit := &rbacv1.ClusterRole{
 		ObjectMeta: metav1.ObjectMeta{
@@ -148,6 +127,72 @@ func RenderClusterRoles(pr *v1.ProviderRevision, crds []extv1.CustomResourceDefi
 	return roles
 }
 
+// AggregatingClusterRoleRenderer returns a ClusterRoleRenderFn that renders
+// ClusterRoles using RenderClusterRoles, then adds the supplied aggregation
+// labels to them.
+func AggregatingClusterRoleRenderer(l controller.AggregationLabels) ClusterRoleRenderFn {
+	return func(pr *v1.ProviderRevision, crds []extv1.CustomResourceDefinition) []rbacv1.ClusterRole {
+		roles := RenderClusterRoles(pr, crds)
+		for i := range roles {
+			l.AddTo(&roles[i])
+		}
+		return roles
+	}
+}
+
+// RenderSystemRole returns the 'system' Role for the supplied ProviderRevision,
+// for use when the provider's runtime is scoped to the supplied namespace. It
+// is bound in place of the system ClusterRole, and grants access only to the
+// namespaced CRDs of the provider, and only within that namespace.
+func RenderSystemRole(pr *v1.ProviderRevision, crds []extv1.CustomResourceDefinition, namespace string) *rbacv1.Role {
+	namespaced := make([]extv1.CustomResourceDefinition, 0, len(crds))
+	for _, crd := range crds {
+		if crd.Spec.Scope == extv1.NamespaceScoped {
+			namespaced = append(namespaced, crd)
+		}
+	}
+
+	r := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      SystemClusterRoleName(pr.GetName()),
+		},
+		Rules: append(append(withVerbs(rulesFor(namespaced), verbsSystem), rulesSystemExtra...), pr.Status.PermissionRequests...),
+	}
+	meta.AddOwnerReference(r, meta.AsController(meta.TypedReferenceTo(pr, v1.ProviderRevisionGroupVersionKind)))
+	return r
+}
+
+// rulesFor returns rules, without verbs, that grant access to the supplied
+// CRDs and their status subresources.
+func rulesFor(crds []extv1.CustomResourceDefinition) []rbacv1.PolicyRule {
+	// Our list of CRDs has no guaranteed order, so we sort them in order to
+	// ensure we don't reorder our RBAC rules on each update.
+	sort.Slice(crds, func(i, j int) bool { return crds[i].GetName() < crds[j].GetName() })
+
+	groups := make([]string, 0)            // Allows deterministic iteration over groups.
+	resources := make(map[string][]string) // Resources by group.
+	for _, crd := range crds {
+		if _, ok := resources[crd.Spec.Group]; !ok {
+			resources[crd.Spec.Group] = make([]string, 0)
+			groups = append(groups, crd.Spec.Group)
+		}
+		resources[crd.Spec.Group] = append(resources[crd.Spec.Group],
+			crd.Spec.Names.Plural,
+			crd.Spec.Names.Plural+suffixStatus,
+		)
+	}
+
+	rules := []rbacv1.PolicyRule{}
+	for _, g := range groups {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{g},
+			Resources: resources[g],
+		})
+	}
+	return rules
+}
+
 func withVerbs(r []rbacv1.PolicyRule, verbs []string) []rbacv1.PolicyRule {
 	verbal := make([]rbacv1.PolicyRule, len(r))
 	for i := range r {
@@ -26,6 +26,8 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 
 	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+
+	"github.com/crossplane/crossplane/internal/controller/rbac/controller"
 )
 
 func TestRenderClusterRoles(t *testing.T) {
@@ -164,3 +166,135 @@ func TestRenderClusterRoles(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregatingClusterRoleRenderer(t *testing.T) {
+	pr := &v1.ProviderRevision{ObjectMeta: metav1.ObjectMeta{Name: "revised"}}
+	l := controller.AggregationLabels{
+		Admin: map[string]string{"example.org/admin": valTrue},
+		Edit:  map[string]string{"example.org/edit": valTrue},
+		View:  map[string]string{"example.org/view": valTrue},
+	}
+
+	want := map[string]map[string]string{
+		namePrefix + pr.GetName() + nameSuffixEdit: {
+			keyAggregateToCrossplane: valTrue,
+			keyAggregateToAdmin:      valTrue,
+			keyAggregateToEdit:       valTrue,
+			"example.org/admin":      valTrue,
+			"example.org/edit":       valTrue,
+		},
+		namePrefix + pr.GetName() + nameSuffixView: {
+			keyAggregateToView: valTrue,
+			"example.org/view": valTrue,
+		},
+		// The system role doesn't aggregate to anything.
+		SystemClusterRoleName(pr.GetName()): nil,
+	}
+
+	got := map[string]map[string]string{}
+	for _, cr := range AggregatingClusterRoleRenderer(l)(pr, nil) {
+		got[cr.GetName()] = cr.GetLabels()
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("AggregatingClusterRoleRenderer(...): -want labels, +got labels:\n%s\n", diff)
+	}
+}
+
+func TestRenderSystemRole(t *testing.T) {
+	prName := "revised"
+	prUID := types.UID("no-you-id")
+	ns := "tenant"
+
+	ctrl := true
+	owner := metav1.OwnerReference{
+		APIVersion: v1.ProviderRevisionGroupVersionKind.GroupVersion().String(),
+		Kind:       v1.ProviderRevisionKind,
+		Name:       prName,
+		UID:        prUID,
+		Controller: &ctrl,
+	}
+
+	type args struct {
+		pr   *v1.ProviderRevision
+		crds []extv1.CustomResourceDefinition
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   *rbacv1.Role
+	}{
+		"NamespacedCRDsOnly": {
+			reason: "A system Role should grant access only to the namespaced CRDs of a ProviderRevision.",
+			args: args{
+				pr: &v1.ProviderRevision{ObjectMeta: metav1.ObjectMeta{Name: prName, UID: prUID}},
+				crds: []extv1.CustomResourceDefinition{
+					{
+						Spec: extv1.CustomResourceDefinitionSpec{
+							Group: "example.org",
+							Names: extv1.CustomResourceDefinitionNames{Plural: "clusterexamples"},
+							Scope: extv1.ClusterScoped,
+						},
+					},
+					{
+						Spec: extv1.CustomResourceDefinitionSpec{
+							Group: "example.org",
+							Names: extv1.CustomResourceDefinitionNames{Plural: "examples"},
+							Scope: extv1.NamespaceScoped,
+						},
+					},
+				},
+			},
+			want: &rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:       ns,
+					Name:            SystemClusterRoleName(prName),
+					OwnerReferences: []metav1.OwnerReference{owner},
+				},
+				Rules: append([]rbacv1.PolicyRule{
+					{
+						APIGroups: []string{"example.org"},
+						Resources: []string{"examples", "examples" + suffixStatus},
+						Verbs:     verbsSystem,
+					},
+				}, rulesSystemExtra...),
+			},
+		},
+		"PermissionRequests": {
+			reason: "A system Role should include any permissions requested by a ProviderRevision.",
+			args: args{
+				pr: &v1.ProviderRevision{
+					ObjectMeta: metav1.ObjectMeta{Name: prName, UID: prUID},
+					Status: v1.PackageRevisionStatus{
+						PermissionRequests: []rbacv1.PolicyRule{{
+							APIGroups: []string{""},
+							Resources: []string{"pods"},
+							Verbs:     []string{"get"},
+						}},
+					},
+				},
+			},
+			want: &rbacv1.Role{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace:       ns,
+					Name:            SystemClusterRoleName(prName),
+					OwnerReferences: []metav1.OwnerReference{owner},
+				},
+				Rules: append(append([]rbacv1.PolicyRule{}, rulesSystemExtra...), rbacv1.PolicyRule{
+					APIGroups: []string{""},
+					Resources: []string{"pods"},
+					Verbs:     []string{"get"},
+				}),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := RenderSystemRole(tc.args.pr, tc.args.crds, ns)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nRenderSystemRole(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package controlplane exports the configuration of a Crossplane control
+// plane, and restores it to another control plane.
+package controlplane
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	extv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+)
+
+// KeyExport is the key of the exported control plane in the data of the
+// ConfigMap a ControlPlaneExport exports to.
+const KeyExport = "controlplane.yaml"
+
+// MaxExportSize is the largest an exported control plane may be. Kubernetes
+// refuses to store a ConfigMap whose data exceeds 1MiB, and the ConfigMap's
+// metadata needs some of that space.
+const MaxExportSize = 1<<20 - 16<<10
+
+const (
+	errFmtList      = "cannot list %s"
+	errFmtConvert   = "cannot convert %s %q"
+	errFmtMarshal   = "cannot marshal %s %q"
+	errDecode       = "cannot decode exported control plane"
+	errFmtNoKind    = "exported object %d has no kind"
+	errFmtUnknownGK = "cannot restore unknown kind of object %s"
+)
+
+// A Phase of restoring a control plane. Objects are restored one phase at a
+// time, in order, and the objects of each phase must be ready before the next
+// phase is restored.
+type Phase int
+
+// Restore phases.
+const (
+	// PhaseRuntimeConfig restores the ControllerConfigs that packages
+	// reference.
+	PhaseRuntimeConfig Phase = iota
+
+	// PhasePackages restores Providers and Configurations. They're ready
+	// when they're healthy, so the CRDs their Compositions compose exist.
+	PhasePackages
+
+	// PhaseDefinitions restores CompositeResourceDefinitions. They're ready
+	// when they're established and Compositions of their composite resources
+	// can be validated.
+	PhaseDefinitions
+
+	// PhaseCompositions restores Compositions.
+	PhaseCompositions
+
+	// phaseNotRestored objects are exported, but not restored.
+	phaseNotRestored Phase = -1
+)
+
+// kinds of objects that are exported, in the order they're restored. The Lock
+// is exported so that the versions of the dependencies that were resolved are
+// recorded, but it's not restored. The package manager rebuilds it as the
+// packages are restored.
+var kinds = []struct {
+	gvk   schema.GroupVersionKind
+	phase Phase
+	list  func() client.ObjectList
+}{
+	{v1alpha1.ControllerConfigGroupVersionKind, PhaseRuntimeConfig, func() client.ObjectList { return &v1alpha1.ControllerConfigList{} }},
+	{v1.ProviderGroupVersionKind, PhasePackages, func() client.ObjectList { return &v1.ProviderList{} }},
+	{v1.ConfigurationGroupVersionKind, PhasePackages, func() client.ObjectList { return &v1.ConfigurationList{} }},
+	{extv1.CompositeResourceDefinitionGroupVersionKind, PhaseDefinitions, func() client.ObjectList { return &extv1.CompositeResourceDefinitionList{} }},
+	{extv1.CompositionGroupVersionKind, PhaseCompositions, func() client.ObjectList { return &extv1.CompositionList{} }},
+	{v1beta1.LockGroupVersionKind, phaseNotRestored, func() client.ObjectList { return &v1beta1.LockList{} }},
+}
+
+// Collect the configuration of the control plane the supplied client reads
+// from. Objects that were installed by a package are not collected; they're
+// restored when the package is. The status and server-populated metadata of
+// each object are removed.
+func Collect(ctx context.Context, c client.Reader) ([]*kunstructured.Unstructured, error) {
+	objs := make([]*kunstructured.Unstructured, 0)
+	for _, k := range kinds {
+		l := k.list()
+		if err := c.List(ctx, l); err != nil {
+			return nil, errors.Wrapf(err, errFmtList, k.gvk.Kind)
+		}
+		items, err := kmeta.ExtractList(l)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtList, k.gvk.Kind)
+		}
+		for _, item := range items {
+			o, err := kmeta.Accessor(item)
+			if err != nil {
+				return nil, errors.Wrapf(err, errFmtList, k.gvk.Kind)
+			}
+			if installedByPackage(o) {
+				continue
+			}
+			u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(item)
+			if err != nil {
+				return nil, errors.Wrapf(err, errFmtConvert, k.gvk.Kind, o.GetName())
+			}
+			e := &kunstructured.Unstructured{Object: u}
+			e.SetGroupVersionKind(k.gvk)
+			sanitize(e)
+			objs = append(objs, e)
+		}
+	}
+	return objs, nil
+}
+
+// Marshal the supplied objects as a stream of YAML documents.
+func Marshal(objs []*kunstructured.Unstructured) ([]byte, error) {
+	b := &bytes.Buffer{}
+	for i, o := range objs {
+		y, err := yaml.Marshal(o.Object)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtMarshal, o.GetKind(), o.GetName())
+		}
+		if i > 0 {
+			b.WriteString("---\n")
+		}
+		b.Write(y)
+	}
+	return b.Bytes(), nil
+}
+
+// Unmarshal a stream of YAML documents into objects, sorted in the order they
+// should be restored.
+func Unmarshal(data []byte) ([]*kunstructured.Unstructured, error) {
+	objs := make([]*kunstructured.Unstructured, 0)
+	d := kyaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+	for {
+		u := &kunstructured.Unstructured{}
+		err := d.Decode(&u.Object)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, errDecode)
+		}
+		if len(u.Object) == 0 {
+			continue
+		}
+		if u.GetKind() == "" {
+			return nil, errors.Errorf(errFmtNoKind, len(objs))
+		}
+		objs = append(objs, u)
+	}
+	for _, o := range objs {
+		if _, ok := phaseOf(o); !ok {
+			return nil, errors.Errorf(errFmtUnknownGK, o.GroupVersionKind().GroupKind())
+		}
+	}
+	sort.SliceStable(objs, func(i, j int) bool {
+		pi, _ := phaseOf(objs[i])
+		pj, _ := phaseOf(objs[j])
+		return pi < pj
+	})
+	return objs, nil
+}
+
+// phaseOf returns the phase in which the supplied object is restored, and
+// whether it is a kind of object that is exported.
+func phaseOf(o *kunstructured.Unstructured) (Phase, bool) {
+	gk := o.GroupVersionKind().GroupKind()
+	for _, k := range kinds {
+		if k.gvk.GroupKind() == gk {
+			return k.phase, true
+		}
+	}
+	return 0, false
+}
+
+// installedByPackage returns true if the supplied object is controlled by a
+// package revision.
+func installedByPackage(o metav1.Object) bool {
+	ref := metav1.GetControllerOf(o)
+	if ref == nil {
+		return false
+	}
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	return err == nil && gv.Group == v1.Group
+}
+
+// sanitize removes the status and server-populated metadata of the supplied
+// object, so that it can be created in another cluster.
+func sanitize(u *kunstructured.Unstructured) {
+	kunstructured.RemoveNestedField(u.Object, "status")
+	for _, f := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink", "ownerReferences", "finalizers"} {
+		kunstructured.RemoveNestedField(u.Object, "metadata", f)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplane
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	extv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+)
+
+func TestCollect(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		objs []*kunstructured.Unstructured
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		c      client.Reader
+		want   want
+	}{
+		"ErrList": {
+			reason: "We should return an error if we can't list a kind of object.",
+			c:      &test.MockClient{MockList: test.NewMockListFn(errBoom)},
+			want: want{
+				err: errors.Wrapf(errBoom, errFmtList, "ControllerConfig"),
+			},
+		},
+		"Collected": {
+			reason: "We should collect objects without their status and server-populated metadata, skipping objects installed by packages.",
+			c: &test.MockClient{MockList: test.NewMockListFn(nil, func(o client.ObjectList) error {
+				switch l := o.(type) {
+				case *v1.ProviderList:
+					l.Items = []v1.Provider{{
+						ObjectMeta: metav1.ObjectMeta{Name: "provider-test", ResourceVersion: "42", UID: "cool"},
+						Spec:       v1.ProviderSpec{PackageSpec: v1.PackageSpec{Package: "provider-test:v0.1.0"}},
+						Status:     v1.ProviderStatus{PackageStatus: v1.PackageStatus{CurrentRevision: "provider-test-1234"}},
+					}}
+				case *extv1.CompositionList:
+					l.Items = []extv1.Composition{
+						{ObjectMeta: metav1.ObjectMeta{Name: "mine"}},
+						{ObjectMeta: metav1.ObjectMeta{
+							Name: "packaged",
+							OwnerReferences: []metav1.OwnerReference{{
+								APIVersion: v1.SchemeGroupVersion.String(),
+								Kind:       v1.ConfigurationRevisionKind,
+								Name:       "config-1234",
+								Controller: pointer.Bool(true),
+							}},
+						}},
+					}
+				}
+				return nil
+			})},
+			want: want{
+				objs: []*kunstructured.Unstructured{
+					{Object: map[string]interface{}{
+						"apiVersion": "pkg.crossplane.io/v1",
+						"kind":       "Provider",
+						"metadata":   map[string]interface{}{"name": "provider-test"},
+						"spec":       map[string]interface{}{"package": "provider-test:v0.1.0"},
+					}},
+					{Object: map[string]interface{}{
+						"apiVersion": "apiextensions.crossplane.io/v1",
+						"kind":       "Composition",
+						"metadata":   map[string]interface{}{"name": "mine"},
+						"spec":       map[string]interface{}{"compositeTypeRef": map[string]interface{}{"apiVersion": "", "kind": ""}, "resources": nil},
+					}},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			objs, err := Collect(context.Background(), tc.c)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCollect(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.objs, objs); diff != "" {
+				t.Errorf("\n%s\nCollect(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	type want struct {
+		kinds []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		data   string
+		want   want
+	}{
+		"Ordered": {
+			reason: "We should return objects in the order they should be restored, regardless of the order they were exported in.",
+			data: `apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: cool
+---
+apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: cool
+---
+apiVersion: pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: cool
+---
+apiVersion: pkg.crossplane.io/v1alpha1
+kind: ControllerConfig
+metadata:
+  name: cool
+---
+apiVersion: pkg.crossplane.io/v1alpha1
+kind: Lock
+metadata:
+  name: lock
+`,
+			want: want{
+				kinds: []string{"Lock", "ControllerConfig", "Configuration", "CompositeResourceDefinition", "Composition"},
+			},
+		},
+		"UnknownKind": {
+			reason: "We should return an error if asked to restore a kind of object that isn't exported.",
+			data: `apiVersion: v1
+kind: Secret
+metadata:
+  name: cool
+`,
+			want: want{
+				err: errors.Errorf(errFmtUnknownGK, "Secret"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			objs, err := Unmarshal([]byte(tc.data))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nUnmarshal(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			var kinds []string
+			for _, o := range objs {
+				kinds = append(kinds, o.GetKind())
+			}
+			if diff := cmp.Diff(tc.want.kinds, kinds); diff != "" {
+				t.Errorf("\n%s\nUnmarshal(...): -want kinds, +got kinds:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	objs := []*kunstructured.Unstructured{
+		{Object: map[string]interface{}{"apiVersion": "pkg.crossplane.io/v1", "kind": "Provider", "metadata": map[string]interface{}{"name": "a"}}},
+		{Object: map[string]interface{}{"apiVersion": "pkg.crossplane.io/v1", "kind": "Provider", "metadata": map[string]interface{}{"name": "b"}}},
+	}
+	data, err := Marshal(objs)
+	if err != nil {
+		t.Fatalf("Marshal(...): %s", err)
+	}
+	got, err := Unmarshal(data)
+	if err != nil {
+		t.Fatalf("Unmarshal(...): %s", err)
+	}
+	if diff := cmp.Diff(objs, got); diff != "" {
+		t.Errorf("Unmarshal(Marshal(...)): -want, +got:\n%s", diff)
+	}
+}
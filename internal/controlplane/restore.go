@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplane
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	extv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+)
+
+const (
+	errFmtApply    = "cannot restore %s %q"
+	errFmtGet      = "cannot get restored %s %q"
+	errFmtNotReady = "%s %q did not become ready"
+
+	defaultPollInterval = 5 * time.Second
+)
+
+// readyConditions are the conditions that restored objects of each phase must
+// have before the next phase is restored. Objects of phases that aren't listed
+// are ready once they exist.
+var readyConditions = map[Phase]xpv1.ConditionType{
+	PhasePackages:    v1.TypeHealthy,
+	PhaseDefinitions: extv1.TypeEstablished,
+}
+
+// RestorerOption is used to configure the Restorer.
+type RestorerOption func(*Restorer)
+
+// WithLogger specifies how the Restorer should log messages.
+func WithLogger(log logging.Logger) RestorerOption {
+	return func(r *Restorer) {
+		r.log = log
+	}
+}
+
+// WithPollInterval specifies how often the Restorer checks whether restored
+// objects are ready.
+func WithPollInterval(d time.Duration) RestorerOption {
+	return func(r *Restorer) {
+		r.poll = d
+	}
+}
+
+// A Restorer restores an exported control plane.
+type Restorer struct {
+	client client.Client
+	log    logging.Logger
+	poll   time.Duration
+}
+
+// NewRestorer returns a Restorer that restores exported control planes using
+// the supplied client.
+func NewRestorer(c client.Client, opts ...RestorerOption) *Restorer {
+	r := &Restorer{
+		client: c,
+		log:    logging.NewNopLogger(),
+		poll:   defaultPollInterval,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// Restore the supplied objects, as returned by Unmarshal, one phase at a time.
+// Objects that already exist are updated. Restore waits for every object of a
+// phase to be ready before it restores the next phase, until the supplied
+// context is done.
+func (r *Restorer) Restore(ctx context.Context, objs []*kunstructured.Unstructured) error {
+	a := resource.NewAPIPatchingApplicator(r.client)
+	for i := 0; i < len(objs); {
+		phase, _ := phaseOf(objs[i])
+		j := i
+		for j < len(objs) {
+			if p, _ := phaseOf(objs[j]); p != phase {
+				break
+			}
+			j++
+		}
+		if phase == phaseNotRestored {
+			i = j
+			continue
+		}
+
+		for _, o := range objs[i:j] {
+			r.log.Debug("Restoring object", "kind", o.GetKind(), "name", o.GetName())
+			if err := a.Apply(ctx, o); err != nil {
+				return errors.Wrapf(err, errFmtApply, o.GetKind(), o.GetName())
+			}
+		}
+		for _, o := range objs[i:j] {
+			if err := r.wait(ctx, o, phase); err != nil {
+				return err
+			}
+		}
+		i = j
+	}
+	return nil
+}
+
+// wait for the supplied restored object to be ready.
+func (r *Restorer) wait(ctx context.Context, o *kunstructured.Unstructured, phase Phase) error {
+	ct, ok := readyConditions[phase]
+	if !ok {
+		return nil
+	}
+	r.log.Debug("Waiting for object to become ready", "kind", o.GetKind(), "name", o.GetName(), "condition", ct)
+	err := wait.PollImmediateUntil(r.poll, func() (bool, error) {
+		u := &kunstructured.Unstructured{}
+		u.SetGroupVersionKind(o.GroupVersionKind())
+		if err := r.client.Get(ctx, types.NamespacedName{Name: o.GetName()}, u); err != nil {
+			return false, errors.Wrapf(err, errFmtGet, o.GetKind(), o.GetName())
+		}
+		return hasCondition(u, ct), nil
+	}, ctx.Done())
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return errors.Errorf(errFmtNotReady, o.GetKind(), o.GetName())
+	}
+	return err
+}
+
+// hasCondition returns true if the supplied object has the supplied condition
+// with status True.
+func hasCondition(u *kunstructured.Unstructured, ct xpv1.ConditionType) bool {
+	cs := []xpv1.Condition{}
+	if err := fieldpath.Pave(u.Object).GetValueInto("status.conditions", &cs); err != nil {
+		return false
+	}
+	for _, c := range cs {
+		if c.Type == ct {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
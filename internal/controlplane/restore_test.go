@@ -0,0 +1,151 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controlplane
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	kunstructured "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestRestore(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	objs, _ := Unmarshal([]byte(`apiVersion: apiextensions.crossplane.io/v1
+kind: Composition
+metadata:
+  name: cool
+---
+apiVersion: pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: cool
+---
+apiVersion: pkg.crossplane.io/v1alpha1
+kind: Lock
+metadata:
+  name: lock
+`))
+
+	// ready sets the supplied condition of the supplied object to True.
+	ready := func(u *kunstructured.Unstructured, ct string) {
+		u.Object["status"] = map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": ct, "status": "True"}},
+		}
+	}
+
+	type args struct {
+		c    func(created *[]string) client.Client
+		objs []*kunstructured.Unstructured
+	}
+	type want struct {
+		created []string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Restored": {
+			reason: "We should restore objects one phase at a time, waiting for each phase to be ready, and skip the Lock.",
+			args: args{
+				c: func(created *[]string) client.Client {
+					return &test.MockClient{
+						MockGet: func(_ context.Context, _ client.ObjectKey, o client.Object) error {
+							u := o.(*kunstructured.Unstructured)
+							for _, k := range *created {
+								if k == u.GetKind() {
+									ready(u, "Healthy")
+									return nil
+								}
+							}
+							return kerrors.NewNotFound(schema.GroupResource{}, "")
+						},
+						MockCreate: func(_ context.Context, o client.Object, _ ...client.CreateOption) error {
+							*created = append(*created, o.GetObjectKind().GroupVersionKind().Kind)
+							return nil
+						},
+						MockPatch: test.NewMockPatchFn(nil),
+					}
+				},
+				objs: objs,
+			},
+			want: want{
+				created: []string{"Configuration", "Composition"},
+			},
+		},
+		"ErrApply": {
+			reason: "We should return an error if we can't restore an object.",
+			args: args{
+				c: func(_ *[]string) client.Client {
+					return &test.MockClient{
+						MockGet:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "")),
+						MockCreate: test.NewMockCreateFn(errBoom),
+					}
+				},
+				objs: objs,
+			},
+			want: want{
+				err: errors.Wrapf(errors.Wrap(errBoom, "cannot create object"), errFmtApply, "Configuration", "cool"),
+			},
+		},
+		"NotReady": {
+			reason: "We should return an error if restored objects don't become ready before the context is done.",
+			args: args{
+				c: func(_ *[]string) client.Client {
+					return &test.MockClient{
+						MockGet:    test.NewMockGetFn(nil),
+						MockCreate: test.NewMockCreateFn(nil),
+						MockPatch:  test.NewMockPatchFn(nil),
+					}
+				},
+				objs: objs,
+			},
+			want: want{
+				err: errors.Errorf(errFmtNotReady, "Configuration", "cool"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			var created []string
+			r := NewRestorer(tc.args.c(&created), WithPollInterval(10*time.Millisecond))
+			err := r.Restore(ctx, tc.args.objs)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRestore(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.created, created); diff != "" {
+				t.Errorf("\n%s\nRestore(...): -want created, +got created:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package dag
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -244,3 +245,59 @@ func TestDag(t *testing.T) {
 	d := NewMapDag()
 	d.AddNode(&simpleNode{identifier: "hi"})
 }
+
+// newScaleNodes returns the supplied number of nodes, each of which has edges
+// to up to the supplied number of nodes that precede it.
+func newScaleNodes(nodes, edges int) []Node {
+	n := make([]simpleNode, nodes)
+	for i := range n {
+		n[i] = simpleNode{identifier: fmt.Sprintf("node-%d", i), neighbors: map[string]simpleNode{}}
+		for j := 1; j <= edges && i-j >= 0; j++ {
+			id := fmt.Sprintf("node-%d", i-j)
+			n[i].neighbors[id] = simpleNode{identifier: id}
+		}
+	}
+	return toNodes(n)
+}
+
+func BenchmarkMapDag(b *testing.B) {
+	nodes := newScaleNodes(1000, 5)
+	last := nodes[len(nodes)-1].Identifier()
+
+	b.Run("Init", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := NewMapDag().Init(nodes); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("TraceNode", func(b *testing.B) {
+		d := NewMapDag()
+		if _, err := d.Init(nodes); err != nil {
+			b.Fatal(err)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := d.TraceNode(last); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Sort", func(b *testing.B) {
+		d := NewMapDag()
+		if _, err := d.Init(nodes); err != nil {
+			b.Fatal(err)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := d.Sort(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
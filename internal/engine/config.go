@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"sync"
+)
+
+// A ConfigTracker tracks the configuration each running controller was started
+// with, by controller name. Starting a controller that is already running is a
+// no-op, so a controller must be stopped to pick up new configuration.
+type ConfigTracker struct {
+	mu      sync.Mutex
+	configs map[string]string
+}
+
+// Changed returns true if the named controller was started with configuration
+// other than the supplied configuration.
+func (t *ConfigTracker) Changed(name, config string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	started, ok := t.configs[name]
+	return ok && started != config
+}
+
+// Started records that the named controller was started with the supplied
+// configuration.
+func (t *ConfigTracker) Started(name, config string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.configs == nil {
+		t.configs = map[string]string{}
+	}
+	t.configs[name] = config
+}
+
+// Stopped forgets the configuration of the named controller.
+func (t *ConfigTracker) Stopped(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.configs, name)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestConfigTrackerChanged(t *testing.T) {
+	type args struct {
+		started map[string]string
+		stopped []string
+		name    string
+		config  string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   bool
+	}{
+		"NotStarted": {
+			reason: "A controller that we didn't start hasn't changed.",
+			args: args{
+				name:   "cool",
+				config: "crossplane-system",
+			},
+			want: false,
+		},
+		"Unchanged": {
+			reason: "A controller that we started with the supplied configuration hasn't changed.",
+			args: args{
+				started: map[string]string{"cool": "crossplane-system"},
+				name:    "cool",
+				config:  "crossplane-system",
+			},
+			want: false,
+		},
+		"Changed": {
+			reason: "A controller that we started with different configuration has changed.",
+			args: args{
+				started: map[string]string{"cool": ""},
+				name:    "cool",
+				config:  "crossplane-system",
+			},
+			want: true,
+		},
+		"Stopped": {
+			reason: "A controller that we stopped hasn't changed.",
+			args: args{
+				started: map[string]string{"cool": ""},
+				stopped: []string{"cool"},
+				name:    "cool",
+				config:  "crossplane-system",
+			},
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ct := &ConfigTracker{}
+			for n, c := range tc.args.started {
+				ct.Started(n, c)
+			}
+			for _, n := range tc.args.stopped {
+				ct.Stopped(n)
+			}
+			got := ct.Changed(tc.args.name, tc.args.config)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nct.Changed(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,340 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package engine manages the lifecycles of dynamically started controllers,
+// and the informer caches they share.
+package engine
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+// Error strings
+const (
+	errCreateCache      = "cannot create new cache"
+	errCreateController = "cannot create new controller"
+	errCrashCache       = "cache error"
+	errCrashController  = "controller error"
+	errWatch            = "cannot setup watch"
+	errGetGVK           = "cannot determine kind of watched object"
+)
+
+// A NewCacheFn creates a new controller-runtime cache.
+type NewCacheFn func(cfg *rest.Config, o cache.Options) (cache.Cache, error)
+
+// A NewControllerFn creates a new controller-runtime controller.
+type NewControllerFn func(name string, m manager.Manager, o controller.Options) (controller.Controller, error)
+
+// The default new cache and new controller functions.
+var (
+	DefaultNewCacheFn      NewCacheFn      = cache.New
+	DefaultNewControllerFn NewControllerFn = controller.NewUnmanaged
+)
+
+// A sharedCache is an informer cache for a single kind of object, shared by
+// all of the controllers that watch that kind.
+type sharedCache struct {
+	cache.Cache
+	stop  context.CancelFunc
+	users map[string]bool
+}
+
+// A runningController is a controller that has been started, and the kinds of
+// object whose caches it uses.
+type runningController struct {
+	stop  context.CancelFunc
+	kinds []schema.GroupVersionKind
+}
+
+// An Engine manages the lifecycles of controller-runtime controllers, and the
+// caches they use. Controllers that watch the same kind of object share a
+// cache, which is stopped when the last controller that uses it is stopped.
+// The lifecycles of the controllers are not coupled to lifecycle of the engine,
+// nor to the lifecycle of the controller manager it uses.
+type Engine struct {
+	mgr manager.Manager
+
+	started map[string]*runningController
+	errors  map[string]error
+	caches  map[schema.GroupVersionKind]*sharedCache
+	mx      sync.RWMutex
+
+	newCache NewCacheFn
+	newCtrl  NewControllerFn
+
+	log logging.Logger
+}
+
+// An EngineOption configures an Engine.
+type EngineOption func(*Engine)
+
+// WithNewCacheFn may be used to configure a different cache implementation.
+// DefaultNewCacheFn is used by default.
+func WithNewCacheFn(fn NewCacheFn) EngineOption {
+	return func(e *Engine) {
+		e.newCache = fn
+	}
+}
+
+// WithNewControllerFn may be used to configure a different controller
+// implementation. DefaultNewControllerFn is used by default.
+func WithNewControllerFn(fn NewControllerFn) EngineOption {
+	return func(e *Engine) {
+		e.newCtrl = fn
+	}
+}
+
+// WithLogger specifies how the Engine should log messages.
+func WithLogger(l logging.Logger) EngineOption {
+	return func(e *Engine) {
+		e.log = l
+	}
+}
+
+// New produces a new Engine.
+func New(mgr manager.Manager, o ...EngineOption) *Engine {
+	e := &Engine{
+		mgr: mgr,
+
+		started: make(map[string]*runningController),
+		errors:  make(map[string]error),
+		caches:  make(map[schema.GroupVersionKind]*sharedCache),
+
+		newCache: DefaultNewCacheFn,
+		newCtrl:  DefaultNewControllerFn,
+
+		log: logging.NewNopLogger(),
+	}
+
+	for _, eo := range o {
+		eo(e)
+	}
+
+	return e
+}
+
+// IsRunning indicates whether the named controller is running - i.e. whether it
+// has been started and does not appear to have crashed.
+func (e *Engine) IsRunning(name string) bool {
+	e.mx.RLock()
+	defer e.mx.RUnlock()
+
+	_, running := e.started[name]
+	return running
+}
+
+// Err returns any error encountered by the named controller. The returned error
+// is always nil if the named controller is running.
+func (e *Engine) Err(name string) error {
+	e.mx.RLock()
+	defer e.mx.RUnlock()
+
+	return e.errors[name]
+}
+
+// Caches returns the number of caches that are currently running.
+func (e *Engine) Caches() int {
+	e.mx.RLock()
+	defer e.mx.RUnlock()
+
+	return len(e.caches)
+}
+
+// Stop the named controller.
+func (e *Engine) Stop(name string) {
+	e.done(name, nil, nil)
+}
+
+// done stops the named controller, recording the supplied error. If a running
+// controller is supplied, done does nothing unless it's the controller that is
+// running under the supplied name. A controller that was stopped and started
+// again is running under the same name by the time the old controller returns.
+func (e *Engine) done(name string, only *runningController, err error) {
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	if only != nil && e.started[name] != only {
+		return
+	}
+
+	if rc, ok := e.started[name]; ok {
+		rc.stop()
+		delete(e.started, name)
+		for _, gvk := range rc.kinds {
+			e.release(name, gvk)
+		}
+	}
+
+	// Don't overwrite the first error if done is called multiple times.
+	if e.errors[name] != nil {
+		return
+	}
+	e.errors[name] = err
+}
+
+// Watch an object.
+type Watch struct {
+	kind       client.Object
+	handler    handler.EventHandler
+	predicates []predicate.Predicate
+}
+
+// For returns a Watch for the supplied kind of object. Events will be handled
+// by the supplied EventHandler, and may be filtered by the supplied predicates.
+func For(kind client.Object, h handler.EventHandler, p ...predicate.Predicate) Watch {
+	return Watch{kind: kind, handler: h, predicates: p}
+}
+
+// Start the named controller. The controller is started with the supplied
+// options, and configured with the supplied watches. Each watch uses the cache
+// for its kind of object, which is started if no running controller uses it
+// yet. Start does not block.
+func (e *Engine) Start(name string, o controller.Options, w ...Watch) error {
+	if e.IsRunning(name) {
+		return nil
+	}
+
+	ctrl, err := e.newCtrl(name, e.mgr, o)
+	if err != nil {
+		return errors.Wrap(err, errCreateController)
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	rc := &runningController{stop: stop}
+	e.mx.Lock()
+	e.started[name] = rc
+	e.errors[name] = nil
+	e.mx.Unlock()
+
+	for _, wt := range w {
+		ca, err := e.acquire(name, rc, wt.kind)
+		if err != nil {
+			e.done(name, rc, err)
+			return err
+		}
+		if err := ctrl.Watch(source.NewKindWithCache(wt.kind, ca), wt.handler, wt.predicates...); err != nil {
+			err = errors.Wrap(err, errWatch)
+			e.done(name, rc, err)
+			return err
+		}
+	}
+
+	go func() {
+		<-e.mgr.Elected()
+		e.done(name, rc, errors.Wrap(ctrl.Start(ctx), errCrashController))
+	}()
+
+	return nil
+}
+
+// acquire the cache for the supplied kind of object on behalf of the named
+// controller, creating and starting it if necessary.
+func (e *Engine) acquire(name string, rc *runningController, obj client.Object) (cache.Cache, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Empty() {
+		var err error
+		if gvk, err = apiutil.GVKForObject(obj, e.mgr.GetScheme()); err != nil {
+			return nil, errors.Wrap(err, errGetGVK)
+		}
+	}
+
+	e.mx.Lock()
+	defer e.mx.Unlock()
+
+	rc.kinds = append(rc.kinds, gvk)
+
+	if sc, ok := e.caches[gvk]; ok {
+		sc.users[name] = true
+		return sc, nil
+	}
+
+	// Each kind gets its own cache because there's currently no way to stop
+	// an informer. In practice a controller-runtime cache is a map of kinds to
+	// informers. If we delete the CRD for a kind we need to stop the relevant
+	// informer, or it will spew errors about the kind not existing. We work
+	// around this by stopping the entire cache once no controller uses it.
+	ca, err := e.newCache(e.mgr.GetConfig(), cache.Options{Scheme: e.mgr.GetScheme(), Mapper: e.mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, errors.Wrap(err, errCreateCache)
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	sc := &sharedCache{Cache: ca, stop: stop, users: map[string]bool{name: true}}
+	e.caches[gvk] = sc
+	e.log.Debug("Starting shared cache", "kind", gvk.String())
+
+	go func() {
+		<-e.mgr.Elected()
+		e.crash(gvk, sc, ca.Start(ctx))
+	}()
+
+	return sc, nil
+}
+
+// release the cache for the supplied kind of object on behalf of the named
+// controller, stopping it if no other controller uses it. The caller must hold
+// the Engine's lock.
+func (e *Engine) release(name string, gvk schema.GroupVersionKind) {
+	sc, ok := e.caches[gvk]
+	if !ok {
+		return
+	}
+	delete(sc.users, name)
+	if len(sc.users) > 0 {
+		return
+	}
+	sc.stop()
+	delete(e.caches, gvk)
+	e.log.Debug("Stopped shared cache", "kind", gvk.String())
+}
+
+// crash stops all of the controllers that use the supplied cache, which
+// returned the supplied error.
+func (e *Engine) crash(gvk schema.GroupVersionKind, sc *sharedCache, err error) {
+	e.mx.Lock()
+	if e.caches[gvk] == sc {
+		sc.stop()
+		delete(e.caches, gvk)
+	}
+	users := make([]string, 0, len(sc.users))
+	for name := range sc.users {
+		users = append(users, name)
+	}
+	e.mx.Unlock()
+
+	// A cache that returns without error was stopped because no controller
+	// uses it anymore.
+	if err == nil {
+		return
+	}
+	for _, name := range users {
+		e.done(name, nil, errors.Wrap(err, errCrashCache))
+	}
+}
@@ -0,0 +1,304 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+type MockCache struct {
+	cache.Cache
+
+	MockStart func(stop context.Context) error
+}
+
+func (c *MockCache) Start(stop context.Context) error {
+	return c.MockStart(stop)
+}
+
+type MockController struct {
+	controller.Controller
+
+	MockStart func(stop context.Context) error
+	MockWatch func(s source.Source, h handler.EventHandler, p ...predicate.Predicate) error
+}
+
+func (c *MockController) Start(stop context.Context) error {
+	return c.MockStart(stop)
+}
+
+func (c *MockController) Watch(s source.Source, h handler.EventHandler, p ...predicate.Predicate) error {
+	return c.MockWatch(s, h, p...)
+}
+
+func kind(k string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(schema.GroupVersionKind{Group: "example.org", Version: "v1", Kind: k})
+	return u
+}
+
+func TestEngine(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	blocking := func(stop context.Context) error {
+		<-stop.Done()
+		return nil
+	}
+	newBlockingController := func(string, manager.Manager, controller.Options) (controller.Controller, error) {
+		return &MockController{
+			MockStart: blocking,
+			MockWatch: func(source.Source, handler.EventHandler, ...predicate.Predicate) error { return nil },
+		}, nil
+	}
+
+	type args struct {
+		name string
+		o    controller.Options
+		w    []Watch
+	}
+	type want struct {
+		err   error
+		crash error
+	}
+	cases := map[string]struct {
+		reason string
+		e      *Engine
+		args   args
+		want   want
+	}{
+		"NewCacheError": {
+			reason: "Errors creating a new cache should be returned",
+			e: New(&fake.Manager{},
+				WithNewCacheFn(func(*rest.Config, cache.Options) (cache.Cache, error) { return nil, errBoom }),
+				WithNewControllerFn(newBlockingController),
+			),
+			args: args{
+				name: "coolcontroller",
+				w:    []Watch{For(kind("Cool"), nil)},
+			},
+			want: want{
+				err:   errors.Wrap(errBoom, errCreateCache),
+				crash: errors.Wrap(errBoom, errCreateCache),
+			},
+		},
+		"NewControllerError": {
+			reason: "Errors creating a new controller should be returned",
+			e: New(&fake.Manager{},
+				WithNewControllerFn(func(string, manager.Manager, controller.Options) (controller.Controller, error) { return nil, errBoom }),
+			),
+			args: args{
+				name: "coolcontroller",
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errCreateController),
+			},
+		},
+		"WatchError": {
+			reason: "Errors adding a watch should be returned",
+			e: New(&fake.Manager{},
+				WithNewCacheFn(func(*rest.Config, cache.Options) (cache.Cache, error) {
+					return &MockCache{MockStart: blocking}, nil
+				}),
+				WithNewControllerFn(func(string, manager.Manager, controller.Options) (controller.Controller, error) {
+					c := &MockController{MockWatch: func(source.Source, handler.EventHandler, ...predicate.Predicate) error { return errBoom }}
+					return c, nil
+				}),
+			),
+			args: args{
+				name: "coolcontroller",
+				w:    []Watch{For(kind("Cool"), nil)},
+			},
+			want: want{
+				err:   errors.Wrap(errBoom, errWatch),
+				crash: errors.Wrap(errBoom, errWatch),
+			},
+		},
+		"CacheCrashError": {
+			reason: "Errors starting or running a cache should be returned",
+			e: New(&fake.Manager{},
+				WithNewCacheFn(func(*rest.Config, cache.Options) (cache.Cache, error) {
+					c := &MockCache{MockStart: func(stop context.Context) error { return errBoom }}
+					return c, nil
+				}),
+				WithNewControllerFn(newBlockingController),
+			),
+			args: args{
+				name: "coolcontroller",
+				w:    []Watch{For(kind("Cool"), nil)},
+			},
+			want: want{
+				crash: errors.Wrap(errBoom, errCrashCache),
+			},
+		},
+		"ControllerCrashError": {
+			reason: "Errors starting or running a controller should be returned",
+			e: New(&fake.Manager{},
+				WithNewCacheFn(func(*rest.Config, cache.Options) (cache.Cache, error) {
+					return &MockCache{MockStart: blocking}, nil
+				}),
+				WithNewControllerFn(func(string, manager.Manager, controller.Options) (controller.Controller, error) {
+					c := &MockController{MockStart: func(stop context.Context) error {
+						return errBoom
+					}}
+					return c, nil
+				}),
+			),
+			args: args{
+				name: "coolcontroller",
+			},
+			want: want{
+				crash: errors.Wrap(errBoom, errCrashController),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := tc.e.Start(tc.args.name, tc.args.o, tc.args.w...)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Start(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+
+			// Give the goroutines a little time to return an error. If this
+			// becomes flaky or time consuming we could use a ticker instead.
+			time.Sleep(100 * time.Millisecond)
+
+			tc.e.Stop(tc.args.name)
+			if diff := cmp.Diff(tc.want.crash, tc.e.Err(tc.args.name), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Err(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(0, tc.e.Caches()); diff != "" {
+				t.Errorf("\n%s\ne.Caches(): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestEngineSharedCaches(t *testing.T) {
+	mx := sync.Mutex{}
+	created, stopped := 0, 0
+
+	e := New(&fake.Manager{},
+		WithNewCacheFn(func(*rest.Config, cache.Options) (cache.Cache, error) {
+			mx.Lock()
+			created++
+			mx.Unlock()
+			return &MockCache{MockStart: func(stop context.Context) error {
+				<-stop.Done()
+				mx.Lock()
+				stopped++
+				mx.Unlock()
+				return nil
+			}}, nil
+		}),
+		WithNewControllerFn(func(string, manager.Manager, controller.Options) (controller.Controller, error) {
+			return &MockController{
+				MockStart: func(stop context.Context) error { <-stop.Done(); return nil },
+				MockWatch: func(source.Source, handler.EventHandler, ...predicate.Predicate) error { return nil },
+			}, nil
+		}),
+	)
+
+	counts := func() (int, int) {
+		// Give the cache goroutines a little time to return.
+		time.Sleep(100 * time.Millisecond)
+		mx.Lock()
+		defer mx.Unlock()
+		return created, stopped
+	}
+
+	if err := e.Start("composite", controller.Options{}, For(kind("XCool"), nil)); err != nil {
+		t.Fatalf("e.Start(...): %s", err)
+	}
+	if err := e.Start("claim", controller.Options{}, For(kind("Cool"), nil), For(kind("XCool"), nil)); err != nil {
+		t.Fatalf("e.Start(...): %s", err)
+	}
+	if c, s := counts(); c != 2 || s != 0 || e.Caches() != 2 {
+		t.Errorf("After starting controllers: want 2 caches created, 0 stopped, 2 running; got %d created, %d stopped, %d running", c, s, e.Caches())
+	}
+
+	// The composite controller still uses the XCool cache.
+	e.Stop("claim")
+	if c, s := counts(); c != 2 || s != 1 || e.Caches() != 1 {
+		t.Errorf("After stopping claim controller: want 2 caches created, 1 stopped, 1 running; got %d created, %d stopped, %d running", c, s, e.Caches())
+	}
+
+	e.Stop("composite")
+	if c, s := counts(); c != 2 || s != 2 || e.Caches() != 0 {
+		t.Errorf("After stopping composite controller: want 2 caches created, 2 stopped, 0 running; got %d created, %d stopped, %d running", c, s, e.Caches())
+	}
+}
+
+func TestEngineRestart(t *testing.T) {
+	e := New(&fake.Manager{},
+		WithNewCacheFn(func(*rest.Config, cache.Options) (cache.Cache, error) {
+			return &MockCache{MockStart: func(stop context.Context) error { <-stop.Done(); return nil }}, nil
+		}),
+		WithNewControllerFn(func(string, manager.Manager, controller.Options) (controller.Controller, error) {
+			return &MockController{
+				// Controllers take a little while to return once
+				// they're stopped.
+				MockStart: func(stop context.Context) error {
+					<-stop.Done()
+					time.Sleep(50 * time.Millisecond)
+					return nil
+				},
+				MockWatch: func(source.Source, handler.EventHandler, ...predicate.Predicate) error { return nil },
+			}, nil
+		}),
+	)
+
+	if err := e.Start("composite", controller.Options{}, For(kind("XCool"), nil)); err != nil {
+		t.Fatalf("e.Start(...): %s", err)
+	}
+
+	// Restart the controller before the stopped controller returns.
+	e.Stop("composite")
+	if err := e.Start("composite", controller.Options{}, For(kind("XCool"), nil)); err != nil {
+		t.Fatalf("e.Start(...): %s", err)
+	}
+
+	// Give the stopped controller time to return.
+	time.Sleep(100 * time.Millisecond)
+
+	if !e.IsRunning("composite") {
+		t.Errorf("e.IsRunning(...): want restarted controller to be running after the stopped controller returned")
+	}
+	if e.Caches() != 1 {
+		t.Errorf("e.Caches(): want 1 cache used by the restarted controller, got %d", e.Caches())
+	}
+
+	e.Stop("composite")
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package events contains Kubernetes event recorders.
+package events
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+)
+
+const msgFmtSuppressed = "%s (%d identical events were suppressed)"
+
+// A DedupingRecorder suppresses events that are identical to an event recorded
+// about the same object within its suppression window. The number of events
+// that were suppressed is folded into the message of the next identical event
+// that is recorded. Counts of suppressed events that don't recur for a whole
+// window are discarded.
+type DedupingRecorder struct {
+	wrapped event.Recorder
+	window  time.Duration
+	seen    *seen
+}
+
+// NewDedupingRecorder returns a Recorder that records events using the
+// supplied Recorder, suppressing identical events about the same object for
+// the supplied window. Events are never suppressed if the window is zero.
+func NewDedupingRecorder(r event.Recorder, window time.Duration) *DedupingRecorder {
+	return &DedupingRecorder{wrapped: r, window: window, seen: newSeen(time.Now)}
+}
+
+// Event records the supplied event, unless it is suppressed.
+func (r *DedupingRecorder) Event(obj runtime.Object, e event.Event) {
+	o, ok := obj.(metav1.Object)
+	if !ok || r.window <= 0 {
+		r.wrapped.Event(obj, e)
+		return
+	}
+
+	k := key{uid: o.GetUID(), namespace: o.GetNamespace(), name: o.GetName(), typ: e.Type, reason: e.Reason, message: e.Message}
+	record, suppressed := r.seen.observe(k, r.window)
+	if !record {
+		return
+	}
+	if suppressed > 0 {
+		e.Message = fmt.Sprintf(msgFmtSuppressed, e.Message, suppressed)
+	}
+	r.wrapped.Event(obj, e)
+}
+
+// WithAnnotations returns a new *DedupingRecorder that includes the supplied
+// annotations with all recorded events. It suppresses events that were
+// recorded by this recorder, and vice versa.
+func (r *DedupingRecorder) WithAnnotations(keysAndValues ...string) event.Recorder {
+	return &DedupingRecorder{wrapped: r.wrapped.WithAnnotations(keysAndValues...), window: r.window, seen: r.seen}
+}
+
+// A key identifies identical events about an object.
+type key struct {
+	uid       types.UID
+	namespace string
+	name      string
+	typ       event.Type
+	reason    event.Reason
+	message   string
+}
+
+type occurrence struct {
+	// recorded is when the event was last recorded.
+	recorded time.Time
+
+	// last is when the event last occurred, whether or not it was recorded.
+	last time.Time
+
+	// suppressed is how many times the event occurred without being recorded
+	// since it was last recorded.
+	suppressed int
+}
+
+// seen tracks recent occurrences of events. It is shared by a recorder and all
+// of the recorders derived from it.
+type seen struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	events map[key]*occurrence
+	pruned time.Time
+}
+
+func newSeen(now func() time.Time) *seen {
+	return &seen{now: now, events: map[key]*occurrence{}, pruned: now()}
+}
+
+// observe an occurrence of the supplied event. It returns true if the event
+// should be recorded, and how many times it was suppressed since it was last
+// recorded.
+func (s *seen) observe(k key, window time.Duration) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now, window)
+
+	o, ok := s.events[k]
+	if ok && now.Sub(o.recorded) < window {
+		o.last = now
+		o.suppressed++
+		return false, 0
+	}
+
+	suppressed := 0
+	if ok {
+		suppressed = o.suppressed
+	}
+	s.events[k] = &occurrence{recorded: now, last: now}
+	return true, suppressed
+}
+
+// prune occurrences of events that haven't occurred for a whole window. We
+// prune at most once per window so that observing an event is usually cheap.
+func (s *seen) prune(now time.Time, window time.Duration) {
+	if now.Sub(s.pruned) < window {
+		return
+	}
+	for k, o := range s.events {
+		if now.Sub(o.last) >= window {
+			delete(s.events, k)
+		}
+	}
+	s.pruned = now
+}
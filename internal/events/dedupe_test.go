@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+)
+
+// A recorder records the messages of the events it is asked to record.
+type recorder struct {
+	messages *[]string
+}
+
+func (r recorder) Event(_ runtime.Object, e event.Event) {
+	*r.messages = append(*r.messages, e.Message)
+}
+
+func (r recorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestDedupingRecorder(t *testing.T) {
+	start := time.Now()
+	a := &fake.Managed{ObjectMeta: metav1.ObjectMeta{Name: "a", UID: "a"}}
+	b := &fake.Managed{ObjectMeta: metav1.ObjectMeta{Name: "b", UID: "b"}}
+
+	type occurrence struct {
+		after time.Duration
+		obj   runtime.Object
+		e     event.Event
+	}
+
+	cases := map[string]struct {
+		reason      string
+		window      time.Duration
+		occurrences []occurrence
+		want        []string
+	}{
+		"Disabled": {
+			reason: "Events should never be suppressed if the window is zero.",
+			window: 0,
+			occurrences: []occurrence{
+				{after: 0, obj: a, e: event.Normal("Sync", "synced")},
+				{after: time.Second, obj: a, e: event.Normal("Sync", "synced")},
+			},
+			want: []string{"synced", "synced"},
+		},
+		"SuppressIdentical": {
+			reason: "Identical events about the same object should be suppressed within the window, and counted in the next identical event.",
+			window: time.Minute,
+			occurrences: []occurrence{
+				{after: 0, obj: a, e: event.Normal("Sync", "synced")},
+				{after: 10 * time.Second, obj: a, e: event.Normal("Sync", "synced")},
+				{after: 20 * time.Second, obj: a, e: event.Normal("Sync", "synced")},
+				{after: 70 * time.Second, obj: a, e: event.Normal("Sync", "synced")},
+			},
+			want: []string{"synced", fmt.Sprintf(msgFmtSuppressed, "synced", 2)},
+		},
+		"DifferentEvents": {
+			reason: "Events that differ, or that are about different objects, should not be suppressed.",
+			window: time.Minute,
+			occurrences: []occurrence{
+				{after: 0, obj: a, e: event.Normal("Sync", "synced")},
+				{after: time.Second, obj: a, e: event.Normal("Sync", "still synced")},
+				{after: 2 * time.Second, obj: a, e: event.Warning("Sync", fmt.Errorf("synced"))},
+				{after: 3 * time.Second, obj: b, e: event.Normal("Sync", "synced")},
+			},
+			want: []string{"synced", "still synced", "synced", "synced"},
+		},
+		"DiscardStaleCounts": {
+			reason: "Counts of suppressed events that don't recur for a whole window should be discarded.",
+			window: time.Minute,
+			occurrences: []occurrence{
+				{after: 0, obj: a, e: event.Normal("Sync", "synced")},
+				{after: 10 * time.Second, obj: a, e: event.Normal("Sync", "synced")},
+				{after: 5 * time.Minute, obj: a, e: event.Normal("Sync", "synced")},
+			},
+			want: []string{"synced", "synced"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := []string{}
+			now := start
+			r := NewDedupingRecorder(recorder{messages: &got}, tc.window)
+			r.seen = newSeen(func() time.Time { return now })
+
+			for _, o := range tc.occurrences {
+				now = start.Add(o.after)
+				r.WithAnnotations("controller", "test").Event(o.obj, o.e)
+			}
+
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nEvent(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -28,4 +28,14 @@ const (
 	// External Secret Stores. See the below design for more details.
 	// https://github.com/crossplane/crossplane/blob/390ddd/design/design-doc-external-secret-stores.md
 	EnableAlphaExternalSecretStores feature.Flag = "EnableAlphaExternalSecretStores"
+	// EnableAlphaPackageTests enables alpha support for running the tests
+	// declared by Configuration packages once they're installed.
+	EnableAlphaPackageTests feature.Flag = "EnableAlphaPackageTests"
+	// EnableAlphaObjectTransformers enables alpha support for transforming
+	// the objects of packages, using the transformers registered with the
+	// package revision controllers, before they're installed.
+	EnableAlphaObjectTransformers feature.Flag = "EnableAlphaObjectTransformers"
+	// EnableAlphaPackagePropagation enables alpha support for propagating
+	// packages to spoke clusters using PackagePropagations.
+	EnableAlphaPackagePropagation feature.Flag = "EnableAlphaPackagePropagation"
 )
@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initializer
+
+import (
+	"context"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+)
+
+const (
+	errCreateClusterHealth = "cannot create cluster health object"
+)
+
+// NewClusterHealthObject returns a new *ClusterHealthObject initializer.
+func NewClusterHealthObject() *ClusterHealthObject {
+	return &ClusterHealthObject{}
+}
+
+// ClusterHealthObject has the initializer for creating the ClusterHealth
+// object.
+type ClusterHealthObject struct{}
+
+// Run makes sure the ClusterHealth object exists.
+func (ho *ClusterHealthObject) Run(ctx context.Context, kube client.Client) error {
+	h := &v1alpha1.ClusterHealth{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: v1alpha1.ClusterHealthName,
+		},
+	}
+	return errors.Wrap(resource.Ignore(kerrors.IsAlreadyExists, kube.Create(ctx, h)), errCreateClusterHealth)
+}
@@ -18,9 +18,12 @@ package initializer
 
 import (
 	"context"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -35,20 +38,44 @@ const (
 	errListConfigurations = "failed getting configuration list"
 	errParsePackageName   = "package name is not valid"
 	errApplyPackage       = "cannot apply package"
+	errGetBootstrap       = "cannot get bootstrap ConfigMap"
 )
 
+// Keys of a bootstrap ConfigMap. Each lists package image URIs, one per line.
+// Empty lines and lines starting with '#' are ignored.
+const (
+	KeyBootstrapProviders      = "providers"
+	KeyBootstrapConfigurations = "configurations"
+)
+
+// PackageInstallerOption is used to configure a PackageInstaller.
+type PackageInstallerOption func(*PackageInstaller)
+
+// WithBootstrapConfigMap specifies a ConfigMap that lists additional packages
+// to install.
+func WithBootstrapConfigMap(nn types.NamespacedName) PackageInstallerOption {
+	return func(pi *PackageInstaller) {
+		pi.bootstrap = nn
+	}
+}
+
 // NewPackageInstaller returns a new package installer.
-func NewPackageInstaller(p []string, c []string) *PackageInstaller {
-	return &PackageInstaller{
+func NewPackageInstaller(p []string, c []string, opts ...PackageInstallerOption) *PackageInstaller {
+	pi := &PackageInstaller{
 		providers:      p,
 		configurations: c,
 	}
+	for _, o := range opts {
+		o(pi)
+	}
+	return pi
 }
 
 // PackageInstaller has the initializer for installing a list of packages.
 type PackageInstaller struct {
 	configurations []string
 	providers      []string
+	bootstrap      types.NamespacedName
 }
 
 // Run makes sure all specified packages exist.
@@ -56,7 +83,17 @@ type PackageInstaller struct {
 // only runs at installation time and is performing fairly straightforward
 // operations.
 func (pi *PackageInstaller) Run(ctx context.Context, kube client.Client) error { //nolint:gocyclo
-	pkgs := make([]client.Object, len(pi.providers)+len(pi.configurations))
+	providers, configurations := pi.providers, pi.configurations
+	if pi.bootstrap.Name != "" {
+		cm := &corev1.ConfigMap{}
+		if err := kube.Get(ctx, pi.bootstrap, cm); err != nil {
+			return errors.Wrap(err, errGetBootstrap)
+		}
+		providers = append(append([]string{}, providers...), images(cm.Data[KeyBootstrapProviders])...)
+		configurations = append(append([]string{}, configurations...), images(cm.Data[KeyBootstrapConfigurations])...)
+	}
+
+	pkgs := make([]client.Object, len(providers)+len(configurations))
 	// NOTE(hasheddan): we build maps of existing Provider and Configuration
 	// sources to the package names such that we can update the version when a
 	// package specified for install matches the source of an existing package.
@@ -92,7 +129,7 @@ func (pi *PackageInstaller) Run(ctx context.Context, kube client.Client) error {
 	// NOTE(hasheddan): we maintain a separate index from the range so that
 	// Providers and Configurations can be added to the same slice for applying.
 	pkgsIdx := 0
-	for _, img := range pi.providers {
+	for _, img := range providers {
 		p := &v1.Provider{}
 		if err := buildPack(p, img, pMap); err != nil {
 			return err
@@ -100,7 +137,7 @@ func (pi *PackageInstaller) Run(ctx context.Context, kube client.Client) error {
 		pkgs[pkgsIdx] = p
 		pkgsIdx++
 	}
-	for _, img := range pi.configurations {
+	for _, img := range configurations {
 		c := &v1.Configuration{}
 		if err := buildPack(c, img, cMap); err != nil {
 			return err
@@ -130,3 +167,16 @@ func buildPack(pack v1.Package, img string, pkgMap map[string]string) error {
 	pack.SetSource(ref.String())
 	return nil
 }
+
+// images returns the package images listed by a key of a bootstrap ConfigMap.
+func images(list string) []string {
+	imgs := make([]string, 0)
+	for _, l := range strings.Split(list, "\n") {
+		l = strings.TrimSpace(l)
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		imgs = append(imgs, l)
+	}
+	return imgs
+}
@@ -22,9 +22,11 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -55,6 +57,7 @@ func TestInstaller(t *testing.T) {
 	type args struct {
 		p    []string
 		c    []string
+		opts []PackageInstallerOption
 		kube client.Client
 	}
 	type want struct {
@@ -337,6 +340,62 @@ func TestInstaller(t *testing.T) {
 				},
 			},
 		},
+		"SuccessBootstrapConfigMap": {
+			args: args{
+				opts: []PackageInstallerOption{WithBootstrapConfigMap(types.NamespacedName{Namespace: "crossplane-system", Name: "bootstrap"})},
+				kube: &test.MockClient{
+					MockList: func(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+						return nil
+					},
+					MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+						switch o := obj.(type) {
+						case *corev1.ConfigMap:
+							o.Data = map[string]string{
+								KeyBootstrapProviders:      "# Providers to install.\n" + p1 + "\n\n",
+								KeyBootstrapConfigurations: c1,
+							}
+							return nil
+						case *v1.Provider:
+							if key.Name != p1Name {
+								t.Errorf(errGetProviderFmt, key.Name)
+							}
+						case *v1.Configuration:
+							if key.Name != c1Name {
+								t.Errorf(errGetConfigurationFmt, key.Name)
+							}
+						default:
+							t.Errorf("unexpected type")
+						}
+						return kerrors.NewNotFound(schema.GroupResource{}, key.Name)
+					},
+					MockCreate: func(_ context.Context, obj client.Object, _ ...client.CreateOption) error {
+						switch o := obj.(type) {
+						case *v1.Provider:
+							if o.GetSource() != p1 {
+								t.Errorf(errPatchProviderSourceFmt, o.GetSource())
+							}
+						case *v1.Configuration:
+							if o.GetSource() != c1 {
+								t.Errorf(errPatchConfigurationSourceFmt, o.GetSource())
+							}
+						}
+						return nil
+					},
+				},
+			},
+		},
+		"FailGetBootstrapConfigMap": {
+			args: args{
+				p:    []string{p1},
+				opts: []PackageInstallerOption{WithBootstrapConfigMap(types.NamespacedName{Namespace: "crossplane-system", Name: "bootstrap"})},
+				kube: &test.MockClient{
+					MockGet: test.NewMockGetFn(errBoom),
+				},
+			},
+			want: want{
+				err: errors.Wrap(errBoom, errGetBootstrap),
+			},
+		},
 		"FailApply": {
 			args: args{
 				p: []string{p1},
@@ -357,7 +416,7 @@ func TestInstaller(t *testing.T) {
 	}
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			i := NewPackageInstaller(tc.args.p, tc.args.c)
+			i := NewPackageInstaller(tc.args.p, tc.args.c, tc.args.opts...)
 			err := i.Run(context.TODO(), tc.args.kube)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\nRun(...): -want err, +got err:\n%s", name, diff)
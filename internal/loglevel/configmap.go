@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loglevel
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const (
+	// KeyDebug is the ConfigMap data key that lists which controllers should
+	// emit debug logs. Its value is a comma or newline separated list of
+	// controller name prefixes, e.g. 'packages/providerrevision'.
+	KeyDebug = "debug"
+
+	errGetConfigMap = "cannot get log levels ConfigMap"
+)
+
+// A ConfigMapWatcher periodically reads which controllers should emit debug
+// logs from a ConfigMap.
+type ConfigMapWatcher struct {
+	client   client.Reader
+	name     types.NamespacedName
+	levels   *Levels
+	log      logging.Logger
+	interval time.Duration
+}
+
+// NewConfigMapWatcher returns a ConfigMapWatcher that reads the named
+// ConfigMap at the supplied interval, and updates the supplied Levels
+// accordingly. It should be supplied an uncached client, in order to avoid
+// caching all ConfigMaps.
+func NewConfigMapWatcher(c client.Reader, name types.NamespacedName, l *Levels, log logging.Logger, interval time.Duration) *ConfigMapWatcher {
+	return &ConfigMapWatcher{client: c, name: name, levels: l, log: log, interval: interval}
+}
+
+// Start reading the ConfigMap. Start blocks until the supplied context is done.
+func (w *ConfigMapWatcher) Start(ctx context.Context) error {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+	for {
+		if err := w.sync(ctx); err != nil {
+			w.log.Info("Cannot update log levels", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// NeedLeaderElection returns false, because every replica of Crossplane should
+// update its log levels.
+func (w *ConfigMapWatcher) NeedLeaderElection() bool {
+	return false
+}
+
+func (w *ConfigMapWatcher) sync(ctx context.Context) error {
+	cm := &corev1.ConfigMap{}
+	if err := w.client.Get(ctx, w.name, cm); resource.IgnoreNotFound(err) != nil {
+		return errors.Wrap(err, errGetConfigMap)
+	}
+
+	var prefixes []string
+	for _, p := range strings.FieldsFunc(cm.Data[KeyDebug], func(r rune) bool { return r == ',' || r == '\n' }) {
+		if p = strings.TrimSpace(p); p != "" {
+			prefixes = append(prefixes, p)
+		}
+	}
+	w.levels.SetDebug(prefixes...)
+	return nil
+}
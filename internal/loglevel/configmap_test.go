@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loglevel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestConfigMapWatcherSync(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		prefixes []string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason   string
+		client   client.Reader
+		prefixes []string
+		want     want
+	}{
+		"ErrGetConfigMap": {
+			reason:   "We should return any error encountered getting the ConfigMap, and leave log levels unchanged.",
+			client:   &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			prefixes: []string{AllControllers},
+			want: want{
+				prefixes: []string{AllControllers},
+				err:      errors.Wrap(errBoom, errGetConfigMap),
+			},
+		},
+		"ConfigMapNotFound": {
+			reason:   "Debug logs should be disabled if the ConfigMap does not exist.",
+			client:   &test.MockClient{MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "crossplane-log-levels"))},
+			prefixes: []string{AllControllers},
+			want:     want{},
+		},
+		"Successful": {
+			reason: "Debug logs should be enabled for the comma or newline separated controllers listed in the ConfigMap.",
+			client: &test.MockClient{MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+				cm := obj.(*corev1.ConfigMap)
+				cm.Data = map[string]string{KeyDebug: "packages/providerrevision, packages/configurationrevision\ndefined/compositeresourcedefinition\n"}
+				return nil
+			})},
+			want: want{
+				prefixes: []string{"packages/providerrevision", "packages/configurationrevision", "defined/compositeresourcedefinition"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := NewLevels()
+			l.SetDebug(tc.prefixes...)
+			w := NewConfigMapWatcher(tc.client, types.NamespacedName{Namespace: "crossplane-system", Name: "crossplane-log-levels"}, l, logging.NewNopLogger(), 0)
+			err := w.sync(context.Background())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nw.sync(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.prefixes, l.prefixes, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("\n%s\nw.sync(...): -want prefixes, +got prefixes:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
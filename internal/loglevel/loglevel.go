@@ -0,0 +1,185 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loglevel allows the controllers that emit debug logs to be changed
+// while Crossplane is running.
+package loglevel
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+// keyController is the key that Crossplane's controllers use to add their name
+// to their logger's values.
+const keyController = "controller"
+
+// AllControllers may be supplied to SetDebug in order to enable debug logs
+// for all controllers.
+const AllControllers = "*"
+
+// AnnotationKeyTrace may be set to "true" on an object to enable debug logs
+// for its reconciles, regardless of which controllers emit debug logs.
+const AnnotationKeyTrace = "crossplane.io/trace"
+
+// TraceDuration is how long an object's reconciles emit debug logs once it is
+// first seen with the trace annotation. The annotation must be removed and
+// added again to trace the object for longer.
+const TraceDuration = 30 * time.Minute
+
+// Levels tracks which controllers should emit debug logs.
+type Levels struct {
+	mx       sync.RWMutex
+	prefixes []string
+
+	tmx    sync.Mutex
+	traced map[types.UID]trace
+	now    func() time.Time
+}
+
+// A trace records when an object was first and last seen with the trace
+// annotation.
+type trace struct {
+	started time.Time
+	seen    time.Time
+}
+
+// NewLevels returns Levels that do not enable debug logs for any controller.
+func NewLevels() *Levels {
+	return &Levels{traced: make(map[types.UID]trace), now: time.Now}
+}
+
+// SetDebug enables debug logs for any controller whose name starts with one of
+// the supplied prefixes, and disables them for all other controllers.
+func (l *Levels) SetDebug(prefixes ...string) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	l.prefixes = prefixes
+}
+
+// Debug returns true if the named controller should emit debug logs.
+func (l *Levels) Debug(controller string) bool {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+	for _, p := range l.prefixes {
+		if p == AllControllers {
+			return true
+		}
+		// Logs that are not emitted by a controller are only enabled by the
+		// wildcard.
+		if controller != "" && strings.HasPrefix(controller, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Tracing returns true if the supplied object's reconciles should emit debug
+// logs, because it requested tracing less than TraceDuration ago. Tracing
+// windows are tracked in memory, so they start again if Crossplane restarts.
+func (l *Levels) Tracing(o metav1.Object) bool {
+	l.tmx.Lock()
+	defer l.tmx.Unlock()
+
+	now := l.now()
+	l.evict(now)
+
+	if o.GetAnnotations()[AnnotationKeyTrace] != "true" {
+		delete(l.traced, o.GetUID())
+		return false
+	}
+	t, ok := l.traced[o.GetUID()]
+	if !ok {
+		t.started = now
+	}
+	t.seen = now
+	l.traced[o.GetUID()] = t
+	return now.Sub(t.started) < TraceDuration
+}
+
+// evict traces whose window has expired, and whose object hasn't been seen
+// for as long, for example because it was deleted. We remember expired traces of
+// objects we still see so that they aren't traced again until their annotation
+// is removed and added again.
+func (l *Levels) evict(now time.Time) {
+	for uid, t := range l.traced {
+		if now.Sub(t.started) >= TraceDuration && now.Sub(t.seen) > TraceDuration {
+			delete(l.traced, uid)
+		}
+	}
+}
+
+// A Logger emits debug logs only if its Levels enable debug logs for the
+// controller it belongs to, or for the object it is tracing. The supplied
+// logger must emit debug logs.
+type Logger struct {
+	log        logging.Logger
+	levels     *Levels
+	controller string
+	trace      bool
+}
+
+// NewLogger returns a Logger that emits debug logs only when they're enabled
+// by the supplied Levels.
+func NewLogger(log logging.Logger, l *Levels) *Logger {
+	return &Logger{log: log, levels: l}
+}
+
+// Info logs a message with optional structured data.
+func (l *Logger) Info(msg string, keysAndValues ...interface{}) {
+	l.log.Info(msg, keysAndValues...)
+}
+
+// Debug logs a message with optional structured data, if debug logs are
+// enabled for this logger's controller or object.
+func (l *Logger) Debug(msg string, keysAndValues ...interface{}) {
+	if !l.trace && !l.levels.Debug(l.controller) {
+		return
+	}
+	l.log.Debug(msg, keysAndValues...)
+}
+
+// WithValues returns a Logger that will include the supplied structured data
+// with any subsequent messages it logs. If the structured data names a
+// controller, the returned Logger belongs to that controller.
+func (l *Logger) WithValues(keysAndValues ...interface{}) logging.Logger {
+	c := l.controller
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		if k, ok := keysAndValues[i].(string); ok && k == keyController {
+			if v, ok := keysAndValues[i+1].(string); ok {
+				c = v
+			}
+		}
+	}
+	return &Logger{log: l.log.WithValues(keysAndValues...), levels: l.levels, controller: c, trace: l.trace}
+}
+
+// ForObject returns a logger that emits debug logs if the supplied object is
+// being traced. The supplied logger is returned unchanged if the object isn't
+// being traced, or if the logger isn't a *Logger.
+func ForObject(log logging.Logger, o metav1.Object) logging.Logger {
+	l, ok := log.(*Logger)
+	if !ok || !l.levels.Tracing(o) {
+		return log
+	}
+	return &Logger{log: l.log.WithValues("trace", true), levels: l.levels, controller: l.controller, trace: true}
+}
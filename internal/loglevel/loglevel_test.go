@@ -0,0 +1,296 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loglevel
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+)
+
+var _ logging.Logger = &Logger{}
+
+func TestLevelsDebug(t *testing.T) {
+	type args struct {
+		prefixes   []string
+		controller string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   bool
+	}{
+		"NoPrefixes": {
+			reason: "Debug logs should be disabled when no prefixes are set.",
+			args:   args{controller: "packages/providerrevision.pkg.crossplane.io"},
+			want:   false,
+		},
+		"MatchingPrefix": {
+			reason: "Debug logs should be enabled for controllers whose name starts with a prefix.",
+			args: args{
+				prefixes:   []string{"defined/compositeresourcedefinition", "packages/providerrevision"},
+				controller: "packages/providerrevision.pkg.crossplane.io",
+			},
+			want: true,
+		},
+		"NoMatchingPrefix": {
+			reason: "Debug logs should be disabled for controllers whose name does not start with a prefix.",
+			args: args{
+				prefixes:   []string{"packages/providerrevision"},
+				controller: "packages/configurationrevision.pkg.crossplane.io",
+			},
+			want: false,
+		},
+		"NoController": {
+			reason: "Debug logs should be disabled for loggers that don't belong to a controller unless all are enabled.",
+			args: args{
+				prefixes: []string{"packages/providerrevision"},
+			},
+			want: false,
+		},
+		"AllControllers": {
+			reason: "Debug logs should be enabled for all loggers when the wildcard is set.",
+			args: args{
+				prefixes: []string{AllControllers},
+			},
+			want: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := NewLevels()
+			l.SetDebug(tc.args.prefixes...)
+			got := l.Debug(tc.args.controller)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nl.Debug(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+type message struct {
+	level         string
+	msg           string
+	keysAndValues []interface{}
+}
+
+type recorder struct {
+	messages *[]message
+	values   []interface{}
+}
+
+func (r recorder) Info(msg string, keysAndValues ...interface{}) {
+	*r.messages = append(*r.messages, message{level: "info", msg: msg, keysAndValues: append(r.values, keysAndValues...)})
+}
+
+func (r recorder) Debug(msg string, keysAndValues ...interface{}) {
+	*r.messages = append(*r.messages, message{level: "debug", msg: msg, keysAndValues: append(r.values, keysAndValues...)})
+}
+
+func (r recorder) WithValues(keysAndValues ...interface{}) logging.Logger {
+	return recorder{messages: r.messages, values: append(r.values, keysAndValues...)}
+}
+
+func TestLogger(t *testing.T) {
+	type args struct {
+		prefixes []string
+		values   []interface{}
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   []message
+	}{
+		"DebugDisabled": {
+			reason: "Only info logs should be emitted when debug logs are not enabled for a controller.",
+			args: args{
+				values: []interface{}{"controller", "packages/providerrevision.pkg.crossplane.io"},
+			},
+			want: []message{
+				{level: "info", msg: "info", keysAndValues: []interface{}{"controller", "packages/providerrevision.pkg.crossplane.io"}},
+			},
+		},
+		"DebugEnabled": {
+			reason: "Info and debug logs should be emitted when debug logs are enabled for a controller.",
+			args: args{
+				prefixes: []string{"packages/providerrevision"},
+				values:   []interface{}{"controller", "packages/providerrevision.pkg.crossplane.io"},
+			},
+			want: []message{
+				{level: "info", msg: "info", keysAndValues: []interface{}{"controller", "packages/providerrevision.pkg.crossplane.io"}},
+				{level: "debug", msg: "debug", keysAndValues: []interface{}{"controller", "packages/providerrevision.pkg.crossplane.io"}},
+			},
+		},
+		"DebugEnabledForOtherController": {
+			reason: "Only info logs should be emitted when debug logs are enabled for a different controller.",
+			args: args{
+				prefixes: []string{"packages/providerrevision"},
+				values:   []interface{}{"controller", "packages/configurationrevision.pkg.crossplane.io"},
+			},
+			want: []message{
+				{level: "info", msg: "info", keysAndValues: []interface{}{"controller", "packages/configurationrevision.pkg.crossplane.io"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := []message{}
+			l := NewLevels()
+			l.SetDebug(tc.args.prefixes...)
+			log := NewLogger(recorder{messages: &got}, l).WithValues(tc.args.values...)
+			log.Info("info")
+			log.Debug("debug")
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(message{})); diff != "" {
+				t.Errorf("\n%s\nLogger: -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLevelsTracing(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	traced := &metav1.ObjectMeta{UID: "cool", Annotations: map[string]string{AnnotationKeyTrace: "true"}}
+	untraced := &metav1.ObjectMeta{UID: "cool"}
+
+	type step struct {
+		o    metav1.Object
+		at   time.Duration
+		want bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		steps  []step
+	}{
+		"NotAnnotated": {
+			reason: "An object without the trace annotation should not be traced.",
+			steps:  []step{{o: untraced, want: false}},
+		},
+		"WithinDuration": {
+			reason: "An annotated object should be traced until the trace duration has passed since it was first seen.",
+			steps: []step{
+				{o: traced, want: true},
+				{o: traced, at: TraceDuration - time.Second, want: true},
+			},
+		},
+		"Expired": {
+			reason: "An annotated object should not be traced once the trace duration has passed since it was first seen.",
+			steps: []step{
+				{o: traced, want: true},
+				{o: traced, at: TraceDuration, want: false},
+			},
+		},
+		"Reannotated": {
+			reason: "An object should be traced again if its trace annotation is removed and added again.",
+			steps: []step{
+				{o: traced, want: true},
+				{o: traced, at: TraceDuration, want: false},
+				{o: untraced, at: TraceDuration, want: false},
+				{o: traced, at: TraceDuration, want: true},
+			},
+		},
+		"StillExpired": {
+			reason: "An annotated object that is still seen should not be traced again once its trace duration has passed.",
+			steps: []step{
+				{o: traced, want: true},
+				{o: traced, at: TraceDuration, want: false},
+				{o: traced, at: 2 * TraceDuration, want: false},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := NewLevels()
+			for i, s := range tc.steps {
+				l.now = func() time.Time { return start.Add(s.at) }
+				if got := l.Tracing(s.o); got != s.want {
+					t.Errorf("\n%s\nl.Tracing(...) at step %d: want %t, got %t", tc.reason, i, s.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLevelsTracingEvict(t *testing.T) {
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	deleted := &metav1.ObjectMeta{UID: "deleted", Annotations: map[string]string{AnnotationKeyTrace: "true"}}
+	other := &metav1.ObjectMeta{UID: "other"}
+
+	l := NewLevels()
+	l.now = func() time.Time { return start }
+	l.Tracing(deleted)
+
+	// The deleted object is never seen again, so its trace should be evicted
+	// once its window has expired and it has gone unseen for as long.
+	l.now = func() time.Time { return start.Add(TraceDuration) }
+	l.Tracing(other)
+	if _, ok := l.traced[deleted.GetUID()]; !ok {
+		t.Errorf("l.Tracing(...): want trace of recently seen object to be kept")
+	}
+	l.now = func() time.Time { return start.Add(2 * TraceDuration) }
+	l.Tracing(other)
+	if _, ok := l.traced[deleted.GetUID()]; ok {
+		t.Errorf("l.Tracing(...): want expired trace of unseen object to be evicted")
+	}
+}
+
+func TestForObject(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		o      metav1.Object
+		want   []message
+	}{
+		"NotTraced": {
+			reason: "Only info logs should be emitted for an object that isn't being traced.",
+			o:      &metav1.ObjectMeta{UID: "cool"},
+			want: []message{
+				{level: "info", msg: "info", keysAndValues: []interface{}{"controller", "composite/xdatabases.example.org", "request", "cool"}},
+			},
+		},
+		"Traced": {
+			reason: "Info and debug logs should be emitted for an object that is being traced.",
+			o:      &metav1.ObjectMeta{UID: "cool", Annotations: map[string]string{AnnotationKeyTrace: "true"}},
+			want: []message{
+				{level: "info", msg: "info", keysAndValues: []interface{}{"controller", "composite/xdatabases.example.org", "trace", true, "request", "cool"}},
+				{level: "debug", msg: "debug", keysAndValues: []interface{}{"controller", "composite/xdatabases.example.org", "trace", true, "request", "cool"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := []message{}
+			log := NewLogger(recorder{messages: &got}, NewLevels()).WithValues("controller", "composite/xdatabases.example.org")
+			// Values added after tracing starts should not stop tracing.
+			log = ForObject(log, tc.o).WithValues("request", "cool")
+			log.Info("info")
+			log.Debug("debug")
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(message{})); diff != "" {
+				t.Errorf("\n%s\nForObject(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
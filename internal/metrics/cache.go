@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	cacheUsage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "crossplane",
+		Subsystem: "package",
+		Name:      "cache_usage_bytes",
+		Help:      "Size of the compressed contents cached for a package. Only recorded when the package cache is scoped per package.",
+	}, []string{"package"})
+
+	cacheCorruptions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "crossplane",
+		Subsystem: "package",
+		Name:      "cache_corruptions_detected_total",
+		Help:      "Number of times cached package contents were found not to match the digest they were cached with. Only recorded when cache corruption detection is enabled. Tampering is not detected.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(cacheUsage, cacheCorruptions)
+}
+
+// A CacheRecorder records metrics about the package cache.
+type CacheRecorder interface {
+	// RecordCacheUsage records the size in bytes of the contents cached for
+	// the supplied package.
+	RecordCacheUsage(pkg string, bytes int64)
+
+	// RecordCacheCorruption records that cached contents were found to be
+	// corrupt when read.
+	RecordCacheCorruption()
+}
+
+// A NopCacheRecorder does nothing.
+type NopCacheRecorder struct{}
+
+// NewNopCacheRecorder returns a CacheRecorder that does nothing.
+func NewNopCacheRecorder() NopCacheRecorder { return NopCacheRecorder{} }
+
+// RecordCacheUsage does nothing.
+func (NopCacheRecorder) RecordCacheUsage(_ string, _ int64) {}
+
+// RecordCacheCorruption does nothing.
+func (NopCacheRecorder) RecordCacheCorruption() {}
+
+// A PrometheusCacheRecorder records metrics using Prometheus. Metrics are
+// served by the controller-runtime metrics server.
+type PrometheusCacheRecorder struct{}
+
+// NewPrometheusCacheRecorder returns a CacheRecorder that records Prometheus
+// metrics.
+func NewPrometheusCacheRecorder() *PrometheusCacheRecorder {
+	return &PrometheusCacheRecorder{}
+}
+
+// RecordCacheUsage records the size in bytes of the contents cached for the
+// supplied package. A package with nothing cached is no longer reported.
+func (r *PrometheusCacheRecorder) RecordCacheUsage(pkg string, bytes int64) {
+	if bytes == 0 {
+		cacheUsage.DeleteLabelValues(pkg)
+		return
+	}
+	cacheUsage.WithLabelValues(pkg).Set(float64(bytes))
+}
+
+// RecordCacheCorruption records that cached contents were found to be corrupt
+// when read.
+func (r *PrometheusCacheRecorder) RecordCacheCorruption() {
+	cacheCorruptions.Inc()
+}
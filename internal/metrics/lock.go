@@ -0,0 +1,67 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	lockConflicts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "crossplane",
+		Subsystem: "package",
+		Name:      "lock_update_conflicts_total",
+		Help:      "Number of times a package revision could not update the package Lock because it was updated by someone else first.",
+	}, []string{"type"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(lockConflicts)
+}
+
+// A LockRecorder records metrics about the package Lock.
+type LockRecorder interface {
+	// RecordLockConflict records that a revision of the supplied package
+	// type could not update the Lock because it was modified concurrently.
+	RecordLockConflict(packageType string)
+}
+
+// A NopLockRecorder does nothing.
+type NopLockRecorder struct{}
+
+// NewNopLockRecorder returns a LockRecorder that does nothing.
+func NewNopLockRecorder() NopLockRecorder { return NopLockRecorder{} }
+
+// RecordLockConflict does nothing.
+func (NopLockRecorder) RecordLockConflict(_ string) {}
+
+// A PrometheusLockRecorder records metrics using Prometheus. Metrics are
+// served by the controller-runtime metrics server.
+type PrometheusLockRecorder struct{}
+
+// NewPrometheusLockRecorder returns a LockRecorder that records Prometheus
+// metrics.
+func NewPrometheusLockRecorder() *PrometheusLockRecorder {
+	return &PrometheusLockRecorder{}
+}
+
+// RecordLockConflict records that a revision of the supplied package type could
+// not update the Lock because it was modified concurrently.
+func (r *PrometheusLockRecorder) RecordLockConflict(packageType string) {
+	lockConflicts.WithLabelValues(packageType).Inc()
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics contains Prometheus metrics about composite resources,
+// claims, the runtimes of provider packages, and the RBAC manager.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Reasons a composite resource may fail to render.
+const (
+	RenderFailureInvalidComposition = "InvalidComposition"
+	RenderFailurePatchSets          = "PatchSets"
+	RenderFailureComposedResource   = "ComposedResource"
+	RenderFailureCompositeResource  = "CompositeResource"
+	RenderFailureLimitExceeded      = "LimitExceeded"
+	RenderFailureSecretPatch        = "SecretPatch"
+)
+
+const labelXRD = "xrd"
+
+var (
+	claimReadySeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "crossplane",
+		Subsystem: "claim",
+		Name:      "ready_seconds",
+		Help:      "Time from when a claim was created to when it first became ready.",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{labelXRD})
+
+	composedResources = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "crossplane",
+		Subsystem: "composite",
+		Name:      "composed_resources",
+		Help:      "Number of resources composed by a composite resource, observed each time it is composed.",
+		Buckets:   []float64{1, 2, 5, 10, 20, 50, 100},
+	}, []string{labelXRD})
+
+	driftCorrections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "crossplane",
+		Subsystem: "composite",
+		Name:      "drift_corrections_total",
+		Help:      "Number of times an existing composed resource was updated to match its desired state.",
+	}, []string{labelXRD})
+
+	renderFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "crossplane",
+		Subsystem: "composite",
+		Name:      "render_failures_total",
+		Help:      "Number of times a composite resource or one of its composed resources could not be rendered.",
+	}, []string{labelXRD, "reason"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(claimReadySeconds, composedResources, driftCorrections, renderFailures)
+}
+
+// A Recorder records metrics about the composite resources and claims of a
+// particular CompositeResourceDefinition.
+type Recorder interface {
+	// RecordClaimReady records how long a claim took to first become ready.
+	RecordClaimReady(d time.Duration)
+
+	// RecordComposedResources records how many resources a composite
+	// resource composes.
+	RecordComposedResources(n int)
+
+	// RecordDriftCorrection records that an existing composed resource was
+	// updated to match its desired state.
+	RecordDriftCorrection()
+
+	// RecordRenderFailure records that a composite resource could not be
+	// fully rendered for the supplied reason.
+	RecordRenderFailure(reason string)
+}
+
+// A NopRecorder does nothing.
+type NopRecorder struct{}
+
+// NewNopRecorder returns a Recorder that does nothing.
+func NewNopRecorder() NopRecorder { return NopRecorder{} }
+
+// RecordClaimReady does nothing.
+func (NopRecorder) RecordClaimReady(_ time.Duration) {}
+
+// RecordComposedResources does nothing.
+func (NopRecorder) RecordComposedResources(_ int) {}
+
+// RecordDriftCorrection does nothing.
+func (NopRecorder) RecordDriftCorrection() {}
+
+// RecordRenderFailure does nothing.
+func (NopRecorder) RecordRenderFailure(_ string) {}
+
+// A PrometheusRecorder records metrics using Prometheus. Metrics are served by
+// the controller-runtime metrics server.
+type PrometheusRecorder struct {
+	xrd string
+}
+
+// NewPrometheusRecorder returns a Recorder that records Prometheus metrics
+// labeled with the supplied CompositeResourceDefinition name.
+func NewPrometheusRecorder(xrd string) *PrometheusRecorder {
+	return &PrometheusRecorder{xrd: xrd}
+}
+
+// RecordClaimReady records how long a claim took to first become ready.
+func (r *PrometheusRecorder) RecordClaimReady(d time.Duration) {
+	claimReadySeconds.WithLabelValues(r.xrd).Observe(d.Seconds())
+}
+
+// RecordComposedResources records how many resources a composite resource
+// composes.
+func (r *PrometheusRecorder) RecordComposedResources(n int) {
+	composedResources.WithLabelValues(r.xrd).Observe(float64(n))
+}
+
+// RecordDriftCorrection records that an existing composed resource was updated
+// to match its desired state.
+func (r *PrometheusRecorder) RecordDriftCorrection() {
+	driftCorrections.WithLabelValues(r.xrd).Inc()
+}
+
+// RecordRenderFailure records that a composite resource could not be fully
+// rendered for the supplied reason.
+func (r *PrometheusRecorder) RecordRenderFailure(reason string) {
+	renderFailures.WithLabelValues(r.xrd, reason).Inc()
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestPrometheusRecorder(t *testing.T) {
+	xrd := "xdatabases.example.org"
+	r := NewPrometheusRecorder(xrd)
+
+	r.RecordClaimReady(30 * time.Second)
+	r.RecordComposedResources(3)
+	r.RecordDriftCorrection()
+	r.RecordDriftCorrection()
+	r.RecordRenderFailure(RenderFailureComposedResource)
+
+	// Metrics recorded for other XRDs should not be counted.
+	NewPrometheusRecorder("xcaches.example.org").RecordDriftCorrection()
+
+	if diff := cmp.Diff(1, testutil.CollectAndCount(claimReadySeconds)); diff != "" {
+		t.Errorf("RecordClaimReady(...): -want series, +got series:\n%s", diff)
+	}
+	if diff := cmp.Diff(1, testutil.CollectAndCount(composedResources)); diff != "" {
+		t.Errorf("RecordComposedResources(...): -want series, +got series:\n%s", diff)
+	}
+	if diff := cmp.Diff(float64(2), testutil.ToFloat64(driftCorrections.WithLabelValues(xrd))); diff != "" {
+		t.Errorf("RecordDriftCorrection(): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(float64(1), testutil.ToFloat64(renderFailures.WithLabelValues(xrd, RenderFailureComposedResource))); diff != "" {
+		t.Errorf("RecordRenderFailure(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestPrometheusRuntimeRecorder(t *testing.T) {
+	r := NewPrometheusRuntimeRecorder()
+
+	r.RecordRuntimeRepair("provider-nop", "Deployment")
+	r.RecordRuntimeRepair("provider-nop", "Deployment")
+	r.RecordRuntimeRepair("provider-nop", "ServiceAccount")
+
+	if diff := cmp.Diff(float64(2), testutil.ToFloat64(runtimeRepairs.WithLabelValues("provider-nop", "Deployment"))); diff != "" {
+		t.Errorf("RecordRuntimeRepair(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(2, testutil.CollectAndCount(runtimeRepairs)); diff != "" {
+		t.Errorf("RecordRuntimeRepair(...): -want series, +got series:\n%s", diff)
+	}
+}
+
+func TestPrometheusRBACRecorder(t *testing.T) {
+	r := NewPrometheusRBACRecorder()
+
+	r.RecordApply("ClusterRole")
+	r.RecordApply("ClusterRole")
+	r.RecordApplyError("ClusterRoleBinding")
+
+	if diff := cmp.Diff(float64(2), testutil.ToFloat64(rbacApplies.WithLabelValues("ClusterRole"))); diff != "" {
+		t.Errorf("RecordApply(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(float64(1), testutil.ToFloat64(rbacApplyErrors.WithLabelValues("ClusterRoleBinding"))); diff != "" {
+		t.Errorf("RecordApplyError(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestPrometheusLockRecorder(t *testing.T) {
+	r := NewPrometheusLockRecorder()
+
+	r.RecordLockConflict("Provider")
+	r.RecordLockConflict("Provider")
+	r.RecordLockConflict("Configuration")
+
+	if diff := cmp.Diff(float64(2), testutil.ToFloat64(lockConflicts.WithLabelValues("Provider"))); diff != "" {
+		t.Errorf("RecordLockConflict(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(2, testutil.CollectAndCount(lockConflicts)); diff != "" {
+		t.Errorf("RecordLockConflict(...): -want series, +got series:\n%s", diff)
+	}
+}
+
+func TestPrometheusCacheRecorder(t *testing.T) {
+	r := NewPrometheusCacheRecorder()
+
+	r.RecordCacheUsage("provider-aws", 1024)
+	r.RecordCacheUsage("provider-gcp", 2048)
+	r.RecordCacheUsage("provider-gcp", 0)
+
+	if diff := cmp.Diff(float64(1024), testutil.ToFloat64(cacheUsage.WithLabelValues("provider-aws"))); diff != "" {
+		t.Errorf("RecordCacheUsage(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(1, testutil.CollectAndCount(cacheUsage)); diff != "" {
+		t.Errorf("RecordCacheUsage(...): -want series, +got series:\n%s", diff)
+	}
+}
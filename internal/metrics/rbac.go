@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	rbacApplies = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "crossplane",
+		Subsystem: "rbac",
+		Name:      "applies_total",
+		Help:      "Number of times the RBAC manager created or updated a role or binding, or would have in dry-run mode.",
+	}, []string{"kind"})
+
+	rbacApplyErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "crossplane",
+		Subsystem: "rbac",
+		Name:      "apply_errors_total",
+		Help:      "Number of times the RBAC manager failed to create or update a role or binding.",
+	}, []string{"kind"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(rbacApplies, rbacApplyErrors)
+}
+
+// An RBACRecorder records metrics about the roles and bindings managed by the
+// RBAC manager.
+type RBACRecorder interface {
+	// RecordApply records that a role or binding of the supplied kind was
+	// created or updated.
+	RecordApply(kind string)
+
+	// RecordApplyError records that a role or binding of the supplied kind
+	// could not be created or updated.
+	RecordApplyError(kind string)
+}
+
+// A NopRBACRecorder does nothing.
+type NopRBACRecorder struct{}
+
+// NewNopRBACRecorder returns an RBACRecorder that does nothing.
+func NewNopRBACRecorder() NopRBACRecorder { return NopRBACRecorder{} }
+
+// RecordApply does nothing.
+func (NopRBACRecorder) RecordApply(_ string) {}
+
+// RecordApplyError does nothing.
+func (NopRBACRecorder) RecordApplyError(_ string) {}
+
+// A PrometheusRBACRecorder records metrics using Prometheus. Metrics are
+// served by the controller-runtime metrics server.
+type PrometheusRBACRecorder struct{}
+
+// NewPrometheusRBACRecorder returns an RBACRecorder that records Prometheus
+// metrics.
+func NewPrometheusRBACRecorder() *PrometheusRBACRecorder {
+	return &PrometheusRBACRecorder{}
+}
+
+// RecordApply records that a role or binding of the supplied kind was created
+// or updated.
+func (r *PrometheusRBACRecorder) RecordApply(kind string) {
+	rbacApplies.WithLabelValues(kind).Inc()
+}
+
+// RecordApplyError records that a role or binding of the supplied kind could
+// not be created or updated.
+func (r *PrometheusRBACRecorder) RecordApplyError(kind string) {
+	rbacApplyErrors.WithLabelValues(kind).Inc()
+}
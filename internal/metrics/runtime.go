@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	runtimeRepairs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "crossplane",
+		Subsystem: "provider",
+		Name:      "runtime_repairs_total",
+		Help:      "Number of times a provider's runtime resources were updated or recreated to match their desired state.",
+	}, []string{"provider", "kind"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(runtimeRepairs)
+}
+
+// A RuntimeRecorder records metrics about the runtimes of provider packages,
+// i.e. their Deployments, ServiceAccounts, and Services.
+type RuntimeRecorder interface {
+	// RecordRuntimeRepair records that a runtime resource of the supplied
+	// kind was updated or recreated to match its desired state.
+	RecordRuntimeRepair(provider, kind string)
+}
+
+// A NopRuntimeRecorder does nothing.
+type NopRuntimeRecorder struct{}
+
+// NewNopRuntimeRecorder returns a RuntimeRecorder that does nothing.
+func NewNopRuntimeRecorder() NopRuntimeRecorder { return NopRuntimeRecorder{} }
+
+// RecordRuntimeRepair does nothing.
+func (NopRuntimeRecorder) RecordRuntimeRepair(_, _ string) {}
+
+// A PrometheusRuntimeRecorder records metrics using Prometheus. Metrics are
+// served by the controller-runtime metrics server.
+type PrometheusRuntimeRecorder struct{}
+
+// NewPrometheusRuntimeRecorder returns a RuntimeRecorder that records
+// Prometheus metrics.
+func NewPrometheusRuntimeRecorder() *PrometheusRuntimeRecorder {
+	return &PrometheusRuntimeRecorder{}
+}
+
+// RecordRuntimeRepair records that a runtime resource of the supplied kind was
+// updated or recreated to match its desired state.
+func (r *PrometheusRuntimeRecorder) RecordRuntimeRepair(provider, kind string) {
+	runtimeRepairs.WithLabelValues(provider, kind).Inc()
+}
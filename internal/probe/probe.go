@@ -0,0 +1,175 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package probe contains the checks served by Crossplane's liveness and
+// readiness probe endpoints.
+package probe
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errGather         = "cannot gather metrics"
+	errFmtStalled     = "workqueues of controllers have been processing an item for longer than %s: %s"
+	errFmtNotStarted  = "controllers have not started: %s"
+	errStopped        = "controllers have stopped"
+	errFmtUnsupported = "unsupported metric type for %s"
+)
+
+// Metrics exposed by controller-runtime that the checks read.
+const (
+	metricLongestRunningProcessor = "workqueue_longest_running_processor_seconds"
+	metricMaxConcurrentReconciles = "controller_runtime_max_concurrent_reconciles"
+
+	labelWorkqueue  = "name"
+	labelController = "controller"
+)
+
+// StalledWorkqueues returns a check that fails when the workqueue of any
+// controller has been processing the same item for longer than the supplied
+// threshold. A controller whose workers are all wedged makes no progress, but
+// would otherwise be considered healthy.
+func StalledWorkqueues(g prometheus.Gatherer, threshold time.Duration) healthz.Checker {
+	return func(_ *http.Request) error {
+		running, err := gauges(g, metricLongestRunningProcessor, labelWorkqueue)
+		if err != nil {
+			return err
+		}
+		stalled := make([]string, 0)
+		for name, seconds := range running {
+			if seconds > threshold.Seconds() {
+				stalled = append(stalled, name)
+			}
+		}
+		if len(stalled) == 0 {
+			return nil
+		}
+		sort.Strings(stalled)
+		return errors.Errorf(errFmtStalled, threshold, strings.Join(stalled, ", "))
+	}
+}
+
+// A Leader tracks whether a manager is running the controllers it starts once
+// it's elected leader. It must be added to the manager. Controllers don't
+// report when they stop, so a Leader reports that they have stopped as soon as
+// the manager begins to stop them.
+type Leader struct {
+	mx      sync.RWMutex
+	elected bool
+	stopped bool
+}
+
+// NewLeader returns a new Leader.
+func NewLeader() *Leader {
+	return &Leader{}
+}
+
+// Start tracks that the manager has been elected leader, until the supplied
+// context is done.
+func (l *Leader) Start(ctx context.Context) error {
+	l.mx.Lock()
+	l.elected = true
+	l.mx.Unlock()
+
+	<-ctx.Done()
+
+	l.mx.Lock()
+	l.stopped = true
+	l.mx.Unlock()
+	return nil
+}
+
+// NeedLeaderElection returns true, because a Leader must start with the
+// controllers that need leader election.
+func (l *Leader) NeedLeaderElection() bool {
+	return true
+}
+
+func (l *Leader) state() (elected, stopped bool) {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+	return l.elected, l.stopped
+}
+
+// ControllersRunning returns a check that fails unless each of the supplied
+// controllers is running. Controllers are started only once they're elected
+// leader, so the check passes until the supplied Leader is elected.
+func ControllersRunning(l *Leader, g prometheus.Gatherer, names ...string) healthz.Checker {
+	return func(_ *http.Request) error {
+		elected, stopped := l.state()
+		if !elected {
+			return nil
+		}
+		if stopped {
+			return errors.New(errStopped)
+		}
+
+		// controller-runtime sets this gauge when a controller starts,
+		// but never clears it. We rely on the Leader to tell us when
+		// controllers stop.
+		started, err := gauges(g, metricMaxConcurrentReconciles, labelController)
+		if err != nil {
+			return err
+		}
+		missing := make([]string, 0)
+		for _, n := range names {
+			if _, ok := started[n]; !ok {
+				missing = append(missing, n)
+			}
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+		return errors.Errorf(errFmtNotStarted, strings.Join(missing, ", "))
+	}
+}
+
+// gauges returns the values of the supplied gauge, keyed by the value of the
+// supplied label.
+func gauges(g prometheus.Gatherer, metric, label string) (map[string]float64, error) {
+	mfs, err := g.Gather()
+	if err != nil {
+		return nil, errors.Wrap(err, errGather)
+	}
+	values := map[string]float64{}
+	for _, mf := range mfs {
+		if mf.GetName() != metric {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			if m.GetGauge() == nil {
+				return nil, errors.Errorf(errFmtUnsupported, metric)
+			}
+			for _, l := range m.GetLabel() {
+				if l.GetName() == label {
+					values[l.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return values, nil
+}
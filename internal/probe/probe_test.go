@@ -0,0 +1,181 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+// registry returns a registry with the supplied values of the supplied gauge,
+// keyed by the value of the supplied label.
+func registry(metric, label string, values map[string]float64) *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: metric}, []string{label})
+	for l, v := range values {
+		g.WithLabelValues(l).Set(v)
+	}
+	r.MustRegister(g)
+	return r
+}
+
+var errBoom = errors.New("boom")
+
+func TestStalledWorkqueues(t *testing.T) {
+	threshold := 10 * time.Minute
+
+	cases := map[string]struct {
+		reason string
+		g      prometheus.Gatherer
+		want   error
+	}{
+		"GatherError": {
+			reason: "We should return any error encountered gathering metrics.",
+			g: prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+				return nil, errBoom
+			}),
+			want: errors.Wrap(errBoom, errGather),
+		},
+		"NoWorkqueues": {
+			reason: "We should pass if there are no workqueues, for example because we're not the leader.",
+			g:      prometheus.NewRegistry(),
+		},
+		"NotStalled": {
+			reason: "We should pass if no workqueue has been processing an item for longer than the threshold.",
+			g:      registry(metricLongestRunningProcessor, labelWorkqueue, map[string]float64{"a": 0, "b": 599}),
+		},
+		"Stalled": {
+			reason: "We should fail if a workqueue has been processing an item for longer than the threshold.",
+			g:      registry(metricLongestRunningProcessor, labelWorkqueue, map[string]float64{"c": 601, "a": 0, "b": 900}),
+			want:   errors.Errorf(errFmtStalled, threshold, "b, c"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := StalledWorkqueues(tc.g, threshold)(nil)
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nStalledWorkqueues(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestControllersRunning(t *testing.T) {
+	elected := &Leader{elected: true}
+
+	type args struct {
+		l     *Leader
+		g     prometheus.Gatherer
+		names []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"NotElected": {
+			reason: "We should pass if we haven't been elected leader, because our controllers aren't started until we are.",
+			args: args{
+				l:     NewLeader(),
+				g:     prometheus.NewRegistry(),
+				names: []string{"a"},
+			},
+		},
+		"Stopped": {
+			reason: "We should fail once we've begun to stop our controllers, even though they were started.",
+			args: args{
+				l:     &Leader{elected: true, stopped: true},
+				g:     registry(metricMaxConcurrentReconciles, labelController, map[string]float64{"a": 10}),
+				names: []string{"a"},
+			},
+			want: errors.New(errStopped),
+		},
+		"GatherError": {
+			reason: "We should return any error encountered gathering metrics.",
+			args: args{
+				l: elected,
+				g: prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+					return nil, errBoom
+				}),
+			},
+			want: errors.Wrap(errBoom, errGather),
+		},
+		"Started": {
+			reason: "We should pass if all of the supplied controllers have started.",
+			args: args{
+				l:     elected,
+				g:     registry(metricMaxConcurrentReconciles, labelController, map[string]float64{"a": 10, "b": 10, "c": 10}),
+				names: []string{"a", "b"},
+			},
+		},
+		"NotStarted": {
+			reason: "We should fail if any of the supplied controllers have not started.",
+			args: args{
+				l:     elected,
+				g:     registry(metricMaxConcurrentReconciles, labelController, map[string]float64{"b": 10}),
+				names: []string{"a", "b", "c"},
+			},
+			want: errors.Errorf(errFmtNotStarted, "a, c"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ControllersRunning(tc.args.l, tc.args.g, tc.args.names...)(nil)
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nControllersRunning(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLeader(t *testing.T) {
+	l := NewLeader()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = l.Start(ctx)
+		close(done)
+	}()
+
+	// Start tracks election before it blocks, so wait for it to do so.
+	for {
+		if elected, _ := l.state(); elected {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, stopped := l.state(); stopped {
+		t.Errorf("l.state(...): want not stopped before the context is done")
+	}
+
+	cancel()
+	<-done
+	if _, stopped := l.state(); !stopped {
+		t.Errorf("l.state(...): want stopped once the context is done")
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package profile serves and captures pprof profiles of Crossplane.
+package profile
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/pprof"
+	"path/filepath"
+	rpprof "runtime/pprof"
+	"time"
+
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errWriteHeapProfile = "cannot write heap profile"
+	errStartCPUProfile  = "cannot start CPU profile"
+	errMkdir            = "cannot create profile directory"
+	errWriteProfile     = "cannot write profile"
+)
+
+// Names of captured profiles.
+const (
+	NameHeap = "heap.pprof"
+	NameCPU  = "cpu.pprof"
+)
+
+// PathPrefix is the path at which pprof endpoints are served.
+const PathPrefix = "/debug/pprof/"
+
+// A HandlerAdder adds a handler at the supplied path. It is satisfied by a
+// controller-runtime manager's AddMetricsExtraHandler method.
+type HandlerAdder func(path string, h http.Handler) error
+
+// AddHandlers adds pprof's handlers under PathPrefix.
+func AddHandlers(add HandlerAdder) error {
+	handlers := map[string]http.Handler{
+		PathPrefix:             http.HandlerFunc(pprof.Index),
+		PathPrefix + "cmdline": http.HandlerFunc(pprof.Cmdline),
+		PathPrefix + "profile": http.HandlerFunc(pprof.Profile),
+		PathPrefix + "symbol":  http.HandlerFunc(pprof.Symbol),
+		PathPrefix + "trace":   http.HandlerFunc(pprof.Trace),
+	}
+	for path, h := range handlers {
+		if err := add(path, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Profiles are captured pprof profiles, keyed by name.
+type Profiles map[string][]byte
+
+// A Profiler captures profiles.
+type Profiler interface {
+	// Profile captures a heap profile, and a CPU profile of the supplied
+	// duration.
+	Profile(ctx context.Context, cpu time.Duration) (Profiles, error)
+}
+
+// A ProfilerFn is a function that satisfies Profiler.
+type ProfilerFn func(ctx context.Context, cpu time.Duration) (Profiles, error)
+
+// Profile captures a heap profile, and a CPU profile of the supplied duration.
+func (fn ProfilerFn) Profile(ctx context.Context, cpu time.Duration) (Profiles, error) {
+	return fn(ctx, cpu)
+}
+
+// A RuntimeProfiler profiles the running process.
+type RuntimeProfiler struct{}
+
+// NewRuntimeProfiler returns a Profiler that profiles the running process.
+func NewRuntimeProfiler() *RuntimeProfiler {
+	return &RuntimeProfiler{}
+}
+
+// Profile captures a heap profile, and a CPU profile of the supplied duration.
+// Only one CPU profile may be captured at a time, so Profile returns an error
+// if a CPU profile is already being captured, for example via the pprof
+// endpoints. Profile returns early if the supplied context is done while the
+// CPU profile is being captured.
+func (p *RuntimeProfiler) Profile(ctx context.Context, cpu time.Duration) (Profiles, error) {
+	heap := &bytes.Buffer{}
+	if err := rpprof.Lookup("heap").WriteTo(heap, 0); err != nil {
+		return nil, errors.Wrap(err, errWriteHeapProfile)
+	}
+
+	prof := &bytes.Buffer{}
+	if err := rpprof.StartCPUProfile(prof); err != nil {
+		return nil, errors.Wrap(err, errStartCPUProfile)
+	}
+	t := time.NewTimer(cpu)
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+	t.Stop()
+	rpprof.StopCPUProfile()
+
+	return Profiles{NameHeap: heap.Bytes(), NameCPU: prof.Bytes()}, nil
+}
+
+// A Store stores captured profiles.
+type Store interface {
+	// Store the supplied profiles, which were captured in response to the
+	// supplied capture request ID.
+	Store(ctx context.Context, id string, p Profiles) error
+}
+
+// A StoreFn is a function that satisfies Store.
+type StoreFn func(ctx context.Context, id string, p Profiles) error
+
+// Store the supplied profiles.
+func (fn StoreFn) Store(ctx context.Context, id string, p Profiles) error {
+	return fn(ctx, id, p)
+}
+
+// A FsStore stores profiles in a filesystem, for example a mounted
+// PersistentVolumeClaim.
+type FsStore struct {
+	dir string
+	fs  afero.Fs
+}
+
+// NewFsStore returns a Store that writes profiles to the supplied directory.
+func NewFsStore(dir string, fs afero.Fs) *FsStore {
+	return &FsStore{dir: dir, fs: fs}
+}
+
+// Store writes each profile to a file named after the profile, in a
+// subdirectory of the store's directory named after the capture request ID.
+func (s *FsStore) Store(_ context.Context, id string, p Profiles) error {
+	dir := filepath.Join(s.dir, filepath.Base(id))
+	if err := s.fs.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrap(err, errMkdir)
+	}
+	for name, data := range p {
+		if err := afero.WriteFile(s.fs, filepath.Join(dir, name), data, 0600); err != nil {
+			return errors.Wrap(err, errWriteProfile)
+		}
+	}
+	return nil
+}
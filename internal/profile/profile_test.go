@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profile
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/afero"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+var _ Profiler = &RuntimeProfiler{}
+var _ Store = &FsStore{}
+var _ Store = &SecretStore{}
+
+func TestAddHandlers(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		paths []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   want
+	}{
+		"ErrAddHandler": {
+			reason: "We should return any error encountered adding a handler.",
+			err:    errBoom,
+			want:   want{err: errBoom},
+		},
+		"Successful": {
+			reason: "We should add all of pprof's handlers.",
+			want: want{paths: []string{
+				"/debug/pprof/",
+				"/debug/pprof/cmdline",
+				"/debug/pprof/profile",
+				"/debug/pprof/symbol",
+				"/debug/pprof/trace",
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var paths []string
+			err := AddHandlers(func(path string, _ http.Handler) error {
+				if tc.err != nil {
+					return tc.err
+				}
+				paths = append(paths, path)
+				return nil
+			})
+			sort.Strings(paths)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAddHandlers(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.paths, paths); diff != "" {
+				t.Errorf("\n%s\nAddHandlers(...): -want paths, +got paths:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRuntimeProfilerProfile(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// The context is already done, so we shouldn't wait for the CPU profile.
+	got, err := NewRuntimeProfiler().Profile(ctx, DefaultCPUDuration)
+	if err != nil {
+		t.Fatalf("Profile(...): %s", err)
+	}
+	for _, name := range []string{NameHeap, NameCPU} {
+		if len(got[name]) == 0 {
+			t.Errorf("Profile(...): want non-empty %s profile", name)
+		}
+	}
+}
+
+func TestFsStoreStore(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	s := NewFsStore("/profiles", fs)
+
+	p := Profiles{NameHeap: []byte("heap"), NameCPU: []byte("cpu")}
+	if err := s.Store(context.Background(), "../cool", p); err != nil {
+		t.Fatalf("s.Store(...): %s", err)
+	}
+
+	for name, want := range p {
+		// Capture request IDs are not trusted to name a safe path.
+		got, err := afero.ReadFile(fs, "/profiles/cool/"+name)
+		if err != nil {
+			t.Fatalf("afero.ReadFile(...): %s", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("s.Store(...): -want, +got:\n%s", diff)
+		}
+	}
+}
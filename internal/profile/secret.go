@@ -0,0 +1,246 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profile
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const (
+	errGetSecret      = "cannot get profile Secret"
+	errUpdateSecret   = "cannot update profile Secret"
+	errParseDuration  = "cannot parse CPU profile duration"
+	errCaptureProfile = "cannot capture profiles"
+	errStoreProfile   = "cannot store profiles"
+
+	errFmtProfilesTooLarge = "profiles would make the Secret's data %d bytes, which exceeds the limit of %d bytes"
+)
+
+const (
+	// AnnotationCapture requests that profiles be captured. Profiles are
+	// captured each time its value changes.
+	AnnotationCapture = "profile.crossplane.io/capture"
+
+	// AnnotationCaptured is the value of AnnotationCapture when profiles were
+	// most recently captured.
+	AnnotationCaptured = "profile.crossplane.io/captured"
+
+	// AnnotationCaptureError is why profiles could not be captured when
+	// they were most recently requested. It is removed when profiles are
+	// captured successfully.
+	AnnotationCaptureError = "profile.crossplane.io/capture-error"
+
+	// AnnotationCPUDuration is how long a CPU profile should be captured for,
+	// e.g. '30s'. Durations longer than MaxCPUDuration are capped.
+	AnnotationCPUDuration = "profile.crossplane.io/cpu-duration"
+)
+
+const (
+	// DefaultCPUDuration is how long a CPU profile is captured for by
+	// default.
+	DefaultCPUDuration = 30 * time.Second
+
+	// MaxCPUDuration is the longest a CPU profile may be captured for.
+	MaxCPUDuration = 5 * time.Minute
+
+	// MaxSecretDataSize is the largest a Secret's data may be. Kubernetes
+	// refuses to store larger Secrets.
+	MaxSecretDataSize = 1 << 20
+)
+
+// A SecretStore stores profiles in a Secret.
+type SecretStore struct {
+	client client.Client
+	name   types.NamespacedName
+}
+
+// NewSecretStore returns a Store that writes profiles to the named Secret.
+func NewSecretStore(c client.Client, name types.NamespacedName) *SecretStore {
+	return &SecretStore{client: c, name: name}
+}
+
+// Store writes each profile to a data key of the Secret named after the
+// profile, replacing any previously captured profiles. It returns an error
+// without writing any profiles if they would make the Secret too large.
+func (s *SecretStore) Store(ctx context.Context, _ string, p Profiles) error {
+	sec := &corev1.Secret{}
+	if err := s.client.Get(ctx, s.name, sec); err != nil {
+		return errors.Wrap(err, errGetSecret)
+	}
+	if sec.Data == nil {
+		sec.Data = map[string][]byte{}
+	}
+	for name, data := range p {
+		sec.Data[name] = data
+	}
+	size := 0
+	for _, data := range sec.Data {
+		size += len(data)
+	}
+	if size > MaxSecretDataSize {
+		return errors.Errorf(errFmtProfilesTooLarge, size, MaxSecretDataSize)
+	}
+	return errors.Wrap(s.client.Update(ctx, sec), errUpdateSecret)
+}
+
+// A SecretWatcher periodically reads a Secret, and captures profiles when the
+// Secret is annotated to request them.
+type SecretWatcher struct {
+	client   client.Client
+	name     types.NamespacedName
+	profiler Profiler
+	store    Store
+	log      logging.Logger
+	interval time.Duration
+}
+
+// A SecretWatcherOption configures a SecretWatcher.
+type SecretWatcherOption func(w *SecretWatcher)
+
+// WithProfiler specifies how a SecretWatcher should capture profiles.
+func WithProfiler(p Profiler) SecretWatcherOption {
+	return func(w *SecretWatcher) {
+		w.profiler = p
+	}
+}
+
+// WithStore specifies where a SecretWatcher should store profiles. Profiles
+// are stored in the watched Secret by default.
+func WithStore(s Store) SecretWatcherOption {
+	return func(w *SecretWatcher) {
+		w.store = s
+	}
+}
+
+// WithLogger specifies how a SecretWatcher should log.
+func WithLogger(l logging.Logger) SecretWatcherOption {
+	return func(w *SecretWatcher) {
+		w.log = l
+	}
+}
+
+// NewSecretWatcher returns a SecretWatcher that reads the named Secret at the
+// supplied interval. It should be supplied an uncached client, in order to
+// avoid caching all Secrets.
+func NewSecretWatcher(c client.Client, name types.NamespacedName, interval time.Duration, o ...SecretWatcherOption) *SecretWatcher {
+	w := &SecretWatcher{
+		client:   c,
+		name:     name,
+		profiler: NewRuntimeProfiler(),
+		store:    NewSecretStore(c, name),
+		log:      logging.NewNopLogger(),
+		interval: interval,
+	}
+	for _, fn := range o {
+		fn(w)
+	}
+	return w
+}
+
+// Start reading the Secret. Start blocks until the supplied context is done.
+func (w *SecretWatcher) Start(ctx context.Context) error {
+	t := time.NewTicker(w.interval)
+	defer t.Stop()
+	for {
+		if err := w.sync(ctx); err != nil {
+			w.log.Info("Cannot capture profiles", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// NeedLeaderElection returns true, because only the leader runs controllers,
+// and it's usually the controllers that we want to profile.
+func (w *SecretWatcher) NeedLeaderElection() bool {
+	return true
+}
+
+// sync captures profiles if they're requested and weren't already. It records
+// each attempt to capture profiles, whether or not it succeeds, so that a
+// failed attempt isn't repeated until profiles are requested again.
+func (w *SecretWatcher) sync(ctx context.Context) error {
+	sec := &corev1.Secret{}
+	if err := w.client.Get(ctx, w.name, sec); err != nil {
+		return errors.Wrap(resource.IgnoreNotFound(err), errGetSecret)
+	}
+
+	a := sec.GetAnnotations()
+	id := a[AnnotationCapture]
+	if id == "" || id == a[AnnotationCaptured] {
+		return nil
+	}
+
+	err := w.capture(ctx, id, a[AnnotationCPUDuration])
+
+	// The store may have updated the Secret, so we get it again before
+	// recording that we captured profiles.
+	if gerr := w.client.Get(ctx, w.name, sec); gerr != nil {
+		return errors.Wrap(gerr, errGetSecret)
+	}
+	meta := sec.GetAnnotations()
+	if meta == nil {
+		meta = map[string]string{}
+	}
+	meta[AnnotationCaptured] = id
+	delete(meta, AnnotationCaptureError)
+	if err != nil {
+		meta[AnnotationCaptureError] = err.Error()
+	}
+	sec.SetAnnotations(meta)
+	if uerr := w.client.Update(ctx, sec); uerr != nil {
+		return errors.Wrap(uerr, errUpdateSecret)
+	}
+	if err != nil {
+		return err
+	}
+	w.log.Info("Captured profiles", "id", id)
+	return nil
+}
+
+func (w *SecretWatcher) capture(ctx context.Context, id, duration string) error {
+	cpu := DefaultCPUDuration
+	if duration != "" {
+		d, err := time.ParseDuration(duration)
+		if err != nil {
+			return errors.Wrap(err, errParseDuration)
+		}
+		cpu = d
+	}
+	if cpu > MaxCPUDuration {
+		cpu = MaxCPUDuration
+	}
+
+	w.log.Info("Capturing profiles", "id", id, "cpu-duration", cpu.String())
+	p, err := w.profiler.Profile(ctx, cpu)
+	if err != nil {
+		return errors.Wrap(err, errCaptureProfile)
+	}
+	return errors.Wrap(w.store.Store(ctx, id, p), errStoreProfile)
+}
@@ -0,0 +1,272 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretStoreStore(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason string
+		client client.Client
+		p      Profiles
+		want   error
+	}{
+		"ErrGetSecret": {
+			reason: "We should return any error encountered getting the Secret.",
+			client: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			want:   errors.Wrap(errBoom, errGetSecret),
+		},
+		"ErrUpdateSecret": {
+			reason: "We should return any error encountered updating the Secret.",
+			client: &test.MockClient{
+				MockGet:    test.NewMockGetFn(nil),
+				MockUpdate: test.NewMockUpdateFn(errBoom),
+			},
+			want: errors.Wrap(errBoom, errUpdateSecret),
+		},
+		"ErrTooLarge": {
+			reason: "We should return an error if the profiles would make the Secret too large.",
+			client: &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					obj.(*corev1.Secret).Data = map[string][]byte{"other": make([]byte, 10)}
+					return nil
+				}),
+			},
+			p:    Profiles{NameHeap: make([]byte, MaxSecretDataSize)},
+			want: errors.Errorf(errFmtProfilesTooLarge, MaxSecretDataSize+10, MaxSecretDataSize),
+		},
+		"Successful": {
+			reason: "We should write each profile to the Secret.",
+			client: &test.MockClient{
+				MockGet: test.NewMockGetFn(nil),
+				MockUpdate: test.NewMockUpdateFn(nil, func(obj client.Object) error {
+					want := map[string][]byte{NameHeap: []byte("heap")}
+					if diff := cmp.Diff(want, obj.(*corev1.Secret).Data); diff != "" {
+						t.Errorf("Update(...): -want data, +got data:\n%s", diff)
+					}
+					return nil
+				}),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := tc.p
+			if p == nil {
+				p = Profiles{NameHeap: []byte("heap")}
+			}
+			s := NewSecretStore(tc.client, types.NamespacedName{Namespace: "crossplane-system", Name: "crossplane-profile"})
+			err := s.Store(context.Background(), "cool", p)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ns.Store(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretWatcherSync(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	annotated := func(a map[string]string) test.MockGetFn {
+		return test.NewMockGetFn(nil, func(obj client.Object) error {
+			obj.SetAnnotations(a)
+			return nil
+		})
+	}
+
+	profiler := func(want time.Duration) ProfilerFn {
+		return func(_ context.Context, got time.Duration) (Profiles, error) {
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Profile(...): -want duration, +got duration:\n%s", diff)
+			}
+			return Profiles{}, nil
+		}
+	}
+
+	// recorded returns a MockUpdateFn that expects the Secret to record that
+	// the supplied capture was attempted, with the supplied error.
+	recorded := func(id, err string) test.MockUpdateFn {
+		return test.NewMockUpdateFn(nil, func(obj client.Object) error {
+			a := obj.GetAnnotations()
+			if diff := cmp.Diff(id, a[AnnotationCaptured]); diff != "" {
+				t.Errorf("Update(...): -want captured, +got captured:\n%s", diff)
+			}
+			if diff := cmp.Diff(err, a[AnnotationCaptureError]); diff != "" {
+				t.Errorf("Update(...): -want capture error, +got capture error:\n%s", diff)
+			}
+			return nil
+		})
+	}
+
+	type args struct {
+		client   client.Client
+		profiler Profiler
+		store    Store
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"SecretNotFound": {
+			reason: "We should not capture profiles if the Secret does not exist.",
+			args: args{
+				client: &test.MockClient{MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "crossplane-profile"))},
+			},
+		},
+		"ErrGetSecret": {
+			reason: "We should return any error encountered getting the Secret.",
+			args: args{
+				client: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			},
+			want: errors.Wrap(errBoom, errGetSecret),
+		},
+		"NotRequested": {
+			reason: "We should not capture profiles if none are requested.",
+			args: args{
+				client: &test.MockClient{MockGet: annotated(nil)},
+			},
+		},
+		"AlreadyCaptured": {
+			reason: "We should not capture profiles if the requested profiles were already captured.",
+			args: args{
+				client: &test.MockClient{MockGet: annotated(map[string]string{
+					AnnotationCapture:  "cool",
+					AnnotationCaptured: "cool",
+				})},
+			},
+		},
+		"ErrParseDuration": {
+			reason: "We should record and return any error encountered parsing the CPU profile duration.",
+			args: args{
+				client: &test.MockClient{
+					MockGet: annotated(map[string]string{
+						AnnotationCapture:     "cool",
+						AnnotationCPUDuration: "forever",
+					}),
+					MockUpdate: recorded("cool", `cannot parse CPU profile duration: time: invalid duration "forever"`),
+				},
+			},
+			want: errors.Wrap(errors.New(`time: invalid duration "forever"`), errParseDuration),
+		},
+		"ErrProfile": {
+			reason: "We should record and return any error encountered capturing profiles.",
+			args: args{
+				client: &test.MockClient{
+					MockGet:    annotated(map[string]string{AnnotationCapture: "cool"}),
+					MockUpdate: recorded("cool", "cannot capture profiles: boom"),
+				},
+				profiler: ProfilerFn(func(_ context.Context, _ time.Duration) (Profiles, error) {
+					return nil, errBoom
+				}),
+			},
+			want: errors.Wrap(errBoom, errCaptureProfile),
+		},
+		"ErrStore": {
+			reason: "We should record and return any error encountered storing profiles.",
+			args: args{
+				client: &test.MockClient{
+					MockGet:    annotated(map[string]string{AnnotationCapture: "cool"}),
+					MockUpdate: recorded("cool", "cannot store profiles: boom"),
+				},
+				profiler: profiler(DefaultCPUDuration),
+				store: StoreFn(func(_ context.Context, _ string, _ Profiles) error {
+					return errBoom
+				}),
+			},
+			want: errors.Wrap(errBoom, errStoreProfile),
+		},
+		"ErrUpdateSecret": {
+			reason: "We should return any error encountered recording that profiles were captured.",
+			args: args{
+				client: &test.MockClient{
+					MockGet:    annotated(map[string]string{AnnotationCapture: "cool"}),
+					MockUpdate: test.NewMockUpdateFn(errBoom),
+				},
+				profiler: profiler(DefaultCPUDuration),
+				store:    StoreFn(func(_ context.Context, _ string, _ Profiles) error { return nil }),
+			},
+			want: errors.Wrap(errBoom, errUpdateSecret),
+		},
+		"CapCPUDuration": {
+			reason: "We should capture a CPU profile for no longer than the maximum duration.",
+			args: args{
+				client: &test.MockClient{
+					MockGet: annotated(map[string]string{
+						AnnotationCapture:     "cool",
+						AnnotationCPUDuration: "24h",
+					}),
+					MockUpdate: recorded("cool", ""),
+				},
+				profiler: profiler(MaxCPUDuration),
+				store:    StoreFn(func(_ context.Context, _ string, _ Profiles) error { return nil }),
+			},
+		},
+		"Successful": {
+			reason: "We should capture and store the requested profiles, then record that they were captured.",
+			args: args{
+				client: &test.MockClient{
+					MockGet: annotated(map[string]string{
+						AnnotationCapture:      "cool",
+						AnnotationCaptured:     "previous",
+						AnnotationCaptureError: "boom",
+						AnnotationCPUDuration:  "10s",
+					}),
+					MockUpdate: recorded("cool", ""),
+				},
+				profiler: profiler(10 * time.Second),
+				store: StoreFn(func(_ context.Context, id string, _ Profiles) error {
+					if diff := cmp.Diff("cool", id); diff != "" {
+						t.Errorf("Store(...): -want id, +got id:\n%s", diff)
+					}
+					return nil
+				}),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			w := NewSecretWatcher(tc.args.client, types.NamespacedName{Namespace: "crossplane-system", Name: "crossplane-profile"}, 0,
+				WithProfiler(tc.args.profiler),
+				WithStore(tc.args.store),
+			)
+			err := w.sync(context.Background())
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nw.sync(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
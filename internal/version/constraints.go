@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/Masterminds/semver"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const errFmtImproperConstraint = "improper constraint: %s"
+
+// ParseConstraints parses the supplied semantic version constraints. In
+// addition to the syntax supported by Masterminds/semver it accepts the syntax
+// users of npm and Cargo expect. Constraints may be separated by whitespace as
+// well as commas, e.g. ">=1.2 <2.0 || >=3.0", and an operator may be separated
+// from its version, e.g. ">= 1.2". A caret constraint on a version below 1.0.0
+// allows only changes that don't modify its leftmost non-zero component, e.g.
+// "^0.2.3" means ">=0.2.3, <0.3.0".
+func ParseConstraints(c string) (*semver.Constraints, error) {
+	alternatives := strings.Split(c, "||")
+	for i, alt := range alternatives {
+		alternatives[i] = normalize(alt)
+	}
+	sc, err := semver.NewConstraint(strings.Join(alternatives, " || "))
+	if err != nil {
+		// Return the constraints as the user wrote them, not as we
+		// normalized them.
+		return nil, errors.Errorf(errFmtImproperConstraint, c)
+	}
+	return sc, nil
+}
+
+// normalize a set of constraints that must all be satisfied into the
+// comma-separated form Masterminds/semver expects.
+func normalize(constraints string) string {
+	fields := strings.FieldsFunc(constraints, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+	terms := make([]string, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		t := fields[i]
+
+		// An operator separated from its version, e.g. ">= 1.2".
+		if isOperator(t) && i+1 < len(fields) {
+			t += fields[i+1]
+			i++
+		}
+
+		// A hyphen range, e.g. "1.2 - 1.4".
+		if i+2 < len(fields) && fields[i+1] == "-" {
+			t = t + " - " + fields[i+2]
+			i += 2
+		}
+
+		terms = append(terms, caret(t))
+	}
+	return strings.Join(terms, ", ")
+}
+
+func isOperator(s string) bool {
+	return strings.Trim(s, "=<>!~^") == ""
+}
+
+// caret rewrites a caret constraint on a version below 1.0.0 into an explicit
+// range. Masterminds/semver treats "^0.2.3" as "<1.0.0", whereas npm and Cargo
+// treat it as "<0.3.0". Other constraints are returned unchanged.
+func caret(t string) string {
+	if !strings.HasPrefix(t, "^") {
+		return t
+	}
+	raw := strings.TrimPrefix(t, "^")
+	core := strings.TrimPrefix(raw, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	if strings.ContainsAny(core, "xX*") {
+		return t
+	}
+	v, err := semver.NewVersion(raw)
+	if err != nil || v.Major() > 0 {
+		return t
+	}
+
+	var upper string
+	switch parts := len(strings.Split(core, ".")); {
+	case parts == 1:
+		// ^0 means <1.0.0, which Masterminds/semver gets right.
+		return t
+	case v.Minor() > 0:
+		upper = fmt.Sprintf("0.%d.0", v.Minor()+1)
+	case parts == 2:
+		upper = "0.1.0"
+	default:
+		upper = fmt.Sprintf("0.0.%d", v.Patch()+1)
+	}
+	return ">=" + raw + ", <" + upper
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Masterminds/semver"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestParseConstraints(t *testing.T) {
+	type want struct {
+		satisfied   []string
+		unsatisfied []string
+		err         error
+	}
+
+	cases := map[string]struct {
+		reason      string
+		constraints string
+		want        want
+	}{
+		"Exact": {
+			reason:      "An exact version should be satisfied only by that version.",
+			constraints: "v1.2.3",
+			want: want{
+				satisfied:   []string{"v1.2.3", "1.2.3"},
+				unsatisfied: []string{"v1.2.2", "v1.2.4"},
+			},
+		},
+		"Comparisons": {
+			reason:      "Each comparison operator should be supported.",
+			constraints: ">1.0.0, >=1.1.0, <2.0.0, <=1.9.0, !=1.5.0",
+			want: want{
+				satisfied:   []string{"1.1.0", "1.4.9", "1.9.0"},
+				unsatisfied: []string{"1.0.5", "1.5.0", "1.9.1", "2.0.0"},
+			},
+		},
+		"WhitespaceSeparated": {
+			reason:      "Constraints separated by whitespace should all have to be satisfied.",
+			constraints: ">=1.2 <2.0",
+			want: want{
+				satisfied:   []string{"1.2.0", "1.9.9"},
+				unsatisfied: []string{"1.1.9", "2.0.0"},
+			},
+		},
+		"OperatorSeparatedFromVersion": {
+			reason:      "An operator separated from its version by whitespace should apply to that version.",
+			constraints: ">= 1.2, < 2.0",
+			want: want{
+				satisfied:   []string{"1.2.0", "1.9.9"},
+				unsatisfied: []string{"1.1.9", "2.0.0"},
+			},
+		},
+		"Alternatives": {
+			reason:      "Alternatives should be satisfied if any one of them is satisfied.",
+			constraints: ">=1.2 <2.0 || >=3.0",
+			want: want{
+				satisfied:   []string{"1.2.0", "1.9.9", "3.0.0", "4.1.0"},
+				unsatisfied: []string{"1.1.0", "2.0.0", "2.9.9"},
+			},
+		},
+		"AlternativesWithoutWhitespace": {
+			reason:      "Alternatives need not be separated by whitespace.",
+			constraints: "1.2.3||>=3.0.0,<4.0.0",
+			want: want{
+				satisfied:   []string{"1.2.3", "3.5.0"},
+				unsatisfied: []string{"1.2.4", "4.0.0"},
+			},
+		},
+		"HyphenRange": {
+			reason:      "A hyphen range should be inclusive of both bounds.",
+			constraints: "1.2 - 1.4.5",
+			want: want{
+				satisfied:   []string{"1.2.0", "1.4.5"},
+				unsatisfied: []string{"1.1.9", "1.4.6"},
+			},
+		},
+		"Wildcard": {
+			reason:      "A wildcard should match any version of that component.",
+			constraints: "1.2.x",
+			want: want{
+				satisfied:   []string{"1.2.0", "1.2.99"},
+				unsatisfied: []string{"1.1.0", "1.3.0"},
+			},
+		},
+		"Tilde": {
+			reason:      "A tilde constraint should allow patch releases.",
+			constraints: "~1.2.3",
+			want: want{
+				satisfied:   []string{"1.2.3", "1.2.9"},
+				unsatisfied: []string{"1.2.2", "1.3.0"},
+			},
+		},
+		"TildeMajorOnly": {
+			reason:      "A tilde constraint on only a major version should allow minor releases.",
+			constraints: "~1",
+			want: want{
+				satisfied:   []string{"1.0.0", "1.9.0"},
+				unsatisfied: []string{"0.9.0", "2.0.0"},
+			},
+		},
+		"TildeV0": {
+			reason:      "A tilde constraint on a v0 version should allow patch releases.",
+			constraints: "~v0.2.3",
+			want: want{
+				satisfied:   []string{"v0.2.3", "v0.2.9"},
+				unsatisfied: []string{"v0.2.2", "v0.3.0"},
+			},
+		},
+		"Caret": {
+			reason:      "A caret constraint should allow minor and patch releases.",
+			constraints: "^1.2",
+			want: want{
+				satisfied:   []string{"1.2.0", "1.9.9"},
+				unsatisfied: []string{"1.1.9", "2.0.0"},
+			},
+		},
+		"CaretWithV": {
+			reason:      "A caret constraint should accept a version with a v prefix.",
+			constraints: "^v1.2.3",
+			want: want{
+				satisfied:   []string{"v1.2.3", "v1.9.0"},
+				unsatisfied: []string{"v1.2.2", "v2.0.0"},
+			},
+		},
+		"CaretV0Minor": {
+			reason:      "A caret constraint on a v0 version with a non-zero minor should allow only patch releases.",
+			constraints: "^0.2.3",
+			want: want{
+				satisfied:   []string{"0.2.3", "0.2.9"},
+				unsatisfied: []string{"0.2.2", "0.3.0", "1.0.0"},
+			},
+		},
+		"CaretV0MinorNoPatch": {
+			reason:      "A caret constraint on a v0 major and minor version should allow only patch releases.",
+			constraints: "^v0.2",
+			want: want{
+				satisfied:   []string{"v0.2.0", "v0.2.9"},
+				unsatisfied: []string{"v0.1.9", "v0.3.0"},
+			},
+		},
+		"CaretV0Patch": {
+			reason:      "A caret constraint on a v0.0 version should allow only that version.",
+			constraints: "^0.0.3",
+			want: want{
+				satisfied:   []string{"0.0.3"},
+				unsatisfied: []string{"0.0.2", "0.0.4", "0.1.0"},
+			},
+		},
+		"CaretV0ZeroMinor": {
+			reason:      "A caret constraint on v0.0 should allow only patch releases.",
+			constraints: "^0.0",
+			want: want{
+				satisfied:   []string{"0.0.0", "0.0.9"},
+				unsatisfied: []string{"0.1.0"},
+			},
+		},
+		"CaretV0MajorOnly": {
+			reason:      "A caret constraint on only a v0 major version should allow any v0 version.",
+			constraints: "^0",
+			want: want{
+				satisfied:   []string{"0.0.1", "0.9.0"},
+				unsatisfied: []string{"1.0.0"},
+			},
+		},
+		"CaretV0Alternatives": {
+			reason:      "A caret constraint on a v0 version should be rewritten within each alternative.",
+			constraints: "^0.2.3 || ^0.4",
+			want: want{
+				satisfied:   []string{"0.2.5", "0.4.1"},
+				unsatisfied: []string{"0.3.0", "0.5.0"},
+			},
+		},
+		"ErrImproper": {
+			reason:      "We should return an error that includes the constraints as they were written.",
+			constraints: ">= a2 <2.0",
+			want: want{
+				err: errors.New("improper constraint: >= a2 <2.0"),
+			},
+		},
+		"ErrDanglingOperator": {
+			reason:      "We should return an error if an operator has no version.",
+			constraints: ">=1.0.0 <",
+			want: want{
+				err: errors.New("improper constraint: >=1.0.0 <"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, err := ParseConstraints(tc.constraints)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseConstraints(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			for _, v := range tc.want.satisfied {
+				if !c.Check(semver.MustParse(v)) {
+					t.Errorf("\n%s\nParseConstraints(%q).Check(%q): want true, got false", tc.reason, tc.constraints, v)
+				}
+			}
+			for _, v := range tc.want.unsatisfied {
+				if c.Check(semver.MustParse(v)) {
+					t.Errorf("\n%s\nParseConstraints(%q).Check(%q): want false, got true", tc.reason, tc.constraints, v)
+				}
+			}
+		})
+	}
+}
@@ -58,7 +58,7 @@ func (v *Versioner) InConstraints(c string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	constraint, err := semver.NewConstraint(c)
+	constraint, err := ParseConstraints(c)
 	if err != nil {
 		return false, err
 	}
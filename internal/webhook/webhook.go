@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook contains utilities for Crossplane's admission webhooks.
+package webhook
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type multiValidating []admission.Handler
+
+// MultiValidatingHandler combines the supplied validating admission handlers
+// into one. Unlike admission.MultiValidatingHandler it returns the warnings of
+// every handler that allows a request, not only those of the handler that
+// denies it.
+func MultiValidatingHandler(hs ...admission.Handler) admission.Handler {
+	return multiValidating(hs)
+}
+
+// Handle an admission request by calling each handler in turn until one denies
+// the request.
+func (hs multiValidating) Handle(ctx context.Context, req admission.Request) admission.Response {
+	warnings := make([]string, 0)
+	for _, h := range hs {
+		rsp := h.Handle(ctx, req)
+		warnings = append(warnings, rsp.Warnings...)
+		if !rsp.Allowed {
+			rsp.Warnings = warnings
+			return rsp
+		}
+	}
+	if len(warnings) == 0 {
+		return admission.Allowed("")
+	}
+	return admission.Allowed("").WithWarnings(warnings...)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestMultiValidatingHandler(t *testing.T) {
+	allow := func(warnings ...string) admission.Handler {
+		return admission.HandlerFunc(func(_ context.Context, _ admission.Request) admission.Response {
+			return admission.Allowed("").WithWarnings(warnings...)
+		})
+	}
+	deny := admission.HandlerFunc(func(_ context.Context, _ admission.Request) admission.Response {
+		return admission.Denied("nope")
+	})
+	unreachable := admission.HandlerFunc(func(_ context.Context, _ admission.Request) admission.Response {
+		return admission.Allowed("").WithWarnings("unreachable")
+	})
+
+	cases := map[string]struct {
+		reason   string
+		handlers []admission.Handler
+		want     admission.Response
+	}{
+		"NoHandlers": {
+			reason: "A request should be allowed if there are no handlers.",
+			want:   admission.Allowed(""),
+		},
+		"AllAllowed": {
+			reason: "A request should be allowed with the warnings of all handlers if all handlers allow it.",
+			handlers: []admission.Handler{
+				allow("one"),
+				allow(),
+				allow("two", "three"),
+			},
+			want: admission.Allowed("").WithWarnings("one", "two", "three"),
+		},
+		"Denied": {
+			reason: "A request should be denied with the warnings of the handlers called so far if any handler denies it.",
+			handlers: []admission.Handler{
+				allow("one"),
+				deny,
+				unreachable,
+			},
+			want: admission.Denied("nope").WithWarnings("one"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := MultiValidatingHandler(tc.handlers...).Handle(context.Background(), admission.Request{})
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nHandle(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"k8s.io/utils/pointer"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 )
@@ -112,6 +113,8 @@ func ForCompositeResource(xrd *v1.CompositeResourceDefinition) (*extv1.CustomRes
 			statusProps.Properties[k] = v
 		}
 		crd.Spec.Versions[i].Schema.OpenAPIV3Schema.Properties["status"] = statusProps
+
+		markDeprecatedFields(crd.Spec.Versions[i].Schema.OpenAPIV3Schema, vr.DeprecatedFields)
 	}
 
 	return crd, nil
@@ -185,6 +188,8 @@ func ForCompositeResourceClaim(xrd *v1.CompositeResourceDefinition) (*extv1.Cust
 			statusProps.Properties[k] = v
 		}
 		crd.Spec.Versions[i].Schema.OpenAPIV3Schema.Properties["status"] = statusProps
+
+		markDeprecatedFields(crd.Spec.Versions[i].Schema.OpenAPIV3Schema, vr.DeprecatedFields)
 	}
 
 	return crd, nil
@@ -232,6 +237,54 @@ func getProps(field string, v *v1.CompositeResourceValidation) (map[string]extv1
 	return spec.Properties, spec.Required, nil
 }
 
+// markDeprecatedFields prefixes the description of each of the supplied
+// deprecated fields with its deprecation message, so that the deprecation is
+// visible to anyone reading the schema (e.g. using kubectl explain). Fields
+// that don't appear in the supplied schema are ignored.
+func markDeprecatedFields(root *extv1.JSONSchemaProps, fields []v1.DeprecatedField) {
+	for _, f := range fields {
+		s, err := fieldpath.Parse(f.Path)
+		if err != nil {
+			// Deprecated field paths are validated when the definition is
+			// created or updated.
+			continue
+		}
+		*root = markDeprecated(*root, s, f.Message)
+	}
+}
+
+func markDeprecated(p extv1.JSONSchemaProps, s fieldpath.Segments, msg string) extv1.JSONSchemaProps {
+	if len(s) == 0 {
+		p.Description = deprecatedDescription(p.Description, msg)
+		return p
+	}
+
+	// Both array indices and wildcards refer to the items of an array.
+	if s[0].Type == fieldpath.SegmentIndex || s[0].Field == "*" {
+		if p.Items != nil && p.Items.Schema != nil {
+			i := markDeprecated(*p.Items.Schema, s[1:], msg)
+			p.Items.Schema = &i
+		}
+		return p
+	}
+
+	if c, ok := p.Properties[s[0].Field]; ok {
+		p.Properties[s[0].Field] = markDeprecated(c, s[1:], msg)
+	}
+	return p
+}
+
+func deprecatedDescription(description, msg string) string {
+	d := "Deprecated."
+	if msg != "" {
+		d = "Deprecated: " + msg
+	}
+	if description == "" {
+		return d
+	}
+	return d + " " + description
+}
+
 // IsEstablished is a helper function to check whether api-server is ready
 // to accept the instances of registered CRD.
 func IsEstablished(s extv1.CustomResourceDefinitionStatus) bool {
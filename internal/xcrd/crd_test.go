@@ -355,6 +355,23 @@ func TestForCompositeResource(t *testing.T) {
 											"lastPublishedTime": {Type: "string", Format: "date-time"},
 										},
 									},
+									"remainingResources": {
+										Description: "Composed resources that still exist while the resource is being deleted.",
+										Type:        "array",
+										Items: &extv1.JSONSchemaPropsOrArray{
+											Schema: &extv1.JSONSchemaProps{
+												Type:     "object",
+												Required: []string{"apiVersion", "kind", "name", "reason"},
+												Properties: map[string]extv1.JSONSchemaProps{
+													"apiVersion": {Type: "string"},
+													"kind":       {Type: "string"},
+													"name":       {Type: "string"},
+													"namespace":  {Type: "string"},
+													"reason":     {Type: "string"},
+												},
+											},
+										},
+									},
 								},
 							},
 						},
@@ -751,6 +768,23 @@ func TestForCompositeResourceClaim(t *testing.T) {
 												"lastPublishedTime": {Type: "string", Format: "date-time"},
 											},
 										},
+										"remainingResources": {
+											Description: "Composed resources that still exist while the resource is being deleted.",
+											Type:        "array",
+											Items: &extv1.JSONSchemaPropsOrArray{
+												Schema: &extv1.JSONSchemaProps{
+													Type:     "object",
+													Required: []string{"apiVersion", "kind", "name", "reason"},
+													Properties: map[string]extv1.JSONSchemaProps{
+														"apiVersion": {Type: "string"},
+														"kind":       {Type: "string"},
+														"name":       {Type: "string"},
+														"namespace":  {Type: "string"},
+														"reason":     {Type: "string"},
+													},
+												},
+											},
+										},
 									},
 								},
 							},
@@ -770,3 +804,74 @@ func TestForCompositeResourceClaim(t *testing.T) {
 		t.Errorf("ForCompositeResourceClaim(...): -want, +got:\n%s", diff)
 	}
 }
+
+func TestMarkDeprecatedFields(t *testing.T) {
+	schema := func() *extv1.JSONSchemaProps {
+		return &extv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]extv1.JSONSchemaProps{
+				"spec": {
+					Type: "object",
+					Properties: map[string]extv1.JSONSchemaProps{
+						"size": {Type: "integer", Description: "The size of the widget."},
+						"parts": {
+							Type: "array",
+							Items: &extv1.JSONSchemaPropsOrArray{Schema: &extv1.JSONSchemaProps{
+								Type: "object",
+								Properties: map[string]extv1.JSONSchemaProps{
+									"name": {Type: "string"},
+								},
+							}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		fields []v1.DeprecatedField
+		want   *extv1.JSONSchemaProps
+	}{
+		"NoDeprecatedFields": {
+			reason: "A schema with no deprecated fields should be unchanged.",
+			want:   schema(),
+		},
+		"DeprecatedField": {
+			reason: "The description of a deprecated field should be prefixed with its deprecation message.",
+			fields: []v1.DeprecatedField{{Path: "spec.size", Message: "Use spec.parameters.size."}},
+			want: func() *extv1.JSONSchemaProps {
+				s := schema()
+				size := s.Properties["spec"].Properties["size"]
+				size.Description = "Deprecated: Use spec.parameters.size. The size of the widget."
+				s.Properties["spec"].Properties["size"] = size
+				return s
+			}(),
+		},
+		"DeprecatedArrayItemField": {
+			reason: "Deprecated fields within the items of an array should be marked deprecated.",
+			fields: []v1.DeprecatedField{{Path: "spec.parts[*].name"}},
+			want: func() *extv1.JSONSchemaProps {
+				s := schema()
+				s.Properties["spec"].Properties["parts"].Items.Schema.Properties["name"] = extv1.JSONSchemaProps{Type: "string", Description: "Deprecated."}
+				return s
+			}(),
+		},
+		"UnknownField": {
+			reason: "Deprecated fields that don't appear in the schema should be ignored.",
+			fields: []v1.DeprecatedField{{Path: "spec.colour"}, {Path: "spec.size.units"}},
+			want:   schema(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := schema()
+			markDeprecatedFields(got, tc.fields)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nmarkDeprecatedFields(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -301,6 +301,23 @@ func CompositeResourceStatusProps() map[string]extv1.JSONSchemaProps {
 				"lastPublishedTime": {Type: "string", Format: "date-time"},
 			},
 		},
+		"remainingResources": {
+			Description: "Composed resources that still exist while the resource is being deleted.",
+			Type:        "array",
+			Items: &extv1.JSONSchemaPropsOrArray{
+				Schema: &extv1.JSONSchemaProps{
+					Type:     "object",
+					Required: []string{"apiVersion", "kind", "name", "reason"},
+					Properties: map[string]extv1.JSONSchemaProps{
+						"apiVersion": {Type: "string"},
+						"kind":       {Type: "string"},
+						"name":       {Type: "string"},
+						"namespace":  {Type: "string"},
+						"reason":     {Type: "string"},
+					},
+				},
+			},
+		},
 	}
 }
 
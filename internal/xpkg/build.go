@@ -78,8 +78,29 @@ func (t *teeReader) Annotate() interface{} {
 	return anno.Annotate()
 }
 
+// A BuildOption configures how a package is built.
+type BuildOption func(o *buildOptions)
+
+type buildOptions struct {
+	warn func(err error)
+}
+
+// WithLintWarningFn specifies a function that is called with the lint rules that
+// the package broke, if it broke only rules of warning severity. Such packages
+// are otherwise built silently.
+func WithLintWarningFn(fn func(err error)) BuildOption {
+	return func(o *buildOptions) {
+		o.warn = fn
+	}
+}
+
 // Build compiles a Crossplane package from an on-disk package.
-func Build(ctx context.Context, b parser.Backend, p parser.Parser, l parser.Linter) (v1.Image, error) {
+func Build(ctx context.Context, b parser.Backend, p parser.Parser, l parser.Linter, opts ...BuildOption) (v1.Image, error) {
+	bo := &buildOptions{warn: func(error) {}}
+	for _, fn := range opts {
+		fn(bo)
+	}
+
 	// Get YAML stream.
 	r, err := b.Init(ctx)
 	if err != nil {
@@ -93,7 +114,12 @@ func Build(ctx context.Context, b parser.Backend, p parser.Parser, l parser.Lint
 	if err != nil {
 		return nil, errors.Wrap(err, errParserPackage)
 	}
-	if err := l.Lint(pkg); err != nil {
+	err = l.Lint(pkg)
+	if IsLintWarnings(err) {
+		bo.warn(err)
+		err = nil
+	}
+	if err != nil {
 		return nil, errors.Wrap(err, errLintPackage)
 	}
 
@@ -17,21 +17,52 @@ limitations under the License.
 package xpkg
 
 import (
+	"compress/flate"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 
 	"github.com/spf13/afero"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+
+	"github.com/crossplane/crossplane/internal/metrics"
+)
+
+const (
+	errGetNopCache       = "cannot get content from a NopCache"
+	errFmtQuotaExceeded  = "cached contents of package %q exceed its quota of %d bytes"
+	errFmtCreateScopeDir = "cannot create cache directory for package %q"
+	errFmtCorrupt        = "cached contents %q are corrupt"
+	errFmtDigestMismatch = "content has digest %s, not %s"
+	errReadDigest        = "cannot read digest of cached contents"
+	errWriteDigest       = "cannot write digest of cached contents"
 )
 
 const (
-	errGetNopCache = "cannot get content from a NopCache"
+	cacheContentExt = ".gz"
+	cacheDigestExt  = ".sha256"
+	digestPrefix    = "sha256:"
 )
 
-const cacheContentExt = ".gz"
+// corruptError is returned when cached content does not match the digest it
+// had when it was stored.
+type corruptError struct{ error }
+
+func (e corruptError) Unwrap() error { return e.error }
+
+// IsCorrupt returns true if the supplied error indicates that content read
+// from the cache was corrupt, and should be fetched again.
+func IsCorrupt(err error) bool {
+	return errors.As(err, &corruptError{})
+}
 
 // A PackageCache caches package content.
 type PackageCache interface {
@@ -41,20 +72,88 @@ type PackageCache interface {
 	Delete(id string) error
 }
 
+// ScopedCacheID returns an identifier under which the supplied content is
+// cached in a directory of the supplied package's own. The total size of the
+// content cached for each package may be limited using WithPackageQuota.
+func ScopedCacheID(pkg, id string) string {
+	return filepath.Join(pkg, id)
+}
+
+// scope returns the package the supplied cache identifier is scoped to, or an
+// empty string if it is not scoped to a package.
+func scope(id string) string {
+	if d := filepath.Dir(filepath.Clean(id)); d != "." {
+		return d
+	}
+	return ""
+}
+
 // FsPackageCache stores and retrieves package content in a filesystem-backed
 // cache in a thread-safe manner.
 type FsPackageCache struct {
-	dir string
-	fs  afero.Fs
-	mu  sync.RWMutex
+	dir              string
+	fs               afero.Fs
+	mu               sync.RWMutex
+	quota            int64
+	detectCorruption bool
+	metrics          metrics.CacheRecorder
+	log              logging.Logger
+}
+
+// A FsPackageCacheOption configures a FsPackageCache.
+type FsPackageCacheOption func(c *FsPackageCache)
+
+// WithPackageQuota limits the total size in bytes of the compressed content
+// cached for each package. It applies only to content cached under a
+// ScopedCacheID. Zero means no limit.
+func WithPackageQuota(bytes int64) FsPackageCacheOption {
+	return func(c *FsPackageCache) {
+		c.quota = bytes
+	}
+}
+
+// WithCorruptionDetection detects content that was corrupted while it was
+// cached, for example by bit-rot, by checking that content read from the cache
+// has the digest it had when it was stored. The digest is stored alongside the
+// content, so this does not detect content that was deliberately tampered with;
+// anyone who can write the content can also write its digest. Content stored
+// without a digest, for example by a pre-populated cache, can't be checked and
+// is read as is.
+func WithCorruptionDetection() FsPackageCacheOption {
+	return func(c *FsPackageCache) {
+		c.detectCorruption = true
+	}
+}
+
+// WithCacheMetricsRecorder specifies how the FsPackageCache should record the
+// size of the content it caches for each package, and any corrupt content it
+// reads.
+func WithCacheMetricsRecorder(r metrics.CacheRecorder) FsPackageCacheOption {
+	return func(c *FsPackageCache) {
+		c.metrics = r
+	}
+}
+
+// WithCacheLogger specifies how the FsPackageCache should log, for example
+// when content it reads can't be checked for corruption.
+func WithCacheLogger(l logging.Logger) FsPackageCacheOption {
+	return func(c *FsPackageCache) {
+		c.log = l
+	}
 }
 
 // NewFsPackageCache creates a new FsPackageCache.
-func NewFsPackageCache(dir string, fs afero.Fs) *FsPackageCache {
-	return &FsPackageCache{
-		dir: dir,
-		fs:  fs,
+func NewFsPackageCache(dir string, fs afero.Fs, opts ...FsPackageCacheOption) *FsPackageCache {
+	c := &FsPackageCache{
+		dir:     dir,
+		fs:      fs,
+		metrics: metrics.NewNopCacheRecorder(),
+		log:     logging.NewNopLogger(),
+	}
+	for _, o := range opts {
+		o(c)
 	}
+	return c
 }
 
 // Has indicates whether an item with the given id is in the cache.
@@ -65,7 +164,9 @@ func (c *FsPackageCache) Has(id string) bool {
 	return false
 }
 
-// Get retrieves package contents from the cache.
+// Get retrieves package contents from the cache. If corruption detection is
+// enabled, reading content that doesn't match its digest returns an error for
+// which IsCorrupt returns true, once the content has been read.
 func (c *FsPackageCache) Get(id string) (io.ReadCloser, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -73,45 +174,217 @@ func (c *FsPackageCache) Get(id string) (io.ReadCloser, error) {
 	if err != nil {
 		return nil, err
 	}
-	return GzipReadCloser(f)
+	if !c.detectCorruption {
+		return GzipReadCloser(f)
+	}
+	want, err := afero.ReadFile(c.fs, BuildPath(c.dir, id, cacheDigestExt))
+	if os.IsNotExist(err) {
+		c.log.Debug("Cannot detect corruption of cached contents that have no digest", "id", id)
+		return GzipReadCloser(f)
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, errors.Wrap(err, errReadDigest)
+	}
+	rc, err := GzipReadCloser(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, c.corrupt(id, err)
+	}
+	return &verifyingReadCloser{rc: rc, h: sha256.New(), want: strings.TrimSpace(string(want)), corrupt: func(err error) error { return c.corrupt(id, err) }}, nil
 }
 
-// Store saves the package contents to the cache.
+// corrupt records that the content cached under the supplied id is corrupt,
+// and returns an error indicating so.
+func (c *FsPackageCache) corrupt(id string, err error) error {
+	c.metrics.RecordCacheCorruption()
+	return corruptError{errors.Wrapf(err, errFmtCorrupt, id)}
+}
+
+// Store saves the package contents to the cache. Content that is scoped to a
+// package is not stored if it would exceed the package's quota. It is still
+// read to the end, so that anyone reading the content as it is stored can
+// finish reading it.
 func (c *FsPackageCache) Store(id string, content io.ReadCloser) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	cf, err := c.fs.Create(BuildPath(c.dir, id, cacheContentExt))
+	path := BuildPath(c.dir, id, cacheContentExt)
+	pkg := scope(id)
+	if pkg != "" {
+		if err := c.fs.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return errors.Wrapf(err, errFmtCreateScopeDir, pkg)
+		}
+		defer c.record(pkg)
+	}
+	cf, err := c.fs.Create(path)
 	if err != nil {
 		return err
 	}
 	// NOTE(hasheddan): we don't check error on deferred file close as Close()
 	// is explicitly called in the happy path.
 	defer cf.Close() //nolint:errcheck
-	w, err := gzip.NewWriterLevel(cf, gzip.BestSpeed)
-	if err != nil {
+
+	// A digest that was stored with previous content doesn't apply to this
+	// content.
+	dpath := BuildPath(c.dir, id, cacheDigestExt)
+	if err := c.fs.Remove(dpath); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, errWriteDigest)
+	}
+	var r io.Reader = content
+	var h hash.Hash
+	if c.detectCorruption {
+		h = sha256.New()
+		r = io.TeeReader(content, h)
+	}
+
+	// Don't leave partially written content in the cache.
+	if err := c.write(cf, pkg, path, r); err != nil {
+		_ = cf.Close()
+		_ = c.fs.Remove(path)
 		return err
 	}
-	_, err = io.Copy(w, content)
+	if err := cf.Close(); err != nil {
+		_ = c.fs.Remove(path)
+		return err
+	}
+	if h == nil {
+		return nil
+	}
+	if err := afero.WriteFile(c.fs, dpath, []byte(digestPrefix+hex.EncodeToString(h.Sum(nil))), 0644); err != nil {
+		_ = c.fs.Remove(path)
+		return errors.Wrap(err, errWriteDigest)
+	}
+	return nil
+}
+
+// write the supplied content to the supplied file, which is scoped to the
+// supplied package, if any.
+func (c *FsPackageCache) write(f io.Writer, pkg, path string, content io.Reader) error {
+	var q *quotaWriter
+	if pkg != "" && c.quota > 0 {
+		q = &quotaWriter{w: f, remaining: c.quota - c.usage(pkg, path)}
+		f = q
+	}
+	w, err := gzip.NewWriterLevel(f, gzip.BestSpeed)
 	if err != nil {
 		return err
 	}
+	if _, err := io.Copy(w, content); err != nil {
+		if q != nil && q.exceeded {
+			_, _ = io.Copy(io.Discard, content)
+			return errors.Errorf(errFmtQuotaExceeded, pkg, c.quota)
+		}
+		return err
+	}
 	// NOTE(hasheddan): gzip writer must be closed to ensure all data is flushed
 	// to file.
 	if err := w.Close(); err != nil {
+		if q != nil && q.exceeded {
+			return errors.Errorf(errFmtQuotaExceeded, pkg, c.quota)
+		}
 		return err
 	}
-	return cf.Close()
+	return nil
 }
 
-// Delete removes package contents from the cache.
+// Delete removes package contents from the cache. The directory of a package
+// is removed along with the last of its cached content.
 func (c *FsPackageCache) Delete(id string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	err := c.fs.Remove(BuildPath(c.dir, id, cacheContentExt))
-	if os.IsNotExist(err) {
+	path := BuildPath(c.dir, id, cacheContentExt)
+	err := c.fs.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := c.fs.Remove(BuildPath(c.dir, id, cacheDigestExt)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	pkg := scope(id)
+	if pkg == "" {
 		return nil
 	}
-	return err
+	defer c.record(pkg)
+	if fis, err := afero.ReadDir(c.fs, filepath.Dir(path)); err == nil && len(fis) == 0 {
+		return c.fs.Remove(filepath.Dir(path))
+	}
+	return nil
+}
+
+// usage returns the size in bytes of the content cached for the supplied
+// package, excluding the content at the supplied path.
+func (c *FsPackageCache) usage(pkg, except string) int64 {
+	fis, err := afero.ReadDir(c.fs, filepath.Join(c.dir, pkg))
+	if err != nil {
+		return 0
+	}
+	var n int64
+	for _, fi := range fis {
+		if fi.IsDir() || filepath.Join(c.dir, pkg, fi.Name()) == except {
+			continue
+		}
+		n += fi.Size()
+	}
+	return n
+}
+
+// record the size of the content cached for the supplied package.
+func (c *FsPackageCache) record(pkg string) {
+	c.metrics.RecordCacheUsage(pkg, c.usage(pkg, ""))
+}
+
+// A quotaWriter returns an error rather than write more than its remaining
+// bytes.
+type quotaWriter struct {
+	w         io.Writer
+	remaining int64
+	exceeded  bool
+}
+
+func (q *quotaWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > q.remaining {
+		q.exceeded = true
+		return 0, io.ErrShortWrite
+	}
+	q.remaining -= int64(len(p))
+	return q.w.Write(p)
+}
+
+// A verifyingReadCloser returns an error once its content has been read if
+// the content doesn't have the expected digest, or can't be decompressed.
+type verifyingReadCloser struct {
+	rc      io.ReadCloser
+	h       hash.Hash
+	want    string
+	corrupt func(err error) error
+
+	// err is returned by every read once the content is found to be
+	// corrupt.
+	err error
+}
+
+func (v *verifyingReadCloser) Read(p []byte) (int, error) {
+	if v.err != nil {
+		return 0, v.err
+	}
+	n, err := v.rc.Read(p)
+	_, _ = v.h.Write(p[:n])
+	switch {
+	case errors.Is(err, gzip.ErrChecksum), errors.Is(err, gzip.ErrHeader), errors.As(err, new(flate.CorruptInputError)):
+		v.err = v.corrupt(err)
+		return n, v.err
+	case !errors.Is(err, io.EOF):
+		return n, err
+	}
+	if got := digestPrefix + hex.EncodeToString(v.h.Sum(nil)); got != v.want {
+		v.err = v.corrupt(errors.Errorf(errFmtDigestMismatch, got, v.want))
+		return n, v.err
+	}
+	return n, err
+}
+
+func (v *verifyingReadCloser) Close() error {
+	return v.rc.Close()
 }
 
 // NopCache is a cache implementation that does not store anything and always
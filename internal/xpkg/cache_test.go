@@ -27,6 +27,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/spf13/afero"
 
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
 )
 
@@ -157,6 +158,21 @@ func TestStore(t *testing.T) {
 				id:    "exists-1234567",
 			},
 		},
+		"SuccessScoped": {
+			reason: "Should not return an error if package is created in the directory of its package.",
+			args: args{
+				cache: NewFsPackageCache("/cache", fs, WithPackageQuota(1024)),
+				id:    ScopedCacheID("provider-aws", "provider-aws-1234567"),
+			},
+		},
+		"ErrQuotaExceeded": {
+			reason: "Should return an error if package contents would exceed the quota of their package.",
+			args: args{
+				cache: NewFsPackageCache("/cache", fs, WithPackageQuota(1)),
+				id:    ScopedCacheID("provider-gcp", "provider-gcp-1234567"),
+			},
+			want: errors.Errorf(errFmtQuotaExceeded, "provider-gcp", 1),
+		},
 		"ErrFailedCreate": {
 			reason: "Should return an error if file creation fails.",
 			args: args{
@@ -225,3 +241,121 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+type cacheUsage map[string]int64
+
+func (u cacheUsage) RecordCacheUsage(pkg string, bytes int64) { u[pkg] = bytes }
+func (u cacheUsage) RecordCacheCorruption()                   {}
+
+func TestScopedCache(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	usage := cacheUsage{}
+	c := NewFsPackageCache("/cache", fs, WithPackageQuota(1024), WithCacheMetricsRecorder(usage))
+
+	a := ScopedCacheID("provider-aws", "provider-aws-1234567")
+	b := ScopedCacheID("provider-aws", "provider-aws-7654321")
+	if err := c.Store(a, io.NopCloser(bytes.NewBufferString("cool"))); err != nil {
+		t.Fatalf("Store(...): %s", err)
+	}
+	if err := c.Store(b, io.NopCloser(bytes.NewBufferString("cooler"))); err != nil {
+		t.Fatalf("Store(...): %s", err)
+	}
+	if !c.Has(a) || !c.Has(b) {
+		t.Errorf("Has(...): want cached contents of both revisions")
+	}
+	if usage["provider-aws"] == 0 {
+		t.Errorf("RecordCacheUsage(...): want usage of package to be recorded")
+	}
+
+	// Contents that would exceed the quota are not left in the cache.
+	big := NewFsPackageCache("/cache", fs, WithPackageQuota(usage["provider-aws"]+1), WithCacheMetricsRecorder(usage))
+	huge := ScopedCacheID("provider-aws", "provider-aws-huge")
+	if err := big.Store(huge, io.NopCloser(bytes.NewBufferString("huge"))); err == nil {
+		t.Errorf("Store(...): want quota exceeded error")
+	}
+	if big.Has(huge) {
+		t.Errorf("Has(...): want contents that exceeded the quota to be removed")
+	}
+
+	// The directory of a package is removed with the last of its contents.
+	if err := c.Delete(a); err != nil {
+		t.Fatalf("Delete(...): %s", err)
+	}
+	if err := c.Delete(b); err != nil {
+		t.Fatalf("Delete(...): %s", err)
+	}
+	if _, err := fs.Stat("/cache/provider-aws"); !os.IsNotExist(err) {
+		t.Errorf("Delete(...): want package directory to be removed, got error %v", err)
+	}
+	if diff := cmp.Diff(cacheUsage{"provider-aws": 0}, usage); diff != "" {
+		t.Errorf("RecordCacheUsage(...): -want, +got:\n%s", diff)
+	}
+}
+
+type cacheCorruptions struct{ count int }
+
+func (c *cacheCorruptions) RecordCacheUsage(string, int64) {}
+func (c *cacheCorruptions) RecordCacheCorruption()         { c.count++ }
+
+func TestCorruptionDetectingCache(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	corruptions := &cacheCorruptions{}
+	c := NewFsPackageCache("/cache", fs, WithCorruptionDetection(), WithCacheMetricsRecorder(corruptions))
+
+	// gz returns the supplied content, compressed.
+	gz := func(content string) []byte {
+		b := &bytes.Buffer{}
+		w := gzip.NewWriter(b)
+		_, _ = w.Write([]byte(content))
+		_ = w.Close()
+		return b.Bytes()
+	}
+	read := func(id string) (string, error) {
+		rc, err := c.Get(id)
+		if err != nil {
+			return "", err
+		}
+		defer rc.Close() //nolint:errcheck
+		b, err := io.ReadAll(rc)
+		return string(b), err
+	}
+
+	if err := c.Store("intact", io.NopCloser(bytes.NewBufferString("cool"))); err != nil {
+		t.Fatalf("Store(...): %s", err)
+	}
+	if got, err := read("intact"); err != nil || got != "cool" {
+		t.Errorf("Get(...): want intact content to be read, got %q and error %v", got, err)
+	}
+
+	if err := c.Store("tampered", io.NopCloser(bytes.NewBufferString("cool"))); err != nil {
+		t.Fatalf("Store(...): %s", err)
+	}
+	_ = afero.WriteFile(fs, "/cache/tampered.gz", gz("evil"), 0644)
+	if _, err := read("tampered"); !IsCorrupt(err) {
+		t.Errorf("Get(...): want tampered content to be corrupt, got error %v", err)
+	}
+
+	if err := c.Store("rotten", io.NopCloser(bytes.NewBufferString("cool"))); err != nil {
+		t.Fatalf("Store(...): %s", err)
+	}
+	_ = afero.WriteFile(fs, "/cache/rotten.gz", []byte("not gzip"), 0644)
+	if _, err := read("rotten"); !IsCorrupt(err) {
+		t.Errorf("Get(...): want undecompressable content to be corrupt, got error %v", err)
+	}
+
+	_ = afero.WriteFile(fs, "/cache/unverified.gz", gz("cool"), 0644)
+	if got, err := read("unverified"); err != nil || got != "cool" {
+		t.Errorf("Get(...): want content without a digest to be read, got %q and error %v", got, err)
+	}
+
+	if diff := cmp.Diff(2, corruptions.count); diff != "" {
+		t.Errorf("RecordCacheCorruption(...): -want, +got:\n%s", diff)
+	}
+
+	if err := c.Delete("tampered"); err != nil {
+		t.Fatalf("Delete(...): %s", err)
+	}
+	if _, err := fs.Stat("/cache/tampered.sha256"); !os.IsNotExist(err) {
+		t.Errorf("Delete(...): want digest to be removed, got error %v", err)
+	}
+}
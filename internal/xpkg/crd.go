@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+const (
+	errFmtUpconvertCRD = "cannot convert apiextensions.k8s.io/v1beta1 CustomResourceDefinition %q to apiextensions.k8s.io/v1"
+
+	typeObject = "object"
+	typeArray  = "array"
+)
+
+// UpconvertCRDs replaces any apiextensions.k8s.io/v1beta1 CRDs in the supplied
+// objects with their apiextensions.k8s.io/v1 equivalents, and returns the names
+// of the CRDs it converted. API servers no longer serve v1beta1 CRDs, but many
+// packages were built before v1 CRDs were common.
+func UpconvertCRDs(objs []runtime.Object) ([]string, error) {
+	converted := make([]string, 0)
+	for i, o := range objs {
+		in, ok := o.(*extv1beta1.CustomResourceDefinition)
+		if !ok {
+			continue
+		}
+		out, err := UpconvertCRD(in)
+		if err != nil {
+			return nil, errors.Wrapf(err, errFmtUpconvertCRD, in.GetName())
+		}
+		objs[i] = out
+		converted = append(converted, in.GetName())
+	}
+	return converted, nil
+}
+
+// UpconvertCRD converts the supplied apiextensions.k8s.io/v1beta1 CRD to an
+// apiextensions.k8s.io/v1 CRD. A v1 CRD must have a structural schema for each
+// version, so versions without a schema are given one that preserves unknown
+// fields, and schemas that omit the type of an object or array have it
+// inferred. Unknown fields that the v1beta1 CRD preserved are still preserved.
+func UpconvertCRD(in *extv1beta1.CustomResourceDefinition) (*extv1.CustomResourceDefinition, error) {
+	in = in.DeepCopy()
+	extv1beta1.SetObjectDefaults_CustomResourceDefinition(in)
+
+	internal := &apiextensions.CustomResourceDefinition{}
+	if err := extv1beta1.Convert_v1beta1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(in, internal, nil); err != nil {
+		return nil, err
+	}
+	out := &extv1.CustomResourceDefinition{}
+	if err := extv1.Convert_apiextensions_CustomResourceDefinition_To_v1_CustomResourceDefinition(internal, out, nil); err != nil {
+		return nil, err
+	}
+	out.SetGroupVersionKind(extv1.SchemeGroupVersion.WithKind("CustomResourceDefinition"))
+
+	// A v1 CRD may not preserve unknown fields at the top level, so we
+	// preserve them at the root of each version's schema instead.
+	preserve := out.Spec.PreserveUnknownFields
+	out.Spec.PreserveUnknownFields = false
+
+	for i := range out.Spec.Versions {
+		v := &out.Spec.Versions[i]
+
+		// v1beta1 CRDs specify one schema for all versions, which the
+		// conversion shares between them.
+		s := &extv1.JSONSchemaProps{Type: typeObject, XPreserveUnknownFields: pointer.Bool(true)}
+		if v.Schema != nil && v.Schema.OpenAPIV3Schema != nil {
+			s = v.Schema.OpenAPIV3Schema.DeepCopy()
+		}
+		inferTypes(s)
+		if preserve {
+			s.XPreserveUnknownFields = pointer.Bool(true)
+		}
+		v.Schema = &extv1.CustomResourceValidation{OpenAPIV3Schema: s}
+	}
+
+	// Defaulting the v1beta1 CRD populated its status, which is the API
+	// server's to set.
+	out.Status = extv1.CustomResourceDefinitionStatus{}
+	return out, nil
+}
+
+// inferTypes infers the type of any schema in the supplied schema that has
+// properties or items but no type. The root of a CRD's schema is always an
+// object.
+func inferTypes(root *extv1.JSONSchemaProps) {
+	if root.Type == "" {
+		root.Type = typeObject
+	}
+	walkSchema(root)
+}
+
+func walkSchema(s *extv1.JSONSchemaProps) {
+	if s.Type == "" {
+		switch {
+		case len(s.Properties) > 0 || s.AdditionalProperties != nil:
+			s.Type = typeObject
+		case s.Items != nil:
+			s.Type = typeArray
+		}
+	}
+	for k, p := range s.Properties {
+		walkSchema(&p)
+		s.Properties[k] = p
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+		walkSchema(s.AdditionalProperties.Schema)
+	}
+	if s.Items != nil {
+		if s.Items.Schema != nil {
+			walkSchema(s.Items.Schema)
+		}
+		for i := range s.Items.JSONSchemas {
+			walkSchema(&s.Items.JSONSchemas[i])
+		}
+	}
+}
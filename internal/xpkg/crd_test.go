@@ -0,0 +1,219 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	extv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+)
+
+func TestUpconvertCRD(t *testing.T) {
+	names := extv1beta1.CustomResourceDefinitionNames{Plural: "widgets", Kind: "Widget"}
+	wantNames := extv1.CustomResourceDefinitionNames{Plural: "widgets", Singular: "widget", Kind: "Widget", ListKind: "WidgetList"}
+	typeMeta := metav1.TypeMeta{APIVersion: extv1.SchemeGroupVersion.String(), Kind: "CustomResourceDefinition"}
+
+	type want struct {
+		crd *extv1.CustomResourceDefinition
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		crd    *extv1beta1.CustomResourceDefinition
+		want   want
+	}{
+		"NoSchema": {
+			reason: "A CRD without a schema should preserve unknown fields at the root of the schema of its only version.",
+			crd: &extv1beta1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.org"},
+				Spec: extv1beta1.CustomResourceDefinitionSpec{
+					Group:   "example.org",
+					Version: "v1alpha1",
+					Names:   names,
+					Scope:   extv1beta1.ClusterScoped,
+				},
+			},
+			want: want{
+				crd: &extv1.CustomResourceDefinition{
+					TypeMeta:   typeMeta,
+					ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.org"},
+					Spec: extv1.CustomResourceDefinitionSpec{
+						Group: "example.org",
+						Names: wantNames,
+						Scope: extv1.ClusterScoped,
+						Versions: []extv1.CustomResourceDefinitionVersion{{
+							Name:    "v1alpha1",
+							Served:  true,
+							Storage: true,
+							Schema: &extv1.CustomResourceValidation{
+								OpenAPIV3Schema: &extv1.JSONSchemaProps{Type: typeObject, XPreserveUnknownFields: pointer.Bool(true)},
+							},
+						}},
+						Conversion: &extv1.CustomResourceConversion{Strategy: extv1.NoneConverter},
+					},
+				},
+			},
+		},
+		"InferTypes": {
+			reason: "A CRD whose schema omits the type of objects and arrays should have them inferred, and its schema should be copied to each version.",
+			crd: &extv1beta1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.org"},
+				Spec: extv1beta1.CustomResourceDefinitionSpec{
+					Group: "example.org",
+					Names: names,
+					Scope: extv1beta1.NamespaceScoped,
+					Versions: []extv1beta1.CustomResourceDefinitionVersion{
+						{Name: "v1beta1", Served: true, Storage: true},
+						{Name: "v1alpha1", Served: true},
+					},
+					Validation: &extv1beta1.CustomResourceValidation{
+						OpenAPIV3Schema: &extv1beta1.JSONSchemaProps{
+							Properties: map[string]extv1beta1.JSONSchemaProps{
+								"spec": {
+									Properties: map[string]extv1beta1.JSONSchemaProps{
+										"size":  {Type: "integer"},
+										"parts": {Items: &extv1beta1.JSONSchemaPropsOrArray{Schema: &extv1beta1.JSONSchemaProps{Type: "string"}}},
+									},
+								},
+							},
+						},
+					},
+					PreserveUnknownFields: pointer.Bool(false),
+				},
+			},
+			want: want{
+				crd: func() *extv1.CustomResourceDefinition {
+					s := &extv1.CustomResourceValidation{
+						OpenAPIV3Schema: &extv1.JSONSchemaProps{
+							Type: typeObject,
+							Properties: map[string]extv1.JSONSchemaProps{
+								"spec": {
+									Type: typeObject,
+									Properties: map[string]extv1.JSONSchemaProps{
+										"size":  {Type: "integer"},
+										"parts": {Type: typeArray, Items: &extv1.JSONSchemaPropsOrArray{Schema: &extv1.JSONSchemaProps{Type: "string"}}},
+									},
+								},
+							},
+						},
+					}
+					return &extv1.CustomResourceDefinition{
+						TypeMeta:   typeMeta,
+						ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.org"},
+						Spec: extv1.CustomResourceDefinitionSpec{
+							Group: "example.org",
+							Names: wantNames,
+							Scope: extv1.NamespaceScoped,
+							Versions: []extv1.CustomResourceDefinitionVersion{
+								{Name: "v1beta1", Served: true, Storage: true, Schema: s},
+								{Name: "v1alpha1", Served: true, Schema: s},
+							},
+							Conversion: &extv1.CustomResourceConversion{Strategy: extv1.NoneConverter},
+						},
+					}
+				}(),
+			},
+		},
+		"PreserveUnknownFields": {
+			reason: "A CRD that preserves unknown fields should preserve them at the root of its schema instead.",
+			crd: &extv1beta1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.org"},
+				Spec: extv1beta1.CustomResourceDefinitionSpec{
+					Group:   "example.org",
+					Version: "v1alpha1",
+					Names:   names,
+					Scope:   extv1beta1.ClusterScoped,
+					Validation: &extv1beta1.CustomResourceValidation{
+						OpenAPIV3Schema: &extv1beta1.JSONSchemaProps{Type: typeObject},
+					},
+					PreserveUnknownFields: pointer.Bool(true),
+				},
+			},
+			want: want{
+				crd: &extv1.CustomResourceDefinition{
+					TypeMeta:   typeMeta,
+					ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.org"},
+					Spec: extv1.CustomResourceDefinitionSpec{
+						Group: "example.org",
+						Names: wantNames,
+						Scope: extv1.ClusterScoped,
+						Versions: []extv1.CustomResourceDefinitionVersion{{
+							Name:    "v1alpha1",
+							Served:  true,
+							Storage: true,
+							Schema: &extv1.CustomResourceValidation{
+								OpenAPIV3Schema: &extv1.JSONSchemaProps{Type: typeObject, XPreserveUnknownFields: pointer.Bool(true)},
+							},
+						}},
+						Conversion: &extv1.CustomResourceConversion{Strategy: extv1.NoneConverter},
+					},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := UpconvertCRD(tc.crd)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nUpconvertCRD(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.crd, got); diff != "" {
+				t.Errorf("\n%s\nUpconvertCRD(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpconvertCRDs(t *testing.T) {
+	xrd := &v1.CompositeResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "xwidgets.example.org"}}
+	crd := &extv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: "gadgets.example.org"}}
+	beta := &extv1beta1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "widgets.example.org"},
+		Spec: extv1beta1.CustomResourceDefinitionSpec{
+			Group:   "example.org",
+			Version: "v1alpha1",
+			Names:   extv1beta1.CustomResourceDefinitionNames{Plural: "widgets", Kind: "Widget"},
+			Scope:   extv1beta1.ClusterScoped,
+		},
+	}
+
+	objs := []runtime.Object{xrd, beta, crd}
+	converted, err := UpconvertCRDs(objs)
+	if err != nil {
+		t.Fatalf("UpconvertCRDs(...): unexpected error: %s", err)
+	}
+	if diff := cmp.Diff([]string{"widgets.example.org"}, converted); diff != "" {
+		t.Errorf("UpconvertCRDs(...): -want converted, +got converted:\n%s", diff)
+	}
+	if objs[0] != xrd || objs[2] != crd {
+		t.Errorf("UpconvertCRDs(...): objects other than v1beta1 CRDs should not be replaced")
+	}
+	if _, ok := objs[1].(*extv1.CustomResourceDefinition); !ok {
+		t.Errorf("UpconvertCRDs(...): want v1beta1 CRD replaced with v1 CRD, got %T", objs[1])
+	}
+}
@@ -17,16 +17,22 @@ limitations under the License.
 package xpkg
 
 import (
-	"github.com/Masterminds/semver"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
 	admv1 "k8s.io/api/admissionregistration/v1"
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	extv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
 	"github.com/crossplane/crossplane-runtime/pkg/parser"
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	pkgv1 "github.com/crossplane/crossplane/apis/pkg/v1"
 	"github.com/crossplane/crossplane/internal/version"
 )
 
@@ -42,23 +48,84 @@ const (
 	errNotComposition                    = "object is not a Composition"
 	errBadConstraints                    = "package version constraints are poorly formatted"
 	errCrossplaneIncompatibleFmt         = "package is not compatible with Crossplane version (%s)"
+	errFmtBadChannel                     = "package channel %q is not one of stable, beta, or edge"
+	errFmtChannelNotPermitted            = "package declares channel %q, which is less stable than pinned channel %q"
+	errFmtDuplicateObjects               = "package contains more than one of %s"
+	errFmtCRDName                        = "CRD must be named %q"
+	errFmtCRDKindCase                    = "CRD kind %q should be UpperCamelCase"
+)
+
+// IDs of the lint rules that packages are checked against.
+const (
+	RuleOneMeta            = "one-meta"
+	RuleMetaType           = "meta-type"
+	RuleValidSemver        = "valid-semver"
+	RuleValidChannel       = "valid-channel"
+	RuleObjectType         = "object-type"
+	RuleNoDuplicateObjects = "no-duplicate-objects"
+	RuleCRDName            = "crd-name"
+	RuleCRDKindCase        = "crd-kind-case"
 )
 
 // NewProviderLinter is a convenience function for creating a package linter for
-// providers.
-func NewProviderLinter() parser.Linter {
-	return parser.NewPackageLinter(parser.PackageLinterFns(OneMeta), parser.ObjectLinterFns(IsProvider, PackageValidSemver),
-		parser.ObjectLinterFns(parser.Or(
-			IsCRD,
-			IsValidatingWebhookConfiguration,
-			IsMutatingWebhookConfiguration,
-		)))
+// providers. Packages are also checked against any supplied rules, which
+// replace any built in rule with the same ID.
+func NewProviderLinter(rules ...Rule) parser.Linter {
+	return NewRuleLinter(withRules([]Rule{
+		{ID: RuleOneMeta, Severity: SeverityError, Required: true, Check: OneMeta},
+		required(MetaRule(RuleMetaType, SeverityError, IsProvider)),
+		MetaRule(RuleValidSemver, SeverityError, PackageValidSemver),
+		MetaRule(RuleValidChannel, SeverityError, PackageValidChannel),
+		required(ObjectRule(RuleObjectType, SeverityError, IsProviderObject)),
+		{ID: RuleNoDuplicateObjects, Severity: SeverityWarning, Check: NoDuplicateObjects},
+		ObjectRule(RuleCRDName, SeverityError, CRDNamedForPluralAndGroup),
+		ObjectRule(RuleCRDKindCase, SeverityWarning, CRDKindUpperCamelCase),
+	}, rules)...)
 }
 
 // NewConfigurationLinter is a convenience function for creating a package linter for
-// configurations.
-func NewConfigurationLinter() parser.Linter {
-	return parser.NewPackageLinter(parser.PackageLinterFns(OneMeta), parser.ObjectLinterFns(IsConfiguration, PackageValidSemver), parser.ObjectLinterFns(parser.Or(IsXRD, IsComposition)))
+// configurations. Packages are also checked against any supplied rules, which
+// replace any built in rule with the same ID.
+func NewConfigurationLinter(rules ...Rule) parser.Linter {
+	return NewRuleLinter(withRules([]Rule{
+		{ID: RuleOneMeta, Severity: SeverityError, Required: true, Check: OneMeta},
+		required(MetaRule(RuleMetaType, SeverityError, IsConfiguration)),
+		MetaRule(RuleValidSemver, SeverityError, PackageValidSemver),
+		MetaRule(RuleValidChannel, SeverityError, PackageValidChannel),
+		required(ObjectRule(RuleObjectType, SeverityError, IsConfigurationObject)),
+		{ID: RuleNoDuplicateObjects, Severity: SeverityWarning, Check: NoDuplicateObjects},
+	}, rules)...)
+}
+
+// withRules returns the supplied built in rules, replacing any that have the
+// same ID as one of the supplied additional rules, followed by the remaining
+// additional rules.
+func withRules(builtin, rules []Rule) []Rule {
+	replace := make(map[string]Rule, len(rules))
+	for _, r := range rules {
+		replace[r.ID] = r
+	}
+	out := make([]Rule, 0, len(builtin)+len(rules))
+	for _, r := range builtin {
+		if rr, ok := replace[r.ID]; ok {
+			out = append(out, rr)
+			delete(replace, r.ID)
+			continue
+		}
+		out = append(out, r)
+	}
+	for _, r := range rules {
+		if _, ok := replace[r.ID]; ok {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// required returns the supplied rule, which can't be disabled.
+func required(r Rule) Rule {
+	r.Required = true
+	return r
 }
 
 // OneMeta checks that there is only one meta object in the package.
@@ -120,12 +187,57 @@ func PackageValidSemver(o runtime.Object) error {
 	if p.GetCrossplaneConstraints() == nil {
 		return nil
 	}
-	if _, err := semver.NewConstraint(p.GetCrossplaneConstraints().Version); err != nil {
+	if _, err := version.ParseConstraints(p.GetCrossplaneConstraints().Version); err != nil {
 		return errors.Wrap(err, errBadConstraints)
 	}
 	return nil
 }
 
+// PackageValidChannel checks that the package declares a valid release channel,
+// if any.
+func PackageValidChannel(o runtime.Object) error {
+	p, ok := TryConvertToPkg(o, &pkgmetav1.Provider{}, &pkgmetav1.Configuration{})
+	if !ok {
+		return errors.New(errNotMeta)
+	}
+
+	if p.GetChannel() == nil {
+		return nil
+	}
+	if _, ok := channelStability[pkgv1.PackageChannel(*p.GetChannel())]; !ok {
+		return errors.Errorf(errFmtBadChannel, *p.GetChannel())
+	}
+	return nil
+}
+
+// PackageInChannel checks that the package declares a release channel that
+// may be installed by a package pinned to the supplied channel.
+func PackageInChannel(ch pkgv1.PackageChannel) parser.ObjectLinterFn {
+	return func(o runtime.Object) error {
+		p, ok := TryConvertToPkg(o, &pkgmetav1.Provider{}, &pkgmetav1.Configuration{})
+		if !ok {
+			return errors.New(errNotMeta)
+		}
+
+		if !ChannelIncludes(ch, p.GetChannel()) {
+			return errors.Errorf(errFmtChannelNotPermitted, *p.GetChannel(), ch)
+		}
+		return nil
+	}
+}
+
+// IsProviderObject checks that an object may be included in a provider
+// package.
+func IsProviderObject(o runtime.Object) error {
+	return parser.Or(IsCRD, IsValidatingWebhookConfiguration, IsMutatingWebhookConfiguration)(o)
+}
+
+// IsConfigurationObject checks that an object may be included in a
+// configuration package.
+func IsConfigurationObject(o runtime.Object) error {
+	return parser.Or(IsXRD, IsComposition)(o)
+}
+
 // IsCRD checks that an object is a CustomResourceDefinition.
 func IsCRD(o runtime.Object) error {
 	switch o.(type) {
@@ -167,3 +279,64 @@ func IsComposition(o runtime.Object) error {
 	}
 	return nil
 }
+
+// NoDuplicateObjects checks that a package doesn't contain more than one object
+// of the same kind, namespace, and name. Different versions of the same kind
+// are considered the same kind, because they would be installed as the same
+// object.
+func NoDuplicateObjects(pkg *parser.Package) error {
+	seen := map[string]int{}
+	var dupes []string
+	for _, o := range pkg.GetObjects() {
+		key := describe(o)
+		if m, err := kmeta.Accessor(o); err == nil && m.GetNamespace() != "" {
+			key = fmt.Sprintf("%s in namespace %q", key, m.GetNamespace())
+		}
+		seen[key]++
+		if seen[key] == 2 {
+			dupes = append(dupes, key)
+		}
+	}
+	if len(dupes) > 0 {
+		return errors.Errorf(errFmtDuplicateObjects, strings.Join(dupes, ", "))
+	}
+	return nil
+}
+
+// CRDNamedForPluralAndGroup checks that a CustomResourceDefinition is named
+// <plural>.<group>, which the API server requires. Objects that aren't CRDs
+// pass.
+func CRDNamedForPluralAndGroup(o runtime.Object) error {
+	var name, want string
+	switch crd := o.(type) {
+	case *extv1.CustomResourceDefinition:
+		name, want = crd.GetName(), crd.Spec.Names.Plural+"."+crd.Spec.Group
+	case *extv1beta1.CustomResourceDefinition:
+		name, want = crd.GetName(), crd.Spec.Names.Plural+"."+crd.Spec.Group
+	default:
+		return nil
+	}
+	if name != want {
+		return errors.Errorf(errFmtCRDName, want)
+	}
+	return nil
+}
+
+// CRDKindUpperCamelCase checks that the kind of a CustomResourceDefinition
+// starts with an upper case letter, as is conventional. Objects that aren't
+// CRDs pass.
+func CRDKindUpperCamelCase(o runtime.Object) error {
+	var kind string
+	switch crd := o.(type) {
+	case *extv1.CustomResourceDefinition:
+		kind = crd.Spec.Names.Kind
+	case *extv1beta1.CustomResourceDefinition:
+		kind = crd.Spec.Names.Kind
+	default:
+		return nil
+	}
+	if r, _ := utf8.DecodeRuneInString(kind); !unicode.IsUpper(r) {
+		return errors.Errorf(errFmtCRDKindCase, kind)
+	}
+	return nil
+}
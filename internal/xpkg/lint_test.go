@@ -24,8 +24,11 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/yaml"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
@@ -34,6 +37,7 @@ import (
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
 	pkgmetav1alpha1 "github.com/crossplane/crossplane/apis/pkg/meta/v1alpha1"
+	pkgv1 "github.com/crossplane/crossplane/apis/pkg/v1"
 	"github.com/crossplane/crossplane/internal/version"
 	"github.com/crossplane/crossplane/internal/version/fake"
 )
@@ -353,6 +357,101 @@ func TestPackageValidSemver(t *testing.T) {
 	}
 }
 
+func TestPackageValidChannel(t *testing.T) {
+	type args struct {
+		obj runtime.Object
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		err    error
+	}{
+		"NoChannel": {
+			reason: "Should not return error if no channel is declared.",
+			args: args{
+				obj: &pkgmetav1.Configuration{},
+			},
+		},
+		"Valid": {
+			reason: "Should not return error if channel is valid.",
+			args: args{
+				obj: &pkgmetav1.Provider{
+					Spec: pkgmetav1.ProviderSpec{
+						MetaSpec: pkgmetav1.MetaSpec{Channel: pointer.StringPtr("beta")},
+					},
+				},
+			},
+		},
+		"ErrInvalidChannel": {
+			reason: "Should return error if channel is invalid.",
+			args: args{
+				obj: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{Channel: pointer.StringPtr("nightly")},
+					},
+				},
+			},
+			err: errors.Errorf(errFmtBadChannel, "nightly"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := PackageValidChannel(tc.args.obj)
+
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPackageValidChannel(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPackageInChannel(t *testing.T) {
+	type args struct {
+		channel pkgv1.PackageChannel
+		obj     runtime.Object
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		err    error
+	}{
+		"InChannel": {
+			reason: "Should not return error if the package's channel is included in the pinned channel.",
+			args: args{
+				channel: pkgv1.PackageChannelEdge,
+				obj: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{Channel: pointer.StringPtr("beta")},
+					},
+				},
+			},
+		},
+		"ErrNotInChannel": {
+			reason: "Should return error if the package's channel is less stable than the pinned channel.",
+			args: args{
+				channel: pkgv1.PackageChannelStable,
+				obj: &pkgmetav1.Configuration{
+					Spec: pkgmetav1.ConfigurationSpec{
+						MetaSpec: pkgmetav1.MetaSpec{Channel: pointer.StringPtr("edge")},
+					},
+				},
+			},
+			err: errors.Errorf(errFmtChannelNotPermitted, "edge", pkgv1.PackageChannelStable),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := PackageInChannel(tc.args.channel)(tc.args.obj)
+
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPackageInChannel(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
 func TestIsCRD(t *testing.T) {
 	cases := map[string]struct {
 		reason string
@@ -440,3 +539,168 @@ func TestIsComposition(t *testing.T) {
 		})
 	}
 }
+
+func TestNoDuplicateObjects(t *testing.T) {
+	uniqueR := bytes.NewReader(bytes.Join([][]byte{v1ProvBytes, v1CRDBytes, v1XRDBytes}, []byte("\n---\n")))
+	unique, _ := p.Parse(context.TODO(), ioutil.NopCloser(uniqueR))
+	dupeR := bytes.NewReader(bytes.Join([][]byte{v1ProvBytes, v1CRDBytes, v1CRDBytes}, []byte("\n---\n")))
+	dupe, _ := p.Parse(context.TODO(), ioutil.NopCloser(dupeR))
+
+	cases := map[string]struct {
+		reason string
+		pkg    *parser.Package
+		err    error
+	}{
+		"Successful": {
+			reason: "Should not return error if no two objects share a kind and name.",
+			pkg:    unique,
+		},
+		"ErrDuplicate": {
+			reason: "Should return error if two objects share a kind and name.",
+			pkg:    dupe,
+			err:    errors.Errorf(errFmtDuplicateObjects, `CustomResourceDefinition "test"`),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := NoDuplicateObjects(tc.pkg)
+
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nNoDuplicateObjects(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCRDNamedForPluralAndGroup(t *testing.T) {
+	crd := func(name string) *extv1.CustomResourceDefinition {
+		return &extv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: extv1.CustomResourceDefinitionSpec{
+				Group: "example.org",
+				Names: extv1.CustomResourceDefinitionNames{Plural: "widgets", Kind: "Widget"},
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		obj    runtime.Object
+		err    error
+	}{
+		"Successful": {
+			reason: "Should not return error if CRD is named for its plural and group.",
+			obj:    crd("widgets.example.org"),
+		},
+		"NotCRD": {
+			reason: "Should not return error if object is not a CRD.",
+			obj:    v1Comp,
+		},
+		"ErrName": {
+			reason: "Should return error if CRD is not named for its plural and group.",
+			obj:    crd("widgets"),
+			err:    errors.Errorf(errFmtCRDName, "widgets.example.org"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := CRDNamedForPluralAndGroup(tc.obj)
+
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCRDNamedForPluralAndGroup(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCRDKindUpperCamelCase(t *testing.T) {
+	crd := func(kind string) *apiextensions.CustomResourceDefinition {
+		return &apiextensions.CustomResourceDefinition{
+			Spec: apiextensions.CustomResourceDefinitionSpec{
+				Names: apiextensions.CustomResourceDefinitionNames{Kind: kind},
+			},
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		obj    runtime.Object
+		err    error
+	}{
+		"Successful": {
+			reason: "Should not return error if CRD kind is UpperCamelCase.",
+			obj:    crd("Widget"),
+		},
+		"NotCRD": {
+			reason: "Should not return error if object is not a CRD.",
+			obj:    v1Comp,
+		},
+		"ErrKindCase": {
+			reason: "Should return error if CRD kind is not UpperCamelCase.",
+			obj:    crd("widget"),
+			err:    errors.Errorf(errFmtCRDKindCase, "widget"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := CRDKindUpperCamelCase(tc.obj)
+
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nCRDKindUpperCamelCase(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestWithRules(t *testing.T) {
+	type rule struct {
+		ID       string
+		Severity Severity
+	}
+	summarize := func(rules []Rule) []rule {
+		out := make([]rule, len(rules))
+		for i, r := range rules {
+			out[i] = rule{ID: r.ID, Severity: r.Severity}
+		}
+		return out
+	}
+
+	type args struct {
+		builtin []Rule
+		rules   []Rule
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   []rule
+	}{
+		"NoAdditionalRules": {
+			reason: "The built in rules should be returned unchanged.",
+			args: args{
+				builtin: []Rule{{ID: "a", Severity: SeverityWarning}, {ID: "b", Severity: SeverityError}},
+			},
+			want: []rule{{ID: "a", Severity: SeverityWarning}, {ID: "b", Severity: SeverityError}},
+		},
+		"ReplaceAndAppend": {
+			reason: "An additional rule should replace the built in rule with the same ID in place, and others should be appended.",
+			args: args{
+				builtin: []Rule{{ID: "a", Severity: SeverityWarning}, {ID: "b", Severity: SeverityError}},
+				rules:   []Rule{{ID: "c", Severity: SeverityWarning}, {ID: "a", Severity: SeverityError}},
+			},
+			want: []rule{{ID: "a", Severity: SeverityError}, {ID: "b", Severity: SeverityError}, {ID: "c", Severity: SeverityWarning}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := withRules(tc.args.builtin, tc.args.rules)
+
+			if diff := cmp.Diff(tc.want, summarize(got)); diff != "" {
+				t.Errorf("\n%s\nwithRules(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"strings"
+
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+)
+
+// OCI annotations that describe how an image was built. See
+// https://github.com/opencontainers/image-spec/blob/main/annotations.md
+const (
+	AnnotationOCIRevision = "org.opencontainers.image.revision"
+	AnnotationOCISource   = "org.opencontainers.image.source"
+	AnnotationOCIVersion  = "org.opencontainers.image.version"
+	AnnotationOCICreated  = "org.opencontainers.image.created"
+)
+
+// Metadata returns the human-facing metadata described by the supplied
+// package metadata annotations, or nil if they describe none.
+func Metadata(annotations map[string]string) *v1.PackageMetadata {
+	m := &v1.PackageMetadata{
+		Description: annotations[pkgmetav1.AnnotationDescription],
+		Source:      annotations[pkgmetav1.AnnotationSource],
+		IconURI:     annotations[pkgmetav1.AnnotationIconURI],
+		IconDigest:  annotations[pkgmetav1.AnnotationIconDigest],
+	}
+
+	for _, mt := range strings.Split(annotations[pkgmetav1.AnnotationMaintainer], ",") {
+		if mt = strings.TrimSpace(mt); mt != "" {
+			m.Maintainers = append(m.Maintainers, mt)
+		}
+	}
+
+	for k, d := range annotations {
+		lang := strings.TrimPrefix(k, pkgmetav1.AnnotationDescription+".")
+		if lang == k || lang == "" {
+			continue
+		}
+		if m.LocalizedDescriptions == nil {
+			m.LocalizedDescriptions = map[string]string{}
+		}
+		m.LocalizedDescriptions[lang] = d
+	}
+
+	if m.Description == "" && m.LocalizedDescriptions == nil && m.Maintainers == nil && m.Source == "" && m.IconURI == "" && m.IconDigest == "" {
+		return nil
+	}
+	return m
+}
+
+// BuildMetadata returns the build metadata described by the supplied OCI
+// annotations, or nil if they describe none.
+func BuildMetadata(annotations map[string]string) *v1.PackageBuildMetadata {
+	m := &v1.PackageBuildMetadata{
+		Revision: annotations[AnnotationOCIRevision],
+		Source:   annotations[AnnotationOCISource],
+		Version:  annotations[AnnotationOCIVersion],
+		Created:  annotations[AnnotationOCICreated],
+	}
+	if *m == (v1.PackageBuildMetadata{}) {
+		return nil
+	}
+	return m
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+)
+
+func TestMetadata(t *testing.T) {
+	cases := map[string]struct {
+		reason      string
+		annotations map[string]string
+		want        *v1.PackageMetadata
+	}{
+		"NoMetadata": {
+			reason:      "Annotations that describe no metadata should return nil.",
+			annotations: map[string]string{"author": "crossplane"},
+			want:        nil,
+		},
+		"AllMetadata": {
+			reason: "Each metadata annotation should be extracted.",
+			annotations: map[string]string{
+				"meta.crossplane.io/description":       "A test provider.",
+				"meta.crossplane.io/description.fr":    "Un fournisseur de test.",
+				"meta.crossplane.io/description.pt-BR": "Um provedor de teste.",
+				"meta.crossplane.io/maintainer":        "Crossplane Maintainers <info@crossplane.io>, Jane Doe",
+				"meta.crossplane.io/source":            "github.com/crossplane/provider-test",
+				"meta.crossplane.io/iconURI":           "https://example.org/icon.svg",
+				"meta.crossplane.io/iconDigest":        "sha256:ecc25c121431dfc7058754427f97c034ecde26d4aafa0da16d6a47b3e1296ad5",
+			},
+			want: &v1.PackageMetadata{
+				Description: "A test provider.",
+				LocalizedDescriptions: map[string]string{
+					"fr":    "Un fournisseur de test.",
+					"pt-BR": "Um provedor de teste.",
+				},
+				Maintainers: []string{"Crossplane Maintainers <info@crossplane.io>", "Jane Doe"},
+				Source:      "github.com/crossplane/provider-test",
+				IconURI:     "https://example.org/icon.svg",
+				IconDigest:  "sha256:ecc25c121431dfc7058754427f97c034ecde26d4aafa0da16d6a47b3e1296ad5",
+			},
+		},
+		"OnlyLocalizedDescription": {
+			reason: "A localized description should be extracted even if there is no default description.",
+			annotations: map[string]string{
+				"meta.crossplane.io/description.de": "Ein Testanbieter.",
+			},
+			want: &v1.PackageMetadata{
+				LocalizedDescriptions: map[string]string{"de": "Ein Testanbieter."},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Metadata(tc.annotations)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nMetadata(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBuildMetadata(t *testing.T) {
+	cases := map[string]struct {
+		reason      string
+		annotations map[string]string
+		want        *v1.PackageBuildMetadata
+	}{
+		"NoMetadata": {
+			reason:      "Annotations that describe no build metadata should return nil.",
+			annotations: map[string]string{"org.opencontainers.image.authors": "crossplane"},
+			want:        nil,
+		},
+		"AllMetadata": {
+			reason: "Each build metadata annotation should be extracted.",
+			annotations: map[string]string{
+				"org.opencontainers.image.revision": "a1b2c3d",
+				"org.opencontainers.image.source":   "https://github.com/crossplane/provider-test",
+				"org.opencontainers.image.version":  "v0.1.0",
+				"org.opencontainers.image.created":  "2022-01-01T00:00:00Z",
+			},
+			want: &v1.PackageBuildMetadata{
+				Revision: "a1b2c3d",
+				Source:   "https://github.com/crossplane/provider-test",
+				Version:  "v0.1.0",
+				Created:  "2022-01-01T00:00:00Z",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := BuildMetadata(tc.annotations)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nBuildMetadata(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -24,6 +24,8 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/spf13/afero"
 	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
 )
 
 const (
@@ -42,6 +44,19 @@ const (
 
 	// XpkgMatchPattern is the match pattern for identifying compiled Crossplane packages.
 	XpkgMatchPattern string = "*" + XpkgExtension
+
+	// LocalSourcePrefix prefixes the source of a package whose contents were
+	// loaded into the package cache rather than pulled from a registry, for
+	// example file://provider-example:dev-1234567.
+	LocalSourcePrefix string = "file://"
+
+	// LocalRegistry is the registry that local packages are considered to be
+	// from. A local package source may not specify any other registry.
+	LocalRegistry string = "local"
+)
+
+const (
+	errFmtLocalSourceRegistry = "local package source %q may not specify a registry"
 )
 
 const (
@@ -82,6 +97,37 @@ func ToDNSLabel(s string) string { // nolint:gocyclo
 	return strings.Trim(cut.String(), "-")
 }
 
+// IsLocalSource returns true if the supplied package source is local, i.e. if
+// its contents were loaded into the package cache rather than pulled from a
+// registry.
+func IsLocalSource(src string) bool {
+	return strings.HasPrefix(src, LocalSourcePrefix)
+}
+
+// ParseSource parses the supplied package source as an OCI image reference.
+// Sources that don't specify a registry are assumed to use the supplied
+// default registry, except for local sources which are always from
+// LocalRegistry.
+func ParseSource(src, defaultRegistry string) (name.Reference, error) {
+	if !IsLocalSource(src) {
+		return name.ParseReference(src, name.WithDefaultRegistry(defaultRegistry))
+	}
+	ref, err := name.ParseReference(strings.TrimPrefix(src, LocalSourcePrefix), name.WithDefaultRegistry(LocalRegistry))
+	if err != nil {
+		return nil, err
+	}
+	if ref.Context().RegistryStr() != LocalRegistry {
+		return nil, errors.Errorf(errFmtLocalSourceRegistry, src)
+	}
+	return ref, nil
+}
+
+// LocalCacheID returns the identifier under which the contents of the supplied
+// local package source are stored in the package cache.
+func LocalCacheID(src string) string {
+	return ToDNSLabel(strings.TrimPrefix(src, LocalSourcePrefix))
+}
+
 // BuildPath builds a path with the provided extension.
 func BuildPath(path, name, ext string) string {
 	full := filepath.Join(path, name)
@@ -21,6 +21,9 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
 )
 
 func TestFriendlyID(t *testing.T) {
@@ -231,3 +234,66 @@ func TestBuildPath(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSource(t *testing.T) {
+	type args struct {
+		src      string
+		registry string
+	}
+	type want struct {
+		name string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"RemoteSource": {
+			reason: "A remote source that doesn't specify a registry should use the default registry.",
+			args: args{
+				src:      "crossplane/provider-aws:v0.1.0",
+				registry: "registry.upbound.io",
+			},
+			want: want{
+				name: "registry.upbound.io/crossplane/provider-aws:v0.1.0",
+			},
+		},
+		"LocalSource": {
+			reason: "A local source should be from the local registry.",
+			args: args{
+				src:      "file://provider-aws:dev-1234567",
+				registry: "registry.upbound.io",
+			},
+			want: want{
+				name: "local/provider-aws:dev-1234567",
+			},
+		},
+		"LocalSourceWithRegistry": {
+			reason: "A local source may not specify a registry.",
+			args: args{
+				src:      "file://registry.upbound.io/crossplane/provider-aws:v0.1.0",
+				registry: "registry.upbound.io",
+			},
+			want: want{
+				err: errors.Errorf(errFmtLocalSourceRegistry, "file://registry.upbound.io/crossplane/provider-aws:v0.1.0"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ref, err := ParseSource(tc.args.src, tc.args.registry)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseSource(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.name, ref.Name()); diff != "" {
+				t.Errorf("\n%s\nParseSource(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
+)
+
+const (
+	errReadStream   = "cannot read package stream"
+	errBadJSON      = "cannot decode JSON object"
+	errFmtAnnotated = "%+v"
+)
+
+var (
+	yamlSeparator = []byte("---")
+	yamlDocEnd    = []byte("...")
+)
+
+// A StreamParserOption configures a StreamParser.
+type StreamParserOption func(p *StreamParser)
+
+// WithSource specifies the name of the file package streams are read from.
+// The name is used to report the position of objects that can't be parsed. The
+// default is the package stream file.
+func WithSource(name string) StreamParserOption {
+	return func(p *StreamParser) {
+		p.source = name
+	}
+}
+
+// A StreamParser parses a package stream. A stream is a series of documents
+// separated by '---'. Each document may contain one object encoded as YAML, or
+// one or more objects encoded as JSON. Objects that can't be parsed are
+// reported with their position in the stream.
+type StreamParser struct {
+	parser parser.Parser
+	source string
+}
+
+// NewStreamParser returns a StreamParser that identifies package metadata
+// using the supplied meta scheme, and package objects using the supplied
+// object scheme.
+func NewStreamParser(meta, obj parser.ObjectCreaterTyper, opts ...StreamParserOption) *StreamParser {
+	p := &StreamParser{parser: parser.New(meta, obj), source: StreamFile}
+	for _, fn := range opts {
+		fn(p)
+	}
+	return p
+}
+
+// A ParseError is an error parsing an object of a package stream.
+type ParseError struct {
+	// Index of the object in the stream, starting from zero.
+	Index int
+
+	// Source file and Line of the stream on which the object starts.
+	Source string
+	Line   int
+
+	// APIVersion, Kind, and Name of the object, if they could be determined.
+	APIVersion string
+	Kind       string
+	Name       string
+
+	// Layer is the digest of the package image layer the object was read
+	// from, if known.
+	Layer string
+
+	err error
+}
+
+// Error returns the position and identity of the object that could not be
+// parsed, and why.
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("%s:%d: object %d", e.Source, e.Line, e.Index)
+	switch {
+	case e.Kind != "" && e.Name != "":
+		msg = fmt.Sprintf("%s (%s %q)", msg, e.Kind, e.Name)
+	case e.Kind != "":
+		msg = fmt.Sprintf("%s (%s)", msg, e.Kind)
+	}
+	return fmt.Sprintf("%s: %s", msg, e.err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ParseError) Unwrap() error {
+	return e.err
+}
+
+// A LayerAnnotation annotates package content that was read from an image
+// layer.
+type LayerAnnotation struct {
+	// Digest of the layer.
+	Digest string
+}
+
+// A document is a single object read from a package stream.
+type document struct {
+	content []byte
+
+	// line of the stream on which the document starts.
+	line int
+
+	// annotation of the reader the document was read from, if any.
+	annotation interface{}
+}
+
+// Parse the supplied package stream.
+func (p *StreamParser) Parse(ctx context.Context, r io.ReadCloser) (*parser.Package, error) {
+	if r == nil {
+		return parser.NewPackage(), nil
+	}
+	defer func() { _ = r.Close() }()
+
+	docs, err := p.split(r)
+	if err != nil {
+		return parser.NewPackage(), err
+	}
+
+	// Parsing the stream as a whole is the common case, so we only parse
+	// documents individually to find the position of one we can't parse.
+	stream := make([][]byte, len(docs))
+	for i := range docs {
+		stream[i] = docs[i].content
+	}
+	pkg, err := p.parser.Parse(ctx, io.NopCloser(bytes.NewReader(bytes.Join(stream, []byte("\n---\n")))))
+	if err == nil {
+		return pkg, nil
+	}
+	for i, d := range docs {
+		if _, derr := p.parser.Parse(ctx, io.NopCloser(bytes.NewReader(d.content))); derr != nil {
+			return pkg, p.parseError(derr, i, d)
+		}
+	}
+	return pkg, err
+}
+
+// parseError returns an error parsing the supplied document, which is at the
+// supplied index of the stream.
+func (p *StreamParser) parseError(err error, index int, d document) error {
+	e := &ParseError{Index: index, Source: p.source, Line: d.line, err: err}
+
+	// The document may not even be valid YAML, so we identify its object on
+	// a best effort basis.
+	id := &struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+		Metadata   struct {
+			Name string `json:"name"`
+		} `json:"metadata"`
+	}{}
+	if yaml.Unmarshal(d.content, id) == nil {
+		e.APIVersion, e.Kind, e.Name = id.APIVersion, id.Kind, id.Metadata.Name
+	}
+
+	if l, ok := d.annotation.(LayerAnnotation); ok {
+		e.Layer = l.Digest
+		return e
+	}
+	return annotate(e, d.annotation)
+}
+
+// split the supplied stream into documents, each containing one object.
+func (p *StreamParser) split(r io.Reader) ([]document, error) {
+	ar := &annotatingReader{r: r}
+	br := bufio.NewReader(ar)
+
+	docs := []document{}
+	cur := &bytes.Buffer{}
+	var start int
+	var begin, offset int64
+	flush := func() error {
+		defer cur.Reset()
+		if isWhiteSpace(cur.Bytes()) {
+			return nil
+		}
+		content := append([]byte{}, cur.Bytes()...)
+		anno := ar.annotation(begin)
+		if bytes.TrimLeftFunc(content, unicode.IsSpace)[0] != '{' {
+			docs = append(docs, document{content: content, line: start, annotation: anno})
+			return nil
+		}
+		objs, err := splitJSON(content)
+		if err != nil {
+			return p.parseError(errors.Wrap(err.err, errBadJSON), len(docs)+len(objs), document{content: err.content, line: start + err.line - 1, annotation: anno})
+		}
+		for _, o := range objs {
+			docs = append(docs, document{content: o.content, line: start + o.line - 1, annotation: anno})
+		}
+		return nil
+	}
+
+	for line := 1; ; line++ {
+		l, err := br.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, errors.Wrap(err, errReadStream)
+		}
+		trimmed := bytes.TrimRightFunc(l, unicode.IsSpace)
+		switch {
+		case bytes.Equal(trimmed, yamlSeparator), bytes.Equal(trimmed, yamlDocEnd):
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		case isWhiteSpace(cur.Bytes()):
+			// Documents start at their first line of content.
+			cur.Reset()
+			cur.Write(l)
+			start, begin = line, offset
+		default:
+			cur.Write(l)
+		}
+		offset += int64(len(l))
+		if err == io.EOF {
+			break
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return docs, nil
+}
+
+// A jsonError is an error decoding the JSON object at a line of a document.
+type jsonError struct {
+	err     error
+	line    int
+	content []byte
+}
+
+// splitJSON splits the supplied JSON stream into one document per object. The
+// line of each document is relative to the start of the stream. If the stream
+// doesn't begin with a JSON object it is returned as a single document, which
+// may be a YAML flow mapping.
+func splitJSON(stream []byte) ([]document, *jsonError) {
+	docs := []document{}
+	dec := json.NewDecoder(bytes.NewReader(stream))
+	for {
+		offset := dec.InputOffset()
+		raw := json.RawMessage{}
+		err := dec.Decode(&raw)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil && len(docs) == 0 {
+			return []document{{content: stream, line: 1}}, nil
+		}
+		begin := offset + leadingSpace(stream[offset:])
+		if err != nil {
+			return docs, &jsonError{err: err, line: lineAt(stream, begin), content: stream[begin:]}
+		}
+		docs = append(docs, document{content: raw, line: lineAt(stream, begin)})
+	}
+}
+
+// lineAt returns the line of the supplied stream on which the supplied byte
+// offset falls.
+func lineAt(stream []byte, offset int64) int {
+	return bytes.Count(stream[:offset], []byte("\n")) + 1
+}
+
+// leadingSpace returns the number of whitespace bytes the supplied bytes begin
+// with.
+func leadingSpace(b []byte) int64 {
+	return int64(len(b) - len(bytes.TrimLeftFunc(b, unicode.IsSpace)))
+}
+
+// isWhiteSpace determines whether the supplied bytes are all whitespace.
+func isWhiteSpace(b []byte) bool {
+	return len(bytes.TrimSpace(b)) == 0
+}
+
+// annotate the supplied error with the supplied reader annotation, if any.
+func annotate(err error, anno interface{}) error {
+	if anno == nil {
+		return err
+	}
+	return errors.Wrapf(err, errFmtAnnotated, anno)
+}
+
+// An annotatingReader records the annotation of an annotated reader, such as a
+// parser.FsReadCloser, after each read. This allows the annotation of content
+// to be determined after it has been read.
+type annotatingReader struct {
+	r      io.Reader
+	offset int64
+	reads  []annotatedRead
+}
+
+// An annotatedRead is the annotation of the content read up to an offset.
+type annotatedRead struct {
+	end        int64
+	annotation interface{}
+}
+
+func (r *annotatingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.offset += int64(n)
+		if anno, ok := r.r.(parser.AnnotatedReadCloser); ok {
+			r.reads = append(r.reads, annotatedRead{end: r.offset, annotation: anno.Annotate()})
+		}
+	}
+	return n, err
+}
+
+// annotation returns the annotation of the content at the supplied offset, or
+// nil if the underlying reader isn't annotated.
+func (r *annotatingReader) annotation(offset int64) interface{} {
+	for _, rd := range r.reads {
+		if offset < rd.end {
+			return rd.annotation
+		}
+	}
+	return nil
+}
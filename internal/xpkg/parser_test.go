@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+var _ parser.Parser = &StreamParser{}
+
+func TestStreamParser(t *testing.T) {
+	meta := `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: test`
+	xrdYAML := `apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xtests.example.org`
+	xrdJSON := `{"apiVersion": "apiextensions.crossplane.io/v1", "kind": "CompositeResourceDefinition", "metadata": {"name": "xtests.example.org"}}`
+	compJSON := `{
+  "apiVersion": "apiextensions.crossplane.io/v1",
+  "kind": "Composition",
+  "metadata": {"name": "test"}
+}`
+	xrdFlow := `{apiVersion: apiextensions.crossplane.io/v1, kind: CompositeResourceDefinition, metadata: {name: xtests.example.org}}`
+	unknown := `apiVersion: example.org/v1
+kind: Unknown
+metadata:
+  name: test`
+
+	metaScheme, _ := BuildMetaScheme()
+	objScheme, _ := BuildObjectScheme()
+
+	// errParse returns the error the underlying parser returns when parsing
+	// the supplied document.
+	errParse := func(doc string) error {
+		_, err := parser.New(metaScheme, objScheme).Parse(context.Background(), io.NopCloser(strings.NewReader(doc)))
+		return err
+	}
+	errJSON := func(doc string) error {
+		return json.Unmarshal([]byte(doc), &json.RawMessage{})
+	}
+
+	type args struct {
+		opts   []StreamParserOption
+		stream string
+		anno   interface{}
+	}
+	type want struct {
+		meta    int
+		objects int
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"YAML": {
+			reason: "We should parse a stream of YAML documents.",
+			args:   args{stream: strings.Join([]string{meta, xrdYAML}, "\n---\n")},
+			want:   want{meta: 1, objects: 1},
+		},
+		"JSON": {
+			reason: "We should parse a document containing several JSON objects.",
+			args:   args{stream: xrdJSON + "\n" + compJSON},
+			want:   want{objects: 2},
+		},
+		"Mixed": {
+			reason: "We should parse a stream of YAML and JSON documents.",
+			args:   args{stream: strings.Join([]string{meta, xrdJSON + compJSON, xrdYAML}, "\n---\n")},
+			want:   want{meta: 1, objects: 3},
+		},
+		"FlowMapping": {
+			reason: "We should parse a YAML flow mapping that isn't valid JSON as YAML.",
+			args:   args{stream: xrdFlow},
+			want:   want{objects: 1},
+		},
+		"DocumentEnd": {
+			reason: "We should treat YAML document end markers, which separate files, as document separators.",
+			args:   args{stream: xrdJSON + "\n...\n\n---\n" + meta},
+			want:   want{meta: 1, objects: 1},
+		},
+		"ErrUnknownYAML": {
+			reason: "We should report the file and line of a YAML document we can't parse.",
+			args:   args{stream: strings.Join([]string{meta, unknown}, "\n---\n")},
+			want: want{err: &ParseError{
+				Index:      1,
+				Source:     StreamFile,
+				Line:       6,
+				APIVersion: "example.org/v1",
+				Kind:       "Unknown",
+				Name:       "test",
+				err:        errParse(unknown),
+			}},
+		},
+		"ErrUnknownJSON": {
+			reason: "We should report the file and line of a JSON object we can't parse.",
+			args: args{
+				opts:   []StreamParserOption{WithSource("crossplane.json")},
+				stream: compJSON + "\n\n" + `{"apiVersion": "example.org/v1", "kind": "Unknown"}`,
+			},
+			want: want{err: &ParseError{
+				Index:      1,
+				Source:     "crossplane.json",
+				Line:       7,
+				APIVersion: "example.org/v1",
+				Kind:       "Unknown",
+				err:        errParse(`{"apiVersion": "example.org/v1", "kind": "Unknown"}`),
+			}},
+		},
+		"ErrBadJSON": {
+			reason: "We should report the file and line of a malformed JSON object.",
+			args:   args{stream: meta + "\n---\n" + xrdJSON + "\n{\"kind\": }"},
+			want: want{err: &ParseError{
+				Index:  2,
+				Source: StreamFile,
+				Line:   7,
+				err:    errors.Wrap(errJSON("{\"kind\": }"), errBadJSON),
+			}},
+		},
+		"ErrLayer": {
+			reason: "We should report the image layer an object we can't parse was read from.",
+			args: args{
+				stream: unknown,
+				anno:   LayerAnnotation{Digest: "sha256:cool"},
+			},
+			want: want{err: &ParseError{
+				Index:      0,
+				Source:     StreamFile,
+				Line:       1,
+				APIVersion: "example.org/v1",
+				Kind:       "Unknown",
+				Name:       "test",
+				Layer:      "sha256:cool",
+				err:        errParse(unknown),
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p := NewStreamParser(metaScheme, objScheme, tc.args.opts...)
+			r := io.NopCloser(strings.NewReader(tc.args.stream))
+			if tc.args.anno != nil {
+				r = AnnotatedReadCloser(r, tc.args.anno)
+			}
+			pkg, err := p.Parse(context.Background(), r)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\np.Parse(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want.meta, len(pkg.GetMeta())); diff != "" {
+				t.Errorf("\n%s\np.Parse(...): -want meta, +got meta:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.objects, len(pkg.GetObjects())); diff != "" {
+				t.Errorf("\n%s\np.Parse(...): -want objects, +got objects:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
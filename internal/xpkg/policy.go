@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"context"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+)
+
+const (
+	errListSourcePolicies  = "cannot list package source policies"
+	errFmtSourceNotAllowed = "packages may not be installed from %q according to PackageSourcePolicy %q"
+)
+
+// A SourcePolicy determines whether packages may be installed from a
+// particular repository.
+type SourcePolicy interface {
+	// Permit returns an error if packages may not be installed from the
+	// supplied repository.
+	Permit(ctx context.Context, repo name.Repository) error
+}
+
+// A SourcePolicyFn is a function that satisfies SourcePolicy.
+type SourcePolicyFn func(ctx context.Context, repo name.Repository) error
+
+// Permit returns an error if packages may not be installed from the supplied
+// repository.
+func (fn SourcePolicyFn) Permit(ctx context.Context, repo name.Repository) error {
+	return fn(ctx, repo)
+}
+
+// NopSourcePolicy permits packages to be installed from any repository.
+type NopSourcePolicy struct{}
+
+// NewNopSourcePolicy creates a new NopSourcePolicy.
+func NewNopSourcePolicy() *NopSourcePolicy {
+	return &NopSourcePolicy{}
+}
+
+// Permit never returns an error.
+func (p *NopSourcePolicy) Permit(_ context.Context, _ name.Repository) error {
+	return nil
+}
+
+// An APISourcePolicy permits packages to be installed from a repository only
+// if every PackageSourcePolicy in the API server allows it.
+type APISourcePolicy struct {
+	client client.Reader
+}
+
+// NewAPISourcePolicy creates a new APISourcePolicy.
+func NewAPISourcePolicy(c client.Reader) *APISourcePolicy {
+	return &APISourcePolicy{client: c}
+}
+
+// Permit returns an error if any PackageSourcePolicy does not allow packages to
+// be installed from the supplied repository.
+func (p *APISourcePolicy) Permit(ctx context.Context, repo name.Repository) error {
+	l := &v1alpha1.PackageSourcePolicyList{}
+	if err := p.client.List(ctx, l); err != nil {
+		return errors.Wrap(err, errListSourcePolicies)
+	}
+	for _, sp := range l.Items {
+		if !sp.Spec.Allows(repo.Name()) {
+			return errors.Errorf(errFmtSourceNotAllowed, repo.Name(), sp.GetName())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/crossplane/apis/pkg/v1alpha1"
+)
+
+var _ SourcePolicy = &NopSourcePolicy{}
+var _ SourcePolicy = &APISourcePolicy{}
+
+func TestAPISourcePolicyPermit(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	withPolicies := func(specs ...v1alpha1.PackageSourcePolicySpec) test.MockListFn {
+		return test.NewMockListFn(nil, func(obj client.ObjectList) error {
+			l := obj.(*v1alpha1.PackageSourcePolicyList)
+			for i, s := range specs {
+				sp := v1alpha1.PackageSourcePolicy{Spec: s}
+				sp.SetName(string(rune('a' + i)))
+				l.Items = append(l.Items, sp)
+			}
+			return nil
+		})
+	}
+
+	type args struct {
+		list test.MockListFn
+		repo string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"ListError": {
+			reason: "We should return any error encountered listing policies.",
+			args: args{
+				list: test.NewMockListFn(errBoom),
+				repo: "crossplane/provider-nop",
+			},
+			want: errors.Wrap(errBoom, errListSourcePolicies),
+		},
+		"NoPolicies": {
+			reason: "Packages may be installed from anywhere if there are no policies.",
+			args: args{
+				list: withPolicies(),
+				repo: "crossplane/provider-nop",
+			},
+		},
+		"AllowedRegistry": {
+			reason: "Packages may be installed from an allowed registry.",
+			args: args{
+				list: withPolicies(v1alpha1.PackageSourcePolicySpec{Allow: []string{"xpkg.example.org"}}),
+				repo: "xpkg.example.org/crossplane/provider-nop",
+			},
+		},
+		"AllowedOrgWildcard": {
+			reason: "Packages may be installed from an organization matched by a wildcard.",
+			args: args{
+				list: withPolicies(v1alpha1.PackageSourcePolicySpec{Allow: []string{"*.example.org/crossplane"}}),
+				repo: "xpkg.example.org/crossplane/provider-nop",
+			},
+		},
+		"NotAllowed": {
+			reason: "Packages may not be installed from a source that is not allowed.",
+			args: args{
+				list: withPolicies(v1alpha1.PackageSourcePolicySpec{Allow: []string{"xpkg.example.org/crossplane"}}),
+				repo: "crossplane/provider-nop",
+			},
+			want: errors.Errorf(errFmtSourceNotAllowed, "index.docker.io/crossplane/provider-nop", "a"),
+		},
+		"Denied": {
+			reason: "Packages may not be installed from a denied source, even if it is allowed.",
+			args: args{
+				list: withPolicies(v1alpha1.PackageSourcePolicySpec{
+					Allow: []string{"xpkg.example.org"},
+					Deny:  []string{"xpkg.example.org/untrusted"},
+				}),
+				repo: "xpkg.example.org/untrusted/provider-nop",
+			},
+			want: errors.Errorf(errFmtSourceNotAllowed, "xpkg.example.org/untrusted/provider-nop", "a"),
+		},
+		"DeniedByAnotherPolicy": {
+			reason: "Packages must be allowed by every policy.",
+			args: args{
+				list: withPolicies(
+					v1alpha1.PackageSourcePolicySpec{Allow: []string{"xpkg.example.org"}},
+					v1alpha1.PackageSourcePolicySpec{Deny: []string{"xpkg.example.org/untrusted"}},
+				),
+				repo: "xpkg.example.org/untrusted/provider-nop",
+			},
+			want: errors.Errorf(errFmtSourceNotAllowed, "xpkg.example.org/untrusted/provider-nop", "b"),
+		},
+		"PartialSegmentNotMatched": {
+			reason: "A source should only match whole path segments.",
+			args: args{
+				list: withPolicies(v1alpha1.PackageSourcePolicySpec{Deny: []string{"xpkg.example.org/cross"}}),
+				repo: "xpkg.example.org/crossplane/provider-nop",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			repo := mustParseRepository(t, tc.args.repo)
+			p := NewAPISourcePolicy(&test.MockClient{MockList: tc.args.list})
+			err := p.Permit(context.Background(), repo)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nPermit(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func mustParseRepository(t *testing.T, s string) name.Repository {
+	t.Helper()
+	r, err := name.NewRepository(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
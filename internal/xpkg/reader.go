@@ -19,6 +19,8 @@ package xpkg
 import (
 	"compress/gzip"
 	"io"
+
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
 )
 
 var _ io.ReadCloser = &gzipReadCloser{}
@@ -87,6 +89,16 @@ func (t *teeReadCloser) Close() error {
 	return t.w.Close()
 }
 
+// Annotate returns the annotation of the underlying ReadCloser, if it is a
+// parser.AnnotatedReadCloser. Otherwise it returns nil.
+func (t *teeReadCloser) Annotate() interface{} {
+	anno, ok := t.r.(parser.AnnotatedReadCloser)
+	if !ok {
+		return nil
+	}
+	return anno.Annotate()
+}
+
 var _ io.ReadCloser = &joinedReadCloser{}
 
 // joinedReadCloster joins a reader and a closer. It is typically used in the
@@ -114,3 +126,22 @@ func (r *joinedReadCloser) Read(b []byte) (int, error) {
 func (r *joinedReadCloser) Close() error {
 	return r.c.Close()
 }
+
+var _ parser.AnnotatedReadCloser = &annotatedReadCloser{}
+
+// annotatedReadCloser annotates a ReadCloser.
+type annotatedReadCloser struct {
+	io.ReadCloser
+	annotation interface{}
+}
+
+// AnnotatedReadCloser constructs a new parser.AnnotatedReadCloser that
+// annotates the content of the passed ReadCloser with the passed annotation.
+func AnnotatedReadCloser(rc io.ReadCloser, annotation interface{}) parser.AnnotatedReadCloser {
+	return &annotatedReadCloser{ReadCloser: rc, annotation: annotation}
+}
+
+// Annotate returns the annotation of the AnnotatedReadCloser.
+func (r *annotatedReadCloser) Annotate() interface{} {
+	return r.annotation
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	kmeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
+
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+)
+
+// A Severity determines what happens when a package breaks a lint rule.
+type Severity string
+
+// Lint rule severities.
+const (
+	// SeverityError rules must not be broken; a package that breaks one
+	// can't be built or installed.
+	SeverityError Severity = "Error"
+
+	// SeverityWarning rules should not be broken; a package that breaks
+	// one is built and installed, but the broken rule is reported.
+	SeverityWarning Severity = "Warning"
+)
+
+// A Rule is a lint rule that a package is checked against.
+type Rule struct {
+	// ID uniquely identifies the rule, for example 'no-duplicate-objects'.
+	ID string
+
+	// Severity of breaking the rule.
+	Severity Severity
+
+	// Required rules can't be disabled by a package.
+	Required bool
+
+	// Check returns an error if the package breaks the rule.
+	Check parser.PackageLinterFn
+}
+
+// MetaRule returns a rule that the package's meta object breaks if it fails
+// any of the supplied linters. Packages that don't have exactly one meta object
+// can't break the rule.
+func MetaRule(id string, s Severity, fns ...parser.ObjectLinterFn) Rule {
+	return Rule{ID: id, Severity: s, Check: func(pkg *parser.Package) error {
+		if len(pkg.GetMeta()) != 1 {
+			return nil
+		}
+		for _, fn := range fns {
+			if err := fn(pkg.GetMeta()[0]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+}
+
+// ObjectRule returns a rule that the package breaks if any of its objects fail
+// the supplied linter. The error identifies every object that failed.
+func ObjectRule(id string, s Severity, fn parser.ObjectLinterFn) Rule {
+	return Rule{ID: id, Severity: s, Check: func(pkg *parser.Package) error {
+		var errs []string
+		for _, o := range pkg.GetObjects() {
+			if err := fn(o); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %s", describe(o), err))
+			}
+		}
+		if len(errs) > 0 {
+			return errors.New(strings.Join(errs, ", "))
+		}
+		return nil
+	}}
+}
+
+// A Finding is a lint rule that a package broke.
+type Finding struct {
+	// Rule that was broken.
+	Rule string
+
+	// Severity of the rule.
+	Severity Severity
+
+	// Message describing how the rule was broken.
+	Message string
+}
+
+// String returns the finding prefixed with the ID of the broken rule.
+func (f Finding) String() string {
+	return fmt.Sprintf("[%s] %s", f.Rule, f.Message)
+}
+
+// lintWarnings is returned by a RuleLinter when a package broke only rules of
+// warning severity.
+type lintWarnings struct{ error }
+
+func (e lintWarnings) Unwrap() error { return e.error }
+
+// IsLintWarnings returns true if the supplied error indicates that a package
+// broke only lint rules of warning severity, and may be built or installed.
+func IsLintWarnings(err error) bool {
+	return errors.As(err, &lintWarnings{})
+}
+
+// A RuleLinter lints a package by checking it against a set of rules. A package
+// may disable any rule that isn't required using the lint-disable annotation of
+// its meta object.
+type RuleLinter struct {
+	rules []Rule
+}
+
+// NewRuleLinter returns a linter that checks packages against the supplied
+// rules, in order.
+func NewRuleLinter(rules ...Rule) *RuleLinter {
+	return &RuleLinter{rules: rules}
+}
+
+// Check the supplied package against every rule it doesn't disable, returning
+// the rules it broke.
+func (l *RuleLinter) Check(pkg *parser.Package) []Finding {
+	disabled := disabledRules(pkg)
+	findings := make([]Finding, 0)
+	for _, r := range l.rules {
+		if !r.Required && disabled[r.ID] {
+			continue
+		}
+		if err := r.Check(pkg); err != nil {
+			findings = append(findings, Finding{Rule: r.ID, Severity: r.Severity, Message: err.Error()})
+		}
+	}
+	return findings
+}
+
+// Lint the supplied package. It returns an error describing every broken rule
+// if the package broke any rule. The error satisfies IsLintWarnings if the
+// package broke only rules of warning severity.
+func (l *RuleLinter) Lint(pkg *parser.Package) error {
+	findings := l.Check(pkg)
+	if len(findings) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(findings))
+	warnings := true
+	for i, f := range findings {
+		msgs[i] = f.String()
+		if f.Severity != SeverityWarning {
+			warnings = false
+		}
+	}
+	err := errors.New(strings.Join(msgs, "; "))
+	if warnings {
+		return lintWarnings{err}
+	}
+	return err
+}
+
+// disabledRules returns the IDs of the rules disabled by the annotations of
+// the supplied package's meta object.
+func disabledRules(pkg *parser.Package) map[string]bool {
+	disabled := map[string]bool{}
+	if len(pkg.GetMeta()) != 1 {
+		return disabled
+	}
+	m, err := kmeta.Accessor(pkg.GetMeta()[0])
+	if err != nil {
+		return disabled
+	}
+	for _, id := range strings.Split(m.GetAnnotations()[pkgmetav1.AnnotationLintDisable], ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			disabled[id] = true
+		}
+	}
+	return disabled
+}
+
+// describe returns the kind and name of the supplied object, for example
+// 'CustomResourceDefinition "widgets.example.org"'.
+func describe(o runtime.Object) string {
+	kind := o.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		t := reflect.TypeOf(o)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		kind = t.Name()
+	}
+	m, err := kmeta.Accessor(o)
+	if err != nil {
+		return kind
+	}
+	return fmt.Sprintf("%s %q", kind, m.GetName())
+}
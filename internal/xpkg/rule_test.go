@@ -0,0 +1,152 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
+)
+
+var _ parser.Linter = &RuleLinter{}
+
+func TestRuleLinterCheck(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	prov, _ := p.Parse(context.TODO(), ioutil.NopCloser(bytes.NewReader(bytes.Join([][]byte{v1ProvBytes, v1CRDBytes}, []byte("\n---\n")))))
+	disabling, _ := p.Parse(context.TODO(), ioutil.NopCloser(bytes.NewReader([]byte(`apiVersion: meta.pkg.crossplane.io/v1
+kind: Provider
+metadata:
+  name: test
+  annotations:
+    meta.crossplane.io/lint-disable: "optional, required"`))))
+
+	broken := func(_ *parser.Package) error { return errBoom }
+	notCRD := func(_ runtime.Object) error { return errBoom }
+
+	cases := map[string]struct {
+		reason string
+		rules  []Rule
+		pkg    *parser.Package
+		want   []Finding
+	}{
+		"NoFindings": {
+			reason: "A package that breaks no rules should have no findings.",
+			rules:  []Rule{{ID: "fine", Severity: SeverityError, Check: func(_ *parser.Package) error { return nil }}},
+			pkg:    prov,
+			want:   []Finding{},
+		},
+		"Findings": {
+			reason: "We should return a finding for each rule the package breaks, in order.",
+			rules: []Rule{
+				{ID: "warn", Severity: SeverityWarning, Check: broken},
+				{ID: "error", Severity: SeverityError, Check: broken},
+			},
+			pkg: prov,
+			want: []Finding{
+				{Rule: "warn", Severity: SeverityWarning, Message: "boom"},
+				{Rule: "error", Severity: SeverityError, Message: "boom"},
+			},
+		},
+		"ObjectRule": {
+			reason: "An object rule's finding should identify the objects that broke it.",
+			rules:  []Rule{ObjectRule("objects", SeverityError, notCRD)},
+			pkg:    prov,
+			want: []Finding{
+				{Rule: "objects", Severity: SeverityError, Message: `CustomResourceDefinition "test": boom`},
+			},
+		},
+		"Disabled": {
+			reason: "We should not check rules the package disables, unless they're required.",
+			rules: []Rule{
+				{ID: "optional", Severity: SeverityError, Check: broken},
+				{ID: "required", Severity: SeverityError, Required: true, Check: broken},
+			},
+			pkg: disabling,
+			want: []Finding{
+				{Rule: "required", Severity: SeverityError, Message: "boom"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := NewRuleLinter(tc.rules...).Check(tc.pkg)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nCheck(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRuleLinterLint(t *testing.T) {
+	errBoom := errors.New("boom")
+	prov, _ := p.Parse(context.TODO(), ioutil.NopCloser(bytes.NewReader(v1ProvBytes)))
+	broken := func(_ *parser.Package) error { return errBoom }
+
+	type want struct {
+		err      string
+		warnings bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		rules  []Rule
+		want   want
+	}{
+		"Passed": {
+			reason: "A package that breaks no rules should pass.",
+			rules:  []Rule{},
+		},
+		"Warnings": {
+			reason: "A package that breaks only warning rules should return warnings.",
+			rules:  []Rule{{ID: "warn", Severity: SeverityWarning, Check: broken}},
+			want:   want{err: "[warn] boom", warnings: true},
+		},
+		"Errors": {
+			reason: "A package that breaks any error rule should return an error describing every broken rule.",
+			rules: []Rule{
+				{ID: "warn", Severity: SeverityWarning, Check: broken},
+				{ID: "error", Severity: SeverityError, Check: broken},
+			},
+			want: want{err: "[warn] boom; [error] boom"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := NewRuleLinter(tc.rules...).Lint(prov)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if diff := cmp.Diff(tc.want.err, got); diff != "" {
+				t.Errorf("\n%s\nLint(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.warnings, IsLintWarnings(err)); diff != "" {
+				t.Errorf("\n%s\nIsLintWarnings(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -21,13 +21,21 @@ import (
 	extv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	extv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+
 	v1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
 	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
 	pkgmetav1alpha1 "github.com/crossplane/crossplane/apis/pkg/meta/v1alpha1"
 )
 
+const (
+	errFmtNoHub        = "%s is not a known package meta type"
+	errFmtConvertToHub = "cannot convert %s to its hub version"
+)
+
 // BuildMetaScheme builds the default scheme used for identifying metadata in a
 // Crossplane package.
 func BuildMetaScheme() (*runtime.Scheme, error) {
@@ -88,3 +96,27 @@ func TryConvertToPkg(obj runtime.Object, candidates ...conversion.Hub) (pkgmetav
 	m, ok := po.(pkgmetav1.Pkg)
 	return m, ok
 }
+
+// ConvertToHub converts the supplied package meta object, of any supported
+// meta.pkg.crossplane.io version, to its hub version. Consumers of package
+// metadata should operate on the hub version, so that they needn't know which
+// version a package shipped.
+func ConvertToHub(obj runtime.Object) (runtime.Object, error) {
+	var hub conversion.Hub
+	var gvk schema.GroupVersionKind
+	switch obj.(type) {
+	case *pkgmetav1.Provider, *pkgmetav1.Configuration:
+		return obj, nil
+	case *pkgmetav1alpha1.Provider:
+		hub, gvk = &pkgmetav1.Provider{}, pkgmetav1.ProviderGroupVersionKind
+	case *pkgmetav1alpha1.Configuration:
+		hub, gvk = &pkgmetav1.Configuration{}, pkgmetav1.ConfigurationGroupVersionKind
+	default:
+		return nil, errors.Errorf(errFmtNoHub, describe(obj))
+	}
+	if err := obj.(conversion.Convertible).ConvertTo(hub); err != nil {
+		return nil, errors.Wrapf(err, errFmtConvertToHub, describe(obj))
+	}
+	hub.GetObjectKind().SetGroupVersionKind(gvk)
+	return hub, nil
+}
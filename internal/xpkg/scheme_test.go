@@ -20,10 +20,15 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/conversion"
 
 	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	pkgmetav1 "github.com/crossplane/crossplane/apis/pkg/meta/v1"
+	pkgmetav1alpha1 "github.com/crossplane/crossplane/apis/pkg/meta/v1alpha1"
 )
 
 type mockHub struct{ runtime.Object }
@@ -104,3 +109,72 @@ func TestTryConvert(t *testing.T) {
 		})
 	}
 }
+
+func TestConvertToHub(t *testing.T) {
+	channel := "stable"
+
+	type want struct {
+		meta runtime.Object
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		meta   runtime.Object
+		want   want
+	}{
+		"AlreadyHub": {
+			reason: "We should return a hub version object unchanged.",
+			meta:   &pkgmetav1.Provider{ObjectMeta: metav1.ObjectMeta{Name: "cool"}},
+			want: want{
+				meta: &pkgmetav1.Provider{ObjectMeta: metav1.ObjectMeta{Name: "cool"}},
+			},
+		},
+		"V1alpha1Provider": {
+			reason: "We should convert a v1alpha1 Provider to a v1 Provider.",
+			meta: &pkgmetav1alpha1.Provider{
+				TypeMeta:   metav1.TypeMeta{APIVersion: pkgmetav1alpha1.SchemeGroupVersion.String(), Kind: pkgmetav1alpha1.ProviderKind},
+				ObjectMeta: metav1.ObjectMeta{Name: "cool"},
+				Spec:       pkgmetav1alpha1.ProviderSpec{MetaSpec: pkgmetav1alpha1.MetaSpec{Channel: &channel}},
+			},
+			want: want{
+				meta: &pkgmetav1.Provider{
+					TypeMeta:   metav1.TypeMeta{APIVersion: pkgmetav1.SchemeGroupVersion.String(), Kind: pkgmetav1.ProviderKind},
+					ObjectMeta: metav1.ObjectMeta{Name: "cool"},
+					Spec:       pkgmetav1.ProviderSpec{MetaSpec: pkgmetav1.MetaSpec{Channel: &channel}},
+				},
+			},
+		},
+		"V1alpha1Configuration": {
+			reason: "We should convert a v1alpha1 Configuration to a v1 Configuration.",
+			meta: &pkgmetav1alpha1.Configuration{
+				ObjectMeta: metav1.ObjectMeta{Name: "cool"},
+			},
+			want: want{
+				meta: &pkgmetav1.Configuration{
+					TypeMeta:   metav1.TypeMeta{APIVersion: pkgmetav1.SchemeGroupVersion.String(), Kind: pkgmetav1.ConfigurationKind},
+					ObjectMeta: metav1.ObjectMeta{Name: "cool"},
+				},
+			},
+		},
+		"ErrNoHub": {
+			reason: "We should return an error if the object is not a known package meta type.",
+			meta:   &metav1.Status{},
+			want: want{
+				err: errors.Errorf(errFmtNoHub, "Status"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ConvertToHub(tc.meta)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nConvertToHub(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.meta, got); diff != "" {
+				t.Errorf("\n%s\nConvertToHub(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+
+	pkgv1 "github.com/crossplane/crossplane/apis/pkg/v1"
+)
+
+// NewestSatisfyingVersion returns the newest of the supplied tags that is a
+// valid semantic version in the supplied release channel and satisfies the
+// supplied constraints. Tags that are not valid semantic versions are ignored.
+// It returns nil if no tag satisfies the constraints.
+func NewestSatisfyingVersion(c *semver.Constraints, ch pkgv1.PackageChannel, tags []string) *semver.Version {
+	vs := []*semver.Version{}
+	for _, t := range tags {
+		v, err := semver.NewVersion(t)
+		if err != nil {
+			continue
+		}
+		vs = append(vs, v)
+	}
+
+	sort.Sort(semver.Collection(vs))
+	var newest *semver.Version
+	for _, v := range vs {
+		if Satisfies(c, ch, v) {
+			newest = v
+		}
+	}
+	return newest
+}
+
+// Satisfies returns true if the supplied version is in the supplied release
+// channel and satisfies the supplied constraints. Constraints are usually
+// satisfied by a pre-release version only if they mention a pre-release. In
+// the beta and edge channels a pre-release version instead satisfies any
+// constraints that its release version would, e.g. v1.2.0-rc.1 satisfies
+// ">=v1.2.0".
+func Satisfies(c *semver.Constraints, ch pkgv1.PackageChannel, v *semver.Version) bool {
+	if !InChannel(ch, v) {
+		return false
+	}
+	if c.Check(v) {
+		return true
+	}
+	if v.Prerelease() == "" || (ch != pkgv1.PackageChannelBeta && ch != pkgv1.PackageChannelEdge) {
+		return false
+	}
+	r, err := v.SetPrerelease("")
+	if err != nil {
+		return false
+	}
+	return c.Check(&r)
+}
+
+// InChannel returns true if the supplied version is in the supplied release
+// channel. Every version is in the empty channel.
+func InChannel(ch pkgv1.PackageChannel, v *semver.Version) bool {
+	switch ch {
+	case pkgv1.PackageChannelStable:
+		return v.Prerelease() == ""
+	case pkgv1.PackageChannelBeta:
+		pre := v.Prerelease()
+		return pre == "" || strings.HasPrefix(pre, "beta") || strings.HasPrefix(pre, "rc")
+	}
+	return true
+}
+
+// channelStability ranks release channels from most to least stable.
+var channelStability = map[pkgv1.PackageChannel]int{
+	pkgv1.PackageChannelStable: 0,
+	pkgv1.PackageChannelBeta:   1,
+	pkgv1.PackageChannelEdge:   2,
+}
+
+// ChannelIncludes returns true if a package pinned to the supplied channel may
+// install a package that declares the supplied channel. Any package may be
+// installed if no channel is pinned, and a package that declares no channel
+// may be installed in any channel.
+func ChannelIncludes(pinned pkgv1.PackageChannel, declared *string) bool {
+	if pinned == "" || declared == nil {
+		return true
+	}
+	d, ok := channelStability[pkgv1.PackageChannel(*declared)]
+	return ok && d <= channelStability[pinned]
+}
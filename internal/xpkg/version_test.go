@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/pointer"
+
+	pkgv1 "github.com/crossplane/crossplane/apis/pkg/v1"
+)
+
+func TestNewestSatisfyingVersion(t *testing.T) {
+	type args struct {
+		constraints string
+		channel     pkgv1.PackageChannel
+		tags        []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   string
+	}{
+		"Newest": {
+			reason: "We should return the newest tag that satisfies the constraints, preserving its original form.",
+			args: args{
+				constraints: ">=v0.14.0, <v1.0.0",
+				tags:        []string{"v0.13.0", "v0.20.1", "v0.14.0", "v1.0.0", "master"},
+			},
+			want: "v0.20.1",
+		},
+		"UnpinnedIgnoresPreReleases": {
+			reason: "We should not consider pre-release tags if no channel is pinned and the constraints don't mention a pre-release.",
+			args: args{
+				constraints: ">=v1.0.0",
+				tags:        []string{"v1.0.0", "v1.1.0-rc.1", "v1.2.0-alpha.1"},
+			},
+			want: "v1.0.0",
+		},
+		"Stable": {
+			reason: "We should consider only release tags in the stable channel, even if the constraints mention a pre-release.",
+			args: args{
+				constraints: ">=v1.0.0-rc.1",
+				channel:     pkgv1.PackageChannelStable,
+				tags:        []string{"v1.0.0-rc.1", "v1.0.0", "v1.1.0-rc.1"},
+			},
+			want: "v1.0.0",
+		},
+		"Beta": {
+			reason: "We should consider beta and release candidate tags in the beta channel.",
+			args: args{
+				constraints: ">=v1.0.0, <v2.0.0",
+				channel:     pkgv1.PackageChannelBeta,
+				tags:        []string{"v1.0.0", "v1.1.0-beta.1", "v1.1.0-rc.2", "v1.2.0-alpha.1", "v2.0.0-rc.1"},
+			},
+			want: "v1.1.0-rc.2",
+		},
+		"Edge": {
+			reason: "We should consider all pre-release tags in the edge channel.",
+			args: args{
+				constraints: "^v1.0.0",
+				channel:     pkgv1.PackageChannelEdge,
+				tags:        []string{"v1.0.0", "v1.1.0-beta.1", "v1.2.0-alpha.1"},
+			},
+			want: "v1.2.0-alpha.1",
+		},
+		"NoneSatisfy": {
+			reason: "We should return nothing if no tag satisfies the constraints.",
+			args: args{
+				constraints: ">=v2.0.0",
+				tags:        []string{"v0.13.0", "v1.0.0", "latest"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, err := semver.NewConstraint(tc.args.constraints)
+			if err != nil {
+				t.Fatalf("semver.NewConstraint(...): %s", err)
+			}
+			got := ""
+			if v := NewestSatisfyingVersion(c, tc.args.channel, tc.args.tags); v != nil {
+				got = v.Original()
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nNewestSatisfyingVersion(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestChannelIncludes(t *testing.T) {
+	type args struct {
+		pinned   pkgv1.PackageChannel
+		declared *string
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   bool
+	}{
+		"NotPinned": {
+			reason: "A package that isn't pinned to a channel may install a package in any channel.",
+			args:   args{declared: pointer.StringPtr("edge")},
+			want:   true,
+		},
+		"NotDeclared": {
+			reason: "A package that declares no channel may be installed in any channel.",
+			args:   args{pinned: pkgv1.PackageChannelStable},
+			want:   true,
+		},
+		"Same": {
+			reason: "A package may be installed in the channel it declares.",
+			args:   args{pinned: pkgv1.PackageChannelBeta, declared: pointer.StringPtr("beta")},
+			want:   true,
+		},
+		"MoreStable": {
+			reason: "A package may be installed in a channel less stable than the one it declares.",
+			args:   args{pinned: pkgv1.PackageChannelEdge, declared: pointer.StringPtr("stable")},
+			want:   true,
+		},
+		"LessStable": {
+			reason: "A package may not be installed in a channel more stable than the one it declares.",
+			args:   args{pinned: pkgv1.PackageChannelStable, declared: pointer.StringPtr("beta")},
+			want:   false,
+		},
+		"Unknown": {
+			reason: "A package that declares an unknown channel may not be installed in a pinned channel.",
+			args:   args{pinned: pkgv1.PackageChannelEdge, declared: pointer.StringPtr("nightly")},
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ChannelIncludes(tc.args.pinned, tc.args.declared)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nChannelIncludes(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
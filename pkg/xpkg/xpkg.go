@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package xpkg exposes the schemes and linters Crossplane uses to decide which
+// objects a provider or configuration package may contain. Tools that build,
+// host, or validate packages outside of a cluster can use it to validate
+// packages exactly as Crossplane's package manager does.
+package xpkg
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
+
+	"github.com/crossplane/crossplane/internal/xpkg"
+)
+
+const (
+	errBuildMetaScheme   = "cannot build meta scheme for package parser"
+	errBuildObjectScheme = "cannot build object scheme for package parser"
+)
+
+// A Severity determines what happens when a package breaks a lint rule.
+type Severity = xpkg.Severity
+
+// Lint rule severities.
+const (
+	// SeverityError rules must not be broken; a package that breaks one
+	// can't be built or installed.
+	SeverityError = xpkg.SeverityError
+
+	// SeverityWarning rules should not be broken; a package that breaks
+	// one is built and installed, but the broken rule is reported.
+	SeverityWarning = xpkg.SeverityWarning
+)
+
+// A Rule is a lint rule that a package is checked against.
+type Rule = xpkg.Rule
+
+// A Finding is a lint rule that a package broke.
+type Finding = xpkg.Finding
+
+// IDs of the lint rules that packages are checked against.
+const (
+	RuleOneMeta            = xpkg.RuleOneMeta
+	RuleMetaType           = xpkg.RuleMetaType
+	RuleValidSemver        = xpkg.RuleValidSemver
+	RuleValidChannel       = xpkg.RuleValidChannel
+	RuleObjectType         = xpkg.RuleObjectType
+	RuleNoDuplicateObjects = xpkg.RuleNoDuplicateObjects
+	RuleCRDName            = xpkg.RuleCRDName
+	RuleCRDKindCase        = xpkg.RuleCRDKindCase
+)
+
+// BuildMetaScheme builds the scheme Crossplane uses to identify the metadata
+// of a package.
+func BuildMetaScheme() (*runtime.Scheme, error) {
+	return xpkg.BuildMetaScheme()
+}
+
+// BuildObjectScheme builds the scheme Crossplane uses to identify the objects
+// of a package.
+func BuildObjectScheme() (*runtime.Scheme, error) {
+	return xpkg.BuildObjectScheme()
+}
+
+// NewParser returns a package parser that identifies metadata and objects
+// the same way Crossplane does. Package content may be a stream of YAML
+// documents, JSON objects, or a mix of both.
+func NewParser() (parser.Parser, error) {
+	metaScheme, err := xpkg.BuildMetaScheme()
+	if err != nil {
+		return nil, errors.Wrap(err, errBuildMetaScheme)
+	}
+	objScheme, err := xpkg.BuildObjectScheme()
+	if err != nil {
+		return nil, errors.Wrap(err, errBuildObjectScheme)
+	}
+	return xpkg.NewStreamParser(metaScheme, objScheme), nil
+}
+
+// NewProviderLinter returns the linter Crossplane uses to validate provider
+// packages. Packages are also checked against any supplied rules.
+func NewProviderLinter(rules ...Rule) parser.Linter {
+	return xpkg.NewProviderLinter(rules...)
+}
+
+// NewConfigurationLinter returns the linter Crossplane uses to validate
+// configuration packages. Packages are also checked against any supplied
+// rules.
+func NewConfigurationLinter(rules ...Rule) parser.Linter {
+	return xpkg.NewConfigurationLinter(rules...)
+}
+
+// IsLintWarnings returns true if the supplied error, as returned by a linter,
+// indicates that a package broke only lint rules of warning severity, and may
+// be built or installed.
+func IsLintWarnings(err error) bool {
+	return xpkg.IsLintWarnings(err)
+}
+
+// IsProviderObject checks that an object may be included in a provider
+// package.
+func IsProviderObject(o runtime.Object) error {
+	return xpkg.IsProviderObject(o)
+}
+
+// IsConfigurationObject checks that an object may be included in a
+// configuration package.
+func IsConfigurationObject(o runtime.Object) error {
+	return xpkg.IsConfigurationObject(o)
+}
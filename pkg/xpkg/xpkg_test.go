@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package xpkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/parser"
+)
+
+const (
+	providerMeta = `apiVersion: meta.pkg.crossplane.io/v1
+kind: Provider
+metadata:
+  name: provider-test
+spec:
+  controller:
+    image: crossplane/provider-test-controller:v0.1.0
+`
+	configurationMeta = `apiVersion: meta.pkg.crossplane.io/v1
+kind: Configuration
+metadata:
+  name: configuration-test
+`
+	crd = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: tests.example.org
+spec:
+  group: example.org
+  names:
+    kind: Test
+    plural: tests
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`
+	xrd = `apiVersion: apiextensions.crossplane.io/v1
+kind: CompositeResourceDefinition
+metadata:
+  name: xtests.example.org
+spec:
+  group: example.org
+  names:
+    kind: XTest
+    plural: xtests
+  versions:
+  - name: v1
+    served: true
+    referenceable: true
+`
+)
+
+func TestLinters(t *testing.T) {
+	type args struct {
+		linter parser.Linter
+		pkg    string
+	}
+	type want struct {
+		valid bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ValidProvider": {
+			reason: "A provider package may contain CRDs.",
+			args:   args{linter: NewProviderLinter(), pkg: providerMeta + "---\n" + crd},
+			want:   want{valid: true},
+		},
+		"InvalidProvider": {
+			reason: "A provider package may not contain XRDs.",
+			args:   args{linter: NewProviderLinter(), pkg: providerMeta + "---\n" + xrd},
+			want:   want{valid: false},
+		},
+		"ValidConfiguration": {
+			reason: "A configuration package may contain XRDs.",
+			args:   args{linter: NewConfigurationLinter(), pkg: configurationMeta + "---\n" + xrd},
+			want:   want{valid: true},
+		},
+		"InvalidConfiguration": {
+			reason: "A configuration package may not contain CRDs.",
+			args:   args{linter: NewConfigurationLinter(), pkg: configurationMeta + "---\n" + crd},
+			want:   want{valid: false},
+		},
+		"WrongMeta": {
+			reason: "A configuration package must not have provider metadata.",
+			args:   args{linter: NewConfigurationLinter(), pkg: providerMeta},
+			want:   want{valid: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewParser()
+			if err != nil {
+				t.Fatalf("NewParser(): %s", err)
+			}
+			r, _ := parser.NewEchoBackend(tc.args.pkg).Init(context.Background())
+			pkg, err := p.Parse(context.Background(), r)
+			if err != nil {
+				t.Fatalf("p.Parse(...): %s", err)
+			}
+			err = tc.args.linter.Lint(pkg)
+			if diff := cmp.Diff(tc.want.valid, err == nil || IsLintWarnings(err)); diff != "" {
+				t.Errorf("\n%s\nLint(...): -want valid, +got valid:\n%s\nerror: %v", tc.reason, diff, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+//go:build e2e
+// +build e2e
+
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package upgrade tests that packages, composite resources, and claims survive
+// an upgrade of Crossplane. It expects to run against a cluster with an older
+// Crossplane release installed, and upgrades it to the chart at the path in
+// the E2E_UPGRADE_CHART environment variable.
+package upgrade
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/composite"
+
+	extv1 "github.com/crossplane/crossplane/apis/apiextensions/v1"
+	v1 "github.com/crossplane/crossplane/apis/pkg/v1"
+	"github.com/crossplane/crossplane/apis/pkg/v1beta1"
+)
+
+// Environment variables that configure the upgrade.
+const (
+	envHelm  = "E2E_HELM"
+	envChart = "E2E_UPGRADE_CHART"
+	envSet   = "E2E_UPGRADE_SET"
+)
+
+const (
+	namespace = "crossplane-system"
+	release   = "crossplane"
+
+	pollInterval = 5 * time.Second
+	pollTimeout  = 3 * time.Minute
+)
+
+var (
+	claimGVK     = schema.GroupVersionKind{Group: "nop.example.org", Version: "v1alpha1", Kind: "NopResource"}
+	compositeGVK = schema.GroupVersionKind{Group: "nop.example.org", Version: "v1alpha1", Kind: "ClusterNopResource"}
+)
+
+// A snapshot of the state we expect to survive an upgrade.
+type snapshot struct {
+	Revisions   map[string]types.UID
+	Lock        []string
+	CompositeID types.UID
+	ClaimID     types.UID
+	Composed    []corev1.ObjectReference
+}
+
+func TestUpgrade(t *testing.T) {
+	chart := os.Getenv(envChart)
+	if chart == "" {
+		t.Skipf("%s is not set; skipping upgrade test", envChart)
+	}
+
+	ctx := context.Background()
+	s := runtime.NewScheme()
+	if err := v1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1beta1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := extv1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: s})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setup(ctx, t, c)
+
+	before := observe(ctx, t, c)
+	t.Logf("Observed state before upgrade: %+v", before)
+
+	upgrade(t, chart)
+
+	// Crossplane may briefly be unable to report readiness while its new
+	// controllers start, so we poll until the state we observed before the
+	// upgrade is restored, or until we time out.
+	var after snapshot
+	if err := wait.PollImmediate(pollInterval, pollTimeout, func() (bool, error) {
+		after = observe(ctx, t, c)
+		return cmp.Equal(before, after), nil
+	}); err != nil {
+		t.Errorf("State did not survive upgrade: -before, +after:\n%s", cmp.Diff(before, after))
+	}
+}
+
+// setup installs the packages, XRD, Composition, and claim that are expected
+// to survive the upgrade, and waits for them to become ready.
+func setup(ctx context.Context, t *testing.T, c client.Client) {
+	t.Helper()
+
+	prv := &v1.Provider{
+		ObjectMeta: metav1.ObjectMeta{Name: "provider-nop"},
+		Spec: v1.ProviderSpec{
+			PackageSpec: v1.PackageSpec{
+				Package:                     "crossplane/provider-nop:main",
+				IgnoreCrossplaneConstraints: pointer.BoolPtr(true),
+			},
+		},
+	}
+	create(ctx, t, c, prv)
+	poll(t, "Provider is healthy", func() (bool, error) {
+		if err := c.Get(ctx, types.NamespacedName{Name: prv.GetName()}, prv); err != nil {
+			return false, err
+		}
+		return prv.GetCondition(v1.TypeHealthy).Status == corev1.ConditionTrue, nil
+	})
+
+	xrd := &extv1.CompositeResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "clusternopresources.nop.example.org"},
+		Spec: extv1.CompositeResourceDefinitionSpec{
+			Group: compositeGVK.Group,
+			Names: kextv1.CustomResourceDefinitionNames{
+				Kind:     compositeGVK.Kind,
+				ListKind: compositeGVK.Kind + "List",
+				Plural:   "clusternopresources",
+				Singular: "clusternopresource",
+			},
+			ClaimNames: &kextv1.CustomResourceDefinitionNames{
+				Kind:     claimGVK.Kind,
+				ListKind: claimGVK.Kind + "List",
+				Plural:   "nopresources",
+				Singular: "nopresource",
+			},
+			Versions: []extv1.CompositeResourceDefinitionVersion{{
+				Name:          compositeGVK.Version,
+				Served:        true,
+				Referenceable: true,
+				Schema: &extv1.CompositeResourceValidation{
+					OpenAPIV3Schema: runtime.RawExtension{Raw: []byte(`{"type":"object","properties":{"spec":{"type":"object","properties":{"coolField":{"type":"string"}}}}}`)},
+				},
+			}},
+		},
+	}
+	create(ctx, t, c, xrd)
+	poll(t, "XRD is established and offered", func() (bool, error) {
+		if err := c.Get(ctx, types.NamespacedName{Name: xrd.GetName()}, xrd); err != nil {
+			return false, err
+		}
+		return xrd.Status.GetCondition(extv1.TypeEstablished).Status == corev1.ConditionTrue &&
+			xrd.Status.GetCondition(extv1.TypeOffered).Status == corev1.ConditionTrue, nil
+	})
+
+	comp := &extv1.Composition{
+		ObjectMeta: metav1.ObjectMeta{Name: "clusternopresources.upgrade.nop.example.org"},
+		Spec: extv1.CompositionSpec{
+			CompositeTypeRef: extv1.TypeReference{APIVersion: compositeGVK.GroupVersion().String(), Kind: compositeGVK.Kind},
+			Resources: []extv1.ComposedTemplate{{
+				Name: pointer.StringPtr("nop"),
+				Base: runtime.RawExtension{Raw: []byte(`{
+					"apiVersion": "nop.crossplane.io/v1alpha1",
+					"kind": "NopResource",
+					"spec": {
+						"forProvider": {
+							"conditionAfter": [
+								{"conditionType": "Ready", "conditionStatus": "True", "time": "5s"},
+								{"conditionType": "Synced", "conditionStatus": "True", "time": "5s"}
+							]
+						}
+					}
+				}`)},
+			}},
+		},
+	}
+	create(ctx, t, c, comp)
+
+	cm := claim.New(claim.WithGroupVersionKind(claimGVK))
+	cm.SetName("upgrade-example")
+	cm.SetNamespace("default")
+	create(ctx, t, c, cm)
+	poll(t, "claim is ready", func() (bool, error) {
+		if err := c.Get(ctx, types.NamespacedName{Namespace: cm.GetNamespace(), Name: cm.GetName()}, cm); err != nil {
+			return false, err
+		}
+		return cm.GetCondition(xpv1.TypeReady).Status == corev1.ConditionTrue, nil
+	})
+}
+
+// observe the state that is expected to survive the upgrade.
+func observe(ctx context.Context, t *testing.T, c client.Client) snapshot {
+	t.Helper()
+	s := snapshot{Revisions: map[string]types.UID{}}
+
+	prl := &v1.ProviderRevisionList{}
+	if err := c.List(ctx, prl); err != nil {
+		t.Logf("Cannot list provider revisions: %v", err)
+		return s
+	}
+	for _, pr := range prl.Items {
+		// A revision that is not healthy has not survived.
+		if pr.GetCondition(v1.TypeHealthy).Status != corev1.ConditionTrue {
+			continue
+		}
+		s.Revisions[pr.GetName()] = pr.GetUID()
+	}
+
+	l := &v1beta1.Lock{}
+	if err := c.Get(ctx, types.NamespacedName{Name: "lock"}, l); err != nil {
+		t.Logf("Cannot get lock: %v", err)
+		return s
+	}
+	for _, p := range l.Packages {
+		s.Lock = append(s.Lock, strings.Join([]string{p.Name, p.Source, p.Version}, "/"))
+	}
+	sort.Strings(s.Lock)
+
+	cm := claim.New(claim.WithGroupVersionKind(claimGVK))
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "default", Name: "upgrade-example"}, cm); err != nil {
+		t.Logf("Cannot get claim: %v", err)
+		return s
+	}
+	if cm.GetCondition(xpv1.TypeReady).Status == corev1.ConditionTrue {
+		s.ClaimID = cm.GetUID()
+	}
+
+	ref := cm.GetResourceReference()
+	if ref == nil {
+		return s
+	}
+	cp := composite.New(composite.WithGroupVersionKind(compositeGVK))
+	if err := c.Get(ctx, types.NamespacedName{Name: ref.Name}, cp); err != nil {
+		t.Logf("Cannot get composite resource: %v", err)
+		return s
+	}
+	if cp.GetCondition(xpv1.TypeReady).Status == corev1.ConditionTrue {
+		s.CompositeID = cp.GetUID()
+	}
+	s.Composed = cp.GetResourceReferences()
+
+	return s
+}
+
+// upgrade the Crossplane release to the supplied chart.
+func upgrade(t *testing.T, chart string) {
+	t.Helper()
+
+	helm := os.Getenv(envHelm)
+	if helm == "" {
+		helm = "helm"
+	}
+	args := []string{"upgrade", release, chart, "--namespace", namespace, "--wait", "--timeout", pollTimeout.String()}
+	if set := os.Getenv(envSet); set != "" {
+		args = append(args, "--set", set)
+	}
+
+	t.Logf("Upgrading Crossplane: %s %s", helm, strings.Join(args, " "))
+	out, err := exec.Command(helm, args...).CombinedOutput() //nolint:gosec // We want to run the helm binary we were told to.
+	if err != nil {
+		t.Fatalf("Upgrade Crossplane: %v\n%s", err, out)
+	}
+}
+
+// create the supplied object, and delete it when the test is complete.
+func create(ctx context.Context, t *testing.T, c client.Client, o client.Object) {
+	t.Helper()
+
+	// Creating an object may briefly fail, for example while Crossplane's
+	// webhooks start or a newly defined CRD is established.
+	poll(t, "create "+o.GetName(), func() (bool, error) {
+		if err := c.Create(ctx, o); err != nil {
+			t.Logf("Create %q: %v", o.GetName(), err)
+			return false, nil
+		}
+		return true, nil
+	})
+	t.Logf("Created %q", o.GetName())
+
+	t.Cleanup(func() {
+		if err := c.Delete(ctx, o); resource.IgnoreNotFound(err) != nil {
+			t.Errorf("Delete %q: %v", o.GetName(), err)
+		}
+		t.Logf("Deleted %q", o.GetName())
+	})
+}
+
+// poll until the supplied condition is true, failing the test if it isn't
+// within our timeout.
+func poll(t *testing.T, desc string, fn wait.ConditionFunc) {
+	t.Helper()
+	t.Logf("Waiting until %s", desc)
+	if err := wait.PollImmediate(pollInterval, pollTimeout, fn); err != nil {
+		t.Fatalf("Waiting until %s: %v", desc, err)
+	}
+}